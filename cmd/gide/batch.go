@@ -0,0 +1,96 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/goki/gide/gide"
+)
+
+// cmdList is a flag that can be given multiple times, e.g., -cmd "Build Go Proj"
+type cmdList []string
+
+func (cl *cmdList) String() string {
+	return strings.Join(*cl, ", ")
+}
+
+func (cl *cmdList) Set(s string) error {
+	*cl = append(*cl, s)
+	return nil
+}
+
+// isBatch returns true if the -batch flag is in the command args
+func isBatch(args []string) bool {
+	for _, a := range args {
+		if a == "-batch" || a == "--batch" {
+			return true
+		}
+	}
+	return false
+}
+
+// batchRun runs gide in batch mode, without any gui: runs the given commands
+// and / or find / replace on the project at given path, returning the exit
+// code for the process
+func batchRun(args []string) int {
+	var cmds cmdList
+	var file, find, repl, prompt1, prompt2 string
+	var replace, ignoreCase bool
+	fs := flag.NewFlagSet("gide -batch", flag.ContinueOnError)
+	fs.Bool("batch", true, "run in batch mode without any gui, on project at path given as the last arg (default current directory)")
+	fs.Var(&cmds, "cmd", "name of command to run (e.g., \"Build Go Proj\") -- can be given multiple times, run in order")
+	fs.StringVar(&file, "file", "", "file for commands, e.g., {FilePath} -- default is the project root")
+	fs.StringVar(&prompt1, "prompt1", "", "value for {PromptString1} in commands")
+	fs.StringVar(&prompt2, "prompt2", "", "value for {PromptString2} in commands")
+	fs.StringVar(&find, "find", "", "string to find in all the project files")
+	fs.StringVar(&repl, "replace", "", "replace all occurrences of -find with this string")
+	fs.BoolVar(&ignoreCase, "i", false, "ignore case for -find and -replace")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "replace" {
+			replace = true
+		}
+	})
+	path := "."
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+	if len(cmds) == 0 && find == "" {
+		fmt.Fprintln(os.Stderr, "gide -batch: nothing to do -- use -cmd and / or -find")
+		fs.Usage()
+		return 2
+	}
+	gide.LoadPlugins()
+	b, err := gide.OpenBatch(path, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gide -batch: %v\n", err)
+		return 1
+	}
+	b.ArgVals["{PromptString1}"] = prompt1
+	b.ArgVals["{PromptString2}"] = prompt2
+	for _, cm := range cmds {
+		if err := b.RunCmd(gide.CmdName(cm), file); err != nil {
+			fmt.Fprintf(os.Stderr, "gide -batch: %v\n", err)
+			return 1
+		}
+	}
+	if find != "" {
+		if replace {
+			if _, err := b.Replace(find, repl, ignoreCase, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "gide -batch: %v\n", err)
+				return 1
+			}
+		} else {
+			b.Find(find, ignoreCase, nil)
+		}
+	}
+	return 0
+}
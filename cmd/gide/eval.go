@@ -0,0 +1,34 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/goki/gide/gide"
+)
+
+// isEval returns true if the command args are gide -eval, run by
+// gide.EvalSnippet to evaluate a Go snippet in a separate process
+func isEval(args []string) bool {
+	return len(args) == 1 && args[0] == gide.EvalArg
+}
+
+// evalRun evaluates the Go snippet on stdin, writing its output to stdout
+// and stderr, and returns the exit code for the process
+func evalRun() int {
+	b, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gide -eval: %v\n", err)
+		return 1
+	}
+	if err := gide.EvalRun(string(b), os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
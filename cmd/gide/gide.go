@@ -6,6 +6,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -18,6 +19,30 @@ import (
 )
 
 func main() {
+	if isEval(os.Args[1:]) {
+		os.Exit(evalRun())
+	}
+	if isBatch(os.Args[1:]) {
+		os.Exit(batchRun(os.Args[1:]))
+	}
+	if isMerge(os.Args[1:]) {
+		oargs, err := mergeArgs(os.Args[1:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gide merge: %v\n", err)
+			os.Exit(1)
+		}
+		os.Args = append(os.Args[:1], oargs...)
+	}
+	if isOpen(os.Args[1:]) {
+		if code, sent := openRun(os.Args[1:]); sent {
+			os.Exit(code)
+		}
+	}
+	if isStdin(os.Args[1:]) {
+		if code, sent := stdinRun(); sent {
+			os.Exit(code)
+		}
+	}
 	gimain.Main(func() {
 		mainrun()
 	})
@@ -37,12 +62,14 @@ Version: ` + gide.Prefs.VersionInfo())
 	// })
 
 	gide.InitPrefs()
+	gide.LoadPlugins()
+	gidev.StartIPCServer()
 
 	var path string
 	var proj string
 
 	// process command args
-	if len(os.Args) > 1 {
+	if len(os.Args) > 1 && !isOpen(os.Args[1:]) && !isStdin(os.Args[1:]) {
 		flag.StringVar(&path, "path", "", "path to open -- can be to a directory or a filename within the directory")
 		flag.StringVar(&proj, "proj", "", "project file to open -- typically has .gide extension")
 		// todo: other args?
@@ -67,6 +94,9 @@ Version: ` + gide.Prefs.VersionInfo())
 		if !inQuitPrompt {
 			inQuitPrompt = true
 			if gidev.QuitReq() {
+				if gidev.IPCServer != nil {
+					gidev.IPCServer.Close()
+				}
 				oswin.TheApp.Quit()
 			} else {
 				inQuitPrompt = false
@@ -74,10 +104,17 @@ Version: ` + gide.Prefs.VersionInfo())
 		}
 	})
 
-	if proj != "" {
+	if isOpen(os.Args[1:]) { // gide was not already running
+		for _, req := range openReqs(os.Args[1:]) {
+			gidev.OpenFilePos(req.Path, req.Ln, req.Col)
+		}
+	} else if isStdin(os.Args[1:]) { // gide was not already running
+		dir, _ := os.Getwd()
+		gidev.OpenScratchText(dir, stdinText)
+	} else if proj != "" {
 		proj, _ = filepath.Abs(proj)
 		gidev.OpenGideProj(proj)
-	} else {
+	} else if path != "" || !gide.Prefs.RestoreProjs || !gidev.RestoreProjs() {
 		if path != "" {
 			path, _ = filepath.Abs(path)
 		}
@@ -0,0 +1,38 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+)
+
+// isMerge returns true if the command args are gide merge local base remote
+// merged, as run by git mergetool, with the mergetool.gide.cmd config set to
+// gide merge "$LOCAL" "$BASE" "$REMOTE" "$MERGED" -- the merged file is
+// opened with its conflicts in the Merge tab
+func isMerge(args []string) bool {
+	return len(args) == 5 && args[0] == "merge"
+}
+
+// mergeArgs writes the merge of the local and remote files to the merged
+// file, with any conflicts marked in the diff3 style, with the base, using
+// git merge-file -- returns the args to open the merged file
+func mergeArgs(args []string) ([]string, error) {
+	local, base, remote, merged := args[1], args[2], args[3], args[4]
+	cmd := exec.Command("git", "merge-file", "-p", "--diff3", "-L", "ours", "-L", "base", "-L", "theirs", local, base, remote)
+	out, err := cmd.Output()
+	if ee, ok := err.(*exec.ExitError); ok && ee.ExitCode() > 0 && ee.ExitCode() < 128 {
+		err = nil // exit code is the number of conflicts
+	}
+	if err != nil {
+		return nil, fmt.Errorf("git merge-file: %v", err)
+	}
+	if err := ioutil.WriteFile(merged, out, 0644); err != nil {
+		return nil, err
+	}
+	return []string{"open", merged}, nil
+}
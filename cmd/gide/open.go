@@ -0,0 +1,43 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/goki/gide/gide"
+)
+
+// isOpen returns true if the command args are gide open file[:line[:col]]...
+func isOpen(args []string) bool {
+	return len(args) > 1 && args[0] == "open"
+}
+
+// openReqs returns the open requests for the file positions in the args
+// after open
+func openReqs(args []string) []*gide.IPCRequest {
+	var reqs []*gide.IPCRequest
+	for _, a := range args[1:] {
+		fp, ln, col := gide.ParseFilePos(a)
+		reqs = append(reqs, &gide.IPCRequest{Cmd: "open", Path: fp, Ln: ln, Col: col})
+	}
+	return reqs
+}
+
+// openRun sends the open requests to the running gide, returning the exit
+// code for the process and false if gide is not running, in which case
+// gide should be started to open the files instead
+func openRun(args []string) (int, bool) {
+	err := gide.SendIPC(openReqs(args)...)
+	switch {
+	case err == gide.ErrNoIPCServer:
+		return 0, false
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "gide open: %v\n", err)
+		return 1, true
+	}
+	return 0, true
+}
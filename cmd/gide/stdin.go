@@ -0,0 +1,46 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/goki/gide/gide"
+)
+
+// isStdin returns true if the command args are gide -, to open the text
+// piped to gide, e.g., some-tool | gide -
+func isStdin(args []string) bool {
+	return len(args) == 1 && args[0] == "-"
+}
+
+// stdinText is the text read from stdin for gide -, opened in a scratch
+// buffer once gide has started
+var stdinText []byte
+
+// stdinRun reads the text piped to gide, and sends it to the running gide
+// to open in a scratch buffer, returning the exit code for the process and
+// false if gide is not running, in which case gide should be started to
+// open it instead
+func stdinRun() (int, bool) {
+	b, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gide -: %v\n", err)
+		return 1, true
+	}
+	stdinText = b
+	dir, _ := os.Getwd()
+	err = gide.SendIPC(&gide.IPCRequest{Cmd: "scratch", Path: dir, Text: string(b)})
+	switch {
+	case err == gide.ErrNoIPCServer:
+		return 0, false
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "gide -: %v\n", err)
+		return 1, true
+	}
+	return 0, true
+}
@@ -0,0 +1,317 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/filecat"
+)
+
+// Abbrev is an abbreviation, or a common misspelling, that is expanded to
+// its text when it is typed as a word followed by a space, punctuation or
+// enter -- undo reverts the expansion in one step
+type Abbrev struct {
+	Abbr  string              `desc:"the abbreviation: a word of letters, digits and _, e.g., teh or fmtp -- if it is in lower case, it is also expanded when typed with an upper case first letter, with the first letter of the text in upper case"`
+	Text  string              `width:"40" desc:"text the abbreviation expands to, e.g., the or fmt.Println( -- if it ends with the char typed after the abbreviation, that char is not doubled"`
+	Langs []filecat.Supported `desc:"languages of the files in which the abbreviation is expanded -- all if empty -- those for the language of a file take precedence over those for all"`
+	Desc  string              `desc:"brief description"`
+}
+
+// Label satisfies the Labeler interface
+func (ab Abbrev) Label() string {
+	return ab.Abbr
+}
+
+// Abbrevs is a list of abbreviations
+type Abbrevs []Abbrev
+
+var KiT_Abbrevs = kit.Types.AddType(&Abbrevs{}, AbbrevsProps)
+
+// AvailAbbrevs are the available abbreviations.  can be loaded / saved /
+// edited with preferences.  This is set to StdAbbrevs at startup.
+var AvailAbbrevs Abbrevs
+
+func init() {
+	AvailAbbrevs.CopyFrom(StdAbbrevs)
+}
+
+// Find returns the text of the abbreviation for files of given language,
+// preferring one for the language over one for all languages -- false if
+// none
+func (al *Abbrevs) Find(abbr string, sup filecat.Supported) (string, bool) {
+	var all *Abbrev
+	for i := range *al {
+		ab := &((*al)[i])
+		if ab.Abbr != abbr {
+			continue
+		}
+		if len(ab.Langs) == 0 {
+			if all == nil {
+				all = ab
+			}
+			continue
+		}
+		if filecat.IsMatchList(ab.Langs, sup) {
+			return ab.Text, true
+		}
+	}
+	if all != nil {
+		return all.Text, true
+	}
+	return "", false
+}
+
+// Expand returns the text the typed word expands to in files of given
+// language (see Find) -- a word with an upper case first letter also
+// matches a lower case abbreviation, with the first letter of the text in
+// upper case -- false if none
+func (al *Abbrevs) Expand(word string, sup filecat.Supported) (string, bool) {
+	if word == "" {
+		return "", false
+	}
+	if txt, ok := al.Find(word, sup); ok {
+		return txt, true
+	}
+	rs := []rune(word)
+	if !unicode.IsUpper(rs[0]) {
+		return "", false
+	}
+	rs[0] = unicode.ToLower(rs[0])
+	txt, ok := al.Find(string(rs), sup)
+	if !ok || txt == "" {
+		return "", false
+	}
+	trs := []rune(txt)
+	trs[0] = unicode.ToUpper(trs[0])
+	return string(trs), true
+}
+
+// AbbrevKeyInput expands the abbreviation (see AvailAbbrevs) typed before
+// the space, punctuation or enter just typed, if Abbrevs is on, as one
+// operation for Undo
+func (tv *TextView) AbbrevKeyInput(kt *key.ChordEvent) {
+	tb := tv.Buf
+	if !tv.Abbrevs || tb == nil || tv.IsInactive() || tv.ISearch.On || tv.QReplace.On {
+		return
+	}
+	pos := tv.CursorPos
+	typed := kt.Rune
+	var tpos giv.TextPos // position of the typed char
+	if kt.Code == key.CodeReturnEnter {
+		if pos.Ln == 0 {
+			return
+		}
+		typed = '\n'
+		tpos = giv.TextPos{Ln: pos.Ln - 1, Ch: tb.LineLen(pos.Ln - 1)}
+	} else {
+		if typed == 0 || unicode.IsControl(typed) || isWordRune(typed) || pos.Ch == 0 {
+			return
+		}
+		tpos = giv.TextPos{Ln: pos.Ln, Ch: pos.Ch - 1}
+		if lt := tb.Line(pos.Ln); tpos.Ch >= len(lt) || lt[tpos.Ch] != typed {
+			return
+		}
+	}
+	// the char must have just been inserted, not, e.g., moved over
+	if tb.UndoPos == 0 || tb.UndoPos > len(tb.Undos) {
+		return
+	}
+	if tbe := tb.Undos[tb.UndoPos-1]; tbe == nil || tbe.Delete || tbe.Reg.Start != tpos {
+		return
+	}
+	lt := tb.Line(tpos.Ln)
+	st := tpos.Ch
+	for st > 0 && isWordRune(lt[st-1]) {
+		st--
+	}
+	if st == tpos.Ch {
+		return
+	}
+	txt, ok := AvailAbbrevs.Expand(string(lt[st:tpos.Ch]), tb.Info.Sup)
+	if !ok {
+		return
+	}
+	ed := tpos
+	if trs := []rune(txt); typed != '\n' && len(trs) > 0 && trs[len(trs)-1] == typed {
+		ed.Ch++ // not doubled
+	}
+	reg := ReplaceRegion(tb, giv.TextRegion{Start: giv.TextPos{Ln: tpos.Ln, Ch: st}, End: ed}, []byte(txt))
+	switch {
+	case typed == '\n':
+		pos.Ln += reg.End.Ln - tpos.Ln
+	case ed != tpos:
+		pos = reg.End
+	default:
+		pos = giv.TextPos{Ln: reg.End.Ln, Ch: reg.End.Ch + pos.Ch - tpos.Ch}
+	}
+	tv.SetCursorShow(pos)
+}
+
+// PrefsAbbrevsFileName is the name of the preferences file in App prefs
+// directory for saving / loading the default AvailAbbrevs
+var PrefsAbbrevsFileName = "abbrevs_prefs.json"
+
+// OpenJSON opens abbreviations from a JSON-formatted file.
+func (al *Abbrevs) OpenJSON(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return err
+	}
+	*al = make(Abbrevs, 0, 10) // reset
+	return json.Unmarshal(b, al)
+}
+
+// SaveJSON saves abbreviations to a JSON-formatted file.
+func (al *Abbrevs) SaveJSON(filename gi.FileName) error {
+	b, err := json.MarshalIndent(al, "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		gi.PromptDialog(nil, gi.DlgOpts{Title: "Could not Save to File", Prompt: err.Error()}, true, false, nil, nil)
+		log.Println(err)
+	}
+	return err
+}
+
+// OpenPrefs opens Abbrevs from App standard prefs directory, using PrefsAbbrevsFileName
+func (al *Abbrevs) OpenPrefs() error {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, PrefsAbbrevsFileName)
+	AvailAbbrevsChanged = false
+	return al.OpenJSON(gi.FileName(pnm))
+}
+
+// SavePrefs saves Abbrevs to App standard prefs directory, using PrefsAbbrevsFileName
+func (al *Abbrevs) SavePrefs() error {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, PrefsAbbrevsFileName)
+	AvailAbbrevsChanged = false
+	return al.SaveJSON(gi.FileName(pnm))
+}
+
+// CopyFrom copies abbreviations from given other list
+func (al *Abbrevs) CopyFrom(cp Abbrevs) {
+	*al = make(Abbrevs, len(cp))
+	copy(*al, cp)
+}
+
+// RevertToStd reverts the abbreviations to the standard compiled-in set
+func (al *Abbrevs) RevertToStd() {
+	al.CopyFrom(StdAbbrevs)
+	AvailAbbrevsChanged = true
+}
+
+// AvailAbbrevsChanged is used to update toolbars via following menu, toolbar
+// props update methods -- not accurate if editing any other list but works for
+// now..
+var AvailAbbrevsChanged = false
+
+// AbbrevsProps define the ToolBar and MenuBar for TableView of Abbrevs
+var AbbrevsProps = ki.Props{
+	"MainMenu": ki.PropSlice{
+		{"AppMenu", ki.BlankProp{}},
+		{"File", ki.PropSlice{
+			{"OpenPrefs", ki.Props{}},
+			{"SavePrefs", ki.Props{
+				"shortcut": "Command+S",
+				"updtfunc": giv.ActionUpdateFunc(func(abi interface{}, act *gi.Action) {
+					act.SetActiveState(AvailAbbrevsChanged && abi.(*Abbrevs) == &AvailAbbrevs)
+				}),
+			}},
+			{"sep-file", ki.BlankProp{}},
+			{"OpenJSON", ki.Props{
+				"label":    "Open from file",
+				"desc":     "You can save and open abbreviations to / from files to share, experiment, transfer, etc",
+				"shortcut": "Command+O",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+			{"SaveJSON", ki.Props{
+				"label": "Save to file",
+				"desc":  "You can save and open abbreviations to / from files to share, experiment, transfer, etc",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+			{"RevertToStd", ki.Props{
+				"desc":    "This reverts the abbreviations to using the StdAbbrevs that are compiled into the program and have all the standard abbreviations",
+				"confirm": true,
+			}},
+		}},
+		{"Edit", "Copy Cut Paste Dupe"},
+		{"Window", "Windows"},
+	},
+	"ToolBar": ki.PropSlice{
+		{"SavePrefs", ki.Props{
+			"desc": "saves Abbrevs to App standard prefs directory, in file abbrevs_prefs.json, which will be loaded automatically at startup)",
+			"icon": "file-save",
+			"updtfunc": giv.ActionUpdateFunc(func(abi interface{}, act *gi.Action) {
+				act.SetActiveState(AvailAbbrevsChanged && abi.(*Abbrevs) == &AvailAbbrevs)
+			}),
+		}},
+		{"sep-file", ki.BlankProp{}},
+		{"OpenJSON", ki.Props{
+			"label": "Open from file",
+			"icon":  "file-open",
+			"desc":  "You can save and open abbreviations to / from files to share, experiment, transfer, etc",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
+		{"SaveJSON", ki.Props{
+			"label": "Save to file",
+			"icon":  "file-save",
+			"desc":  "You can save and open abbreviations to / from files to share, experiment, transfer, etc",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
+		{"sep-std", ki.BlankProp{}},
+		{"RevertToStd", ki.Props{
+			"icon":    "update",
+			"desc":    "This reverts the abbreviations to using the StdAbbrevs that are compiled into the program and have all the standard abbreviations",
+			"confirm": true,
+		}},
+	},
+}
+
+// StdAbbrevs is the original compiled-in set of standard abbreviations:
+// common misspellings in all languages, and a few for Go
+var StdAbbrevs = Abbrevs{
+	{"teh", "the", nil, "misspelling"},
+	{"adn", "and", nil, "misspelling"},
+	{"taht", "that", nil, "misspelling"},
+	{"wich", "which", nil, "misspelling"},
+	{"recieve", "receive", nil, "misspelling"},
+	{"seperate", "separate", nil, "misspelling"},
+	{"occured", "occurred", nil, "misspelling"},
+	{"definately", "definitely", nil, "misspelling"},
+	{"fmtp", "fmt.Println(", []filecat.Supported{filecat.Go}, "print a line"},
+	{"fmtf", "fmt.Printf(", []filecat.Supported{filecat.Go}, "print formatted"},
+	{"fmte", "fmt.Errorf(", []filecat.Supported{filecat.Go}, "formatted error"},
+}
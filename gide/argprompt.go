@@ -0,0 +1,214 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/pi/complete"
+)
+
+// PromptFileVar is the variable under which the {PromptFile*} variables
+// are prompted for, with one file chooser for all of them
+const PromptFileVar = "{PromptFileName}"
+
+// PromptVars are the variables that are prompted for, in the order they
+// are prompted for
+var PromptVars = []string{PromptFileVar, "{PromptString1}", "{PromptString2}"}
+
+// PromptHistMax is the maximum number of values remembered for each prompt
+// of each command
+var PromptHistMax = 20
+
+// PromptHist holds the values entered for the prompts of the commands, most
+// recent first, keyed by the command name and the prompt variable -- the
+// last value is the default the next time the command is run
+type PromptHist map[string][]string
+
+// promptHistKey returns the key of the values of given command and prompt
+func promptHistKey(cmd, pv string) string {
+	return cmd + " " + pv
+}
+
+// Add adds a value entered for the prompt of the command, as the most recent
+func (ph *PromptHist) Add(cmd, pv, val string) {
+	if *ph == nil {
+		*ph = make(PromptHist)
+	}
+	key := promptHistKey(cmd, pv)
+	vals := []string{val}
+	for _, v := range (*ph)[key] {
+		if v != val && len(vals) < PromptHistMax {
+			vals = append(vals, v)
+		}
+	}
+	(*ph)[key] = vals
+}
+
+// Values returns the values entered for the prompt of the command, most
+// recent first
+func (ph *PromptHist) Values(cmd, pv string) []string {
+	return (*ph)[promptHistKey(cmd, pv)]
+}
+
+// Last returns the last value entered for the prompt of the command, "" if
+// none
+func (ph *PromptHist) Last(cmd, pv string) string {
+	if vals := ph.Values(cmd, pv); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// PromptVarDefault splits a prompt variable with a default value, e.g.,
+// {PromptString1:all}, into the variable, {PromptString1}, and the default,
+// all -- other variables are returned as is, with no default
+func PromptVarDefault(vnm string) (string, string) {
+	if !strings.HasPrefix(vnm, "{Prompt") {
+		return vnm, ""
+	}
+	ci := strings.Index(vnm, ":")
+	if ci < 0 {
+		return vnm, ""
+	}
+	return vnm[:ci] + "}", strings.TrimSuffix(vnm[ci+1:], "}")
+}
+
+// ValidatePromptVal returns an error if the value entered for the prompt
+// variable is not valid: strings must not be blank, and files must exist
+func ValidatePromptVal(pv, val string) error {
+	if strings.TrimSpace(val) == "" {
+		return fmt.Errorf("a value is needed for %v", pv)
+	}
+	if pv == PromptFileVar {
+		if _, err := os.Stat(val); err != nil {
+			return fmt.Errorf("file not found: %v", val)
+		}
+	}
+	return nil
+}
+
+// SetPromptFile sets the {PromptFile*} variables for the file at given path,
+// chosen at the prompt, in the project at given root
+func (avp *ArgVarVals) SetPromptFile(fpath, projpath string) {
+	fpath, _ = filepath.Abs(fpath)
+	dirpath, fnm := filepath.Split(fpath)
+	dirpath = filepath.Clean(dirpath)
+	dirrel, _ := filepath.Rel(projpath, dirpath)
+	(*avp)["{PromptFilePath}"] = fpath
+	(*avp)["{PromptFileName}"] = fnm
+	(*avp)["{PromptFileDir}"] = filepath.Base(dirpath)
+	(*avp)["{PromptFileDirPath}"] = dirpath
+	(*avp)["{PromptFileDirProjRel}"] = dirrel
+}
+
+// PromptDefaults returns the default values of the prompts of the command,
+// given as {PromptString1:default} in its commands and args
+func (cm *Command) PromptDefaults() map[string]string {
+	defs := map[string]string{}
+	add := func(arg string) {
+		for _, vnm := range argVarNames(arg) {
+			pv, def := PromptVarDefault(vnm)
+			if def == "" {
+				continue
+			}
+			if strings.HasPrefix(pv, "{PromptFile") {
+				pv = PromptFileVar
+			}
+			defs[pv] = def
+		}
+	}
+	for i := range cm.Cmds {
+		add(cm.Cmds[i].Cmd)
+		for _, av := range cm.Cmds[i].Args {
+			add(av)
+		}
+	}
+	return defs
+}
+
+// PromptNext prompts for the first of the prompt variables, and then for
+// the rest, running the command after the last -- the last value entered
+// for each prompt, or else its default, is the initial value, and invalid
+// values are prompted for again -- canceling a prompt cancels the command
+func (cm *Command) PromptNext(ge Gide, buf *giv.TextBuf, pvs []string, defs map[string]string) {
+	if len(pvs) == 0 {
+		cm.RunAfterPrompts(ge, buf)
+		return
+	}
+	pv := pvs[0]
+	pp := ge.ProjPrefs()
+	avp := ge.ArgVarVals()
+	curval := pp.PromptHist.Last(cm.Name, pv)
+	if curval == "" {
+		curval = defs[pv]
+	}
+	accept := func(val string) {
+		if err := ValidatePromptVal(pv, val); err != nil {
+			gi.PromptDialog(ge.VPort(), gi.DlgOpts{Title: "Invalid Value", Prompt: err.Error()}, gi.AddOk, gi.NoCancel,
+				ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					cm.PromptNext(ge, buf, pvs, defs)
+				})
+			return
+		}
+		if pv == PromptFileVar {
+			avp.SetPromptFile(val, string(pp.ProjRoot))
+		} else {
+			(*avp)[pv] = val
+		}
+		pp.PromptHist.Add(cm.Name, pv, val)
+		cm.PromptNext(ge, buf, pvs[1:], defs)
+	}
+	if pv == PromptFileVar {
+		if curval == "" {
+			curval = (*avp)["{FilePath}"]
+		}
+		giv.FileViewDialog(ge.VPort(), curval, "", giv.DlgOpts{Title: "Gide Command File", Prompt: fmt.Sprintf("Command: %v: %v: choose the file", cm.Name, cm.Desc)}, nil,
+			ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				if sig == int64(gi.DialogAccepted) {
+					accept(giv.FileViewDialogValue(send.(*gi.Dialog)))
+				}
+			})
+		return
+	}
+	dlg := gi.StringPromptDialog(ge.VPort(), curval, "Enter string value here..",
+		gi.DlgOpts{Title: "Gide Command Prompt", Prompt: fmt.Sprintf("Command: %v: %v: %v -- previous values are offered as completions", cm.Name, cm.Desc, strings.Trim(pv, "{}"))},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.DialogAccepted) {
+				accept(gi.StringPromptDialogValue(send.(*gi.Dialog)))
+			}
+		})
+	if tf, ok := dlg.Frame().ChildByName("str-field", 0).(*gi.TextField); ok {
+		tf.SetCompleter(pp.PromptHist.Values(cm.Name, pv), CompletePromptHist, CompletePromptHistEdit)
+	}
+}
+
+// CompletePromptHist supplies the previous values of a prompt, starting
+// with the text entered so far, to the completer
+func CompletePromptHist(data interface{}, text string, posLn, posCh int) (md complete.MatchData) {
+	vals, _ := data.([]string)
+	md.Seed = text
+	for _, v := range vals {
+		if strings.HasPrefix(strings.ToLower(v), strings.ToLower(text)) {
+			md.Matches = append(md.Matches, complete.Completion{Text: v})
+		}
+	}
+	return md
+}
+
+// CompletePromptHistEdit replaces the text of the prompt with the chosen
+// previous value
+func CompletePromptHistEdit(data interface{}, text string, cursorPos int, c complete.Completion, seed string) (ed complete.EditData) {
+	ed.NewText = c.Text
+	ed.ForwardDelete = utf8.RuneCountInString(text) - cursorPos + utf8.RuneCountInString(seed)
+	return ed
+}
@@ -7,8 +7,13 @@ package gide
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/goki/gi/giv"
 	"github.com/goki/gi/oswin"
@@ -37,6 +42,7 @@ var ArgVars = map[string]ArgVarInfo{
 	// Project Root dir
 	"{ProjDir}":  ArgVarInfo{"Current project directory name, without full path.", ArgVarDir},
 	"{ProjPath}": ArgVarInfo{"Full path to current project directory.", ArgVarDir},
+	"{ProjName}": ArgVarInfo{"Name of the project: its .gide project file name without extension, or the project directory name if it has none.", ArgVarText},
 
 	// BuildDir
 	"{BuildDir}":    ArgVarInfo{"Full path to BuildDir specified in project prefs -- the default Build.", ArgVarDir},
@@ -55,7 +61,8 @@ var ArgVars = map[string]ArgVarInfo{
 	"{RunExecDirPathRel}": ArgVarInfo{"Project-root relative path to the directory of the run-time executable file RunExec specified in project prefs.", ArgVarDir},
 
 	// Cursor, Selection
-	"{CurLine}":      ArgVarInfo{"Cursor current line number (starts at 1).", ArgVarPos},
+	"{CurLine}":      ArgVarInfo{"Cursor current line number (starts at 0).", ArgVarPos},
+	"{CurLine1}":     ArgVarInfo{"Cursor current line number, starting at 1 as shown in the editor, for tools that count lines from 1.", ArgVarPos},
 	"{CurCol}":       ArgVarInfo{"Cursor current column number (starts at 0).", ArgVarPos},
 	"{SelStartLine}": ArgVarInfo{"Selection starting line (same as CurLine if no selection).", ArgVarPos},
 	"{SelStartCol}":  ArgVarInfo{"Selection starting column (same as CurCol if no selection).", ArgVarPos},
@@ -66,13 +73,21 @@ var ArgVars = map[string]ArgVarInfo{
 	"{CurLineText}": ArgVarInfo{"Current line text under cursor.", ArgVarText},
 	"{CurWord}":     ArgVarInfo{"Current word under cursor.", ArgVarText},
 
+	"{Selection}":     ArgVarInfo{"Currently selected text, or the current line if there is no selection.", ArgVarText},
+	"{SelectionPath}": ArgVarInfo{"Full path to a temporary file holding the {Selection} text, with the extension of the current file, for commands that read a file.", ArgVarFile},
+
+	// Version control, time
+	"{GitBranch}": ArgVarInfo{"Current git branch of the directory of the current file.", ArgVarText},
+	"{GitHash}":   ArgVarInfo{"Abbreviated hash of the git HEAD commit of the directory of the current file.", ArgVarText},
+	"{DateTime}":  ArgVarInfo{"Current date and time, as 2006-01-02T15:04:05 (local time).", ArgVarText},
+
 	"{PromptFilePath}":       ArgVarInfo{"Prompt user for a file, and this is the full path to that file.", ArgVarPrompt},
 	"{PromptFileName}":       ArgVarInfo{"Prompt user for a file, and this is the filename (only) of that file.", ArgVarPrompt},
 	"{PromptFileDir}":        ArgVarInfo{"Prompt user for a file, and this is the directory name (only) of that file.", ArgVarPrompt},
 	"{PromptFileDirPath}":    ArgVarInfo{"Prompt user for a file, and this is the full path to that directory.", ArgVarPrompt},
 	"{PromptFileDirProjRel}": ArgVarInfo{"Prompt user for a file, and this is the path of that directory relative to the project root.", ArgVarPrompt},
-	"{PromptString1}":        ArgVarInfo{"Prompt user for a string -- this is it.", ArgVarPrompt},
-	"{PromptString2}":        ArgVarInfo{"Prompt user for another string -- this is it.", ArgVarPrompt},
+	"{PromptString1}":        ArgVarInfo{"Prompt user for a string -- this is it -- {PromptString1:default} gives a default value, and previous values are remembered per command.", ArgVarPrompt},
+	"{PromptString2}":        ArgVarInfo{"Prompt user for another string -- this is it -- {PromptString2:default} gives a default value.", ArgVarPrompt},
 }
 
 // ArgVarVals are current values of arg var vals -- updated on demand when a
@@ -104,6 +119,11 @@ func (avp *ArgVarVals) Set(fpath string, ppref *ProjPrefs, tv *giv.TextView) {
 	dirrel, _ := filepath.Rel(projpath, dirpath)
 
 	_, projdir := filepath.Split(projpath)
+	projnm := projdir
+	if ppref.ProjFilename != "" {
+		_, pfnm := filepath.Split(string(ppref.ProjFilename))
+		projnm = strings.TrimSuffix(pfnm, filepath.Ext(pfnm))
+	}
 
 	ext := filepath.Ext(fnm)
 	extlc := strings.ToLower(ext)
@@ -133,6 +153,7 @@ func (avp *ArgVarVals) Set(fpath string, ppref *ProjPrefs, tv *giv.TextView) {
 
 	av["{ProjDir}"] = projdir
 	av["{ProjPath}"] = projpath
+	av["{ProjName}"] = projnm
 
 	av["{BuildDir}"] = bdir
 	av["{BuildDirRel}"] = bdirrel
@@ -147,18 +168,34 @@ func (avp *ArgVarVals) Set(fpath string, ppref *ProjPrefs, tv *giv.TextView) {
 	av["{RunExecDirPath}"] = exepath
 	av["{RunExecDirPathRel}"] = exerel
 
+	for vnm := range ArgVarFuncs {
+		delete(av, vnm) // computed again when next used
+	}
+
 	if tv != nil {
 		av["{CurLine}"] = fmt.Sprintf("%v", tv.CursorPos.Ln)
+		av["{CurLine1}"] = fmt.Sprintf("%v", tv.CursorPos.Ln+1)
 		av["{CurCol}"] = fmt.Sprintf("%v", tv.CursorPos.Ch)             // not quite col
 		av["{SelStartLine}"] = fmt.Sprintf("%v", tv.SelectReg.Start.Ln) // check for no sel
 		av["{SelStartCol}"] = fmt.Sprintf("%v", tv.SelectReg.Start.Ch)
 		av["{SelEndLine}"] = fmt.Sprintf("%v", tv.SelectReg.End.Ln)  // check for no sel
 		av["{SelEndCol}"] = fmt.Sprintf("%v", tv.SelectReg.Start.Ch) // check for no sel
-		av["{CurSel}"] = ""                                          // todo get sel
-		av["{CurLineText}"] = ""                                     // todo get cur line
-		av["{CurWord}"] = ""                                         // todo get word
+		av["{CurSel}"] = ""
+		av["{CurLineText}"] = ""
+		av["{CurWord}"] = "" // todo get word
+		if tv.Buf != nil && tv.CursorPos.Ln < tv.Buf.NumLines() {
+			av["{CurLineText}"] = string(tv.Buf.BytesLine(tv.CursorPos.Ln))
+			if sel := tv.Selection(); sel != nil {
+				av["{CurSel}"] = string(sel.ToBytes())
+			}
+		}
+		av["{Selection}"] = av["{CurSel}"]
+		if av["{Selection}"] == "" {
+			av["{Selection}"] = av["{CurLineText}"]
+		}
 	} else {
 		av["{CurLine}"] = ""
+		av["{CurLine1}"] = ""
 		av["{CurCol}"] = ""
 		av["{SelStartLine}"] = ""
 		av["{SelStartCol}"] = ""
@@ -167,7 +204,101 @@ func (avp *ArgVarVals) Set(fpath string, ppref *ProjPrefs, tv *giv.TextView) {
 		av["{CurSel}"] = ""
 		av["{CurLineText}"] = ""
 		av["{CurWord}"] = ""
+		av["{Selection}"] = ""
+	}
+}
+
+// ArgVarDateTimeFormat is the time format of {DateTime}
+var ArgVarDateTimeFormat = "2006-01-02T15:04:05"
+
+// ArgVarFuncs compute the values of the arg vars that are only computed when
+// a command uses them, e.g., because they run git -- the value is then kept
+// until the values are Set again
+var ArgVarFuncs = map[string]func(avp *ArgVarVals) string{
+	"{GitBranch}": func(avp *ArgVarVals) string {
+		return argVarGit(avp, "rev-parse", "--abbrev-ref", "HEAD")
+	},
+	"{GitHash}": func(avp *ArgVarVals) string {
+		return argVarGit(avp, "rev-parse", "--short", "HEAD")
+	},
+	"{DateTime}": func(avp *ArgVarVals) string {
+		return time.Now().Format(ArgVarDateTimeFormat)
+	},
+	"{SelectionPath}": (*ArgVarVals).SelectionFile,
+}
+
+// argVarGit returns the output of git with given args, run in the
+// directory of the current file -- "" if it fails
+func argVarGit(avp *ArgVarVals, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = (*avp)["{FileDirPath}"]
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// SelectionFile writes the {Selection} text to a temporary file, with the
+// extension of the current file, and returns its path -- the command using
+// it removes it when it is finished (see RemoveSelectionFile)
+func (avp *ArgVarVals) SelectionFile() string {
+	f, err := ioutil.TempFile("", "gide-sel-*"+(*avp)["{FileExt}"])
+	if err != nil {
+		log.Println(err)
+		return ""
+	}
+	defer f.Close()
+	if _, err := f.WriteString((*avp)["{Selection}"]); err != nil {
+		log.Println(err)
+	}
+	return f.Name()
+}
+
+// RemoveSelectionFile removes the temporary file of {SelectionPath} at the
+// given path, if it was written -- called when the command using it is
+// finished
+func RemoveSelectionFile(fpath string) {
+	if fpath != "" {
+		os.Remove(fpath)
+	}
+}
+
+// ArgVarEnvPrefix starts the variables whose value is that of an
+// environment variable, e.g., {Env:HOME} -- they are not among the ArgVars,
+// as any name can follow it
+const ArgVarEnvPrefix = "{Env:"
+
+// ArgVarEnvName returns the name of the environment variable of the
+// variable, e.g., HOME for {Env:HOME}, and false if it is not one
+func ArgVarEnvName(vnm string) (string, bool) {
+	if !strings.HasPrefix(vnm, ArgVarEnvPrefix) || !strings.HasSuffix(vnm, "}") {
+		return "", false
 	}
+	nm := vnm[len(ArgVarEnvPrefix) : len(vnm)-1]
+	return nm, nm != ""
+}
+
+// Value returns the value of the variable, e.g., {FilePath}, including those
+// computed when used (ArgVarFuncs), environment variables, {Env:NAME}, and
+// prompt variables with a default -- false if it is not a known variable
+func (avp *ArgVarVals) Value(vnm string) (string, bool) {
+	if val, ok := (*avp)[vnm]; ok {
+		return val, true
+	}
+	if fun, ok := ArgVarFuncs[vnm]; ok {
+		val := fun(avp)
+		(*avp)[vnm] = val
+		return val, true
+	}
+	if nm, ok := ArgVarEnvName(vnm); ok {
+		return os.Getenv(nm), true
+	}
+	if pv, def := PromptVarDefault(vnm); def != "" {
+		val, ok := (*avp)[pv]
+		return val, ok
+	}
+	return "", false
 }
 
 // Bind replaces the variables in the given arg string with their values
@@ -194,7 +325,7 @@ func (avp *ArgVarVals) Bind(arg string) string {
 		eb += ci + 1
 		vnm := string(bs[ci : eb+1])
 		// fmt.Printf("%v\n", vnm)
-		if val, ok := (*avp)[vnm]; ok {
+		if val, ok := avp.Value(vnm); ok {
 			end := make([]byte, sz-(eb+1))
 			copy(end, bs[eb+1:])
 			bs = append(bs[:ci], []byte(val)...)
@@ -217,10 +348,11 @@ func (avp *ArgVarVals) Bind(arg string) string {
 	return string(bs)
 }
 
-// ArgVarPrompts returns any Prompt* variables required by this string, false if none
-func ArgVarPrompts(arg string) (map[string]struct{}, bool) {
+// argVarNames returns the names of the variables in the given arg string,
+// including the braces, skipping those quoted with a backslash
+func argVarNames(arg string) []string {
+	var vns []string
 	sz := len(arg)
-	var ps map[string]struct{}
 	bs := []byte(arg)
 	ci := 0
 	for ci < sz {
@@ -238,20 +370,30 @@ func ArgVarPrompts(arg string) (map[string]struct{}, bool) {
 			break
 		}
 		eb += ci + 1
-		vnm := string(bs[ci : eb+1])
-		// fmt.Printf("%v\n", vnm)
-		if strings.HasPrefix(vnm, "{Prompt") {
-			if ps == nil {
-				ps = make(map[string]struct{})
-			}
-			if strings.HasPrefix(vnm, "{PromptFile") {
-				ps["{PromptFileName}"] = struct{}{}
-			} else {
-				ps[vnm] = struct{}{}
-			}
-		}
+		vns = append(vns, string(bs[ci:eb+1]))
 		ci = eb
 	}
+	return vns
+}
+
+// ArgVarPrompts returns any Prompt* variables required by this string, false if none
+// -- variables with a default, e.g., {PromptString1:all}, are returned without it
+func ArgVarPrompts(arg string) (map[string]struct{}, bool) {
+	var ps map[string]struct{}
+	for _, vnm := range argVarNames(arg) {
+		if !strings.HasPrefix(vnm, "{Prompt") {
+			continue
+		}
+		if ps == nil {
+			ps = make(map[string]struct{})
+		}
+		if strings.HasPrefix(vnm, "{PromptFile") {
+			ps[PromptFileVar] = struct{}{}
+		} else {
+			pv, _ := PromptVarDefault(vnm)
+			ps[pv] = struct{}{}
+		}
+	}
 	if len(ps) > 0 {
 		return ps, true
 	}
@@ -61,4 +61,29 @@ func TestBind(t *testing.T) {
 	if bv != cv {
 		t.Errorf("bind error: should have been: %v  was: %v\n", cv, bv)
 	}
+
+	bv = avp.Bind("{CurLine} {CurLine1}")
+	cv = "22 23"
+	if bv != cv {
+		t.Errorf("bind error: should have been: %v  was: %v\n", cv, bv)
+	}
+}
+
+func TestArgProblems(t *testing.T) {
+	for _, arg := range []string{"{FilePath}", "{FileDir}/{FileName}", "{Env:HOME}", "{PromptString1:all}", "\\{FilNam}", "no vars"} {
+		if probs := ArgProblems(arg); len(probs) != 0 {
+			t.Errorf("ArgProblems(%q) should have none, got: %v\n", arg, probs)
+		}
+	}
+	probs := ArgProblems("-o={FilNam}")
+	cv := "unknown variable {FilNam}, did you mean {FileName}?"
+	if len(probs) != 1 || probs[0] != cv {
+		t.Errorf("ArgProblems should have been: %v  was: %v\n", cv, probs)
+	}
+	if probs := ArgProblems("{FilePath} {Env:"); len(probs) != 1 {
+		t.Errorf("ArgProblems should have found the unclosed {, got: %v\n", probs)
+	}
+	if probs := ArgProblems("{Env:} {Xyzzy}"); len(probs) != 2 {
+		t.Errorf("ArgProblems should have found 2 unknown variables, got: %v\n", probs)
+	}
 }
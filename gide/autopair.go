@@ -0,0 +1,138 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/pi/filecat"
+)
+
+// PairPrefs are the prefs for the auto-pairing of brackets and quotes in
+// the editor
+type PairPrefs struct {
+	On    bool                `desc:"insert the closing char of a pair when its opening char is typed, before a space, the end of the line or another closing char (quotes not after a letter or digit), type over the closing char when it is typed at it, delete an empty pair with backspace, and wrap the selection in the pair when its opening char is typed"`
+	Pairs string              `desc:"the pairs of opening and closing chars, e.g., ()[]{}\"\" -- quotes open and close with the same char"`
+	Off   []filecat.Supported `desc:"languages of the files in which pairs are not auto-inserted -- not even ({[ as by default"`
+}
+
+// Defaults are the defaults for PairPrefs
+func (pp *PairPrefs) Defaults() {
+	pp.On = true
+	pp.Pairs = "()[]{}\"\"''``"
+}
+
+// Close returns the closing char of the pair with given opening char --
+// false if there is none
+func (pp *PairPrefs) Close(r rune) (rune, bool) {
+	prs := []rune(pp.Pairs)
+	for i := 0; i+1 < len(prs); i += 2 {
+		if prs[i] == r {
+			return prs[i+1], true
+		}
+	}
+	return 0, false
+}
+
+// IsClose returns true if the char is the closing char of a pair
+func (pp *PairPrefs) IsClose(r rune) bool {
+	prs := []rune(pp.Pairs)
+	for i := 1; i < len(prs); i += 2 {
+		if prs[i] == r {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOn returns true if pairs are auto-inserted in files of given language
+func (pp *PairPrefs) IsOn(sup filecat.Supported) bool {
+	if !pp.On {
+		return false
+	}
+	for _, s := range pp.Off {
+		if s == sup {
+			return false
+		}
+	}
+	return true
+}
+
+// PairKeyInput handles the typing of the chars of pairs, per PairPrefs:
+// wraps the selection in the pair of an opening char, types over a closing
+// char at the cursor, inserts the closing char after an opening one, and
+// deletes an empty pair with backspace -- or if pairs are off for the
+// language, inserts ({[ without their closing char
+func (tv *TextView) PairKeyInput(kt *key.ChordEvent) {
+	pp := tv.PairPrefs
+	tb := tv.Buf
+	if pp == nil || tb == nil || kt.IsProcessed() || tv.IsInactive() || tv.ISearch.On || tv.QReplace.On {
+		return
+	}
+	if kt.HasAnyModifier(key.Control, key.Meta) {
+		return
+	}
+	r := kt.Rune
+	if !pp.IsOn(tb.Info.Sup) {
+		if r == '(' || r == '[' || r == '{' {
+			kt.SetProcessed()
+			tv.InsertAtCursor([]byte(string(r)))
+		}
+		return
+	}
+	pos := tv.CursorPos
+	lt := tb.Line(pos.Ln)
+	var prv, nxt rune
+	if pos.Ch > 0 && pos.Ch <= len(lt) {
+		prv = lt[pos.Ch-1]
+	}
+	if pos.Ch < len(lt) {
+		nxt = lt[pos.Ch]
+	}
+	if gi.KeyFun(kt.Chord()) == gi.KeyFunBackspace {
+		if cr, ok := pp.Close(prv); ok && cr == nxt && !tv.HasSelection() {
+			kt.SetProcessed()
+			tb.DeleteText(giv.TextPos{Ln: pos.Ln, Ch: pos.Ch - 1}, giv.TextPos{Ln: pos.Ln, Ch: pos.Ch + 1}, true, true)
+			tv.SetCursorShow(giv.TextPos{Ln: pos.Ln, Ch: pos.Ch - 1})
+		}
+		return
+	}
+	if r == 0 || !unicode.IsPrint(r) {
+		return
+	}
+	cr, open := pp.Close(r)
+	if open && tv.HasSelection() {
+		kt.SetProcessed()
+		sel := tv.Selection()
+		txt := string(r) + string(sel.ToBytes()) + string(cr)
+		reg := ReplaceRegion(tb, sel.Reg, []byte(txt))
+		reg.Start.Ch++
+		if reg.End.Ch > 0 {
+			reg.End.Ch--
+		}
+		tv.SelectRegionShow(reg)
+		return
+	}
+	if pp.IsClose(r) && nxt == r {
+		kt.SetProcessed()
+		tv.SetCursorShow(giv.TextPos{Ln: pos.Ln, Ch: pos.Ch + 1})
+		return
+	}
+	if !open {
+		return
+	}
+	if nxt != 0 && !unicode.IsSpace(nxt) && !pp.IsClose(nxt) {
+		return
+	}
+	if cr == r && (isWordRune(prv) || prv == r) { // e.g., an apostrophe
+		return
+	}
+	kt.SetProcessed()
+	tb.InsertText(pos, []byte(string(r)+string(cr)), true, true)
+	tv.SetCursorShow(giv.TextPos{Ln: pos.Ln, Ch: pos.Ch + 1})
+}
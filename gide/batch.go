@@ -0,0 +1,126 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/filecat"
+)
+
+// Batch is a gide project without any gui, for running commands and
+// project-wide find / replace from the command line (gide -batch) or
+// scripts.  It uses the same project prefs (.gide file) as the gui if the
+// project has one.
+type Batch struct {
+	Project
+	Out io.Writer `desc:"where command output and find results are written"`
+}
+
+// OpenBatch opens a batch project at given path, which can be a .gide
+// project file, a directory, or a file within the project directory --
+// custom commands are loaded from the gide prefs
+func OpenBatch(path string, out io.Writer) (*Batch, error) {
+	b := &Batch{Out: out}
+	if strings.ToLower(filepath.Ext(path)) != ".gide" {
+		if gproj, has := CheckForProjAtPath(path); has {
+			path = gproj
+		}
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".gide" {
+		if _, err := b.OpenProj(gi.FileName(path)); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, _, ok := b.OpenPath(path); !ok {
+			return nil, fmt.Errorf("gide.OpenBatch: cannot open path: %v", path)
+		}
+	}
+	if err := CustomCmds.OpenPrefs(); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(out, "gide: could not open custom commands: %v\n", err)
+	}
+	b.ArgVals.Set("", &b.Prefs, nil)
+	return b, nil
+}
+
+// RunCmd runs the named command for the given file (project root if empty),
+// writing its output to Out -- see Project.RunCmd
+func (b *Batch) RunCmd(name CmdName, fpath string) error {
+	return b.Project.RunCmd(name, fpath, b.Out)
+}
+
+// BatchFindResult is the result of a Batch Find for one file
+type BatchFindResult struct {
+	FPath   string
+	Count   int
+	Matches []giv.FileSearchMatch
+}
+
+// Find searches the project files of given languages (all if empty) for the
+// given string, writing matches to Out, as file:line:col: text, and
+// returns the results, sorted in descending order by number of occurrences
+func (b *Batch) Find(find string, ignoreCase bool, langs []filecat.Supported) []BatchFindResult {
+	if find == "" {
+		return nil
+	}
+	res := b.FindFiles(find, ignoreCase, langs)
+	tot := 0
+	for _, fs := range res {
+		rp, _ := filepath.Rel(string(b.Prefs.ProjRoot), fs.FPath)
+		for _, m := range fs.Matches {
+			fmt.Fprintf(b.Out, "%v:%d:%d: %s\n", rp, m.Reg.Start.Ln+1, m.Reg.Start.Ch+1, bytes.TrimSpace(m.Text))
+		}
+		tot += fs.Count
+	}
+	fmt.Fprintf(b.Out, "Find: %q: %v matches in %v files\n", find, tot, len(res))
+	return res
+}
+
+// Replace replaces all occurrences of the find string with repl in the
+// project files of given languages (all if empty), writing each changed
+// file and a summary to Out -- returns the total number of replacements
+func (b *Batch) Replace(find, repl string, ignoreCase bool, langs []filecat.Supported) (int, error) {
+	if find == "" {
+		return 0, nil
+	}
+	rs := regexp.QuoteMeta(find)
+	if ignoreCase {
+		rs = "(?i)" + rs
+	}
+	re := regexp.MustCompile(rs)
+	tot, nfl := 0, 0
+	for _, fp := range b.ProjFiles(langs) {
+		src, err := ioutil.ReadFile(fp)
+		if err != nil {
+			return tot, err
+		}
+		n := len(re.FindAllIndex(src, -1))
+		if n == 0 {
+			continue
+		}
+		fi, err := os.Stat(fp)
+		if err != nil {
+			return tot, err
+		}
+		if err := ioutil.WriteFile(fp, re.ReplaceAllLiteral(src, []byte(repl)), fi.Mode()); err != nil {
+			return tot, err
+		}
+		rp, _ := filepath.Rel(string(b.Prefs.ProjRoot), fp)
+		fmt.Fprintf(b.Out, "%v: %v replaced\n", rp, n)
+		tot += n
+		nfl++
+	}
+	fmt.Fprintf(b.Out, "Replace: %q with %q: %v replaced in %v files\n", find, repl, tot, nfl)
+	return tot, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBatchFindReplace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".git"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\n// Foo is foo\nfunc Foo() {}\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("call foo and FOO\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, ".git", "c.txt"), []byte("foo\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "d.bin"), []byte("foo\x00"), 0644)
+
+	var out bytes.Buffer
+	b, err := OpenBatch(dir, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := b.Find("foo", false, nil)
+	if len(res) != 2 || res[0].Count != 1 || res[1].Count != 1 {
+		t.Errorf("find error: should have found 1 match in each of 2 files, got: %v\n", res)
+	}
+	res = b.Find("foo", true, nil)
+	if len(res) != 2 || res[0].FPath != filepath.Join(dir, "a.go") || res[0].Count != 3 || res[1].Count != 2 {
+		t.Errorf("find ignore case error: should have found 3 matches in a.go then 2 in sub/b.txt, got: %v\n", res)
+	}
+
+	n, err := b.Replace("foo", "bar", true, nil)
+	if err != nil || n != 5 {
+		t.Errorf("replace error: should have replaced 5, replaced: %v err: %v\n", n, err)
+	}
+	bs, _ := ioutil.ReadFile(filepath.Join(dir, "sub", "b.txt"))
+	if string(bs) != "call bar and bar\n" {
+		t.Errorf("replace error: sub/b.txt should be: call bar and bar, was: %q\n", bs)
+	}
+	bs, _ = ioutil.ReadFile(filepath.Join(dir, ".git", "c.txt"))
+	if string(bs) != "foo\n" {
+		t.Errorf("replace error: hidden dir .git should not have been changed, was: %q\n", bs)
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"image"
+	"image/draw"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/mouse"
+)
+
+// RainbowColors are the colors of brackets by nesting depth when rainbow
+// brackets are on -- the colors repeat for deeper nesting
+var RainbowColors = []gi.Color{
+	{0xd7, 0x9a, 0x00, 0xff}, // gold
+	{0xb0, 0x4c, 0xc8, 0xff}, // orchid
+	{0x1e, 0x88, 0xe5, 0xff}, // blue
+	{0x2e, 0x9d, 0x5a, 0xff}, // green
+	{0xe0, 0x6c, 0x1c, 0xff}, // orange
+	{0x00, 0x9c, 0xa6, 0xff}, // teal
+}
+
+// UnmatchedBracketColor is the color of brackets that have no match, when
+// rainbow brackets are on
+var UnmatchedBracketColor = gi.Color{0xce, 0x42, 0x52, 0xff}
+
+// BracketMaxLines is the maximum number of lines in a file for brackets to
+// be matched and colored -- the whole file is scanned on each change
+var BracketMaxLines = 50000
+
+// BracketSyntax is the comment and quote syntax used to skip over brackets
+// in comments and strings, which is all that is needed to match brackets in
+// any language
+type BracketSyntax struct {
+	CommentLn string `desc:"characters that start a comment to the end of the line"`
+	CommentSt string `desc:"characters that start a multi-line comment"`
+	CommentEd string `desc:"characters that end a multi-line comment"`
+	Quotes    string `desc:"characters that start and end strings -- strings must end on the same line, otherwise the quote is taken as a regular char (e.g., an apostrophe)"`
+}
+
+// BufBracketSyntax returns the bracket syntax for the buffer, from its
+// comment options
+func BufBracketSyntax(tb *giv.TextBuf) BracketSyntax {
+	syn := BracketSyntax{CommentLn: tb.Opts.CommentLn, Quotes: "\"'`"}
+	if tb.Opts.CommentSt != "" && tb.Opts.CommentEd != "" {
+		syn.CommentSt = tb.Opts.CommentSt
+		syn.CommentEd = tb.Opts.CommentEd
+	}
+	return syn
+}
+
+// Bracket is one of (){}[] in the text, outside of comments and strings
+type Bracket struct {
+	Pos   giv.TextPos `desc:"position of the bracket"`
+	Rune  rune        `desc:"the bracket"`
+	Depth int         `desc:"nesting depth of the pair, starting at 0 -- -1 if unmatched"`
+	Match int         `desc:"index of the matching bracket, or -1 if unmatched"`
+}
+
+// IsOpen returns true if this is an opening bracket
+func (br *Bracket) IsOpen() bool {
+	return br.Rune == '(' || br.Rune == '{' || br.Rune == '['
+}
+
+// BracketOpenFor returns the opening bracket for given closing bracket
+func BracketOpenFor(r rune) rune {
+	switch r {
+	case ')':
+		return '('
+	case '}':
+		return '{'
+	case ']':
+		return '['
+	}
+	return 0
+}
+
+// runesAt returns true if lt has the runes of s at ch
+func runesAt(lt []rune, ch int, s []rune) bool {
+	if len(s) == 0 || ch+len(s) > len(lt) {
+		return false
+	}
+	for i, r := range s {
+		if lt[ch+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteEnd returns the index of the quote ending the string started by the
+// quote at ch, or -1 if it does not end on the line -- backslash escapes
+// are skipped, except in back-quoted strings
+func quoteEnd(lt []rune, ch int) int {
+	q := lt[ch]
+	for i := ch + 1; i < len(lt); i++ {
+		switch {
+		case lt[i] == '\\' && q != '`':
+			i++
+		case lt[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+// scanCode calls fun for each position in the lines outside of comments and
+// strings, in order
+func scanCode(lines [][]rune, syn *BracketSyntax, fun func(ln, ch int, r rune)) {
+	cln := []rune(syn.CommentLn)
+	cst := []rune(syn.CommentSt)
+	ced := []rune(syn.CommentEd)
+	incmt := false
+	for ln, lt := range lines {
+		for ch := 0; ch < len(lt); ch++ {
+			if incmt {
+				if runesAt(lt, ch, ced) {
+					incmt = false
+					ch += len(ced) - 1
+				}
+				continue
+			}
+			if runesAt(lt, ch, cln) {
+				break
+			}
+			if len(ced) > 0 && runesAt(lt, ch, cst) {
+				incmt = true
+				ch += len(cst) - 1
+				continue
+			}
+			r := lt[ch]
+			if strings.ContainsRune(syn.Quotes, r) {
+				if qe := quoteEnd(lt, ch); qe > 0 {
+					ch = qe
+				}
+				continue
+			}
+			fun(ln, ch, r)
+		}
+	}
+}
+
+// ScanBrackets returns all the brackets in the lines, in order, with their
+// nesting depth and matches, skipping those in comments and strings
+func ScanBrackets(lines [][]rune, syn *BracketSyntax) []Bracket {
+	var brs []Bracket
+	var stack []int
+	scanCode(lines, syn, func(ln, ch int, r rune) {
+		br := Bracket{Pos: giv.TextPos{Ln: ln, Ch: ch}, Rune: r, Depth: -1, Match: -1}
+		switch r {
+		case '(', '{', '[':
+			br.Depth = len(stack)
+			stack = append(stack, len(brs))
+		case ')', '}', ']':
+			if n := len(stack); n > 0 && brs[stack[n-1]].Rune == BracketOpenFor(r) {
+				oi := stack[n-1]
+				stack = stack[:n-1]
+				br.Depth = brs[oi].Depth
+				br.Match = oi
+				brs[oi].Match = len(brs)
+			}
+		default:
+			return
+		}
+		brs = append(brs, br)
+	})
+	for _, oi := range stack {
+		brs[oi].Depth = -1
+	}
+	return brs
+}
+
+// BracketIndex returns the index of the bracket at given position, or -1
+// if there is none
+func BracketIndex(brs []Bracket, pos giv.TextPos) int {
+	i := sort.Search(len(brs), func(i int) bool {
+		return !brs[i].Pos.IsLess(pos)
+	})
+	if i < len(brs) && brs[i].Pos == pos {
+		return i
+	}
+	return -1
+}
+
+// EnclosingBracket returns the index of the opening bracket of the
+// innermost pair enclosing given position, or -1 if there is none
+func EnclosingBracket(brs []Bracket, pos giv.TextPos) int {
+	i := sort.Search(len(brs), func(i int) bool {
+		return !brs[i].Pos.IsLess(pos)
+	})
+	for i--; i >= 0; i-- {
+		br := &brs[i]
+		if br.IsOpen() && br.Match >= 0 && !brs[br.Match].Pos.IsLess(pos) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ScanBufBrackets updates the Brackets of the view from its buffer
+func (tv *TextView) ScanBufBrackets() {
+	tv.Brackets = nil
+	if tv.Buf == nil || tv.Buf.NumLines() > BracketMaxLines {
+		return
+	}
+	syn := BufBracketSyntax(tv.Buf)
+	tv.Buf.LinesMu.RLock()
+	tv.Brackets = ScanBrackets(tv.Buf.Lines, &syn)
+	tv.Buf.LinesMu.RUnlock()
+}
+
+// CursorBracket returns the index in Brackets of the bracket at the
+// cursor, or else just before it, or -1 if there is none
+func (tv *TextView) CursorBracket() int {
+	cp := tv.CursorPos
+	if bi := BracketIndex(tv.Brackets, cp); bi >= 0 {
+		return bi
+	}
+	if cp.Ch > 0 {
+		return BracketIndex(tv.Brackets, giv.TextPos{Ln: cp.Ln, Ch: cp.Ch - 1})
+	}
+	return -1
+}
+
+// MatchBrackets highlights the bracket at or just before the cursor and its
+// match, skipping brackets in comments and strings -- replaces the plain
+// highlighting of the bracket at the cursor done by the base view
+func (tv *TextView) MatchBrackets() {
+	if tv.Buf == nil || tv.NLines == 0 || tv.Viewport == nil || tv.Viewport.Win == nil {
+		return
+	}
+	tv.ScanBufBrackets()
+	var sl []giv.TextRegion
+	if bi := tv.CursorBracket(); bi >= 0 && tv.Brackets[bi].Match >= 0 {
+		for _, br := range []Bracket{tv.Brackets[bi], tv.Brackets[tv.Brackets[bi].Match]} {
+			sl = append(sl, giv.NewTextRegionPos(br.Pos, giv.TextPos{Ln: br.Pos.Ln, Ch: br.Pos.Ch + 1}))
+		}
+	}
+	if len(sl) == len(tv.Scopelights) {
+		same := true
+		for i, reg := range sl {
+			if reg.Start != tv.Scopelights[i].Start || reg.End != tv.Scopelights[i].End {
+				same = false
+				break
+			}
+		}
+		if same {
+			return
+		}
+	}
+	old := tv.Scopelights
+	tv.Scopelights = sl
+	updt := tv.Viewport.Win.UpdateStart()
+	for _, reg := range append(old, sl...) {
+		tv.RenderLines(reg.Start.Ln, reg.Start.Ln)
+	}
+	tv.Viewport.Win.UpdateEnd(updt)
+}
+
+// JumpBracket moves the cursor to the bracket matching the one at or just
+// before the cursor, or else to the opening bracket of the innermost pair
+// enclosing the cursor
+func (tv *TextView) JumpBracket() {
+	if tv.Buf == nil {
+		return
+	}
+	tv.ScanBufBrackets()
+	bi := tv.CursorBracket()
+	if bi >= 0 && tv.Brackets[bi].Match >= 0 {
+		bi = tv.Brackets[bi].Match
+	} else {
+		bi = EnclosingBracket(tv.Brackets, tv.CursorPos)
+	}
+	if bi < 0 {
+		return
+	}
+	tv.SavePosHistory(tv.CursorPos)
+	tv.SetCursorShow(tv.Brackets[bi].Pos)
+	tv.SetCursorCol(tv.CursorPos)
+	tv.MatchBrackets()
+	tv.UpdateOverlays()
+}
+
+// BracketMouseInput matches brackets after the cursor is set by a click
+func (tv *TextView) BracketMouseInput(me *mouse.Event) {
+	if me.Action != mouse.Press || tv.Buf == nil {
+		return
+	}
+	tv.MatchBrackets()
+	tv.UpdateOccurs()
+	tv.UpdateOverlays()
+}
+
+// RenderBrackets draws the visible brackets in the RainbowColors by their
+// nesting depth, if Rainbow is on -- each bracket is drawn over its cell
+// filled with the background, so this can be redrawn at any time
+func (tv *TextView) RenderBrackets() {
+	if !tv.Rainbow || len(RainbowColors) == 0 || tv.Buf == nil || tv.NLines == 0 || tv.Viewport == nil || tv.VpBBox.Empty() {
+		return
+	}
+	if len(tv.Renders) < tv.NLines || len(tv.Offs) < tv.NLines {
+		return
+	}
+	tv.ScanBufBrackets()
+	st := tv.FirstVisibleLine(0)
+	ed := tv.LastVisibleLine(st)
+	bi := sort.Search(len(tv.Brackets), func(i int) bool {
+		return tv.Brackets[i].Pos.Ln >= st
+	})
+	sty := &tv.Sty
+	fst := sty.Font
+	fst.BgColor.SetColor(nil)
+	chw := sty.Font.Face.Metrics.Ch
+	met := sty.Font.Face.Face.Metrics()
+	boff := gi.FixedToFloat32(met.Ascent) - gi.FixedToFloat32(met.Descent)
+	img := tv.Viewport.Pixels
+	rs := &tv.Viewport.Render
+	tbb := tv.VpBBox
+	tbb.Min.X += int(tv.LineNoOff)
+	rs.PushBounds(tbb)
+	rs.Lock()
+	var tr gi.TextRender
+	for ; bi < len(tv.Brackets) && tv.Brackets[bi].Pos.Ln <= ed; bi++ {
+		br := &tv.Brackets[bi]
+		if br.Pos.Ln >= tv.NLines {
+			break
+		}
+		pos := tv.CharStartPos(br.Pos)
+		ex := pos.X + chw
+		if epos := tv.CharStartPos(giv.TextPos{Ln: br.Pos.Ln, Ch: br.Pos.Ch + 1}); epos.Y == pos.Y && epos.X > pos.X {
+			ex = epos.X
+		}
+		cell := image.Rect(int(pos.X), int(pos.Y), int(ex+0.5), int(pos.Y+tv.LineHeight)).Intersect(tbb)
+		if cell.Empty() {
+			continue
+		}
+		// sample the background at the top right, clear of the glyph and cursor
+		bg := img.At(cell.Max.X-1, cell.Min.Y)
+		draw.Draw(img, cell, image.NewUniform(bg), image.ZP, draw.Src)
+		if br.Depth < 0 {
+			fst.Color = UnmatchedBracketColor
+		} else {
+			fst.Color = RainbowColors[br.Depth%len(RainbowColors)]
+		}
+		tr.SetString(string(br.Rune), &fst, &sty.UnContext, &sty.Text, true, 0, 0)
+		tr.Render(rs, gi.Vec2D{X: pos.X, Y: pos.Y + boff})
+	}
+	rs.Unlock()
+	rs.PopBounds()
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultBroadcastPort is the default port for broadcasting the active view
+var DefaultBroadcastPort = 8090
+
+// BroadcastInterval is how often the active view is checked for changes to
+// send to followers
+var BroadcastInterval = 250 * time.Millisecond
+
+// BroadcastPollTimeout is how long a follower's request for the state waits
+// for a change before returning the current state
+var BroadcastPollTimeout = 20 * time.Second
+
+// FollowState is the state of the broadcast view sent to followers: the
+// file and its text, the cursor position, and the range of visible lines
+type FollowState struct {
+	Version  int    `json:"version" desc:"incremented on every change"`
+	TextVers int    `json:"textVers" desc:"incremented when the file or its text changes"`
+	File     string `json:"file" desc:"file being viewed, relative to the project root"`
+	Text     string `json:"text,omitempty" desc:"text of the file -- only sent if the follower does not have the current TextVers"`
+	Line     int    `json:"line" desc:"cursor line (0-based)"`
+	Col      int    `json:"col" desc:"cursor column (0-based, in chars)"`
+	Top      int    `json:"top" desc:"first visible line"`
+	Bottom   int    `json:"bottom" desc:"last visible line"`
+}
+
+// Broadcaster serves the state of the active view read-only over the
+// network, for following by another gide (Follow Broadcast) or a browser --
+// followers long-poll for changes, and must have the random Token, which is
+// included in the share URLs
+type Broadcaster struct {
+	Port    int           `desc:"port the server is listening on, on all interfaces"`
+	Token   string        `desc:"access token required from followers"`
+	Server  *http.Server  `desc:"the http server"`
+	Mu      sync.Mutex    `desc:"mutex protecting the State"`
+	State   FollowState   `desc:"current state, with the full text"`
+	Changed chan struct{} `desc:"closed and replaced when the state changes, to wake waiting followers"`
+}
+
+// StartBroadcast starts serving the broadcast state on given port on all
+// interfaces -- if the port is 0, or it is in use, a free port is used
+func StartBroadcast(port int) (*Broadcaster, error) {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil && port != 0 {
+		ln, err = net.Listen("tcp", ":0")
+	}
+	if err != nil {
+		return nil, err
+	}
+	tok := make([]byte, 16)
+	rand.Read(tok)
+	bc := &Broadcaster{Port: ln.Addr().(*net.TCPAddr).Port, Token: hex.EncodeToString(tok)}
+	bc.Changed = make(chan struct{})
+	bc.Server = &http.Server{Handler: bc}
+	go bc.Server.Serve(ln)
+	return bc, nil
+}
+
+// URLs returns the share urls for following the broadcast, on localhost
+// and each of the non-loopback addresses of this machine
+func (bc *Broadcaster) URLs() []string {
+	urls := []string{fmt.Sprintf("http://localhost:%d/?token=%v", bc.Port, bc.Token)}
+	addrs, _ := net.InterfaceAddrs()
+	for _, a := range addrs {
+		ipn, ok := a.(*net.IPNet)
+		if !ok || ipn.IP.IsLoopback() || ipn.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		host := ipn.IP.String()
+		if ipn.IP.To4() == nil {
+			host = "[" + host + "]"
+		}
+		urls = append(urls, fmt.Sprintf("http://%v:%d/?token=%v", host, bc.Port, bc.Token))
+	}
+	return urls
+}
+
+// changed increments the version and wakes waiting followers -- must be
+// called with Mu locked
+func (bc *Broadcaster) changed() {
+	bc.State.Version++
+	close(bc.Changed)
+	bc.Changed = make(chan struct{})
+}
+
+// Update updates the broadcast state with the file and text being viewed,
+// the cursor and the visible lines, waking followers if anything changed
+func (bc *Broadcaster) Update(file string, text []byte, line, col, top, bottom int) {
+	bc.Mu.Lock()
+	defer bc.Mu.Unlock()
+	st := &bc.State
+	chg := false
+	if file != st.File || string(text) != st.Text {
+		st.File = file
+		st.Text = string(text)
+		st.TextVers++
+		chg = true
+	}
+	if line != st.Line || col != st.Col || top != st.Top || bottom != st.Bottom {
+		st.Line, st.Col, st.Top, st.Bottom = line, col, top, bottom
+		chg = true
+	}
+	if chg {
+		bc.changed()
+	}
+}
+
+// ServeHTTP serves the follow page at the root, and the state at /state --
+// both require the token
+func (bc *Broadcaster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tok := r.URL.Query().Get("token")
+	if subtle.ConstantTimeCompare([]byte(tok), []byte(bc.Token)) != 1 {
+		http.Error(w, "invalid or missing token", http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(FollowPage))
+	case "/state":
+		bc.ServeState(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// ServeState waits until the state is newer than the version given by the
+// follower (since), or the poll timeout, and returns it as json -- the text
+// is only included if the follower's text version (text) is not current
+func (bc *Broadcaster) ServeState(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	since, _ := strconv.Atoi(q.Get("since"))
+	tvers, _ := strconv.Atoi(q.Get("text"))
+	bc.Mu.Lock()
+	if bc.State.Version <= since {
+		ch := bc.Changed
+		bc.Mu.Unlock()
+		select {
+		case <-ch:
+		case <-time.After(BroadcastPollTimeout):
+		case <-r.Context().Done():
+			return
+		}
+		bc.Mu.Lock()
+	}
+	st := bc.State
+	bc.Mu.Unlock()
+	if st.TextVers == tvers {
+		st.Text = ""
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&st)
+}
+
+// Stop stops the server, waking any waiting followers
+func (bc *Broadcaster) Stop() error {
+	bc.Mu.Lock()
+	bc.changed()
+	bc.Mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return bc.Server.Shutdown(ctx)
+}
+
+// FetchFollowState requests the broadcast state from the share url, waiting
+// for a state newer than given version -- the text is only returned if it
+// is newer than given text version
+func FetchFollowState(shareURL string, since, tvers int) (*FollowState, error) {
+	u, err := url.Parse(shareURL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	if q.Get("token") == "" {
+		return nil, fmt.Errorf("follow url has no token: %v", shareURL)
+	}
+	q.Set("since", strconv.Itoa(since))
+	q.Set("text", strconv.Itoa(tvers))
+	u.Path = "/state"
+	u.RawQuery = q.Encode()
+	cl := &http.Client{Timeout: BroadcastPollTimeout + 10*time.Second}
+	resp, err := cl.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("follow request failed: %v", resp.Status)
+	}
+	st := &FollowState{}
+	if err := json.NewDecoder(resp.Body).Decode(st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// FollowPage is the page served to browsers following the broadcast: it
+// long-polls the state, and shows the text with line numbers, the cursor
+// line highlighted, scrolled to the visible lines of the broadcast view
+var FollowPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gide follow</title>
+<style>
+body { margin: 0; font-family: sans-serif; background: #fff; color: #222; }
+#file { position: fixed; top: 0; left: 0; right: 0; padding: 4px 8px; background: #eee; border-bottom: 1px solid #ccc; font-weight: bold; }
+#text { margin: 0; padding: 32px 0 8px 0; font-family: "Go Mono", Menlo, Consolas, monospace; font-size: 14px; tab-size: 4; }
+.ln { display: block; white-space: pre; min-height: 1.2em; }
+.ln::before { content: attr(data-n); display: inline-block; width: 4em; margin-right: 1em; text-align: right; color: #999; }
+.vis { background: #f6f6ff; }
+.cur { background: #ffeeb0; }
+</style>
+</head>
+<body>
+<div id="file">connecting...</div>
+<pre id="text"></pre>
+<script>
+(function() {
+	var token = new URLSearchParams(location.search).get("token");
+	var vers = 0, tvers = 0, lines = [];
+	function render(st) {
+		document.getElementById("file").textContent = st.file || "(no file)";
+		var pre = document.getElementById("text");
+		if (st.text !== undefined && st.text !== "") {
+			pre.textContent = "";
+			lines = [];
+			st.text.replace(/\n$/, "").split("\n").forEach(function(l, i) {
+				var el = document.createElement("span");
+				el.className = "ln";
+				el.setAttribute("data-n", i + 1);
+				el.textContent = l;
+				pre.appendChild(el);
+				lines.push(el);
+			});
+		}
+		lines.forEach(function(el, i) {
+			el.className = "ln" + (i == st.line ? " cur" : (i >= st.top && i <= st.bottom ? " vis" : ""));
+		});
+		if (lines[st.top]) {
+			window.scrollTo(0, lines[st.top].offsetTop - 32);
+		}
+	}
+	function poll() {
+		fetch("/state?token=" + token + "&since=" + vers + "&text=" + tvers).then(function(r) {
+			if (!r.ok) { throw new Error(r.statusText); }
+			return r.json();
+		}).then(function(st) {
+			if (st.textVers != tvers && !st.text) { st.text = ""; lines.forEach(function(el) { el.remove(); }); lines = []; }
+			vers = st.version;
+			tvers = st.textVers;
+			render(st);
+			poll();
+		}).catch(function(err) {
+			document.getElementById("file").textContent = "disconnected: " + err + " -- retrying...";
+			setTimeout(poll, 2000);
+		});
+	}
+	poll();
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,27 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+)
+
+// DiffTextBuf returns a new text buffer, not for viewing, with given text
+// and name, shown as its file name in diffs
+func DiffTextBuf(name string, txt []byte) *giv.TextBuf {
+	tb := &giv.TextBuf{}
+	tb.InitName(tb, "diff-buf")
+	tb.SetText(txt)
+	tb.Filename = gi.FileName(name)
+	return tb
+}
+
+// DiffTextUnified returns the unified diff, with 3 lines of context, from
+// text a to text b, with given names shown as the file names -- empty if
+// they are the same
+func DiffTextUnified(aname string, a []byte, bname string, b []byte) []byte {
+	return DiffTextBuf(aname, a).DiffBufsUnified(DiffTextBuf(bname, b), 3)
+}
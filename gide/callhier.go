@@ -0,0 +1,264 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CallPos is a position in a file, as reported by the language server --
+// ln and col are 1-based, and col is in bytes, not runes
+type CallPos struct {
+	File string `desc:"path of the file"`
+	Ln   int    `desc:"line, 1-based"`
+	Col  int    `desc:"column in bytes, 1-based"`
+}
+
+// URL returns the file:/// url of the position, for links
+func (cp *CallPos) URL() string {
+	return fmt.Sprintf("file:///%v#L%vC%v", cp.File, cp.Ln, cp.Col)
+}
+
+// CallItem is a function in a call hierarchy, with the sites of the calls
+// that relate it to the function the hierarchy is for
+type CallItem struct {
+	Name  string    `desc:"name of the function"`
+	Def   CallPos   `desc:"position of the name in the definition of the function"`
+	Sites []CallPos `desc:"positions of the calls: in this function for a caller, and in the function called from for a callee"`
+}
+
+// CallHierarchy is the incoming and outgoing calls of a function, from the
+// call_hierarchy command of the language server
+type CallHierarchy struct {
+	Item    CallItem   `desc:"the function"`
+	Callers []CallItem `desc:"functions that call it, with the sites of the calls in them"`
+	Callees []CallItem `desc:"functions it calls, with the sites of the calls in it"`
+}
+
+// CallHierarchyAt returns the call hierarchy of the function at given file
+// position, using the SigHelpCmd language server, which reads the file from
+// disk -- ln and col are 1-based, and col is in bytes, not runes
+func CallHierarchyAt(fpath string, ln, col int) (*CallHierarchy, error) {
+	cmd := exec.Command(SigHelpCmd, "call_hierarchy", fmt.Sprintf("%v:%v:%v", fpath, ln, col))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%v", strings.TrimSpace(string(out)))
+	}
+	return ParseCallHierarchy(string(out))
+}
+
+// callLineRe matches the caller and callee lines of the call_hierarchy
+// output: caller[0]: ranges 12:2-7, 20:3-8 in file from/to function Name in span
+var callLineRe = regexp.MustCompile(`^(caller|callee)\[\d+\]: ranges (.*) in (.*) from/to function (.*)$`)
+
+// callSpanRe matches a span of the call_hierarchy output, e.g., file:12:2-7
+// or file:12:2-14:1, and the ranges of the calls without the file
+var callSpanRe = regexp.MustCompile(`^(?:(.*):)?(\d+):(\d+)(?:-\d+(?::\d+)?)?$`)
+
+// ParseCallHierarchy parses the output of the call_hierarchy command of the
+// language server
+func ParseCallHierarchy(out string) (*CallHierarchy, error) {
+	ch := &CallHierarchy{}
+	got := false
+	for _, ln := range strings.Split(out, "\n") {
+		ln = strings.TrimSpace(ln)
+		if strings.HasPrefix(ln, "identifier: function ") {
+			it, ok := parseCallFunc(strings.TrimPrefix(ln, "identifier: function "))
+			if !ok {
+				return nil, fmt.Errorf("gide.ParseCallHierarchy: could not parse: %v", ln)
+			}
+			ch.Item = it
+			got = true
+			continue
+		}
+		m := callLineRe.FindStringSubmatch(ln)
+		if m == nil {
+			continue
+		}
+		it, ok := parseCallFunc(m[4])
+		if !ok {
+			return nil, fmt.Errorf("gide.ParseCallHierarchy: could not parse: %v", ln)
+		}
+		for _, rg := range strings.Split(m[2], ",") {
+			if sm := callSpanRe.FindStringSubmatch(strings.TrimSpace(rg)); sm != nil {
+				l, _ := strconv.Atoi(sm[2])
+				c, _ := strconv.Atoi(sm[3])
+				it.Sites = append(it.Sites, CallPos{File: m[3], Ln: l, Col: c})
+			}
+		}
+		if m[1] == "caller" {
+			ch.Callers = append(ch.Callers, it)
+		} else {
+			ch.Callees = append(ch.Callees, it)
+		}
+	}
+	if !got {
+		return nil, fmt.Errorf("gide.ParseCallHierarchy: no function found")
+	}
+	return ch, nil
+}
+
+// parseCallFunc parses the Name in span part of a function in the
+// call_hierarchy output
+func parseCallFunc(s string) (CallItem, bool) {
+	var it CallItem
+	ii := strings.Index(s, " in ")
+	if ii < 0 {
+		return it, false
+	}
+	it.Name = s[:ii]
+	sm := callSpanRe.FindStringSubmatch(s[ii+4:])
+	if sm == nil || sm[1] == "" {
+		return it, false
+	}
+	it.Def.File = sm[1]
+	it.Def.Ln, _ = strconv.Atoi(sm[2])
+	it.Def.Col, _ = strconv.Atoi(sm[3])
+	return it, true
+}
+
+// CallNode is a node of a CallTree: a function, expanded to show its
+// callers or callees, in the direction of its branch of the tree
+type CallNode struct {
+	ID       int         `desc:"unique id of the node in the tree, for links"`
+	Item     CallItem    `desc:"the function"`
+	Incoming bool        `desc:"the node is in the callers branch, and expands to show its callers, otherwise its callees"`
+	Expanded bool        `desc:"the children are shown"`
+	Loaded   bool        `desc:"the children have been loaded from the language server"`
+	Kids     []*CallNode `desc:"callers or callees of the function"`
+	Err      string      `desc:"error loading the children, if any"`
+}
+
+// CallTree is the call hierarchy of a function, shown as an expandable tree
+// of its callers and callees in the Call Hierarchy tab
+type CallTree struct {
+	Root    *CallNode   `desc:"the function the tree is for"`
+	Callers []*CallNode `desc:"functions that call the root"`
+	Callees []*CallNode `desc:"functions that the root calls"`
+	Nodes   []*CallNode `desc:"all the nodes, by ID"`
+}
+
+// NewCallTree returns a new call tree for the call hierarchy
+func NewCallTree(ch *CallHierarchy) *CallTree {
+	ct := &CallTree{}
+	ct.Root = ct.newNode(ch.Item, false)
+	ct.Callers = ct.newNodes(ch.Callers, true)
+	ct.Callees = ct.newNodes(ch.Callees, false)
+	return ct
+}
+
+// newNode adds a new node for the function
+func (ct *CallTree) newNode(it CallItem, in bool) *CallNode {
+	cn := &CallNode{ID: len(ct.Nodes), Item: it, Incoming: in}
+	ct.Nodes = append(ct.Nodes, cn)
+	return cn
+}
+
+// newNodes adds new nodes for the functions
+func (ct *CallTree) newNodes(its []CallItem, in bool) []*CallNode {
+	cns := make([]*CallNode, len(its))
+	for i, it := range its {
+		cns[i] = ct.newNode(it, in)
+	}
+	return cns
+}
+
+// NodeByID returns the node of given id, or nil if not found
+func (ct *CallTree) NodeByID(id int) *CallNode {
+	if id < 0 || id >= len(ct.Nodes) {
+		return nil
+	}
+	return ct.Nodes[id]
+}
+
+// Toggle expands or collapses the node, loading its children from the
+// language server the first time it is expanded
+func (ct *CallTree) Toggle(cn *CallNode) {
+	if cn.Expanded {
+		cn.Expanded = false
+		return
+	}
+	cn.Expanded = true
+	if cn.Loaded {
+		return
+	}
+	cn.Loaded = true
+	ch, err := CallHierarchyAt(cn.Item.Def.File, cn.Item.Def.Ln, cn.Item.Def.Col)
+	if err != nil {
+		cn.Err = err.Error()
+		return
+	}
+	if cn.Incoming {
+		cn.Kids = ct.newNodes(ch.Callers, true)
+	} else {
+		cn.Kids = ct.newNodes(ch.Callees, false)
+	}
+}
+
+// Lines returns the lines of the tree, and their markup, with links to
+// expand the nodes, to the definitions of the functions, and to the call
+// sites -- paths are shown relative to root
+func (ct *CallTree) Lines(root string) (lns, mus [][]byte) {
+	add := func(ln, mu string) {
+		lns = append(lns, []byte(ln))
+		mus = append(mus, []byte(mu))
+	}
+	rel := func(fp string) string {
+		if rp, err := filepath.Rel(root, fp); err == nil && !strings.HasPrefix(rp, "..") {
+			return rp
+		}
+		return fp
+	}
+	rt := &ct.Root.Item
+	hd := fmt.Sprintf("Call Hierarchy: %v in %v:%v", rt.Name, rel(rt.Def.File), rt.Def.Ln)
+	add(hd, fmt.Sprintf(`<b>Call Hierarchy: <a href="%v">%v</a></b> in %v:%v`, rt.Def.URL(), html.EscapeString(rt.Name), html.EscapeString(rel(rt.Def.File)), rt.Def.Ln))
+	var addNode func(cn *CallNode, depth int)
+	addNode = func(cn *CallNode, depth int) {
+		ind := strings.Repeat("    ", depth)
+		tog := "+"
+		if cn.Expanded {
+			tog = "-"
+		}
+		it := &cn.Item
+		ln := fmt.Sprintf("%v[%v] %v  %v:%v", ind, tog, it.Name, rel(it.Def.File), it.Def.Ln)
+		mu := fmt.Sprintf(`%v[<a href="calls:///%v#toggle">%v</a>] <a href="%v">%v</a>  %v:%v`, ind, cn.ID, tog, it.Def.URL(), html.EscapeString(it.Name), html.EscapeString(rel(it.Def.File)), it.Def.Ln)
+		if len(it.Sites) > 0 {
+			ln += "  calls at:"
+			mu += "  calls at:"
+			for _, st := range it.Sites {
+				ln += fmt.Sprintf(" %v", st.Ln)
+				mu += fmt.Sprintf(` <a href="%v">%v</a>`, st.URL(), st.Ln)
+			}
+		}
+		add(ln, mu)
+		if !cn.Expanded {
+			return
+		}
+		if cn.Err != "" {
+			el := ind + "    " + cn.Err
+			add(el, html.EscapeString(el))
+		}
+		for _, kn := range cn.Kids {
+			addNode(kn, depth+1)
+		}
+	}
+	branch := func(hd string, cns []*CallNode) {
+		add("", "")
+		hl := fmt.Sprintf("%v: %v", hd, len(cns))
+		add(hl, "<b>"+html.EscapeString(hl)+"</b>")
+		for _, cn := range cns {
+			addNode(cn, 1)
+		}
+	}
+	branch("Incoming Calls (callers)", ct.Callers)
+	branch("Outgoing Calls (callees)", ct.Callees)
+	return
+}
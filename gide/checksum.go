@@ -0,0 +1,189 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumAlgs are the checksum algorithms shown by Checksums, in order
+var ChecksumAlgs = []string{"md5", "sha256"}
+
+// NewChecksumHash returns a new hash for given checksum algorithm: md5,
+// sha1, sha256 or sha512
+func NewChecksumHash(alg string) (hash.Hash, error) {
+	switch alg {
+	case "md5":
+		return md5.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	}
+	return nil, fmt.Errorf("checksum algorithm not supported: %v", alg)
+}
+
+// ChecksumAlgForLen returns the checksum algorithm for a hex checksum of
+// given length, or "" if none
+func ChecksumAlgForLen(n int) string {
+	switch n {
+	case 32:
+		return "md5"
+	case 40:
+		return "sha1"
+	case 64:
+		return "sha256"
+	case 128:
+		return "sha512"
+	}
+	return ""
+}
+
+// FileChecksum returns the hex checksum of the file, using given algorithm
+func FileChecksum(fpath, alg string) (string, error) {
+	h, err := NewChecksumHash(alg)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(fpath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Checksums returns the checksums of the file for each of the ChecksumAlgs,
+// as lines in the format of md5sum, sha256sum etc: sum, two spaces and the
+// file name
+func Checksums(fpath string) ([]string, error) {
+	var lns []string
+	for _, alg := range ChecksumAlgs {
+		sum, err := FileChecksum(fpath, alg)
+		if err != nil {
+			return nil, err
+		}
+		lns = append(lns, sum+"  "+filepath.Base(fpath))
+	}
+	return lns, nil
+}
+
+// VerifyChecksum checks the file against the checksums in given text, e.g.,
+// from the clipboard: either a bare hex checksum, or lines as output by
+// md5sum, sha256sum etc, of which those for the file (by base name) are used
+// (all lines if none are named for it) -- the algorithm is determined by the
+// length of each checksum.  Returns the algorithms checked, and an error
+// if any checksum does not match.
+func VerifyChecksum(fpath, sums string) ([]string, error) {
+	type sumalg struct{ sum, alg string }
+	var all, named []sumalg
+	fnm := filepath.Base(fpath)
+	sc := bufio.NewScanner(strings.NewReader(sums))
+	for sc.Scan() {
+		fs := strings.Fields(sc.Text())
+		if len(fs) == 0 {
+			continue
+		}
+		sum := strings.ToLower(fs[0])
+		if _, err := hex.DecodeString(sum); err != nil {
+			continue
+		}
+		alg := ChecksumAlgForLen(len(sum))
+		if alg == "" {
+			continue
+		}
+		sa := sumalg{sum, alg}
+		all = append(all, sa)
+		if len(fs) > 1 && filepath.Base(strings.TrimPrefix(fs[len(fs)-1], "*")) == fnm {
+			named = append(named, sa)
+		}
+	}
+	if len(named) > 0 {
+		all = named
+	}
+	if len(all) == 0 {
+		return nil, errors.New("no md5, sha1, sha256 or sha512 checksum found")
+	}
+	var algs []string
+	for _, sa := range all {
+		fs, err := FileChecksum(fpath, sa.alg)
+		if err != nil {
+			return algs, err
+		}
+		algs = append(algs, sa.alg)
+		if fs != sa.sum {
+			return algs, fmt.Errorf("%v checksum does not match: file has %v, expected %v", sa.alg, fs, sa.sum)
+		}
+	}
+	return algs, nil
+}
+
+// CompareFiles compares the two files byte-wise, returning true if they are
+// identical, and otherwise the offset of the first byte that differs (the
+// size of the shorter file if it is a prefix of the other)
+func CompareFiles(apath, bpath string) (bool, int64, error) {
+	af, err := os.Open(apath)
+	if err != nil {
+		return false, 0, err
+	}
+	defer af.Close()
+	bf, err := os.Open(bpath)
+	if err != nil {
+		return false, 0, err
+	}
+	defer bf.Close()
+	const bsz = 64 * 1024
+	ab := make([]byte, bsz)
+	bb := make([]byte, bsz)
+	var off int64
+	for {
+		an, aerr := io.ReadFull(af, ab)
+		bn, berr := io.ReadFull(bf, bb)
+		n := an
+		if bn < n {
+			n = bn
+		}
+		if !bytes.Equal(ab[:n], bb[:n]) {
+			for i := 0; i < n; i++ {
+				if ab[i] != bb[i] {
+					return false, off + int64(i), nil
+				}
+			}
+		}
+		if an != bn {
+			return false, off + int64(n), nil
+		}
+		off += int64(n)
+		aeof := aerr == io.EOF || aerr == io.ErrUnexpectedEOF
+		beof := berr == io.EOF || berr == io.ErrUnexpectedEOF
+		if aerr != nil && !aeof {
+			return false, off, aerr
+		}
+		if berr != nil && !beof {
+			return false, off, berr
+		}
+		if aeof || beof {
+			return true, off, nil
+		}
+	}
+}
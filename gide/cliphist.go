@@ -0,0 +1,70 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+)
+
+// ClipHistFileName is the name of the file in the prefs directory with the
+// clipboard history, saved at quit if Prefs.SaveClipHist is set
+var ClipHistFileName = "gide_clip_hist.json"
+
+// SaveClipHist saves the clipboard history of the copies and cuts in all
+// the editors (giv.TextViewClipHistory) to the prefs dir
+func SaveClipHist() error {
+	ch := make([]string, len(giv.TextViewClipHistory))
+	for i, c := range giv.TextViewClipHistory {
+		ch[i] = string(c)
+	}
+	b, err := json.MarshalIndent(ch, "", "  ")
+	if err != nil {
+		return err
+	}
+	pdir := oswin.TheApp.AppPrefsDir()
+	return ioutil.WriteFile(filepath.Join(pdir, ClipHistFileName), b, 0644)
+}
+
+// OpenClipHist restores the clipboard history saved by SaveClipHist from
+// the prefs dir, up to giv.TextViewClipHistMax entries
+func OpenClipHist() error {
+	pdir := oswin.TheApp.AppPrefsDir()
+	b, err := ioutil.ReadFile(filepath.Join(pdir, ClipHistFileName))
+	if err != nil {
+		return err
+	}
+	var ch []string
+	if err := json.Unmarshal(b, &ch); err != nil {
+		return err
+	}
+	if len(ch) > giv.TextViewClipHistMax {
+		ch = ch[:giv.TextViewClipHistMax]
+	}
+	giv.TextViewClipHistory = make([][]byte, len(ch), giv.TextViewClipHistMax)
+	for i, c := range ch {
+		giv.TextViewClipHistory[i] = []byte(c)
+	}
+	return nil
+}
+
+// ClipHistFind returns the indexes in the clipboard history, most recent
+// first, of the entries that contain the find string, ignoring case -- all
+// of them if it is empty
+func ClipHistFind(find string) []int {
+	find = strings.ToLower(find)
+	var idxs []int
+	for i, c := range giv.TextViewClipHistory {
+		if find == "" || strings.Contains(strings.ToLower(string(c)), find) {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
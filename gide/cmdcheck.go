@@ -0,0 +1,231 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+)
+
+// ValidArgVar returns true if the variable, including its braces, is
+// replaced when a command is run: one of the ArgVars, {Env:NAME} for any
+// NAME, or a prompt variable with a default, e.g., {PromptString1:all}
+func ValidArgVar(vnm string) bool {
+	if _, has := ArgVars[vnm]; has {
+		return true
+	}
+	if _, ok := ArgVarEnvName(vnm); ok {
+		return true
+	}
+	if pv, _ := PromptVarDefault(vnm); pv != vnm {
+		_, has := ArgVars[pv]
+		return has
+	}
+	return false
+}
+
+// editDist returns the number of single-char edits (insertions, deletions
+// or substitutions) that turn a into b, ignoring case
+func editDist(a, b string) int {
+	ar := []rune(strings.ToLower(a))
+	br := []rune(strings.ToLower(b))
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			d := prev[j-1]
+			if ar[i-1] != br[j-1] {
+				d++
+			}
+			if prev[j]+1 < d {
+				d = prev[j] + 1
+			}
+			if cur[j-1]+1 < d {
+				d = cur[j-1] + 1
+			}
+			cur[j] = d
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(br)]
+}
+
+// ClosestName returns the name among the candidates closest to nm, for
+// suggesting a fix of a typo, or "" if none is close enough
+func ClosestName(nm string, cands []string) string {
+	best := ""
+	bd := len(nm)/3 + 1
+	for _, cn := range cands {
+		if d := editDist(nm, cn); d < bd || (d == bd && best != "" && cn < best) {
+			best, bd = cn, d
+		}
+	}
+	return best
+}
+
+// unclosedVar returns the index of the first { that starts a variable
+// without a closing }, or -1 if there is none
+func unclosedVar(arg string) int {
+	for ci := 0; ci < len(arg); ci++ {
+		if arg[ci] != '{' || (ci > 0 && arg[ci-1] == '\\') {
+			continue
+		}
+		eb := strings.Index(arg[ci+1:], "}")
+		if eb < 0 {
+			return ci
+		}
+		ci += eb + 1
+	}
+	return -1
+}
+
+// ArgProblems returns the problems with the variables in the arg string of
+// a command: unknown variables, e.g., a typo like {FilNam}, with the one
+// most likely meant, and a { that is not closed
+func ArgProblems(arg string) []string {
+	var probs []string
+	keys := ArgVarKeys()
+	for _, vnm := range argVarNames(arg) {
+		if ValidArgVar(vnm) {
+			continue
+		}
+		msg := "unknown variable " + vnm
+		if strings.HasPrefix(vnm, ArgVarEnvPrefix) {
+			msg += ", use the name of the environment variable, e.g., {Env:HOME}"
+		} else if cn := ClosestName(vnm, keys); cn != "" {
+			msg += ", did you mean " + cn + "?"
+		}
+		probs = append(probs, msg)
+	}
+	if ci := unclosedVar(arg); ci >= 0 {
+		probs = append(probs, fmt.Sprintf("{ at %v is not closed by }, use \\{ for a literal {", ci+1))
+	}
+	return probs
+}
+
+// Problems returns the problems with the command that otherwise only show
+// when it is run: those of ArgProblems, in its directory and the programs,
+// args and directories of its steps, and missing names and programs
+func (cm *Command) Problems() []string {
+	var probs []string
+	add := func(where, arg string) {
+		for _, p := range ArgProblems(arg) {
+			probs = append(probs, where+": "+p)
+		}
+	}
+	if strings.TrimSpace(cm.Name) == "" {
+		probs = append(probs, "no name")
+	}
+	if len(cm.Cmds) == 0 {
+		probs = append(probs, "no steps to run")
+	}
+	add("dir", cm.Dir)
+	for i := range cm.Cmds {
+		cma := &cm.Cmds[i]
+		st := fmt.Sprintf("step %v", i+1)
+		if strings.TrimSpace(cma.Cmd) == "" {
+			probs = append(probs, st+": no program to run")
+		}
+		add(st+" program", cma.Cmd)
+		add(st+" dir", cma.Dir)
+		for j, arg := range cma.Args {
+			add(fmt.Sprintf("%v arg %v", st, j+1), arg)
+		}
+	}
+	return probs
+}
+
+// Problems returns the problems with the commands, each starting with the
+// name of its command: those of Command.Problems, and duplicate names --
+// and then those of the command names referenced by the post-save commands
+// of the Lang Opts, and ExtPostSaveCmds, that are neither among these nor
+// AvailCmds
+func (cm *Commands) Problems() []string {
+	var probs []string
+	names := map[string]bool{}
+	for _, cmd := range *cm {
+		for _, p := range cmd.Problems() {
+			probs = append(probs, cmd.Name+": "+p)
+		}
+		if names[cmd.Name] && cmd.Name != "" {
+			probs = append(probs, cmd.Name+": another command has the same name, and is overridden by this one")
+		}
+		names[cmd.Name] = true
+	}
+	for _, cmd := range AvailCmds {
+		names[cmd.Name] = true
+	}
+	all := make([]string, 0, len(names))
+	for nm := range names {
+		all = append(all, nm)
+	}
+	var rprobs []string
+	check := func(where string, cns CmdNames) {
+		for _, cn := range cns {
+			if names[string(cn)] {
+				continue
+			}
+			msg := fmt.Sprintf("%v: post-save command %v not found", where, cn)
+			if cl := ClosestName(string(cn), all); cl != "" {
+				msg += ", did you mean " + cl + "?"
+			}
+			rprobs = append(rprobs, msg)
+		}
+	}
+	for sup, lo := range AvailLangs {
+		check("Lang Opts "+sup.String(), lo.PostSaveCmds)
+	}
+	for ext, cns := range ExtPostSaveCmds {
+		check("files "+ext, cns)
+	}
+	sort.Strings(rprobs)
+	return append(probs, rprobs...)
+}
+
+// CmdsProblemsLabel returns the text of the label of the problems of the
+// commands in the commands editor: their number, in the error color, and
+// the first few of them, or "" if there are none
+func CmdsProblemsLabel(cm *Commands) string {
+	probs := cm.Problems()
+	if len(probs) == 0 {
+		return ""
+	}
+	mx := 3
+	if len(probs) < mx {
+		mx = len(probs)
+	}
+	lbl := ErrorSpan(fmt.Sprintf("%v problems:", len(probs)))
+	for _, p := range probs[:mx] {
+		lbl += " " + html.EscapeString(p) + "."
+	}
+	if len(probs) > mx {
+		lbl += " -- Check in the toolbar lists them all"
+	}
+	return lbl
+}
+
+// Check shows the problems with the commands (see Problems), e.g., typos
+// in variable names like {FilNam}, that otherwise only show when they are
+// run
+func (cm *Commands) Check() {
+	probs := cm.Problems()
+	if len(probs) == 0 {
+		gi.PromptDialog(nil, gi.DlgOpts{Title: "No Problems", Prompt: "No problems were found in the commands"}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	var b strings.Builder
+	for _, p := range probs {
+		fmt.Fprintf(&b, "%v<br>\n", html.EscapeString(p))
+	}
+	gi.PromptDialog(nil, gi.DlgOpts{Title: fmt.Sprintf("%v Problems in Commands", len(probs)), Prompt: b.String()}, gi.AddOk, gi.NoCancel, nil, nil)
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/pi/filecat"
+)
+
+// CmdFilesParams are the parameters of the last Run Command on Files, saved
+// in the project prefs
+type CmdFilesParams struct {
+	Cmd      CmdName             `desc:"command to run on each file, as {FilePath} etc"`
+	Glob     string              `desc:"glob patterns of the files to run on, separated by spaces or commas, e.g., *.c *.h -- matched against the file name, or the path relative to the project root if the pattern has a / -- all files if empty"`
+	Langs    []filecat.Supported `desc:"languages of the files to run on -- all if empty"`
+	MaxProcs int                 `desc:"maximum number of files the command runs on at the same time -- number of cpus if 0"`
+}
+
+// CmdFileResult is the result of running a command on one file, from
+// RunCmdOnFiles
+type CmdFileResult struct {
+	FPath    string        `desc:"path of the file"`
+	OK       bool          `desc:"all the commands succeeded"`
+	ExitCode int           `desc:"exit code of the last command run -- -1 if it could not be run"`
+	Dur      time.Duration `desc:"time taken to run the commands"`
+	Out      []byte        `desc:"combined output of the commands"`
+}
+
+// Status returns a short status of the result: ok, failed or error
+func (cr *CmdFileResult) Status() string {
+	switch {
+	case cr.OK:
+		return "ok"
+	case cr.ExitCode < 0:
+		return "error"
+	default:
+		return "failed"
+	}
+}
+
+// FirstOutLine returns the first non-blank line of the output
+func (cr *CmdFileResult) FirstOutLine() string {
+	for _, l := range bytes.Split(cr.Out, []byte("\n")) {
+		if l = bytes.TrimSpace(l); len(l) > 0 {
+			return string(l)
+		}
+	}
+	return ""
+}
+
+// GlobPatterns returns the glob patterns in given string, separated by
+// spaces or commas
+func GlobPatterns(glob string) []string {
+	return strings.FieldsFunc(glob, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// FilesMatching returns the text files in the project (see ProjFiles) of
+// given languages (all if empty), matching any of the glob patterns in glob
+// (see GlobPatterns) -- all if empty -- returns an error if a pattern is
+// not valid
+func (pj *Project) FilesMatching(glob string, langs []filecat.Supported) ([]string, error) {
+	pats := GlobPatterns(glob)
+	for _, pat := range pats {
+		if _, err := filepath.Match(pat, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern: %v: %v", pat, err)
+		}
+	}
+	fls := pj.ProjFiles(langs)
+	if len(pats) == 0 {
+		return fls, nil
+	}
+	root := string(pj.ProjRoot)
+	var mfls []string
+	for _, fp := range fls {
+		if GlobMatch(pats, root, fp) {
+			mfls = append(mfls, fp)
+		}
+	}
+	return mfls, nil
+}
+
+// GlobMatch returns true if the file at given path, in the project at given
+// root, matches any of the glob patterns: against its name, or its path
+// relative to the root if the pattern has a /
+func GlobMatch(pats []string, root, fpath string) bool {
+	rp, _ := filepath.Rel(root, fpath)
+	rp = filepath.ToSlash(rp)
+	for _, pat := range pats {
+		nm := filepath.Base(fpath)
+		if strings.Contains(pat, "/") {
+			nm = rp
+		}
+		if ok, _ := filepath.Match(pat, nm); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RunCmdOnFile runs the command on given file, with given arg var values
+// as set for the file, returning the result -- stops at the first of its
+// commands that fails
+func RunCmdOnFile(cmd *Command, avp *ArgVarVals, fpath string) CmdFileResult {
+	res := CmdFileResult{FPath: fpath, OK: true}
+	st := time.Now()
+	cdir := "{ProjPath}"
+	if cmd.Dir != "" {
+		cdir = cmd.Dir
+	}
+	cds := avp.Bind(cdir)
+	var out bytes.Buffer
+	for i := range cmd.Cmds {
+		cma := &cmd.Cmds[i]
+		ec, cmdstr := cma.PrepCmd(avp)
+		ec.Dir = cds
+		ec.Stdout = &out
+		ec.Stderr = &out
+		err := ec.Run()
+		res.ExitCode = 0
+		if err != nil {
+			res.OK = false
+			res.ExitCode = -1
+			if ee, ok := err.(*exec.ExitError); ok {
+				res.ExitCode = ee.ExitCode()
+			} else {
+				fmt.Fprintf(&out, "%v: %v\n", cmdstr, err)
+			}
+			break
+		}
+	}
+	res.Out = out.Bytes()
+	res.Dur = time.Since(st)
+	return res
+}
+
+// RunCmdOnFiles runs the named command once for each of the files, as
+// {FilePath} etc, running it on at most maxProcs files at the same time
+// (number of cpus if <= 0) -- done is called with the result for each file
+// as it finishes, one at a time -- returns the results in the order of the
+// files, or an error if the command is not found, or a prompt string it
+// uses has not been set in ArgVals
+func (pj *Project) RunCmdOnFiles(name CmdName, files []string, maxProcs int, done func(res *CmdFileResult)) ([]CmdFileResult, error) {
+	cmd, _, ok := AvailCmds.CmdByName(name, false)
+	if !ok {
+		return nil, fmt.Errorf("command named: %v not found", name)
+	}
+	if pvals, has := cmd.HasPrompts(); has {
+		for pv := range pvals {
+			if pj.ArgVals[pv] == "" {
+				return nil, fmt.Errorf("command: %v needs a value for %v", name, pv)
+			}
+		}
+	}
+	if maxProcs <= 0 {
+		maxProcs = runtime.NumCPU()
+	}
+	pj.CmdHistory.Add(name)
+	res := make([]CmdFileResult, len(files))
+	sem := make(chan struct{}, maxProcs)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i, fp := range files {
+		avp := ArgVarVals{}
+		avp.Set(fp, &pj.Prefs, nil)
+		for _, pv := range []string{"{PromptString1}", "{PromptString2}"} {
+			avp[pv] = pj.ArgVals[pv]
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, fp string, avp ArgVarVals) {
+			defer wg.Done()
+			res[i] = RunCmdOnFile(cmd, &avp, fp)
+			<-sem
+			if done != nil {
+				mu.Lock()
+				done(&res[i])
+				mu.Unlock()
+			}
+		}(i, fp, avp)
+	}
+	wg.Wait()
+	return res, nil
+}
+
+// CmdFileResultsSummary returns a one-line summary of the results
+func CmdFileResultsSummary(name CmdName, res []CmdFileResult) string {
+	nok := 0
+	for i := range res {
+		if res[i].OK {
+			nok++
+		}
+	}
+	return fmt.Sprintf("Run %v on Files: %v files: %v ok, %v failed", name, len(res), nok, len(res)-nok)
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/kit"
+)
+
+// CmdConcurrency is what is done when a command is run while it, or another
+// command of its group, is still running
+type CmdConcurrency int32
+
+const (
+	// CmdCancelPrev cancels the previous run, e.g., for lint-on-save
+	// commands, whose previous output is out of date
+	CmdCancelPrev CmdConcurrency = iota
+
+	// CmdQueue queues the run until the previous one has finished, e.g.,
+	// for builds, only one of which should run at a time
+	CmdQueue
+
+	// CmdSkipIfRunning skips the run, leaving the previous one running
+	CmdSkipIfRunning
+
+	// CmdConcurrencyN is the number of concurrency policies
+	CmdConcurrencyN
+)
+
+//go:generate stringer -type=CmdConcurrency
+
+var KiT_CmdConcurrency = kit.Enums.AddEnumAltLower(CmdConcurrencyN, kit.NotBitFlag, nil, "Cmd")
+
+// MarshalJSON encodes
+func (ev CmdConcurrency) MarshalJSON() ([]byte, error) { return kit.EnumMarshalJSON(ev) }
+
+// UnmarshalJSON decodes
+func (ev *CmdConcurrency) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// QueueRun queues the run of the command, with the current values of the
+// variables, until blk, the job it waits for, has finished, or fewer than
+// Prefs.MaxCmds commands are running if nil -- it is listed in the Jobs tab
+// until it starts, and can be canceled there
+func (cm *Command) QueueRun(ge Gide, buf *giv.TextBuf, blk *Job) {
+	avs := make(ArgVarVals, len(*ge.ArgVarVals()))
+	for k, v := range *ge.ArgVarVals() {
+		avs[k] = v
+	}
+	ge.Jobs().QueueCmd(cm.Name, cm.Group, cm.Desc, func(jb *Job) {
+		*ge.ArgVarVals() = avs
+		cm.RunJob(ge, buf, jb)
+	})
+	why := fmt.Sprintf("%v commands are running", Prefs.MaxCmds)
+	if blk != nil {
+		why = fmt.Sprintf("waiting for %v", blk.Name)
+	}
+	msg := fmt.Sprintf("%v queued: %v -- %v queued in all, see the Jobs tab", cm.Name, why, ge.Jobs().NQueued())
+	cm.AppendCmdOut(ge, buf, []byte(msg))
+	ge.SetStatus(msg)
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/kit"
+)
+
+// CmdStepIfs are the conditions under which a step of a command is run,
+// depending on whether the previous steps succeeded
+type CmdStepIfs int32
+
+const (
+	// CmdIfOK runs the step only if all the previous steps succeeded
+	CmdIfOK CmdStepIfs = iota
+
+	// CmdIfFailed runs the step only if a previous step failed, e.g., to
+	// clean up or report the failure
+	CmdIfFailed
+
+	// CmdIfAlways runs the step whether or not the previous steps succeeded
+	CmdIfAlways
+
+	// CmdStepIfsN is the number of step conditions
+	CmdStepIfsN
+)
+
+//go:generate stringer -type=CmdStepIfs
+
+var KiT_CmdStepIfs = kit.Enums.AddEnumAltLower(CmdStepIfsN, kit.NotBitFlag, nil, "CmdIf")
+
+// MarshalJSON encodes
+func (ev CmdStepIfs) MarshalJSON() ([]byte, error) { return kit.EnumMarshalJSON(ev) }
+
+// UnmarshalJSON decodes
+func (ev *CmdStepIfs) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// ShouldRun returns true if a step with this condition is run, given whether
+// any of the previous steps failed
+func (ev CmdStepIfs) ShouldRun(failed bool) bool {
+	switch ev {
+	case CmdIfFailed:
+		return failed
+	case CmdIfAlways:
+		return true
+	}
+	return !failed
+}
+
+// StepDir returns the directory to run the step in: its Dir, bound, and
+// relative to cds if a relative path -- cds if it has no Dir
+func (cm *CmdAndArgs) StepDir(avp *ArgVarVals, cds string) string {
+	if cm.Dir == "" {
+		return cds
+	}
+	dir := avp.Bind(cm.Dir)
+	if !filepath.IsAbs(dir) && cds != "" {
+		dir = filepath.Join(cds, dir)
+	}
+	return dir
+}
+
+// Pipelines returns the indexes of the steps of the command, grouped into
+// the pipelines they are run as: each step marked Pipe is in the same
+// pipeline as the step after it, and other steps are on their own
+func (cm *Command) Pipelines() [][]int {
+	var pls [][]int
+	var pl []int
+	for i := range cm.Cmds {
+		pl = append(pl, i)
+		if !cm.Cmds[i].Pipe || i == len(cm.Cmds)-1 {
+			pls = append(pls, pl)
+			pl = nil
+		}
+	}
+	return pls
+}
+
+// PrepPipeline prepares to run the steps of a pipeline, in their directories
+// relative to cds, returning the *exec.Cmd of each and a string of the full
+// pipeline, with the steps separated by |
+func (cm *Command) PrepPipeline(avp *ArgVarVals, cds string, pl []int) ([]*exec.Cmd, string) {
+	cmds := make([]*exec.Cmd, len(pl))
+	strs := make([]string, len(pl))
+	for i, ci := range pl {
+		cma := &cm.Cmds[ci]
+		cmds[i], strs[i] = cma.PrepCmd(avp)
+		cmds[i].Dir = cma.StepDir(avp, cds)
+	}
+	return cmds, strings.Join(strs, " | ")
+}
+
+// SkippedStatus returns the status message for a pipeline that was skipped
+// because of the If condition of its first step
+func (cm *Command) SkippedStatus(pl []int) string {
+	nms := make([]string, len(pl))
+	for i, ci := range pl {
+		nms[i] = cm.Cmds[ci].Cmd
+	}
+	why := "a previous step failed"
+	if cm.Cmds[pl[0]].If == CmdIfFailed {
+		why = "no previous step failed"
+	}
+	return fmt.Sprintf("%v skipped: %v", strings.Join(nms, " | "), why)
+}
+
+// RunSteps runs the steps of the command in order, waiting for each step (or
+// pipeline) to finish, and skipping those whose If condition is not met by
+// the success of the previous steps -- the output of all of them goes to buf
+// if non-nil -- returns true if all the steps run succeeded, and stops if
+// the job of the run is canceled
+func (cm *Command) RunSteps(ge Gide, buf *giv.TextBuf, cds string, jb *Job) bool {
+	failed := false
+	for _, pl := range cm.Pipelines() {
+		if jb.Canceled() {
+			return false
+		}
+		cma := &cm.Cmds[pl[0]]
+		if !cma.If.ShouldRun(failed) {
+			cm.AppendCmdOut(ge, buf, []byte(cm.SkippedStatus(pl)))
+			continue
+		}
+		if !cm.RunPipelineWait(ge, buf, cds, pl, jb) {
+			failed = true
+		}
+	}
+	return !failed
+}
+
+// RunPipelineWait runs the steps of a pipeline, waiting for all of them to
+// finish, and then appends their combined output to buf if non-nil --
+// returns true if all of them succeeded, and logs one line of the output to
+// gide statusbar
+func (cm *Command) RunPipelineWait(ge Gide, buf *giv.TextBuf, cds string, pl []int, jb *Job) bool {
+	cmds, cmdstr := cm.PrepPipeline(ge.ArgVarVals(), cds, pl)
+	if dir := cmds[0].Dir; dir != cds {
+		cm.AppendCmdOut(ge, buf, []byte(fmt.Sprintf("cd %v", dir)))
+	}
+	jb.SetCmds(cmdstr, cmds...)
+	var out bytes.Buffer
+	err := RunPipeline(cmds, &out)
+	cm.AppendCmdOut(ge, buf, out.Bytes())
+	return cm.RunStatus(ge, buf, cmdstr, err, out.Bytes())
+}
+
+// RunPipeline runs the commands at the same time, with the output of each
+// piped to the input of the next, writing the errors of all of them, and the
+// output of the last, to out, and waits for all of them to finish -- returns
+// the error of the first command that failed, if any
+func RunPipeline(cmds []*exec.Cmd, out io.Writer) error {
+	sw := &syncWriter{w: out}
+	var pfs []*os.File // pipe ends, closed here once the commands have them
+	closePipes := func() {
+		for _, f := range pfs {
+			f.Close()
+		}
+	}
+	for i, cmd := range cmds {
+		cmd.Stderr = sw
+		if i == len(cmds)-1 {
+			cmd.Stdout = sw
+			break
+		}
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			closePipes()
+			return err
+		}
+		cmd.Stdout = pw
+		cmds[i+1].Stdin = pr
+		pfs = append(pfs, pr, pw)
+	}
+	var ferr error
+	nst := 0
+	for _, cmd := range cmds {
+		if ferr = cmd.Start(); ferr != nil {
+			break
+		}
+		nst++
+	}
+	closePipes()
+	for _, cmd := range cmds[:nst] {
+		if err := cmd.Wait(); err != nil && ferr == nil {
+			ferr = err
+		}
+	}
+	return ferr
+}
+
+// syncWriter serializes the writes of the commands of a pipeline, which
+// write their output at the same time
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (sw *syncWriter) Write(b []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.w.Write(b)
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CmdWatchInterval is how often the files of a project are checked for
+// changes in watch mode, for the commands with Watch globs
+var CmdWatchInterval = time.Second
+
+// CmdWatchDelay is how long after the last change to its watched files a
+// command is re-run, so that a burst of changes, e.g., a checkout or a Save
+// All, runs it once
+var CmdWatchDelay = 500 * time.Millisecond
+
+// WatchedCmds returns the glob patterns of the available commands with
+// Watch globs, by command name
+func WatchedCmds() map[CmdName][]string {
+	wcs := map[CmdName][]string{}
+	for _, cm := range AvailCmds {
+		if pats := GlobPatterns(cm.Watch); len(pats) > 0 {
+			wcs[CmdName(cm.Name)] = pats
+		}
+	}
+	return wcs
+}
+
+// CmdWatch is the watch mode of a project: the commands with Watch globs are
+// re-run, by calling Run, whenever files in the project matching them
+// change -- the files are checked every CmdWatchInterval, and also by Check,
+// e.g., right after a file is saved
+type CmdWatch struct {
+	Root   string               `desc:"root directory of the project"`
+	Run    func(cmdNm CmdName)  `desc:"function that runs the command, in its output tab"`
+	Mods   map[string]time.Time `desc:"modification times of the watched files at the last check"`
+	Pend   map[CmdName]bool     `desc:"commands to re-run once their files stop changing for CmdWatchDelay"`
+	Timer  *time.Timer          `desc:"timer that re-runs the Pend commands"`
+	Ticker *time.Ticker         `desc:"ticker for the periodic checks"`
+	Done   chan bool            `desc:"closed to stop the periodic checks"`
+	Mu     sync.Mutex           `desc:"mutex for the checks, from the ticker and from saves"`
+}
+
+// NewCmdWatch starts watch mode for the project at given root, recording
+// the current state of the watched files, and checking them every
+// CmdWatchInterval until Stop is called
+func NewCmdWatch(root string, run func(cmdNm CmdName)) *CmdWatch {
+	cw := &CmdWatch{Root: root, Run: run, Done: make(chan bool)}
+	cw.Check()
+	cw.Ticker = time.NewTicker(CmdWatchInterval)
+	go func() {
+		for {
+			select {
+			case <-cw.Ticker.C:
+				cw.Check()
+			case <-cw.Done:
+				return
+			}
+		}
+	}()
+	return cw
+}
+
+// Stop stops watch mode, including any pending re-runs
+func (cw *CmdWatch) Stop() {
+	cw.Ticker.Stop()
+	close(cw.Done)
+	cw.Mu.Lock()
+	if cw.Timer != nil {
+		cw.Timer.Stop()
+	}
+	cw.Pend = nil
+	cw.Mu.Unlock()
+}
+
+// Check checks the watched files for changes since the last check: files
+// added, removed or modified -- the commands whose globs match any of them
+// are re-run CmdWatchDelay after the last change
+func (cw *CmdWatch) Check() {
+	wcs := WatchedCmds()
+	mods := map[string]time.Time{}
+	WalkProjFiles(cw.Root, func(path string, info os.FileInfo) {
+		for _, pats := range wcs {
+			if GlobMatch(pats, cw.Root, path) {
+				mods[path] = info.ModTime()
+				return
+			}
+		}
+	})
+	cw.Mu.Lock()
+	defer cw.Mu.Unlock()
+	prev := cw.Mods
+	cw.Mods = mods
+	if prev == nil {
+		return
+	}
+	var chg []string
+	for fp, mt := range mods {
+		if pmt, has := prev[fp]; !has || !pmt.Equal(mt) {
+			chg = append(chg, fp)
+		}
+	}
+	for fp := range prev {
+		if _, has := mods[fp]; !has {
+			chg = append(chg, fp)
+		}
+	}
+	if len(chg) == 0 {
+		return
+	}
+	for cmdNm, pats := range wcs {
+		for _, fp := range chg {
+			if GlobMatch(pats, cw.Root, fp) {
+				if cw.Pend == nil {
+					cw.Pend = map[CmdName]bool{}
+				}
+				cw.Pend[cmdNm] = true
+				break
+			}
+		}
+	}
+	if len(cw.Pend) == 0 {
+		return
+	}
+	if cw.Timer != nil {
+		cw.Timer.Stop()
+	}
+	cw.Timer = time.AfterFunc(CmdWatchDelay, cw.RunPend)
+}
+
+// RunPend re-runs the commands whose files have changed, in name order
+func (cw *CmdWatch) RunPend() {
+	cw.Mu.Lock()
+	var cmds CmdNames
+	for cmdNm := range cw.Pend {
+		cmds = append(cmds, cmdNm)
+	}
+	cw.Pend = nil
+	cw.Mu.Unlock()
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i] < cmds[j] })
+	for _, cmdNm := range cmds {
+		cw.Run(cmdNm)
+	}
+}
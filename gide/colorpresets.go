@@ -0,0 +1,189 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image/color"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// ColorPresets are the sets of status colors used for diffs, problems and
+// version control decorations -- including sets that remain distinguishable
+// for color-blind users and on low-quality displays
+type ColorPresets int32
+
+const (
+	// ColorPresetDefault is the standard green / blue / red set
+	ColorPresetDefault ColorPresets = iota
+
+	// ColorPresetHighContrast is a saturated, bright set that stands out
+	// from the text on both light and dark backgrounds
+	ColorPresetHighContrast
+
+	// ColorPresetDeuteranopia uses blue / orange / vermillion from the
+	// Okabe-Ito palette, which remain distinct with red-green color blindness
+	ColorPresetDeuteranopia
+
+	// ColorPresetsN is the number of color presets
+	ColorPresetsN
+)
+
+//go:generate stringer -type=ColorPresets
+
+var KiT_ColorPresets = kit.Enums.AddEnumAltLower(ColorPresetsN, kit.NotBitFlag, nil, "ColorPreset")
+
+// MarshalJSON encodes
+func (ev ColorPresets) MarshalJSON() ([]byte, error) { return kit.EnumMarshalJSON(ev) }
+
+// UnmarshalJSON decodes
+func (ev *ColorPresets) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// StatusColors are the colors that carry meaning in the UI, set from a
+// ColorPresets
+type StatusColors struct {
+	Added    gi.Color `desc:"lines added in diffs and the VCS gutter, and files added to version control"`
+	Modified gi.Color `desc:"lines modified in the VCS gutter, and modified files"`
+	Deleted  gi.Color `desc:"lines deleted in diffs and the VCS gutter, and files not in version control"`
+	Error    gi.Color `desc:"errors and problems, e.g., unmatched brackets, long lines and dependency cycles"`
+	Warning  gi.Color `desc:"warnings"`
+	Info     gi.Color `desc:"informational diagnostics, and the hunk headers of diffs"`
+}
+
+// ColorPresetColors are the status colors of each of the ColorPresets
+var ColorPresetColors = [ColorPresetsN]StatusColors{
+	ColorPresetDefault: {
+		Added:    gi.Color{0x4c, 0xaf, 0x50, 0xff},
+		Modified: gi.Color{0x42, 0x85, 0xf4, 0xff},
+		Deleted:  gi.Color{0xe5, 0x39, 0x35, 0xff},
+		Error:    gi.Color{0xce, 0x42, 0x52, 0xff},
+		Warning:  gi.Color{0xd4, 0x8a, 0x1e, 0xff},
+		Info:     gi.Color{0x4b, 0x7f, 0xd1, 0xff},
+	},
+	ColorPresetHighContrast: {
+		Added:    gi.Color{0x00, 0xc8, 0x00, 0xff},
+		Modified: gi.Color{0x00, 0x8c, 0xff, 0xff},
+		Deleted:  gi.Color{0xff, 0x00, 0x28, 0xff},
+		Error:    gi.Color{0xff, 0x00, 0x28, 0xff},
+		Warning:  gi.Color{0xff, 0xa0, 0x00, 0xff},
+		Info:     gi.Color{0x00, 0x8c, 0xff, 0xff},
+	},
+	ColorPresetDeuteranopia: {
+		Added:    gi.Color{0x00, 0x72, 0xb2, 0xff},
+		Modified: gi.Color{0xe6, 0x9f, 0x00, 0xff},
+		Deleted:  gi.Color{0xd5, 0x5e, 0x00, 0xff},
+		Error:    gi.Color{0xd5, 0x5e, 0x00, 0xff},
+		Warning:  gi.Color{0xe6, 0x9f, 0x00, 0xff},
+		Info:     gi.Color{0x56, 0xb4, 0xe9, 0xff},
+	},
+}
+
+// CurColorPreset is the color preset last applied by ApplyColorPreset
+var CurColorPreset = ColorPresetDefault
+
+// CurStatusColors are the status colors of the CurColorPreset
+var CurStatusColors = ColorPresetColors[ColorPresetDefault]
+
+// StyleColorPresets are the color presets for particular highlighting styles
+type StyleColorPresets map[histyle.StyleName]ColorPresets
+
+// ColorPresetFor returns the color preset for given highlighting style: its
+// entry in StyleColors if any, else ColorPreset
+func (pf *Preferences) ColorPresetFor(style histyle.StyleName) ColorPresets {
+	if cp, has := pf.StyleColors[style]; has {
+		return cp
+	}
+	return pf.ColorPreset
+}
+
+// ApplyColorPreset sets the status colors used throughout to those of the
+// preset: the VCS gutter and file tree decorations, problem markers, and the
+// markup of diffs and diagnostics -- views must be re-rendered to show them
+func ApplyColorPreset(cp ColorPresets) {
+	if cp < 0 || cp >= ColorPresetsN {
+		cp = ColorPresetDefault
+	}
+	CurColorPreset = cp
+	sc := ColorPresetColors[cp]
+	CurStatusColors = sc
+	VcsAddedColor = sc.Added
+	VcsModifiedColor = sc.Modified
+	VcsDeletedColor = sc.Deleted
+	UnmatchedBracketColor = sc.Error
+	DepGraphCycleColor = sc.Error
+	LongLineColor = color.RGBA(sc.Error)
+	MinimapDiagColor = color.RGBA(sc.Error)
+	FileTreeViewProps[".added"] = ki.Props{"color": ColorHex(sc.Added)}
+	FileTreeViewProps[".modified"] = ki.Props{"color": ColorHex(sc.Modified)}
+	FileTreeViewProps[".notinvcs"] = ki.Props{"color": ColorHex(sc.Deleted)}
+}
+
+// ColorHex returns the #rrggbb form of the color, for styles and markup
+func ColorHex(c gi.Color) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// ColorSpan returns the text, html-escaped, in a span of given color
+func ColorSpan(c gi.Color, txt string) string {
+	return `<span style="color:` + ColorHex(c) + `">` + html.EscapeString(txt) + "</span>"
+}
+
+// ErrorSpan returns the text, html-escaped, in the error color
+func ErrorSpan(txt string) string {
+	return ColorSpan(CurStatusColors.Error, txt)
+}
+
+// SeverityColor returns the status color for the severity of a diagnostic,
+// e.g., error, warning, info, note or style
+func SeverityColor(sev string) gi.Color {
+	switch strings.ToLower(sev) {
+	case "error", "fatal":
+		return CurStatusColors.Error
+	case "warning", "warn":
+		return CurStatusColors.Warning
+	}
+	return CurStatusColors.Info
+}
+
+// SeverityMarkup returns the message of a diagnostic of given severity,
+// html-escaped, with the severity (its first part, up to ": ") in the color
+// of the severity
+func SeverityMarkup(sev, msg string) string {
+	if !strings.HasPrefix(msg, sev) {
+		return html.EscapeString(msg)
+	}
+	return ColorSpan(SeverityColor(sev), sev) + html.EscapeString(msg[len(sev):])
+}
+
+// DiffMarkup returns the markup of the lines of a unified diff, with the
+// added and deleted lines in the status colors, and the file and hunk
+// headers highlighted
+func DiffMarkup(dif []byte) [][]byte {
+	lns := bytes.Split(dif, []byte("\n"))
+	mus := make([][]byte, len(lns))
+	for i, ln := range lns {
+		s := string(ln)
+		switch {
+		case strings.HasPrefix(s, "+++") || strings.HasPrefix(s, "---"):
+			mus[i] = []byte("<b>" + html.EscapeString(s) + "</b>")
+		case strings.HasPrefix(s, "@@"):
+			mus[i] = []byte(ColorSpan(CurStatusColors.Info, s))
+		case strings.HasPrefix(s, "+"):
+			mus[i] = []byte(ColorSpan(CurStatusColors.Added, s))
+		case strings.HasPrefix(s, "-"):
+			mus[i] = []byte(ColorSpan(CurStatusColors.Deleted, s))
+		default:
+			mus[i] = []byte(html.EscapeString(s))
+		}
+	}
+	return mus
+}
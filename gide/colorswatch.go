@@ -0,0 +1,495 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ki"
+	"github.com/goki/pi/filecat"
+	"golang.org/x/image/colornames"
+)
+
+// ColorSwatchExts are the extensions of the style sheet files whose color
+// literals are shown as swatches, in addition to markup files (see
+// IsMarkupFile)
+var ColorSwatchExts = []string{".css", ".scss", ".sass", ".less"}
+
+// ColorLitKinds are the kinds of color literals
+type ColorLitKinds int
+
+const (
+	// ColorLitHex is a hex color, e.g., #f80 or #ff8800cc
+	ColorLitHex ColorLitKinds = iota
+
+	// ColorLitRGB is an rgb() or rgba() color, e.g., rgb(255, 128, 0)
+	ColorLitRGB
+
+	// ColorLitName is a named color, e.g., orange
+	ColorLitName
+)
+
+// ColorLit is a color literal in a line of text
+type ColorLit struct {
+	St    int           `desc:"start of the literal in the line, in runes"`
+	Ed    int           `desc:"end of the literal in the line, in runes, exclusive"`
+	Kind  ColorLitKinds `desc:"kind of literal"`
+	Color gi.Color      `desc:"the color, not premultiplied by its alpha"`
+}
+
+// ColorLitFile returns whether the color literals of the file are shown as
+// swatches, and whether they are found anywhere in its text, as in CSS,
+// HTML and XML, or only in its strings, as in Go
+func ColorLitFile(fi *giv.FileInfo) (ok, css bool) {
+	if fi.Sup == filecat.Go {
+		return true, false
+	}
+	if IsMarkupFile(fi) {
+		return true, true
+	}
+	ext := strings.ToLower(filepath.Ext(fi.Name))
+	for _, ce := range ColorSwatchExts {
+		if ext == ce {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// isCSSNameRune returns true if the rune can be in a CSS identifier
+func isCSSNameRune(r rune) bool {
+	return isWordRune(r) || r == '-'
+}
+
+// colorName returns the named color, ignoring case -- false if it is not a
+// color name
+func colorName(s string) (gi.Color, bool) {
+	nc, ok := colornames.Map[strings.ToLower(s)]
+	if !ok {
+		return gi.Color{}, false
+	}
+	return gi.Color{R: nc.R, G: nc.G, B: nc.B, A: nc.A}, true
+}
+
+// FindColorLits returns the color literals of the line, in order: hex
+// colors, rgb() and rgba() colors, and named colors -- if css, anywhere,
+// with names in property values, after a :, and as quoted values -- else
+// only in strings, with names as whole strings, and // comments skipped
+func FindColorLits(lt []rune, css bool) []ColorLit {
+	var lits []ColorLit
+	if css {
+		findColorLits(lt, 0, len(lt), true, &lits)
+		return lits
+	}
+	for ch := 0; ch < len(lt); ch++ {
+		switch {
+		case lt[ch] == '/' && ch+1 < len(lt) && lt[ch+1] == '/':
+			return lits
+		case lt[ch] == '"' || lt[ch] == '`' || lt[ch] == '\'':
+			qe := quoteEnd(lt, ch)
+			if qe < 0 {
+				return lits
+			}
+			if lt[ch] != '\'' {
+				findQuotedColorLits(lt, ch+1, qe, false, &lits)
+			}
+			ch = qe
+		}
+	}
+	return lits
+}
+
+// colorAttr returns false if the quoted string starting at ch is the value
+// of a markup attribute that is not a color, e.g., class="red" -- those
+// with color in their name, fill and stroke are colors
+func colorAttr(lt []rune, ch int) bool {
+	if ch == 0 || lt[ch-1] != '=' {
+		return true
+	}
+	st := ch - 1
+	for st > 0 && isCSSNameRune(lt[st-1]) {
+		st--
+	}
+	attr := strings.ToLower(string(lt[st : ch-1]))
+	return strings.Contains(attr, "color") || attr == "fill" || attr == "stroke"
+}
+
+// findQuotedColorLits adds the color literals of a quoted string, from st
+// to ed: the whole string if it is a color name, except as the value of an
+// attribute that is not a color, else as in findColorLits
+func findQuotedColorLits(lt []rune, st, ed int, css bool, lits *[]ColorLit) {
+	if c, ok := colorName(string(lt[st:ed])); ok && colorAttr(lt, st-1) {
+		*lits = append(*lits, ColorLit{St: st, Ed: ed, Kind: ColorLitName, Color: c})
+		return
+	}
+	findColorLits(lt, st, ed, css, lits)
+}
+
+// findColorLits adds the color literals of the line from st to ed, see
+// FindColorLits
+func findColorLits(lt []rune, st, ed int, css bool, lits *[]ColorLit) {
+	value := false // in a property value, after a :
+	for ch := st; ch < ed; ch++ {
+		r := lt[ch]
+		var prv rune
+		if ch > st {
+			prv = lt[ch-1]
+		}
+		switch {
+		case r == '#':
+			if isWordRune(prv) || prv == '&' {
+				continue
+			}
+			if cl, ok := parseHexColorLit(lt, ch, ed); ok {
+				*lits = append(*lits, cl)
+				ch = cl.Ed - 1
+			}
+		case unicode.IsLetter(r):
+			if isCSSNameRune(prv) {
+				continue
+			}
+			if cl, ok := parseRGBColorLit(lt, ch, ed, css); ok {
+				*lits = append(*lits, cl)
+				ch = cl.Ed - 1
+				continue
+			}
+			we := ch
+			for we < ed && isCSSNameRune(lt[we]) {
+				we++
+			}
+			if css && value {
+				if c, ok := colorName(string(lt[ch:we])); ok {
+					*lits = append(*lits, ColorLit{St: ch, Ed: we, Kind: ColorLitName, Color: c})
+				}
+			}
+			ch = we - 1
+		case css && (r == '"' || r == '\''):
+			qe := quoteEnd(lt[:ed], ch)
+			if qe < 0 {
+				return
+			}
+			findQuotedColorLits(lt, ch+1, qe, css, lits)
+			ch = qe
+		case css && r == ':':
+			value = true
+		case css && (r == ';' || r == '{' || r == '}'):
+			value = false
+		}
+	}
+}
+
+// parseHexColorLit parses a hex color of 3, 4, 6 or 8 digits at ch
+func parseHexColorLit(lt []rune, ch, ed int) (ColorLit, bool) {
+	he := ch + 1
+	for he < ed && strings.ContainsRune("0123456789abcdefABCDEF", lt[he]) {
+		he++
+	}
+	n := he - ch - 1
+	if (n != 3 && n != 4 && n != 6 && n != 8) || (he < ed && isWordRune(lt[he])) {
+		return ColorLit{}, false
+	}
+	hx := string(lt[ch+1 : he])
+	if n <= 4 {
+		var sb strings.Builder
+		for _, r := range hx {
+			sb.WriteRune(r)
+			sb.WriteRune(r)
+		}
+		hx = sb.String()
+	}
+	if len(hx) == 6 {
+		hx += "ff"
+	}
+	v, err := strconv.ParseUint(hx, 16, 32)
+	if err != nil {
+		return ColorLit{}, false
+	}
+	c := gi.Color{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}
+	return ColorLit{St: ch, Ed: he, Kind: ColorLitHex, Color: c}, true
+}
+
+// parseRGBColorLit parses an rgb() or rgba() color at ch, with components
+// of 0-255 or percentages, separated by commas or spaces, and an alpha from
+// 0 to 1 if css, else from 0 to 255 as in gi color strings, or a percentage
+func parseRGBColorLit(lt []rune, ch, ed int, css bool) (ColorLit, bool) {
+	rest := strings.ToLower(string(lt[ch:ed]))
+	if !strings.HasPrefix(rest, "rgb(") && !strings.HasPrefix(rest, "rgba(") {
+		return ColorLit{}, false
+	}
+	op := strings.IndexRune(rest, '(')
+	cp := strings.IndexRune(rest, ')')
+	if cp < 0 {
+		return ColorLit{}, false
+	}
+	args := strings.FieldsFunc(rest[op+1:cp], func(r rune) bool {
+		return r == ',' || r == '/' || unicode.IsSpace(r)
+	})
+	if len(args) != 3 && len(args) != 4 {
+		return ColorLit{}, false
+	}
+	var vs [4]float64
+	vs[3] = 255
+	for i, a := range args {
+		pct := strings.HasSuffix(a, "%")
+		v, err := strconv.ParseFloat(strings.TrimSuffix(a, "%"), 64)
+		if err != nil {
+			return ColorLit{}, false
+		}
+		switch {
+		case pct:
+			v = v * 255 / 100
+		case i == 3 && css:
+			v *= 255
+		}
+		vs[i] = math.Max(0, math.Min(255, math.Round(v)))
+	}
+	c := gi.Color{R: uint8(vs[0]), G: uint8(vs[1]), B: uint8(vs[2]), A: uint8(vs[3])}
+	return ColorLit{St: ch, Ed: ch + len([]rune(rest[:cp+1])), Kind: ColorLitRGB, Color: c}, true
+}
+
+// FormatColorLit returns the text of a color literal of the same kind and
+// style as the original one, for the new color: hex colors keep their
+// number of digits and case where they can, rgb() colors their separators,
+// and names are kept for named colors, else replaced by a hex color
+func FormatColorLit(orig string, kind ColorLitKinds, c gi.Color, css bool) string {
+	switch kind {
+	case ColorLitHex:
+		n := len(orig) - 1
+		alpha := c.A != 255 || n == 4 || n == 8
+		var hx string
+		if alpha {
+			hx = fmt.Sprintf("%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+		} else {
+			hx = fmt.Sprintf("%02x%02x%02x", c.R, c.G, c.B)
+		}
+		if n <= 4 && hx[0] == hx[1] && hx[2] == hx[3] && hx[4] == hx[5] && (!alpha || hx[6] == hx[7]) {
+			var sb strings.Builder
+			for i := 0; i < len(hx); i += 2 {
+				sb.WriteByte(hx[i])
+			}
+			hx = sb.String()
+		}
+		if strings.ToLower(orig) != orig {
+			hx = strings.ToUpper(hx)
+		}
+		return "#" + hx
+	case ColorLitRGB:
+		sep := ", "
+		if !strings.Contains(orig, ", ") && strings.Contains(orig, ",") {
+			sep = ","
+		}
+		fn := orig[:strings.IndexRune(orig, '(')]
+		if c.A == 255 && len(fn) == 3 {
+			return fmt.Sprintf("%v(%v%v%v%v%v)", fn, c.R, sep, c.G, sep, c.B)
+		}
+		if len(fn) == 3 {
+			fn += "a"
+			if strings.ToUpper(fn) == orig[:3]+"A" {
+				fn = strings.ToUpper(fn)
+			}
+		}
+		alpha := strconv.Itoa(int(c.A))
+		if css {
+			alpha = strconv.FormatFloat(math.Round(float64(c.A)/255*100)/100, 'f', -1, 64)
+		}
+		return fmt.Sprintf("%v(%v%v%v%v%v%v%v)", fn, c.R, sep, c.G, sep, c.B, sep, alpha)
+	default:
+		if oc, ok := colorName(orig); ok && oc == c {
+			return orig
+		}
+		for _, nm := range colornames.Names {
+			if nc, _ := colorName(nm); nc == c {
+				return nm
+			}
+		}
+		if c.A != 255 {
+			return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+		}
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+}
+
+// colorSwatchOn returns whether color swatches are shown in the view, and
+// how its color literals are found (see ColorLitFile)
+func (tv *TextView) colorSwatchOn() (ok, css bool) {
+	if !tv.ColorSwatch || tv.Buf == nil {
+		return false, false
+	}
+	return ColorLitFile(&tv.Buf.Info)
+}
+
+// ColorLitAt returns the color literal at given position, including its
+// end, if any
+func (tv *TextView) ColorLitAt(pos giv.TextPos) (ColorLit, bool) {
+	on, css := tv.colorSwatchOn()
+	if !on || !tv.Buf.IsValidLine(pos.Ln) {
+		return ColorLit{}, false
+	}
+	for _, cl := range FindColorLits(tv.Buf.Line(pos.Ln), css) {
+		if pos.Ch >= cl.St && pos.Ch <= cl.Ed {
+			return cl, true
+		}
+	}
+	return ColorLit{}, false
+}
+
+// swatchColor returns the color blended over the background of the view,
+// as an opaque color that can be drawn over and over
+func (tv *TextView) swatchColor(c gi.Color) gi.Color {
+	if c.A == 255 {
+		return c
+	}
+	bg := tv.Sty.Font.BgColor.Color
+	a := float64(c.A) / 255
+	mix := func(f, b uint8) uint8 {
+		return uint8(math.Round(float64(f)*a + float64(b)*(1-a)))
+	}
+	return gi.Color{R: mix(c.R, bg.R), G: mix(c.G, bg.G), B: mix(c.B, bg.B), A: 255}
+}
+
+// swatchBox returns the box of the swatch of the first color literal of
+// the line in the gutter, after the line number, relative to the view --
+// empty if there are no line numbers
+func (tv *TextView) swatchBox(ln int) image.Rectangle {
+	if tv.LineNoOff == 0 {
+		return image.Rectangle{}
+	}
+	chw := tv.Sty.Font.Face.Metrics.Ch
+	sz := int(math.Min(float64(tv.LineHeight)*0.6, float64(chw)*1.5))
+	x := int(tv.Sty.BoxSpace() + float32(tv.LineNoDigs)*chw + chw*0.5)
+	y := int(tv.CharStartPos(giv.TextPos{Ln: ln}).Y+(tv.LineHeight-float32(sz))/2) - tv.VpBBox.Min.Y
+	return image.Rect(x, y, x+sz, y+sz)
+}
+
+// RenderColorSwatches draws the colors of the color literals of the visible
+// lines, if ColorSwatch is on: as bars under the literals, and as a swatch
+// of the first one of each line in the gutter
+func (tv *TextView) RenderColorSwatches() {
+	on, css := tv.colorSwatchOn()
+	if !on || tv.NLines == 0 || tv.Viewport == nil || tv.VpBBox.Empty() {
+		return
+	}
+	if len(tv.Renders) < tv.NLines || len(tv.Offs) < tv.NLines {
+		return
+	}
+	st := tv.FirstVisibleLine(0)
+	ed := tv.LastVisibleLine(st)
+	img := tv.Viewport.Pixels
+	tbb := tv.VpBBox
+	tbb.Min.X += int(tv.LineNoOff)
+	chw := tv.Sty.Font.Face.Metrics.Ch
+	th := 2 * (int(tv.Sty.Font.Size.Dots/8) + 1)
+	for ln := st; ln <= ed && ln < tv.NLines; ln++ {
+		lits := FindColorLits(tv.Buf.Line(ln), css)
+		for i, cl := range lits {
+			clr := image.NewUniform(tv.swatchColor(cl.Color))
+			pos := tv.CharStartPos(giv.TextPos{Ln: ln, Ch: cl.St})
+			ex := pos.X + chw*float32(cl.Ed-cl.St)
+			if epos := tv.CharStartPos(giv.TextPos{Ln: ln, Ch: cl.Ed}); epos.Y == pos.Y && epos.X > pos.X {
+				ex = epos.X
+			}
+			y := int(pos.Y + tv.LineHeight)
+			ul := image.Rect(int(pos.X), y-th, int(ex+0.5), y).Intersect(tbb)
+			if !ul.Empty() {
+				draw.Draw(img, ul, clr, image.ZP, draw.Src)
+			}
+			if i > 0 {
+				continue
+			}
+			sb := tv.swatchBox(ln)
+			if sb.Empty() {
+				continue
+			}
+			sb = sb.Add(tv.VpBBox.Min).Intersect(tv.VpBBox)
+			draw.Draw(img, sb, image.NewUniform(&tv.Sty.Font.Color), image.ZP, draw.Src)
+			draw.Draw(img, sb.Inset(1), clr, image.ZP, draw.Src)
+		}
+	}
+}
+
+// ColorSwatchMouseInput opens the color picker for the first color literal
+// of a line when its swatch in the gutter is clicked
+func (tv *TextView) ColorSwatchMouseInput(me *mouse.Event) {
+	if me.Action != mouse.Press || me.Button != mouse.Left || tv.LineNoOff == 0 {
+		return
+	}
+	on, css := tv.colorSwatchOn()
+	if !on {
+		return
+	}
+	pt := tv.PointToRelPos(me.Pos())
+	if pt.X >= int(tv.LineNoOff) {
+		return
+	}
+	ln := tv.PixelToCursor(pt).Ln
+	if !tv.Buf.IsValidLine(ln) {
+		return
+	}
+	sb := tv.swatchBox(ln)
+	if pt.X < sb.Min.X-1 || pt.X > sb.Max.X+1 {
+		return
+	}
+	if lits := FindColorLits(tv.Buf.Line(ln), css); len(lits) > 0 {
+		me.SetProcessed()
+		tv.PickColor(ln, lits[0])
+	}
+}
+
+// ColorContextMenu adds an action to pick the color of the color literal
+// at the cursor, if any, to the menu
+func (tv *TextView) ColorContextMenu(m *gi.Menu) {
+	if tv.IsInactive() {
+		return
+	}
+	ln := tv.CursorPos.Ln
+	cl, ok := tv.ColorLitAt(tv.CursorPos)
+	if !ok {
+		return
+	}
+	m.AddAction(gi.ActOpts{Label: "Pick Color...", Tooltip: "choose a new color for the color literal at the cursor, replacing it"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			txf := recv.Embed(KiT_TextView).(*TextView)
+			txf.PickColor(ln, cl)
+		})
+	m.AddSeparator("sep-color")
+}
+
+// PickColor opens a color picker with the color of the color literal in
+// given line, replacing the literal with the color picked, in the same
+// style (see FormatColorLit), if it is still there
+func (tv *TextView) PickColor(ln int, cl ColorLit) {
+	tb := tv.Buf
+	if tb == nil || tv.IsInactive() {
+		return
+	}
+	_, css := ColorLitFile(&tb.Info)
+	orig := string(tb.Line(ln)[cl.St:cl.Ed])
+	giv.ColorViewDialog(tv.Viewport, cl.Color, giv.DlgOpts{Title: "Pick Color", Prompt: "Choose the color to replace " + orig + " with"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			ddlg, _ := send.(*gi.Dialog)
+			tvv := recv.Embed(KiT_TextView).(*TextView)
+			reg := giv.NewTextRegion(ln, cl.St, ln, cl.Ed)
+			if tbe := tvv.Buf.Region(reg.Start, reg.End); tbe == nil || string(tbe.ToBytes()) != orig {
+				return
+			}
+			txt := FormatColorLit(orig, cl.Kind, giv.ColorViewDialogValue(ddlg), css)
+			if txt != orig {
+				ReplaceRegion(tvv.Buf, reg, []byte(txt))
+			}
+		})
+}
@@ -8,11 +8,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"html"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -29,8 +33,11 @@ import (
 // CmdAndArgs contains the name of an external program to execute and args to
 // pass to that program
 type CmdAndArgs struct {
-	Cmd  string  `width:"25" desc:"external program to execute -- must be on path or have full path specified -- use {RunExec} for the project RunExec executable."`
-	Args CmdArgs `complete:"arg" width:"25" desc:"args to pass to the program, one string per arg -- use {FileName} etc to refer to special variables -- just start typing { and you'll get a completion menu of options, and use backslash-quoted bracket to insert a literal curly bracket.  Use unix-standard path separators (/) -- they will be replaced with proper os-specific path separator (e.g., on Windows)."`
+	Cmd  string     `width:"25" complete:"arg" desc:"external program to execute -- must be on path or have full path specified -- use {RunExec} for the project RunExec executable."`
+	Args CmdArgs    `complete:"arg" width:"25" desc:"args to pass to the program, one string per arg -- use {FileName} etc to refer to special variables -- just start typing { and you'll get a completion menu of options (Variables in the toolbar lists them all, e.g., {Selection}, {GitBranch}, {DateTime} or {Env:NAME}), and use backslash-quoted bracket to insert a literal curly bracket.  Use unix-standard path separators (/) -- they will be replaced with proper os-specific path separator (e.g., on Windows)."`
+	Dir  string     `width:"20" complete:"arg" desc:"if specified, the directory to run this step in, overriding that of the command -- relative paths are relative to the directory of the command"`
+	Pipe bool       `desc:"if true, the output of this step is piped to the input of the next step, which is run at the same time as a pipeline -- the errors of all the steps, and the output of the last, go to the output of the command"`
+	If   CmdStepIfs `desc:"when this step (or the pipeline it starts) is run, depending on whether the previous steps succeeded -- by default, only if they all did"`
 }
 
 // Label satisfies the Labeler interface
@@ -38,6 +45,16 @@ func (cm CmdAndArgs) Label() string {
 	return cm.Cmd
 }
 
+// SetCompleter specifies the functions that do completion and post selection
+// editing when inserting the chosen completion
+func (cm *CmdAndArgs) SetCompleter(tf *gi.TextField, id string) {
+	if id == "arg" {
+		tf.SetCompleter(cm, CompleteArg, CompleteArgEdit)
+		return
+	}
+	fmt.Printf("no match for SetCompleter id argument")
+}
+
 // CmdArgs is a slice of arguments for a command
 type CmdArgs []string
 
@@ -58,7 +75,7 @@ func (cm *CmdAndArgs) HasPrompts() (map[string]struct{}, bool) {
 	if aps, has := ArgVarPrompts(cm.Cmd); has {
 		ps = aps
 	}
-	for _, av := range cm.Args {
+	for _, av := range append([]string{cm.Dir}, cm.Args...) {
 		if aps, has := ArgVarPrompts(av); has {
 			if ps == nil {
 				ps = aps
@@ -99,7 +116,8 @@ func (cm *CmdAndArgs) BindArgs(avp *ArgVarVals) []string {
 // PrepCmd prepares to run command, returning *exec.Cmd and a string of the full command
 func (cm *CmdAndArgs) PrepCmd(avp *ArgVarVals) (*exec.Cmd, string) {
 	cstr := avp.Bind(cm.Cmd)
-	switch cm.Cmd {
+	cnm, _ := PromptVarDefault(cm.Cmd)
+	switch cnm {
 	case "{PromptString1}": // special case -- expand args
 		cmdstr := cstr
 		args := strings.Fields(cmdstr)
@@ -113,13 +131,19 @@ func (cm *CmdAndArgs) PrepCmd(avp *ArgVarVals) (*exec.Cmd, string) {
 		cmd := exec.Command(cstr, args...)
 		return cmd, cmdstr
 	case "open":
-		switch oswin.TheApp.Platform() {
-		case oswin.MacOS:
-			// open is fine
-		case oswin.LinuxX11:
-			cstr = "xdg-open"
-		case oswin.Windows:
-			// todo
+		if oswin.TheApp == nil { // batch mode
+			if runtime.GOOS == "linux" {
+				cstr = "xdg-open"
+			}
+		} else {
+			switch oswin.TheApp.Platform() {
+			case oswin.MacOS:
+				// open is fine
+			case oswin.LinuxX11:
+				cstr = "xdg-open"
+			case oswin.Windows:
+				// todo
+			}
 		}
 		cmdstr := cstr
 		args := cm.BindArgs(avp)
@@ -141,92 +165,23 @@ func (cm *CmdAndArgs) PrepCmd(avp *ArgVarVals) (*exec.Cmd, string) {
 	}
 }
 
-///////////////////////////////////////////////////////////////////////////
-//  CmdRun, RunningCmds
-
-// CmdRun tracks running commands
-type CmdRun struct {
-	Name    string      `desc:"Name of command being run -- same as Command.Name"`
-	CmdStr  string      `desc:"command string"`
-	CmdArgs *CmdAndArgs `desc:"Details of the command and args"`
-	Exec    *exec.Cmd   `desc:"exec.Cmd for the command"`
-}
-
-// Kill kills the process
-func (cm *CmdRun) Kill() {
-	if cm.Exec.Process != nil {
-		cm.Exec.Process.Kill()
-	}
-}
-
-// CmdRuns is a slice list of running commands
-type CmdRuns []*CmdRun
-
-// Add adds a new running command
-func (rc *CmdRuns) Add(cm *CmdRun) {
-	if *rc == nil {
-		*rc = make(CmdRuns, 0, 100)
-	}
-	*rc = append(*rc, cm)
-}
-
-// AddCmd adds a new running command, creating CmdRun via args
-func (rc *CmdRuns) AddCmd(name, cmdstr string, cmdargs *CmdAndArgs, ex *exec.Cmd) {
-	cm := &CmdRun{name, cmdstr, cmdargs, ex}
-	rc.Add(cm)
-}
-
-// DeleteIdx delete command at given index
-func (rc *CmdRuns) DeleteIdx(idx int) {
-	*rc = append((*rc)[:idx], (*rc)[idx+1:]...)
-}
-
-// ByName returns command with given name
-func (rc *CmdRuns) ByName(name string) (*CmdRun, int) {
-	for i, cm := range *rc {
-		if cm.Name == name {
-			return cm, i
-		}
-	}
-	return nil, -1
-}
-
-// DeleteByName deletes command by name
-func (rc *CmdRuns) DeleteByName(name string) bool {
-	_, idx := rc.ByName(name)
-	if idx >= 0 {
-		rc.DeleteIdx(idx)
-		return true
-	}
-	return false
-}
-
-// KillByName kills a running process by name, and removes it from the list of
-// running commands
-func (rc *CmdRuns) KillByName(name string) bool {
-	cm, idx := rc.ByName(name)
-	if idx >= 0 {
-		cm.Kill()
-		rc.DeleteIdx(idx)
-		return true
-	}
-	return false
-}
-
 ///////////////////////////////////////////////////////////////////////////
 //  Command
 
 // Command defines different types of commands that can be run in the project.
 // The output of the commands shows up in an associated tab.
 type Command struct {
-	Name    string            `width:"20" desc:"name of this command (must be unique in list of commands)"`
-	Desc    string            `width:"40" desc:"brief description of this command"`
-	Lang    filecat.Supported `desc:"supported language / file type that this command applies to -- choose Any or e.g., AnyCode for subtypes -- filters the list of commands shown based on file language type"`
-	Cmds    []CmdAndArgs      `tableview-select:"-" desc:"sequence of commands to run for this overall command."`
-	Dir     string            `width:"20" complete:"arg" desc:"if specified, will change to this directory before executing the command -- e.g., use {FileDirPath} for current file's directory -- only use directory values here -- if not specified, directory will be project root directory."`
-	Wait    bool              `desc:"if true, we wait for the command to run before displaying output -- mainly for post-save commands and those with subsequent steps: if multiple commands are present, then it uses Wait mode regardless."`
-	Focus   bool              `desc:"if true, keyboard focus is directed to the command output tab panel after the command runs."`
-	Confirm bool              `desc:"if true, command requires Ok / Cancel confirmation dialog -- only needed for non-prompt commands"`
+	Name        string            `width:"20" desc:"name of this command (must be unique in list of commands)"`
+	Desc        string            `width:"40" desc:"brief description of this command"`
+	Lang        filecat.Supported `desc:"supported language / file type that this command applies to -- choose Any or e.g., AnyCode for subtypes -- filters the list of commands shown based on file language type"`
+	Cmds        []CmdAndArgs      `tableview-select:"-" desc:"sequence of commands to run for this overall command -- each step can have its own directory, pipe its output to the next step, and be run only if the previous steps succeeded (the default), failed, or always -- all the output goes to the one command tab."`
+	Dir         string            `width:"20" complete:"arg" desc:"if specified, will change to this directory before executing the command -- e.g., use {FileDirPath} for current file's directory -- only use directory values here -- if not specified, directory will be project root directory."`
+	Wait        bool              `desc:"if true, we wait for the command to run before displaying output -- mainly for post-save commands and those with subsequent steps: if multiple commands are present, then it uses Wait mode regardless."`
+	Focus       bool              `desc:"if true, keyboard focus is directed to the command output tab panel after the command runs."`
+	Confirm     bool              `desc:"if true, command requires Ok / Cancel confirmation dialog -- only needed for non-prompt commands"`
+	Concurrency CmdConcurrency    `desc:"what to do when the command is run while it, or another command of its Group, is still running: cancel the previous run (the default), queue this run until the previous one finishes, or skip this run -- runs beyond the MaxCmds preference are queued regardless"`
+	Group       string            `width:"10" desc:"if specified, at most one command of this group runs at a time, e.g., build for the commands that build things -- the others are canceled, queued or skipped according to their Concurrency"`
+	Watch       string            `width:"20" desc:"glob patterns of the files that re-run this command when they change, in watch mode (Watch Commands in the Command menu), separated by spaces or commas, e.g., *.go -- matched against the file name, or the path relative to the project root if the pattern has a / -- they must not match the files the command itself writes"`
 }
 
 // Label satisfies the Labeler interface
@@ -256,6 +211,23 @@ func (cm *Command) HasPrompts() (map[string]struct{}, bool) {
 	return nil, false
 }
 
+// UsesArgVar returns true if the variable, e.g., {SelectionPath}, is used
+// in the directory, or the program, args or directory of any of the steps
+func (cm *Command) UsesArgVar(vnm string) bool {
+	if strings.Contains(cm.Dir, vnm) {
+		return true
+	}
+	for i := range cm.Cmds {
+		cma := &cm.Cmds[i]
+		for _, av := range append([]string{cma.Cmd, cma.Dir}, cma.Args...) {
+			if strings.Contains(av, vnm) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // CmdNoUserPrompt can be set to true to prevent user from being prompted for strings
 // this is useful when a custom outer-loop has already set the string values.
 // this will be reset automatically after command is run.
@@ -268,47 +240,18 @@ var CmdNoUserPrompt bool
 // in the output buffer.
 var CmdWaitOverride bool
 
-// CmdPrompt1Vals holds last values  for PromptString1 per command, so that
-// each such command has its own appropriate history
-var CmdPrompt1Vals = map[string]string{}
-
-// CmdPrompt2Vals holds last values  for PromptString2 per command, so that
-// each such command has its own appropriate history
-var CmdPrompt2Vals = map[string]string{}
-
-// PromptUser prompts for values that need prompting for, and then runs
-// RunAfterPrompts if not otherwise cancelled by user
+// PromptUser prompts for values that need prompting for, one at a time in
+// the order of PromptVars, and then runs RunAfterPrompts if not otherwise
+// cancelled by user -- the values are remembered per command in the
+// PromptHist of the project
 func (cm *Command) PromptUser(ge Gide, buf *giv.TextBuf, pvals map[string]struct{}) {
-	sz := len(pvals)
-	avp := ge.ArgVarVals()
-	cnt := 0
-	var cmvals map[string]string
-	for pv := range pvals {
-		switch pv {
-		case "{PromptString1}":
-			cmvals = CmdPrompt1Vals
-			fallthrough
-		case "{PromptString2}":
-			if cmvals == nil {
-				cmvals = CmdPrompt2Vals
-			}
-			curval, _ := cmvals[cm.Name] // (*avp)[pv]
-			gi.StringPromptDialog(ge.VPort(), curval, "Enter string value here..",
-				gi.DlgOpts{Title: "Gide Command Prompt", Prompt: fmt.Sprintf("Command: %v: %v:", cm.Name, cm.Desc)},
-				ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-					dlg := send.(*gi.Dialog)
-					if sig == int64(gi.DialogAccepted) {
-						val := gi.StringPromptDialogValue(dlg)
-						cmvals[cm.Name] = val
-						(*avp)[pv] = val
-						cnt++
-						if cnt == sz {
-							cm.RunAfterPrompts(ge, buf)
-						}
-					}
-				})
+	var pvs []string
+	for _, pv := range PromptVars {
+		if _, has := pvals[pv]; has {
+			pvs = append(pvs, pv)
 		}
 	}
+	cm.PromptNext(ge, buf, pvs, cm.PromptDefaults())
 }
 
 // Run runs the command and saves the output in the Buf if it is non-nil,
@@ -319,11 +262,17 @@ func (cm *Command) Run(ge Gide, buf *giv.TextBuf) {
 	if cm.Confirm {
 		gi.PromptDialog(nil, gi.DlgOpts{Title: "Confirm Command", Prompt: fmt.Sprintf("Command: %v: %v", cm.Name, cm.Desc)}, true, true, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			if sig == int64(gi.DialogAccepted) {
-				cm.RunAfterPrompts(ge, buf)
+				cm.RunPrompts(ge, buf)
 			}
 		})
 		return
 	}
+	cm.RunPrompts(ge, buf)
+}
+
+// RunPrompts prompts the user for any values needed by the command, and then
+// runs it with RunAfterPrompts
+func (cm *Command) RunPrompts(ge Gide, buf *giv.TextBuf) {
 	pvals, hasp := cm.HasPrompts()
 	if !hasp || CmdNoUserPrompt {
 		cm.RunAfterPrompts(ge, buf)
@@ -332,10 +281,39 @@ func (cm *Command) Run(ge Gide, buf *giv.TextBuf) {
 	cm.PromptUser(ge, buf, pvals)
 }
 
-// RunAfterPrompts runs after any prompts have been set, if needed
+// RunAfterPrompts runs after any prompts have been set, if needed -- if the
+// command, or another of its Group, is still running, or Prefs.MaxCmds
+// commands are, the previous run is canceled, or this run is queued or
+// skipped, according to the Concurrency of the command
 func (cm *Command) RunAfterPrompts(ge Gide, buf *giv.TextBuf) {
-	ge.CmdRuns().KillByName(cm.Name) // make sure nothing still running for us..
 	CmdNoUserPrompt = false
+	js := ge.Jobs()
+	for {
+		jb, blk := js.StartCmd(cm.Name, cm.Group, cm.Desc)
+		switch {
+		case jb != nil:
+			cm.RunJob(ge, buf, jb)
+			return
+		case blk != nil && cm.Concurrency == CmdCancelPrev:
+			js.CancelJob(blk) // and try again
+		case blk != nil && cm.Concurrency == CmdSkipIfRunning:
+			ge.SetStatus(fmt.Sprintf("%v skipped: %v is still running", cm.Name, blk.Name))
+			return
+		default:
+			cm.QueueRun(ge, buf, blk)
+			return
+		}
+	}
+}
+
+// RunJob runs the command as the given job, from RunAfterPrompts, or once
+// its queued run can start -- the job is finished when the run is done, and
+// the {SelectionPath} file, if the command uses it, is then removed
+func (cm *Command) RunJob(ge Gide, buf *giv.TextBuf, jb *Job) {
+	selfn := ""
+	if cm.UsesArgVar("{SelectionPath}") {
+		selfn, _ = ge.ArgVarVals().Value("{SelectionPath}")
+	}
 	cdir := "{ProjPath}"
 	if cm.Dir != "" {
 		cdir = cm.Dir
@@ -348,44 +326,29 @@ func (cm *Command) RunAfterPrompts(ge Gide, buf *giv.TextBuf) {
 	}
 
 	if CmdWaitOverride || cm.Wait || len(cm.Cmds) > 1 {
-		for i := range cm.Cmds {
-			cma := &cm.Cmds[i]
+		cm.RunSteps(ge, buf, cds, jb)
+		jb.Finish()
+		RemoveSelectionFile(selfn)
+	} else {
+		cma := &cm.Cmds[0]
+		go func() {
 			if buf == nil {
-				if !cm.RunNoBuf(ge, cma) {
-					break
-				}
+				cm.RunNoBuf(ge, cma, jb)
 			} else {
-				if !cm.RunBufWait(ge, buf, cma) {
-					break
-				}
+				cm.RunBuf(ge, buf, cma, jb)
 			}
-		}
-	} else {
-		cma := &cm.Cmds[0]
-		if buf == nil {
-			go cm.RunNoBuf(ge, cma)
-		} else {
-			go cm.RunBuf(ge, buf, cma)
-		}
+			jb.Finish()
+			RemoveSelectionFile(selfn)
+		}()
 	}
 }
 
-// RunBufWait runs a command with output to the buffer, using CombinedOutput
-// so it waits for completion -- returns overall command success, and logs one
-// line of the command output to gide statusbar
-func (cm *Command) RunBufWait(ge Gide, buf *giv.TextBuf, cma *CmdAndArgs) bool {
-	cmd, cmdstr := cma.PrepCmd(ge.ArgVarVals())
-	ge.CmdRuns().AddCmd(cm.Name, cmdstr, cma, cmd)
-	out, err := cmd.CombinedOutput()
-	cm.AppendCmdOut(ge, buf, out)
-	return cm.RunStatus(ge, buf, cmdstr, err, out)
-}
-
 // RunBuf runs a command with output to the buffer, incrementally updating the
 // buffer with new results line-by-line as they come in
-func (cm *Command) RunBuf(ge Gide, buf *giv.TextBuf, cma *CmdAndArgs) bool {
+func (cm *Command) RunBuf(ge Gide, buf *giv.TextBuf, cma *CmdAndArgs, jb *Job) bool {
 	cmd, cmdstr := cma.PrepCmd(ge.ArgVarVals())
-	ge.CmdRuns().AddCmd(cm.Name, cmdstr, cma, cmd)
+	cmd.Dir = cma.StepDir(ge.ArgVarVals(), "")
+	jb.SetCmds(cmdstr, cmd)
 	stdout, err := cmd.StdoutPipe()
 	if err == nil {
 		cmd.Stderr = cmd.Stdout
@@ -403,9 +366,10 @@ func (cm *Command) RunBuf(ge Gide, buf *giv.TextBuf, cma *CmdAndArgs) bool {
 // RunNoBuf runs a command without any output to the buffer -- can call using
 // go as a goroutine for no-wait case -- returns overall command success, and
 // logs one line of the command output to gide statusbar
-func (cm *Command) RunNoBuf(ge Gide, cma *CmdAndArgs) bool {
+func (cm *Command) RunNoBuf(ge Gide, cma *CmdAndArgs, jb *Job) bool {
 	cmd, cmdstr := cma.PrepCmd(ge.ArgVarVals())
-	ge.CmdRuns().AddCmd(cm.Name, cmdstr, cma, cmd)
+	cmd.Dir = cma.StepDir(ge.ArgVarVals(), "")
+	jb.SetCmds(cmdstr, cmd)
 	out, err := cmd.CombinedOutput()
 	return cm.RunStatus(ge, nil, cmdstr, err, out)
 }
@@ -438,24 +402,11 @@ var CmdOutStatusLen = 80
 // ge.StatusBar -- returns true if there are no errors, and false if there
 // were errors
 func (cm *Command) RunStatus(ge Gide, buf *giv.TextBuf, cmdstr string, err error, out []byte) bool {
-	ge.CmdRuns().DeleteByName(cm.Name)
-	var rval bool
 	outstr := ""
 	if out != nil {
-		outstr = string(out[:CmdOutStatusLen])
-	}
-	finstat := ""
-	tstr := time.Now().Format("Mon Jan  2 15:04:05 MST 2006")
-	if err == nil {
-		finstat = fmt.Sprintf("%v <b>successful</b> at: %v", cmdstr, tstr)
-		rval = true
-	} else if ee, ok := err.(*exec.ExitError); ok {
-		finstat = fmt.Sprintf("%v <b>failed</b> at: %v with error: %v", cmdstr, tstr, ee.Error())
-		rval = false
-	} else {
-		finstat = fmt.Sprintf("%v <b>exec error</b> at: %v error: %v", cmdstr, tstr, err.Error())
-		rval = false
+		outstr = string(out[:ints.MinInt(len(out), CmdOutStatusLen)])
 	}
+	finstat, rval := CmdFinalStatus(cmdstr, err)
 	if buf != nil {
 		if err != nil {
 			ge.SelectMainTabByName(cm.Name) // sometimes it isn't
@@ -473,14 +424,61 @@ func (cm *Command) RunStatus(ge Gide, buf *giv.TextBuf, cmdstr string, err error
 	return rval
 }
 
+// CmdFinalStatus returns the final status message for the command run
+// (given in cmdstr) that returned given error, with <b> markup, and true if
+// there was no error
+func CmdFinalStatus(cmdstr string, err error) (string, bool) {
+	tstr := time.Now().Format("Mon Jan  2 15:04:05 MST 2006")
+	if err == nil {
+		return fmt.Sprintf("%v <b>successful</b> at: %v", cmdstr, tstr), true
+	}
+	if ee, ok := err.(*exec.ExitError); ok {
+		return fmt.Sprintf("%v <b>failed</b> at: %v with error: %v", cmdstr, tstr, ee.Error()), false
+	}
+	return fmt.Sprintf("%v <b>exec error</b> at: %v error: %v", cmdstr, tstr, err.Error()), false
+}
+
+// RunOut runs the command without any gui, using given arg values, which
+// must include any prompt values, sequentially writing the output of each
+// of its steps (or pipelines) to out -- steps are skipped according to their
+// If conditions, so by default it stops at the first step that fails, and
+// returns true if all the steps run succeeded.  Used for batch mode.
+func (cm *Command) RunOut(avp *ArgVarVals, out io.Writer) bool {
+	cdir := "{ProjPath}"
+	if cm.Dir != "" {
+		cdir = cm.Dir
+	}
+	cds := avp.Bind(cdir)
+	failed := false
+	for _, pl := range cm.Pipelines() {
+		cif := cm.Cmds[pl[0]].If
+		if !cif.ShouldRun(failed) {
+			fmt.Fprintln(out, cm.SkippedStatus(pl))
+			continue
+		}
+		cmds, cmdstr := cm.PrepPipeline(avp, cds, pl)
+		fmt.Fprintf(out, "cd %v; %v\n", cmds[0].Dir, cmdstr)
+		finstat, ok := CmdFinalStatus(cmdstr, RunPipeline(cmds, out))
+		fmt.Fprintln(out, strings.NewReplacer("<b>", "", "</b>", "").Replace(finstat))
+		if !ok {
+			failed = true
+		}
+	}
+	return !failed
+}
+
 // LangMatch returns true if the given language matches the command Lang constraints
 func (cm *Command) LangMatch(lang filecat.Supported) bool {
 	return filecat.IsMatch(cm.Lang, lang)
 }
 
 // MarkupCmdOutput applies links to the first element in command output line
-// if it looks like a file name / position
+// if it looks like a file name / position, or to a terraform diagnostic
+// location
 func MarkupCmdOutput(out []byte) []byte {
+	if mu, ok := MarkupTerraformDiag(out); ok {
+		return mu
+	}
 	flds := strings.Fields(string(out))
 	if len(flds) == 0 {
 		return out
@@ -661,7 +659,7 @@ func (cm *Commands) SaveJSON(filename gi.FileName) error {
 // OpenPrefs opens custom Commands from App standard prefs directory, using
 // PrefsCmdsFileName
 func (cm *Commands) OpenPrefs() error {
-	pdir := oswin.TheApp.AppPrefsDir()
+	pdir := AppPrefsDir()
 	pnm := filepath.Join(pdir, PrefsCmdsFileName)
 	CustomCmdsChanged = false
 	err := cm.OpenJSON(gi.FileName(pnm))
@@ -674,7 +672,7 @@ func (cm *Commands) OpenPrefs() error {
 // SavePrefs saves custom Commands to App standard prefs directory, using
 // PrefsCmdsFileName
 func (cm *Commands) SavePrefs() error {
-	pdir := oswin.TheApp.AppPrefsDir()
+	pdir := AppPrefsDir()
 	pnm := filepath.Join(pdir, PrefsCmdsFileName)
 	CustomCmdsChanged = false
 	err := cm.SaveJSON(gi.FileName(pnm))
@@ -694,9 +692,20 @@ func (cm *Commands) CopyFrom(cp Commands) {
 	json.Unmarshal(b, cm)
 }
 
-// MergeAvailCmds updates the AvailCmds list from CustomCmds and StdCmds
+// MergeAvailCmds updates the AvailCmds list from CustomCmds, the commands
+// of registered Plugins, and StdCmds
 func MergeAvailCmds() {
 	AvailCmds.CopyFrom(StdCmds)
+	for _, pl := range Plugins {
+		for _, cmd := range pl.Cmds {
+			_, idx, has := AvailCmds.CmdByName(CmdName(cmd.Name), false)
+			if has {
+				AvailCmds[idx] = cmd // replace
+			} else {
+				AvailCmds = append(AvailCmds, cmd)
+			}
+		}
+	}
 	for _, cmd := range CustomCmds {
 		_, idx, has := AvailCmds.CmdByName(CmdName(cmd.Name), false)
 		if has {
@@ -713,6 +722,19 @@ func (cm *Commands) ViewStd() {
 	CmdsView(&StdCmds)
 }
 
+// ViewArgVars shows the variables that can be used in the commands and
+// args, e.g., {FilePath}, with their descriptions
+func (cm *Commands) ViewArgVars() {
+	keys := ArgVarKeys()
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "<b>%v</b>: %v<br>\n", html.EscapeString(k), html.EscapeString(ArgVars[k].Desc))
+	}
+	fmt.Fprintf(&b, "<b>%vNAME}</b>: Value of the environment variable NAME, e.g., {Env:HOME} -- empty if not set.<br>\n", ArgVarEnvPrefix)
+	gi.PromptDialog(nil, gi.DlgOpts{Title: "Command Variables", Prompt: "These variables are replaced by their values when a command is run -- type { in the args for a completion menu of them, and use \\{ for a literal curly bracket:<br>\n<br>\n" + b.String()}, gi.AddOk, gi.NoCancel, nil, nil)
+}
+
 // CustomCmdsChanged is used to update giv.CmdsView toolbars via following
 // menu, toolbar props update methods.
 var CustomCmdsChanged = false
@@ -751,6 +773,14 @@ var CommandsProps = ki.Props{
 			}},
 		}},
 		{"Edit", "Copy Cut Paste Dupe"},
+		{"Help", ki.PropSlice{
+			{"ViewArgVars", ki.Props{
+				"label": "Command Variables",
+			}},
+			{"Check", ki.Props{
+				"label": "Check Commands",
+			}},
+		}},
 		{"Window", "Windows"},
 	},
 	"ToolBar": ki.PropSlice{
@@ -783,6 +813,15 @@ var CommandsProps = ki.Props{
 			},
 		}},
 		{"sep-std", ki.BlankProp{}},
+		{"Check", ki.Props{
+			"icon": "search",
+			"desc": "Shows the problems with the commands that otherwise only show when they are run, e.g., unknown variables like {FilNam} (a typo for {FileName}), a { not closed by }, duplicate names, and post-save commands of the Lang Opts that are not found.",
+		}},
+		{"ViewArgVars", ki.Props{
+			"label": "Variables",
+			"icon":  "info",
+			"desc":  "Shows the variables, e.g., {FilePath}, {Selection}, {GitBranch} or {Env:NAME}, that can be used in the commands and args, with what they are replaced by.",
+		}},
 		{"ViewStd", ki.Props{
 			"desc": "Shows the standard commands that are compiled into the program (edits will not be saved -- even though the viewer is editable).  Custom commands override standard ones of the same name, so that is the way to change any existing commands.",
 			"updtfunc": giv.ActionUpdateFunc(func(cmi interface{}, act *gi.Action) {
@@ -800,112 +839,153 @@ const (
 	CmdNoFocus   = false
 	CmdConfirm   = true
 	CmdNoConfirm = false
+
+	CmdNoGroup    = ""
+	CmdBuildGroup = "build"
+
+	CmdNoWatch = ""
 )
 
 // StdCmds is the original compiled-in set of standard commands.
 var StdCmds = Commands{
 	{"Run Proj", "run RunExec executable set in project", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"{RunExecPath}", nil}}, "{RunExecDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "{RunExecPath}", Args: nil}}, "{RunExecDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Run Prompt", "run any command you enter at the prompt", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"{PromptString1}", nil}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "{PromptString1}", Args: nil}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 
 	// Make
 	{"Make", "run make with no args", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"make", nil}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "make", Args: nil}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdQueue, CmdBuildGroup, CmdNoWatch},
 	{"Make Prompt", "run make with prompted make target", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"make", []string{"{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "make", Args: []string{"{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdQueue, CmdBuildGroup, CmdNoWatch},
 
 	// Go
 	{"Imports Go File", "run goimports on file", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"goimports", []string{"-w", "{FilePath}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "goimports", Args: []string{"-w", "{FilePath}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Fmt Go File", "run go fmt on file", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"gofmt", []string{"-w", "{FilePath}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "gofmt", Args: []string{"-w", "{FilePath}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Build Go Dir", "run go build to build in current dir", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"go", []string{"build", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "go", Args: []string{"build", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdQueue, CmdBuildGroup, CmdNoWatch},
 	{"Build Go Proj", "run go build for project BuildDir", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"go", []string{"build", "-v"}}}, "{BuildDir}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "go", Args: []string{"build", "-v"}}}, "{BuildDir}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdQueue, CmdBuildGroup, CmdNoWatch},
 	{"Install Go Proj", "run go install for project BuildDir", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"go", []string{"install", "-v"}}}, "{BuildDir}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "go", Args: []string{"install", "-v"}}}, "{BuildDir}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdQueue, CmdBuildGroup, CmdNoWatch},
 	{"Generate Go", "run go generate in current dir", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"go", []string{"generate"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "go", Args: []string{"generate"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Test Go", "run go test in current dir", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"go", []string{"test", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "go", Args: []string{"test", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
+	{"Bench Go", "run go test -bench (benchmarks only) in current dir", filecat.Go,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "go", Args: []string{"test", "-run", "^$", "-bench", ".", "-benchmem"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Vet Go", "run go vet in current dir", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"go", []string{"vet"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "go", Args: []string{"vet"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Get Go", "run go get on package you enter at prompt", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"go", []string{"get", "{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "go", Args: []string{"get", "{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Get Go Updt", "run go get -u (updt) on package you enter at prompt", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"go", []string{"get", "{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "go", Args: []string{"get", "{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
+
+	// Rust
+	{"Build Rust", "run cargo build for the crate of the current dir", filecat.Rust,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "cargo", Args: []string{"build"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdQueue, CmdBuildGroup, CmdNoWatch},
+	{"Test Rust", "run cargo test for the crate of the current dir", filecat.Rust,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "cargo", Args: []string{"test"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
+	{"Bench Rust", "run cargo bench for the crate of the current dir", filecat.Rust,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "cargo", Args: []string{"bench"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
+
+	// Python
+	{"Test Python", "run pytest on the tests in current dir", filecat.Python,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "python3", Args: []string{"-m", "pytest", "-v", "."}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 
 	// Git
 	{"Add Git", "git add file", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"git", []string{"add", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "git", Args: []string{"add", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Checkout Git", "git checkout file or directory -- WARNING will overwrite local changes!", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"git", []string{"checkout", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "git", Args: []string{"checkout", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Status Git", "git status", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"git", []string{"status"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "git", Args: []string{"status"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Diff Git", "git diff -- see changes since last checkin", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"git", []string{"diff"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "git", Args: []string{"diff"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Log Git", "git log", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"git", []string{"log"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "git", Args: []string{"log"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Commit Git", "git commit", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"git", []string{"commit", "-am", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm}, // promptstring1 provided during normal commit process, MUST be wait!
+		[]CmdAndArgs{CmdAndArgs{Cmd: "git", Args: []string{"commit", "-am", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch}, // promptstring1 provided during normal commit process, MUST be wait!
 	{"Pull Git ", "git pull", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"git", []string{"pull"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "git", Args: []string{"pull"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Push Git ", "git push", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"git", []string{"push"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "git", Args: []string{"push"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 
 	// SVN
 	{"Add SVN", "svn add file", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"svn", []string{"add", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "svn", Args: []string{"add", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Status SVN", "svn status", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"svn", []string{"status"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "svn", Args: []string{"status"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Info SVN", "svn info", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"svn", []string{"info"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "svn", Args: []string{"info"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Log SVN", "svn log", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"svn", []string{"log", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "svn", Args: []string{"log", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Commit SVN Proj", "svn commit for entire project directory", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"svn", []string{"commit", "-m", "{PromptString1}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdNoConfirm}, // promptstring1 provided during normal commit process
+		[]CmdAndArgs{CmdAndArgs{Cmd: "svn", Args: []string{"commit", "-m", "{PromptString1}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch}, // promptstring1 provided during normal commit process
 	{"Commit SVN Dir", "svn commit in directory of current file", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"svn", []string{"commit", "-m", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm}, // promptstring1 provided during normal commit process
+		[]CmdAndArgs{CmdAndArgs{Cmd: "svn", Args: []string{"commit", "-m", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch}, // promptstring1 provided during normal commit process
 	{"Update SVN", "svn update", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"svn", []string{"update"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "svn", Args: []string{"update"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 
 	// LaTeX
 	{"LaTeX PDF", "run PDFLaTeX on file", filecat.TeX,
-		[]CmdAndArgs{CmdAndArgs{"pdflatex", []string{"-file-line-error", "-interaction=nonstopmode", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "pdflatex", Args: []string{"-file-line-error", "-interaction=nonstopmode", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"BibTeX", "run BibTeX on file", filecat.TeX,
-		[]CmdAndArgs{CmdAndArgs{"bibtex", []string{"{FileNameNoExt}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "bibtex", Args: []string{"{FileNameNoExt}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"CleanTeX", "remove aux LaTeX files", filecat.TeX,
-		[]CmdAndArgs{CmdAndArgs{"rm", []string{"*.aux", "*.log", "*.blg", "*.bbl", "*.fff", "*.lof", "*.ttt", "*.toc", "*.spl"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "rm", Args: []string{"*.aux", "*.log", "*.blg", "*.bbl", "*.fff", "*.lof", "*.ttt", "*.toc", "*.spl"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 
 	// Generic files / images / etc
 	{"Open File", "open file using OS 'open' command", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"open", []string{"{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "open", Args: []string{"{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Open Target File", "open project target file using OS 'open' command", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"open", []string{"{RunExecPath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "open", Args: []string{"{RunExecPath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
+
+	// OpenAPI
+	{"OpenAPI Gen Client", "generate client stub from OpenAPI document using openapi-generator, prompting for generator and output dir", filecat.Any,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "openapi-generator", Args: []string{"generate", "-i", "{FilePath}", "-g", "{PromptString1}", "-o", "{PromptString2}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
+	{"OpenAPI Gen Server", "generate server stub from OpenAPI document using openapi-generator, prompting for generator and output dir", filecat.Any,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "openapi-generator", Args: []string{"generate", "-i", "{FilePath}", "-g", "{PromptString1}", "-o", "{PromptString2}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
+
+	// Shell
+	{"Fmt Shell File", "run shfmt on file", filecat.Bash,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "shfmt", Args: []string{"-w", "{FilePath}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
+	{"ShellCheck File", "run shellcheck on file", filecat.Bash,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "shellcheck", Args: []string{"-f", "gcc", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
+
+	// Terraform
+	{"Fmt Terraform File", "run terraform fmt on file", filecat.Any,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "terraform", Args: []string{"fmt", "{FilePath}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
+	{"Init Terraform", "run terraform init in current dir", filecat.Any,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "terraform", Args: []string{"init", "-no-color"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
+	{"Validate Terraform", "run terraform validate in current dir", filecat.Any,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "terraform", Args: []string{"validate", "-no-color"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
+	{"Plan Terraform", "run terraform plan in current dir", filecat.Any,
+		[]CmdAndArgs{CmdAndArgs{Cmd: "terraform", Args: []string{"plan", "-no-color"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 
 	// Misc
 	{"List Dir", "list current dir", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"ls", []string{"-la"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "ls", Args: []string{"-la"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Grep", "recursive grep of all files for prompted value", filecat.Any,
-		[]CmdAndArgs{CmdAndArgs{"grep", []string{"-R", "-e", "{PromptString1}", "{FileDirPath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "grep", Args: []string{"-R", "-e", "{PromptString1}", "{FileDirPath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 
 	//	grunt for Go emergent
 	{"Submit grunt", "grunt submit", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"grunt", []string{"submit", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "grunt", Args: []string{"submit", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Jobs grunt", "grunt jobs", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"grunt", []string{"jobs"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "grunt", Args: []string{"jobs"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Status grunt", "grunt stat", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"grunt", []string{"status"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "grunt", Args: []string{"status"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Out grunt job", "grunt out jobid", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"grunt", []string{"out", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "grunt", Args: []string{"out", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Update grunt", "grunt update", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"grunt", []string{"update"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "grunt", Args: []string{"update"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Update grunt job", "grunt update jobid", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"grunt", []string{"update", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "grunt", Args: []string{"update", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 	{"Pull grunt", "grunt pull", filecat.Go,
-		[]CmdAndArgs{CmdAndArgs{"grunt", []string{"pull"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{CmdAndArgs{Cmd: "grunt", Args: []string{"pull"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, CmdCancelPrev, CmdNoGroup, CmdNoWatch},
 }
 
 // SetCompleter adds a completer to the textfield - each field
@@ -919,10 +999,27 @@ func (cmd *Command) SetCompleter(tf *gi.TextField, id string) {
 	fmt.Printf("no match for SetCompleter id argument")
 }
 
-// CompleteArg supplies directory variables to the completer
+// CompleteArg supplies directory variables to the completer -- the seed is
+// the variable being typed, from its {, even if not at the start of a word,
+// e.g., -o={Fil
 func CompleteArg(data interface{}, text string, posLn, posCh int) (md complete.MatchData) {
 	md.Seed = complete.SeedWhiteSpace(text)
+	if ci := strings.LastIndex(md.Seed, "{"); ci > 0 && !strings.Contains(md.Seed[ci:], "}") {
+		md.Seed = md.Seed[ci:]
+	}
 	possibles := complete.MatchSeedString(ArgVarKeys(), md.Seed)
+	if strings.HasPrefix(md.Seed, ArgVarEnvPrefix) {
+		var envs []string
+		for _, ev := range os.Environ() {
+			if ei := strings.Index(ev, "="); ei > 0 {
+				envs = append(envs, ArgVarEnvPrefix+ev[:ei]+"}")
+			}
+		}
+		sort.Strings(envs)
+		possibles = append(possibles, complete.MatchSeedString(envs, md.Seed)...)
+	} else if strings.HasPrefix(ArgVarEnvPrefix, md.Seed) && md.Seed != "" {
+		possibles = append(possibles, ArgVarEnvPrefix)
+	}
 	for _, p := range possibles {
 		m := complete.Completion{Text: p, Icon: ""}
 		md.Matches = append(md.Matches, m)
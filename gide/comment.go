@@ -0,0 +1,172 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/filecat"
+)
+
+// CommentStrs are the comment strings used by Comment Out for a language
+type CommentStrs struct {
+	Ln    string `desc:"line comment string, e.g., //"`
+	St    string `desc:"block comment start string, e.g., /*"`
+	Ed    string `desc:"block comment end string, e.g., */"`
+	Block bool   `desc:"comment out with one block comment around the lines, instead of commenting each line"`
+}
+
+// LangCommentStrs returns the comment strings for given language: those
+// set in AvailLangs, else the defaults of the buffer options -- block
+// comments are used if there is no line comment string
+func LangCommentStrs(sup filecat.Supported, opts *giv.TextBufOpts) CommentStrs {
+	cs := CommentStrs{Ln: opts.CommentLn, St: opts.CommentSt, Ed: opts.CommentEd}
+	if lo, has := AvailLangs[sup]; has {
+		if lo.CommentLn != "" {
+			cs.Ln = lo.CommentLn
+		}
+		if lo.CommentSt != "" && lo.CommentEd != "" {
+			cs.St = lo.CommentSt
+			cs.Ed = lo.CommentEd
+		}
+		cs.Block = lo.BlockComment
+	}
+	if strings.TrimSpace(cs.Ln) == "" {
+		cs.Block = true
+	}
+	if strings.TrimSpace(cs.St) == "" || strings.TrimSpace(cs.Ed) == "" {
+		cs.Block = false
+	}
+	return cs
+}
+
+// commonIndent returns the leading whitespace common to all the non-blank
+// lines, and the indexes of the first and last non-blank lines (-1 if none)
+func commonIndent(lns []string) (string, int, int) {
+	ind := ""
+	first, last := -1, -1
+	for i, l := range lns {
+		txt := strings.TrimLeftFunc(l, unicode.IsSpace)
+		if txt == "" {
+			continue
+		}
+		li := l[:len(l)-len(txt)]
+		if first < 0 {
+			ind = li
+			first = i
+		} else {
+			n := 0
+			for n < len(ind) && n < len(li) && ind[n] == li[n] {
+				n++
+			}
+			ind = ind[:n]
+		}
+		last = i
+	}
+	return ind, first, last
+}
+
+// removeMarker removes the comment marker from the start of s, along with
+// the space after it if the marker ends with a space
+func removeMarker(s, mark string) string {
+	tm := strings.TrimSpace(mark)
+	s = strings.TrimPrefix(s, tm)
+	if strings.HasSuffix(mark, " ") {
+		s = strings.TrimPrefix(s, " ")
+	}
+	return s
+}
+
+// removeEndMarker removes the comment end marker from the end of s, along
+// with the space before it if the marker starts with a space
+func removeEndMarker(s, mark string) string {
+	tm := strings.TrimSpace(mark)
+	s = strings.TrimSuffix(strings.TrimRightFunc(s, unicode.IsSpace), tm)
+	if strings.HasPrefix(mark, " ") {
+		s = strings.TrimSuffix(s, " ")
+	}
+	return s
+}
+
+// CommentLines returns the lines commented out with given comment strings,
+// or uncommented if they are all commented already: line comments are
+// inserted at the indentation common to the non-blank lines, so they stay
+// aligned and the indentation is preserved, and blank lines are skipped --
+// with Block, one block comment is put around the lines instead
+func CommentLines(lns []string, cs CommentStrs) []string {
+	ind, first, last := commonIndent(lns)
+	if first < 0 {
+		return lns
+	}
+	nlns := make([]string, len(lns))
+	copy(nlns, lns)
+	if cs.Block {
+		ftx := strings.TrimLeftFunc(lns[first], unicode.IsSpace)
+		ltx := strings.TrimRightFunc(lns[last], unicode.IsSpace)
+		st, ed := strings.TrimSpace(cs.St), strings.TrimSpace(cs.Ed)
+		if strings.HasPrefix(ftx, st) && strings.HasSuffix(ltx, ed) && (first != last || len(ftx) >= len(st)+len(ed)) {
+			fi := lns[first][:len(lns[first])-len(ftx)]
+			nlns[last] = removeEndMarker(lns[last], cs.Ed)
+			ftx = strings.TrimLeftFunc(nlns[first], unicode.IsSpace)
+			nlns[first] = fi + removeMarker(ftx, cs.St)
+			return nlns
+		}
+		nlns[first] = ind + cs.St + lns[first][len(ind):]
+		nlns[last] = strings.TrimRightFunc(nlns[last], unicode.IsSpace) + cs.Ed
+		return nlns
+	}
+	lm := strings.TrimSpace(cs.Ln)
+	all := true
+	for _, l := range lns {
+		txt := strings.TrimLeftFunc(l, unicode.IsSpace)
+		if txt != "" && !strings.HasPrefix(txt, lm) {
+			all = false
+			break
+		}
+	}
+	for i, l := range lns {
+		txt := strings.TrimLeftFunc(l, unicode.IsSpace)
+		if txt == "" {
+			continue
+		}
+		if all {
+			nlns[i] = l[:len(l)-len(txt)] + removeMarker(txt, cs.Ln)
+		} else {
+			nlns[i] = ind + cs.Ln + l[len(ind):]
+		}
+	}
+	return nlns
+}
+
+// CommentOut comments out the lines st to ed (exclusive) of the buffer,
+// or uncomments them if they are all commented already, using the comment
+// strings for its language (see LangCommentStrs and CommentLines)
+func (tv *TextView) CommentOut(st, ed int) bool {
+	tb := tv.Buf
+	if tb == nil {
+		return false
+	}
+	if ed > tb.NumLines() {
+		ed = tb.NumLines()
+	}
+	if st >= ed {
+		return false
+	}
+	cs := LangCommentStrs(tb.Info.Sup, &tb.Opts)
+	if strings.TrimSpace(cs.Ln) == "" && !cs.Block {
+		return false
+	}
+	lns := BufLines(tb)[st:ed]
+	nlns := CommentLines(lns, cs)
+	for i := range lns {
+		if lns[i] != nlns[i] {
+			ReplaceLines(tb, st, ed, nlns)
+			return true
+		}
+	}
+	return false
+}
@@ -69,7 +69,7 @@ func MarkupStdout(out []byte) []byte {
 }
 
 func MarkupStderr(out []byte) []byte {
-	sst := []byte(`<span style="color:red">`)
+	sst := []byte(`<span style="color:` + ColorHex(CurStatusColors.Error) + `">`)
 	est := []byte(`</span>`)
 	esz := len(sst) + len(est)
 
@@ -0,0 +1,209 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+)
+
+// CryptPrefs are the settings for editing encrypted files in the project:
+// age (.age) and sops (yaml, json, env, ini) files are decrypted when
+// opened and re-encrypted when saved, keeping the plaintext only in memory
+type CryptPrefs struct {
+	AgeIdentity   gi.FileName `desc:"age identity (private key) file used to decrypt age and sops files -- defaults to SOPS_AGE_KEY_FILE if set, else sops/age/keys.txt in the user config directory"`
+	AgeRecipients []string    `desc:"age recipients (public keys) that files are encrypted to when saved -- if empty, .age files are encrypted to the AgeIdentity, and sops files per the .sops.yaml creation rules"`
+}
+
+// FileCryptMap holds the kind of encryption (age or sops) of the files that
+// are open decrypted, by file name
+type FileCryptMap map[gi.FileName]string
+
+// AgeHeaders are the starts of age encrypted files, binary and armored
+var AgeHeaders = []string{"age-encryption.org/v1\n", "-----BEGIN AGE ENCRYPTED FILE-----"}
+
+// SopsExts are the extensions of files that may be encrypted with sops,
+// with the sops input / output type for each
+var SopsExts = map[string]string{
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".json": "json",
+	".env":  "dotenv",
+	".ini":  "ini",
+}
+
+// SopsMaxSize is the maximum size of files checked for sops encryption
+var SopsMaxSize = int64(1 << 20)
+
+// DetectCrypt returns the kind of encryption of the file with given name
+// and contents: age or sops, or "" if it is not encrypted
+func DetectCrypt(fpath string, b []byte) string {
+	for _, h := range AgeHeaders {
+		if bytes.HasPrefix(b, []byte(h)) {
+			return "age"
+		}
+	}
+	if _, ok := SopsExts[strings.ToLower(filepath.Ext(fpath))]; ok {
+		if bytes.Contains(b, []byte("ENC[AES256_GCM,")) && (bytes.Contains(b, []byte("sops_mac=")) || bytes.Contains(b, []byte("sops:")) || bytes.Contains(b, []byte(`"sops":`))) {
+			return "sops"
+		}
+	}
+	return ""
+}
+
+type cryptCacheEntry struct {
+	mod  time.Time
+	size int64
+	kind string
+}
+
+var cryptCache = map[string]cryptCacheEntry{}
+var cryptCacheMu sync.Mutex
+
+// EncryptedKind returns the kind of encryption of the file, age or sops, or
+// "" if it is not encrypted -- results are cached by modification time
+func EncryptedKind(fpath string) string {
+	fi, err := os.Stat(fpath)
+	if err != nil || fi.IsDir() {
+		return ""
+	}
+	cryptCacheMu.Lock()
+	ce, ok := cryptCache[fpath]
+	cryptCacheMu.Unlock()
+	if ok && ce.mod.Equal(fi.ModTime()) && ce.size == fi.Size() {
+		return ce.kind
+	}
+	ce = cryptCacheEntry{mod: fi.ModTime(), size: fi.Size()}
+	n := int64(len(AgeHeaders[1]))
+	if _, ok := SopsExts[strings.ToLower(filepath.Ext(fpath))]; ok && fi.Size() <= SopsMaxSize {
+		n = fi.Size() // sops metadata is at the end
+	}
+	if f, err := os.Open(fpath); err == nil {
+		b, _ := ioutil.ReadAll(io.LimitReader(f, n))
+		f.Close()
+		ce.kind = DetectCrypt(fpath, b)
+	}
+	cryptCacheMu.Lock()
+	cryptCache[fpath] = ce
+	cryptCacheMu.Unlock()
+	return ce.kind
+}
+
+// IdentityFile returns the age identity file, per AgeIdentity or the sops
+// defaults
+func (cp *CryptPrefs) IdentityFile() string {
+	if cp.AgeIdentity != "" {
+		return string(cp.AgeIdentity)
+	}
+	if kf := os.Getenv("SOPS_AGE_KEY_FILE"); kf != "" {
+		return kf
+	}
+	cd, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cd, "sops", "age", "keys.txt")
+}
+
+// cryptCmd returns the command for the kind of encryption, with the
+// identity file set for sops
+func (cp *CryptPrefs) cryptCmd(kind string, args ...string) *exec.Cmd {
+	cmd := exec.Command(kind, args...)
+	if kind == "sops" {
+		if kf := cp.IdentityFile(); kf != "" {
+			cmd.Env = append(os.Environ(), "SOPS_AGE_KEY_FILE="+kf)
+		}
+	}
+	return cmd
+}
+
+// runCrypt runs the command with given input, returning its output, or an
+// error with its stderr
+func runCrypt(cmd *exec.Cmd, in []byte) ([]byte, error) {
+	var out, serr bytes.Buffer
+	if in != nil {
+		cmd.Stdin = bytes.NewReader(in)
+	}
+	cmd.Stdout = &out
+	cmd.Stderr = &serr
+	if err := cmd.Run(); err != nil {
+		if es := strings.TrimSpace(serr.String()); es != "" {
+			return nil, fmt.Errorf("%v failed: %v: %v", cmd.Args[0], err, es)
+		}
+		return nil, fmt.Errorf("%v failed: %v", cmd.Args[0], err)
+	}
+	return out.Bytes(), nil
+}
+
+// Decrypt returns the plaintext of the file, encrypted with given kind of
+// encryption (age or sops)
+func (cp *CryptPrefs) Decrypt(kind, fpath string) ([]byte, error) {
+	switch kind {
+	case "age":
+		return runCrypt(cp.cryptCmd("age", "--decrypt", "-i", cp.IdentityFile(), fpath), nil)
+	case "sops":
+		return runCrypt(cp.cryptCmd("sops", "--decrypt", fpath), nil)
+	}
+	return nil, fmt.Errorf("encryption not supported: %v", kind)
+}
+
+// Encrypt returns the text encrypted for the file with given kind of
+// encryption (age or sops) -- age files are armored if the existing file is
+func (cp *CryptPrefs) Encrypt(kind, fpath string, txt []byte) ([]byte, error) {
+	switch kind {
+	case "age":
+		args := []string{"--encrypt"}
+		if b, err := ioutil.ReadFile(fpath); err == nil && bytes.HasPrefix(b, []byte(AgeHeaders[1])) {
+			args = append(args, "--armor")
+		}
+		if len(cp.AgeRecipients) == 0 {
+			args = append(args, "-i", cp.IdentityFile())
+		}
+		for _, r := range cp.AgeRecipients {
+			args = append(args, "-r", r)
+		}
+		return runCrypt(cp.cryptCmd("age", args...), txt)
+	case "sops":
+		if runtime.GOOS == "windows" {
+			return nil, fmt.Errorf("saving sops files is not supported on Windows, where sops can not read the text from /dev/stdin")
+		}
+		typ, ok := SopsExts[strings.ToLower(filepath.Ext(fpath))]
+		if !ok {
+			typ = "binary"
+		}
+		args := []string{"--encrypt", "--input-type", typ, "--output-type", typ, "--filename-override", fpath}
+		if len(cp.AgeRecipients) > 0 {
+			args = append(args, "--age", strings.Join(cp.AgeRecipients, ","))
+		}
+		return runCrypt(cp.cryptCmd("sops", append(args, "/dev/stdin")...), txt)
+	}
+	return nil, fmt.Errorf("encryption not supported: %v", kind)
+}
+
+// SaveEncrypted encrypts the text and writes it to the file -- the
+// plaintext is never written to disk.  It is written to a temp file in the
+// same directory first, which then replaces the file, so a failed write can
+// not leave it truncated.
+func (cp *CryptPrefs) SaveEncrypted(kind, fpath string, txt []byte) error {
+	b, err := cp.Encrypt(kind, fpath, txt)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return fmt.Errorf("%v produced no output", kind)
+	}
+	return WriteFileAtomic(fpath, b, 0600)
+}
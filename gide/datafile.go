@@ -0,0 +1,1835 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DataFormats maps file extensions to the format of data and config files,
+// which are checked as they are edited, shown in the Structure outline, and
+// converted among the formats: "json", "yaml" or "toml"
+var DataFormats = map[string]string{
+	".json":    "json",
+	".geojson": "json",
+	".yaml":    "yaml",
+	".yml":     "yaml",
+	".toml":    "toml",
+}
+
+// DataFormatNames are the names of the data formats, for messages
+var DataFormatNames = map[string]string{
+	"json": "JSON",
+	"yaml": "YAML",
+	"toml": "TOML",
+}
+
+// DataFormat returns the data format for given file name, or "" if it is
+// not a data file
+func DataFormat(fname string) string {
+	return DataFormats[strings.ToLower(filepath.Ext(fname))]
+}
+
+// DataKinds are the kinds of values in a data file
+type DataKinds int
+
+const (
+	// DataNull is a null value (YAML null or ~, or an empty value)
+	DataNull DataKinds = iota
+
+	// DataBool is a true or false value
+	DataBool
+
+	// DataNum is a number, with its literal text in the Val
+	DataNum
+
+	// DataStr is a string
+	DataStr
+
+	// DataTime is a date and / or time, with its literal text in the Val
+	DataTime
+
+	// DataList is a list (JSON / TOML array, YAML sequence) of the Kids
+	DataList
+
+	// DataMap is a map (JSON object, YAML mapping, TOML table) of the Kids
+	DataMap
+)
+
+// DataNode is a node in the tree of a parsed data file
+type DataNode struct {
+	Key  string      `desc:"key of the node in its parent map -- empty for list elements and the root"`
+	Kind DataKinds   `desc:"kind of value of the node"`
+	Val  string      `desc:"value of a scalar node: the unquoted string, or the literal text of the number, bool or time"`
+	Ln   int         `desc:"line of the node in the file (0-based)"`
+	Kids []*DataNode `desc:"elements of a list, or entries of a map, in file order"`
+}
+
+// IsScalar returns true if the node is not a list or map
+func (dn *DataNode) IsScalar() bool {
+	return dn.Kind < DataList
+}
+
+// Kid returns the entry of a map with given key, or nil
+func (dn *DataNode) Kid(key string) *DataNode {
+	if dn == nil || dn.Kind != DataMap {
+		return nil
+	}
+	for _, k := range dn.Kids {
+		if k.Key == key {
+			return k
+		}
+	}
+	return nil
+}
+
+// Copy returns a deep copy of the node, with given key
+func (dn *DataNode) Copy(key string) *DataNode {
+	cp := *dn
+	cp.Key = key
+	cp.Kids = make([]*DataNode, len(dn.Kids))
+	for i, k := range dn.Kids {
+		cp.Kids[i] = k.Copy(k.Key)
+	}
+	return &cp
+}
+
+// DataProblem is a syntax error or other problem in a data file
+type DataProblem struct {
+	Ln  int    `desc:"line of the problem (0-based)"`
+	Col int    `desc:"column of the problem in the line, in runes (0-based)"`
+	Msg string `desc:"description of the problem"`
+}
+
+func (dp DataProblem) Error() string {
+	return fmt.Sprintf("line %d, col %d: %s", dp.Ln+1, dp.Col+1, dp.Msg)
+}
+
+// DataMaxDepth is the maximum nesting depth of the lists and maps of a data
+// file
+var DataMaxDepth = 500
+
+// ParseData parses the text of a data file in given format ("json", "yaml"
+// or "toml"), returning the root node and the problems found -- the root is
+// nil if there is a syntax error, which is the last problem -- other
+// problems, e.g., duplicate keys, do not stop the parsing
+func ParseData(format string, src []byte) (*DataNode, []DataProblem) {
+	dp := &dataParser{}
+	var root *DataNode
+	var err error
+	switch format {
+	case "json":
+		root, err = (&dataJSONParser{dataParser: dp, src: src}).parse()
+	case "yaml":
+		root, err = (&dataYAMLParser{dataParser: dp}).parse(src)
+	case "toml":
+		root, err = (&dataTOMLParser{dataParser: dp, src: src}).parse()
+	default:
+		err = DataProblem{Msg: "unknown data format: " + format}
+	}
+	if err != nil {
+		dp.probs = append(dp.probs, err.(DataProblem))
+		return nil, dp.probs
+	}
+	return root, dp.probs
+}
+
+// dataParser has the state shared by the parsers of the formats
+type dataParser struct {
+	ln    int
+	probs []DataProblem
+	keys  map[*DataNode]map[string]int
+}
+
+func (p *dataParser) errorAt(ln, col int, f string, args ...interface{}) error {
+	return DataProblem{Ln: ln, Col: col, Msg: fmt.Sprintf(f, args...)}
+}
+
+// dataCol returns the column, in runes, of the byte offset in the text
+func dataCol(src []byte, pos int) int {
+	if pos > len(src) {
+		pos = len(src)
+	}
+	return utf8.RuneCount(src[bytes.LastIndexByte(src[:pos], '\n')+1 : pos])
+}
+
+// add adds the kid to the list or map, reporting a duplicate key of a map
+func (p *dataParser) add(nd, kd *DataNode) {
+	if nd.Kind == DataMap {
+		if p.keys == nil {
+			p.keys = make(map[*DataNode]map[string]int)
+		}
+		km := p.keys[nd]
+		if km == nil {
+			km = make(map[string]int)
+			p.keys[nd] = km
+		}
+		if ln, has := km[kd.Key]; has {
+			p.probs = append(p.probs, DataProblem{Ln: kd.Ln, Msg: fmt.Sprintf("duplicate key %q (also at line %d)", kd.Key, ln+1)})
+		} else {
+			km[kd.Key] = kd.Ln
+		}
+	}
+	nd.Kids = append(nd.Kids, kd)
+}
+
+var (
+	jsonNumRe   = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+	dataDateRe  = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}([Tt ][0-9]{2}:[0-9]{2}(:[0-9]{2}(\.[0-9]+)?)?([Zz]|[-+][0-9]{2}:[0-9]{2})?)?$`)
+	dataTimeRe  = regexp.MustCompile(`^[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?$`)
+	dataIntRe   = regexp.MustCompile(`^[-+]?(0|[1-9](_?[0-9])*)$`)
+	dataRadixRe = regexp.MustCompile(`^(0x[0-9a-fA-F](_?[0-9a-fA-F])*|0o[0-7](_?[0-7])*|0b[01](_?[01])*)$`)
+	dataFloatRe = regexp.MustCompile(`^[-+]?(0|[1-9](_?[0-9])*)((\.[0-9](_?[0-9])*)([eE][-+]?[0-9](_?[0-9])*)?|[eE][-+]?[0-9](_?[0-9])*)$`)
+	dataSpecRe  = regexp.MustCompile(`^[-+]?(inf|nan)$`)
+)
+
+// dataNumber returns the canonical text of a number literal of any of the
+// formats, e.g., 31 for 0x1F and 1000 for 1_000 -- special is true for
+// infinity and NaN, which are returned as inf, -inf or nan
+func dataNumber(s string) (num string, special bool) {
+	t := strings.TrimPrefix(strings.Replace(s, "_", "", -1), "+")
+	neg := strings.HasPrefix(t, "-")
+	body := strings.TrimPrefix(t, "-")
+	switch strings.ToLower(strings.TrimPrefix(body, ".")) {
+	case "inf":
+		if neg {
+			return "-inf", true
+		}
+		return "inf", true
+	case "nan":
+		return "nan", true
+	}
+	if len(body) > 2 && body[0] == '0' && strings.ContainsAny(body[1:2], "xXoObB") {
+		v, err := strconv.ParseInt(strings.ToLower(body), 0, 64)
+		if err != nil {
+			return t, false
+		}
+		if neg {
+			v = -v
+		}
+		return strconv.FormatInt(v, 10), false
+	}
+	if strings.ContainsAny(body, ".eE") {
+		if jsonNumRe.MatchString(t) {
+			return t, false
+		}
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return t, false
+		}
+		n := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(n, ".eE") {
+			n += ".0"
+		}
+		return n, false
+	}
+	body = strings.TrimLeft(body, "0")
+	if body == "" {
+		return "0", false
+	}
+	if neg {
+		return "-" + body, false
+	}
+	return body, false
+}
+
+////////////////////////////////////////////////////////////////////////////
+//  JSON
+
+type dataJSONParser struct {
+	*dataParser
+	src []byte
+	pos int
+}
+
+func (p *dataJSONParser) parse() (*DataNode, error) {
+	nd, err := p.value("", 0)
+	if err != nil {
+		return nil, err
+	}
+	p.space()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected text after the end of the document")
+	}
+	return nd, nil
+}
+
+// errorf returns an error at the current position
+func (p *dataJSONParser) errorf(f string, args ...interface{}) error {
+	return p.errorAt(p.ln, dataCol(p.src, p.pos), f, args...)
+}
+
+func (p *dataJSONParser) space() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '\n':
+			p.ln++
+		case ' ', '\t', '\r':
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+func (p *dataJSONParser) str() (string, error) {
+	st := p.pos
+	p.pos++ // opening quote
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == '\\':
+			p.pos++
+		case c == '\n':
+			return "", p.errorf("newline in string")
+		case c < ' ':
+			return "", p.errorf("control character %q in string", rune(c))
+		case c == '"':
+			p.pos++
+			var s string
+			if err := json.Unmarshal(p.src[st:p.pos], &s); err != nil {
+				return "", p.errorf("invalid escape in string: %s", p.src[st:p.pos])
+			}
+			return s, nil
+		}
+		p.pos++
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *dataJSONParser) value(key string, depth int) (*DataNode, error) {
+	p.space()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of the document")
+	}
+	if depth > DataMaxDepth {
+		return nil, p.errorf("lists and maps are nested too deeply")
+	}
+	nd := &DataNode{Key: key, Ln: p.ln}
+	switch c := p.src[p.pos]; c {
+	case '{', '[':
+		end := byte(']')
+		nd.Kind = DataList
+		if c == '{' {
+			nd.Kind, end = DataMap, '}'
+		}
+		p.pos++
+		p.space()
+		if p.pos < len(p.src) && p.src[p.pos] == end {
+			p.pos++
+			return nd, nil
+		}
+		for {
+			p.space()
+			k := ""
+			if nd.Kind == DataMap {
+				if p.pos >= len(p.src) || p.src[p.pos] != '"' {
+					return nil, p.errorf("expected a key string")
+				}
+				var err error
+				if k, err = p.str(); err != nil {
+					return nil, err
+				}
+				p.space()
+				if p.pos >= len(p.src) || p.src[p.pos] != ':' {
+					return nil, p.errorf("expected : after key %q", k)
+				}
+				p.pos++
+			}
+			kd, err := p.value(k, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			p.add(nd, kd)
+			p.space()
+			if p.pos < len(p.src) && p.src[p.pos] == end {
+				p.pos++
+				return nd, nil
+			}
+			if p.pos >= len(p.src) || p.src[p.pos] != ',' {
+				return nil, p.errorf("expected , or %c", end)
+			}
+			p.pos++
+			p.space()
+			if p.pos < len(p.src) && p.src[p.pos] == end {
+				return nil, p.errorf("trailing comma before %c", end)
+			}
+		}
+	case '"':
+		s, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		nd.Kind, nd.Val = DataStr, s
+	default:
+		st := p.pos
+		for p.pos < len(p.src) && strings.IndexByte("+-.0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		nd.Val = string(p.src[st:p.pos])
+		switch {
+		case nd.Val == "true" || nd.Val == "false":
+			nd.Kind = DataBool
+		case nd.Val == "null":
+			nd.Kind = DataNull
+		case jsonNumRe.MatchString(nd.Val):
+			nd.Kind = DataNum
+		case nd.Val == "":
+			r, _ := utf8.DecodeRune(p.src[p.pos:])
+			return nil, p.errorf("unexpected character %q", r)
+		default:
+			return nil, p.errorAt(p.ln, dataCol(p.src, st), "invalid value: %v", nd.Val)
+		}
+	}
+	return nd, nil
+}
+
+// FormatJSON returns the JSON text pretty-printed with given indent per
+// level, or minified if the indent is empty -- keys stay in order, and
+// numbers keep their text
+func FormatJSON(src []byte, indent string) ([]byte, error) {
+	if root, probs := ParseData("json", src); root == nil {
+		return nil, probs[len(probs)-1]
+	}
+	var out bytes.Buffer
+	if indent == "" {
+		if err := json.Compact(&out, src); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	}
+	if err := json.Indent(&out, bytes.TrimSpace(src), "", indent); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+//  YAML
+
+// dataYAMLParser parses the block and flow styles of YAML, with anchors,
+// aliases and merge keys, and literal and folded block scalars -- only the
+// first document of a stream is parsed, and complex (?) keys are not
+// supported
+type dataYAMLParser struct {
+	*dataParser
+	lns     []string
+	i       int
+	end     int
+	vset    bool
+	vind    int
+	vtxt    string
+	cerr    error
+	anchors map[string]*DataNode
+}
+
+func (p *dataYAMLParser) parse(src []byte) (*DataNode, error) {
+	p.lns = strings.Split(strings.Replace(string(src), "\r\n", "\n", -1), "\n")
+	p.end = len(p.lns)
+	p.anchors = make(map[string]*DataNode)
+	st, doc := 0, false
+scan:
+	for i, ln := range p.lns {
+		switch {
+		case !doc && strings.HasPrefix(ln, "%"):
+			st = i + 1
+		case ln == "---" || strings.HasPrefix(ln, "--- "):
+			if doc {
+				p.end = i
+				p.probs = append(p.probs, DataProblem{Ln: i, Msg: "only the first document of the stream is checked"})
+				break scan
+			}
+			doc = true
+			p.lns[i] = "   " + ln[3:]
+			st = i
+		case ln == "..." || strings.HasPrefix(ln, "... "):
+			p.end = i
+			break scan
+		case strings.TrimSpace(yamlStrip(ln)) != "":
+			doc = true
+		}
+	}
+	p.i = st
+	ind, _, ok := p.cur()
+	if !ok {
+		return &DataNode{Kind: DataNull}, nil
+	}
+	if err := p.cerr; err != nil {
+		return nil, err
+	}
+	nd, err := p.block("", ind)
+	if err != nil {
+		return nil, err
+	}
+	if ind, _, ok := p.cur(); ok {
+		if p.cerr != nil {
+			return nil, p.cerr
+		}
+		return nil, p.errorAt(p.i, p.indent(p.i), "unexpected text at indentation %d, after the end of the document", ind)
+	}
+	return nd, nil
+}
+
+// yamlStrip returns the text of the line without its comment and trailing
+// space
+func yamlStrip(ln string) string {
+	var q byte
+	for i := 0; i < len(ln); i++ {
+		c := ln[i]
+		switch {
+		case q == '"' && c == '\\':
+			i++
+		case q != 0:
+			if c == q {
+				q = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[{,:-", ln[i-1]) >= 0):
+			q = c
+		case c == '#' && (i == 0 || ln[i-1] == ' ' || ln[i-1] == '\t'):
+			return strings.TrimRight(ln[:i], " \t")
+		}
+	}
+	return strings.TrimRight(ln, " \t")
+}
+
+// cur skips blank and comment lines, returning the indentation and text of
+// the current line, or false at the end -- a tab in the indentation is
+// reported in cerr
+func (p *dataYAMLParser) cur() (int, string, bool) {
+	if p.vset {
+		return p.vind, p.vtxt, true
+	}
+	p.cerr = nil
+	for ; p.i < p.end; p.i++ {
+		ln := p.lns[p.i]
+		txt := strings.TrimLeft(ln, " ")
+		ind := len(ln) - len(txt)
+		txt = yamlStrip(txt)
+		if strings.TrimSpace(txt) == "" {
+			continue
+		}
+		if txt[0] == '\t' {
+			p.cerr = p.errorAt(p.i, p.indent(p.i), "tabs are not allowed in indentation")
+		}
+		p.ln = p.i
+		return ind, txt, true
+	}
+	return 0, "", false
+}
+
+// errorf returns an error at the current line, at the column of its text
+func (p *dataYAMLParser) errorf(f string, args ...interface{}) error {
+	return p.errorAt(p.ln, p.indent(p.ln), f, args...)
+}
+
+// indent returns the column of the text of given line: its indentation,
+// or the column of the rest of the line set by inline
+func (p *dataYAMLParser) indent(ln int) int {
+	if ln >= len(p.lns) {
+		return 0
+	}
+	l := p.lns[ln]
+	if p.vset && ln == p.ln && p.vind <= len(l) {
+		return utf8.RuneCountInString(l[:p.vind])
+	}
+	return len(l) - len(strings.TrimLeft(l, " "))
+}
+
+// next moves to the next line
+func (p *dataYAMLParser) next() {
+	p.vset = false
+	p.i++
+}
+
+// inline sets the rest of the current line, at given column, as the
+// current line, e.g., the item after a - in a sequence
+func (p *dataYAMLParser) inline(col int, txt string) {
+	p.vset, p.vind, p.vtxt = true, col, txt
+}
+
+func yamlIsSeqItem(txt string) bool {
+	return txt == "-" || strings.HasPrefix(txt, "- ") || strings.HasPrefix(txt, "-\t")
+}
+
+// yamlKey returns the key and the rest of the line after its colon, if the
+// text is a mapping entry
+func yamlKey(txt string) (key, rest string, ok bool, err string) {
+	if txt == "" || strings.IndexByte("[{&*!|>%@`", txt[0]) >= 0 {
+		return
+	}
+	if txt[0] == '?' && (len(txt) == 1 || txt[1] == ' ') {
+		return "", "", false, "complex (?) keys are not supported"
+	}
+	if txt[0] == '"' || txt[0] == '\'' {
+		s, n, closed := yamlQuoted(txt)
+		if !closed {
+			return
+		}
+		r := strings.TrimLeft(txt[n:], " ")
+		if r == ":" || strings.HasPrefix(r, ": ") {
+			return s, strings.TrimSpace(r[1:]), true, ""
+		}
+		return
+	}
+	for i := 0; i < len(txt); i++ {
+		if txt[i] == ':' && (i+1 == len(txt) || txt[i+1] == ' ' || txt[i+1] == '\t') {
+			return strings.TrimSpace(txt[:i]), strings.TrimSpace(txt[i+1:]), true, ""
+		}
+	}
+	return
+}
+
+// yamlQuoted returns the value of the quoted string at the start of the
+// text, and the length of its text, or false if it is not closed
+func yamlQuoted(txt string) (string, int, bool) {
+	q := txt[0]
+	var sb strings.Builder
+	for i := 1; i < len(txt); i++ {
+		c := txt[i]
+		switch {
+		case q == '\'' && c == '\'':
+			if i+1 < len(txt) && txt[i+1] == '\'' {
+				sb.WriteByte('\'')
+				i++
+				continue
+			}
+			return sb.String(), i + 1, true
+		case q == '"' && c == '"':
+			s, err := strconv.Unquote(yamlEscapes.Replace(txt[:i+1]))
+			if err != nil {
+				s = txt[1:i]
+			}
+			return s, i + 1, true
+		case q == '"' && c == '\\':
+			i++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+// yamlEscapes converts the YAML escapes in double quoted strings that
+// strconv.Unquote does not handle
+var yamlEscapes = strings.NewReplacer(`\\`, `\\`, `\/`, "/", `\ `, " ", `\0`, `\x00`, `\e`, `\x1b`, `\N`, `\u0085`, `\_`, `\u00a0`, `\L`, `\u2028`, `\P`, `\u2029`, "\\\t", "\t")
+
+// props strips the anchor and tag at the start of the value, returning the
+// anchor name and whether the tag is !!str
+func yamlProps(txt string) (rest, anchor string, str bool) {
+	for txt != "" && (txt[0] == '&' || txt[0] == '!') {
+		e := strings.IndexAny(txt, " \t")
+		if e < 0 {
+			e = len(txt)
+		}
+		if txt[0] == '&' {
+			anchor = txt[1:e]
+		} else if txt[:e] == "!!str" {
+			str = true
+		}
+		txt = strings.TrimLeft(txt[e:], " \t")
+	}
+	return txt, anchor, str
+}
+
+// block parses the node starting at the current line, at given indentation
+func (p *dataYAMLParser) block(key string, ind int) (*DataNode, error) {
+	if p.cerr != nil {
+		return nil, p.cerr
+	}
+	_, txt, _ := p.cur()
+	if yamlIsSeqItem(txt) {
+		return p.seq(key, ind)
+	}
+	if _, _, ok, msg := yamlKey(txt); ok || msg != "" {
+		if msg != "" {
+			return nil, p.errorf(msg)
+		}
+		return p.mapping(key, ind)
+	}
+	p.next()
+	return p.value(key, ind-1, txt)
+}
+
+func (p *dataYAMLParser) seq(key string, ind int) (*DataNode, error) {
+	nd := &DataNode{Key: key, Kind: DataList, Ln: p.ln}
+	for {
+		lind, txt, ok := p.cur()
+		if !ok || lind < ind || (lind == ind && !yamlIsSeqItem(txt)) {
+			return nd, nil
+		}
+		if p.cerr != nil {
+			return nil, p.cerr
+		}
+		if lind > ind {
+			return nil, p.errorf("bad indentation of a sequence entry")
+		}
+		ln := p.ln
+		rest := strings.TrimLeft(txt[1:], " \t")
+		kd, err := p.entry("", ind, lind+len(txt)-len(rest), rest)
+		if err != nil {
+			return nil, err
+		}
+		kd.Ln = ln
+		p.add(nd, kd)
+	}
+}
+
+func (p *dataYAMLParser) mapping(key string, ind int) (*DataNode, error) {
+	nd := &DataNode{Key: key, Kind: DataMap, Ln: p.ln}
+	var merges []*DataNode
+	for {
+		lind, txt, ok := p.cur()
+		if !ok || lind < ind {
+			break
+		}
+		if p.cerr != nil {
+			return nil, p.cerr
+		}
+		if lind > ind {
+			return nil, p.errorf("bad indentation of a mapping entry")
+		}
+		k, rest, ok, msg := yamlKey(txt)
+		if msg != "" {
+			return nil, p.errorf(msg)
+		}
+		if !ok {
+			if yamlIsSeqItem(txt) {
+				return nil, p.errorf("expected a key: value mapping entry, not a sequence entry")
+			}
+			return nil, p.errorf("expected a key: value mapping entry")
+		}
+		ln := p.ln
+		kd, err := p.entry(k, ind, lind+len(txt)-len(rest), rest)
+		if err != nil {
+			return nil, err
+		}
+		kd.Ln = ln
+		if k == "<<" {
+			merges = append(merges, kd)
+			continue
+		}
+		p.add(nd, kd)
+	}
+	for _, mg := range merges {
+		mps := []*DataNode{mg}
+		if mg.Kind == DataList {
+			mps = mg.Kids
+		}
+		for _, mp := range mps {
+			if mp.Kind != DataMap {
+				return nil, p.errorAt(mg.Ln, p.indent(mg.Ln), "the value of the merge key << must be a map or a list of maps")
+			}
+			for _, mk := range mp.Kids {
+				if nd.Kid(mk.Key) == nil {
+					nd.Kids = append(nd.Kids, mk.Copy(mk.Key))
+				}
+			}
+		}
+	}
+	return nd, nil
+}
+
+// entry parses the value of a mapping or sequence entry at given
+// indentation, with the rest of its line at given column
+func (p *dataYAMLParser) entry(key string, ind, col int, rest string) (*DataNode, error) {
+	rest, anchor, str := yamlProps(rest)
+	var kd *DataNode
+	var err error
+	if rest == "" {
+		p.next()
+		nind, ntxt, ok := p.cur()
+		switch {
+		case ok && nind > ind:
+			kd, err = p.block(key, nind)
+		case ok && nind == ind && key != "" && yamlIsSeqItem(ntxt):
+			kd, err = p.seq(key, ind)
+		default:
+			kd = &DataNode{Key: key, Kind: DataNull}
+		}
+	} else if yamlIsSeqItem(rest) || key == "" && !strings.ContainsAny(rest[:1], `"'[{`) && yamlIsMapEntry(rest) {
+		if key != "" {
+			return nil, p.errorf("a sequence cannot start on the line of its key")
+		}
+		p.inline(col, rest)
+		kd, err = p.block(key, col)
+	} else {
+		p.next()
+		kd, err = p.value(key, ind, rest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if str && kd.IsScalar() && kd.Kind != DataStr {
+		kd.Kind = DataStr
+	}
+	if anchor != "" {
+		p.anchors[anchor] = kd
+	}
+	return kd, nil
+}
+
+func yamlIsMapEntry(txt string) bool {
+	_, _, ok, msg := yamlKey(txt)
+	return ok || msg != ""
+}
+
+// value parses the scalar, flow collection or alias in the text, from the
+// previous line, continuing on the following lines indented more than
+// given indentation as needed
+func (p *dataYAMLParser) value(key string, ind int, txt string) (*DataNode, error) {
+	ln := p.ln
+	nd := &DataNode{Key: key, Ln: ln}
+	switch c := txt[0]; {
+	case c == '*':
+		an, ok := p.anchors[txt[1:]]
+		if !ok {
+			return nil, p.errorAt(ln, p.indent(ln), "unknown alias: %v", txt)
+		}
+		nd = an.Copy(key)
+		nd.Ln = ln
+	case c == '|' || c == '>':
+		nd.Kind, nd.Val = DataStr, p.blockScalar(ind, txt)
+	case c == '"' || c == '\'':
+		for {
+			s, n, ok := yamlQuoted(txt)
+			if ok {
+				if r := strings.TrimSpace(txt[n:]); r != "" {
+					if strings.HasPrefix(r, ":") {
+						return nil, p.errorAt(ln, p.indent(ln), "mapping values are not allowed here")
+					}
+					return nil, p.errorAt(p.ln, p.indent(p.ln), "unexpected text after the quoted string: %v", r)
+				}
+				nd.Kind, nd.Val = DataStr, s
+				break
+			}
+			if p.i >= p.end {
+				return nil, p.errorAt(ln, p.indent(ln), "unterminated quoted string")
+			}
+			nt := strings.TrimSpace(p.lns[p.i])
+			p.ln = p.i
+			p.next()
+			if nt == "" {
+				if c == '"' {
+					txt += `\n`
+				} else {
+					txt += "\n"
+				}
+				continue
+			}
+			if strings.HasSuffix(txt, `\n`) || strings.HasSuffix(txt, "\n") {
+				txt += nt
+			} else {
+				txt += " " + nt
+			}
+		}
+	case c == '[' || c == '{':
+		for yamlFlowOpen(txt) {
+			if p.i >= p.end {
+				return nil, p.errorAt(ln, p.indent(ln), "unterminated flow collection")
+			}
+			txt += " " + yamlStrip(strings.TrimSpace(p.lns[p.i]))
+			p.next()
+		}
+		fp := &yamlFlowParser{txt: txt}
+		fn, err := fp.value(key, 0)
+		if err == nil {
+			fp.space()
+			if fp.pos < len(fp.txt) {
+				err = fmt.Errorf("unexpected text after the flow collection: %v", fp.txt[fp.pos:])
+			}
+		}
+		if err != nil {
+			return nil, p.errorAt(ln, p.indent(ln), "%v", err)
+		}
+		p.setLine(fn, ln)
+		nd = fn
+	default:
+		for {
+			nind, ntxt, ok := p.cur()
+			if !ok || nind <= ind || p.cerr != nil {
+				break
+			}
+			if yamlIsMapEntry(ntxt) {
+				return nil, p.errorf("bad indentation of a mapping entry")
+			}
+			txt += " " + ntxt
+			p.next()
+		}
+		if strings.Contains(txt, ": ") || strings.HasSuffix(txt, ":") {
+			return nil, p.errorAt(ln, p.indent(ln), "mapping values are not allowed here")
+		}
+		nd.Val = txt
+		nd.Kind = yamlPlainKind(txt)
+	}
+	return nd, nil
+}
+
+func (p *dataYAMLParser) setLine(nd *DataNode, ln int) {
+	nd.Ln = ln
+	for _, k := range nd.Kids {
+		p.setLine(k, ln)
+	}
+}
+
+// blockScalar returns the text of the literal (|) or folded (>) block
+// scalar with given header, on the lines indented more than given
+// indentation
+func (p *dataYAMLParser) blockScalar(ind int, hdr string) string {
+	var lns []string
+	bind := -1
+	for ; p.i < p.end; p.i++ {
+		ln := p.lns[p.i]
+		t := strings.TrimLeft(ln, " ")
+		if t == "" {
+			lns = append(lns, "")
+			continue
+		}
+		li := len(ln) - len(t)
+		if bind < 0 {
+			bind = li
+		}
+		if li <= ind || li < bind {
+			break
+		}
+		lns = append(lns, ln[bind:])
+	}
+	p.vset = false
+	var txt string
+	if hdr[0] == '|' {
+		txt = strings.Join(lns, "\n")
+	} else {
+		var sb strings.Builder
+		for i, l := range lns {
+			switch {
+			case i == 0:
+			case l == "" || lns[i-1] == "" || strings.HasPrefix(l, " ") || strings.HasPrefix(lns[i-1], " "):
+				sb.WriteByte('\n')
+			default:
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(l)
+		}
+		txt = sb.String()
+	}
+	body := strings.TrimRight(txt, "\n")
+	switch {
+	case strings.Contains(hdr, "-"):
+		return body
+	case strings.Contains(hdr, "+"):
+		return txt + "\n"
+	case body == "":
+		return ""
+	}
+	return body + "\n"
+}
+
+// yamlFlowOpen returns true if the brackets of the flow collection at the
+// start of the text are not closed
+func yamlFlowOpen(txt string) bool {
+	depth := 0
+	var q byte
+	for i := 0; i < len(txt); i++ {
+		c := txt[i]
+		switch {
+		case q == '"' && c == '\\':
+			i++
+		case q != 0:
+			if c == q {
+				q = 0
+			}
+		case c == '"' || c == '\'':
+			q = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+			if depth == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// yamlPlainKind returns the kind of a plain (unquoted) scalar, per the core
+// schema of YAML 1.2 -- plus dates and times
+func yamlPlainKind(s string) DataKinds {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return DataNull
+	case "true", "True", "TRUE", "false", "False", "FALSE":
+		return DataBool
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF", "-.inf", "-.Inf", "-.INF", ".nan", ".NaN", ".NAN":
+		return DataNum
+	}
+	if yamlNumRe.MatchString(s) || dataRadixRe.MatchString(s) {
+		return DataNum
+	}
+	if dataDateRe.MatchString(s) {
+		return DataTime
+	}
+	return DataStr
+}
+
+var yamlNumRe = regexp.MustCompile(`^[-+]?([0-9]+|(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?)$`)
+
+// yamlFlowParser parses a flow collection, e.g., [a, b] or {a: 1}, joined
+// on one line
+type yamlFlowParser struct {
+	txt string
+	pos int
+}
+
+func (fp *yamlFlowParser) space() {
+	for fp.pos < len(fp.txt) && (fp.txt[fp.pos] == ' ' || fp.txt[fp.pos] == '\t') {
+		fp.pos++
+	}
+}
+
+func (fp *yamlFlowParser) scalar(key bool) (*DataNode, error) {
+	fp.space()
+	nd := &DataNode{}
+	if fp.pos < len(fp.txt) && (fp.txt[fp.pos] == '"' || fp.txt[fp.pos] == '\'') {
+		s, n, ok := yamlQuoted(fp.txt[fp.pos:])
+		if !ok {
+			return nil, fmt.Errorf("unterminated quoted string")
+		}
+		fp.pos += n
+		nd.Kind, nd.Val = DataStr, s
+		return nd, nil
+	}
+	st := fp.pos
+	for fp.pos < len(fp.txt) {
+		c := fp.txt[fp.pos]
+		if c == ',' || c == ']' || c == '}' || c == '[' || c == '{' || (c == ':' && (fp.pos+1 == len(fp.txt) || strings.IndexByte(" ,]}", fp.txt[fp.pos+1]) >= 0)) {
+			break
+		}
+		fp.pos++
+	}
+	nd.Val = strings.TrimSpace(fp.txt[st:fp.pos])
+	nd.Kind = yamlPlainKind(nd.Val)
+	if key {
+		nd.Kind = DataStr
+	}
+	return nd, nil
+}
+
+func (fp *yamlFlowParser) value(key string, depth int) (*DataNode, error) {
+	fp.space()
+	if fp.pos >= len(fp.txt) || fp.txt[fp.pos] != '[' && fp.txt[fp.pos] != '{' {
+		nd, err := fp.scalar(false)
+		if err != nil {
+			return nil, err
+		}
+		nd.Key = key
+		return nd, nil
+	}
+	if depth > DataMaxDepth {
+		return nil, fmt.Errorf("lists and maps are nested too deeply")
+	}
+	nd, end := &DataNode{Key: key, Kind: DataList}, byte(']')
+	if fp.txt[fp.pos] == '{' {
+		nd.Kind, end = DataMap, '}'
+	}
+	fp.pos++
+	keys := map[string]bool{}
+	for {
+		fp.space()
+		if fp.pos < len(fp.txt) && fp.txt[fp.pos] == end {
+			fp.pos++
+			return nd, nil
+		}
+		if fp.pos >= len(fp.txt) {
+			return nil, fmt.Errorf("expected %c", end)
+		}
+		var kd *DataNode
+		var err error
+		if nd.Kind == DataMap {
+			kn, err := fp.scalar(true)
+			if err != nil {
+				return nil, err
+			}
+			fp.space()
+			if fp.pos < len(fp.txt) && fp.txt[fp.pos] == ':' {
+				fp.pos++
+				kd, err = fp.value(kn.Val, depth+1)
+			} else {
+				kd = &DataNode{Key: kn.Val, Kind: DataNull}
+			}
+			if keys[kn.Val] {
+				return nil, fmt.Errorf("duplicate key %q", kn.Val)
+			}
+			keys[kn.Val] = true
+		} else {
+			kd, err = fp.value("", depth+1)
+		}
+		if err != nil {
+			return nil, err
+		}
+		nd.Kids = append(nd.Kids, kd)
+		fp.space()
+		if fp.pos < len(fp.txt) && fp.txt[fp.pos] == ',' {
+			fp.pos++
+			continue
+		}
+		if fp.pos >= len(fp.txt) || fp.txt[fp.pos] != end {
+			return nil, fmt.Errorf("expected , or %c", end)
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+//  TOML
+
+// dataTOMLParser parses TOML 1.0
+type dataTOMLParser struct {
+	*dataParser
+	src     []byte
+	pos     int
+	defined map[*DataNode]bool
+	dotted  map[*DataNode]bool
+	fixed   map[*DataNode]bool
+	aot     map[*DataNode]bool
+}
+
+func (p *dataTOMLParser) parse() (*DataNode, error) {
+	root := &DataNode{Kind: DataMap}
+	p.defined = map[*DataNode]bool{root: true}
+	p.dotted = map[*DataNode]bool{}
+	p.fixed = map[*DataNode]bool{}
+	p.aot = map[*DataNode]bool{}
+	cur := root
+	for {
+		p.spaceNL()
+		if p.pos >= len(p.src) {
+			return root, nil
+		}
+		var err error
+		if p.src[p.pos] == '[' {
+			cur, err = p.header(root)
+		} else {
+			err = p.keyVal(cur)
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.space()
+		if p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '\r' {
+			return nil, p.errorf("expected the end of the line, not: %v", p.rest())
+		}
+	}
+}
+
+// errorf returns an error at the current position
+func (p *dataTOMLParser) errorf(f string, args ...interface{}) error {
+	return p.errorAt(p.ln, dataCol(p.src, p.pos), f, args...)
+}
+
+// rest returns the rest of the current line, for messages
+func (p *dataTOMLParser) rest() string {
+	e := bytes.IndexByte(p.src[p.pos:], '\n')
+	if e < 0 {
+		e = len(p.src) - p.pos
+	}
+	return strings.TrimSpace(string(p.src[p.pos : p.pos+e]))
+}
+
+// space skips spaces and a comment
+func (p *dataTOMLParser) space() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == '#' {
+		for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+			p.pos++
+		}
+	}
+}
+
+// spaceNL skips spaces, comments and newlines
+func (p *dataTOMLParser) spaceNL() {
+	for {
+		p.space()
+		if p.pos < len(p.src) && (p.src[p.pos] == '\n' || p.src[p.pos] == '\r') {
+			if p.src[p.pos] == '\n' {
+				p.ln++
+			}
+			p.pos++
+			continue
+		}
+		return
+	}
+}
+
+func tomlIsBare(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// key returns the dotted key at the position
+func (p *dataTOMLParser) key() ([]string, error) {
+	var keys []string
+	for {
+		p.space()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("expected a key")
+		}
+		switch c := p.src[p.pos]; {
+		case c == '"' || c == '\'':
+			if bytes.HasPrefix(p.src[p.pos:], []byte{c, c, c}) {
+				return nil, p.errorf("multi-line strings cannot be keys")
+			}
+			s, err := p.str()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, s)
+		case tomlIsBare(c):
+			st := p.pos
+			for p.pos < len(p.src) && tomlIsBare(p.src[p.pos]) {
+				p.pos++
+			}
+			keys = append(keys, string(p.src[st:p.pos]))
+		default:
+			return nil, p.errorf("invalid key: %v", p.rest())
+		}
+		p.space()
+		if p.pos >= len(p.src) || p.src[p.pos] != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+// table returns the table at given key under the node, making it if needed
+func (p *dataTOMLParser) table(nd *DataNode, k string, dotted bool) (*DataNode, error) {
+	kd := nd.Kid(k)
+	switch {
+	case kd == nil:
+		kd = &DataNode{Key: k, Kind: DataMap, Ln: p.ln}
+		p.add(nd, kd)
+		p.dotted[kd] = dotted
+	case kd.Kind == DataList && p.aot[kd] && !dotted:
+		kd = kd.Kids[len(kd.Kids)-1]
+	case kd.Kind != DataMap:
+		return nil, p.errorf("key %q is already defined as a value at line %d", k, kd.Ln+1)
+	case p.fixed[kd]:
+		return nil, p.errorf("inline table %q cannot be extended", k)
+	case dotted && p.defined[kd] && !p.dotted[kd]:
+		return nil, p.errorf("table %q is already defined at line %d", k, kd.Ln+1)
+	}
+	return kd, nil
+}
+
+// header parses a [table] or [[array of tables]] header, returning its table
+func (p *dataTOMLParser) header(root *DataNode) (*DataNode, error) {
+	arr := bytes.HasPrefix(p.src[p.pos:], []byte("[["))
+	p.pos++
+	if arr {
+		p.pos++
+	}
+	keys, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	cl := "]"
+	if arr {
+		cl = "]]"
+	}
+	if !bytes.HasPrefix(p.src[p.pos:], []byte(cl)) {
+		return nil, p.errorf("expected %v after the table name", cl)
+	}
+	p.pos += len(cl)
+	nd := root
+	for _, k := range keys[:len(keys)-1] {
+		if nd, err = p.table(nd, k, false); err != nil {
+			return nil, err
+		}
+	}
+	k := keys[len(keys)-1]
+	kd := nd.Kid(k)
+	name := strings.Join(keys, ".")
+	if arr {
+		switch {
+		case kd == nil:
+			kd = &DataNode{Key: k, Kind: DataList, Ln: p.ln}
+			p.aot[kd] = true
+			p.add(nd, kd)
+		case !p.aot[kd]:
+			return nil, p.errorf("key %q is already defined at line %d, not as an array of tables", name, kd.Ln+1)
+		}
+		el := &DataNode{Kind: DataMap, Ln: p.ln}
+		p.defined[el] = true
+		kd.Kids = append(kd.Kids, el)
+		return el, nil
+	}
+	switch {
+	case kd == nil:
+		kd = &DataNode{Key: k, Kind: DataMap, Ln: p.ln}
+		p.add(nd, kd)
+	case kd.Kind != DataMap || p.fixed[kd]:
+		return nil, p.errorf("key %q is already defined as a value at line %d", name, kd.Ln+1)
+	case p.defined[kd] || p.dotted[kd]:
+		return nil, p.errorf("table [%v] is already defined at line %d", name, kd.Ln+1)
+	}
+	kd.Ln = p.ln
+	p.defined[kd] = true
+	return kd, nil
+}
+
+// keyVal parses a key = value line into the table
+func (p *dataTOMLParser) keyVal(nd *DataNode) error {
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+		return p.errorf("expected = after the key %v", strings.Join(keys, "."))
+	}
+	p.pos++
+	for _, k := range keys[:len(keys)-1] {
+		if nd, err = p.table(nd, k, true); err != nil {
+			return err
+		}
+	}
+	ln := p.ln
+	kd, err := p.value(keys[len(keys)-1], 0)
+	if err != nil {
+		return err
+	}
+	kd.Ln = ln
+	p.add(nd, kd)
+	return nil
+}
+
+func (p *dataTOMLParser) value(key string, depth int) (*DataNode, error) {
+	p.space()
+	if p.pos >= len(p.src) || p.src[p.pos] == '\n' || p.src[p.pos] == '\r' {
+		return nil, p.errorf("expected a value for %q", key)
+	}
+	if depth > DataMaxDepth {
+		return nil, p.errorf("arrays and tables are nested too deeply")
+	}
+	nd := &DataNode{Key: key, Ln: p.ln}
+	switch c := p.src[p.pos]; c {
+	case '"', '\'':
+		s, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		nd.Kind, nd.Val = DataStr, s
+	case '[':
+		nd.Kind = DataList
+		p.fixed[nd] = true
+		p.pos++
+		for {
+			p.spaceNL()
+			if p.pos < len(p.src) && p.src[p.pos] == ']' {
+				p.pos++
+				return nd, nil
+			}
+			kd, err := p.value("", depth+1)
+			if err != nil {
+				return nil, err
+			}
+			nd.Kids = append(nd.Kids, kd)
+			p.spaceNL()
+			if p.pos < len(p.src) && p.src[p.pos] == ',' {
+				p.pos++
+				continue
+			}
+			if p.pos >= len(p.src) || p.src[p.pos] != ']' {
+				return nil, p.errorf("expected , or ] in the array")
+			}
+		}
+	case '{':
+		nd.Kind = DataMap
+		p.pos++
+		p.space()
+		if p.pos < len(p.src) && p.src[p.pos] == '}' {
+			p.pos++
+			p.fixed[nd] = true
+			return nd, nil
+		}
+		for {
+			if err := p.keyVal(nd); err != nil {
+				return nil, err
+			}
+			p.space()
+			if p.pos < len(p.src) && p.src[p.pos] == '}' {
+				p.pos++
+				p.fixTree(nd)
+				return nd, nil
+			}
+			if p.pos >= len(p.src) || p.src[p.pos] != ',' {
+				return nil, p.errorf("expected , or } in the inline table (which must be on one line)")
+			}
+			p.pos++
+			p.space()
+			switch {
+			case p.pos >= len(p.src) || p.src[p.pos] == '\n' || p.src[p.pos] == '\r':
+				return nil, p.errorf("inline tables must be on one line")
+			case p.src[p.pos] == '}':
+				return nil, p.errorf("trailing comma in the inline table")
+			}
+		}
+	default:
+		st := p.pos
+		for p.pos < len(p.src) && (tomlIsBare(p.src[p.pos]) || strings.IndexByte("+.:", p.src[p.pos]) >= 0) {
+			p.pos++
+		}
+		lit := string(p.src[st:p.pos])
+		if len(lit) == 10 && p.pos+1 < len(p.src) && p.src[p.pos] == ' ' && p.src[p.pos+1] >= '0' && p.src[p.pos+1] <= '9' {
+			e := p.pos + 1
+			for e < len(p.src) && (tomlIsBare(p.src[e]) || strings.IndexByte("+.:", p.src[e]) >= 0) {
+				e++
+			}
+			if dataDateRe.MatchString(string(p.src[st:e])) {
+				p.pos = e
+				lit = string(p.src[st:e])
+			}
+		}
+		nd.Val = lit
+		switch {
+		case lit == "true" || lit == "false":
+			nd.Kind = DataBool
+		case dataIntRe.MatchString(lit) || dataRadixRe.MatchString(lit) || dataFloatRe.MatchString(lit) || dataSpecRe.MatchString(lit):
+			nd.Kind = DataNum
+		case dataDateRe.MatchString(lit) || dataTimeRe.MatchString(lit):
+			nd.Kind = DataTime
+		case lit == "":
+			r, _ := utf8.DecodeRune(p.src[p.pos:])
+			return nil, p.errorf("unexpected character %q", r)
+		default:
+			return nil, p.errorAt(p.ln, dataCol(p.src, st), "invalid value: %v", lit)
+		}
+	}
+	return nd, nil
+}
+
+// fixTree marks the inline table and its sub-tables as not extendable
+func (p *dataTOMLParser) fixTree(nd *DataNode) {
+	p.fixed[nd] = true
+	for _, k := range nd.Kids {
+		if k.Kind == DataMap {
+			p.fixTree(k)
+		}
+	}
+}
+
+// str parses a basic (") or literal (') string, or a multi-line one with
+// the quotes tripled
+func (p *dataTOMLParser) str() (string, error) {
+	q := p.src[p.pos]
+	ml := bytes.HasPrefix(p.src[p.pos:], []byte{q, q, q})
+	ln, col := p.ln, dataCol(p.src, p.pos)
+	if ml {
+		p.pos += 3
+		if bytes.HasPrefix(p.src[p.pos:], []byte("\r\n")) {
+			p.pos++
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == '\n' {
+			p.pos++
+			p.ln++
+		}
+	} else {
+		p.pos++
+	}
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == q && !ml:
+			p.pos++
+			return sb.String(), nil
+		case c == q && bytes.HasPrefix(p.src[p.pos:], []byte{q, q, q}):
+			n := 3
+			for n < 5 && p.pos+n < len(p.src) && p.src[p.pos+n] == q {
+				n++
+			}
+			sb.WriteString(strings.Repeat(string(q), n-3))
+			p.pos += n
+			return sb.String(), nil
+		case c == '\n':
+			if !ml {
+				return "", p.errorf("newline in string")
+			}
+			p.ln++
+			sb.WriteByte(c)
+		case c == '\\' && q == '"':
+			p.pos++
+			if p.pos >= len(p.src) {
+				break
+			}
+			e := p.src[p.pos]
+			if ml && (e == ' ' || e == '\t' || e == '\r' || e == '\n') {
+				// line ending backslash trims the whitespace up to the next text
+				for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+					if p.src[p.pos] == '\n' {
+						p.ln++
+					}
+					p.pos++
+				}
+				continue
+			}
+			if r, ok := tomlEscapes[e]; ok {
+				sb.WriteByte(r)
+				break
+			}
+			n := map[byte]int{'u': 4, 'U': 8}[e]
+			if n == 0 || p.pos+n >= len(p.src) {
+				return "", p.errorf("invalid escape in string: \\%c", e)
+			}
+			v, err := strconv.ParseUint(string(p.src[p.pos+1:p.pos+1+n]), 16, 32)
+			if err != nil || !utf8.ValidRune(rune(v)) {
+				return "", p.errorf("invalid unicode escape in string: \\%c%s", e, p.src[p.pos+1:p.pos+1+n])
+			}
+			sb.WriteRune(rune(v))
+			p.pos += n
+		case c < ' ' && c != '\t' && c != '\r':
+			return "", p.errorf("control character %q in string", rune(c))
+		default:
+			sb.WriteByte(c)
+		}
+		p.pos++
+	}
+	return "", p.errorAt(ln, col, "unterminated string")
+}
+
+var tomlEscapes = map[byte]byte{'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r', 'e': 0x1b, '"': '"', '\\': '\\'}
+
+////////////////////////////////////////////////////////////////////////////
+//  emit
+
+// EmitData returns the text of the data tree in given format: "json" (with
+// given indent per level), "yaml" or "toml" -- an error is returned for
+// values that the format does not have, e.g., null in TOML, and for
+// duplicate keys, which the parsers keep (see dataDupKey)
+func EmitData(root *DataNode, format, indent string) ([]byte, error) {
+	if err := dataDupKey(root, nil); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "json":
+		err = emitJSON(&buf, root, indent, 0)
+		buf.WriteByte('\n')
+	case "yaml":
+		if root.IsScalar() || len(root.Kids) == 0 {
+			buf.WriteString(yamlValue(root) + "\n")
+		} else {
+			emitYAML(&buf, root, 0, false)
+		}
+	case "toml":
+		if root.Kind != DataMap {
+			return nil, fmt.Errorf("the top level of a TOML document must be a table (map)")
+		}
+		err = emitTOML(&buf, root, nil)
+		b := bytes.TrimLeft(buf.Bytes(), "\n")
+		buf = *bytes.NewBuffer(b)
+	default:
+		err = fmt.Errorf("unknown data format: %v", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// dataDupKey returns an error for the first duplicate key of a map in the
+// tree, which would be emitted as is, giving invalid TOML and ambiguous
+// JSON or YAML
+func dataDupKey(nd *DataNode, path []string) error {
+	if nd.Kind == DataMap {
+		lns := make(map[string]int, len(nd.Kids))
+		for _, k := range nd.Kids {
+			if ln, has := lns[k.Key]; has {
+				return fmt.Errorf("duplicate key %v at line %d (also at line %d) -- remove one of them first", dataPath(path, k.Key), k.Ln+1, ln+1)
+			}
+			lns[k.Key] = k.Ln
+		}
+	}
+	for _, k := range nd.Kids {
+		kp := path
+		if k.Key != "" {
+			kp = append(path[:len(path):len(path)], k.Key)
+		}
+		if err := dataDupKey(k, kp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dataPath returns the path of keys to the node, for messages
+func dataPath(path []string, key string) string {
+	if key == "" {
+		return strings.Join(path, ".")
+	}
+	return strings.Join(append(path[:len(path):len(path)], key), ".")
+}
+
+// jsonQuote returns the JSON string for s, without escaping HTML chars
+func jsonQuote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+func emitJSON(buf *bytes.Buffer, nd *DataNode, indent string, depth int) error {
+	switch nd.Kind {
+	case DataNull:
+		buf.WriteString("null")
+	case DataBool:
+		buf.WriteString(strings.ToLower(nd.Val))
+	case DataNum:
+		n, spec := dataNumber(nd.Val)
+		if spec {
+			return fmt.Errorf("JSON has no %v number, at line %d", n, nd.Ln+1)
+		}
+		buf.WriteString(n)
+	case DataStr, DataTime:
+		buf.WriteString(jsonQuote(nd.Val))
+	default:
+		open, cl := "[", "]"
+		if nd.Kind == DataMap {
+			open, cl = "{", "}"
+		}
+		buf.WriteString(open)
+		for i, k := range nd.Kids {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			if indent != "" {
+				buf.WriteString("\n" + strings.Repeat(indent, depth+1))
+			}
+			if nd.Kind == DataMap {
+				buf.WriteString(jsonQuote(k.Key) + ":")
+				if indent != "" {
+					buf.WriteString(" ")
+				}
+			}
+			if err := emitJSON(buf, k, indent, depth+1); err != nil {
+				return err
+			}
+		}
+		if indent != "" && len(nd.Kids) > 0 {
+			buf.WriteString("\n" + strings.Repeat(indent, depth))
+		}
+		buf.WriteString(cl)
+	}
+	return nil
+}
+
+// yamlQuote returns s as a plain scalar if it would be read back as the
+// same string, and otherwise as a double quoted string
+func yamlQuote(s string) string {
+	if s == "" || yamlPlainKind(strings.Replace(s, "_", "", -1)) != DataStr || yamlBools[strings.ToLower(s)] || strings.IndexByte("-?:,[]{}#&*!|>'\"%@` \t", s[0]) >= 0 ||
+		strings.HasSuffix(s, " ") || strings.HasSuffix(s, ":") || strings.Contains(s, ": ") || strings.Contains(s, " #") ||
+		strings.IndexFunc(s, func(r rune) bool { return r < ' ' || r == 0x7f || r == 0xfeff }) >= 0 {
+		return jsonQuote(s)
+	}
+	return s
+}
+
+// yamlBools are the strings that YAML 1.1 reads as bools, which are
+// quoted for older parsers
+var yamlBools = map[string]bool{"y": true, "n": true, "yes": true, "no": true, "on": true, "off": true}
+
+// yamlValue returns the text of a scalar, or an empty list or map
+func yamlValue(nd *DataNode) string {
+	switch nd.Kind {
+	case DataNull:
+		return "null"
+	case DataBool:
+		return strings.ToLower(nd.Val)
+	case DataNum:
+		n, spec := dataNumber(nd.Val)
+		if spec {
+			return strings.Replace("."+n, ".-", "-.", 1)
+		}
+		return n
+	case DataTime:
+		if strings.Contains(nd.Val, "-") {
+			return nd.Val
+		}
+		return jsonQuote(nd.Val)
+	case DataList:
+		return "[]"
+	case DataMap:
+		return "{}"
+	}
+	return yamlQuote(nd.Val)
+}
+
+// emitYAML writes the block form of the list or map at given indentation
+// -- if cont, the line is already at the indentation, after a -
+func emitYAML(buf *bytes.Buffer, nd *DataNode, ind int, cont bool) {
+	pre := strings.Repeat(" ", ind)
+	for i, k := range nd.Kids {
+		if i > 0 || !cont {
+			buf.WriteString(pre)
+		}
+		if nd.Kind == DataMap {
+			buf.WriteString(yamlQuote(k.Key) + ":")
+		} else {
+			buf.WriteString("-")
+		}
+		if k.IsScalar() || len(k.Kids) == 0 {
+			buf.WriteString(" " + yamlValue(k) + "\n")
+			continue
+		}
+		if nd.Kind == DataMap {
+			buf.WriteString("\n")
+			emitYAML(buf, k, ind+2, false)
+			continue
+		}
+		buf.WriteString(" ")
+		emitYAML(buf, k, ind+2, true)
+	}
+}
+
+var tomlBareRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlKey returns the key bare if possible, and otherwise quoted
+func tomlKey(k string) string {
+	if tomlBareRe.MatchString(k) {
+		return k
+	}
+	return tomlQuote(k)
+}
+
+// tomlQuote returns s as a TOML basic string
+func tomlQuote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if r < ' ' || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// tomlIsTables returns true if the node is a non-empty list of maps, which
+// is written as an array of tables
+func tomlIsTables(nd *DataNode) bool {
+	if nd.Kind != DataList || len(nd.Kids) == 0 {
+		return false
+	}
+	for _, k := range nd.Kids {
+		if k.Kind != DataMap {
+			return false
+		}
+	}
+	return true
+}
+
+// tomlValue returns the inline text of the value
+func tomlValue(nd *DataNode, path []string) (string, error) {
+	switch nd.Kind {
+	case DataNull:
+		return "", fmt.Errorf("TOML has no null value, at: %v (line %d)", dataPath(path, nd.Key), nd.Ln+1)
+	case DataBool:
+		return strings.ToLower(nd.Val), nil
+	case DataNum:
+		n, _ := dataNumber(nd.Val)
+		return n, nil
+	case DataTime:
+		return nd.Val, nil
+	case DataStr:
+		return tomlQuote(nd.Val), nil
+	}
+	var vals []string
+	for _, k := range nd.Kids {
+		v, err := tomlValue(k, append(path, nd.Key))
+		if err != nil {
+			return "", err
+		}
+		if nd.Kind == DataMap {
+			v = tomlKey(k.Key) + " = " + v
+		}
+		vals = append(vals, v)
+	}
+	if nd.Kind == DataMap {
+		if len(vals) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(vals, ", ") + " }", nil
+	}
+	return "[" + strings.Join(vals, ", ") + "]", nil
+}
+
+// emitTOML writes the values of the table at given path, followed by its
+// sub-tables and arrays of tables
+func emitTOML(buf *bytes.Buffer, nd *DataNode, path []string) error {
+	var subs []*DataNode
+	for _, k := range nd.Kids {
+		if (k.Kind == DataMap && len(k.Kids) > 0) || tomlIsTables(k) {
+			subs = append(subs, k)
+			continue
+		}
+		v, err := tomlValue(k, path)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(tomlKey(k.Key) + " = " + v + "\n")
+	}
+	for _, k := range subs {
+		kpath := append(path[:len(path):len(path)], tomlKey(k.Key))
+		hdr := strings.Join(kpath, ".")
+		els := []*DataNode{k}
+		if k.Kind == DataList {
+			els = k.Kids
+		}
+		for _, el := range els {
+			if k.Kind == DataList {
+				buf.WriteString("\n[[" + hdr + "]]\n")
+			} else if tomlHasValues(el) {
+				buf.WriteString("\n[" + hdr + "]\n")
+			}
+			if err := emitTOML(buf, el, kpath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tomlHasValues returns true if the table has values that are not written
+// as sub-tables, so it needs its own [header]
+func tomlHasValues(nd *DataNode) bool {
+	for _, k := range nd.Kids {
+		if !(k.Kind == DataMap && len(k.Kids) > 0) && !tomlIsTables(k) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDataErrors(t *testing.T) {
+	tests := []struct {
+		format string
+		src    string
+		ln     int
+		col    int
+		msg    string
+	}{
+		{"json", `{"a": 1,}`, 0, 8, "trailing comma before }"},
+		{"json", "{\n  \"a\" 1\n}", 1, 6, `expected : after key "a"`},
+		{"json", "[1, 2", 0, 5, "expected , or ]"},
+		{"json", "{\"a\": \"b\n\"}", 0, 8, "newline in string"},
+		{"json", "[1, tru]", 0, 4, "invalid value: tru"},
+		{"json", `{"é": 1 x}`, 0, 8, "expected , or }"},
+		{"yaml", "a: 1\n\tb: 2", 1, 0, "tabs are not allowed in indentation"},
+		{"yaml", "a:\n  b: 1\n   c: 2", 2, 3, "bad indentation of a mapping entry"},
+		{"yaml", "a:\n  - b: 1\n    - c", 2, 4, "not a sequence entry"},
+		{"yaml", "a: *x", 0, 0, "unknown alias: *x"},
+		{"yaml", "- a\nb: 1", 1, 0, "after the end of the document"},
+		{"toml", "a = 1\nb = \n", 1, 4, `expected a value for "b"`},
+		{"toml", "a = \"x", 0, 4, "unterminated string"},
+		{"toml", "a = tru", 0, 4, "invalid value: tru"},
+		{"toml", "a = 1 2", 0, 6, "expected the end of the line"},
+		{"toml", "[t]\na = 1\n[t]\n", 2, 3, "table [t] is already defined at line 1"},
+	}
+	for _, ts := range tests {
+		root, probs := ParseData(ts.format, []byte(ts.src))
+		if root != nil || len(probs) == 0 {
+			t.Errorf("ParseData %v %q error: should fail, got: %v\n", ts.format, ts.src, probs)
+			continue
+		}
+		pb := probs[len(probs)-1]
+		if pb.Ln != ts.ln || pb.Col != ts.col || !strings.Contains(pb.Msg, ts.msg) {
+			t.Errorf("ParseData %v %q error: should be line %d, col %d: %v, got: %v\n", ts.format, ts.src, ts.ln+1, ts.col+1, ts.msg, pb)
+		}
+	}
+}
+
+func TestParseDataDuplicateKey(t *testing.T) {
+	for _, ts := range []struct{ format, src string }{
+		{"json", "{\"a\": 1,\n \"a\": 2}"},
+		{"yaml", "a: 1\na: 2\n"},
+		{"toml", "a = 1\na = 2\n"},
+	} {
+		root, probs := ParseData(ts.format, []byte(ts.src))
+		if root == nil || len(probs) != 1 || probs[0].Ln != 1 || !strings.Contains(probs[0].Msg, `duplicate key "a"`) {
+			t.Errorf("ParseData %v %q error: should report the duplicate key on line 2, got: %v\n", ts.format, ts.src, probs)
+		}
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	tests := []struct {
+		src    string
+		pretty string
+		min    string
+	}{
+		{`{"a":[1,2,{"b":null}],"c":"x y","d":{}}`,
+			"{\n  \"a\": [\n    1,\n    2,\n    {\n      \"b\": null\n    }\n  ],\n  \"c\": \"x y\",\n  \"d\": {}\n}\n",
+			`{"a":[1,2,{"b":null}],"c":"x y","d":{}}`},
+		{"{ \"z\" : [ ] ,\n \"a\" : 1.50e3 }",
+			"{\n  \"z\": [],\n  \"a\": 1.50e3\n}\n",
+			`{"z":[],"a":1.50e3}`},
+		{" [ \"<é>\" ] \n",
+			"[\n  \"<é>\"\n]\n",
+			`["<é>"]`},
+	}
+	for _, ts := range tests {
+		pretty, err := FormatJSON([]byte(ts.src), "  ")
+		if err != nil || string(pretty) != ts.pretty {
+			t.Errorf("FormatJSON %q error: should pretty-print to %q, got: %q, %v\n", ts.src, ts.pretty, pretty, err)
+		}
+		min, err := FormatJSON([]byte(ts.src), "")
+		if err != nil || string(min) != ts.min {
+			t.Errorf("FormatJSON %q error: should minify to %q, got: %q, %v\n", ts.src, ts.min, min, err)
+		}
+		// round trips
+		if rt, err := FormatJSON(pretty, ""); err != nil || string(rt) != ts.min {
+			t.Errorf("FormatJSON %q error: minified pretty text should be %q, got: %q, %v\n", ts.src, ts.min, rt, err)
+		}
+		if rt, err := FormatJSON(min, "  "); err != nil || string(rt) != ts.pretty {
+			t.Errorf("FormatJSON %q error: pretty-printed minified text should be %q, got: %q, %v\n", ts.src, ts.pretty, rt, err)
+		}
+	}
+	if _, err := FormatJSON([]byte(`[1,]`), "  "); err == nil {
+		t.Errorf("FormatJSON error: should fail on a trailing comma, got: nil\n")
+	}
+}
+
+func TestEmitData(t *testing.T) {
+	srcs := map[string]string{
+		"json": "{\n  \"name\": \"gide\",\n  \"n\": 3,\n  \"pi\": 3.5,\n  \"ok\": true,\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ],\n  \"sub\": {\n    \"x\": 1\n  }\n}\n",
+		"yaml": "name: gide\n\"n\": 3\npi: 3.5\nok: true\ntags:\n  - a\n  - b\nsub:\n  x: 1\n",
+		"toml": "name = \"gide\"\nn = 3\npi = 3.5\nok = true\ntags = [\"a\", \"b\"]\n\n[sub]\nx = 1\n",
+	}
+	for _, from := range []string{"json", "yaml", "toml"} {
+		for _, to := range []string{"json", "yaml", "toml"} {
+			root, probs := ParseData(from, []byte(srcs[from]))
+			if root == nil || len(probs) > 0 {
+				t.Errorf("ParseData %v error: should parse, got: %v\n", from, probs)
+				continue
+			}
+			out, err := EmitData(root, to, "  ")
+			if err != nil || string(out) != srcs[to] {
+				t.Errorf("EmitData %v to %v error: should be %q, got: %q, %v\n", from, to, srcs[to], out, err)
+			}
+		}
+	}
+
+	errs := []struct{ from, src, to, msg string }{
+		{"json", `{"a": null}`, "toml", "TOML has no null value"},
+		{"json", `[1]`, "toml", "top level of a TOML document must be a table"},
+		{"json", "{\"a\": 1,\n \"a\": 2}", "yaml", "duplicate key a at line 2 (also at line 1)"},
+		{"json", `{"b": [{"c": 1, "c": 2}]}`, "json", "duplicate key b.c at line 1"},
+		{"yaml", "a:\n  b: 1\n  b: 2\n", "toml", "duplicate key a.b at line 3 (also at line 2)"},
+		{"toml", "a = 1\na = 2\n", "json", "duplicate key a at line 2"},
+	}
+	for _, ts := range errs {
+		root, _ := ParseData(ts.from, []byte(ts.src))
+		if _, err := EmitData(root, ts.to, ""); err == nil || !strings.Contains(err.Error(), ts.msg) {
+			t.Errorf("EmitData %v %q to %v error: should fail with %q, got: %v\n", ts.from, ts.src, ts.to, ts.msg, err)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// DataOutlineMax is the maximum number of keys and elements shown in the
+// Structure outline of a data file
+var DataOutlineMax = 5000
+
+// DataOutlineOpenDepth is the depth to which the Structure outline is
+// opened when first shown
+var DataOutlineOpenDepth = 2
+
+// DataLabelMax is the maximum number of chars of the values shown in the
+// Structure outline
+var DataLabelMax = 40
+
+// DataTreeNode is a key or list element in the Structure outline of a data
+// file -- the name is the key with the value of a scalar, or the number of
+// entries of a list or map, which are its children
+type DataTreeNode struct {
+	ki.Node
+	Ln      int    `desc:"line of the node in the file (0-based)"`
+	KeyPath string `desc:"path of keys and list indexes to the node, for keeping the outline open across updates"`
+}
+
+var KiT_DataTreeNode = kit.Types.AddType(&DataTreeNode{}, ki.Props{"EnumType:Flag": ki.KiT_Flags})
+
+// DataView shows the structure of a JSON, YAML or TOML file in a
+// collapsible tree, for jumping to its keys -- it is updated when the file
+// is checked as it is edited (see TextView.CheckData)
+type DataView struct {
+	gi.Layout
+	Gide     Gide          `json:"-" xml:"-" desc:"parent gide project"`
+	Buf      *giv.TextBuf  `json:"-" xml:"-" desc:"buffer of the data file"`
+	Format   string        `desc:"data format of the file -- see DataFormats"`
+	DataRoot DataTreeNode  `desc:"root of the outline tree"`
+	TreeView *giv.TreeView `json:"-" xml:"-" desc:"the tree view of the outline"`
+}
+
+var KiT_DataView = kit.Types.AddType(&DataView{}, DataViewProps)
+
+// Config configures the view for the data file in given buffer
+func (dv *DataView) Config(ge Gide, tb *giv.TextBuf) {
+	dv.Gide = ge
+	dv.Buf = tb
+	dv.Format = DataFormat(string(tb.Filename))
+	dv.Lay = gi.LayoutVert
+	dv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "toolbar")
+	config.Add(gi.KiT_Frame, "data-tree")
+	mods, updt := dv.ConfigChildren(config, false)
+	if !mods {
+		updt = dv.UpdateStart()
+	}
+	dv.ConfigToolbar()
+	dv.Refresh()
+	dv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (dv *DataView) ToolBar() *gi.ToolBar {
+	return dv.ChildByName("toolbar", 0).(*gi.ToolBar)
+}
+
+// DataTree returns the frame holding the outline tree
+func (dv *DataView) DataTree() *gi.Frame {
+	return dv.ChildByName("data-tree", 1).(*gi.Frame)
+}
+
+// ConfigToolbar adds toolbar.
+func (dv *DataView) ConfigToolbar() {
+	tb := dv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "parse the file again and update the outline"},
+		dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dvv, _ := recv.Embed(KiT_DataView).(*DataView)
+			dvv.Refresh()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Expand All", Icon: "wedge-down", Tooltip: "open all the lists and maps of the outline"},
+		dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dvv, _ := recv.Embed(KiT_DataView).(*DataView)
+			if dvv.TreeView != nil {
+				dvv.TreeView.OpenAll()
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Collapse All", Icon: "wedge-right", Tooltip: "close all the lists and maps of the outline"},
+		dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dvv, _ := recv.Embed(KiT_DataView).(*DataView)
+			if dvv.TreeView != nil {
+				dvv.TreeView.CloseAll()
+				dvv.TreeView.Open()
+			}
+		})
+	gi.AddNewLabel(tb, "info", "")
+}
+
+// Refresh parses the file again and updates the outline
+func (dv *DataView) Refresh() {
+	if dv.Buf == nil || dv.Format == "" {
+		dv.Update(nil, nil)
+		return
+	}
+	dv.Update(ParseData(dv.Format, dv.Buf.LinesToBytesCopy()))
+}
+
+// Update shows the outline of the parsed root of the file, with the
+// problems found -- if the root is nil because of a syntax error, the
+// previous outline is kept, with the error shown in the toolbar
+func (dv *DataView) Update(root *DataNode, probs []DataProblem) {
+	var info string
+	switch {
+	case dv.Format == "":
+		fnm := ""
+		if dv.Buf != nil {
+			fnm = string(dv.Buf.Filename)
+		}
+		info = fmt.Sprintf("Structure is only available for JSON, YAML and TOML files, not: %v", fnm)
+	case root == nil:
+		info = fmt.Sprintf("%v syntax error: %v", DataFormatNames[dv.Format], probs[len(probs)-1])
+	default:
+		info = fmt.Sprintf("%v: %v values", DataFormatNames[dv.Format], dataCount(root))
+		if len(probs) > 0 {
+			info += fmt.Sprintf(", %v problems", len(probs))
+		}
+	}
+	dv.ToolBar().ChildByName("info", 3).(*gi.Label).SetText(info)
+	if root == nil && dv.Format != "" {
+		return
+	}
+	dv.ConfigTree(root)
+}
+
+// dataCount returns the number of values under the node
+func dataCount(nd *DataNode) int {
+	n := 0
+	for _, k := range nd.Kids {
+		n += 1 + dataCount(k)
+	}
+	return n
+}
+
+// dataTreeLabel returns the label of a node in the outline
+func dataTreeLabel(key string, nd *DataNode) string {
+	switch nd.Kind {
+	case DataList:
+		return fmt.Sprintf("%v [%d]", key, len(nd.Kids))
+	case DataMap:
+		return fmt.Sprintf("%v {%d}", key, len(nd.Kids))
+	case DataNull:
+		return key + ": null"
+	}
+	v := nd.Val
+	if utf8.RuneCountInString(v) > DataLabelMax {
+		v = string([]rune(v)[:DataLabelMax]) + "..."
+	}
+	if nd.Kind == DataStr {
+		v = jsonQuote(v)
+	}
+	return key + ": " + v
+}
+
+// ConfigTree configures the outline tree view for the parsed root, keeping
+// the lists and maps that are open
+func (dv *DataView) ConfigTree(root *DataNode) {
+	dt := dv.DataTree()
+	updt := dt.UpdateStart()
+	open := dv.OpenPaths()
+	dr := &dv.DataRoot
+	if dr.This() == nil {
+		dr.InitName(dr, "data")
+	}
+	rupdt := dr.UpdateStart()
+	dr.DeleteChildren(true)
+	if dv.Buf != nil {
+		dr.SetName(filepath.Base(string(dv.Buf.Filename)))
+	}
+	if root != nil {
+		n := 0
+		dv.addKids(dr, root, &n)
+	}
+	dr.UpdateEnd(rupdt)
+	if dv.TreeView == nil {
+		dt.SetProp("height", units.NewEm(5)) // enables scrolling
+		dt.SetStretchMaxWidth()
+		dt.SetStretchMaxHeight()
+		tv := dt.AddNewChild(giv.KiT_TreeView, "data").(*giv.TreeView)
+		tv.SetRootNode(dr)
+		dv.TreeView = tv
+		tv.TreeViewSig.Connect(dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if data == nil || sig != int64(giv.TreeViewSelected) {
+				return
+			}
+			tvn, _ := data.(ki.Ki).Embed(giv.KiT_TreeView).(*giv.TreeView)
+			dvv, _ := recv.Embed(KiT_DataView).(*DataView)
+			if dn, ok := tvn.SrcNode.Embed(KiT_DataTreeNode).(*DataTreeNode); ok && dn != &dvv.DataRoot {
+				dvv.SelectLine(dn.Ln)
+			}
+		})
+	}
+	dv.TreeView.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		tvn, ok := k.Embed(giv.KiT_TreeView).(*giv.TreeView)
+		if !ok || tvn.SrcNode == nil {
+			return true
+		}
+		dn, _ := tvn.SrcNode.Embed(KiT_DataTreeNode).(*DataTreeNode)
+		switch {
+		case dn == nil || dn == &dv.DataRoot:
+			tvn.SetOpen()
+		case open == nil:
+			tvn.SetClosedState(level >= DataOutlineOpenDepth)
+		default:
+			tvn.SetClosedState(!open[dn.KeyPath])
+		}
+		return true
+	})
+	dv.TreeView.SetFullReRender()
+	dt.UpdateEnd(updt)
+}
+
+// addKids adds the outline nodes of the entries of the list or map, up to
+// DataOutlineMax in all
+func (dv *DataView) addKids(dn *DataTreeNode, nd *DataNode, n *int) {
+	for i, k := range nd.Kids {
+		if *n >= DataOutlineMax {
+			return
+		}
+		*n++
+		key := k.Key
+		if nd.Kind == DataList {
+			key = fmt.Sprintf("[%d]", i)
+		}
+		nm := strings.Replace(dataTreeLabel(key, k), "/", "-", -1) // no path seps
+		kid := dn.AddNewChild(KiT_DataTreeNode, nm).Embed(KiT_DataTreeNode).(*DataTreeNode)
+		kid.Ln = k.Ln
+		kid.KeyPath = dn.KeyPath + "\n" + key
+		if !k.IsScalar() {
+			dv.addKids(kid, k, n)
+		}
+	}
+}
+
+// OpenPaths returns the paths of the open nodes of the outline, or nil if
+// it is not yet shown
+func (dv *DataView) OpenPaths() map[string]bool {
+	if dv.TreeView == nil {
+		return nil
+	}
+	open := make(map[string]bool)
+	dv.TreeView.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		tvn, ok := k.Embed(giv.KiT_TreeView).(*giv.TreeView)
+		if !ok || tvn.SrcNode == nil {
+			return true
+		}
+		if dn, ok := tvn.SrcNode.Embed(KiT_DataTreeNode).(*DataTreeNode); ok && !tvn.IsClosed() {
+			open[dn.KeyPath] = true
+		}
+		return true
+	})
+	return open
+}
+
+// SelectLine moves the cursor to the start of given line of the file --
+// in the active view if it shows the file, keeping the focus in the
+// outline, otherwise opening the file
+func (dv *DataView) SelectLine(ln int) {
+	if dv.Buf == nil || ln >= dv.Buf.NumLines() {
+		return
+	}
+	ge := dv.Gide
+	reg := giv.NewTextRegion(ln, 0, ln, len(dv.Buf.Line(ln)))
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf != dv.Buf {
+		ge.OpenFileAtRegion(gi.FileName(dv.Buf.Filename), reg)
+		return
+	}
+	tv.SetCursorShow(reg.Start)
+}
+
+// DataViewProps are style properties for DataView
+var DataViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+
+////////////////////////////////////////////////////////////////////////////
+//  check as edited
+
+// DataCheckWaitMSec is the number of milliseconds after the last key press
+// that a JSON, YAML or TOML file is checked again
+var DataCheckWaitMSec = 500
+
+// DataCheckMaxLines is the maximum number of lines of a data file that is
+// checked as it is edited
+var DataCheckMaxLines = 100000
+
+// DataCheckState is the state of the checking of a data file as it is
+// edited
+type DataCheckState struct {
+	Timer *time.Timer `desc:"timer for checking after a pause in typing"`
+	Sum   uint64      `desc:"hash of the text last checked, for skipping unchanged text"`
+	Probs string      `desc:"problems found by the last check, for only reporting changes"`
+}
+
+// UpdateDataCheck checks the JSON, YAML or TOML file of the view again
+// after a pause in typing
+func (tv *TextView) UpdateDataCheck() {
+	if !tv.DataCheck || tv.Buf == nil || DataFormat(string(tv.Buf.Filename)) == "" {
+		return
+	}
+	if tv.DataChk.Timer != nil {
+		tv.DataChk.Timer.Stop()
+	}
+	tv.DataChk.Timer = time.AfterFunc(time.Duration(DataCheckWaitMSec)*time.Millisecond, tv.CheckData)
+}
+
+// CheckData parses the JSON, YAML or TOML file of the view, if its text has
+// changed since the last check, and checks it against its JSON Schema, if
+// any (see ValidateData), reporting the problems found to the parent gide
+// -- see Gide.DataChecked
+func (tv *TextView) CheckData() {
+	tb := tv.Buf
+	if tb == nil || tb.NumLines() > DataCheckMaxLines {
+		return
+	}
+	format := DataFormat(string(tb.Filename))
+	if format == "" {
+		return
+	}
+	txt := tb.LinesToBytesCopy()
+	h := fnv.New64a()
+	h.Write(txt)
+	if sum := h.Sum64(); sum != tv.DataChk.Sum {
+		tv.DataChk.Sum = sum
+	} else {
+		return
+	}
+	root, probs := ParseData(format, txt)
+	probs = append(probs, ValidateData(string(tb.Filename), root, txt, true)...)
+	ps := make([]string, len(probs))
+	for i, pb := range probs {
+		ps[i] = pb.Error()
+	}
+	pstr := strings.Join(ps, "\n")
+	changed := pstr != tv.DataChk.Probs
+	tv.DataChk.Probs = pstr
+	if ge, ok := ParentGide(tv.This()); ok {
+		ge.DataChecked(tv, root, probs, changed)
+	}
+}
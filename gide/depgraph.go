@@ -0,0 +1,291 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/goki/pi/filecat"
+)
+
+// DepNode is a node of a dependency graph, e.g., a package
+type DepNode struct {
+	Name    string `desc:"name of the node, e.g., the import path of the package"`
+	Dir     string `desc:"directory of the node, opened in the file tree when it is clicked"`
+	Deps    []int  `desc:"indexes of the nodes it depends on"`
+	Layer   int    `desc:"layer of the node in the graph view: one more than the highest layer of its dependencies outside of its cycle, if any"`
+	Row     int    `desc:"row of the node within its layer"`
+	InCycle bool   `desc:"the node is in a dependency cycle"`
+	Scc     int    `desc:"index of the strongly-connected component of the node -- the nodes of a cycle share one"`
+}
+
+// DepGraph is a dependency graph, e.g., of the package imports of a
+// project, made by a DepGraphProvider
+type DepGraph struct {
+	Nodes  []*DepNode     `desc:"nodes of the graph"`
+	Index  map[string]int `desc:"index of the nodes by name"`
+	Cycles [][]int        `desc:"dependency cycles: the nodes of each strongly-connected component of more than one node, or with a dependency on itself"`
+	Layers int            `desc:"number of layers, after LayoutNodes"`
+}
+
+// NewDepGraph returns a new empty dependency graph
+func NewDepGraph() *DepGraph {
+	return &DepGraph{Index: map[string]int{}}
+}
+
+// Add adds a node of given name and directory, if not already added,
+// returning its index
+func (dg *DepGraph) Add(name, dir string) int {
+	if idx, ok := dg.Index[name]; ok {
+		if dir != "" {
+			dg.Nodes[idx].Dir = dir
+		}
+		return idx
+	}
+	idx := len(dg.Nodes)
+	dg.Nodes = append(dg.Nodes, &DepNode{Name: name, Dir: dir})
+	dg.Index[name] = idx
+	return idx
+}
+
+// AddDep adds a dependency from the node of given name on the other,
+// adding the nodes as needed
+func (dg *DepGraph) AddDep(from, to string) {
+	fi := dg.Add(from, "")
+	ti := dg.Add(to, "")
+	for _, d := range dg.Nodes[fi].Deps {
+		if d == ti {
+			return
+		}
+	}
+	dg.Nodes[fi].Deps = append(dg.Nodes[fi].Deps, ti)
+}
+
+// IsCycleDep returns true if the dependency between the nodes of given
+// indexes is part of a cycle
+func (dg *DepGraph) IsCycleDep(from, to int) bool {
+	fn, tn := dg.Nodes[from], dg.Nodes[to]
+	return fn.InCycle && tn.InCycle && fn.Scc == tn.Scc
+}
+
+// FindCycles finds the dependency cycles, setting Cycles and the InCycle
+// and Scc of the nodes (Tarjan's strongly-connected components algorithm)
+func (dg *DepGraph) FindCycles() {
+	n := len(dg.Nodes)
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	var stack []int
+	next, nscc := 0, 0
+	dg.Cycles = nil
+	var connect func(v int)
+	connect = func(v int) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		self := false
+		for _, w := range dg.Nodes[v].Deps {
+			switch {
+			case w == v:
+				self = true
+			case index[w] < 0:
+				connect(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+			case onStack[w] && index[w] < low[v]:
+				low[v] = index[w]
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var scc []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			dg.Nodes[w].Scc = nscc
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		nscc++
+		if len(scc) > 1 || self {
+			for _, w := range scc {
+				dg.Nodes[w].InCycle = true
+			}
+			sort.Ints(scc)
+			dg.Cycles = append(dg.Cycles, scc)
+		}
+	}
+	for v := 0; v < n; v++ {
+		if index[v] < 0 {
+			connect(v)
+		}
+	}
+}
+
+// LayoutNodes finds the cycles, and sets the Layer and Row of the nodes:
+// nodes with no dependencies are in layer 0, and the nodes of a cycle share
+// a layer -- rows are in order of name within each layer
+func (dg *DepGraph) LayoutNodes() {
+	dg.FindCycles()
+	sccLayer := map[int]int{}
+	var layer func(v int) int
+	layer = func(v int) int {
+		nd := dg.Nodes[v]
+		if ly, ok := sccLayer[nd.Scc]; ok {
+			return ly
+		}
+		sccLayer[nd.Scc] = 0 // in progress: guards against cycles
+		ly := 0
+		for _, u := range dg.Nodes {
+			if u.Scc != nd.Scc {
+				continue
+			}
+			for _, w := range u.Deps {
+				if dg.Nodes[w].Scc == nd.Scc {
+					continue
+				}
+				if wl := layer(w) + 1; wl > ly {
+					ly = wl
+				}
+			}
+		}
+		sccLayer[nd.Scc] = ly
+		return ly
+	}
+	dg.Layers = 0
+	for v, nd := range dg.Nodes {
+		nd.Layer = layer(v)
+		if nd.Layer+1 > dg.Layers {
+			dg.Layers = nd.Layer + 1
+		}
+	}
+	idxs := make([]int, len(dg.Nodes))
+	for i := range idxs {
+		idxs[i] = i
+	}
+	sort.Slice(idxs, func(i, j int) bool {
+		return dg.Nodes[idxs[i]].Name < dg.Nodes[idxs[j]].Name
+	})
+	rows := make([]int, dg.Layers)
+	for _, v := range idxs {
+		nd := dg.Nodes[v]
+		nd.Row = rows[nd.Layer]
+		rows[nd.Layer]++
+	}
+}
+
+// Labels returns the labels of the nodes: their names without the
+// directories common to all of them, e.g., github.com/goki/ for the
+// packages of the github.com/goki/gide module
+func (dg *DepGraph) Labels() []string {
+	pfx := ""
+	for i, nd := range dg.Nodes {
+		if i == 0 {
+			pfx = nd.Name
+			continue
+		}
+		for !strings.HasPrefix(nd.Name, pfx) {
+			pfx = pfx[:len(pfx)-1]
+		}
+	}
+	if len(dg.Nodes) == 1 {
+		pfx = ""
+	}
+	if si := strings.LastIndex(pfx, "/"); si >= 0 {
+		pfx = pfx[:si+1]
+	} else {
+		pfx = ""
+	}
+	lbls := make([]string, len(dg.Nodes))
+	for i, nd := range dg.Nodes {
+		lbls[i] = strings.TrimPrefix(nd.Name, pfx)
+		if lbls[i] == "" {
+			lbls[i] = nd.Name
+		}
+	}
+	return lbls
+}
+
+// DepGraphProvider returns the dependency graph of the project at given
+// root, for a language -- registered with RegisterDepGraphProvider, e.g.,
+// by a plugin
+type DepGraphProvider func(root string) (*DepGraph, error)
+
+// DepGraphProviders are the registered dependency graph providers for each
+// language
+var DepGraphProviders = map[filecat.Supported]DepGraphProvider{
+	filecat.Go: GoDepGraph,
+}
+
+// RegisterDepGraphProvider registers the dependency graph provider for
+// projects of given language, replacing any registered before
+func RegisterDepGraphProvider(sup filecat.Supported, dp DepGraphProvider) {
+	DepGraphProviders[sup] = dp
+}
+
+// goListPkg is the part of the go list -json output used by GoDepGraph
+type goListPkg struct {
+	ImportPath string
+	Dir        string
+	Imports    []string
+	Module     *struct {
+		Path string
+		Main bool
+	}
+}
+
+// GoDepGraph returns the graph of the imports among the packages of the Go
+// module at root, from go list -e -deps -json -- imports of packages outside
+// the module are not included
+func GoDepGraph(root string) (*DepGraph, error) {
+	cmd := exec.Command("go", "list", "-e", "-deps", "-json", "./...")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("go list: %v", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, fmt.Errorf("go list: %v", err)
+	}
+	var pkgs []goListPkg
+	dec := json.NewDecoder(strings.NewReader(string(out)))
+	for {
+		var pkg goListPkg
+		if err := dec.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("go list output: %v", err)
+		}
+		if pkg.Module != nil && pkg.Module.Main {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	dg := NewDepGraph()
+	for _, pkg := range pkgs {
+		dg.Add(pkg.ImportPath, pkg.Dir)
+	}
+	for _, pkg := range pkgs {
+		for _, imp := range pkg.Imports {
+			if _, ok := dg.Index[imp]; ok {
+				dg.AddDep(pkg.ImportPath, imp)
+			}
+		}
+	}
+	return dg, nil
+}
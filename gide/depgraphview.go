@@ -0,0 +1,314 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/filecat"
+)
+
+// DepGraphCycleColor is the color of the nodes and dependencies of cycles
+// in the dependency graph
+var DepGraphCycleColor = gi.Color{0xce, 0x42, 0x52, 0xff}
+
+// DepGraphView shows the dependency graph of a project, e.g., the imports
+// among the packages of a Go module -- refreshed on demand
+type DepGraphView struct {
+	gi.Layout
+	Gide  Gide              `json:"-" xml:"-" desc:"parent gide project"`
+	Lang  filecat.Supported `desc:"language of the provider of the graph"`
+	Graph *DepGraph         `json:"-" xml:"-" desc:"graph shown"`
+}
+
+var KiT_DepGraphView = kit.Types.AddType(&DepGraphView{}, DepGraphViewProps)
+
+// Config configures the view to show given graph, from the provider for
+// given language
+func (dv *DepGraphView) Config(ge Gide, lang filecat.Supported, dg *DepGraph) {
+	dv.Gide = ge
+	dv.Lang = lang
+	dv.Graph = dg
+	dv.Lay = gi.LayoutVert
+	dv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "toolbar")
+	config.Add(gi.KiT_Layout, "scroll")
+	mods, updt := dv.ConfigChildren(config, false)
+	if !mods {
+		updt = dv.UpdateStart()
+	}
+	dv.ConfigToolbar()
+	sc := dv.Scroll()
+	sc.Lay = gi.LayoutVert
+	sc.SetStretchMaxWidth()
+	sc.SetStretchMaxHeight()
+	if !sc.HasChildren() {
+		sc.AddNewChild(KiT_DepGraphCanvas, "graph")
+	}
+	info := fmt.Sprintf("%v: %v nodes", lang, len(dg.Nodes))
+	if nc := len(dg.Cycles); nc > 0 {
+		info += fmt.Sprintf(", %v cycles", nc)
+	}
+	dv.InfoLabel().SetText(info)
+	dv.Canvas().SetGraph(dg)
+	dv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (dv *DepGraphView) ToolBar() *gi.ToolBar {
+	return dv.ChildByName("toolbar", 0).(*gi.ToolBar)
+}
+
+// Scroll returns the scrolling layout of the graph
+func (dv *DepGraphView) Scroll() *gi.Layout {
+	return dv.ChildByName("scroll", 1).(*gi.Layout)
+}
+
+// InfoLabel returns the label with the number of nodes and cycles in the
+// toolbar
+func (dv *DepGraphView) InfoLabel() *gi.Label {
+	return dv.ToolBar().ChildByName("info", 1).(*gi.Label)
+}
+
+// Canvas returns the graph
+func (dv *DepGraphView) Canvas() *DepGraphCanvas {
+	return dv.Scroll().Child(0).Embed(KiT_DepGraphCanvas).(*DepGraphCanvas)
+}
+
+// ConfigToolbar adds the refresh action and info label to the toolbar
+func (dv *DepGraphView) ConfigToolbar() {
+	tb := dv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "get the dependency graph of the project again"},
+		dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dvv, _ := recv.Embed(KiT_DepGraphView).(*DepGraphView)
+			dvv.Gide.DepGraph()
+		})
+	gi.AddNewLabel(tb, "info", "")
+}
+
+// DepGraphViewProps are style properties for DepGraphView
+var DepGraphViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+
+// DepGraphCanvas draws a dependency graph in layers from left to right, each
+// node to the right of the nodes it depends on, with the cycles in
+// DepGraphCycleColor -- click on a node to select its directory in the file
+// tree
+type DepGraphCanvas struct {
+	gi.WidgetBase
+	Graph  *DepGraph         `json:"-" xml:"-" desc:"graph shown"`
+	Labels []string          `json:"-" xml:"-" view:"-" desc:"labels of the nodes"`
+	Boxes  []image.Rectangle `json:"-" xml:"-" view:"-" desc:"boxes of the nodes, relative to the canvas, as last laid out"`
+	Texts  []gi.TextRender   `json:"-" xml:"-" view:"-" desc:"rendered labels of the nodes"`
+}
+
+var KiT_DepGraphCanvas = kit.Types.AddType(&DepGraphCanvas{}, DepGraphCanvasProps)
+
+// DepGraphCanvasProps are style properties for DepGraphCanvas
+var DepGraphCanvasProps = ki.Props{
+	"padding":          units.NewValue(4, units.Px),
+	"border-color":     &gi.Prefs.Colors.Border,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+}
+
+// SetGraph sets the graph shown, laying out its nodes, and renders it
+func (dc *DepGraphCanvas) SetGraph(dg *DepGraph) {
+	dg.LayoutNodes()
+	dc.Graph = dg
+	dc.Labels = dg.Labels()
+	dc.SetFullReRender()
+	dc.UpdateSig()
+}
+
+// LayoutBoxes sets the boxes of the nodes, from the sizes of their labels,
+// returning the total size
+func (dc *DepGraphCanvas) LayoutBoxes() image.Point {
+	dg := dc.Graph
+	if dg == nil || len(dg.Nodes) == 0 {
+		dc.Boxes = nil
+		return image.ZP
+	}
+	sty := &dc.Sty
+	pad := int(sty.Layout.Padding.Dots)
+	gap := int(4 * sty.Font.Size.Dots)
+	dc.Texts = make([]gi.TextRender, len(dg.Nodes))
+	dc.Boxes = make([]image.Rectangle, len(dg.Nodes))
+	wds := make([]int, dg.Layers)
+	ht := 0
+	for i, nd := range dg.Nodes {
+		dc.Texts[i].SetString(dc.Labels[i], &sty.Font, &sty.UnContext, &sty.Text, true, 0, 0)
+		sz := dc.Texts[i].Size
+		if w := int(sz.X) + 2*pad; w > wds[nd.Layer] {
+			wds[nd.Layer] = w
+		}
+		if h := int(sz.Y) + 2*pad; h > ht {
+			ht = h
+		}
+	}
+	xs := make([]int, dg.Layers)
+	x := pad
+	for ly, w := range wds {
+		xs[ly] = x
+		x += w + gap
+	}
+	var tot image.Point
+	for i, nd := range dg.Nodes {
+		y := pad + nd.Row*(ht+pad)
+		dc.Boxes[i] = image.Rect(xs[nd.Layer], y, xs[nd.Layer]+wds[nd.Layer], y+ht)
+		if dc.Boxes[i].Max.X+gap > tot.X {
+			tot.X = dc.Boxes[i].Max.X + gap // room for the cycle edges on the right
+		}
+		if dc.Boxes[i].Max.Y+pad > tot.Y {
+			tot.Y = dc.Boxes[i].Max.Y + pad
+		}
+	}
+	return tot
+}
+
+// NodeAt returns the index of the node at given point, relative to the
+// canvas, or -1 if none
+func (dc *DepGraphCanvas) NodeAt(pt image.Point) int {
+	for i, bx := range dc.Boxes {
+		if pt.In(bx) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Size2D sets the size needed for the graph
+func (dc *DepGraphCanvas) Size2D(iter int) {
+	dc.InitLayout2D()
+	tot := dc.LayoutBoxes()
+	dc.Size2DFromWH(float32(tot.X), float32(tot.Y))
+}
+
+// Render2D renders the dependencies as curves from each node to the nodes
+// it depends on, and the nodes as boxes with their labels
+func (dc *DepGraphCanvas) Render2D() {
+	if dc.FullReRenderIfNeeded() {
+		return
+	}
+	if dc.PushBounds() {
+		dc.This().(gi.Node2D).ConnectEvents2D()
+		sty := &dc.Sty
+		draw.Draw(dc.Viewport.Pixels, dc.VpBBox, image.NewUniform(&sty.Font.BgColor.Color), image.ZP, draw.Src)
+		if dc.Graph != nil && len(dc.Boxes) == len(dc.Graph.Nodes) {
+			dc.RenderGraph()
+		}
+		dc.PopBounds()
+	} else {
+		dc.DisconnectAllEvents(gi.RegPri)
+	}
+}
+
+// RenderGraph renders the dependencies and the nodes
+func (dc *DepGraphCanvas) RenderGraph() {
+	dg := dc.Graph
+	sty := &dc.Sty
+	org := dc.LayData.AllocPos // scrolled: may be above and left of VpBBox
+	rs := &dc.Viewport.Render
+	rs.Lock()
+	pc := &rs.Paint
+	pc.FillStyle.SetColor(nil)
+	pc.StrokeStyle.Width = units.Value{Val: 1, Un: units.Dot, Dots: 1}
+	off := 2 * sty.Font.Size.Dots
+	for i, nd := range dg.Nodes {
+		fb := dc.Boxes[i]
+		for _, d := range nd.Deps {
+			db := dc.Boxes[d]
+			fy := org.Y + float32(fb.Min.Y+fb.Max.Y)/2
+			dy := org.Y + float32(db.Min.Y+db.Max.Y)/2
+			if dg.IsCycleDep(i, d) {
+				pc.StrokeStyle.SetColor(DepGraphCycleColor)
+			} else {
+				pc.StrokeStyle.SetColor(&sty.Border.Color)
+			}
+			if nd.Layer == dg.Nodes[d].Layer { // within a cycle: loop out on the right
+				fx := org.X + float32(fb.Max.X)
+				dx := org.X + float32(db.Max.X)
+				pc.MoveTo(rs, fx, fy)
+				pc.CubicTo(rs, fx+off, fy, dx+off, dy, dx, dy)
+			} else {
+				fx := org.X + float32(fb.Min.X)
+				dx := org.X + float32(db.Max.X)
+				mx := (fx + dx) / 2
+				pc.MoveTo(rs, fx, fy)
+				pc.CubicTo(rs, mx, fy, mx, dy, dx, dy)
+			}
+			pc.Stroke(rs)
+		}
+	}
+	rad := 0.25 * sty.Font.Size.Dots
+	for i, nd := range dg.Nodes {
+		bx := dc.Boxes[i]
+		pos := org.Add(gi.NewVec2DFmPoint(bx.Min))
+		sz := gi.NewVec2DFmPoint(bx.Size())
+		if nd.InCycle {
+			pc.StrokeStyle.SetColor(DepGraphCycleColor)
+		} else {
+			pc.StrokeStyle.SetColor(&sty.Border.Color)
+		}
+		pc.FillStyle.SetColor(&gi.Prefs.Colors.Control)
+		pc.DrawRoundedRectangle(rs, pos.X, pos.Y, sz.X, sz.Y, rad)
+		pc.FillStrokeClear(rs)
+	}
+	pc.FillStyle.SetColor(nil)
+	rs.Unlock()
+	pad := sty.Layout.Padding.Dots
+	for i := range dg.Nodes {
+		pos := org.Add(gi.NewVec2DFmPoint(dc.Boxes[i].Min))
+		dc.Texts[i].RenderTopPos(rs, pos.AddVal(pad))
+	}
+}
+
+// ConnectEvents2D connects the mouse click to select the directory of the
+// node clicked on in the file tree
+func (dc *DepGraphCanvas) ConnectEvents2D() {
+	dc.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		dcc := recv.Embed(KiT_DepGraphCanvas).(*DepGraphCanvas)
+		if me.Button != mouse.Left || me.Action != mouse.Press {
+			return
+		}
+		me.SetProcessed()
+		ni := dcc.NodeAt(dcc.PointToRelPos(me.Where).Add(dcc.VpBBox.Min).Sub(dcc.LayData.AllocPos.ToPoint()))
+		if ni < 0 || dcc.Graph == nil {
+			return
+		}
+		nd := dcc.Graph.Nodes[ni]
+		ge, ok := ParentGide(dcc.This())
+		if !ok {
+			return
+		}
+		msg := fmt.Sprintf("%v: %v dependencies", nd.Name, len(nd.Deps))
+		if nd.InCycle {
+			msg += " -- in a dependency cycle"
+		}
+		if nd.Dir != "" {
+			ge.SelectFileInTree(gi.FileName(nd.Dir))
+		}
+		ge.SetStatus(msg)
+	})
+}
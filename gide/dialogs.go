@@ -0,0 +1,177 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"sync"
+	"time"
+
+	"github.com/goki/ki/kit"
+)
+
+// DialogModes are the ways a non-critical prompt, e.g., that an auto-save
+// file exists, is shown, so that it does not steal the focus and swallow
+// keystrokes while typing
+type DialogModes int32
+
+const (
+	// DialogModal shows a modal dialog right away
+	DialogModal DialogModes = iota
+
+	// DialogDefer shows a modal dialog once typing pauses for
+	// DialogPrefs.TypingPauseMSec
+	DialogDefer
+
+	// DialogBanner shows a non-modal notice in the Notices tab, with links
+	// for the choices, and a message in the status bar
+	DialogBanner
+
+	// DialogModesN is the number of dialog modes
+	DialogModesN
+)
+
+//go:generate stringer -type=DialogModes
+
+var KiT_DialogModes = kit.Enums.AddEnumAltLower(DialogModesN, kit.NotBitFlag, nil, "Dialog")
+
+// MarshalJSON encodes
+func (ev DialogModes) MarshalJSON() ([]byte, error) { return kit.EnumMarshalJSON(ev) }
+
+// UnmarshalJSON decodes
+func (ev *DialogModes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// DialogPrefs are the settings for how the non-critical prompts are shown
+type DialogPrefs struct {
+	AutoSave        DialogModes `desc:"prompt that an auto-save file exists for a file being opened, with unsaved changes from a previous session"`
+	BigFile         DialogModes `desc:"prompt to confirm opening a file larger than BigFileSize"`
+	Binary          DialogModes `desc:"prompt to confirm opening a file that appears to be binary"`
+	TypingPauseMSec int         `min:"0" desc:"milliseconds without key presses after which deferred prompts are shown"`
+}
+
+// Defaults are the defaults for DialogPrefs
+func (dp *DialogPrefs) Defaults() {
+	dp.AutoSave = DialogDefer
+	dp.BigFile = DialogDefer
+	dp.Binary = DialogDefer
+	dp.TypingPauseMSec = 1500
+}
+
+// TypingPause returns the time without key presses after which deferred
+// prompts are shown
+func (dp *DialogPrefs) TypingPause() time.Duration {
+	return time.Duration(dp.TypingPauseMSec) * time.Millisecond
+}
+
+// Notice is a non-modal prompt, shown in the Notices tab, with links for
+// its choices
+type Notice struct {
+	ID      int              `desc:"unique id of the notice in this session"`
+	Time    time.Time        `desc:"time the notice was posted"`
+	Title   string           `desc:"title of the prompt"`
+	Prompt  string           `desc:"text of the prompt"`
+	Choices []string         `desc:"choices, one of which is passed to Fun"`
+	Fun     func(choice int) `json:"-" xml:"-" view:"-" desc:"function called with the index of the choice made"`
+	Done    bool             `desc:"a choice has been made"`
+}
+
+// Notices are the notices of a project, most recent last
+type Notices []*Notice
+
+// Add adds a notice with given prompt and choices, returning it
+func (ns *Notices) Add(title, prompt string, choices []string, fun func(choice int)) *Notice {
+	id := 1
+	if n := len(*ns); n > 0 {
+		id = (*ns)[n-1].ID + 1
+	}
+	nt := &Notice{ID: id, Time: time.Now(), Title: title, Prompt: prompt, Choices: choices, Fun: fun}
+	*ns = append(*ns, nt)
+	return nt
+}
+
+// ByID returns the notice with given id, or nil if not found
+func (ns *Notices) ByID(id int) *Notice {
+	for _, nt := range *ns {
+		if nt.ID == id {
+			return nt
+		}
+	}
+	return nil
+}
+
+// Pending returns the number of notices with no choice made yet
+func (ns *Notices) Pending() int {
+	n := 0
+	for _, nt := range *ns {
+		if !nt.Done {
+			n++
+		}
+	}
+	return n
+}
+
+// Choose makes the choice of given index for the notice, if none has been
+// made yet, returning false otherwise
+func (nt *Notice) Choose(choice int) bool {
+	if nt.Done || choice < 0 || choice >= len(nt.Choices) {
+		return false
+	}
+	nt.Done = true
+	if nt.Fun != nil {
+		nt.Fun(choice)
+	}
+	return true
+}
+
+// DialogDeferrer defers showing prompts while the user is typing, until
+// there have been no key presses for the typing pause
+type DialogDeferrer struct {
+	LastKey time.Time   `desc:"time of the last key press"`
+	Pending []func()    `desc:"functions showing the deferred prompts, in order"`
+	Timer   *time.Timer `desc:"timer for checking for a pause in typing"`
+	Mu      sync.Mutex  `desc:"mutex for the pending prompts"`
+}
+
+// KeyPressed records a key press
+func (dd *DialogDeferrer) KeyPressed() {
+	dd.Mu.Lock()
+	dd.LastKey = time.Now()
+	dd.Mu.Unlock()
+}
+
+// Show calls show, which shows a prompt, right away if there have been no
+// key presses for the pause, and otherwise once typing pauses
+func (dd *DialogDeferrer) Show(pause time.Duration, show func()) {
+	dd.Mu.Lock()
+	idle := time.Since(dd.LastKey)
+	if idle >= pause && len(dd.Pending) == 0 {
+		dd.Mu.Unlock()
+		show()
+		return
+	}
+	dd.Pending = append(dd.Pending, show)
+	if dd.Timer == nil {
+		dd.Timer = time.AfterFunc(pause-idle, func() { dd.check(pause) })
+	}
+	dd.Mu.Unlock()
+}
+
+// check is called by the timer: it shows the pending prompts if typing has
+// paused, and otherwise waits for the remaining time
+func (dd *DialogDeferrer) check(pause time.Duration) {
+	dd.Mu.Lock()
+	idle := time.Since(dd.LastKey)
+	if idle < pause {
+		dd.Timer = time.AfterFunc(pause-idle, func() { dd.check(pause) })
+		dd.Mu.Unlock()
+		return
+	}
+	pend := dd.Pending
+	dd.Pending = nil
+	dd.Timer = nil
+	dd.Mu.Unlock()
+	for _, show := range pend {
+		show()
+	}
+}
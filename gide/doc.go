@@ -10,4 +10,10 @@
 // tap into the gide infrastructure, and be callable directly from the
 // GideView editor, without creating circular import problems.
 //
+// Editors for specific domains can be built on gide without changing
+// GideView, by registering a custom file tree node type (SetFileNodeType),
+// viewers for files of given languages (RegisterFileViewer), main tabs
+// (RegisterMainTab), and completion, lookup and formatting providers
+// (RegisterCompletionProvider) -- see extend.go and providers.go.
+//
 package gide
@@ -0,0 +1,223 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ki"
+	"github.com/goki/pi/filecat"
+)
+
+// DocPopupMaxLines is the maximum number of lines of documentation shown in
+// the hover / popup -- the full docs can be pinned into the Docs tab
+var DocPopupMaxLines = 16
+
+// DocHoverWaitMSec is the number of milliseconds the mouse must rest on a
+// symbol before its documentation is looked up for the hover
+var DocHoverWaitMSec = 500
+
+// PyDocCmd is the python command used to get python docstrings via pydoc
+var PyDocCmd = "python3"
+
+// DocLangSupported returns true if documentation lookup is supported for
+// given language
+func DocLangSupported(sup filecat.Supported) bool {
+	return sup == filecat.Go || sup == filecat.Python
+}
+
+// DocSupported returns true if documentation lookup is supported for the
+// buffer: for its language, or from the JSON Schema of a data file
+func DocSupported(tb *giv.TextBuf) bool {
+	return DocLangSupported(tb.Info.Sup) || SchemaDocSupported(string(tb.Filename))
+}
+
+// DottedWordAt returns the dotted identifier (e.g., os.path.join) at given
+// position in the buffer
+func DottedWordAt(tb *giv.TextBuf, pos giv.TextPos) string {
+	if pos.Ln >= tb.NumLines() {
+		return ""
+	}
+	lt := tb.Line(pos.Ln)
+	isw := func(r rune) bool {
+		return r == '_' || r == '.' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+	}
+	st := pos.Ch
+	if st > len(lt) {
+		st = len(lt)
+	}
+	for st > 0 && isw(lt[st-1]) {
+		st--
+	}
+	ed := pos.Ch
+	for ed < len(lt) && isw(lt[ed]) && lt[ed] != '.' {
+		ed++
+	}
+	if st >= ed {
+		return ""
+	}
+	return strings.Trim(string(lt[st:ed]), ".")
+}
+
+// DocAt returns the documentation for the symbol at given position in the
+// buffer: for Go, the hover description from gopls (given the unsaved text
+// of the buffer, see GoplsCmd), for Python, the pydoc docstring for the
+// dotted name, and for JSON, YAML and TOML files, the docs of the key from
+// their JSON Schema
+func DocAt(tb *giv.TextBuf, pos giv.TextPos) (string, error) {
+	if SchemaDocSupported(string(tb.Filename)) {
+		return SchemaDocAt(tb, pos)
+	}
+	switch tb.Info.Sup {
+	case filecat.Go:
+		if pos.Ln >= tb.NumLines() {
+			return "", fmt.Errorf("gide.DocAt: position out of range")
+		}
+		lt := tb.Line(pos.Ln)
+		ch := pos.Ch
+		if ch > len(lt) {
+			ch = len(lt)
+		}
+		bcol := len(string(lt[:ch])) + 1
+		cmd := GoplsCmd(tb, "definition", "-json", fmt.Sprintf("%v:%v:%v", tb.Filename, pos.Ln+1, bcol))
+		out, err := cmd.Output()
+		if err != nil {
+			return "", err
+		}
+		def := struct {
+			Description string `json:"description"`
+		}{}
+		if err := json.Unmarshal(out, &def); err != nil {
+			return "", err
+		}
+		return def.Description, nil
+	case filecat.Python:
+		return DocForName(tb.Info.Sup, "", DottedWordAt(tb, pos))
+	}
+	return "", fmt.Errorf("gide.DocAt: documentation not supported for language: %v", tb.Info.Sup)
+}
+
+// DocForName returns the documentation for given (qualified) name, for use
+// in following cross-references: for Go, go doc run in given dir, and for
+// Python, pydoc
+func DocForName(sup filecat.Supported, dir, name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("gide.DocForName: no name")
+	}
+	var cmd *exec.Cmd
+	switch sup {
+	case filecat.Go:
+		cmd = exec.Command("go", "doc", name)
+	case filecat.Python:
+		cmd = exec.Command(PyDocCmd, "-m", "pydoc", name)
+	default:
+		return "", fmt.Errorf("gide.DocForName: documentation not supported for language: %v", sup)
+	}
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%v", strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// docRefRe matches qualified names (pkg.Name) to be made into doc:///
+// cross-reference links
+var docRefRe = regexp.MustCompile(`\b([a-z][a-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)+)\b`)
+
+// docURLRe matches http(s) urls in docs
+var docURLRe = regexp.MustCompile(`https?://[^\s)\]>"]+`)
+
+// DocMarkup returns the documentation text as markup for the Docs tab, with
+// cross-references to qualified names as doc:/// links, and urls as links
+func DocMarkup(doc string) string {
+	lns := strings.Split(html.EscapeString(doc), "\n")
+	for i, ln := range lns {
+		if docURLRe.MatchString(ln) {
+			lns[i] = docURLRe.ReplaceAllString(ln, `<a href="$0">$0</a>`)
+			continue
+		}
+		lns[i] = docRefRe.ReplaceAllString(ln, `<a href="doc:///$1">$1</a>`)
+	}
+	return strings.Join(lns, "\n")
+}
+
+// DocPopupText returns the documentation text trimmed to DocPopupMaxLines,
+// as markup for a popup
+func DocPopupText(doc string) string {
+	lns := strings.Split(strings.TrimSpace(doc), "\n")
+	if len(lns) > DocPopupMaxLines {
+		lns = append(lns[:DocPopupMaxLines], "...")
+	}
+	return strings.Replace(html.EscapeString(strings.Join(lns, "\n")), "\n", "<br>", -1)
+}
+
+// DocDir returns the directory to use for looking up docs for the buffer
+func DocDir(tb *giv.TextBuf) string {
+	dir, _ := filepath.Split(string(tb.Filename))
+	return dir
+}
+
+// ShowDocAt shows a popup with documentation for the symbol at given
+// position, at given window location -- runs the lookup in the background
+func (tv *TextView) ShowDocAt(pos giv.TextPos, x, y int) {
+	if tv.Buf == nil || !DocSupported(tv.Buf) {
+		return
+	}
+	vp := tv.Viewport
+	go func() {
+		doc, err := DocAt(tv.Buf, pos)
+		if err != nil || strings.TrimSpace(doc) == "" || vp == nil || vp.Win == nil {
+			return
+		}
+		gi.PopupTooltip(DocPopupText(doc), x, y, vp, "doc-hover")
+		vp.Win.OSWin.SendEmptyEvent() // needs an extra event to show popup
+	}()
+}
+
+// ShowDocs shows a popup with documentation for the symbol at the cursor
+func (tv *TextView) ShowDocs() {
+	cpos := tv.CharStartPos(tv.CursorPos).ToPoint()
+	cpos.Y += int(tv.LineHeight) + 2
+	tv.ShowDocAt(tv.CursorPos, cpos.X, cpos.Y)
+}
+
+// DocHoverEvent connects to hover events to show documentation for the
+// symbol under the mouse, if enabled in the project editor prefs -- the
+// lookup waits DocHoverWaitMSec, and is restarted by each hover in the
+// meantime
+func (tv *TextView) DocHoverEvent() {
+	tv.ConnectEvent(oswin.MouseHoverEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		tvv := recv.Embed(KiT_TextView).(*TextView)
+		if tvv.Buf == nil || tvv.IsInactive() || !DocSupported(tvv.Buf) {
+			return
+		}
+		ge, ok := ParentGide(tvv.This())
+		if !ok || !ge.ProjPrefs().Editor.DocHover {
+			return
+		}
+		me := d.(*mouse.HoverEvent)
+		me.SetProcessed()
+		pos := tvv.PixelToCursor(tvv.PointToRelPos(me.Where))
+		if tvv.DocTimer != nil {
+			tvv.DocTimer.Stop()
+		}
+		x, y := me.Where.X, me.Where.Y+int(tvv.LineHeight)
+		tvv.DocTimer = time.AfterFunc(time.Duration(DocHoverWaitMSec)*time.Millisecond, func() {
+			tvv.ShowDocAt(pos, x, y)
+		})
+	})
+}
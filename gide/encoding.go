@@ -0,0 +1,300 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+)
+
+// TextEncodings are the supported text encodings, for Reopen / Save with
+// Encoding
+var TextEncodings = []string{"UTF-8", "UTF-16LE", "UTF-16BE", "ISO-8859-1", "Windows-1252"}
+
+// EncodingSniffSize is the number of bytes at the start of a file used to
+// detect its encoding, and whether it is binary
+var EncodingSniffSize = 8192
+
+// FileEnc is the text encoding and line ending style of a file -- files
+// are converted to UTF-8 with LF line endings for editing, and back to
+// their FileEnc when saved
+type FileEnc struct {
+	Encoding string `desc:"text encoding: one of TextEncodings"`
+	BOM      bool   `desc:"file starts with a byte order mark"`
+	CRLF     bool   `desc:"lines end with CR LF (windows style), otherwise LF"`
+}
+
+// FileEncMap holds the encodings of files, by file name
+type FileEncMap map[gi.FileName]FileEnc
+
+// IsDefault returns true if the encoding is UTF-8 with LF line endings, as
+// used for editing
+func (fe FileEnc) IsDefault() bool {
+	return fe.Encoding == "UTF-8" && !fe.BOM && !fe.CRLF
+}
+
+// String returns the encoding and line endings, e.g., UTF-16LE BOM CRLF
+func (fe FileEnc) String() string {
+	s := fe.Encoding
+	if fe.BOM {
+		s += " BOM"
+	}
+	if fe.CRLF {
+		return s + " CRLF"
+	}
+	return s + " LF"
+}
+
+// Windows1252 maps the bytes 0x80..0x9F of Windows-1252 to unicode -- the
+// undefined ones map to the same code point, as in ISO-8859-1
+var Windows1252 = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// DetectFileEnc detects the encoding and line endings of the file contents:
+// by byte order mark if any, else UTF-16 by the pattern of zero bytes, else
+// UTF-8 if valid, else Windows-1252 or ISO-8859-1 -- also returns true if
+// the contents appear to be binary, in which case the encoding is
+// ISO-8859-1, so all bytes are preserved as is
+func DetectFileEnc(b []byte) (FileEnc, bool) {
+	fe := FileEnc{Encoding: "UTF-8"}
+	sn := b
+	if len(sn) > EncodingSniffSize {
+		sn = sn[:EncodingSniffSize]
+	}
+	switch {
+	case bytes.HasPrefix(b, utf8BOM):
+		fe.BOM = true
+	case bytes.HasPrefix(b, []byte{0xFF, 0xFE}):
+		fe.Encoding, fe.BOM = "UTF-16LE", true
+	case bytes.HasPrefix(b, []byte{0xFE, 0xFF}):
+		fe.Encoding, fe.BOM = "UTF-16BE", true
+	default:
+		if enc := sniffUTF16(sn); enc != "" {
+			fe.Encoding = enc
+		} else if IsBinaryData(sn) {
+			return FileEnc{Encoding: "ISO-8859-1"}, true
+		} else if !utf8.Valid(b) {
+			fe.Encoding = "ISO-8859-1"
+			for _, c := range b {
+				if c >= 0x80 && c <= 0x9F {
+					fe.Encoding = "Windows-1252"
+					break
+				}
+			}
+		}
+	}
+	if fe.Encoding == "UTF-16LE" || fe.Encoding == "UTF-16BE" {
+		if txt, err := DecodeText(sn[:len(sn)&^1], FileEnc{Encoding: fe.Encoding}); err == nil {
+			fe.CRLF = IsCRLF(txt)
+		}
+	} else {
+		fe.CRLF = IsCRLF(sn)
+	}
+	return fe, false
+}
+
+// UTF16SniffMin is the minimum number of chars of text without a byte order
+// mark that is taken to be UTF-16 by its zero high bytes
+var UTF16SniffMin = 8
+
+// sniffUTF16 returns UTF-16LE or BE if the text looks like UTF-16 without
+// a byte order mark: mostly ascii, with the high bytes zero -- it needs at
+// least UTF16SniffMin chars, as a few bytes with a zero are more likely binary
+func sniffUTF16(b []byte) string {
+	n := len(b) / 2
+	if n < UTF16SniffMin {
+		return ""
+	}
+	ez, oz := 0, 0
+	for i := 0; i < 2*n; i += 2 {
+		if b[i] == 0 {
+			ez++
+		}
+		if b[i+1] == 0 {
+			oz++
+		}
+	}
+	switch {
+	case oz > n*2/5 && ez <= n/20:
+		return "UTF-16LE"
+	case ez > n*2/5 && oz <= n/20:
+		return "UTF-16BE"
+	}
+	return ""
+}
+
+// IsBinaryData returns true if the data appears to be binary rather than
+// text: it has zero bytes, or more than 10% control characters
+func IsBinaryData(b []byte) bool {
+	nc := 0
+	for _, c := range b {
+		switch {
+		case c == 0:
+			return true
+		case c < 0x20 && c != '\t' && c != '\n' && c != '\r' && c != '\f' && c != '\v' && c != 0x1b:
+			nc++
+		}
+	}
+	return nc > len(b)/10
+}
+
+// IsBinaryFile returns true if the start of the file appears to be binary
+// -- see IsBinaryData
+func IsBinaryFile(fpath string) bool {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	b := make([]byte, EncodingSniffSize)
+	n, _ := io.ReadFull(f, b)
+	_, bin := DetectFileEnc(b[:n])
+	return bin
+}
+
+// IsCRLF returns true if most lines in the text end with CR LF
+func IsCRLF(b []byte) bool {
+	crlf := bytes.Count(b, []byte("\r\n"))
+	return crlf > 0 && crlf >= bytes.Count(b, []byte("\n"))-crlf
+}
+
+// DecodeText converts the text in given encoding to UTF-8, with LF line
+// endings if CRLF -- a byte order mark is removed
+func DecodeText(b []byte, fe FileEnc) ([]byte, error) {
+	var txt []byte
+	switch fe.Encoding {
+	case "UTF-8":
+		txt = bytes.TrimPrefix(b, utf8BOM)
+	case "UTF-16LE", "UTF-16BE":
+		var bo binary.ByteOrder = binary.LittleEndian
+		bom := []byte{0xFF, 0xFE}
+		if fe.Encoding == "UTF-16BE" {
+			bo = binary.BigEndian
+			bom = []byte{0xFE, 0xFF}
+		}
+		b = bytes.TrimPrefix(b, bom)
+		u16 := make([]uint16, len(b)/2)
+		for i := range u16 {
+			u16[i] = bo.Uint16(b[2*i:])
+		}
+		txt = []byte(string(utf16.Decode(u16)))
+	case "ISO-8859-1", "Windows-1252":
+		rs := make([]rune, len(b))
+		for i, c := range b {
+			rs[i] = rune(c)
+			if fe.Encoding == "Windows-1252" && c >= 0x80 && c <= 0x9F {
+				rs[i] = Windows1252[c-0x80]
+			}
+		}
+		txt = []byte(string(rs))
+	default:
+		return nil, fmt.Errorf("text encoding not supported: %v", fe.Encoding)
+	}
+	if fe.CRLF {
+		txt = bytes.Replace(txt, []byte("\r\n"), []byte("\n"), -1)
+	}
+	return txt, nil
+}
+
+// EncodeText converts the UTF-8 text to given encoding, with CR LF line
+// endings if CRLF, and a byte order mark if BOM -- characters that cannot be
+// represented in the encoding are replaced by ?, and their number returned
+func EncodeText(txt []byte, fe FileEnc) ([]byte, int, error) {
+	if fe.CRLF {
+		txt = bytes.Replace(txt, []byte("\r\n"), []byte("\n"), -1)
+		txt = bytes.Replace(txt, []byte("\n"), []byte("\r\n"), -1)
+	}
+	nbad := 0
+	var b []byte
+	switch fe.Encoding {
+	case "UTF-8":
+		if fe.BOM {
+			b = append(b, utf8BOM...)
+		}
+		b = append(b, txt...)
+	case "UTF-16LE", "UTF-16BE":
+		var bo binary.ByteOrder = binary.LittleEndian
+		if fe.Encoding == "UTF-16BE" {
+			bo = binary.BigEndian
+		}
+		u16 := utf16.Encode([]rune(string(txt)))
+		if fe.BOM {
+			u16 = append([]uint16{0xFEFF}, u16...)
+		}
+		b = make([]byte, 2*len(u16))
+		for i, c := range u16 {
+			bo.PutUint16(b[2*i:], c)
+		}
+	case "ISO-8859-1", "Windows-1252":
+		var w1252 map[rune]byte
+		if fe.Encoding == "Windows-1252" {
+			w1252 = make(map[rune]byte, len(Windows1252))
+			for i, r := range Windows1252 {
+				w1252[r] = byte(0x80 + i)
+			}
+		}
+		b = make([]byte, 0, len(txt))
+		for _, r := range string(txt) {
+			if c, ok := w1252[r]; ok {
+				b = append(b, c)
+				continue
+			}
+			if r > 0xFF || (w1252 != nil && r >= 0x80 && r <= 0x9F) {
+				b = append(b, '?')
+				nbad++
+				continue
+			}
+			b = append(b, byte(r))
+		}
+	default:
+		return nil, 0, fmt.Errorf("text encoding not supported: %v", fe.Encoding)
+	}
+	return b, nbad, nil
+}
+
+// WriteFileAtomic writes the data to the file via a temporary file in the
+// same directory that is renamed over it, so the file is never left partly
+// written -- the permissions of an existing file are kept, and otherwise
+// perm is used
+func WriteFileAtomic(fpath string, b []byte, perm os.FileMode) error {
+	if fi, err := os.Stat(fpath); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	dir, fn := filepath.Split(fpath)
+	tf, err := ioutil.TempFile(dir, "."+fn+".tmp")
+	if err != nil {
+		return err
+	}
+	tfn := tf.Name()
+	_, err = tf.Write(b)
+	if cerr := tf.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tfn, perm)
+	}
+	if err == nil {
+		err = os.Rename(tfn, fpath)
+	}
+	if err != nil {
+		os.Remove(tfn)
+	}
+	return err
+}
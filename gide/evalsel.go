@@ -0,0 +1,145 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+)
+
+// EvalTimeout is the maximum time that EvalSnippet lets a snippet run,
+// before killing its process
+var EvalTimeout = 10 * time.Second
+
+// EvalSnippetSrc returns the source of a main package that evaluates the Go
+// snippet: the value of an expression is printed in Go syntax, and any
+// other code is run as the body of main (see SnippetMain)
+func EvalSnippetSrc(snip string) string {
+	s := strings.TrimSpace(snip)
+	ex, err := parser.ParseExpr(s)
+	if err != nil {
+		return SnippetMain(snip)
+	}
+	if ce, ok := ex.(*ast.CallExpr); ok {
+		if id, ok := ce.Fun.(*ast.Ident); ok && (id.Name == "print" || id.Name == "println" || id.Name == "panic") {
+			return SnippetMain(snip)
+		}
+	}
+	return "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Printf(\"%#v\\n\", " + s + ")\n}\n"
+}
+
+// EvalImports returns the source with imports added for the standard
+// library packages that it uses without importing them, e.g., strings in
+// strings.ToUpper(s) -- returned as is if it does not parse
+func EvalImports(src string) string {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", src, 0)
+	if err != nil {
+		return src
+	}
+	have := map[string]bool{}
+	for _, is := range f.Imports {
+		nm := path.Base(strings.Trim(is.Path.Value, `"`))
+		if is.Name != nil {
+			nm = is.Name.Name
+		}
+		have[nm] = true
+	}
+	need := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if se, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := se.X.(*ast.Ident); ok && id.Obj == nil && !have[id.Name] {
+				need[id.Name] = true
+			}
+		}
+		return true
+	})
+	var ips []string
+	for key := range stdlib.Symbols {
+		// keys are the import path, or the path and the package name
+		ip := key
+		if i := strings.LastIndex(ip, "/"); i > 0 && path.Base(ip[:i]) == ip[i+1:] {
+			ip = ip[:i]
+		}
+		if need[path.Base(ip)] && !strings.Contains(ip, "internal") {
+			ips = append(ips, ip)
+		}
+	}
+	sort.Slice(ips, func(i, j int) bool { // shortest first, e.g., math/rand before crypto/rand
+		if len(ips[i]) != len(ips[j]) {
+			return len(ips[i]) < len(ips[j])
+		}
+		return ips[i] < ips[j]
+	})
+	var imps []string
+	for _, ip := range ips {
+		if nm := path.Base(ip); need[nm] {
+			need[nm] = false
+			imps = append(imps, fmt.Sprintf("import %q\n", ip))
+		}
+	}
+	if len(imps) == 0 {
+		return src
+	}
+	off := fset.Position(f.Name.End()).Offset
+	return src[:off] + "\n\n" + strings.Join(imps, "") + src[off:]
+}
+
+// EvalArg is the arg that runs gide as an evaluator of the Go snippet on
+// its standard input (see EvalRun), in the process started by EvalSnippet
+const EvalArg = "-eval"
+
+// EvalExe is the executable that EvalSnippet runs with EvalArg -- the
+// running gide if empty
+var EvalExe = ""
+
+// EvalRun evaluates the Go snippet (see EvalSnippetSrc) with the yaegi Go
+// interpreter, which resolves imports of the standard library (added as
+// needed, see EvalImports) and of the packages in GOPATH, writing its
+// output to the writers -- run by gide -eval in the process started by
+// EvalSnippet, as a snippet can run forever or exit the process
+func EvalRun(snip string, stdout, stderr io.Writer) error {
+	in := interp.New(interp.Options{GoPath: build.Default.GOPATH, Stdout: stdout, Stderr: stderr})
+	in.Use(stdlib.Symbols)
+	_, err := in.Eval(EvalImports(EvalSnippetSrc(snip)))
+	return err
+}
+
+// EvalSnippet evaluates the Go snippet in a separate gide -eval process
+// (see EvalRun), which is killed if it runs for more than EvalTimeout --
+// returns the combined output, and an error if the snippet does not
+// compile, panics, exits with an error or times out
+func EvalSnippet(snip string) ([]byte, error) {
+	exe := EvalExe
+	if exe == "" {
+		var err error
+		if exe, err = os.Executable(); err != nil {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), EvalTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, exe, EvalArg)
+	cmd.Stdin = strings.NewReader(snip)
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("evaluation stopped after %v", EvalTimeout)
+	}
+	return out, err
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/syms"
+	"github.com/goki/pi/token"
+)
+
+// regionHas returns true if region a contains region b
+func regionHas(a, b giv.TextRegion) bool {
+	return !b.Start.IsLess(a.Start) && !a.End.IsLess(b.End)
+}
+
+// regionSame returns true if the regions have the same start and end
+func regionSame(a, b giv.TextRegion) bool {
+	return a.Start == b.Start && a.End == b.End
+}
+
+// regionSize returns the number of chars in the region of the lines,
+// counting line endings
+func regionSize(lines [][]rune, reg giv.TextRegion) int {
+	if reg.Start.Ln == reg.End.Ln {
+		return reg.End.Ch - reg.Start.Ch
+	}
+	n := len(lines[reg.Start.Ln]) - reg.Start.Ch + 1
+	for ln := reg.Start.Ln + 1; ln < reg.End.Ln && ln < len(lines); ln++ {
+		n += len(lines[ln]) + 1
+	}
+	return n + reg.End.Ch
+}
+
+// lineIndent returns the indentation of the line in columns, with tabs
+// expanded, or -1 if the line is blank
+func lineIndent(lt []rune, tabsz int) int {
+	col := 0
+	for _, r := range lt {
+		switch r {
+		case ' ':
+			col++
+		case '\t':
+			col += tabsz - col%tabsz
+		default:
+			return col
+		}
+	}
+	return -1
+}
+
+// linesRegion returns the region of the whole lines st to ed (inclusive),
+// including the line ending of the last line if there is one
+func linesRegion(lines [][]rune, st, ed int) giv.TextRegion {
+	if ed+1 < len(lines) {
+		return giv.NewTextRegion(st, 0, ed+1, 0)
+	}
+	return giv.NewTextRegion(st, 0, ed, len(lines[ed]))
+}
+
+// isWordRune returns true if the rune is part of a word for selection
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// ExpandCandidates returns the regions of the syntactic units of the lines
+// that contain the given region: the word, string and line at it, the
+// inside and whole of the enclosing bracket pairs (from ScanBrackets), the
+// blocks of lines by indentation, the given symbol regions (e.g., from the
+// language parser), and the whole text
+func ExpandCandidates(lines [][]rune, reg giv.TextRegion, brs []Bracket, syn *BracketSyntax, symRegs []giv.TextRegion, tabsz int) []giv.TextRegion {
+	var cands []giv.TextRegion
+	add := func(r giv.TextRegion) {
+		if regionHas(r, reg) {
+			cands = append(cands, r)
+		}
+	}
+	nl := len(lines)
+	if nl == 0 {
+		return nil
+	}
+	if reg.Start.Ln == reg.End.Ln && reg.Start.Ln < nl {
+		ln := reg.Start.Ln
+		lt := lines[ln]
+		st, ed := reg.Start.Ch, reg.End.Ch
+		for st > 0 && isWordRune(lt[st-1]) {
+			st--
+		}
+		for ed < len(lt) && isWordRune(lt[ed]) {
+			ed++
+		}
+		add(giv.NewTextRegion(ln, st, ln, ed))
+		for ch := 0; ch < len(lt); ch++ {
+			if !strings.ContainsRune(syn.Quotes, lt[ch]) {
+				continue
+			}
+			qe := quoteEnd(lt, ch)
+			if qe < 0 {
+				continue
+			}
+			add(giv.NewTextRegion(ln, ch+1, ln, qe))
+			add(giv.NewTextRegion(ln, ch, ln, qe+1))
+			ch = qe
+		}
+		st, ed = 0, len(lt)
+		for st < ed && unicode.IsSpace(lt[st]) {
+			st++
+		}
+		for ed > st && unicode.IsSpace(lt[ed-1]) {
+			ed--
+		}
+		add(giv.NewTextRegion(ln, st, ln, ed))
+	}
+	for _, br := range brs {
+		if !br.IsOpen() || br.Match < 0 {
+			continue
+		}
+		op, cl := br.Pos, brs[br.Match].Pos
+		add(giv.NewTextRegionPos(giv.TextPos{Ln: op.Ln, Ch: op.Ch + 1}, cl))
+		add(giv.NewTextRegionPos(op, giv.TextPos{Ln: cl.Ln, Ch: cl.Ch + 1}))
+		if cl.Ln > op.Ln+1 {
+			add(giv.NewTextRegion(op.Ln+1, 0, cl.Ln, 0))
+		}
+		add(linesRegion(lines, op.Ln, cl.Ln))
+	}
+	for _, sr := range symRegs {
+		add(sr)
+		add(linesRegion(lines, sr.Start.Ln, sr.End.Ln))
+	}
+	// indentation blocks, and their header lines, out to the top level
+	ln0, ln1 := reg.Start.Ln, reg.End.Ln
+	if reg.End.Ch == 0 && ln1 > ln0 {
+		ln1--
+	}
+	lev := -1
+	for ln := ln0; ln <= ln1 && ln < nl; ln++ {
+		if id := lineIndent(lines[ln], tabsz); id >= 0 && (lev < 0 || id < lev) {
+			lev = id
+		}
+	}
+	inBlock := func(ln int) bool {
+		id := lineIndent(lines[ln], tabsz)
+		return id < 0 || id >= lev
+	}
+	for lev >= 0 && ln1 < nl {
+		st, ed := ln0, ln1
+		for st > 0 && inBlock(st-1) {
+			st--
+		}
+		for ed < nl-1 && inBlock(ed+1) {
+			ed++
+		}
+		for st < ln0 && lineIndent(lines[st], tabsz) < 0 {
+			st++
+		}
+		for ed > ln1 && lineIndent(lines[ed], tabsz) < 0 {
+			ed--
+		}
+		add(linesRegion(lines, st, ed))
+		if st == 0 || lev == 0 {
+			break
+		}
+		hd := st - 1 // header line, with less indent
+		add(linesRegion(lines, hd, ed))
+		ln0, ln1, lev = hd, ed, lineIndent(lines[hd], tabsz)
+	}
+	add(giv.NewTextRegion(0, 0, nl-1, len(lines[nl-1])))
+	return cands
+}
+
+// ExpandRegion returns the smallest of the candidate regions that is larger
+// than the given region, and true, or false if there is none
+func ExpandRegion(lines [][]rune, reg giv.TextRegion, cands []giv.TextRegion) (giv.TextRegion, bool) {
+	var best giv.TextRegion
+	bsz := -1
+	rsz := regionSize(lines, reg)
+	for _, c := range cands {
+		if regionSame(c, reg) || !regionHas(c, reg) {
+			continue
+		}
+		sz := regionSize(lines, c)
+		if sz <= rsz {
+			continue
+		}
+		if bsz < 0 || sz < bsz {
+			best, bsz = c, sz
+		}
+	}
+	return best, bsz >= 0
+}
+
+// SymbolRegions returns the regions of the symbols (functions, types, etc)
+// in the buffer of this view, from the language parser, if available
+func (tv *TextView) SymbolRegions() []giv.TextRegion {
+	fn := string(tv.Buf.Filename)
+	var regs []giv.TextRegion
+	var addSyms func(sm syms.SymMap)
+	addSyms = func(sm syms.SymMap) {
+		for _, sy := range sm {
+			rg := sy.Region
+			if sy.Filename == fn && (rg.St.Ln < rg.Ed.Ln || (rg.St.Ln == rg.Ed.Ln && rg.St.Ch < rg.Ed.Ch)) {
+				regs = append(regs, giv.NewTextRegion(rg.St.Ln, rg.St.Ch, rg.Ed.Ln, rg.Ed.Ch))
+			}
+			addSyms(sy.Children)
+		}
+	}
+	addSyms(tv.Buf.PiState.Syms)
+	return regs
+}
+
+// FuncRegion returns the region of the innermost function or method at
+// the cursor, from the language parser, and false if there is none
+func (tv *TextView) FuncRegion() (giv.TextRegion, bool) {
+	fn := string(tv.Buf.Filename)
+	cur := giv.NewTextRegionPos(tv.CursorPos, tv.CursorPos)
+	var best giv.TextRegion
+	got := false
+	var findSyms func(sm syms.SymMap)
+	findSyms = func(sm syms.SymMap) {
+		for _, sy := range sm {
+			if sy.Filename == fn && (sy.Kind == token.NameFunction || sy.Kind == token.NameMethod) {
+				rg := sy.Region
+				reg := giv.NewTextRegion(rg.St.Ln, rg.St.Ch, rg.Ed.Ln, rg.Ed.Ch)
+				if regionHas(reg, cur) && (!got || regionHas(best, reg)) {
+					best = reg
+					got = true
+				}
+			}
+			findSyms(sy.Children)
+		}
+	}
+	findSyms(tv.Buf.PiState.Syms)
+	return best, got
+}
+
+// CurSelRegion returns the selected region, or the empty region at the
+// cursor if there is no selection
+func (tv *TextView) CurSelRegion() giv.TextRegion {
+	if tv.HasSelection() {
+		return tv.SelectReg
+	}
+	return giv.NewTextRegionPos(tv.CursorPos, tv.CursorPos)
+}
+
+// SelectRegionShow selects the region, with the cursor at its end, or just
+// moves the cursor if it is empty
+func (tv *TextView) SelectRegionShow(reg giv.TextRegion) {
+	updt := tv.Viewport.Win.UpdateStart()
+	defer tv.Viewport.Win.UpdateEnd(updt)
+	tv.SetCursorShow(reg.End)
+	if reg.IsNil() {
+		tv.SelectReset()
+	} else {
+		tv.SelectReg = reg
+		tv.SelectStart = reg.Start
+	}
+	tv.RenderAllLines()
+}
+
+// ExpandSel grows the selection to the next larger syntactic unit: word,
+// string, expression in brackets, line, block, function -- using the
+// language parser where available, and indentation elsewhere -- ShrinkSel
+// goes back through the previous selections
+func (tv *TextView) ExpandSel() {
+	if tv.Buf == nil || tv.Buf.NumLines() == 0 {
+		return
+	}
+	reg := tv.CurSelRegion()
+	if !regionSame(reg, tv.ExpandReg) {
+		tv.ExpandStack = nil
+	}
+	tv.ScanBufBrackets()
+	syn := BufBracketSyntax(tv.Buf)
+	tabsz := tv.Sty.Text.TabSize
+	if tabsz <= 0 {
+		tabsz = 4
+	}
+	symRegs := tv.SymbolRegions()
+	tv.Buf.LinesMu.RLock()
+	cands := ExpandCandidates(tv.Buf.Lines, reg, tv.Brackets, &syn, symRegs, tabsz)
+	nreg, ok := ExpandRegion(tv.Buf.Lines, reg, cands)
+	tv.Buf.LinesMu.RUnlock()
+	if !ok {
+		return
+	}
+	tv.ExpandStack = append(tv.ExpandStack, reg)
+	tv.ExpandReg = nreg
+	tv.SelectRegionShow(nreg)
+}
+
+// ShrinkSel shrinks the selection back to what it was before the last
+// ExpandSel -- does nothing if the selection was changed since then
+func (tv *TextView) ShrinkSel() {
+	n := len(tv.ExpandStack)
+	if tv.Buf == nil || n == 0 || !regionSame(tv.CurSelRegion(), tv.ExpandReg) {
+		return
+	}
+	preg := tv.ExpandStack[n-1]
+	tv.ExpandStack = tv.ExpandStack[:n-1]
+	tv.ExpandReg = preg
+	tv.SelectRegionShow(preg)
+}
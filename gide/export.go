@@ -0,0 +1,262 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/pi/lex"
+	"github.com/goki/pi/token"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// PrintHiStyle is the highlighting style used for PDF export and printing
+// when the current style is dark, as paper is white
+var PrintHiStyle = histyle.StyleName("emacs")
+
+// PDFFontSize is the size of the text of exported PDFs, in points
+var PDFFontSize = 8.0
+
+// ExportDoc is the part of a buffer to export to HTML or PDF, with its
+// highlighting, as returned by NewExportDoc
+type ExportDoc struct {
+	Title  string        `desc:"title in the header of each page: the file name"`
+	Date   time.Time     `desc:"date in the header of each page: when exported"`
+	StLn   int           `desc:"line number of the first line, starting at 0"`
+	Lines  [][]byte      `desc:"the lines to export"`
+	Tags   []lex.Line    `desc:"highlighting tags of each line"`
+	Markup [][]byte      `desc:"HTML markup of each line, with the highlighting as span classes"`
+	Style  histyle.Style `desc:"highlighting style"`
+	TabSz  int           `desc:"tab size, in chars"`
+	LineNo bool          `desc:"show line numbers"`
+}
+
+// ExportLines returns the lines of the view to export: those of the
+// selection, if any, else all of them -- ed is exclusive
+func ExportLines(tv *giv.TextView) (st, ed int) {
+	nln := tv.Buf.NumLines()
+	if !tv.HasSelection() {
+		return 0, nln
+	}
+	reg := tv.SelectReg
+	st, ed = reg.Start.Ln, reg.End.Ln+1
+	if reg.End.Ch == 0 && reg.End.Ln > reg.Start.Ln {
+		ed--
+	}
+	if ed > nln {
+		ed = nln
+	}
+	return st, ed
+}
+
+// NewExportDoc returns the lines from st to ed (exclusive) of the buffer to
+// export, with the highlighting of the buffer, titled with given file name
+func NewExportDoc(tb *giv.TextBuf, st, ed int, title string, lineNos bool) *ExportDoc {
+	xd := &ExportDoc{Title: title, Date: time.Now(), StLn: st, Style: tb.Hi.HiStyle, TabSz: tb.Opts.TabSize, LineNo: lineNos}
+	if xd.TabSz <= 0 {
+		xd.TabSz = 4
+	}
+	tb.LinesMu.RLock()
+	tb.MarkupMu.Lock()
+	for ln := st; ln < ed && ln < len(tb.LineBytes); ln++ {
+		xd.Lines = append(xd.Lines, append([]byte(nil), tb.LineBytes[ln]...))
+		var tags lex.Line
+		if ln < len(tb.HiTags) {
+			tags = append(tags, tb.HiTags[ln]...)
+		}
+		xd.Tags = append(xd.Tags, tags)
+		mu := giv.HTMLEscapeBytes(tb.LineBytes[ln])
+		if ln < len(tb.Markup) {
+			mu = append([]byte(nil), tb.Markup[ln]...)
+		}
+		xd.Markup = append(xd.Markup, mu)
+	}
+	tb.MarkupMu.Unlock()
+	tb.LinesMu.RUnlock()
+	return xd
+}
+
+// header returns the text of the header: the title and the date
+func (xd *ExportDoc) header() (string, string) {
+	return xd.Title, xd.Date.Format("2006-01-02 15:04")
+}
+
+// lineNoWidth returns the number of digits of the largest line number
+func (xd *ExportDoc) lineNoWidth() int {
+	return len(fmt.Sprintf("%d", xd.StLn+len(xd.Lines)))
+}
+
+// HTML returns the lines as a standalone HTML page, with the highlighting as
+// CSS classes of the style, and the background and text colors of the gi
+// prefs, under a header with the title and date
+func (xd *ExportDoc) HTML() []byte {
+	var b bytes.Buffer
+	ttl, dt := xd.header()
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%v</title>\n<style>\n", html.EscapeString(ttl))
+	fmt.Fprintf(&b, "body { background-color: %v; color: %v; }\n", gi.Prefs.Colors.Background.HexString(), gi.Prefs.Colors.Font.HexString())
+	fmt.Fprintf(&b, ".header { font-family: sans-serif; display: flex; justify-content: space-between; border-bottom: 1px solid; margin-bottom: 1em; }\n")
+	fmt.Fprintf(&b, "pre { font-family: \"Go Mono\", monospace; tab-size: %v; -moz-tab-size: %v; }\n", xd.TabSz, xd.TabSz)
+	fmt.Fprintf(&b, ".ln { opacity: 0.5; user-select: none; }\n")
+	css := xd.Style.ToCSS()
+	toks := make([]token.Tokens, 0, len(css))
+	for tk := range css {
+		toks = append(toks, tk)
+	}
+	sort.Slice(toks, func(i, j int) bool { return toks[i] < toks[j] })
+	for _, tk := range toks {
+		if css[tk] != "" {
+			fmt.Fprintf(&b, ".%v { %v; }\n", tk.StyleName(), css[tk])
+		}
+	}
+	fmt.Fprintf(&b, "</style>\n</head>\n<body>\n<div class=\"header\"><span>%v</span><span>%v</span></div>\n<pre>", html.EscapeString(ttl), dt)
+	lnw := xd.lineNoWidth()
+	for i, mu := range xd.Markup {
+		if xd.LineNo {
+			fmt.Fprintf(&b, "<span class=\"ln\">%*d  </span>", lnw, xd.StLn+i+1)
+		}
+		b.Write(mu)
+		b.WriteByte('\n')
+	}
+	b.WriteString("</pre>\n</body>\n</html>\n")
+	return b.Bytes()
+}
+
+// HiSpan is a span of the text of a line with the same highlighting
+type HiSpan struct {
+	Text string       `desc:"text of the span, with tabs expanded"`
+	Tok  token.Tokens `desc:"highlighting token of the span -- the innermost tag"`
+}
+
+// HiSpans returns the spans of the line with the same highlighting, given
+// its tags -- tabs are expanded to spaces, to the given tab size
+func HiSpans(txt []byte, tags lex.Line, tabSz int) []HiSpan {
+	var sps []HiSpan
+	var sb strings.Builder
+	cur := token.Tokens(-1)
+	col := 0
+	for i := 0; i < len(txt); {
+		r, sz := utf8.DecodeRune(txt[i:])
+		tok := token.None
+		for _, tg := range tags {
+			if tg.St <= i && i < tg.Ed {
+				tok = tg.Tok.Tok
+			}
+		}
+		if tok != cur && sb.Len() > 0 {
+			sps = append(sps, HiSpan{Text: sb.String(), Tok: cur})
+			sb.Reset()
+		}
+		cur = tok
+		if r == '\t' {
+			n := tabSz - col%tabSz
+			sb.WriteString(strings.Repeat(" ", n))
+			col += n
+		} else {
+			sb.WriteRune(r)
+			col++
+		}
+		i += sz
+	}
+	if sb.Len() > 0 {
+		sps = append(sps, HiSpan{Text: sb.String(), Tok: cur})
+	}
+	return sps
+}
+
+// PDF renders the lines as a paginated A4 PDF, in Courier, with the
+// highlighting of the style -- or of PrintHiStyle if the text of the gi
+// prefs is light, i.e., the style is dark -- under a header with the title
+// and date on each page, and the page number in the footer -- long lines
+// are wrapped
+func (xd *ExportDoc) PDF(fname string) error {
+	hs := xd.Style
+	if _, _, l, _ := gi.Prefs.Colors.Font.ToHSLA(); l > 0.5 {
+		hs = histyle.AvailStyle(PrintHiStyle)
+	}
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	ttl, dt := xd.header()
+	lh := PDFFontSize * 0.3528 * 1.25 // points to mm, with line spacing
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(0, 0, 0)
+		lm, _, _, _ := pdf.GetMargins()
+		pdf.CellFormat(0, 6, tr(ttl), "", 0, "L", false, 0, "")
+		pdf.SetX(lm)
+		pdf.CellFormat(0, 6, dt, "B", 1, "R", false, 0, "")
+		pdf.Ln(2)
+	})
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(0x80, 0x80, 0x80)
+		pdf.CellFormat(0, 6, fmt.Sprintf("%d / {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+	pdf.SetFont("Courier", "", PDFFontSize)
+	pgw, _ := pdf.GetPageSize()
+	lm, _, rm, _ := pdf.GetMargins()
+	chw := pdf.GetStringWidth("m")
+	lnw := 0
+	if xd.LineNo {
+		lnw = xd.lineNoWidth() + 2
+	}
+	cols := int((pgw-lm-rm)/chw) - lnw
+	if cols < 10 {
+		cols = 10
+	}
+	for i, txt := range xd.Lines {
+		pdf.SetFont("Courier", "", PDFFontSize)
+		if xd.LineNo {
+			pdf.SetTextColor(0x90, 0x90, 0x90)
+			pdf.CellFormat(float64(lnw)*chw, lh, fmt.Sprintf("%*d", lnw-2, xd.StLn+i+1), "", 0, "L", false, 0, "")
+		}
+		col := 0
+		for _, sp := range HiSpans(txt, xd.Tags[i], xd.TabSz) {
+			se := hs.Tag(sp.Tok)
+			sty := ""
+			if se.Bold == histyle.Yes {
+				sty += "B"
+			}
+			if se.Italic == histyle.Yes {
+				sty += "I"
+			}
+			pdf.SetFont("Courier", sty, PDFFontSize)
+			if se.Color.IsNil() {
+				pdf.SetTextColor(0, 0, 0)
+			} else {
+				pdf.SetTextColor(int(se.Color.R), int(se.Color.G), int(se.Color.B))
+			}
+			rs := []rune(sp.Text)
+			for len(rs) > 0 {
+				if col == cols {
+					pdf.Ln(lh)
+					pdf.SetX(lm + float64(lnw)*chw)
+					col = 0
+				}
+				n := cols - col
+				if n > len(rs) {
+					n = len(rs)
+				}
+				pdf.CellFormat(float64(n)*chw, lh, tr(string(rs[:n])), "", 0, "L", false, 0, "")
+				col += n
+				rs = rs[n:]
+			}
+		}
+		pdf.Ln(lh)
+	}
+	return pdf.OutputFileAndClose(fname)
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/filecat"
+)
+
+// Extension points: domain-specific IDEs built on gide register their own
+// file tree node type, viewers for files of given languages or extensions,
+// main tabs, and completion providers (see providers.go), typically in the
+// init function of their package, instead of changing GideView methods such
+// as FileNodeOpened.
+
+// FileNodeType is the type of the nodes of the file tree of new projects
+// -- it must embed gide.FileNode -- set with SetFileNodeType
+var FileNodeType = KiT_FileNode
+
+// SetFileNodeType sets the type of the nodes of the file tree of new
+// projects, e.g., a type embedding gide.FileNode with additional
+// domain-specific actions in its context menu -- returns an error if the
+// type does not embed gide.FileNode
+func SetFileNodeType(typ reflect.Type) error {
+	if !kit.TypeEmbeds(typ, KiT_FileNode) {
+		return fmt.Errorf("gide.SetFileNodeType: type %v does not embed gide.FileNode", typ)
+	}
+	FileNodeType = typ
+	return nil
+}
+
+// FileViewer opens files of given languages or extensions in a custom
+// viewer, instead of the text editor -- registered with RegisterFileViewer
+type FileViewer struct {
+	Name  string                               `desc:"name of the viewer, shown in the status"`
+	Desc  string                               `desc:"brief description"`
+	Langs []filecat.Supported                  `desc:"languages of files opened by this viewer"`
+	Exts  []string                             `desc:"file extensions (with the dot, e.g., .csv) of files opened by this viewer"`
+	Match func(fn *giv.FileNode) bool          `view:"-" json:"-" desc:"optional function that returns true for files opened by this viewer, in addition to Langs and Exts"`
+	Open  func(ge Gide, fn *giv.FileNode) bool `view:"-" json:"-" desc:"opens the file, e.g., in a main tab from RecycleMainTab -- returns false to open the file the standard way instead"`
+}
+
+// FileViewers are the registered file viewers, in order of registration
+var FileViewers []*FileViewer
+
+// RegisterFileViewer registers given file viewer -- viewers registered
+// later take precedence over earlier ones for the same files
+func RegisterFileViewer(fv *FileViewer) {
+	FileViewers = append(FileViewers, fv)
+}
+
+// Matches returns true if this viewer opens the file of given node
+func (fv *FileViewer) Matches(fn *giv.FileNode) bool {
+	for _, sup := range fv.Langs {
+		if fn.Info.Sup == sup {
+			return true
+		}
+	}
+	ext := strings.ToLower(filepath.Ext(string(fn.FPath)))
+	for _, ex := range fv.Exts {
+		if strings.ToLower(ex) == ext {
+			return true
+		}
+	}
+	return fv.Match != nil && fv.Match(fn)
+}
+
+// FileViewerFor returns the most recently registered file viewer that
+// opens the file of given node, or nil if none
+func FileViewerFor(fn *giv.FileNode) *FileViewer {
+	for i := len(FileViewers) - 1; i >= 0; i-- {
+		if fv := FileViewers[i]; fv.Open != nil && fv.Matches(fn) {
+			return fv
+		}
+	}
+	return nil
+}
+
+// OpenFileViewer opens the file of given node in its registered file
+// viewer, returning false if there is none, or it declined to open it
+func OpenFileViewer(ge Gide, fn *giv.FileNode) bool {
+	fv := FileViewerFor(fn)
+	if fv == nil || !fv.Open(ge, fn) {
+		return false
+	}
+	ge.SetStatus(fmt.Sprintf("Opened: %v in: %v", fn.Nm, fv.Name))
+	return true
+}
+
+// MainTabType is a kind of tab that can be opened in the main tabs, from
+// the View / Open Tab menu -- registered with RegisterMainTab
+type MainTabType struct {
+	Label  string                        `desc:"label of the tab, and in the Open Tab menu -- must be unique"`
+	Desc   string                        `desc:"brief description"`
+	Type   reflect.Type                  `view:"-" json:"-" desc:"type of widget in the tab, e.g., gi.KiT_Layout"`
+	Config func(ge Gide, widg gi.Node2D) `view:"-" json:"-" desc:"configures the widget -- called each time the tab is opened, so it should only add children the first time, as in ConfigOutputTextView"`
+}
+
+// MainTabTypes are the registered main tab types, in order of registration
+var MainTabTypes []*MainTabType
+
+// RegisterMainTab registers given main tab type -- one with the same label
+// is replaced
+func RegisterMainTab(mt *MainTabType) {
+	for i, et := range MainTabTypes {
+		if et.Label == mt.Label {
+			MainTabTypes[i] = mt
+			return
+		}
+	}
+	MainTabTypes = append(MainTabTypes, mt)
+}
+
+// MainTabTypeByLabel returns the main tab type with given label, or nil
+func MainTabTypeByLabel(label string) *MainTabType {
+	for _, mt := range MainTabTypes {
+		if mt.Label == label {
+			return mt
+		}
+	}
+	return nil
+}
+
+// MainTabTypeLabels returns the labels of the registered main tab types,
+// for the Open Tab menu
+func MainTabTypeLabels() []string {
+	lbs := make([]string, len(MainTabTypes))
+	for i, mt := range MainTabTypes {
+		lbs[i] = mt.Label
+	}
+	return lbs
+}
+
+// OpenMainTab opens the main tab of given registered type in given gide,
+// or selects it if already open -- returns the widget, or nil if there is
+// no such type
+func OpenMainTab(ge Gide, label string) gi.Node2D {
+	mt := MainTabTypeByLabel(label)
+	if mt == nil {
+		return nil
+	}
+	widg := ge.RecycleMainTab(mt.Label, mt.Type, true)
+	if mt.Config != nil {
+		mt.Config(ge, widg)
+	}
+	return widg
+}
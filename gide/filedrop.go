@@ -0,0 +1,212 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/dnd"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/ki/ki"
+	"github.com/goki/pi/filecat"
+)
+
+// TextURIList is the mime type for lists of file urls, as dropped from OS
+// file managers
+var TextURIList = "text/uri-list"
+
+// ExternalPaths returns the existing file paths in given text, one per line,
+// as absolute paths or file:// urls (as in text/uri-list) -- other lines
+// are ignored
+func ExternalPaths(txt string) []string {
+	var paths []string
+	for _, ln := range strings.Split(txt, "\n") {
+		ln = strings.TrimSpace(ln)
+		if ln == "" || strings.HasPrefix(ln, "#") {
+			continue
+		}
+		if strings.HasPrefix(ln, "file://") {
+			up, err := url.Parse(ln)
+			if err != nil {
+				continue
+			}
+			ln = up.Path
+		}
+		if !filepath.IsAbs(ln) {
+			continue
+		}
+		if _, err := os.Stat(ln); err == nil {
+			paths = append(paths, filepath.Clean(ln))
+		}
+	}
+	return paths
+}
+
+// DroppedFiles returns the files in the dropped mime data: tree nodes for
+// files dragged from within the file tree with given root view node, and
+// paths for files from outside (e.g., the OS file manager)
+func DroppedFiles(md mimedata.Mimes, sroot ki.Ki) (nodes []*giv.FileNode, paths []string) {
+	for _, d := range md {
+		switch d.Type {
+		case filecat.TextPlain:
+			if sroot != nil {
+				if sn, err := sroot.FindPathUniqueTry(string(d.Data)); err == nil {
+					if fn, ok := sn.Embed(giv.KiT_FileNode).(*giv.FileNode); ok {
+						nodes = append(nodes, fn)
+					}
+					continue
+				}
+			}
+			paths = append(paths, ExternalPaths(string(d.Data))...)
+		case TextURIList:
+			paths = append(paths, ExternalPaths(string(d.Data))...)
+		}
+	}
+	return
+}
+
+// DropDir returns the directory for dropping files onto this node: itself
+// if it is a directory, else the directory containing it
+func (ft *FileTreeView) DropDir() string {
+	fn := ft.FileNode()
+	if fn == nil {
+		return ""
+	}
+	if fn.IsDir() {
+		return string(fn.FPath)
+	}
+	return filepath.Dir(string(fn.FPath))
+}
+
+// Drop moves or copies files dropped onto this node, into its directory,
+// after confirming -- files dragged from within the tree are moved (copied
+// with the copy modifier), and files dropped from outside are copied.
+// satisfies gi.DragNDropper interface
+func (ft *FileTreeView) Drop(md mimedata.Mimes, mod dnd.DropMods) {
+	// all the actual file changes are done here, so the source does nothing
+	ft.DragNDropFinalize(dnd.DropIgnore)
+	tdir := ft.DropDir()
+	if tdir == "" {
+		return
+	}
+	var sroot ki.Ki
+	if ft.RootView != nil {
+		sroot = ft.RootView.SrcNode
+	}
+	dnodes, paths := DroppedFiles(md, sroot)
+	var nodes []*giv.FileNode
+	for _, fn := range dnodes {
+		if filepath.Dir(string(fn.FPath)) == tdir {
+			continue // already there
+		}
+		nodes = append(nodes, fn)
+		paths = append(paths, string(fn.FPath))
+	}
+	if len(paths) == 0 {
+		return
+	}
+	move := mod == dnd.DropMove && len(nodes) > 0
+	verb := "Copy"
+	if move {
+		verb = "Move"
+	}
+	nms := make([]string, len(paths))
+	for i, p := range paths {
+		nms[i] = filepath.Base(p)
+	}
+	gi.ChoiceDialog(ft.Viewport, gi.DlgOpts{Title: verb + " Files?",
+		Prompt: fmt.Sprintf("%v %v into folder: %v?", verb, strings.Join(nms, ", "), tdir)},
+		[]string{"Cancel", verb},
+		ft.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != 1 {
+				return
+			}
+			ftv := recv.Embed(KiT_FileTreeView).(*FileTreeView)
+			if move {
+				ftv.MoveFilesToDir(nodes, tdir)
+			} else {
+				ftv.CopyFilesToDir(paths, tdir)
+			}
+		})
+}
+
+// Dragged does nothing -- files are moved by the Drop target
+func (ft *FileTreeView) Dragged(de *dnd.Event) {
+}
+
+// CopyFilesToDir copies the files at given paths into given directory in
+// the tree, prompting before overwriting any existing files
+func (ft *FileTreeView) CopyFilesToDir(paths []string, tdir string) {
+	fn := ft.FileNode()
+	if fn == nil {
+		return
+	}
+	tfn, ok := fn.FRoot.FindFile(tdir)
+	if !ok {
+		return
+	}
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if fi.IsDir() {
+			log.Printf("gide.FileTreeView: copying folders is not supported: %v\n", p)
+			continue
+		}
+		tfn.CopyFileToDir(p, fi.Mode())
+	}
+	fn.FRoot.UpdateNewFile(tdir)
+}
+
+// MoveFilesToDir moves the given file nodes into given directory -- files
+// under version control are moved using the version control system (e.g.,
+// git mv) if the VcsMove file pref is set.  Files that are open in a buffer,
+// or that would overwrite an existing file, are not moved.
+func (ft *FileTreeView) MoveFilesToDir(nodes []*giv.FileNode, tdir string) {
+	fn := ft.FileNode()
+	if fn == nil {
+		return
+	}
+	var errs []string
+	dirs := map[string]bool{tdir: true}
+	for _, sfn := range nodes {
+		spath := string(sfn.FPath)
+		tpath := filepath.Join(tdir, filepath.Base(spath))
+		if sfn.IsOpen() {
+			errs = append(errs, fmt.Sprintf("%v is open -- close it first", sfn.Name()))
+			continue
+		}
+		if _, err := os.Stat(tpath); err == nil {
+			errs = append(errs, fmt.Sprintf("%v already exists", tpath))
+			continue
+		}
+		var err error
+		if Prefs.Files.VcsMove && sfn.FRoot.Repo != nil && sfn.VcsState >= giv.FileNodeVcsAdded {
+			err = sfn.Repo().Move(spath, tpath)
+		} else {
+			err = os.Rename(spath, tpath)
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		dirs[filepath.Dir(spath)] = true
+	}
+	fr := fn.FRoot
+	for d := range dirs {
+		fr.UpdateNewFile(d)
+	}
+	if len(errs) > 0 {
+		gi.PromptDialog(ft.Viewport, gi.DlgOpts{Title: "Some Files Not Moved", Prompt: strings.Join(errs, "<br>\n")}, true, false, nil, nil)
+	}
+}
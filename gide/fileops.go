@@ -0,0 +1,124 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+)
+
+// IsPathUnder returns true if the given path is the same as, or is within,
+// the given directory path
+func IsPathUnder(fpath, dir gi.FileName) bool {
+	return fpath == dir || strings.HasPrefix(string(fpath), string(dir)+string(filepath.Separator))
+}
+
+// TrashDir returns the trash (recycle bin) directory for the user, where
+// files are moved by TrashFile -- empty on windows, which uses the recycle bin
+func TrashDir() string {
+	hdir, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		return ""
+	case "darwin":
+		return filepath.Join(hdir, ".Trash")
+	default: // freedesktop.org trash spec
+		ddir := os.Getenv("XDG_DATA_HOME")
+		if ddir == "" {
+			ddir = filepath.Join(hdir, ".local", "share")
+		}
+		return filepath.Join(ddir, "Trash")
+	}
+}
+
+// uniqueName returns a name in given directory based on given name that does
+// not already exist
+func uniqueName(dir, name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	nm := name
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(filepath.Join(dir, nm)); os.IsNotExist(err) {
+			return nm
+		}
+		nm = fmt.Sprintf("%s %d%s", base, i, ext)
+	}
+}
+
+// TrashFile moves the file or directory at given path to the trash, so it
+// can be recovered with the OS file manager, instead of deleting it
+func TrashFile(fpath string) error {
+	fpath, err := filepath.Abs(fpath)
+	if err != nil {
+		return err
+	}
+	switch runtime.GOOS {
+	case "windows":
+		fun := "DeleteFile"
+		if fi, err := os.Stat(fpath); err == nil && fi.IsDir() {
+			fun = "DeleteDirectory"
+		}
+		ps := fmt.Sprintf("Add-Type -AssemblyName Microsoft.VisualBasic; [Microsoft.VisualBasic.FileIO.FileSystem]::%s('%s', 'OnlyErrorDialogs', 'SendToRecycleBin')", fun, strings.Replace(fpath, "'", "''", -1))
+		out, err := exec.Command("powershell", "-NoProfile", "-Command", ps).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%v: %s", err, out)
+		}
+		return nil
+	case "darwin":
+		tdir := TrashDir()
+		return os.Rename(fpath, filepath.Join(tdir, uniqueName(tdir, filepath.Base(fpath))))
+	default:
+		tdir := TrashDir()
+		fdir := filepath.Join(tdir, "files")
+		idir := filepath.Join(tdir, "info")
+		if err := os.MkdirAll(fdir, 0700); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(idir, 0700); err != nil {
+			return err
+		}
+		nm := uniqueName(fdir, filepath.Base(fpath))
+		for { // info file must also be unique
+			if _, err := os.Stat(filepath.Join(idir, nm+".trashinfo")); os.IsNotExist(err) {
+				break
+			}
+			nm = uniqueName(fdir, "_"+nm)
+		}
+		info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n", (&url.URL{Path: fpath}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+		ifn := filepath.Join(idir, nm+".trashinfo")
+		if err := ioutil.WriteFile(ifn, []byte(info), 0600); err != nil {
+			return err
+		}
+		if err := os.Rename(fpath, filepath.Join(fdir, nm)); err != nil {
+			os.Remove(ifn)
+			return err
+		}
+		return nil
+	}
+}
+
+// RevealFile shows the file in the OS file manager: selected in its folder
+// on mac and windows, and by opening its folder elsewhere
+func RevealFile(fpath string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", "-R", fpath)
+	case "windows":
+		cmd = exec.Command("explorer", "/select,"+fpath)
+	default:
+		cmd = exec.Command("xdg-open", filepath.Dir(fpath))
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"time"
+
+	"github.com/goki/gi/giv"
+)
+
+// FilePosMax is the maximum number of files whose positions are remembered
+// in a project -- the least recently remembered are dropped
+var FilePosMax = 500
+
+// FilePos is the cursor and scroll position of a file, remembered when it
+// is no longer viewed, and restored when it is opened again
+type FilePos struct {
+	Cursor giv.TextPos `desc:"position of the cursor"`
+	Top    int         `desc:"first visible line"`
+	Time   time.Time   `desc:"time the position was remembered"`
+}
+
+// FilePosMap holds the remembered positions of the files of a project,
+// keyed by the file path relative to the project root
+type FilePosMap map[string]*FilePos
+
+// Set remembers the position of the file at given relative path, dropping
+// the least recently remembered beyond FilePosMax
+func (fm *FilePosMap) Set(fpath string, pos FilePos) {
+	if *fm == nil {
+		*fm = make(FilePosMap)
+	}
+	pos.Time = time.Now()
+	(*fm)[fpath] = &pos
+	for len(*fm) > FilePosMax {
+		old := ""
+		for fp, ps := range *fm {
+			if old == "" || ps.Time.Before((*fm)[old].Time) {
+				old = fp
+			}
+		}
+		delete(*fm, old)
+	}
+}
+
+// FilePos returns the current cursor and scroll position of the view
+func (tv *TextView) FilePos() FilePos {
+	fp := FilePos{Cursor: tv.CursorPos}
+	if tv.NLines > 0 && len(tv.Offs) >= tv.NLines {
+		fp.Top = tv.FirstVisibleLine(0)
+	}
+	return fp
+}
+
+// RestoreFilePos scrolls the view so the first visible line is that of the
+// position, and sets the cursor, within the range of the buffer
+func (tv *TextView) RestoreFilePos(fp *FilePos) {
+	if tv.Buf == nil || tv.NLines == 0 {
+		return
+	}
+	tv.SetCursor(fp.Cursor)
+	top := fp.Top
+	if top >= tv.NLines {
+		top = tv.NLines - 1
+	}
+	if top > 0 && len(tv.Offs) >= tv.NLines {
+		tv.ScrollToTop(int(tv.CharStartPos(giv.TextPos{Ln: top}).Y))
+	}
+	tv.RenderCursor(true)
+}
@@ -5,14 +5,18 @@
 package gide
 
 import (
+	"fmt"
 	"image/color"
 	"log"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mimedata"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
@@ -54,6 +58,23 @@ func (fn *FileNode) ViewFile() {
 	}
 }
 
+// RenameFile renames file to new name, updating any open buffers for it, or
+// for files within it if it is a directory
+func (fn *FileNode) RenameFile(newpath string) error {
+	opath := fn.FPath
+	err := fn.FileNode.RenameFile(newpath)
+	if err != nil || fn.FPath == opath {
+		return err
+	}
+	if ge, ok := ParentGide(fn.This()); ok {
+		ge.FileRenamed(opath, fn.FPath)
+	}
+	if fn.IsDir() {
+		fn.UpdateNode()
+	}
+	return nil
+}
+
 // ExecCmdFile pops up a menu to select a command appropriate for the given node,
 // and shows output in MainTab with name of command
 func (fn *FileNode) ExecCmdFile() {
@@ -71,6 +92,49 @@ func (fn *FileNode) ExecCmdNameFile(cmdNm string) {
 	}
 }
 
+// UpdateVcsState updates the version control state of the file node, as
+// reading its directory does
+func UpdateVcsState(fn *giv.FileNode) {
+	repo := fn.Repo()
+	if repo == nil {
+		return
+	}
+	relpth := fn.MyRelPath()
+	switch {
+	case repo.IsAdded(relpth):
+		fn.VcsState = giv.FileNodeVcsAdded
+	case repo.IsModified(relpth):
+		fn.VcsState = giv.FileNodeVcsModified
+	case repo.InRepo(relpth):
+		fn.VcsState = giv.FileNodeInVcs
+	default:
+		fn.VcsState = giv.FileNodeNotInVcs
+	}
+}
+
+// UpdateSavedFile updates the node of the file of the buffer, which was just
+// saved or reverted, in the file tree: its info and version control state,
+// and removes the node of its auto-save file, which is deleted then -- so the
+// directory is not read again, which stalls on big trees -- if the file has
+// no node yet, its directory is read, and false is returned
+func UpdateSavedFile(ft *giv.FileTree, tb *giv.TextBuf) bool {
+	fn, ok := ft.FindFile(string(tb.Filename))
+	if !ok {
+		ft.UpdateNewFile(string(tb.Filename))
+		return false
+	}
+	fn.UpdateNode()
+	UpdateVcsState(fn)
+	fn.UpdateSig()
+	asfn := tb.AutoSaveFilename()
+	if _, err := os.Stat(asfn); os.IsNotExist(err) {
+		if an, ok := ft.FindFile(asfn); ok && an.IsAutoSave() {
+			an.Delete(true)
+		}
+	}
+	return true
+}
+
 // OpenNodes is a list of file nodes that have been opened for editing -- it
 // is maintained in recency order -- most recent on top -- call Add every time
 // a node is opened / visited for editing
@@ -131,6 +195,10 @@ func (on *OpenNodes) Strings() []string {
 	on.DeleteDeleted()
 	sl := make([]string, len(*on))
 	for i, fn := range *on {
+		if IsScratch(fn) {
+			sl[i] = fn.Nm + " - scratch"
+			continue
+		}
 		rp := fn.FRoot.RelPath(fn.FPath)
 		rp = strings.TrimSuffix(rp, fn.Nm)
 		if rp != "" {
@@ -156,11 +224,12 @@ func (on *OpenNodes) ByStringName(name string) *giv.FileNode {
 	return nil
 }
 
-// NChanged returns number of changed open files
+// NChanged returns number of changed open files -- scratch buffers are
+// never saved, so they are not counted
 func (on *OpenNodes) NChanged() int {
 	cnt := 0
 	for _, fn := range *on {
-		if fn.IsChanged() {
+		if fn.IsChanged() && !IsScratch(fn) {
 			cnt++
 		}
 	}
@@ -265,6 +334,15 @@ func (ft *FileTreeView) FileNode() *FileNode {
 	return fn.(*FileNode)
 }
 
+// Style2D styles the view per its file, with a lock icon for age and sops
+// encrypted files
+func (ft *FileTreeView) Style2D() {
+	ft.FileTreeView.Style2D()
+	if fn := ft.FileNode(); fn != nil && !fn.IsDir() && EncryptedKind(string(fn.FPath)) != "" {
+		ft.Icon = "file-lock"
+	}
+}
+
 // ViewFiles calls ViewFile on selected files
 func (ft *FileTreeView) ViewFiles() {
 	sels := ft.SelectedViews()
@@ -278,6 +356,205 @@ func (ft *FileTreeView) ViewFiles() {
 	}
 }
 
+// NewFile makes a new file in the selected directory, with its initial
+// contents from the given template, or if none is given, the template for
+// the file's extension (see AvailTemplates)
+func (ft *FileTreeView) NewFile(filename string, tmpl TemplateName, addToVcs bool) {
+	sels := ft.SelectedViews()
+	if len(sels) == 0 { // shouldn't happen
+		return
+	}
+	ftv := sels[len(sels)-1].Embed(KiT_FileTreeView).(*FileTreeView)
+	fn := ftv.FileNode()
+	if fn == nil {
+		return
+	}
+	ppath := string(fn.FPath)
+	if !fn.IsDir() {
+		ppath = filepath.Dir(ppath)
+	}
+	np := filepath.Join(ppath, filename)
+	err := NewFileFromTemplate(np, tmpl, filepath.Base(string(fn.FRoot.FPath)))
+	if err != nil {
+		gi.PromptDialog(ft.Viewport, gi.DlgOpts{Title: "Couldn't Make File", Prompt: fmt.Sprintf("Could not make new file at: %v, err: %v", np, err)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	fn.FRoot.UpdateNewFile(np)
+	if addToVcs {
+		if nfn, ok := fn.FRoot.FindFile(np); ok {
+			nfn.AddToVcs()
+		}
+	}
+}
+
+// LocalHistory shows the local history of saved versions of the first
+// selected file
+func (ft *FileTreeView) LocalHistory() {
+	sels := ft.SelectedViews()
+	if len(sels) == 0 {
+		return
+	}
+	ftv := sels[len(sels)-1].Embed(KiT_FileTreeView).(*FileTreeView)
+	fn := ftv.FileNode()
+	if fn == nil || fn.IsDir() {
+		return
+	}
+	ge, ok := ParentGide(fn.This())
+	if ok {
+		ge.LocalHistory(fn.This().Embed(giv.KiT_FileNode).(*giv.FileNode))
+	}
+}
+
+// TrashFiles moves the selected files to the trash, after confirming --
+// open buffers for them are closed, and files under version control are
+// removed from it
+func (ft *FileTreeView) TrashFiles() {
+	sels := ft.SelectedViews()
+	gi.ChoiceDialog(ft.Viewport, gi.DlgOpts{Title: "Move Files To Trash?",
+		Prompt: "Ok to move file(s) to the trash?  Any open buffers for them are closed without saving, and they are removed from version control.  If any selections are directories all files and subdirectories will also be moved."},
+		[]string{"Move To Trash", "Cancel"},
+		ft.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != 0 {
+				return
+			}
+			var errs []string
+			for i := len(sels) - 1; i >= 0; i-- {
+				ftv := sels[i].Embed(KiT_FileTreeView).(*FileTreeView)
+				fn := ftv.FileNode()
+				if fn == nil {
+					continue
+				}
+				if ge, ok := ParentGide(fn.This()); ok {
+					ge.CloseFileBufs(fn.FPath)
+				}
+				fpath := string(fn.FPath)
+				if err := TrashFile(fpath); err != nil {
+					errs = append(errs, fmt.Sprintf("%v: %v", fpath, err))
+					continue
+				}
+				if fn.FRoot.Repo != nil && fn.VcsState >= giv.FileNodeInVcs {
+					fn.Repo().Remove(fpath)
+				}
+				fn.Delete(true)
+			}
+			if len(errs) > 0 {
+				gi.PromptDialog(ft.Viewport, gi.DlgOpts{Title: "Some Files Not Moved To Trash", Prompt: strings.Join(errs, "<br>\n")}, true, false, nil, nil)
+			}
+		})
+}
+
+// CopyPaths copies the full paths of the selected files to the clipboard,
+// one per line
+func (ft *FileTreeView) CopyPaths() {
+	ft.copyPaths(false)
+}
+
+// CopyRelPaths copies the paths of the selected files, relative to the
+// project root, to the clipboard, one per line
+func (ft *FileTreeView) CopyRelPaths() {
+	ft.copyPaths(true)
+}
+
+func (ft *FileTreeView) copyPaths(rel bool) {
+	sels := ft.SelectedViews()
+	var paths []string
+	for _, sn := range sels {
+		ftv := sn.Embed(KiT_FileTreeView).(*FileTreeView)
+		fn := ftv.FileNode()
+		if fn == nil {
+			continue
+		}
+		if rel {
+			paths = append(paths, fn.MyRelPath())
+		} else {
+			paths = append(paths, string(fn.FPath))
+		}
+	}
+	if len(paths) == 0 {
+		return
+	}
+	oswin.TheApp.ClipBoard(ft.Viewport.Win.OSWin).Write(mimedata.NewText(strings.Join(paths, "\n")))
+}
+
+// ShowChecksums shows the md5 and sha256 checksums of the selected files,
+// and copies them to the clipboard in the format of md5sum / sha256sum
+func (ft *FileTreeView) ShowChecksums() {
+	sels := ft.SelectedViews()
+	var lns, errs []string
+	for i := len(sels) - 1; i >= 0; i-- {
+		ftv := sels[i].Embed(KiT_FileTreeView).(*FileTreeView)
+		fn := ftv.FileNode()
+		if fn == nil || fn.IsDir() {
+			continue
+		}
+		cs, err := Checksums(string(fn.FPath))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", fn.FPath, err))
+			continue
+		}
+		lns = append(lns, cs...)
+	}
+	if len(lns) > 0 {
+		oswin.TheApp.ClipBoard(ft.Viewport.Win.OSWin).Write(mimedata.NewText(strings.Join(lns, "\n")))
+	}
+	gi.PromptDialog(ft.Viewport, gi.DlgOpts{Title: "Checksums", Prompt: strings.Join(append(lns, errs...), "<br>\n") + "<br>\n<br>\n(copied to the clipboard)"}, gi.AddOk, gi.NoCancel, nil, nil)
+}
+
+// VerifyChecksum checks the selected file against the checksum(s) on the
+// clipboard: a bare md5, sha1, sha256 or sha512 checksum, or the output of
+// md5sum, sha256sum etc, e.g., from a release page
+func (ft *FileTreeView) VerifyChecksum() {
+	sels := ft.SelectedViews()
+	if len(sels) == 0 {
+		return
+	}
+	ftv := sels[len(sels)-1].Embed(KiT_FileTreeView).(*FileTreeView)
+	fn := ftv.FileNode()
+	if fn == nil || fn.IsDir() {
+		return
+	}
+	md := oswin.TheApp.ClipBoard(ft.Viewport.Win.OSWin).Read([]string{filecat.TextPlain})
+	if md == nil {
+		gi.PromptDialog(ft.Viewport, gi.DlgOpts{Title: "No Checksum", Prompt: "There is no text on the clipboard -- copy the checksum to verify first"}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	algs, err := VerifyChecksum(string(fn.FPath), md.Text(filecat.TextPlain))
+	if err != nil {
+		gi.PromptDialog(ft.Viewport, gi.DlgOpts{Title: "Checksum Not Verified", Prompt: fmt.Sprintf("File: %v<br>\n<b>%v</b>", fn.MyRelPath(), err)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	gi.PromptDialog(ft.Viewport, gi.DlgOpts{Title: "Checksum Verified", Prompt: fmt.Sprintf("File: %v matches the %v checksum(s) on the clipboard", fn.MyRelPath(), strings.Join(algs, ", "))}, gi.AddOk, gi.NoCancel, nil, nil)
+}
+
+// CompareFiles compares the two selected files byte-wise, reporting
+// whether they are identical or the offset of the first difference
+func (ft *FileTreeView) CompareFiles() {
+	sels := ft.SelectedViews()
+	var fns []*FileNode
+	for _, sn := range sels {
+		fn := sn.Embed(KiT_FileTreeView).(*FileTreeView).FileNode()
+		if fn != nil && !fn.IsDir() {
+			fns = append(fns, fn)
+		}
+	}
+	if len(fns) != 2 {
+		gi.PromptDialog(ft.Viewport, gi.DlgOpts{Title: "Select Two Files", Prompt: "Select exactly two files to compare (use Control or Shift click to select the second one)"}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	a, b := fns[0], fns[1]
+	same, off, err := CompareFiles(string(a.FPath), string(b.FPath))
+	prompt := ""
+	switch {
+	case err != nil:
+		prompt = fmt.Sprintf("Could not compare: %v", err)
+	case same:
+		prompt = fmt.Sprintf("<b>Identical</b>: %v and %v (%v bytes)", a.MyRelPath(), b.MyRelPath(), off)
+	default:
+		prompt = fmt.Sprintf("<b>Different</b>: %v (%v bytes) and %v (%v bytes) first differ at byte offset %v", a.MyRelPath(), a.Info.Size, b.MyRelPath(), b.Info.Size, off)
+	}
+	gi.PromptDialog(ft.Viewport, gi.DlgOpts{Title: "Compare Files", Prompt: prompt}, gi.AddOk, gi.NoCancel, nil, nil)
+}
+
 // FileTreeViewExecCmds gets list of available commands for given file node, as a submenu-func
 func FileTreeViewExecCmds(it interface{}, vp *gi.Viewport2D) []string {
 	ft, ok := it.(ki.Ki).Embed(KiT_FileTreeView).(*FileTreeView)
@@ -425,6 +702,11 @@ var FileTreeViewProps = ki.Props{
 		{"ShowFileInfo", ki.Props{
 			"label": "File Info",
 		}},
+		{"LocalHistory", ki.Props{
+			"label":    "Local History",
+			"desc":     "show the local history of saved versions of this file, with diffs against the current version -- versions are saved every time the file is saved in gide",
+			"updtfunc": FileTreeInactiveDirFunc,
+		}},
 		{"ExecCmdFiles", ki.Props{
 			"label":        "Exec Cmd",
 			"submenu-func": giv.SubMenuFunc(FileTreeViewExecCmds),
@@ -437,6 +719,10 @@ var FileTreeViewProps = ki.Props{
 			"updtfunc": FileTreeInactiveDirFunc,
 			"shortcut": gi.KeyFunDuplicate,
 		}},
+		{"TrashFiles", ki.Props{
+			"label": "Move To Trash",
+			"desc":  "move file(s) to the trash / recycle bin, closing any open buffers for them and removing them from version control",
+		}},
 		{"DeleteFiles", ki.Props{
 			"label":    "Delete",
 			"desc":     "Ok to delete file(s)?  This is not undoable and is not moving to trash / recycle bin",
@@ -446,6 +732,30 @@ var FileTreeViewProps = ki.Props{
 			"label": "Rename",
 			"desc":  "Rename file to new file name",
 		}},
+		{"CopyPaths", ki.Props{
+			"label": "Copy Path",
+			"desc":  "copy the full path(s) of the file(s) to the clipboard",
+		}},
+		{"CopyRelPaths", ki.Props{
+			"label": "Copy Relative Path",
+			"desc":  "copy the path(s) of the file(s) relative to the project root to the clipboard",
+		}},
+		{"sep-sums", ki.BlankProp{}},
+		{"ShowChecksums", ki.Props{
+			"label":    "Checksums",
+			"desc":     "show the md5 and sha256 checksums of the file(s), and copy them to the clipboard",
+			"updtfunc": FileTreeInactiveDirFunc,
+		}},
+		{"VerifyChecksum", ki.Props{
+			"label":    "Verify Checksum",
+			"desc":     "verify the file against the md5, sha1, sha256 or sha512 checksum(s) on the clipboard, e.g., copied from a release page",
+			"updtfunc": FileTreeInactiveDirFunc,
+		}},
+		{"CompareFiles", ki.Props{
+			"label":    "Compare Files",
+			"desc":     "compare the two selected files byte-wise",
+			"updtfunc": FileTreeInactiveDirFunc,
+		}},
 		{"sep-open", ki.BlankProp{}},
 		{"OpenDirs", ki.Props{
 			"label":    "Open Dir",
@@ -454,13 +764,16 @@ var FileTreeViewProps = ki.Props{
 		}},
 		{"NewFile", ki.Props{
 			"label":    "New File...",
-			"desc":     "make a new file in this folder",
+			"desc":     "make a new file in this folder, with initial contents from a template (chosen by extension if not selected)",
 			"shortcut": gi.KeyFunInsert,
 			"updtfunc": FileTreeActiveDirFunc,
 			"Args": ki.PropSlice{
 				{"File Name", ki.Props{
 					"width": 60,
 				}},
+				{"Template", ki.Props{
+					"desc": "template for the initial contents of the file -- if none, the template for the file's extension is used, if any",
+				}},
 				{"Add To Version Control", ki.Props{}},
 			},
 		}},
@@ -475,6 +788,22 @@ var FileTreeViewProps = ki.Props{
 				}},
 			},
 		}},
+		{"sep-pkg", ki.BlankProp{}},
+		{"TestPkg", ki.Props{
+			"label":    "Test Package",
+			"desc":     "run the tests of the package in this folder, e.g., go test, with the output in the tab of the test command",
+			"updtfunc": FileTreeActivePkgFunc(PkgTestCmd),
+		}},
+		{"BuildPkg", ki.Props{
+			"label":    "Build Package",
+			"desc":     "build the package in this folder, e.g., go build, with the output in the tab of the build command",
+			"updtfunc": FileTreeActivePkgFunc(PkgBuildCmd),
+		}},
+		{"BenchPkg", ki.Props{
+			"label":    "Benchmark Package",
+			"desc":     "run the benchmarks of the package in this folder, e.g., go test -bench, with the output in the tab of the benchmark command",
+			"updtfunc": FileTreeActivePkgFunc(PkgBenchCmd),
+		}},
 		{"sep-vcs", ki.BlankProp{}},
 		{"AddToVcs", ki.Props{
 			//"label":    "Add To Git",
@@ -17,12 +17,14 @@ func _() {
 	_ = x[FindLocFile-1]
 	_ = x[FindLocDir-2]
 	_ = x[FindLocNotTop-3]
-	_ = x[FindLocN-4]
+	_ = x[FindLocSelection-4]
+	_ = x[FindLocFunc-5]
+	_ = x[FindLocN-6]
 }
 
-const _FindLoc_name = "FindLocAllFindLocFileFindLocDirFindLocNotTopFindLocN"
+const _FindLoc_name = "FindLocAllFindLocFileFindLocDirFindLocNotTopFindLocSelectionFindLocFuncFindLocN"
 
-var _FindLoc_index = [...]uint8{0, 10, 21, 31, 44, 52}
+var _FindLoc_index = [...]uint8{0, 10, 21, 31, 44, 60, 71, 79}
 
 func (i FindLoc) String() string {
 	if i < 0 || i >= FindLoc(len(_FindLoc_index)-1) {
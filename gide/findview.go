@@ -6,6 +6,7 @@ package gide
 
 import (
 	"bytes"
+	"fmt"
 	"net/url"
 	"reflect"
 	"strings"
@@ -34,6 +35,15 @@ const (
 	// FindLocNotTop finds in all open folders *except* the top-level folder
 	FindLocNotTop
 
+	// FindLocSelection only finds within the selection in the current active
+	// file -- replace is limited to it as well
+	FindLocSelection
+
+	// FindLocFunc only finds within the function at the cursor in the
+	// current active file, from the language parser -- replace is limited to
+	// it as well
+	FindLocFunc
+
 	// FindLocN is the number of find locations (scopes)
 	FindLocN
 )
@@ -59,6 +69,32 @@ type FindParams struct {
 	ReplHist   []string            `desc:"history of replaces"`
 }
 
+// FindScope returns the region to search in for given location, for the
+// locations within the active file: the selection for FindLocSelection, and
+// the function at the cursor for FindLocFunc -- false if there is none
+func (tv *TextView) FindScope(loc FindLoc) (giv.TextRegion, bool) {
+	switch loc {
+	case FindLocSelection:
+		if tv.HasSelection() {
+			return tv.SelectReg, true
+		}
+	case FindLocFunc:
+		return tv.FuncRegion()
+	}
+	return giv.TextRegion{}, false
+}
+
+// ScopeMatches returns the matches that are entirely within the scope region
+func ScopeMatches(matches []giv.FileSearchMatch, scope giv.TextRegion) []giv.FileSearchMatch {
+	var sm []giv.FileSearchMatch
+	for _, mt := range matches {
+		if regionHas(scope, mt.Reg) {
+			sm = append(sm, mt)
+		}
+	}
+	return sm
+}
+
 // FindView is a find / replace widget that displays results in a TextView
 // and has a toolbar for controlling find / replace process.
 type FindView struct {
@@ -66,6 +102,7 @@ type FindView struct {
 	Gide   Gide          `json:"-" xml:"-" desc:"parent gide project"`
 	LangVV giv.ValueView `desc:"langs value view"`
 	Time   time.Time     `desc:"time of last find"`
+	Op     *ProjOp       `json:"-" xml:"-" view:"-" desc:"project operation of the current replace all, recording the buffers it changes for Undo"`
 }
 
 var KiT_FindView = kit.Types.AddType(&FindView{}, FindViewProps)
@@ -144,6 +181,9 @@ func (fv *FindView) ReplaceAction() bool {
 	reg = tv.Buf.AdjustReg(reg)
 	if !reg.IsNil() {
 		tv.RefreshIfNeeded()
+		if fv.Op != nil {
+			fv.Op.AddBuf(tv.Buf)
+		}
 		tbe := tv.Buf.DeleteText(reg.Start, reg.End, true, true)
 		tv.Buf.InsertText(tbe.Reg.Start, []byte(fv.Params().Replace), true, true)
 
@@ -166,14 +206,20 @@ func (fv *FindView) ReplaceAction() bool {
 	return ok
 }
 
-// ReplaceAllAction performs replace all
+// ReplaceAllAction performs replace all, as one project operation that can
+// be undone from the Operations history
 func (fv *FindView) ReplaceAllAction() {
+	fv.Op = NewProjOp(fmt.Sprintf("Replace All: %q with %q", fv.Params().Find, fv.Params().Replace))
 	for {
 		ok := fv.ReplaceAction()
 		if !ok {
 			break
 		}
 	}
+	if len(fv.Op.Files) > 0 {
+		fv.Gide.AddProjOp(fv.Op)
+	}
+	fv.Op = nil
 }
 
 // NextFind shows next find result
@@ -435,7 +481,7 @@ func (fv *FindView) ConfigToolbar() {
 
 	locl := rb.AddNewChild(gi.KiT_Label, "loc-lbl").(*gi.Label)
 	locl.SetText("Loc:")
-	locl.Tooltip = "location to find in: all = all open folders in browser; file = current active file; dir = directory of current active file; nottop = all except the top-level in browser"
+	locl.Tooltip = "location to find in: all = all open folders in browser; file = current active file; dir = directory of current active file; nottop = all except the top-level in browser; selection = selection in current active file; func = function at the cursor in current active file"
 	// locl.SetProp("vertical-align", gi.AlignMiddle)
 
 	cf := rb.AddNewChild(gi.KiT_ComboBox, "loc").(*gi.ComboBox)
@@ -28,9 +28,9 @@ type Gide interface {
 	// version or whatever is set in project preferences
 	VersCtrl() giv.VersCtrlName
 
-	// CmdRuns returns the CmdRuns manager of running commands, used extensively
-	// in commands.go
-	CmdRuns() *CmdRuns
+	// Jobs returns the registry of the jobs running in the background, e.g.,
+	// the commands run in commands.go
+	Jobs() *Jobs
 
 	// ArgVarVals returns the ArgVarVals argument variable values
 	ArgVarVals() *ArgVarVals
@@ -52,6 +52,15 @@ type Gide interface {
 	// ActiveTextView returns the currently-active TextView
 	ActiveTextView() *TextView
 
+	// RecycleMainTab adds a MainTabs tab with given label and widget type, or
+	// returns the existing one, and selects it if sel is true
+	RecycleMainTab(label string, typ reflect.Type, sel bool) gi.Node2D
+
+	// RecycleCmdTab adds a MainTabs tab showing the output buffer of given
+	// name, or returns the existing one, optionally clearing the buffer --
+	// returns the buffer, text view, and true if it is new
+	RecycleCmdTab(cmdNm string, sel bool, clearBuf bool) (*giv.TextBuf, *giv.TextView, bool)
+
 	// ConfigOutputTextView configures a command-output textview within given parent layout
 	ConfigOutputTextView(ly *gi.Layout) *giv.TextView
 
@@ -65,6 +74,10 @@ type Gide interface {
 	// ExecCmdNameFileName executes command of given name on given file name
 	ExecCmdNameFileName(fn string, cmdNm CmdName, sel bool, clearBuf bool)
 
+	// DiagLines returns the lines (0-based) of given file that have problems
+	// reported in the command output tabs, e.g., build errors or lint
+	DiagLines(fpath gi.FileName) []int
+
 	// Find does Find / Replace in files, using given options and filters -- opens up a
 	// main tab with the results and further controls.
 	Find(find, repl string, ignoreCase bool, loc FindLoc, langs []filecat.Supported)
@@ -85,6 +98,44 @@ type Gide interface {
 
 	// Declaration
 	Declaration()
+
+	// LocalHistory shows the local history of saved versions of given file
+	// node, with links to show diffs against the current buffer, or restore
+	LocalHistory(fn *giv.FileNode)
+
+	// FileRenamed updates any open buffers for a file or directory that has
+	// been renamed from oldpath to newpath
+	FileRenamed(oldpath, newpath gi.FileName)
+
+	// CloseFileBufs closes, without saving, any open buffers for the file or
+	// files within the directory at given path, e.g., prior to deleting it
+	CloseFileBufs(fpath gi.FileName)
+
+	// AddProjOp adds a project-level operation that changed multiple files,
+	// e.g., Replace All, to the Operations history, where it can be undone
+	AddProjOp(op *ProjOp)
+
+	// ProjStats shows the statistics of the project files, e.g., lines of
+	// code per language, in the Stats tab
+	ProjStats()
+
+	// DepGraph shows the dependency graph of the project, e.g., the imports
+	// among the packages of a Go module, in the Deps tab
+	DepGraph()
+
+	// SelectFileInTree opens the directories above the file or directory at
+	// given path in the file tree, and selects it
+	SelectFileInTree(fpath gi.FileName) bool
+
+	// DataChecked is called when the JSON, YAML or TOML file of the text
+	// view has been checked as it is edited, with its parsed root (nil on a
+	// syntax error) and the problems found -- changed is true if they differ
+	// from those of the last check
+	DataChecked(tv *TextView, root *DataNode, probs []DataProblem, changed bool)
+
+	// RunOnGUI runs the function on the window event loop goroutine, for
+	// the results of work done in the background
+	RunOnGUI(fun func())
 }
 
 // GideType is a Gide reflect.Type, suitable for checking for Type.Implements.
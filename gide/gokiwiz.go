@@ -0,0 +1,364 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// GoTypeName returns an exported Go type name made from the file name,
+// without its path and extension, e.g., MyView for my_view.go
+func GoTypeName(fname string) string {
+	fnm := filepath.Base(fname)
+	fnm = strings.TrimSuffix(fnm, filepath.Ext(fnm))
+	var sb strings.Builder
+	up := true
+	for _, r := range fnm {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if up {
+				r = unicode.ToUpper(r)
+				up = false
+			}
+			sb.WriteRune(r)
+		default:
+			up = true
+		}
+	}
+	tn := sb.String()
+	if tn == "" || unicode.IsDigit(rune(tn[0])) {
+		tn = "T" + tn
+	}
+	return tn
+}
+
+// GoImportPath returns the Go import path for the package in given
+// directory: from the module path in the go.mod file of the directory or
+// its parents, or else its path within the src of GOPATH -- "" if neither
+func GoImportPath(dir string) string {
+	for d := dir; ; {
+		if f, err := os.Open(filepath.Join(d, "go.mod")); err == nil {
+			sc := bufio.NewScanner(f)
+			mod := ""
+			for sc.Scan() {
+				if fs := strings.Fields(sc.Text()); len(fs) >= 2 && fs[0] == "module" {
+					mod = strings.Trim(fs[1], `"`)
+					break
+				}
+			}
+			f.Close()
+			if mod == "" {
+				return ""
+			}
+			rp, _ := filepath.Rel(d, dir)
+			return strings.TrimSuffix(mod+"/"+filepath.ToSlash(rp), "/.")
+		}
+		pd := filepath.Dir(d)
+		if pd == d {
+			break
+		}
+		d = pd
+	}
+	for _, gp := range filepath.SplitList(build.Default.GOPATH) {
+		src := filepath.Join(gp, "src") + string(filepath.Separator)
+		if strings.HasPrefix(dir, src) {
+			return filepath.ToSlash(strings.TrimPrefix(dir, src))
+		}
+	}
+	return ""
+}
+
+// GoKiWidgetTemplate is the name of the template for a GoKi widget file,
+// used by NewGoKiWidget -- GoKiWidgetText is used if it has been removed
+var GoKiWidgetTemplate = TemplateName("GoKi Widget")
+
+// NewGoKiWidget makes a new Go file at given path, with a GoKi widget type
+// of given name (from the file name if empty, see GoTypeName), from the
+// GoKiWidgetTemplate: with its KiT type registration, Props and AddNew
+// function -- the file must not already exist
+func NewGoKiWidget(fpath, typ, projName string) error {
+	text := GoKiWidgetText
+	if tm, _, ok := AvailTemplates.TemplateByName(GoKiWidgetTemplate); ok {
+		text = tm.Text
+	}
+	if typ == "" {
+		typ = GoTypeName(fpath)
+	}
+	return writeNewFile(fpath, ExpandTemplate(strings.Replace(text, "{Type}", typ, -1), fpath, projName))
+}
+
+// NewGoKiEditor makes a new package in the directory at given path for a
+// GoKi editor app, like gide, with an editor type of given name (from the
+// directory name if empty): an editor file from the GoKiEditorText, with
+// the editor's KiT type registration, Props with its ToolBar and MainMenu,
+// and a function making its window, and a cmd/<package> main package that
+// opens it -- the directory must not exist or be empty -- returns the paths
+// of the files made
+func NewGoKiEditor(dir, typ, projName string) ([]string, error) {
+	if fls, _ := filepath.Glob(filepath.Join(dir, "*")); len(fls) > 0 {
+		return nil, fmt.Errorf("directory is not empty: %v", dir)
+	}
+	if typ == "" {
+		typ = GoTypeName(dir)
+	}
+	pkg := GoPackageName(dir)
+	cdir := filepath.Join(dir, "cmd", pkg)
+	if err := os.MkdirAll(cdir, 0775); err != nil {
+		return nil, err
+	}
+	ipath := GoImportPath(dir)
+	if ipath == "" {
+		ipath = pkg
+	}
+	rp := strings.NewReplacer("{Type}", typ, "{Package}", pkg, "{ImportPath}", ipath)
+	efn := filepath.Join(dir, strings.ToLower(typ)+".go")
+	mfn := filepath.Join(cdir, pkg+".go")
+	if err := writeNewFile(efn, ExpandTemplate(rp.Replace(GoKiEditorText), efn, projName)); err != nil {
+		return nil, err
+	}
+	if err := writeNewFile(mfn, ExpandTemplate(rp.Replace(GoKiEditorMainText), mfn, projName)); err != nil {
+		return []string{efn}, err
+	}
+	return []string{efn, mfn}, nil
+}
+
+// writeNewFile writes the text to a new file at given path, which must not
+// already exist
+func writeNewFile(fpath, text string) error {
+	f, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(text)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// GoKiWidgetText is the text of the standard GoKi Widget template
+var GoKiWidgetText = `package {Package}
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// {Type} is a widget that
+type {Type} struct {
+	gi.Layout
+}
+
+var KiT_{Type} = kit.Types.AddType(&{Type}{}, {Type}Props)
+
+// AddNew{Type} adds a new {Type} to given parent node, with given name.
+func AddNew{Type}(parent ki.Ki, name string) *{Type} {
+	return parent.AddNewChild(KiT_{Type}, name).(*{Type})
+}
+
+// Config configures the children of the {Type}
+func (w *{Type}) Config() {
+	w.Lay = gi.LayoutVert
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Label, "label")
+	mods, updt := w.ConfigChildren(config, false)
+	if !mods {
+		updt = w.UpdateStart()
+	}
+	w.Label().SetText("{Type}")
+	w.UpdateEnd(updt)
+}
+
+// Label returns the label of the {Type}
+func (w *{Type}) Label() *gi.Label {
+	return w.ChildByName("label", 0).(*gi.Label)
+}
+
+// {Type}Props are the style properties of the {Type} -- EnumType:Flag is
+// needed for its flags to be shown and saved by name
+var {Type}Props = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+`
+
+// GoKiEditorText is the text of the editor file made by NewGoKiEditor, with
+// the TemplateVars and {Type}, the name of the editor type
+var GoKiEditorText = `package {Package}
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// {Type} is an editor, with a toolbar and main menu whose actions call its
+// methods of the same names (see {Type}Props)
+type {Type} struct {
+	gi.Frame
+	Filename gi.FileName ` + "`" + `ext:".json" desc:"file being edited"` + "`" + `
+	Changed  bool        ` + "`" + `json:"-" desc:"the data has been changed since it was last saved"` + "`" + `
+}
+
+var KiT_{Type} = kit.Types.AddType(&{Type}{}, {Type}Props)
+
+// AddNew{Type} adds a new {Type} to given parent node, with given name.
+func AddNew{Type}(parent ki.Ki, name string) *{Type} {
+	return parent.AddNewChild(KiT_{Type}, name).(*{Type})
+}
+
+// Open opens the file to edit
+func (ed *{Type}) Open(filename gi.FileName) error {
+	ed.Filename = filename
+	ed.Changed = false
+	return nil
+}
+
+// Save saves the data to the file being edited
+func (ed *{Type}) Save() error {
+	ed.Changed = false
+	return nil
+}
+
+// Config configures the editor
+func (ed *{Type}) Config() {
+	ed.Lay = gi.LayoutVert
+	ed.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "toolbar")
+	config.Add(gi.KiT_Frame, "main")
+	mods, updt := ed.ConfigChildren(config, false)
+	if !mods {
+		updt = ed.UpdateStart()
+	}
+	tb := ed.ToolBar()
+	if !tb.HasChildren() {
+		giv.ToolBarView(ed, ed.Viewport, tb)
+	}
+	ed.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar of the editor
+func (ed *{Type}) ToolBar() *gi.ToolBar {
+	return ed.ChildByName("toolbar", 0).(*gi.ToolBar)
+}
+
+// {Type}Props are the properties of the {Type}: its styles, and its ToolBar
+// and MainMenu, whose actions call its methods of the same names
+var {Type}Props = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+	"ToolBar": ki.PropSlice{
+		{"Open", ki.Props{
+			"label": "Open",
+			"icon":  "file-open",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"default-field": "Filename",
+				}},
+			},
+		}},
+		{"Save", ki.Props{
+			"icon": "file-save",
+		}},
+	},
+	"MainMenu": ki.PropSlice{
+		{"AppMenu", ki.BlankProp{}},
+		{"File", ki.PropSlice{
+			{"Open", ki.Props{
+				"shortcut": gi.KeyFunMenuOpen,
+				"label":    "Open...",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"default-field": "Filename",
+					}},
+				},
+			}},
+			{"Save", ki.Props{
+				"shortcut": gi.KeyFunMenuSave,
+			}},
+			{"sep-close", ki.BlankProp{}},
+			{"Close Window", ki.BlankProp{}},
+		}},
+		{"Edit", "Copy Cut Paste"},
+		{"Window", "Windows"},
+	},
+}
+
+// New{Type}Window makes a new window with a {Type}, editing given file if
+// not empty
+func New{Type}Window(filename gi.FileName) (*gi.Window, *{Type}) {
+	width := 1280
+	height := 720
+	win := gi.NewMainWindow("{Package}", "{Type}", width, height)
+
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+
+	mfr := win.SetMainFrame()
+	ed := AddNew{Type}(mfr, "editor")
+	ed.Viewport = vp
+	ed.Config()
+	if filename != "" {
+		ed.Open(filename)
+	}
+
+	giv.MainMenuView(ed, win, win.MainMenu)
+
+	win.OSWin.SetCloseCleanFunc(func(w oswin.Window) {
+		if gi.MainWindows.Len() <= 1 {
+			go oswin.TheApp.Quit() // once main window is closed, quit
+		}
+	})
+
+	win.MainMenuUpdated()
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+	return win, ed
+}
+`
+
+// GoKiEditorMainText is the text of the main package made by NewGoKiEditor,
+// with the TemplateVars, {Type}, the name of the editor type, and
+// {ImportPath}, the import path of its package
+var GoKiEditorMainText = `package main
+
+import (
+	"flag"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gimain"
+	"github.com/goki/gi/oswin"
+	"{ImportPath}"
+)
+
+func main() {
+	gimain.Main(func() {
+		mainrun()
+	})
+}
+
+func mainrun() {
+	oswin.TheApp.SetName("{Package}")
+	flag.Parse()
+	{Package}.New{Type}Window(gi.FileName(flag.Arg(0)))
+	gi.WinWait.Wait()
+}
+`
@@ -0,0 +1,130 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/giv"
+)
+
+// GotoTarget is where the Goto command goes, as parsed by ParseGoto
+type GotoTarget struct {
+	File string `desc:"file to open first, as given -- empty for the active view"`
+	Ln   int    `desc:"line number, starting at 1 -- 0 if not given, for the file form"`
+	Col  int    `desc:"column, starting at 1 -- 0 if not given"`
+}
+
+// ParseGoto parses the target of the Goto command, given the current line
+// and the number of lines of the active view (both starting at 1): a line
+// number, e.g., 120, a number of lines down or up from the current line,
+// e.g., +15 or -10, or a percentage of the lines, e.g., 50% -- any of which
+// can be followed by a column, e.g., 120:8 -- or else a file, optionally
+// followed by a line and column, e.g., file.go:120:8 -- lines are clamped to
+// those of the view, except in the file form, whose lines are not known yet
+func ParseGoto(s string, curLn, nLines int) (GotoTarget, error) {
+	var gt GotoTarget
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return gt, errors.New("Goto: nothing to go to")
+	}
+	var nums []int // trailing :line:col numbers
+	rest := s
+	for len(nums) < 2 {
+		ci := strings.LastIndex(rest, ":")
+		if ci < 0 {
+			break
+		}
+		n, err := strconv.Atoi(rest[ci+1:])
+		if err != nil || n < 0 {
+			break
+		}
+		nums = append([]int{n}, nums...)
+		rest = rest[:ci]
+	}
+	ln, isln, err := parseGotoLine(rest, curLn, nLines)
+	if err != nil {
+		return gt, err
+	}
+	if !isln {
+		gt.File = rest
+		if len(nums) > 0 {
+			gt.Ln = nums[0]
+		}
+		if len(nums) > 1 {
+			gt.Col = nums[1]
+		}
+		return gt, nil
+	}
+	if len(nums) > 1 {
+		return gt, fmt.Errorf("Goto: %q has too many numbers -- use line:col or file:line:col", s)
+	}
+	gt.Ln = ln
+	if len(nums) > 0 {
+		gt.Col = nums[0]
+	}
+	if nLines > 0 && gt.Ln > nLines {
+		gt.Ln = nLines
+	}
+	if gt.Ln < 1 {
+		gt.Ln = 1
+	}
+	return gt, nil
+}
+
+// parseGotoLine parses the line part of a Goto target: a line number, a
+// relative +N / -N, or a percentage -- isln is false if it is none of those,
+// i.e., it is a file name, and err is set if it is one but malformed
+func parseGotoLine(s string, curLn, nLines int) (ln int, isln bool, err error) {
+	switch {
+	case s == "":
+		return 0, false, nil
+	case strings.HasSuffix(s, "%"):
+		pct, perr := strconv.ParseFloat(s[:len(s)-1], 64)
+		if perr != nil {
+			return 0, false, nil
+		}
+		if pct < 0 || pct > 100 {
+			return 0, true, fmt.Errorf("Goto: %q is not a percentage from 0 to 100", s)
+		}
+		return int(math.Round(float64(nLines) * pct / 100)), true, nil
+	case s[0] == '+' || s[0] == '-':
+		n, nerr := strconv.Atoi(s[1:])
+		if nerr != nil {
+			return 0, false, nil
+		}
+		if s[0] == '-' {
+			n = -n
+		}
+		return curLn + n, true, nil
+	}
+	n, nerr := strconv.Atoi(s)
+	if nerr != nil {
+		return 0, false, nil
+	}
+	return n, true, nil
+}
+
+// Pos returns the position of the target in a buffer of given number of
+// lines, with the line clamped to those of the buffer -- the column is
+// clamped to the length of the line by SetCursorShow
+func (gt *GotoTarget) Pos(nLines int) giv.TextPos {
+	ln := gt.Ln
+	if ln > nLines {
+		ln = nLines
+	}
+	if ln < 1 {
+		ln = 1
+	}
+	ch := gt.Col - 1
+	if ch < 0 {
+		ch = 0
+	}
+	return giv.TextPos{Ln: ln - 1, Ch: ch}
+}
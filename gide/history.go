@@ -0,0 +1,129 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+)
+
+// HistoryMax is the maximum number of local history snapshots kept per file
+// -- the oldest are removed when this is exceeded
+var HistoryMax = 50
+
+// HistoryTimeFormat is the time format used for snapshot file names
+var HistoryTimeFormat = "20060102-150405.000"
+
+// HistorySep separates the time from the file name in snapshot file names,
+// which are <time>~<name>, keeping the extension of the file, if any
+var HistorySep = "~"
+
+// HistoryRootDir returns the root directory for local file history, in the
+// user cache directory (e.g., ~/.cache/gide/history)
+func HistoryRootDir() string {
+	cdir, err := os.UserCacheDir()
+	if err != nil {
+		cdir = os.TempDir()
+	}
+	return filepath.Join(cdir, "gide", "history")
+}
+
+// HistoryDir returns the directory holding the local history snapshots for
+// the file at given path
+func HistoryDir(fpath gi.FileName) string {
+	abs, err := filepath.Abs(string(fpath))
+	if err != nil {
+		abs = string(fpath)
+	}
+	h := fnv.New64a()
+	h.Write([]byte(abs))
+	return filepath.Join(HistoryRootDir(), fmt.Sprintf("%s-%016x", filepath.Base(abs), h.Sum64()))
+}
+
+// HistorySnapshot is one saved version of a file in the local history
+type HistorySnapshot struct {
+	Path string    `desc:"full path to the snapshot file"`
+	Time time.Time `desc:"time when the version was saved"`
+	Size int64     `desc:"size of the snapshot in bytes"`
+}
+
+// FileHistory returns the local history snapshots for the file at given
+// path, most recent first
+func FileHistory(fpath gi.FileName) []HistorySnapshot {
+	hdir := HistoryDir(fpath)
+	fis, err := ioutil.ReadDir(hdir)
+	if err != nil {
+		return nil
+	}
+	var hs []HistorySnapshot
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		ts := fi.Name()
+		if si := strings.Index(ts, HistorySep); si >= 0 {
+			ts = ts[:si]
+		} else if len(ts) > len(HistoryTimeFormat) { // older snapshots are <time><ext>
+			ts = ts[:len(HistoryTimeFormat)]
+		}
+		tm, err := time.ParseInLocation(HistoryTimeFormat, ts, time.Local)
+		if err != nil {
+			continue
+		}
+		hs = append(hs, HistorySnapshot{Path: filepath.Join(hdir, fi.Name()), Time: tm, Size: fi.Size()})
+	}
+	sort.Slice(hs, func(i, j int) bool {
+		return hs[i].Time.After(hs[j].Time)
+	})
+	return hs
+}
+
+// SaveHistory saves a snapshot of the current contents of the file at given
+// path to its local history, unless it is the same as the most recent
+// snapshot -- the oldest snapshots beyond HistoryMax are removed
+func SaveHistory(fpath gi.FileName) error {
+	b, err := ioutil.ReadFile(string(fpath))
+	if err != nil {
+		return err
+	}
+	hs := FileHistory(fpath)
+	if len(hs) > 0 && hs[0].Size == int64(len(b)) {
+		if lb, err := ioutil.ReadFile(hs[0].Path); err == nil && bytes.Equal(lb, b) {
+			return nil
+		}
+	}
+	hdir := HistoryDir(fpath)
+	if err := os.MkdirAll(hdir, 0755); err != nil {
+		return err
+	}
+	snm := time.Now().Format(HistoryTimeFormat) + HistorySep + filepath.Base(string(fpath))
+	if err := ioutil.WriteFile(filepath.Join(hdir, snm), b, 0644); err != nil {
+		return err
+	}
+	for i := HistoryMax - 1; i < len(hs); i++ { // one new one added
+		os.Remove(hs[i].Path)
+	}
+	return nil
+}
+
+// HistoryDiff returns the unified diff from the given snapshot to the given
+// (current) buffer
+func HistoryDiff(snap string, tb *giv.TextBuf) ([]byte, error) {
+	b, err := ioutil.ReadFile(snap)
+	if err != nil {
+		return nil, err
+	}
+	return DiffTextBuf(snap, b).DiffBufsUnified(tb, 3), nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goki/gi/gi"
+)
+
+func TestSaveHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+
+	svmax := HistoryMax
+	HistoryMax = 2
+	defer func() { HistoryMax = svmax }()
+
+	for _, fn := range []string{"main.go", "Makefile", "archive.tar.gz"} {
+		fpath := gi.FileName(filepath.Join(dir, fn))
+		for i := 0; i < 3; i++ {
+			time.Sleep(2 * time.Millisecond) // snapshot names have the time in msec
+			if err := ioutil.WriteFile(string(fpath), []byte(fmt.Sprintf("version %d\n", i)), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := SaveHistory(fpath); err != nil {
+				t.Errorf("SaveHistory %v error: should save, got: %v\n", fn, err)
+			}
+			if i == 2 { // same as the last snapshot
+				if err := SaveHistory(fpath); err != nil {
+					t.Errorf("SaveHistory %v error: should skip the unchanged file, got: %v\n", fn, err)
+				}
+			}
+		}
+		hs := FileHistory(fpath)
+		if len(hs) != 2 {
+			t.Errorf("FileHistory %v error: should have %d snapshots, got: %v\n", fn, HistoryMax, hs)
+			continue
+		}
+		if b, err := ioutil.ReadFile(hs[0].Path); err != nil || string(b) != "version 2\n" {
+			t.Errorf("FileHistory %v error: the latest snapshot should be version 2, got: %q, %v\n", fn, b, err)
+		}
+		if !strings.HasSuffix(hs[0].Path, HistorySep+fn) {
+			t.Errorf("FileHistory %v error: snapshot should end in the file name, got: %v\n", fn, hs[0].Path)
+		}
+		fis, _ := ioutil.ReadDir(HistoryDir(fpath))
+		if len(fis) != 2 {
+			t.Errorf("SaveHistory %v error: should remove the snapshots beyond HistoryMax, got: %v files\n", fn, len(fis))
+		}
+	}
+}
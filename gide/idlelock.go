@@ -0,0 +1,56 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+// LockPassIters is the number of sha256 iterations used in hashing the lock
+// passphrase, to slow down guessing it from the saved hash
+var LockPassIters = 100000
+
+// LockText is the text shown in place of the editor contents while a
+// project is locked
+var LockText = "\n\n\tProject locked after inactivity -- enter the lock passphrase to unlock\n"
+
+// lockPassHash returns the iterated sha256 hash of the passphrase with salt
+func lockPassHash(salt []byte, pass string) []byte {
+	h := sha256.Sum256(append(append([]byte{}, salt...), pass...))
+	for i := 1; i < LockPassIters; i++ {
+		h = sha256.Sum256(h[:])
+	}
+	return h[:]
+}
+
+// HashLockPass returns the salted hash of the lock passphrase, as salt$hash
+// in hex, for saving in the prefs -- the passphrase itself is never saved
+func HashLockPass(pass string) string {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	return hex.EncodeToString(salt) + "$" + hex.EncodeToString(lockPassHash(salt, pass))
+}
+
+// CheckLockPass returns true if the passphrase matches the hash from
+// HashLockPass
+func CheckLockPass(hash, pass string) bool {
+	sh := strings.SplitN(hash, "$", 2)
+	if len(sh) != 2 {
+		return false
+	}
+	salt, err := hex.DecodeString(sh[0])
+	if err != nil {
+		return false
+	}
+	want, err := hex.DecodeString(sh[1])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(lockPassHash(salt, pass), want) == 1
+}
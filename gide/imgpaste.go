@@ -0,0 +1,207 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/pi/filecat"
+)
+
+// DefaultAssetsDir is the directory where images pasted into Markdown files
+// are saved, if not set in the project prefs
+var DefaultAssetsDir = "assets"
+
+// ImageExts maps image mime types to the file extensions used when saving
+// pasted images
+var ImageExts = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+	"image/svg+xml": ".svg",
+	"image/webp":    ".webp",
+}
+
+// ImagePasteCmds are external commands, by runtime.GOOS, that are tried in
+// order to get png image data from the system clipboard, when the window
+// clipboard does not provide it -- each writes the image to stdout
+var ImagePasteCmds = map[string][][]string{
+	"linux": {
+		{"wl-paste", "--no-newline", "--type", "image/png"},
+		{"xclip", "-selection", "clipboard", "-target", "image/png", "-out"},
+	},
+	"darwin": {
+		{"pngpaste", "-"},
+	},
+	"windows": {
+		{"powershell", "-NoProfile", "-Command", "$i = Get-Clipboard -Format Image; if ($i) { $m = New-Object System.IO.MemoryStream; $i.Save($m, [System.Drawing.Imaging.ImageFormat]::Png); [Console]::OpenStandardOutput().Write($m.ToArray(), 0, $m.Length) }"},
+	},
+}
+
+// MimesImage returns the first image data in the mime data, with its
+// file extension
+func MimesImage(md mimedata.Mimes) ([]byte, string) {
+	for _, d := range md {
+		if ext, has := ImageExts[d.Type]; has && len(d.Data) > 0 {
+			return d.Data, ext
+		}
+	}
+	return nil, ""
+}
+
+// ImageFileText returns the contents of the image file named in given text,
+// as a path or file:// url, as copied from a file manager
+func ImageFileText(txt string) ([]byte, string) {
+	txt = strings.TrimSpace(txt)
+	if strings.Contains(txt, "\n") {
+		return nil, ""
+	}
+	if strings.HasPrefix(txt, "file://") {
+		up, err := url.Parse(txt)
+		if err != nil {
+			return nil, ""
+		}
+		txt = up.Path
+	}
+	ext := strings.ToLower(filepath.Ext(txt))
+	if ext == ".jpeg" {
+		ext = ".jpg"
+	}
+	known := false
+	for _, ie := range ImageExts {
+		if ie == ext {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return nil, ""
+	}
+	b, err := ioutil.ReadFile(txt)
+	if err != nil {
+		return nil, ""
+	}
+	return b, ext
+}
+
+// CmdClipboardImage gets png image data from the system clipboard using
+// the first of the ImagePasteCmds for this platform that works
+func CmdClipboardImage() ([]byte, string) {
+	for _, cmd := range ImagePasteCmds[runtime.GOOS] {
+		if _, err := exec.LookPath(cmd[0]); err != nil {
+			continue
+		}
+		b, err := exec.Command(cmd[0], cmd[1:]...).Output()
+		if err == nil && len(b) > 0 {
+			return b, ".png"
+		}
+	}
+	return nil, ""
+}
+
+// ClipboardImage returns the image data on the clipboard of given window,
+// with its file extension -- tries image data from the window clipboard,
+// then an image file named by the clipboard text, and then the
+// ImagePasteCmds.  If text is false, text on the clipboard is not looked at.
+func ClipboardImage(win *gi.Window, text bool) ([]byte, string) {
+	cb := oswin.TheApp.ClipBoard(win.OSWin)
+	md := cb.Read([]string{filecat.TextPlain})
+	if b, ext := MimesImage(md); b != nil {
+		return b, ext
+	}
+	if md != nil {
+		if !text {
+			return nil, ""
+		}
+		if b, ext := ImageFileText(md.Text(filecat.TextPlain)); b != nil {
+			return b, ext
+		}
+	}
+	return CmdClipboardImage()
+}
+
+// SaveAsset saves the image data as a new file in the assets directory for
+// the given Markdown file, returning the relative link to it from the
+// Markdown file -- a relative assets dir is relative to the Markdown file
+func SaveAsset(mdfile, assetsDir string, b []byte, ext string) (string, error) {
+	if assetsDir == "" {
+		assetsDir = DefaultAssetsDir
+	}
+	mdir := filepath.Dir(mdfile)
+	adir := assetsDir
+	if !filepath.IsAbs(adir) {
+		adir = filepath.Join(mdir, adir)
+	}
+	if err := os.MkdirAll(adir, 0755); err != nil {
+		return "", err
+	}
+	base := strings.TrimSuffix(filepath.Base(mdfile), filepath.Ext(mdfile)) + "-" + time.Now().Format("20060102-150405")
+	fnm := filepath.Join(adir, base+ext)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(fnm); os.IsNotExist(err) {
+			break
+		}
+		fnm = filepath.Join(adir, fmt.Sprintf("%s-%d%s", base, i, ext))
+	}
+	if err := ioutil.WriteFile(fnm, b, 0644); err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(mdir, fnm)
+	if err != nil {
+		rel = fnm
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// PasteImage saves the image on the clipboard into the assets directory (see
+// SaveAsset) and inserts a Markdown image link to it at the cursor -- only
+// for Markdown files.  If text is false, text on the clipboard is ignored,
+// so that this can be used after a regular paste.
+func (tv *TextView) PasteImage(assetsDir string, text bool) error {
+	if tv.Buf == nil || OutlineFormat(string(tv.Buf.Filename)) != "md" {
+		return fmt.Errorf("Paste Image is only for Markdown files")
+	}
+	if strings.HasPrefix(string(tv.Buf.Filename), ScratchPrefix) {
+		return fmt.Errorf("save the file before pasting images, so they can be saved relative to it")
+	}
+	b, ext := ClipboardImage(tv.Viewport.Win, text)
+	if b == nil {
+		return fmt.Errorf("no image on the clipboard")
+	}
+	rel, err := SaveAsset(string(tv.Buf.Filename), assetsDir, b, ext)
+	if err != nil {
+		return err
+	}
+	tv.InsertAtCursor([]byte(fmt.Sprintf("![](%s)", rel)))
+	return nil
+}
+
+// PasteImageKeyInput pastes an image after a regular paste key in Markdown
+// files, if the clipboard has no text but has an image
+func (tv *TextView) PasteImageKeyInput(kt *key.ChordEvent) {
+	if gi.KeyFun(kt.Chord()) != gi.KeyFunPaste || tv.Buf == nil || tv.IsInactive() {
+		return
+	}
+	if OutlineFormat(string(tv.Buf.Filename)) != "md" || strings.HasPrefix(string(tv.Buf.Filename), ScratchPrefix) {
+		return
+	}
+	var adir string
+	if ge, ok := ParentGide(tv.This()); ok {
+		adir = ge.ProjPrefs().AssetsDir
+	}
+	tv.PasteImage(adir, false)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goki/gi/gi"
+)
+
+func TestProjectImportConfigs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, ".vscode"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, ".vscode", "settings.json"), []byte("{\n  // indent\n  \"editor.tabSize\": 2,\n  \"editor.insertSpaces\": true,\n}\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, ".vscode", "tasks.json"), []byte(`{"tasks": [{"label": "vet", "type": "shell", "command": "go vet ${fileDirname}"}]}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "Makefile"), []byte("CC := gcc\n.PHONY: all test\nall: prog\n\tcc -o prog main.c\ntest:\n\t./prog\n%.o: %.c\n"), 0644)
+
+	pj := Project{ProjRoot: gi.FileName(dir)}
+	pi := pj.ImportConfigs()
+	if pj.Prefs.Editor.TabSize != 2 || !pj.Prefs.Editor.SpaceIndent {
+		t.Errorf("import error: settings not imported: tab size: %v space indent: %v\n", pj.Prefs.Editor.TabSize, pj.Prefs.Editor.SpaceIndent)
+	}
+	if len(pi.Cmds) != 3 {
+		t.Fatalf("import error: should have imported 3 commands, got: %v\n", len(pi.Cmds))
+	}
+	vet := pi.Cmds[0]
+	if vet.Name != "VS Code: vet" || vet.Cmds[0].Cmd != "go" || len(vet.Cmds[0].Args) != 2 || vet.Cmds[0].Args[1] != "{FileDirPath}" {
+		t.Errorf("import error: task not translated: %v %v\n", vet.Name, vet.Cmds[0])
+	}
+	if pi.Cmds[1].Name != "Make all" || pi.Cmds[2].Name != "Make test" {
+		t.Errorf("import error: makefile targets should be all, test, got: %v, %v\n", pi.Cmds[1].Name, pi.Cmds[2].Name)
+	}
+	if len(pj.Prefs.BuildCmds) != 1 || pj.Prefs.BuildCmds[0] != "Make all" {
+		t.Errorf("import error: build cmds should be Make all, were: %v\n", pj.Prefs.BuildCmds)
+	}
+}
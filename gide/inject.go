@@ -0,0 +1,467 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+	"github.com/goki/pi/lex"
+	"github.com/goki/pi/token"
+)
+
+// InjectMaxLines is the maximum number of lines in a file for its embedded
+// languages to be highlighted -- the whole file is scanned on each change
+var InjectMaxLines = 20000
+
+// Injection is a language embedded in files of another language, e.g., SQL
+// in the strings of Go code, whose regions are highlighted as that language:
+// the string literals whose text matches Detect, or that have Marker in a
+// comment just before them, or else the text between matches of Start and
+// End, if set
+type Injection struct {
+	Lang   string `desc:"embedded language, by its name for the highlighter, e.g., sql, javascript, css, yaml"`
+	Detect string `desc:"regular expression that the text of a string literal must match for it to be highlighted as the embedded language, e.g., (?i)^\\s*select\\s"`
+	Marker string `desc:"marker in a comment before a string literal, on its line or the line before, that makes it of the embedded language, e.g., lang:yaml"`
+	Start  string `desc:"regular expression matching the start of a region of the embedded language, e.g., <script[^>]*> -- the region ends at the next match of End -- if set, strings are not checked"`
+	End    string `desc:"regular expression matching the end of a region started by Start, e.g., </script>"`
+}
+
+// SQLInjection highlights SQL in the string literals that start with a
+// statement, or are marked with lang:sql
+var SQLInjection = Injection{Lang: "sql", Detect: `(?is)^\s*(select|insert|update|delete|create|alter|drop|with)\s`, Marker: "lang:sql"}
+
+// InjectRegion is a region of text in an embedded language
+type InjectRegion struct {
+	Reg  giv.TextRegion `desc:"region of the text, without the quotes of strings"`
+	Lang string         `desc:"embedded language of the text"`
+}
+
+// InjectLine is the highlighting of the embedded languages on a line
+type InjectLine struct {
+	Spans [][2]int `desc:"byte ranges of the line in embedded languages, where the highlighting of the file language is replaced"`
+	Tags  lex.Line `desc:"syntax tags of the embedded languages"`
+}
+
+// InjectLines is the highlighting of the embedded languages, by line
+type InjectLines map[int]*InjectLine
+
+var (
+	injectMu      sync.Mutex
+	injectRes     = map[string]*regexp.Regexp{}
+	injectLexers  = map[string]chroma.Lexer{}
+	injectNoLexer = map[string]bool{}
+)
+
+// injectRe returns the compiled regular expression, cached, or nil if it
+// is not valid
+func injectRe(pat string) *regexp.Regexp {
+	injectMu.Lock()
+	defer injectMu.Unlock()
+	re, has := injectRes[pat]
+	if !has {
+		re, _ = regexp.Compile(pat)
+		injectRes[pat] = re
+	}
+	return re
+}
+
+// injectLexer returns the lexer of the highlighter for given language,
+// cached, or nil if there is none
+func injectLexer(lang string) chroma.Lexer {
+	injectMu.Lock()
+	defer injectMu.Unlock()
+	if lx, has := injectLexers[lang]; has {
+		return lx
+	}
+	if injectNoLexer[lang] {
+		return nil
+	}
+	lx := lexers.Get(lang)
+	if lx == nil {
+		injectNoLexer[lang] = true
+		return nil
+	}
+	lx = chroma.Coalesce(lx)
+	injectLexers[lang] = lx
+	return lx
+}
+
+// regionText returns the text of the region of the lines
+func regionText(lines [][]rune, reg giv.TextRegion) string {
+	var sb strings.Builder
+	for ln := reg.Start.Ln; ln <= reg.End.Ln && ln < len(lines); ln++ {
+		lt := lines[ln]
+		st, ed := 0, len(lt)
+		if ln == reg.Start.Ln {
+			st = reg.Start.Ch
+		}
+		if ln == reg.End.Ln {
+			ed = reg.End.Ch
+		}
+		if ln > reg.Start.Ln {
+			sb.WriteByte('\n')
+		}
+		if st < ed && ed <= len(lt) {
+			sb.WriteString(string(lt[st:ed]))
+		}
+	}
+	return sb.String()
+}
+
+// quoteClose returns the position of the quote q closing a string whose
+// text starts at given position, which may be on a later line, or -1 line
+// if it is not closed
+func quoteClose(lines [][]rune, ln, ch int, q []rune) (int, int) {
+	for ; ln < len(lines); ln++ {
+		lt := lines[ln]
+		for ; ch < len(lt); ch++ {
+			if runesAt(lt, ch, q) {
+				return ln, ch
+			}
+		}
+		ch = 0
+	}
+	return -1, 0
+}
+
+// scanStrings calls fun with the region of the text of each string literal
+// in the lines, without its quotes, outside of comments -- strings quoted
+// by ` or by tripled quotes may span lines
+func scanStrings(lines [][]rune, syn *BracketSyntax, fun func(reg giv.TextRegion)) {
+	cln := []rune(syn.CommentLn)
+	cst := []rune(syn.CommentSt)
+	ced := []rune(syn.CommentEd)
+	incmt := false
+	for ln := 0; ln < len(lines); ln++ {
+		lt := lines[ln]
+		for ch := 0; ch < len(lt); ch++ {
+			if incmt {
+				if runesAt(lt, ch, ced) {
+					incmt = false
+					ch += len(ced) - 1
+				}
+				continue
+			}
+			if runesAt(lt, ch, cln) {
+				break
+			}
+			if len(ced) > 0 && runesAt(lt, ch, cst) {
+				incmt = true
+				ch += len(cst) - 1
+				continue
+			}
+			r := lt[ch]
+			if !strings.ContainsRune(syn.Quotes, r) {
+				continue
+			}
+			q := []rune{r}
+			if r != '`' && runesAt(lt, ch, []rune{r, r, r}) {
+				q = []rune{r, r, r}
+			}
+			if len(q) == 1 && r != '`' {
+				if qe := quoteEnd(lt, ch); qe > 0 {
+					fun(giv.NewTextRegion(ln, ch+1, ln, qe))
+					ch = qe
+				}
+				continue
+			}
+			eln, ech := quoteClose(lines, ln, ch+len(q), q)
+			if eln < 0 {
+				ch += len(q) - 1
+				continue
+			}
+			fun(giv.NewTextRegion(ln, ch+len(q), eln, ech))
+			ln = eln
+			lt = lines[ln]
+			ch = ech + len(q) - 1
+		}
+	}
+}
+
+// hasMarker returns true if the text has the marker in a comment
+func hasMarker(txt, marker string, syn *BracketSyntax) bool {
+	i := strings.Index(txt, marker)
+	if i < 0 {
+		return false
+	}
+	pre := txt[:i]
+	return (syn.CommentLn != "" && strings.Contains(pre, syn.CommentLn)) || (syn.CommentSt != "" && strings.Contains(pre, syn.CommentSt))
+}
+
+// delimRegions returns the regions of the lines between the matches of st
+// and ed, in order -- a region that is not ended is dropped
+func delimRegions(lines [][]rune, st, ed *regexp.Regexp) []giv.TextRegion {
+	var regs []giv.TextRegion
+	in := false
+	var sp giv.TextPos
+	for ln, lt := range lines {
+		s := string(lt)
+		off := 0
+		for off <= len(s) {
+			re := st
+			if in {
+				re = ed
+			}
+			m := re.FindStringIndex(s[off:])
+			if m == nil || (!in && m[1] == 0) {
+				break
+			}
+			if in {
+				ep := giv.TextPos{Ln: ln, Ch: utf8.RuneCountInString(s[:off+m[0]])}
+				if sp.IsLess(ep) {
+					regs = append(regs, giv.NewTextRegionPos(sp, ep))
+				}
+			} else {
+				sp = giv.TextPos{Ln: ln, Ch: utf8.RuneCountInString(s[:off+m[1]])}
+			}
+			in = !in
+			if m[1] == 0 {
+				off++
+			} else {
+				off += m[1]
+			}
+		}
+	}
+	return regs
+}
+
+// FindInjections returns the regions of the lines in the embedded
+// languages, in order, given the comment and quote syntax of the file
+// language -- regions overlapping an earlier one are dropped
+func FindInjections(lines [][]rune, syn *BracketSyntax, injs []Injection) []InjectRegion {
+	var irs []InjectRegion
+	var sinjs []Injection
+	for _, inj := range injs {
+		switch {
+		case inj.Lang == "":
+		case inj.Start != "":
+			st, ed := injectRe(inj.Start), injectRe(inj.End)
+			if st == nil || ed == nil || inj.End == "" {
+				continue
+			}
+			for _, reg := range delimRegions(lines, st, ed) {
+				irs = append(irs, InjectRegion{Reg: reg, Lang: inj.Lang})
+			}
+		case inj.Detect != "" || inj.Marker != "":
+			sinjs = append(sinjs, inj)
+		}
+	}
+	if len(sinjs) > 0 {
+		scanStrings(lines, syn, func(reg giv.TextRegion) {
+			txt := ""
+			for _, inj := range sinjs {
+				if inj.Marker != "" {
+					ln := reg.Start.Ln
+					if hasMarker(string(lines[ln][:reg.Start.Ch]), inj.Marker, syn) || (ln > 0 && hasMarker(string(lines[ln-1]), inj.Marker, syn)) {
+						irs = append(irs, InjectRegion{Reg: reg, Lang: inj.Lang})
+						return
+					}
+				}
+				if inj.Detect != "" {
+					if txt == "" {
+						txt = regionText(lines, reg)
+					}
+					if re := injectRe(inj.Detect); re != nil && re.MatchString(txt) {
+						irs = append(irs, InjectRegion{Reg: reg, Lang: inj.Lang})
+						return
+					}
+				}
+			}
+		})
+	}
+	sort.SliceStable(irs, func(i, j int) bool {
+		return irs[i].Reg.Start.IsLess(irs[j].Reg.Start)
+	})
+	var nirs []InjectRegion
+	for _, ir := range irs {
+		if n := len(nirs); n > 0 && ir.Reg.Start.IsLess(nirs[n-1].Reg.End) {
+			continue
+		}
+		nirs = append(nirs, ir)
+	}
+	return nirs
+}
+
+// byteOff returns the byte offset of the rune at ch in the line
+func byteOff(lt []rune, ch int) int {
+	if ch > len(lt) {
+		ch = len(lt)
+	}
+	return len(string(lt[:ch]))
+}
+
+// InjectHighlight returns the highlighting of the regions in the embedded
+// languages, from the highlighter of each language -- positions are byte
+// offsets in the lines, as in the highlighting of the file language
+func InjectHighlight(lines [][]rune, irs []InjectRegion) InjectLines {
+	ils := InjectLines{}
+	line := func(ln int) *InjectLine {
+		il, has := ils[ln]
+		if !has {
+			il = &InjectLine{}
+			ils[ln] = il
+		}
+		return il
+	}
+	for _, ir := range irs {
+		lx := injectLexer(ir.Lang)
+		if lx == nil {
+			continue
+		}
+		for ln := ir.Reg.Start.Ln; ln <= ir.Reg.End.Ln && ln < len(lines); ln++ {
+			st, ed := 0, len(lines[ln])
+			if ln == ir.Reg.Start.Ln {
+				st = ir.Reg.Start.Ch
+			}
+			if ln == ir.Reg.End.Ln {
+				ed = ir.Reg.End.Ch
+			}
+			if st < ed {
+				il := line(ln)
+				il.Spans = append(il.Spans, [2]int{byteOff(lines[ln], st), byteOff(lines[ln], ed)})
+			}
+		}
+		it, err := lx.Tokenise(nil, regionText(lines, ir.Reg))
+		if err != nil {
+			continue
+		}
+		ln := ir.Reg.Start.Ln
+		cp := byteOff(lines[ln], ir.Reg.Start.Ch)
+		for _, tok := range it.Tokens() {
+			for i, part := range strings.Split(tok.Value, "\n") {
+				if i > 0 {
+					ln++
+					cp = 0
+				}
+				if part == "" || ln > ir.Reg.End.Ln {
+					continue
+				}
+				ep := cp + len(part)
+				if tok.Type != chroma.None && tok.Type < chroma.Text {
+					line(ln).Tags.AddLex(token.KeyToken{Tok: histyle.TokenFromChroma(tok.Type)}, cp, ep)
+				}
+				cp = ep
+			}
+		}
+	}
+	return ils
+}
+
+// ClipTags returns the parts of the tags outside of the spans, which are
+// in order and do not overlap
+func ClipTags(tags lex.Line, spans [][2]int) lex.Line {
+	var ct lex.Line
+	for _, tg := range tags {
+		st := tg.St
+		for _, sp := range spans {
+			if sp[1] <= st || sp[0] >= tg.Ed {
+				continue
+			}
+			if st < sp[0] {
+				ct.AddLex(tg.Tok, st, sp[0])
+			}
+			st = sp[1]
+		}
+		if st < tg.Ed {
+			ct.AddLex(tg.Tok, st, tg.Ed)
+		}
+	}
+	return ct
+}
+
+// BufInjections returns the languages embedded in the buffer, from the Lang
+// Opts of its language
+func BufInjections(tb *giv.TextBuf) []Injection {
+	if lo, has := AvailLangs[tb.Info.Sup]; has {
+		return lo.Inject
+	}
+	return nil
+}
+
+// InjectMarkup re-does the markup of the lines of the buffer in embedded
+// languages (see Injection) with their highlighting, where it has changed,
+// or was re-done without it, e.g., by an edit, and that of the lines no
+// longer in them, and then lays those lines out again -- returns them
+func (tv *TextView) InjectMarkup() []int {
+	tb := tv.Buf
+	if tb == nil || tv.NLines == 0 || !tb.Hi.HasHi() {
+		return nil
+	}
+	nln := tb.NumLines()
+	var ils InjectLines
+	if injs := BufInjections(tb); len(injs) > 0 && nln <= InjectMaxLines {
+		syn := BufBracketSyntax(tb)
+		tb.LinesMu.RLock()
+		ils = InjectHighlight(tb.Lines, FindInjections(tb.Lines, &syn, injs))
+		tb.LinesMu.RUnlock()
+	}
+	if len(ils) == 0 && len(tv.Injects) == 0 {
+		return nil
+	}
+	var chg []int
+	tb.MarkupMu.Lock()
+	if len(tb.Markup) < nln || len(tb.HiTags) < nln || len(tb.LineBytes) < nln {
+		tb.MarkupMu.Unlock()
+		return nil
+	}
+	for ln := range tv.Injects {
+		if _, has := ils[ln]; !has && ln < nln {
+			tb.MarkupLines(ln, ln)
+			chg = append(chg, ln)
+		}
+	}
+	for ln, il := range ils {
+		hitags := lex.MergeLines(ClipTags(tb.HiTags[ln], il.Spans), il.Tags)
+		mu := tb.Hi.MarkupLine(tb.LineBytes[ln], hitags, tb.AdjustedTags(ln))
+		if !bytes.Equal(mu, tb.Markup[ln]) {
+			tb.Markup[ln] = mu
+			chg = append(chg, ln)
+		}
+	}
+	tb.MarkupMu.Unlock()
+	tv.Injects = ils
+	sort.Ints(chg)
+	if len(tv.Renders) >= tv.NLines && len(tv.Offs) >= tv.NLines {
+		for _, ln := range chg {
+			if ln < tv.NLines {
+				tv.LayoutLines(ln, ln, false)
+			}
+		}
+	}
+	return chg
+}
+
+// UpdateInjections does InjectMarkup and re-renders the lines it changed
+func (tv *TextView) UpdateInjections() {
+	chg := tv.InjectMarkup()
+	if len(chg) == 0 || tv.Viewport == nil || tv.Viewport.Win == nil {
+		return
+	}
+	updt := tv.Viewport.Win.UpdateStart()
+	tv.RenderLines(chg[0], chg[len(chg)-1])
+	tv.Viewport.Win.UpdateEnd(updt)
+}
+
+// InjectFocusEvent connects to mouse focus events at the lowest priority,
+// to highlight the embedded languages again after the base view has been
+// refreshed on them, e.g., with the full markup of a newly opened file
+func (tv *TextView) InjectFocusEvent() {
+	tv.ConnectEvent(oswin.MouseFocusEvent, gi.LowRawPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		tvv := recv.Embed(KiT_TextView).(*TextView)
+		tvv.UpdateInjections()
+	})
+}
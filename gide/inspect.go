@@ -0,0 +1,67 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ki"
+)
+
+// InspectLine returns the line for the node in the Inspector tree, indented
+// by its level: its type and name, its allocated position and size if it is
+// a widget, and its number of props
+func InspectLine(k ki.Ki, level int) string {
+	ln := fmt.Sprintf("%v%v %v", strings.Repeat("  ", level), k.Type().Name(), k.Name())
+	if wb, ok := k.Embed(gi.KiT_WidgetBase).(*gi.WidgetBase); ok {
+		ld := &wb.LayData
+		ln += fmt.Sprintf("  pos: %v,%v size: %v,%v", ld.AllocPos.X, ld.AllocPos.Y, ld.AllocSize.X, ld.AllocSize.Y)
+	}
+	if np := len(*k.Properties()); np > 0 {
+		ln += fmt.Sprintf("  props: %v", np)
+	}
+	return ln
+}
+
+// InspectTree returns the lines of the tree of nodes under root, one per
+// node (see InspectLine), and the unique paths of the nodes -- the subtree
+// of skip, e.g., the Inspector itself, is skipped
+func InspectTree(root, skip ki.Ki) (lns, paths []string) {
+	root.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if skip != nil && k == skip.This() {
+			return false
+		}
+		lns = append(lns, InspectLine(k, level))
+		paths = append(paths, k.PathUnique())
+		return true
+	})
+	return
+}
+
+// InspectProps returns the lines describing the node: its path and type,
+// its layout if it is a widget, and its props, sorted by name
+func InspectProps(k ki.Ki) []string {
+	lns := []string{"Path: " + k.PathUnique(), "Type: " + k.Type().String()}
+	if wb, ok := k.Embed(gi.KiT_WidgetBase).(*gi.WidgetBase); ok {
+		ld := &wb.LayData
+		lns = append(lns, fmt.Sprintf("Alloc Pos: %v  Size: %v  Pos Rel: %v", ld.AllocPos, ld.AllocSize, ld.AllocPosRel))
+		lns = append(lns, fmt.Sprintf("Size Need: %v  Pref: %v  Max: %v", ld.Size.Need, ld.Size.Pref, ld.Size.Max))
+		lns = append(lns, fmt.Sprintf("Viewport BBox: %v", wb.VpBBox))
+	}
+	pr := *k.Properties()
+	lns = append(lns, "", fmt.Sprintf("Props: %v", len(pr)))
+	keys := make([]string, 0, len(pr))
+	for key := range pr {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lns = append(lns, fmt.Sprintf("  %v: %v", key, pr[key]))
+	}
+	return lns
+}
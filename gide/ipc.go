@@ -0,0 +1,157 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// IPCRequest is a request from an external tool (e.g., gide open file:line)
+// to the running gide, sent as one line of JSON over the IPC connection
+type IPCRequest struct {
+	Cmd   string `desc:"the command -- open to open the file at Path, or scratch to open Text in a new scratch buffer, in the project containing the directory at Path"`
+	Path  string `desc:"full path of the file, or of the directory for scratch"`
+	Ln    int    `desc:"line to go to (1-based) -- 0 for none"`
+	Col   int    `desc:"column to go to (1-based) -- 0 for none"`
+	Text  string `desc:"text of the scratch buffer, for scratch"`
+	Token string `desc:"token from the address file, on platforms without unix sockets"`
+}
+
+// IPCMaxRequest is the maximum size in bytes of one IPC request, e.g., with
+// text piped to gide -
+var IPCMaxRequest = 64 << 20
+
+// IPCResponse is the response of the running gide to an IPCRequest
+type IPCResponse struct {
+	Err string `desc:"error message -- empty if ok"`
+}
+
+// ErrNoIPCServer is returned by SendIPC when there is no running gide
+var ErrNoIPCServer = errors.New("gide is not running")
+
+// IPCServer listens for requests from external tools, on a unix socket in
+// the prefs dir (on windows, a loopback tcp port given in an address file
+// in the prefs dir), so they can control an already-running gide
+type IPCServer struct {
+	Listener net.Listener                `desc:"the listener"`
+	Handler  func(req *IPCRequest) error `desc:"handles each request"`
+	Token    string                      `desc:"token that requests must have, if any"`
+	Mu       sync.Mutex                  `desc:"mutex serializing calls to Handler"`
+}
+
+// StartIPCServer starts serving IPC requests with given handler, which is
+// called for each request, one at a time -- returns an error if another
+// gide is already serving
+func StartIPCServer(handler func(req *IPCRequest) error) (*IPCServer, error) {
+	ln, token, err := ipcListen()
+	if err != nil {
+		return nil, err
+	}
+	sv := &IPCServer{Listener: ln, Handler: handler, Token: token}
+	go sv.Serve()
+	return sv, nil
+}
+
+// Serve accepts connections until the listener is closed
+func (sv *IPCServer) Serve() {
+	for {
+		conn, err := sv.Listener.Accept()
+		if err != nil {
+			return
+		}
+		go sv.ServeConn(conn)
+	}
+}
+
+// ServeConn handles the requests on given connection, one per line,
+// writing a response line for each
+func (sv *IPCServer) ServeConn(conn net.Conn) {
+	defer conn.Close()
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(nil, IPCMaxRequest)
+	enc := json.NewEncoder(conn)
+	for sc.Scan() {
+		var req IPCRequest
+		var resp IPCResponse
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			resp.Err = err.Error()
+		} else if req.Token != sv.Token {
+			resp.Err = "invalid token"
+		} else {
+			sv.Mu.Lock()
+			err = sv.Handler(&req)
+			sv.Mu.Unlock()
+			if err != nil {
+				resp.Err = err.Error()
+			}
+		}
+		if enc.Encode(&resp) != nil || resp.Err == "invalid token" {
+			return
+		}
+	}
+}
+
+// Close stops the server
+func (sv *IPCServer) Close() error {
+	ipcCleanup()
+	return sv.Listener.Close()
+}
+
+// SendIPC sends the given requests to the running gide, returning the first
+// error -- ErrNoIPCServer if gide is not running
+func SendIPC(reqs ...*IPCRequest) error {
+	conn, token, err := ipcDial()
+	if err != nil {
+		return ErrNoIPCServer
+	}
+	defer conn.Close()
+	enc := json.NewEncoder(conn)
+	dec := json.NewDecoder(conn)
+	for _, req := range reqs {
+		req.Token = token
+		if err := enc.Encode(req); err != nil {
+			return err
+		}
+		var resp IPCResponse
+		if err := dec.Decode(&resp); err != nil {
+			return err
+		}
+		if resp.Err != "" {
+			return fmt.Errorf("%v: %v", req.Path, resp.Err)
+		}
+	}
+	return nil
+}
+
+// ParseFilePos parses a file position as used in compiler and grep output,
+// file:line:col or file:line, returning the file and line and column
+// (1-based, 0 if not given) -- the file is made absolute
+func ParseFilePos(arg string) (fpath string, ln, col int) {
+	fpath = strings.TrimSuffix(arg, ":")
+	for i := 0; i < 2; i++ {
+		ci := strings.LastIndex(fpath, ":")
+		if ci < 0 {
+			break
+		}
+		n, err := strconv.Atoi(fpath[ci+1:])
+		if err != nil || n < 0 {
+			break
+		}
+		col, ln = ln, n
+		fpath = fpath[:ci]
+	}
+	if afp, err := filepath.Abs(fpath); err == nil {
+		fpath = afp
+	}
+	return
+}
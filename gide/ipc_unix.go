@@ -0,0 +1,62 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package gide
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// IPCSocketPath returns the path of the unix socket that gide listens on
+// for requests from external tools -- it is in its own directory, which
+// only the user can access (see ipcListen)
+func IPCSocketPath() string {
+	return filepath.Join(AppPrefsDir(), "ipc", "gide.sock")
+}
+
+// ipcListen listens on the IPC socket, removing it first if it is stale --
+// the socket is created in a directory with mode 0700, so that it can not
+// be reached by other users, whatever the umask
+func ipcListen() (net.Listener, string, error) {
+	sp := IPCSocketPath()
+	if conn, err := net.Dial("unix", sp); err == nil {
+		conn.Close()
+		return nil, "", errors.New("another gide is already listening on: " + sp)
+	}
+	dir := filepath.Dir(sp)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, "", err
+	}
+	if err := os.Chmod(dir, 0700); err != nil { // in case it already existed
+		return nil, "", err
+	}
+	if err := os.Remove(sp); err != nil && !os.IsNotExist(err) {
+		return nil, "", err
+	}
+	ln, err := net.Listen("unix", sp)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := os.Chmod(sp, 0600); err != nil {
+		ln.Close()
+		return nil, "", err
+	}
+	return ln, "", nil
+}
+
+// ipcDial connects to the IPC socket
+func ipcDial() (net.Conn, string, error) {
+	conn, err := net.Dial("unix", IPCSocketPath())
+	return conn, "", err
+}
+
+// ipcCleanup does nothing -- the socket is removed when the listener is
+// closed
+func ipcCleanup() {
+}
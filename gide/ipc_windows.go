@@ -0,0 +1,67 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IPCAddrPath returns the path of the file with the loopback address (and
+// token) that gide listens on for requests from external tools -- the file
+// is only readable by the user
+func IPCAddrPath() string {
+	return filepath.Join(AppPrefsDir(), "gide.addr")
+}
+
+// ipcListen listens on a free loopback port, writing its address and a
+// random token to the address file
+func ipcListen() (net.Listener, string, error) {
+	if conn, _, err := ipcDial(); err == nil {
+		conn.Close()
+		return nil, "", errors.New("another gide is already listening, per: " + IPCAddrPath())
+	}
+	tb := make([]byte, 16)
+	if _, err := rand.Read(tb); err != nil {
+		return nil, "", err
+	}
+	token := hex.EncodeToString(tb)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, "", err
+	}
+	ap := IPCAddrPath()
+	os.MkdirAll(filepath.Dir(ap), 0755)
+	if err := ioutil.WriteFile(ap, []byte(ln.Addr().String()+" "+token), 0600); err != nil {
+		ln.Close()
+		return nil, "", err
+	}
+	return ln, token, nil
+}
+
+// ipcDial connects to the address in the address file
+func ipcDial() (net.Conn, string, error) {
+	b, err := ioutil.ReadFile(IPCAddrPath())
+	if err != nil {
+		return nil, "", err
+	}
+	flds := strings.Fields(string(b))
+	if len(flds) != 2 {
+		return nil, "", errors.New("invalid address file: " + IPCAddrPath())
+	}
+	conn, err := net.Dial("tcp", flds[0])
+	return conn, flds[1], err
+}
+
+// ipcCleanup removes the address file
+func ipcCleanup() {
+	os.Remove(IPCAddrPath())
+}
@@ -0,0 +1,353 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// JobCommand is the kind of the jobs of running commands -- their names are
+// the command names, which are also the names of their output tabs
+const JobCommand = "Command"
+
+// JobQueued is the kind of the jobs of command runs waiting for other runs of
+// the command or its group to finish, or for fewer than Prefs.MaxCmds
+// commands to be running -- they become JobCommand jobs when they start
+const JobQueued = "Queued"
+
+// Job is something a project is doing in the background, e.g., a running
+// command, a release build or a file server, registered in its Jobs so it
+// is listed in the Jobs tab, with its progress, and can be canceled
+type Job struct {
+	ID       int       `desc:"unique id of the job in this session"`
+	Kind     string    `desc:"kind of job, e.g., Command, Release, Serve"`
+	Name     string    `desc:"name of the job, e.g., the command name"`
+	Desc     string    `desc:"description of the job, e.g., the command line"`
+	Start    time.Time `desc:"time the job was started"`
+	Done     int       `desc:"number of steps done, for the progress"`
+	Total    int       `desc:"total number of steps -- 0 if the progress is not known"`
+	Group    string    `desc:"group of the command of a command job -- at most one command of a group runs at a time"`
+	Cancel   func()    `json:"-" xml:"-" view:"-" desc:"function that cancels the job -- nil if it cannot be canceled"`
+	jobs     *Jobs
+	cmds     []*exec.Cmd
+	start    func(jb *Job)
+	canceled bool
+}
+
+// Elapsed returns the time since the job was started
+func (jb *Job) Elapsed() time.Duration {
+	return time.Since(jb.Start)
+}
+
+// Progress returns the progress of the job, as done / total steps -- "" if
+// not known
+func (jb *Job) Progress() string {
+	jb.jobs.Mu.Lock()
+	defer jb.jobs.Mu.Unlock()
+	if jb.Total <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%v / %v", jb.Done, jb.Total)
+}
+
+// Description returns the description of the job, which a command job
+// updates for each step (see SetCmds)
+func (jb *Job) Description() string {
+	jb.jobs.Mu.Lock()
+	defer jb.jobs.Mu.Unlock()
+	return jb.Desc
+}
+
+// SetProgress sets the number of steps done, and the total, if known
+func (jb *Job) SetProgress(done, total int) {
+	jb.jobs.Mu.Lock()
+	jb.Done, jb.Total = done, total
+	jb.jobs.Mu.Unlock()
+	jb.jobs.changed()
+}
+
+// Finish removes the finished job from its Jobs -- it can be called more
+// than once
+func (jb *Job) Finish() {
+	jb.jobs.Remove(jb)
+}
+
+// SetCmds sets the description of a command job, and the processes of its
+// current step, which are killed if it is canceled
+func (jb *Job) SetCmds(desc string, cmds ...*exec.Cmd) {
+	jb.jobs.Mu.Lock()
+	jb.Desc = desc
+	jb.cmds = cmds
+	jb.jobs.Mu.Unlock()
+	jb.jobs.changed()
+}
+
+// Canceled returns true if the command job has been canceled -- the steps
+// after the current one are not run
+func (jb *Job) Canceled() bool {
+	jb.jobs.Mu.Lock()
+	defer jb.jobs.Mu.Unlock()
+	return jb.canceled
+}
+
+// killCmds cancels a command job, killing the processes of its current step
+func (jb *Job) killCmds() {
+	jb.jobs.Mu.Lock()
+	jb.canceled = true
+	cmds := jb.cmds
+	jb.jobs.Mu.Unlock()
+	for _, cmd := range cmds {
+		if cmd.Process != nil {
+			cmd.Process.Kill()
+		}
+	}
+}
+
+// Jobs is the registry of the jobs running in the background in a project:
+// each subsystem adds its jobs with Add, and Finishes them when done, and
+// the functions added with Watch are called whenever a job is added,
+// finished or makes progress, e.g., to update the Jobs tab
+type Jobs struct {
+	Jobs     []*Job     `desc:"running jobs, in the order started"`
+	LastID   int        `desc:"id of the last job added"`
+	Watchers []func()   `json:"-" xml:"-" view:"-" desc:"functions called when the jobs change"`
+	Mu       sync.Mutex `json:"-" xml:"-" view:"-" desc:"mutex for the jobs, which are added and finished from goroutines"`
+}
+
+// Add adds a new job of given kind, name and description, started now, with
+// given function to cancel it (nil if it cannot be canceled) -- returns the
+// job, to update its progress and Finish it
+func (js *Jobs) Add(kind, name, desc string, cancel func()) *Job {
+	js.Mu.Lock()
+	jb := js.add(&Job{Kind: kind, Name: name, Desc: desc, Cancel: cancel})
+	js.Mu.Unlock()
+	js.changed()
+	return jb
+}
+
+// add adds the job, started now -- must be called under the mutex
+func (js *Jobs) add(jb *Job) *Job {
+	js.LastID++
+	jb.ID = js.LastID
+	jb.Start = time.Now()
+	jb.jobs = js
+	js.Jobs = append(js.Jobs, jb)
+	return jb
+}
+
+// StartCmd adds a job for a run of the command of given name and group, if
+// it can run now, which is canceled by killing the processes of its current
+// step, set with SetCmds -- otherwise it returns nil, and the job that keeps
+// it from running: another run of the command or of its group, running or
+// queued before it, or nil if Prefs.MaxCmds commands are running
+func (js *Jobs) StartCmd(name, group, desc string) (*Job, *Job) {
+	js.Mu.Lock()
+	if blk := js.cmdBlocker(name, group, nil); blk != nil {
+		js.Mu.Unlock()
+		return nil, blk
+	}
+	if js.cmdsFull() {
+		js.Mu.Unlock()
+		return nil, nil
+	}
+	jb := &Job{Kind: JobCommand, Name: name, Group: group, Desc: desc}
+	jb.Cancel = jb.killCmds
+	js.add(jb)
+	js.Mu.Unlock()
+	js.changed()
+	return jb, nil
+}
+
+// QueueCmd adds a queued job for a run of the command of given name and
+// group, which is started by calling start, once it can run, with the job,
+// which is then a JobCommand job -- canceling it removes it from the queue
+func (js *Jobs) QueueCmd(name, group, desc string, start func(jb *Job)) *Job {
+	js.Mu.Lock()
+	jb := &Job{Kind: JobQueued, Name: name, Group: group, Desc: desc, start: start}
+	jb.Cancel = jb.killCmds
+	js.add(jb)
+	js.Mu.Unlock()
+	js.StartQueued() // in case what it waits for has just finished
+	return jb
+}
+
+// cmdBlocker returns the command job that keeps a run of the command of
+// given name and group from running: a running one of the command or its
+// group, or a queued one before qjb (any if nil) -- must be called under
+// the mutex
+func (js *Jobs) cmdBlocker(name, group string, qjb *Job) *Job {
+	before := true
+	for _, jb := range js.Jobs {
+		if jb == qjb {
+			before = false
+			continue
+		}
+		if jb.Kind != JobCommand && !(before && jb.Kind == JobQueued) {
+			continue
+		}
+		if jb.Name == name || (group != "" && jb.Group == group) {
+			return jb
+		}
+	}
+	return nil
+}
+
+// cmdsFull returns true if Prefs.MaxCmds commands are running -- must be
+// called under the mutex
+func (js *Jobs) cmdsFull() bool {
+	if Prefs.MaxCmds <= 0 {
+		return false
+	}
+	n := 0
+	for _, jb := range js.Jobs {
+		if jb.Kind == JobCommand {
+			n++
+		}
+	}
+	return n >= Prefs.MaxCmds
+}
+
+// StartQueued starts the queued command runs that can now run, in the order
+// they were queued -- it is called whenever a command job is removed
+func (js *Jobs) StartQueued() {
+	var sts []*Job
+	js.Mu.Lock()
+	for _, jb := range js.Jobs {
+		if jb.Kind != JobQueued || js.cmdBlocker(jb.Name, jb.Group, jb) != nil {
+			continue
+		}
+		if js.cmdsFull() {
+			break
+		}
+		jb.Kind = JobCommand
+		jb.Start = time.Now()
+		sts = append(sts, jb)
+	}
+	js.Mu.Unlock()
+	if len(sts) == 0 {
+		return
+	}
+	js.changed()
+	for _, jb := range sts {
+		go jb.start(jb)
+	}
+}
+
+// NQueued returns the number of queued command runs
+func (js *Jobs) NQueued() int {
+	js.Mu.Lock()
+	defer js.Mu.Unlock()
+	n := 0
+	for _, jb := range js.Jobs {
+		if jb.Kind == JobQueued {
+			n++
+		}
+	}
+	return n
+}
+
+// Remove removes the job, returning false if it had already been removed
+func (js *Jobs) Remove(jb *Job) bool {
+	js.Mu.Lock()
+	idx := -1
+	for i, j := range js.Jobs {
+		if j == jb {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		js.Mu.Unlock()
+		return false
+	}
+	js.Jobs = append(js.Jobs[:idx], js.Jobs[idx+1:]...)
+	js.Mu.Unlock()
+	js.changed()
+	if jb.Kind == JobCommand {
+		js.StartQueued()
+	}
+	return true
+}
+
+// Running returns a copy of the list of running jobs
+func (js *Jobs) Running() []*Job {
+	js.Mu.Lock()
+	defer js.Mu.Unlock()
+	return append([]*Job(nil), js.Jobs...)
+}
+
+// ByID returns the running job with given id, or nil if not found
+func (js *Jobs) ByID(id int) *Job {
+	for _, jb := range js.Running() {
+		if jb.ID == id {
+			return jb
+		}
+	}
+	return nil
+}
+
+// ByName returns the first running job of given kind and name, or nil if
+// not found
+func (js *Jobs) ByName(kind, name string) *Job {
+	for _, jb := range js.Running() {
+		if jb.Kind == kind && jb.Name == name {
+			return jb
+		}
+	}
+	return nil
+}
+
+// CancelJob cancels the job and removes it -- returns false if it cannot be
+// canceled
+func (js *Jobs) CancelJob(jb *Job) bool {
+	if jb.Cancel == nil {
+		return false
+	}
+	jb.Cancel()
+	js.Remove(jb)
+	return true
+}
+
+// CancelByName cancels the first running job of given kind and name, e.g.,
+// a command before it is run again -- returns false if none
+func (js *Jobs) CancelByName(kind, name string) bool {
+	jb := js.ByName(kind, name)
+	if jb == nil {
+		return false
+	}
+	return js.CancelJob(jb)
+}
+
+// FinishByName finishes the first running job of given kind and name --
+// returns false if none
+func (js *Jobs) FinishByName(kind, name string) bool {
+	jb := js.ByName(kind, name)
+	if jb == nil {
+		return false
+	}
+	return js.Remove(jb)
+}
+
+// Watch adds a function to be called whenever the jobs change -- it may be
+// called from the goroutine of a job
+func (js *Jobs) Watch(fun func()) {
+	js.Mu.Lock()
+	js.Watchers = append(js.Watchers, fun)
+	js.Mu.Unlock()
+}
+
+// changed calls the Watchers
+func (js *Jobs) changed() {
+	js.Mu.Lock()
+	ws := make([]func(), len(js.Watchers))
+	copy(ws, js.Watchers)
+	js.Mu.Unlock()
+	for _, fun := range ws {
+		fun()
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/pi/filecat"
+)
+
+// JournalBuf is the snapshot of one unsaved buffer in a Journal
+type JournalBuf struct {
+	Filename gi.FileName       `desc:"file name of the buffer -- for scratch buffers, this is the scratch path"`
+	Sup      filecat.Supported `desc:"language of the buffer -- used for scratch buffers"`
+	Text     string            `desc:"unsaved contents of the buffer"`
+	Cursor   giv.TextPos       `desc:"cursor position in the buffer"`
+}
+
+// Journal is a crash-recovery snapshot of the unsaved buffers and session
+// state of a project, which is periodically saved while there are unsaved
+// changes, and deleted when the project is closed normally
+type Journal struct {
+	ProjRoot gi.FileName   `desc:"root directory of the project"`
+	Time     time.Time     `desc:"time of the snapshot"`
+	Splits   []float32     `desc:"splitter splits"`
+	Views    []gi.FileName `desc:"file viewed in each of the text views"`
+	Active   int           `desc:"index of the active text view"`
+	Bufs     []JournalBuf  `desc:"snapshots of the unsaved buffers"`
+}
+
+// JournalFileName returns the name of the journal file for the project at
+// given root, within the app prefs directory
+func JournalFileName(root gi.FileName) string {
+	h := fnv.New32a()
+	h.Write([]byte(root))
+	pdir := oswin.TheApp.AppPrefsDir()
+	return filepath.Join(pdir, "journal", fmt.Sprintf("%s-%08x.json", filepath.Base(string(root)), h.Sum32()))
+}
+
+// Save saves the journal for its project, replacing the prior one -- written
+// to a temp file first so a crash while saving cannot corrupt the journal
+func (jn *Journal) Save() error {
+	fnm := JournalFileName(jn.ProjRoot)
+	if err := os.MkdirAll(filepath.Dir(fnm), 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(jn)
+	if err != nil {
+		return err
+	}
+	tfn := fnm + ".tmp"
+	if err := ioutil.WriteFile(tfn, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tfn, fnm)
+}
+
+// OpenJournal opens the journal for the project at given root, returning nil
+// if there is none
+func OpenJournal(root gi.FileName) *Journal {
+	b, err := ioutil.ReadFile(JournalFileName(root))
+	if err != nil {
+		return nil
+	}
+	jn := &Journal{}
+	if err := json.Unmarshal(b, jn); err != nil {
+		return nil
+	}
+	return jn
+}
+
+// DeleteJournal deletes the journal for the project at given root
+func DeleteJournal(root gi.FileName) {
+	os.Remove(JournalFileName(root))
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"image"
+	"image/draw"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/key"
+)
+
+// JumpLabelChars are the characters the labels of Jump to Visible are made
+// of, those easiest to type first -- the targets nearest the cursor get the
+// labels made of the first ones
+var JumpLabelChars = "asdfghjklqwertyuiopzxcvbnm"
+
+// JumpMax is the maximum number of targets labeled by Jump to Visible --
+// those farthest from the cursor are dropped
+var JumpMax = 676
+
+// JumpLabelColor is the color of the text of the labels of Jump to Visible
+var JumpLabelColor = gi.Color{0xff, 0xff, 0xff, 0xff}
+
+// JumpLabelBg is the background color of the labels of Jump to Visible
+var JumpLabelBg = gi.Color{0xd8, 0x43, 0x15, 0xff}
+
+// JumpTarget is a position labeled by Jump to Visible
+type JumpTarget struct {
+	Label string      `desc:"label to type to jump to the position"`
+	Pos   giv.TextPos `desc:"position in the buffer"`
+}
+
+// JumpState is the state of Jump to Visible in a view, while its labels are
+// shown
+type JumpState struct {
+	Active  bool         `desc:"labels are shown, and typing goes to them"`
+	Targets []JumpTarget `desc:"targets whose labels start with the Typed chars"`
+	Typed   string       `desc:"chars of a label typed so far"`
+}
+
+// JumpLabels returns n distinct labels made of the JumpLabelChars, all of
+// the same length, as short as possible, so none is the prefix of another
+func JumpLabels(n int) []string {
+	chars := []rune(JumpLabelChars)
+	nc := len(chars)
+	if n <= 0 || nc == 0 {
+		return nil
+	}
+	ln := 1
+	for tot := nc; tot < n; tot *= nc {
+		ln++
+	}
+	lbs := make([]string, n)
+	for i := range lbs {
+		lb := make([]rune, ln)
+		for j, k := ln-1, i; j >= 0; j-- {
+			lb[j] = chars[k%nc]
+			k /= nc
+		}
+		lbs[i] = string(lb)
+	}
+	return lbs
+}
+
+// JumpPositions returns the positions labeled by Jump to Visible in the
+// lines from st to ed (inclusive): the start of each word, and the first
+// non-blank char of the lines without words (or their start if blank),
+// nearest given cursor position first
+func JumpPositions(lines [][]rune, st, ed int, cur giv.TextPos) []giv.TextPos {
+	var pos []giv.TextPos
+	for ln := st; ln <= ed && ln < len(lines); ln++ {
+		lt := lines[ln]
+		nw := 0
+		for ch, r := range lt {
+			if isWordRune(r) && (ch == 0 || !isWordRune(lt[ch-1])) {
+				pos = append(pos, giv.TextPos{Ln: ln, Ch: ch})
+				nw++
+			}
+		}
+		if nw > 0 {
+			continue
+		}
+		ch := 0
+		for ch < len(lt) && unicode.IsSpace(lt[ch]) {
+			ch++
+		}
+		if ch == len(lt) {
+			ch = 0
+		}
+		pos = append(pos, giv.TextPos{Ln: ln, Ch: ch})
+	}
+	dist := func(tp giv.TextPos) (int, int) {
+		dl, dc := tp.Ln-cur.Ln, tp.Ch-cur.Ch
+		if dl < 0 {
+			dl = -dl
+		}
+		if dc < 0 {
+			dc = -dc
+		}
+		return dl, dc
+	}
+	sort.SliceStable(pos, func(i, j int) bool {
+		li, ci := dist(pos[i])
+		lj, cj := dist(pos[j])
+		if li != lj {
+			return li < lj
+		}
+		return ci < cj
+	})
+	return pos
+}
+
+// StartJump starts Jump to Visible: labels are shown over the visible words
+// and line starts, and typing one moves the cursor there (see
+// JumpKeyInput) -- returns false if nothing is visible
+func (tv *TextView) StartJump() bool {
+	if tv.Buf == nil || tv.NLines == 0 || tv.Viewport == nil {
+		return false
+	}
+	st := tv.FirstVisibleLine(0)
+	ed := tv.LastVisibleLine(st)
+	tv.Buf.LinesMu.RLock()
+	pos := JumpPositions(tv.Buf.Lines, st, ed, tv.CursorPos)
+	tv.Buf.LinesMu.RUnlock()
+	if len(pos) > JumpMax {
+		pos = pos[:JumpMax]
+	}
+	if len(pos) == 0 {
+		return false
+	}
+	lbs := JumpLabels(len(pos))
+	jts := make([]JumpTarget, len(pos))
+	for i := range pos {
+		jts[i] = JumpTarget{Label: lbs[i], Pos: pos[i]}
+	}
+	tv.Jump = JumpState{Active: true, Targets: jts}
+	tv.UpdateOverlays()
+	return true
+}
+
+// EndJump ends Jump to Visible, removing the labels
+func (tv *TextView) EndJump() {
+	if !tv.Jump.Active {
+		return
+	}
+	tv.Jump = JumpState{}
+	tv.redrawVisible()
+}
+
+// JumpKeyInput processes a key while Jump to Visible is active, before the
+// view gets it: the chars of a label narrow the labels down, and the cursor
+// moves to the position of the label once it is typed in full -- any other
+// key ends it -- returns false if it is not active
+func (tv *TextView) JumpKeyInput(kt *key.ChordEvent) bool {
+	if !tv.Jump.Active {
+		return false
+	}
+	kt.SetProcessed()
+	kc := string(kt.Chord())
+	if len([]rune(kc)) != 1 || !strings.Contains(JumpLabelChars, kc) {
+		tv.EndJump()
+		return true
+	}
+	typed := tv.Jump.Typed + kc
+	var jts []JumpTarget
+	for _, jt := range tv.Jump.Targets {
+		if strings.HasPrefix(jt.Label, typed) {
+			jts = append(jts, jt)
+		}
+	}
+	switch {
+	case len(jts) == 0:
+		tv.EndJump()
+	case len(jts) == 1 && jts[0].Label == typed:
+		tv.EndJump()
+		tv.SelectReset()
+		tv.SetCursorShow(jts[0].Pos)
+	default:
+		tv.Jump.Typed = typed
+		tv.Jump.Targets = jts
+		tv.redrawVisible()
+	}
+	return true
+}
+
+// redrawVisible re-renders the visible lines, without the overlays drawn
+// on them before, and then draws the current overlays
+func (tv *TextView) redrawVisible() {
+	if tv.NLines == 0 || tv.Viewport == nil || tv.Viewport.Win == nil {
+		return
+	}
+	st := tv.FirstVisibleLine(0)
+	updt := tv.Viewport.Win.UpdateStart()
+	tv.RenderLines(st, tv.LastVisibleLine(st))
+	tv.Viewport.Win.UpdateEnd(updt)
+	tv.UpdateOverlays()
+}
+
+// RenderJump draws the labels of Jump to Visible, less the chars already
+// typed, over the positions of their targets
+func (tv *TextView) RenderJump() {
+	if !tv.Jump.Active || tv.NLines == 0 || tv.Viewport == nil || tv.VpBBox.Empty() {
+		return
+	}
+	if len(tv.Renders) < tv.NLines || len(tv.Offs) < tv.NLines {
+		return
+	}
+	img := tv.Viewport.Pixels
+	tbb := tv.VpBBox
+	tbb.Min.X += int(tv.LineNoOff)
+	chw := tv.Sty.Font.Face.Metrics.Ch
+	sty := &tv.Sty
+	fst := sty.Font
+	fst.BgColor.SetColor(nil)
+	fst.Color = JumpLabelColor
+	met := sty.Font.Face.Face.Metrics()
+	boff := gi.FixedToFloat32(met.Ascent) - gi.FixedToFloat32(met.Descent)
+	nt := len(tv.Jump.Typed)
+	trs := make([]gi.TextRender, 0, len(tv.Jump.Targets))
+	var tps []gi.Vec2D
+	for _, jt := range tv.Jump.Targets {
+		if jt.Pos.Ln >= tv.NLines {
+			continue
+		}
+		rest := jt.Label[nt:]
+		pos := tv.CharStartPos(jt.Pos)
+		box := image.Rect(int(pos.X), int(pos.Y), int(pos.X+chw*float32(len(rest))+0.5), int(pos.Y+tv.LineHeight)).Intersect(tbb)
+		if box.Empty() {
+			continue
+		}
+		draw.Draw(img, box, image.NewUniform(JumpLabelBg), image.ZP, draw.Src)
+		trs = append(trs, gi.TextRender{})
+		trs[len(trs)-1].SetString(rest, &fst, &sty.UnContext, &sty.Text, true, 0, 0)
+		pos.Y += boff
+		tps = append(tps, pos)
+	}
+	rs := &tv.Viewport.Render
+	rs.PushBounds(tbb)
+	rs.Lock()
+	for i := range trs {
+		trs[i].Render(rs, tps[i])
+	}
+	rs.Unlock()
+	rs.PopBounds()
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/units"
+)
+
+// KeyDisplaySpriteName is the name of the window sprite showing the keys
+var KeyDisplaySpriteName = "gide.KeyDisplay"
+
+// KeyDisplayMax is the maximum number of recent keys shown
+var KeyDisplayMax = 5
+
+// KeyDisplayTime is how long each key is shown
+var KeyDisplayTime = 3 * time.Second
+
+// KeyDisplayFontSize is the font size of the keys shown, in points
+var KeyDisplayFontSize = float32(20)
+
+// KeyDisplayBg is the background color of the key display
+var KeyDisplayBg = color.RGBA{0x20, 0x20, 0x20, 0xd8}
+
+// KeyDisplayFg is the text color of the key display
+var KeyDisplayFg = gi.Color{0xf0, 0xf0, 0xf0, 0xff}
+
+// KeyDisplayEntry is one key shown in the key display
+type KeyDisplayEntry struct {
+	Text string    `desc:"the key chord(s) and the function they executed"`
+	N    int       `desc:"number of times in a row the key was pressed"`
+	Time time.Time `desc:"when the key was last pressed"`
+}
+
+// KeyDisplay shows the recently pressed key chords, and the key functions
+// they executed, in an overlay at the bottom of the window -- for
+// screencasts and teaching -- keys are removed after KeyDisplayTime
+type KeyDisplay struct {
+	Win     *gi.Window        `desc:"window the keys are shown in"`
+	Font    gi.FontStyle      `desc:"font for the keys"`
+	Ctxt    units.Context     `desc:"units context for the font"`
+	TextSty gi.TextStyle      `desc:"text style for the keys"`
+	Entries []KeyDisplayEntry `desc:"the keys currently shown, oldest first"`
+	Timer   *time.Timer       `desc:"timer for removing keys"`
+	Mu      sync.Mutex        `desc:"mutex protecting the Entries"`
+}
+
+// NewKeyDisplay returns a new key display for given window, in the font of
+// the given style at KeyDisplayFontSize
+func NewKeyDisplay(win *gi.Window, sty *gi.Style) *KeyDisplay {
+	kd := &KeyDisplay{Win: win, Font: sty.Font, Ctxt: sty.UnContext, TextSty: sty.Text}
+	kd.Font.Size = units.NewValue(KeyDisplayFontSize, units.Pt)
+	kd.Font.OpenFont(&kd.Ctxt)
+	kd.Font.Color = KeyDisplayFg
+	kd.Font.BgColor.SetColor(nil)
+	return kd
+}
+
+// KeyDisplayText returns the text shown for given chord(s) and the name of
+// the key function they executed, if any -- plain typing (without a key
+// function or modifiers other than shift) is not shown, returning ""
+func KeyDisplayText(chord, fun string) string {
+	if fun == "" {
+		c := strings.TrimPrefix(chord, "Shift+")
+		if !strings.Contains(c, "+") {
+			return ""
+		}
+	}
+	if fun == "" || fun == chord {
+		return chord
+	}
+	return chord + "  " + fun
+}
+
+// Add adds the key text to the display, counting repeats of the last key
+func (kd *KeyDisplay) Add(text string) {
+	if text == "" {
+		return
+	}
+	kd.Mu.Lock()
+	defer kd.Mu.Unlock()
+	now := time.Now()
+	if n := len(kd.Entries); n > 0 && kd.Entries[n-1].Text == text {
+		kd.Entries[n-1].N++
+		kd.Entries[n-1].Time = now
+	} else {
+		kd.Entries = append(kd.Entries, KeyDisplayEntry{Text: text, N: 1, Time: now})
+		if len(kd.Entries) > KeyDisplayMax {
+			kd.Entries = kd.Entries[len(kd.Entries)-KeyDisplayMax:]
+		}
+	}
+	if kd.Timer == nil {
+		kd.Timer = time.AfterFunc(KeyDisplayTime, kd.Expire)
+	}
+	kd.Render()
+}
+
+// Expire removes the keys shown for KeyDisplayTime, and schedules the next
+// removal if any remain
+func (kd *KeyDisplay) Expire() {
+	kd.Mu.Lock()
+	defer kd.Mu.Unlock()
+	kd.Timer = nil
+	now := time.Now()
+	n := 0
+	for n < len(kd.Entries) && now.Sub(kd.Entries[n].Time) >= KeyDisplayTime {
+		n++
+	}
+	kd.Entries = kd.Entries[n:]
+	if len(kd.Entries) > 0 {
+		kd.Timer = time.AfterFunc(KeyDisplayTime-now.Sub(kd.Entries[0].Time), kd.Expire)
+	}
+	kd.Render()
+}
+
+// Render renders the keys into the sprite, centered at the bottom of the
+// window, or removes it if there are none -- must be called with Mu locked
+func (kd *KeyDisplay) Render() {
+	win := kd.Win
+	if win == nil || !win.IsVisible() {
+		return
+	}
+	if len(kd.Entries) == 0 {
+		if win.DeleteSprite(KeyDisplaySpriteName) {
+			win.RenderOverlays()
+			win.UpdateSig()
+		}
+		return
+	}
+	trs := make([]gi.TextRender, len(kd.Entries))
+	var sz gi.Vec2D
+	for i, e := range kd.Entries {
+		txt := e.Text
+		if e.N > 1 {
+			txt += fmt.Sprintf("  ×%d", e.N)
+		}
+		trs[i].SetString(txt, &kd.Font, &kd.Ctxt, &kd.TextSty, true, 0, 0)
+		sz.X = gi.Max32(sz.X, trs[i].Size.X)
+		sz.Y += trs[i].Size.Y
+	}
+	pad := kd.Font.Size.Dots / 2
+	spsz := image.Point{int(sz.X + 2*pad + 1), int(sz.Y + 2*pad + 1)}
+	sp, ok := win.SpriteByName(KeyDisplaySpriteName)
+	if !ok {
+		sp = win.AddNewSprite(KeyDisplaySpriteName, spsz, image.ZP)
+	}
+	sp.Resize(spsz)
+	draw.Draw(sp.Pixels, sp.Pixels.Bounds(), image.NewUniform(KeyDisplayBg), image.ZP, draw.Src)
+	rs := &gi.RenderState{}
+	rs.Init(spsz.X, spsz.Y, sp.Pixels)
+	rs.Bounds = sp.Pixels.Bounds()
+	y := pad
+	for i := range trs {
+		trs[i].RenderTopPos(rs, gi.Vec2D{X: pad, Y: y})
+		y += trs[i].Size.Y
+	}
+	wsz := win.Viewport.Geom.Size
+	sp.Geom.Pos = image.Point{(wsz.X - spsz.X) / 2, wsz.Y - spsz.Y - 4*int(pad)}
+	win.ActivateSprite(KeyDisplaySpriteName)
+	win.RenderOverlays()
+	win.UpdateSig()
+}
+
+// Stop removes the display and stops the timer
+func (kd *KeyDisplay) Stop() {
+	kd.Mu.Lock()
+	defer kd.Mu.Unlock()
+	if kd.Timer != nil {
+		kd.Timer.Stop()
+		kd.Timer = nil
+	}
+	kd.Entries = nil
+	kd.Render()
+}
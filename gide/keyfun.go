@@ -32,25 +32,42 @@ import (
 type KeyFuns int32
 
 const (
-	KeyFunNil        KeyFuns = iota
-	KeyFunNeeds2             // special internal signal returned by KeyFun indicating need for second key
-	KeyFunNextPanel          // move to next panel to the right
-	KeyFunPrevPanel          // move to prev panel to the left
-	KeyFunFileOpen           // open a new file in active textview
-	KeyFunBufSelect          // select an open buffer to edit in active textview
-	KeyFunBufClone           // open active file in other view
-	KeyFunBufSave            // save active textview buffer to its file
-	KeyFunBufSaveAs          // save as active textview buffer to its file
-	KeyFunBufClose           // close active textview buffer
-	KeyFunExecCmd            // execute a command on active textview buffer
-	KeyFunRegCopy            // copy selection to named register
-	KeyFunRegPaste           // paste selection from named register
-	KeyFunCommentOut         // comment out region
-	KeyFunIndent             // indent region
-	KeyFunJump               // jump to line (same as gi.KeyFunJump)
-	KeyFunSetSplit           // set named splitter config
-	KeyFunBuildProj          // build overall project
-	KeyFunRunProj            // run overall project
+	KeyFunNil          KeyFuns = iota
+	KeyFunNeeds2               // special internal signal returned by KeyFun indicating need for second key
+	KeyFunNextPanel            // move to next panel to the right
+	KeyFunPrevPanel            // move to prev panel to the left
+	KeyFunFileOpen             // open a new file in active textview
+	KeyFunBufSelect            // select an open buffer to edit in active textview
+	KeyFunBufClone             // open active file in other view
+	KeyFunBufSave              // save active textview buffer to its file
+	KeyFunBufSaveAs            // save as active textview buffer to its file
+	KeyFunBufClose             // close active textview buffer
+	KeyFunExecCmd              // execute a command on active textview buffer
+	KeyFunRegCopy              // copy selection to named register
+	KeyFunRegPaste             // paste selection from named register
+	KeyFunCommentOut           // comment out region
+	KeyFunIndent               // indent region
+	KeyFunJump                 // go to a line, line:col or file:line:col (same as gi.KeyFunJump)
+	KeyFunSetSplit             // set named splitter config
+	KeyFunBuildProj            // build overall project
+	KeyFunRunProj              // run overall project
+	KeyFunSigHelp              // show signature help for function call at cursor
+	KeyFunDocs                 // show documentation for symbol at cursor
+	KeyFunSentenceNext         // move to next sentence (prose)
+	KeyFunSentencePrev         // move to previous sentence (prose)
+	KeyFunBufReopen            // reopen the most recently closed file
+	KeyFunZenMode              // toggle distraction-free (zen) editing mode
+	KeyFunJumpBracket          // jump to the matching bracket, or the start of the enclosing pair
+	KeyFunExpandSel            // expand the selection to the next larger syntactic unit
+	KeyFunShrinkSel            // shrink the selection back to what it was before the last expand
+	KeyFunDupLine              // duplicate the current line or the selected lines
+	KeyFunMoveLineUp           // move the current line or the selected lines up one line
+	KeyFunMoveLineDown         // move the current line or the selected lines down one line
+	KeyFunDeleteLine           // delete the current line or the selected lines
+	KeyFunNextOccur            // move to the next occurrence of the identifier at the cursor
+	KeyFunPrevOccur            // move to the previous occurrence of the identifier at the cursor
+	KeyFunJumpVisible          // jump to a word or line start visible in the active view, by typing its label
+	KeyFunJumpTag              // jump to the matching tag in HTML / XML, or the start tag of the enclosing element
 	KeyFunsN
 )
 
@@ -528,6 +545,32 @@ var StdKeyMaps = KeyMaps{
 		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
 		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
 		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+M", "h"}:         KeyFunSigHelp,
+		KeySeq{"Control+M", "Control+H"}: KeyFunSigHelp,
+		KeySeq{"Control+M", "d"}:         KeyFunDocs,
+		KeySeq{"Control+M", "Control+D"}: KeyFunDocs,
+		KeySeq{"Control+M", "e"}:         KeyFunSentenceNext,
+		KeySeq{"Control+M", "Control+E"}: KeyFunSentenceNext,
+		KeySeq{"Control+M", "a"}:         KeyFunSentencePrev,
+		KeySeq{"Control+M", "Control+A"}: KeyFunSentencePrev,
+		KeySeq{"Control+M", "u"}:         KeyFunBufReopen,
+		KeySeq{"Control+M", "Control+U"}: KeyFunBufReopen,
+		KeySeq{"Control+M", "z"}:         KeyFunZenMode,
+		KeySeq{"Control+M", "Control+Z"}: KeyFunZenMode,
+		KeySeq{"Control+M", "l"}:         KeyFunJumpBracket,
+		KeySeq{"Control+M", "Control+L"}: KeyFunJumpBracket,
+		KeySeq{"Control+M", "="}:         KeyFunExpandSel,
+		KeySeq{"Control+M", "-"}:         KeyFunShrinkSel,
+		KeySeq{"Control+M", "y"}:         KeyFunDupLine,
+		KeySeq{"Control+M", "Control+Y"}: KeyFunDupLine,
+		KeySeq{"Control+M", "UpArrow"}:   KeyFunMoveLineUp,
+		KeySeq{"Control+M", "DownArrow"}: KeyFunMoveLineDown,
+		KeySeq{"Control+M", "q"}:         KeyFunDeleteLine,
+		KeySeq{"Control+M", "Control+Q"}: KeyFunDeleteLine,
+		KeySeq{"Control+M", "."}:         KeyFunNextOccur,
+		KeySeq{"Control+M", ","}:         KeyFunPrevOccur,
+		KeySeq{"Control+M", ";"}:         KeyFunJumpVisible,
+		KeySeq{"Control+M", "/"}:         KeyFunJumpTag,
 	}},
 	{"MacEmacs", "Mac with emacs-style navigation -- emacs wins in conflicts", KeySeqMap{
 		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
@@ -566,6 +609,32 @@ var StdKeyMaps = KeyMaps{
 		KeySeq{"Control+X", "Control+M"}: KeyFunBuildProj,
 		KeySeq{"Control+X", "r"}:         KeyFunRunProj,
 		KeySeq{"Control+X", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+C", "h"}:         KeyFunSigHelp,
+		KeySeq{"Control+C", "Control+H"}: KeyFunSigHelp,
+		KeySeq{"Control+C", "d"}:         KeyFunDocs,
+		KeySeq{"Control+C", "Control+D"}: KeyFunDocs,
+		KeySeq{"Control+C", "e"}:         KeyFunSentenceNext,
+		KeySeq{"Control+C", "Control+E"}: KeyFunSentenceNext,
+		KeySeq{"Control+C", "a"}:         KeyFunSentencePrev,
+		KeySeq{"Control+C", "Control+A"}: KeyFunSentencePrev,
+		KeySeq{"Control+X", "u"}:         KeyFunBufReopen,
+		KeySeq{"Control+X", "Control+U"}: KeyFunBufReopen,
+		KeySeq{"Control+X", "z"}:         KeyFunZenMode,
+		KeySeq{"Control+X", "Control+Z"}: KeyFunZenMode,
+		KeySeq{"Control+X", "l"}:         KeyFunJumpBracket,
+		KeySeq{"Control+X", "Control+L"}: KeyFunJumpBracket,
+		KeySeq{"Control+X", "="}:         KeyFunExpandSel,
+		KeySeq{"Control+X", "-"}:         KeyFunShrinkSel,
+		KeySeq{"Control+X", "y"}:         KeyFunDupLine,
+		KeySeq{"Control+X", "Control+Y"}: KeyFunDupLine,
+		KeySeq{"Control+X", "UpArrow"}:   KeyFunMoveLineUp,
+		KeySeq{"Control+X", "DownArrow"}: KeyFunMoveLineDown,
+		KeySeq{"Control+X", "q"}:         KeyFunDeleteLine,
+		KeySeq{"Control+X", "Control+Q"}: KeyFunDeleteLine,
+		KeySeq{"Control+X", "."}:         KeyFunNextOccur,
+		KeySeq{"Control+X", ","}:         KeyFunPrevOccur,
+		KeySeq{"Control+X", ";"}:         KeyFunJumpVisible,
+		KeySeq{"Control+X", "/"}:         KeyFunJumpTag,
 	}},
 	{"LinuxEmacs", "Linux with emacs-style navigation -- emacs wins in conflicts", KeySeqMap{
 		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
@@ -604,6 +673,32 @@ var StdKeyMaps = KeyMaps{
 		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
 		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
 		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+C", "h"}:         KeyFunSigHelp,
+		KeySeq{"Control+C", "Control+H"}: KeyFunSigHelp,
+		KeySeq{"Control+C", "d"}:         KeyFunDocs,
+		KeySeq{"Control+C", "Control+D"}: KeyFunDocs,
+		KeySeq{"Control+C", "e"}:         KeyFunSentenceNext,
+		KeySeq{"Control+C", "Control+E"}: KeyFunSentenceNext,
+		KeySeq{"Control+C", "a"}:         KeyFunSentencePrev,
+		KeySeq{"Control+C", "Control+A"}: KeyFunSentencePrev,
+		KeySeq{"Control+X", "u"}:         KeyFunBufReopen,
+		KeySeq{"Control+X", "Control+U"}: KeyFunBufReopen,
+		KeySeq{"Control+X", "z"}:         KeyFunZenMode,
+		KeySeq{"Control+X", "Control+Z"}: KeyFunZenMode,
+		KeySeq{"Control+X", "l"}:         KeyFunJumpBracket,
+		KeySeq{"Control+X", "Control+L"}: KeyFunJumpBracket,
+		KeySeq{"Control+X", "="}:         KeyFunExpandSel,
+		KeySeq{"Control+X", "-"}:         KeyFunShrinkSel,
+		KeySeq{"Control+X", "y"}:         KeyFunDupLine,
+		KeySeq{"Control+X", "Control+Y"}: KeyFunDupLine,
+		KeySeq{"Control+X", "UpArrow"}:   KeyFunMoveLineUp,
+		KeySeq{"Control+X", "DownArrow"}: KeyFunMoveLineDown,
+		KeySeq{"Control+X", "q"}:         KeyFunDeleteLine,
+		KeySeq{"Control+X", "Control+Q"}: KeyFunDeleteLine,
+		KeySeq{"Control+X", "."}:         KeyFunNextOccur,
+		KeySeq{"Control+X", ","}:         KeyFunPrevOccur,
+		KeySeq{"Control+X", ";"}:         KeyFunJumpVisible,
+		KeySeq{"Control+X", "/"}:         KeyFunJumpTag,
 	}},
 	{"LinuxStd", "Standard Linux KeySeqMap", KeySeqMap{
 		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
@@ -644,6 +739,32 @@ var StdKeyMaps = KeyMaps{
 		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
 		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
 		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+M", "h"}:         KeyFunSigHelp,
+		KeySeq{"Control+M", "Control+H"}: KeyFunSigHelp,
+		KeySeq{"Control+M", "d"}:         KeyFunDocs,
+		KeySeq{"Control+M", "Control+D"}: KeyFunDocs,
+		KeySeq{"Control+M", "e"}:         KeyFunSentenceNext,
+		KeySeq{"Control+M", "Control+E"}: KeyFunSentenceNext,
+		KeySeq{"Control+M", "a"}:         KeyFunSentencePrev,
+		KeySeq{"Control+M", "Control+A"}: KeyFunSentencePrev,
+		KeySeq{"Control+M", "u"}:         KeyFunBufReopen,
+		KeySeq{"Control+M", "Control+U"}: KeyFunBufReopen,
+		KeySeq{"Control+M", "z"}:         KeyFunZenMode,
+		KeySeq{"Control+M", "Control+Z"}: KeyFunZenMode,
+		KeySeq{"Control+M", "l"}:         KeyFunJumpBracket,
+		KeySeq{"Control+M", "Control+L"}: KeyFunJumpBracket,
+		KeySeq{"Control+M", "="}:         KeyFunExpandSel,
+		KeySeq{"Control+M", "-"}:         KeyFunShrinkSel,
+		KeySeq{"Control+M", "y"}:         KeyFunDupLine,
+		KeySeq{"Control+M", "Control+Y"}: KeyFunDupLine,
+		KeySeq{"Control+M", "UpArrow"}:   KeyFunMoveLineUp,
+		KeySeq{"Control+M", "DownArrow"}: KeyFunMoveLineDown,
+		KeySeq{"Control+M", "q"}:         KeyFunDeleteLine,
+		KeySeq{"Control+M", "Control+Q"}: KeyFunDeleteLine,
+		KeySeq{"Control+M", "."}:         KeyFunNextOccur,
+		KeySeq{"Control+M", ","}:         KeyFunPrevOccur,
+		KeySeq{"Control+M", ";"}:         KeyFunJumpVisible,
+		KeySeq{"Control+M", "/"}:         KeyFunJumpTag,
 	}},
 	{"WindowsStd", "Standard Windows KeySeqMap", KeySeqMap{
 		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
@@ -684,6 +805,32 @@ var StdKeyMaps = KeyMaps{
 		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
 		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
 		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+M", "h"}:         KeyFunSigHelp,
+		KeySeq{"Control+M", "Control+H"}: KeyFunSigHelp,
+		KeySeq{"Control+M", "d"}:         KeyFunDocs,
+		KeySeq{"Control+M", "Control+D"}: KeyFunDocs,
+		KeySeq{"Control+M", "e"}:         KeyFunSentenceNext,
+		KeySeq{"Control+M", "Control+E"}: KeyFunSentenceNext,
+		KeySeq{"Control+M", "a"}:         KeyFunSentencePrev,
+		KeySeq{"Control+M", "Control+A"}: KeyFunSentencePrev,
+		KeySeq{"Control+M", "u"}:         KeyFunBufReopen,
+		KeySeq{"Control+M", "Control+U"}: KeyFunBufReopen,
+		KeySeq{"Control+M", "z"}:         KeyFunZenMode,
+		KeySeq{"Control+M", "Control+Z"}: KeyFunZenMode,
+		KeySeq{"Control+M", "l"}:         KeyFunJumpBracket,
+		KeySeq{"Control+M", "Control+L"}: KeyFunJumpBracket,
+		KeySeq{"Control+M", "="}:         KeyFunExpandSel,
+		KeySeq{"Control+M", "-"}:         KeyFunShrinkSel,
+		KeySeq{"Control+M", "y"}:         KeyFunDupLine,
+		KeySeq{"Control+M", "Control+Y"}: KeyFunDupLine,
+		KeySeq{"Control+M", "UpArrow"}:   KeyFunMoveLineUp,
+		KeySeq{"Control+M", "DownArrow"}: KeyFunMoveLineDown,
+		KeySeq{"Control+M", "q"}:         KeyFunDeleteLine,
+		KeySeq{"Control+M", "Control+Q"}: KeyFunDeleteLine,
+		KeySeq{"Control+M", "."}:         KeyFunNextOccur,
+		KeySeq{"Control+M", ","}:         KeyFunPrevOccur,
+		KeySeq{"Control+M", ";"}:         KeyFunJumpVisible,
+		KeySeq{"Control+M", "/"}:         KeyFunJumpTag,
 	}},
 	{"ChromeStd", "Standard chrome-browser and linux-under-chrome bindings", KeySeqMap{
 		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
@@ -724,5 +871,31 @@ var StdKeyMaps = KeyMaps{
 		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
 		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
 		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+M", "h"}:         KeyFunSigHelp,
+		KeySeq{"Control+M", "Control+H"}: KeyFunSigHelp,
+		KeySeq{"Control+M", "d"}:         KeyFunDocs,
+		KeySeq{"Control+M", "Control+D"}: KeyFunDocs,
+		KeySeq{"Control+M", "e"}:         KeyFunSentenceNext,
+		KeySeq{"Control+M", "Control+E"}: KeyFunSentenceNext,
+		KeySeq{"Control+M", "a"}:         KeyFunSentencePrev,
+		KeySeq{"Control+M", "Control+A"}: KeyFunSentencePrev,
+		KeySeq{"Control+M", "u"}:         KeyFunBufReopen,
+		KeySeq{"Control+M", "Control+U"}: KeyFunBufReopen,
+		KeySeq{"Control+M", "z"}:         KeyFunZenMode,
+		KeySeq{"Control+M", "Control+Z"}: KeyFunZenMode,
+		KeySeq{"Control+M", "l"}:         KeyFunJumpBracket,
+		KeySeq{"Control+M", "Control+L"}: KeyFunJumpBracket,
+		KeySeq{"Control+M", "="}:         KeyFunExpandSel,
+		KeySeq{"Control+M", "-"}:         KeyFunShrinkSel,
+		KeySeq{"Control+M", "y"}:         KeyFunDupLine,
+		KeySeq{"Control+M", "Control+Y"}: KeyFunDupLine,
+		KeySeq{"Control+M", "UpArrow"}:   KeyFunMoveLineUp,
+		KeySeq{"Control+M", "DownArrow"}: KeyFunMoveLineDown,
+		KeySeq{"Control+M", "q"}:         KeyFunDeleteLine,
+		KeySeq{"Control+M", "Control+Q"}: KeyFunDeleteLine,
+		KeySeq{"Control+M", "."}:         KeyFunNextOccur,
+		KeySeq{"Control+M", ","}:         KeyFunPrevOccur,
+		KeySeq{"Control+M", ";"}:         KeyFunJumpVisible,
+		KeySeq{"Control+M", "/"}:         KeyFunJumpTag,
 	}},
 }
@@ -32,12 +32,29 @@ func _() {
 	_ = x[KeyFunSetSplit-16]
 	_ = x[KeyFunBuildProj-17]
 	_ = x[KeyFunRunProj-18]
-	_ = x[KeyFunsN-19]
+	_ = x[KeyFunSigHelp-19]
+	_ = x[KeyFunDocs-20]
+	_ = x[KeyFunSentenceNext-21]
+	_ = x[KeyFunSentencePrev-22]
+	_ = x[KeyFunBufReopen-23]
+	_ = x[KeyFunZenMode-24]
+	_ = x[KeyFunJumpBracket-25]
+	_ = x[KeyFunExpandSel-26]
+	_ = x[KeyFunShrinkSel-27]
+	_ = x[KeyFunDupLine-28]
+	_ = x[KeyFunMoveLineUp-29]
+	_ = x[KeyFunMoveLineDown-30]
+	_ = x[KeyFunDeleteLine-31]
+	_ = x[KeyFunNextOccur-32]
+	_ = x[KeyFunPrevOccur-33]
+	_ = x[KeyFunJumpVisible-34]
+	_ = x[KeyFunJumpTag-35]
+	_ = x[KeyFunsN-36]
 }
 
-const _KeyFuns_name = "KeyFunNilKeyFunNeeds2KeyFunNextPanelKeyFunPrevPanelKeyFunFileOpenKeyFunBufSelectKeyFunBufCloneKeyFunBufSaveKeyFunBufSaveAsKeyFunBufCloseKeyFunExecCmdKeyFunRegCopyKeyFunRegPasteKeyFunCommentOutKeyFunIndentKeyFunJumpKeyFunSetSplitKeyFunBuildProjKeyFunRunProjKeyFunsN"
+const _KeyFuns_name = "KeyFunNilKeyFunNeeds2KeyFunNextPanelKeyFunPrevPanelKeyFunFileOpenKeyFunBufSelectKeyFunBufCloneKeyFunBufSaveKeyFunBufSaveAsKeyFunBufCloseKeyFunExecCmdKeyFunRegCopyKeyFunRegPasteKeyFunCommentOutKeyFunIndentKeyFunJumpKeyFunSetSplitKeyFunBuildProjKeyFunRunProjKeyFunSigHelpKeyFunDocsKeyFunSentenceNextKeyFunSentencePrevKeyFunBufReopenKeyFunZenModeKeyFunJumpBracketKeyFunExpandSelKeyFunShrinkSelKeyFunDupLineKeyFunMoveLineUpKeyFunMoveLineDownKeyFunDeleteLineKeyFunNextOccurKeyFunPrevOccurKeyFunJumpVisibleKeyFunJumpTagKeyFunsN"
 
-var _KeyFuns_index = [...]uint16{0, 9, 21, 36, 51, 65, 80, 94, 107, 122, 136, 149, 162, 176, 192, 204, 214, 228, 243, 256, 264}
+var _KeyFuns_index = [...]uint16{0, 9, 21, 36, 51, 65, 80, 94, 107, 122, 136, 149, 162, 176, 192, 204, 214, 228, 243, 256, 269, 279, 297, 315, 330, 343, 360, 375, 390, 403, 419, 437, 453, 468, 483, 500, 513, 521}
 
 func (i KeyFuns) String() string {
 	if i < 0 || i >= KeyFuns(len(_KeyFuns_index)-1) {
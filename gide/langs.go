@@ -21,7 +21,15 @@ import (
 // LangOpts defines options associated with a given language / file format
 // only languages in filecat.Supported list are supported..
 type LangOpts struct {
-	PostSaveCmds CmdNames `desc:"command(s) to run after a file of this type is saved"`
+	PostSaveCmds CmdNames    `desc:"command(s) to run after a file of this type is saved"`
+	ProseMode    bool        `desc:"view files of this type in prose (writing) mode: soft wrap at the editor ProseWidth, typewriter-style centered scrolling, and no line numbers"`
+	Rulers       []int       `desc:"columns at which ruler lines are drawn for files of this type, overriding the editor Rulers -- set to 0 for none"`
+	Formatter    string      `desc:"formatter command that reads a file of this type on stdin and writes it formatted to stdout (e.g., gofmt), used by Reformat File -- if empty or not installed, lines are re-indented by their bracket nesting"`
+	CommentLn    string      `desc:"line comment string, overriding the default for the language, e.g., '# ' -- a trailing space is inserted by Comment Out and removed by uncommenting"`
+	CommentSt    string      `desc:"block comment start string, overriding the default for the language, e.g., '/* '"`
+	CommentEd    string      `desc:"block comment end string, overriding the default for the language, e.g., ' */'"`
+	BlockComment bool        `desc:"Comment Out puts one block comment around the selected lines, instead of commenting each line -- always done for languages without line comments (e.g., CSS, HTML)"`
+	Inject       []Injection `desc:"languages embedded in files of this type, e.g., SQL in strings, highlighted as those languages"`
 }
 
 // Langs is a map of language options
@@ -216,5 +224,24 @@ var LangsProps = ki.Props{
 
 // StdLangs is the original compiled-in set of standard language options.
 var StdLangs = Langs{
-	filecat.Go: {CmdNames{"Imports Go File"}},
+	filecat.Go: {PostSaveCmds: CmdNames{"Imports Go File"}, Formatter: "gofmt", Inject: []Injection{
+		SQLInjection,
+		{Lang: "yaml", Marker: "lang:yaml"},
+		{Lang: "json", Marker: "lang:json"},
+	}},
+	filecat.Bash:     {PostSaveCmds: CmdNames{"Fmt Shell File"}, Formatter: "shfmt"},
+	filecat.Markdown: {ProseMode: true},
+	filecat.TeX:      {ProseMode: true},
+	filecat.Python:   {Inject: []Injection{SQLInjection}},
+	filecat.Html: {Inject: []Injection{
+		{Lang: "javascript", Start: `(?i)<script[^>]*>`, End: `(?i)</script>`},
+		{Lang: "css", Start: `(?i)<style[^>]*>`, End: `(?i)</style>`},
+	}},
+}
+
+// ExtPostSaveCmds are commands to run after a file with given extension is
+// saved, for file types that are not a supported language in AvailLangs
+var ExtPostSaveCmds = map[string]CmdNames{
+	".tf":     {"Fmt Terraform File"},
+	".tfvars": {"Fmt Terraform File"},
 }
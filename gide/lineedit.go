@@ -0,0 +1,200 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+)
+
+// leadingSpace returns the leading whitespace of the line
+func leadingSpace(ln string) string {
+	return ln[:len(ln)-len(strings.TrimLeftFunc(ln, unicode.IsSpace))]
+}
+
+// prevNonBlank returns the last non-blank line of the lines, or ""
+func prevNonBlank(lns []string) string {
+	for i := len(lns) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lns[i]) != "" {
+			return lns[i]
+		}
+	}
+	return ""
+}
+
+// ExpectedIndent returns the indentation expected for a line starting with
+// given text after the previous non-blank line: that of the previous line,
+// plus one indent if it ends with an opening bracket, minus one if the text
+// starts with a closing bracket -- the same rule as auto-indent
+func ExpectedIndent(prev, first, ind string) string {
+	ei := leadingSpace(prev)
+	pt := strings.TrimSpace(prev)
+	if strings.HasSuffix(pt, "{") || strings.HasSuffix(pt, "(") || strings.HasSuffix(pt, "[") {
+		ei += ind
+	}
+	if strings.HasPrefix(first, "}") || strings.HasPrefix(first, ")") || strings.HasPrefix(first, "]") {
+		ei = strings.TrimSuffix(ei, ind)
+	}
+	return ei
+}
+
+// ReindentMoved returns the block of lines re-indented for being moved from
+// after the line oprev to after the line nprev (the previous non-blank
+// lines): if the block was indented as expected after oprev (see
+// ExpectedIndent), it is shifted to be indented as expected after nprev,
+// e.g., into or out of a block -- otherwise it is unchanged
+func ReindentMoved(blk []string, oprev, nprev, ind string) []string {
+	first, cur := "", ""
+	for _, l := range blk {
+		if t := strings.TrimSpace(l); t != "" {
+			first = t
+			cur = leadingSpace(l)
+			break
+		}
+	}
+	if first == "" || ind == "" || ExpectedIndent(oprev, first, ind) != cur {
+		return blk
+	}
+	nw := ExpectedIndent(nprev, first, ind)
+	if nw == cur {
+		return blk
+	}
+	nblk := make([]string, len(blk))
+	for i, l := range blk {
+		if strings.TrimSpace(l) != "" && strings.HasPrefix(l, cur) {
+			nblk[i] = nw + l[len(cur):]
+		} else {
+			nblk[i] = l
+		}
+	}
+	return nblk
+}
+
+// BufIndentString returns the string for one level of indentation in the
+// buffer, per its options
+func BufIndentString(tb *giv.TextBuf) string {
+	if tb.Opts.SpaceIndent {
+		return strings.Repeat(" ", tb.Opts.TabSize)
+	}
+	return "\t"
+}
+
+// SelLines returns the range of lines (ed exclusive) of the selection, not
+// including the last line if the selection ends at its start, or the line
+// of the cursor if there is no selection
+func (tv *TextView) SelLines() (st, ed int) {
+	if !tv.HasSelection() {
+		return tv.CursorPos.Ln, tv.CursorPos.Ln + 1
+	}
+	st, ed = tv.SelectReg.Start.Ln, tv.SelectReg.End.Ln+1
+	if tv.SelectReg.End.Ch == 0 && ed-1 > st {
+		ed--
+	}
+	return
+}
+
+// SelectLines selects the lines st to ed (exclusive), with the cursor at
+// the end of the last one
+func (tv *TextView) SelectLines(st, ed int) {
+	tv.SelectRegionShow(giv.NewTextRegion(st, 0, ed-1, len(tv.Buf.Line(ed-1))))
+}
+
+// DupLines duplicates the line of the cursor, or the lines of the
+// selection, inserting the copy below, which is then selected if there was
+// a selection, or has the cursor
+func (tv *TextView) DupLines() bool {
+	tb := tv.Buf
+	if tb == nil {
+		return false
+	}
+	st, ed := tv.SelLines()
+	sel := tv.HasSelection()
+	lns := BufLines(tb)[st:ed]
+	ep := giv.TextPos{Ln: ed - 1, Ch: len(tb.Line(ed - 1))}
+	tb.InsertText(ep, []byte("\n"+strings.Join(lns, "\n")), true, true)
+	n := ed - st
+	if sel {
+		tv.SelectLines(st+n, ed+n)
+	} else {
+		tv.SetCursorShow(giv.TextPos{Ln: tv.CursorPos.Ln + n, Ch: tv.CursorPos.Ch})
+	}
+	return true
+}
+
+// MoveLines moves the line of the cursor, or the lines of the selection, up
+// or down one line, as one operation for Undo -- they are re-indented for
+// their new place, e.g., into or out of a block, where the indentation is
+// not syntax (see ReindentMoved and KeepsIndent)
+func (tv *TextView) MoveLines(up bool) bool {
+	tb := tv.Buf
+	if tb == nil {
+		return false
+	}
+	st, ed := tv.SelLines()
+	if (up && st == 0) || (!up && ed >= tb.NumLines()) {
+		return false
+	}
+	sel := tv.HasSelection()
+	lns := BufLines(tb)
+	blk := make([]string, ed-st)
+	copy(blk, lns[st:ed])
+	oprev := prevNonBlank(lns[:st])
+	var nlns []string
+	rst, red := st, ed+1
+	if up {
+		rst, red = st-1, ed
+		if !KeepsIndent(tb.Info.Sup, string(tb.Filename)) {
+			blk = ReindentMoved(blk, oprev, prevNonBlank(lns[:st-1]), BufIndentString(tb))
+		}
+		nlns = append(blk, lns[st-1])
+	} else {
+		nprev := lns[ed]
+		if strings.TrimSpace(nprev) == "" {
+			nprev = oprev
+		}
+		if !KeepsIndent(tb.Info.Sup, string(tb.Filename)) {
+			blk = ReindentMoved(blk, oprev, nprev, BufIndentString(tb))
+		}
+		nlns = append([]string{lns[ed]}, blk...)
+	}
+	reg := giv.NewTextRegion(rst, 0, red-1, len(tb.Line(red-1)))
+	ReplaceRegion(tb, reg, []byte(strings.Join(nlns, "\n")))
+	d := 1
+	if up {
+		d = -1
+	}
+	if sel {
+		tv.SelectLines(st+d, ed+d)
+	} else {
+		ln := tv.CursorPos.Ln + d
+		tv.SetCursorShow(giv.TextPos{Ln: ln, Ch: len(leadingSpace(string(tb.Line(ln))))})
+	}
+	return true
+}
+
+// DeleteLines deletes the line of the cursor, or the lines of the
+// selection, adding them to the numbered kill registers
+func (tv *TextView) DeleteLines() bool {
+	tb := tv.Buf
+	if tb == nil {
+		return false
+	}
+	st, ed := tv.SelLines()
+	tv.SelectReset()
+	switch {
+	case ed < tb.NumLines():
+		tb.DeleteText(giv.TextPos{Ln: st}, giv.TextPos{Ln: ed}, true, true)
+	case st > 0:
+		tb.DeleteText(giv.TextPos{Ln: st - 1, Ch: len(tb.Line(st - 1))}, giv.TextPos{Ln: ed - 1, Ch: len(tb.Line(ed - 1))}, true, true)
+		st--
+	default:
+		tb.DeleteText(giv.TextPos{}, giv.TextPos{Ln: ed - 1, Ch: len(tb.Line(ed - 1))}, true, true)
+	}
+	tv.AddKillRegister()
+	tv.SetCursorShow(giv.TextPos{Ln: st})
+	return true
+}
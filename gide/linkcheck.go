@@ -0,0 +1,187 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LinkProblem is a broken link found by CheckDocLinks
+type LinkProblem struct {
+	File string `desc:"full path of the file containing the link"`
+	Ln   int    `desc:"line of the link (0-based)"`
+	Ch   int    `desc:"char position of the link in the line (0-based)"`
+	Link string `desc:"the link target as written"`
+	Msg  string `desc:"what is wrong with the link"`
+}
+
+var (
+	// mdLinkRe matches inline links and images: [text](target "title")
+	mdLinkRe = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+["'(][^)]*)?\)`)
+
+	// mdRefDefRe matches reference link definitions: [ref]: target
+	mdRefDefRe = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s*<?([^\s>]+)>?`)
+
+	// linkSchemeRe matches links with a url scheme (http:, mailto: etc), which
+	// are not checked
+	linkSchemeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// HeadingAnchor returns the anchor id for a Markdown heading title, as
+// generated by GitHub: lower case, punctuation removed, spaces to dashes
+func HeadingAnchor(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(title)) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// HeadingAnchors returns the set of anchor ids for the headings in the given
+// Markdown lines -- repeated headings get -1, -2 etc suffixes
+func HeadingAnchors(lns []string) map[string]bool {
+	anc := make(map[string]bool)
+	cnt := make(map[string]int)
+	for _, hd := range Headings(lns, "md") {
+		a := HeadingAnchor(hd.Title)
+		if n := cnt[a]; n > 0 {
+			anc[fmt.Sprintf("%v-%v", a, n)] = true
+		} else {
+			anc[a] = true
+		}
+		cnt[a]++
+	}
+	return anc
+}
+
+// MarkdownLinks calls fun for each link in the Markdown lines, skipping
+// fenced code blocks, with the 0-based line and char of the link target
+func MarkdownLinks(lns []string, fun func(ln, ch int, link string)) {
+	infence := false
+	for i, ln := range lns {
+		if mdFenceRe.MatchString(ln) {
+			infence = !infence
+			continue
+		}
+		if infence {
+			continue
+		}
+		for _, m := range mdLinkRe.FindAllStringSubmatchIndex(ln, -1) {
+			fun(i, len([]rune(ln[:m[2]])), ln[m[2]:m[3]])
+		}
+		if m := mdRefDefRe.FindStringSubmatchIndex(ln); m != nil {
+			fun(i, len([]rune(ln[:m[2]])), ln[m[2]:m[3]])
+		}
+	}
+}
+
+// fileLines returns the lines of given file
+func fileLines(fpath string) ([]string, error) {
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(b), "\n"), nil
+}
+
+// CheckDocLinks checks the relative links and anchors in all the Markdown
+// files within the given root directory, returning the broken ones, sorted
+// by file -- links with a url scheme (e.g., http:) are not checked, and
+// hidden directories are skipped.  If bufLines is non-nil, it is used to get
+// the current lines of files that are open for editing.
+func CheckDocLinks(root string, bufLines func(fpath string) []string) []LinkProblem {
+	var probs []LinkProblem
+	ancs := make(map[string]map[string]bool) // anchors by file
+	getLines := func(fpath string) ([]string, error) {
+		if bufLines != nil {
+			if lns := bufLines(fpath); lns != nil {
+				return lns, nil
+			}
+		}
+		return fileLines(fpath)
+	}
+	anchors := func(fpath string) map[string]bool {
+		if a, has := ancs[fpath]; has {
+			return a
+		}
+		var a map[string]bool
+		if lns, err := getLines(fpath); err == nil {
+			a = HeadingAnchors(lns)
+		}
+		ancs[fpath] = a
+		return a
+	}
+	filepath.Walk(root, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if fpath != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if OutlineFormat(fpath) != "md" {
+			return nil
+		}
+		lns, err := getLines(fpath)
+		if err != nil {
+			return nil
+		}
+		dir := filepath.Dir(fpath)
+		MarkdownLinks(lns, func(ln, ch int, link string) {
+			if linkSchemeRe.MatchString(link) {
+				return
+			}
+			tpath, frag := link, ""
+			if hi := strings.Index(link, "#"); hi >= 0 {
+				tpath, frag = link[:hi], link[hi+1:]
+			}
+			if up, err := url.PathUnescape(tpath); err == nil {
+				tpath = up
+			}
+			tfile := fpath
+			if tpath != "" {
+				if filepath.IsAbs(tpath) {
+					tfile = filepath.Join(root, tpath)
+				} else {
+					tfile = filepath.Join(dir, tpath)
+				}
+				tinfo, err := os.Stat(tfile)
+				if err != nil {
+					probs = append(probs, LinkProblem{File: fpath, Ln: ln, Ch: ch, Link: link, Msg: "file not found"})
+					return
+				}
+				if tinfo.IsDir() {
+					return
+				}
+			}
+			if frag == "" || OutlineFormat(tfile) != "md" {
+				return
+			}
+			if !anchors(tfile)[strings.ToLower(frag)] {
+				probs = append(probs, LinkProblem{File: fpath, Ln: ln, Ch: ch, Link: link, Msg: "anchor not found: #" + frag})
+			}
+		})
+		return nil
+	})
+	sort.SliceStable(probs, func(i, j int) bool {
+		return probs[i].File < probs[j].File
+	})
+	return probs
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"time"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/kit"
+)
+
+// LinkPanels are the policies for which text view opens the files of links,
+// e.g., find results, errors in command output, and go to definition
+type LinkPanels int32
+
+const (
+	// LinkPanelSecond opens them in the second view, which is next to the
+	// tabs where links are clicked, if it is open -- else the first
+	LinkPanelSecond LinkPanels = iota
+
+	// LinkPanelLeastEdited opens them in the view edited least recently, so
+	// that the file being worked on stays in view
+	LinkPanelLeastEdited
+
+	// LinkPanelOther opens them in the view other than the active one
+	LinkPanelOther
+
+	// LinkPanelActive opens them in the active view
+	LinkPanelActive
+
+	// LinkPanelsN is the number of link panel policies
+	LinkPanelsN
+)
+
+//go:generate stringer -type=LinkPanels
+
+var KiT_LinkPanels = kit.Enums.AddEnumAltLower(LinkPanelsN, kit.NotBitFlag, nil, "LinkPanel")
+
+// MarshalJSON encodes
+func (ev LinkPanels) MarshalJSON() ([]byte, error) { return kit.EnumMarshalJSON(ev) }
+
+// UnmarshalJSON decodes
+func (ev *LinkPanels) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// EditTrack tracks when the buffer of a view was last edited in it, as
+// opposed to in another view of the same buffer
+type EditTrack struct {
+	Buf  *giv.TextBuf `desc:"buffer of the view when last checked"`
+	Mark [2]int       `desc:"undo position and number of undo edits of the buffer when last checked"`
+	Time time.Time    `desc:"when the buffer was last edited in the view -- zero if never"`
+}
+
+// editMark returns the undo position and number of undo edits of the
+// buffer, which change with each edit
+func editMark(tb *giv.TextBuf) [2]int {
+	return [2]int{tb.UndoPos, len(tb.Undos)}
+}
+
+// TrackEdit is called after a key is processed by the view, to record the
+// time if it edited the buffer -- and that the other views of the buffer
+// did not
+func (tv *TextView) TrackEdit() {
+	tb := tv.Buf
+	et := &tv.Edit
+	if tb == nil {
+		return
+	}
+	mk := editMark(tb)
+	if et.Buf != tb {
+		et.Buf = tb
+		et.Mark = mk
+		return
+	}
+	if et.Mark == mk {
+		return
+	}
+	et.Time = time.Now()
+	for _, v := range tb.Views {
+		if gv, ok := v.This().Embed(KiT_TextView).(*TextView); ok && gv.Edit.Buf == tb {
+			gv.Edit.Mark = mk
+		}
+	}
+}
+
+// LeastEdited returns the index of the view edited least recently, among
+// those for which open is true -- views never edited come first, and ties
+// go to the later view, e.g., the second, which is next to the tabs where
+// links are clicked -- returns -1 if none is open
+func LeastEdited(tvs []*TextView, open []bool) int {
+	idx := -1
+	for i, tv := range tvs {
+		if !open[i] {
+			continue
+		}
+		if idx < 0 || !tvs[idx].Edit.Time.Before(tv.Edit.Time) {
+			idx = i
+		}
+	}
+	return idx
+}
@@ -0,0 +1,435 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/pi/filecat"
+)
+
+// MarkupTagExts are the extensions of files edited as markup (see
+// IsMarkupFile) in addition to HTML and XML files
+var MarkupTagExts = []string{".xhtml", ".svg", ".xsd", ".xsl", ".xslt", ".plist"}
+
+// HTMLVoidElements are the HTML elements that have no end tag, e.g., <br>
+var HTMLVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlRawElements are the HTML elements whose content is not markup
+var htmlRawElements = map[string]bool{"script": true, "style": true}
+
+// IsMarkupFile returns true if the file is HTML or XML, whose tags are
+// edited as such, see TagEdit
+func IsMarkupFile(fi *giv.FileInfo) bool {
+	if fi.Sup == filecat.Html || fi.Sup == filecat.Xml {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(fi.Name))
+	for _, me := range MarkupTagExts {
+		if ext == me {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkupTag is a start or end tag in HTML or XML text
+type MarkupTag struct {
+	Name   string      `desc:"name of the element"`
+	St     giv.TextPos `desc:"position of the <"`
+	Ed     giv.TextPos `desc:"position after the >"`
+	NameSt giv.TextPos `desc:"start of the name"`
+	End    bool        `desc:"end tag, e.g., </p>"`
+	Empty  bool        `desc:"start tag without an end tag: self-closing, e.g., <br/>, or of an HTML void element, e.g., <br>"`
+	Match  int         `desc:"index of the matching start or end tag -- -1 if none"`
+}
+
+// isTagNameRune returns true if the rune can be in the name of a tag
+func isTagNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == ':' || r == '-' || r == '.'
+}
+
+// isTagNameStart returns true if the rune can start the name of a tag
+func isTagNameStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_' || r == ':'
+}
+
+// tagNameAt returns the name of a tag starting at ch in the line
+func tagNameAt(lt []rune, ch int) string {
+	ed := ch
+	for ed < len(lt) && isTagNameRune(lt[ed]) {
+		ed++
+	}
+	if ch >= ed {
+		return ""
+	}
+	return string(lt[ch:ed])
+}
+
+// tagNamesEqual returns true if the tag names are equal, ignoring case in
+// HTML
+func tagNamesEqual(a, b string, html bool) bool {
+	if html {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// markupSkipTo returns the position after the next s, starting at given
+// position, and whether it was found
+func markupSkipTo(lines [][]rune, pos giv.TextPos, s string, fold bool) (giv.TextPos, bool) {
+	rs := []rune(s)
+	for ln := pos.Ln; ln < len(lines); ln++ {
+		lt := lines[ln]
+		if fold {
+			lt = []rune(strings.ToLower(string(lt)))
+		}
+		st := 0
+		if ln == pos.Ln {
+			st = pos.Ch
+		}
+		for ch := st; ch < len(lt); ch++ {
+			if runesAt(lt, ch, rs) {
+				return giv.TextPos{Ln: ln, Ch: ch + len(rs)}, true
+			}
+		}
+	}
+	return pos, false
+}
+
+// markupTagEnd returns the position after the > ending the tag whose name
+// ends at given position, skipping quoted attribute values, and whether
+// the tag is self-closing
+func markupTagEnd(lines [][]rune, pos giv.TextPos) (giv.TextPos, bool, bool) {
+	var quote rune
+	var prv rune
+	for ln := pos.Ln; ln < len(lines); ln++ {
+		lt := lines[ln]
+		st := 0
+		if ln == pos.Ln {
+			st = pos.Ch
+		}
+		for ch := st; ch < len(lt); ch++ {
+			r := lt[ch]
+			switch {
+			case quote != 0:
+				if r == quote {
+					quote = 0
+				}
+			case r == '"' || r == '\'':
+				quote = r
+			case r == '<':
+				return pos, false, false // not closed
+			case r == '>':
+				return giv.TextPos{Ln: ln, Ch: ch + 1}, prv == '/', true
+			}
+			if !unicode.IsSpace(r) {
+				prv = r
+			}
+		}
+	}
+	return pos, false, false
+}
+
+// ScanMarkupTags returns the start and end tags of HTML or XML text, in
+// order, skipping comments, CDATA sections, declarations and processing
+// instructions, and for HTML, the content of script and style elements --
+// each tag is matched with the nearest open tag of the same name before it,
+// with any unmatched tags in between left open, as browsers do
+func ScanMarkupTags(lines [][]rune, html bool) []MarkupTag {
+	var tags []MarkupTag
+	pos := giv.TextPos{}
+	for pos.Ln < len(lines) {
+		lt := lines[pos.Ln]
+		if pos.Ch >= len(lt) {
+			pos = giv.TextPos{Ln: pos.Ln + 1}
+			continue
+		}
+		if lt[pos.Ch] != '<' {
+			pos.Ch++
+			continue
+		}
+		st := pos
+		rest := string(lt[pos.Ch:])
+		var ok bool
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			pos, ok = markupSkipTo(lines, giv.TextPos{Ln: st.Ln, Ch: st.Ch + 4}, "-->", false)
+		case strings.HasPrefix(rest, "<![CDATA["):
+			pos, ok = markupSkipTo(lines, giv.TextPos{Ln: st.Ln, Ch: st.Ch + 9}, "]]>", false)
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			pos, ok = markupSkipTo(lines, giv.TextPos{Ln: st.Ln, Ch: st.Ch + 2}, ">", false)
+		default:
+			tg := MarkupTag{St: st, NameSt: giv.TextPos{Ln: st.Ln, Ch: st.Ch + 1}, Match: -1}
+			if strings.HasPrefix(rest, "</") {
+				tg.End = true
+				tg.NameSt.Ch++
+			}
+			if tg.NameSt.Ch >= len(lt) || !isTagNameStart(lt[tg.NameSt.Ch]) {
+				pos.Ch++
+				continue
+			}
+			tg.Name = tagNameAt(lt, tg.NameSt.Ch)
+			var empty bool
+			tg.Ed, empty, ok = markupTagEnd(lines, giv.TextPos{Ln: st.Ln, Ch: tg.NameSt.Ch + len([]rune(tg.Name))})
+			if !ok {
+				pos.Ch++
+				continue
+			}
+			lnm := strings.ToLower(tg.Name)
+			tg.Empty = !tg.End && (empty || (html && HTMLVoidElements[lnm]))
+			tags = append(tags, tg)
+			pos = tg.Ed
+			if html && !tg.End && !tg.Empty && htmlRawElements[lnm] {
+				if ep, found := markupSkipTo(lines, pos, "</"+lnm, true); found {
+					pos = giv.TextPos{Ln: ep.Ln, Ch: ep.Ch - len(lnm) - 2}
+				} else {
+					pos = giv.TextPos{Ln: len(lines)}
+				}
+			}
+			continue
+		}
+		if !ok {
+			break
+		}
+	}
+	var open []int
+	for i := range tags {
+		tg := &tags[i]
+		if !tg.End {
+			if !tg.Empty {
+				open = append(open, i)
+			}
+			continue
+		}
+		for k := len(open) - 1; k >= 0; k-- {
+			if tagNamesEqual(tags[open[k]].Name, tg.Name, html) {
+				tg.Match = open[k]
+				tags[open[k]].Match = i
+				open = open[:k]
+				break
+			}
+		}
+	}
+	return tags
+}
+
+// MarkupTagAt returns the index of the tag at given position, from its <
+// to its >, inclusive -- -1 if none
+func MarkupTagAt(tags []MarkupTag, pos giv.TextPos) int {
+	for i := range tags {
+		tg := &tags[i]
+		if !pos.IsLess(tg.St) && pos.IsLess(tg.Ed) {
+			return i
+		}
+	}
+	for i := range tags {
+		if tags[i].Ed == pos {
+			return i
+		}
+	}
+	return -1
+}
+
+// EnclosingMarkupTag returns the index of the start tag of the innermost
+// element whose content contains given position -- -1 if none
+func EnclosingMarkupTag(tags []MarkupTag, pos giv.TextPos) int {
+	enc := -1
+	for i := range tags {
+		tg := &tags[i]
+		if pos.IsLess(tg.Ed) {
+			break
+		}
+		if !tg.End && tg.Match >= 0 && !tags[tg.Match].St.IsLess(pos) {
+			enc = i
+		}
+	}
+	return enc
+}
+
+// markupTagNameAt returns the index of the tag whose name contains given
+// position, including its end -- -1 if none
+func markupTagNameAt(tags []MarkupTag, pos giv.TextPos) int {
+	for i := range tags {
+		tg := &tags[i]
+		if tg.NameSt.Ln == pos.Ln && pos.Ch >= tg.NameSt.Ch && pos.Ch <= tg.NameSt.Ch+len([]rune(tg.Name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// TagRenameState is the state of the renaming of the matching tag while the
+// name of a tag is edited in a TextView
+type TagRenameState struct {
+	Active bool        `desc:"the name of a tag with a matching tag is being edited"`
+	Name   string      `desc:"name of both tags, as last synchronized"`
+	Tag    giv.TextPos `desc:"start of the name edited"`
+	Match  giv.TextPos `desc:"start of the name of the matching tag"`
+	NLines int         `desc:"number of lines of the buffer -- editing stops if it changes"`
+}
+
+// IsMarkupView returns true if the view edits a markup file with TagEdit on
+func (tv *TextView) IsMarkupView() bool {
+	return tv.TagEdit && tv.Buf != nil && IsMarkupFile(&tv.Buf.Info)
+}
+
+// MarkupTags returns the tags of the buffer (see ScanMarkupTags)
+func (tv *TextView) MarkupTags() []MarkupTag {
+	tb := tv.Buf
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	return ScanMarkupTags(tb.Lines, tb.Info.Sup == filecat.Html)
+}
+
+// JumpTag moves the cursor to the tag matching the one at the cursor, or
+// else to the start tag of the innermost element containing the cursor
+func (tv *TextView) JumpTag() bool {
+	if tv.Buf == nil || !IsMarkupFile(&tv.Buf.Info) {
+		return false
+	}
+	tags := tv.MarkupTags()
+	ti := MarkupTagAt(tags, tv.CursorPos)
+	if ti >= 0 && tags[ti].Match >= 0 {
+		ti = tags[ti].Match
+	} else {
+		ti = EnclosingMarkupTag(tags, tv.CursorPos)
+	}
+	if ti < 0 {
+		return false
+	}
+	tv.SavePosHistory(tv.CursorPos)
+	tv.SetCursorShow(tags[ti].St)
+	tv.SetCursorCol(tv.CursorPos)
+	return true
+}
+
+// TagCloseKeyInput inserts the end tag after the cursor when the > of a
+// start tag has just been typed, if TagEdit is on, unless the tag is empty,
+// e.g., <br/>, or it and all the other open tags of its name before it are
+// already closed
+func (tv *TextView) TagCloseKeyInput(kt *key.ChordEvent) {
+	tb := tv.Buf
+	if kt.Rune != '>' || !tv.IsMarkupView() || tv.IsInactive() || tv.ISearch.On || tv.QReplace.On {
+		return
+	}
+	pos := tv.CursorPos
+	if pos.Ch == 0 || tb.UndoPos == 0 || tb.UndoPos > len(tb.Undos) {
+		return
+	}
+	if tbe := tb.Undos[tb.UndoPos-1]; tbe == nil || tbe.Delete || tbe.Reg.Start != (giv.TextPos{Ln: pos.Ln, Ch: pos.Ch - 1}) {
+		return
+	}
+	tags := tv.MarkupTags()
+	ti := -1
+	for i := range tags {
+		if tags[i].Ed == pos {
+			ti = i
+			break
+		}
+	}
+	if ti < 0 || tags[ti].End || tags[ti].Empty {
+		return
+	}
+	html := tb.Info.Sup == filecat.Html
+	open := false
+	for i := 0; i <= ti; i++ {
+		tg := &tags[i]
+		if !tg.End && !tg.Empty && tg.Match < 0 && tagNamesEqual(tg.Name, tags[ti].Name, html) {
+			open = true
+			break
+		}
+	}
+	if !open {
+		return
+	}
+	tb.InsertText(pos, []byte("</"+tags[ti].Name+">"), true, true)
+	tv.SetCursorShow(pos)
+}
+
+// TagRenameKeyBefore starts or continues the renaming of the matching tag
+// before a key is processed, if the cursor is in the name of a tag with a
+// matching tag and TagEdit is on, see TagRenameKeyInput
+func (tv *TextView) TagRenameKeyBefore(kt *key.ChordEvent) {
+	tr := &tv.TagRename
+	if kt.IsProcessed() || !tv.IsMarkupView() {
+		tr.Active = false
+		return
+	}
+	tb := tv.Buf
+	pos := tv.CursorPos
+	if tr.Active && tr.NLines == tb.NumLines() && pos.Ln == tr.Tag.Ln {
+		nm := tagNameAt(tb.Line(tr.Tag.Ln), tr.Tag.Ch)
+		if nm == tr.Name && pos.Ch >= tr.Tag.Ch && pos.Ch <= tr.Tag.Ch+len([]rune(nm)) {
+			return
+		}
+	}
+	tr.Active = false
+	lt := tb.Line(pos.Ln)
+	st := pos.Ch
+	for st > 0 && st <= len(lt) && isTagNameRune(lt[st-1]) {
+		st--
+	}
+	if st == 0 || st > len(lt) || (lt[st-1] != '<' && lt[st-1] != '/') {
+		return // not in the name of a tag
+	}
+	tags := tv.MarkupTags()
+	ti := markupTagNameAt(tags, pos)
+	if ti < 0 || tags[ti].Match < 0 {
+		return
+	}
+	*tr = TagRenameState{Active: true, Name: tags[ti].Name, Tag: tags[ti].NameSt, Match: tags[tags[ti].Match].NameSt, NLines: tb.NumLines()}
+}
+
+// TagRenameKeyInput renames the matching tag after the name of a tag has
+// been edited, as started by TagRenameKeyBefore
+func (tv *TextView) TagRenameKeyInput(kt *key.ChordEvent) {
+	tr := &tv.TagRename
+	tb := tv.Buf
+	if !tr.Active || tb == nil {
+		return
+	}
+	if kf := gi.KeyFun(kt.Chord()); kf == gi.KeyFunUndo || kf == gi.KeyFunRedo {
+		tr.Active = false
+		return
+	}
+	if tr.NLines != tb.NumLines() || !tb.IsValidLine(tr.Tag.Ln) || !tb.IsValidLine(tr.Match.Ln) {
+		tr.Active = false
+		return
+	}
+	lt := tb.Line(tr.Tag.Ln)
+	if tr.Tag.Ch == 0 || tr.Tag.Ch > len(lt) || (lt[tr.Tag.Ch-1] != '<' && lt[tr.Tag.Ch-1] != '/') {
+		tr.Active = false
+		return
+	}
+	nm := tagNameAt(lt, tr.Tag.Ch)
+	if nm == tr.Name {
+		return
+	}
+	delta := len([]rune(nm)) - len([]rune(tr.Name))
+	mt := tr.Match
+	if mt.Ln == tr.Tag.Ln && mt.Ch > tr.Tag.Ch {
+		mt.Ch += delta
+	}
+	if tagNameAt(tb.Line(mt.Ln), mt.Ch) != tr.Name {
+		tr.Active = false
+		return
+	}
+	ReplaceRegion(tb, giv.NewTextRegion(mt.Ln, mt.Ch, mt.Ln, mt.Ch+len([]rune(tr.Name))), []byte(nm))
+	if mt.Ln == tr.Tag.Ln && mt.Ch < tr.Tag.Ch {
+		tr.Tag.Ch += delta
+		tv.SetCursorShow(giv.TextPos{Ln: tv.CursorPos.Ln, Ch: tv.CursorPos.Ch + delta})
+	}
+	tr.Name = nm
+	tr.Match = mt
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goki/gi/giv"
+)
+
+// TableMinColWidth is the minimum width of a Markdown table column when
+// reformatting -- the separator row needs at least 3 dashes
+var TableMinColWidth = 3
+
+// tableSepRe matches a cell of a Markdown table separator row, with optional
+// alignment colons
+var tableSepRe = regexp.MustCompile(`^:?-+:?$`)
+
+// IsTableLine returns true if the line is (part of) a Markdown table
+func IsTableLine(ln string) bool {
+	return strings.Contains(ln, "|") && strings.TrimSpace(ln) != ""
+}
+
+// TableRow splits a Markdown table line into its cells, ignoring the outer
+// pipes -- escaped pipes (\|) are not cell separators
+func TableRow(ln string) []string {
+	ln = strings.TrimSpace(ln)
+	ln = strings.TrimPrefix(ln, "|")
+	if strings.HasSuffix(ln, "|") && !strings.HasSuffix(ln, `\|`) {
+		ln = ln[:len(ln)-1]
+	}
+	var cells []string
+	st := 0
+	for i := 0; i < len(ln); i++ {
+		switch ln[i] {
+		case '\\':
+			i++
+		case '|':
+			cells = append(cells, strings.TrimSpace(ln[st:i]))
+			st = i + 1
+		}
+	}
+	return append(cells, strings.TrimSpace(ln[st:]))
+}
+
+// IsTableSep returns true if the cells are a table separator row
+func IsTableSep(cells []string) bool {
+	for _, c := range cells {
+		if !tableSepRe.MatchString(c) {
+			return false
+		}
+	}
+	return len(cells) > 0
+}
+
+// TableColumnAt returns the index of the table column at given char
+// position in the table line
+func TableColumnAt(ln string, ch int) int {
+	lr := []rune(ln)
+	if ch > len(lr) {
+		ch = len(lr)
+	}
+	pre := strings.TrimLeft(string(lr[:ch]), " \t")
+	col := strings.Count(pre, "|") - strings.Count(pre, `\|`)
+	if strings.HasPrefix(pre, "|") {
+		col--
+	}
+	if col < 0 {
+		col = 0
+	}
+	return col
+}
+
+// FormatTable reformats the Markdown table lines so that all the columns are
+// aligned, according to the alignment of the separator row -- rows are padded
+// with empty cells to the full number of columns, and a separator row is
+// added after the header if missing
+func FormatTable(lns []string) []string {
+	var rows [][]string
+	sep := -1
+	ncol := 0
+	for i, ln := range lns {
+		cells := TableRow(ln)
+		if sep < 0 && i == 1 && IsTableSep(cells) {
+			sep = i
+		}
+		rows = append(rows, cells)
+		if len(cells) > ncol {
+			ncol = len(cells)
+		}
+	}
+	if len(rows) == 0 {
+		return lns
+	}
+	if sep < 0 {
+		rows = append(rows[:1], append([][]string{{}}, rows[1:]...)...)
+		sep = 1
+	}
+	align := make([]string, ncol)
+	for c := range align {
+		if c < len(rows[sep]) {
+			cl := rows[sep][c]
+			switch {
+			case strings.HasPrefix(cl, ":") && strings.HasSuffix(cl, ":"):
+				align[c] = "c"
+			case strings.HasSuffix(cl, ":"):
+				align[c] = "r"
+			case strings.HasPrefix(cl, ":"):
+				align[c] = "l"
+			}
+		}
+	}
+	wd := make([]int, ncol)
+	for c := range wd {
+		wd[c] = TableMinColWidth
+	}
+	for r, cells := range rows {
+		if r == sep {
+			continue
+		}
+		for c, cl := range cells {
+			if n := utf8.RuneCountInString(cl); n > wd[c] {
+				wd[c] = n
+			}
+		}
+	}
+	out := make([]string, len(rows))
+	for r, cells := range rows {
+		var b strings.Builder
+		b.WriteString("|")
+		for c := 0; c < ncol; c++ {
+			cl := ""
+			if c < len(cells) {
+				cl = cells[c]
+			}
+			if r == sep {
+				dsh := strings.Repeat("-", wd[c])
+				switch align[c] {
+				case "c":
+					dsh = ":" + dsh[2:] + ":"
+				case "r":
+					dsh = dsh[1:] + ":"
+				case "l":
+					dsh = ":" + dsh[1:]
+				}
+				b.WriteString(" " + dsh + " |")
+				continue
+			}
+			pad := wd[c] - utf8.RuneCountInString(cl)
+			switch align[c] {
+			case "c":
+				cl = strings.Repeat(" ", pad/2) + cl + strings.Repeat(" ", pad-pad/2)
+			case "r":
+				cl = strings.Repeat(" ", pad) + cl
+			default:
+				cl += strings.Repeat(" ", pad)
+			}
+			b.WriteString(" " + cl + " |")
+		}
+		out[r] = b.String()
+	}
+	return out
+}
+
+// TableInsertColumn returns the table lines with an empty column inserted at
+// given index (in all rows), reformatted
+func TableInsertColumn(lns []string, col int) []string {
+	out := make([]string, len(lns))
+	for i, ln := range lns {
+		cells := TableRow(ln)
+		for len(cells) < col {
+			cells = append(cells, "")
+		}
+		nc := ""
+		if i == 1 && IsTableSep(cells) {
+			nc = "---"
+		}
+		cells = append(cells[:col], append([]string{nc}, cells[col:]...)...)
+		out[i] = "| " + strings.Join(cells, " | ") + " |"
+	}
+	return FormatTable(out)
+}
+
+// TableDeleteColumn returns the table lines with the column at given index
+// removed (from all rows), reformatted
+func TableDeleteColumn(lns []string, col int) []string {
+	out := make([]string, len(lns))
+	for i, ln := range lns {
+		cells := TableRow(ln)
+		if col < len(cells) {
+			cells = append(cells[:col], cells[col+1:]...)
+		}
+		out[i] = "| " + strings.Join(cells, " | ") + " |"
+	}
+	return FormatTable(out)
+}
+
+// CSVToTable converts CSV text to Markdown table lines, with the first
+// record as the header
+func CSVToTable(text string) ([]string, error) {
+	rd := csv.NewReader(strings.NewReader(text))
+	rd.FieldsPerRecord = -1
+	rd.TrimLeadingSpace = true
+	recs, err := rd.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, fmt.Errorf("CSVToTable: no records in selection")
+	}
+	lns := make([]string, 0, len(recs)+1)
+	for i, rec := range recs {
+		for j, f := range rec {
+			rec[j] = strings.Replace(f, "|", `\|`, -1)
+		}
+		lns = append(lns, "| "+strings.Join(rec, " | ")+" |")
+		if i == 0 {
+			lns = append(lns, "| --- |")
+		}
+	}
+	return FormatTable(lns), nil
+}
+
+// TableAt returns the start and end (exclusive) lines of the Markdown table
+// containing given line in the buffer -- false if not in a table
+func TableAt(tb *giv.TextBuf, ln int) (st, ed int, ok bool) {
+	nl := tb.NumLines()
+	if ln >= nl || !IsTableLine(string(tb.Line(ln))) {
+		return 0, 0, false
+	}
+	st = ln
+	for st > 0 && IsTableLine(string(tb.Line(st-1))) {
+		st--
+	}
+	ed = ln + 1
+	for ed < nl && IsTableLine(string(tb.Line(ed))) {
+		ed++
+	}
+	return st, ed, true
+}
+
+// ReplaceLines replaces lines st through ed (exclusive) in the buffer with
+// given lines, as one delete and one insert
+func ReplaceLines(tb *giv.TextBuf, st, ed int, lns []string) {
+	ep := giv.TextPos{Ln: ed - 1, Ch: len(tb.Line(ed - 1))}
+	tb.DeleteText(giv.TextPos{Ln: st}, ep, true, true)
+	tb.InsertText(giv.TextPos{Ln: st}, []byte(strings.Join(lns, "\n")), true, true)
+}
+
+// TableEdit applies given function to the lines of the Markdown table at the
+// cursor, replacing them with the result -- returns false if not in a table
+func (tv *TextView) TableEdit(fun func(lns []string, col int) []string) bool {
+	if tv.Buf == nil {
+		return false
+	}
+	st, ed, ok := TableAt(tv.Buf, tv.CursorPos.Ln)
+	if !ok {
+		return false
+	}
+	lns := BufLines(tv.Buf)[st:ed]
+	col := TableColumnAt(lns[tv.CursorPos.Ln-st], tv.CursorPos.Ch)
+	ReplaceLines(tv.Buf, st, ed, fun(lns, col))
+	tv.SetCursorShow(giv.TextPos{Ln: tv.CursorPos.Ln})
+	return true
+}
+
+// TableFormat reformats (aligns) the Markdown table at the cursor
+func (tv *TextView) TableFormat() bool {
+	return tv.TableEdit(func(lns []string, col int) []string {
+		return FormatTable(lns)
+	})
+}
+
+// TableAddColumn adds an empty column after the one at the cursor, in the
+// Markdown table at the cursor
+func (tv *TextView) TableAddColumn() bool {
+	return tv.TableEdit(func(lns []string, col int) []string {
+		return TableInsertColumn(lns, col+1)
+	})
+}
+
+// TableDeleteColumn deletes the column at the cursor, in the Markdown table
+// at the cursor
+func (tv *TextView) TableDeleteColumn() bool {
+	return tv.TableEdit(func(lns []string, col int) []string {
+		return TableDeleteColumn(lns, col)
+	})
+}
+
+// TableFromCSV converts the selected CSV text to a Markdown table
+func (tv *TextView) TableFromCSV() error {
+	sel := tv.Selection()
+	if sel == nil {
+		return fmt.Errorf("select the CSV text to convert to a table")
+	}
+	lns, err := CSVToTable(string(sel.ToBytes()))
+	if err != nil {
+		return err
+	}
+	tv.Buf.DeleteText(sel.Reg.Start, sel.Reg.End, true, true)
+	tv.Buf.InsertText(sel.Reg.Start, []byte(strings.Join(lns, "\n")+"\n"), true, true)
+	tv.SelectReset()
+	return nil
+}
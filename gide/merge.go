@@ -0,0 +1,135 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+)
+
+// The markers of merge conflicts, as written by git and other VCS, at the
+// start of a line: a base section is only present in the diff3 style
+const (
+	MergeOursMarker   = "<<<<<<<"
+	MergeBaseMarker   = "|||||||"
+	MergeSepMarker    = "======="
+	MergeTheirsMarker = ">>>>>>>"
+)
+
+// MergeConflict is a merge conflict in text with conflict markers, with the
+// lines of each side
+type MergeConflict struct {
+	St          int      `desc:"line of the <<<<<<< marker starting the conflict"`
+	Ed          int      `desc:"line after the >>>>>>> marker ending the conflict"`
+	Ours        []string `desc:"lines of our side, e.g., HEAD"`
+	Base        []string `desc:"lines of the base, the common ancestor -- only with the diff3 conflict style"`
+	Theirs      []string `desc:"lines of their side, e.g., the branch being merged"`
+	HasBase     bool     `desc:"the conflict has a base section"`
+	OursLabel   string   `desc:"label of our side, after the <<<<<<< marker"`
+	BaseLabel   string   `desc:"label of the base, after the ||||||| marker"`
+	TheirsLabel string   `desc:"label of their side, after the >>>>>>> marker"`
+}
+
+// MergeTake is how a merge conflict is resolved: which of its sides is
+// taken
+type MergeTake int
+
+const (
+	// MergeTakeOurs takes our side
+	MergeTakeOurs MergeTake = iota
+
+	// MergeTakeTheirs takes their side
+	MergeTakeTheirs
+
+	// MergeTakeBoth takes both sides, ours first
+	MergeTakeBoth
+
+	// MergeTakeBase takes the base, undoing both changes
+	MergeTakeBase
+)
+
+// markerLabel returns the label after the marker at the start of the line,
+// and false if the line does not start with the marker
+func markerLabel(ln, marker string) (string, bool) {
+	if !strings.HasPrefix(ln, marker) {
+		return "", false
+	}
+	rest := ln[len(marker):]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' && rest[0] != '\r' {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// ParseConflicts returns the merge conflicts in the lines, in order --
+// conflicts without all their markers are skipped
+func ParseConflicts(lns []string) []MergeConflict {
+	var mcs []MergeConflict
+	for i := 0; i < len(lns); i++ {
+		lbl, ok := markerLabel(lns[i], MergeOursMarker)
+		if !ok {
+			continue
+		}
+		mc := MergeConflict{St: i, OursLabel: lbl}
+		sec := &mc.Ours
+		done := false
+		j := i + 1
+		for ; j < len(lns) && !done; j++ {
+			ln := lns[j]
+			if _, ok := markerLabel(ln, MergeOursMarker); ok {
+				break // nested start: this one is incomplete
+			}
+			if lbl, ok := markerLabel(ln, MergeBaseMarker); ok && sec == &mc.Ours {
+				mc.HasBase = true
+				mc.BaseLabel = lbl
+				sec = &mc.Base
+				continue
+			}
+			if _, ok := markerLabel(ln, MergeSepMarker); ok && sec != &mc.Theirs {
+				sec = &mc.Theirs
+				continue
+			}
+			if lbl, ok := markerLabel(ln, MergeTheirsMarker); ok && sec == &mc.Theirs {
+				mc.TheirsLabel = lbl
+				done = true
+				continue
+			}
+			*sec = append(*sec, ln)
+		}
+		if !done {
+			continue
+		}
+		mc.Ed = j
+		mcs = append(mcs, mc)
+		i = j - 1
+	}
+	return mcs
+}
+
+// HasConflicts returns true if the lines have any merge conflicts
+func HasConflicts(lns []string) bool {
+	for _, ln := range lns {
+		if _, ok := markerLabel(ln, MergeOursMarker); ok {
+			return len(ParseConflicts(lns)) > 0
+		}
+	}
+	return false
+}
+
+// Resolved returns the lines that resolve the conflict by taking given
+// side(s) of it -- the base is empty if the conflict has none
+func (mc *MergeConflict) Resolved(take MergeTake) []string {
+	var lns []string
+	switch take {
+	case MergeTakeOurs:
+		lns = append(lns, mc.Ours...)
+	case MergeTakeTheirs:
+		lns = append(lns, mc.Theirs...)
+	case MergeTakeBoth:
+		lns = append(append(lns, mc.Ours...), mc.Theirs...)
+	case MergeTakeBase:
+		lns = append(lns, mc.Base...)
+	}
+	return lns
+}
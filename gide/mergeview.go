@@ -0,0 +1,259 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// MergeView is a three-pane merge conflict resolver for a buffer with merge
+// conflict markers: it shows our side, the base (if any) and their side of
+// the current conflict, which is selected in the editor of the buffer, the
+// result of the merge -- the toolbar actions resolve the conflict by taking
+// one or both sides, and navigate among the conflicts
+type MergeView struct {
+	gi.Layout
+	Gide      Gide            `json:"-" xml:"-" desc:"parent gide project"`
+	FileNode  *giv.FileNode   `json:"-" xml:"-" desc:"file node of the buffer with the conflicts, where they are resolved"`
+	Conflicts []MergeConflict `json:"-" xml:"-" desc:"conflicts in the buffer, as of the last update"`
+	Cur       int             `desc:"index of the current conflict"`
+}
+
+var KiT_MergeView = kit.Types.AddType(&MergeView{}, MergeViewProps)
+
+// MergePanes are the names and labels of the panes of the MergeView
+var MergePanes = []string{"ours", "base", "theirs"}
+
+// Config configures the view for resolving the conflicts in the buffer of
+// given file node, and shows the first one
+func (mv *MergeView) Config(ge Gide, fn *giv.FileNode) {
+	mv.Gide = ge
+	mv.FileNode = fn
+	mv.Lay = gi.LayoutVert
+	mv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "toolbar")
+	config.Add(gi.KiT_SplitView, "panes")
+	mods, updt := mv.ConfigChildren(config, false)
+	if !mods {
+		updt = mv.UpdateStart()
+	}
+	mv.ConfigToolbar()
+	mv.ConfigPanes()
+	mv.UpdateConflicts()
+	mv.ShowConflict(0)
+	mv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (mv *MergeView) ToolBar() *gi.ToolBar {
+	return mv.ChildByName("toolbar", 0).(*gi.ToolBar)
+}
+
+// Panes returns the splitview of the panes
+func (mv *MergeView) Panes() *gi.SplitView {
+	return mv.ChildByName("panes", 1).(*gi.SplitView)
+}
+
+// PaneLabel returns the label of the pane of given index (see MergePanes)
+func (mv *MergeView) PaneLabel(idx int) *gi.Label {
+	return mv.Panes().Child(idx).Child(0).(*gi.Label)
+}
+
+// PaneTextView returns the TextView of the pane of given index (see
+// MergePanes)
+func (mv *MergeView) PaneTextView(idx int) *giv.TextView {
+	return mv.Panes().Child(idx).Child(1).Child(0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// StatusLabel returns the label showing the current conflict in the toolbar
+func (mv *MergeView) StatusLabel() *gi.Label {
+	return mv.ToolBar().ChildByName("status", 0).(*gi.Label)
+}
+
+// ConfigPanes configures the panes showing the sides of the conflict
+func (mv *MergeView) ConfigPanes() {
+	sv := mv.Panes()
+	if sv.HasChildren() {
+		return
+	}
+	sv.Dim = gi.X
+	for _, pn := range MergePanes {
+		ly := sv.AddNewChild(gi.KiT_Layout, pn).(*gi.Layout)
+		ly.Lay = gi.LayoutVert
+		ly.SetStretchMaxWidth()
+		ly.SetStretchMaxHeight()
+		gi.AddNewLabel(ly, "label", "<b>"+strings.Title(pn)+"</b>")
+		tly := gi.AddNewLayout(ly, "text", gi.LayoutVert)
+		tv := mv.Gide.ConfigOutputTextView(tly)
+		tb := &giv.TextBuf{}
+		tb.InitName(tb, "merge-"+pn)
+		tv.SetBuf(tb)
+	}
+	sv.SetSplits(.33, .34, .33)
+}
+
+// ConfigToolbar adds the actions to the toolbar
+func (mv *MergeView) ConfigToolbar() {
+	tb := mv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Name: "prev", Icon: "wedge-up", Tooltip: "go to the previous conflict"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.PrevConflict()
+		})
+	tb.AddAction(gi.ActOpts{Name: "next", Icon: "wedge-down", Tooltip: "go to the next conflict"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.NextConflict()
+		})
+	gi.AddNewLabel(tb, "status", "")
+	tb.AddSeparator("sep-take")
+	takes := []struct {
+		label, tip string
+		take       MergeTake
+	}{
+		{"Take Ours", "resolve the conflict by taking our side (left pane)", MergeTakeOurs},
+		{"Take Theirs", "resolve the conflict by taking their side (right pane)", MergeTakeTheirs},
+		{"Take Both", "resolve the conflict by taking both sides, ours first", MergeTakeBoth},
+		{"Take Base", "resolve the conflict by taking the base (middle pane), undoing both changes", MergeTakeBase},
+	}
+	for _, tk := range takes {
+		take := tk.take
+		tb.AddAction(gi.ActOpts{Label: tk.label, Tooltip: tk.tip},
+			mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+				mvv.TakeConflict(take)
+			})
+	}
+	tb.AddSeparator("sep-upd")
+	tb.AddAction(gi.ActOpts{Label: "Update", Icon: "update", Tooltip: "update the conflicts from the buffer, e.g., after editing it"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.UpdateConflicts()
+			mvv.ShowConflict(mvv.Cur)
+		})
+}
+
+// TextView returns the editor of the buffer, viewing it if it is not
+// already being viewed
+func (mv *MergeView) TextView() *TextView {
+	tv := mv.Gide.ActiveTextView()
+	if tv == nil || tv.Buf != mv.FileNode.Buf {
+		tv, _ = mv.Gide.NextViewFileNode(mv.FileNode)
+	}
+	return tv
+}
+
+// UpdateConflicts updates the conflicts from the buffer
+func (mv *MergeView) UpdateConflicts() {
+	mv.Conflicts = nil
+	if mv.FileNode.Buf != nil {
+		mv.Conflicts = ParseConflicts(BufLines(mv.FileNode.Buf))
+	}
+}
+
+// ShowConflict shows the conflict of given index in the panes, and selects
+// it in the editor of the buffer
+func (mv *MergeView) ShowConflict(idx int) {
+	updt := mv.UpdateStart()
+	defer mv.UpdateEnd(updt)
+	n := len(mv.Conflicts)
+	if n == 0 {
+		mv.Cur = 0
+		mv.StatusLabel().SetText(fmt.Sprintf("%v: no conflicts", mv.FileNode.Nm))
+		for i := range MergePanes {
+			mv.PaneTextView(i).Buf.New(0)
+		}
+		return
+	}
+	if idx < 0 {
+		idx = n - 1
+	} else if idx >= n {
+		idx = 0
+	}
+	mv.Cur = idx
+	mc := &mv.Conflicts[idx]
+	mv.StatusLabel().SetText(fmt.Sprintf("%v: conflict %v of %v", mv.FileNode.Nm, idx+1, n))
+	lbls := []string{mc.OursLabel, mc.BaseLabel, mc.TheirsLabel}
+	for i, lns := range [][]string{mc.Ours, mc.Base, mc.Theirs} {
+		lbl := "<b>" + strings.Title(MergePanes[i]) + "</b>"
+		if lbls[i] != "" {
+			lbl += ": " + lbls[i]
+		}
+		if i == 1 && !mc.HasBase {
+			lbl += " (none -- use the diff3 conflict style to see it)"
+		}
+		mv.PaneLabel(i).SetText(lbl)
+		mv.PaneTextView(i).Buf.SetText([]byte(strings.Join(lns, "\n")))
+	}
+	tv := mv.TextView()
+	if tv == nil {
+		return
+	}
+	tb := tv.Buf
+	tv.SelectRegionShow(giv.NewTextRegion(mc.St, 0, mc.Ed-1, len(tb.Line(mc.Ed-1))))
+}
+
+// NextConflict shows the next conflict, wrapping around
+func (mv *MergeView) NextConflict() {
+	mv.ShowConflict(mv.Cur + 1)
+}
+
+// PrevConflict shows the previous conflict, wrapping around
+func (mv *MergeView) PrevConflict() {
+	mv.ShowConflict(mv.Cur - 1)
+}
+
+// TakeConflict resolves the current conflict by taking given side(s) of
+// it, replacing it in the buffer, as one operation for Undo, and shows the
+// next one
+func (mv *MergeView) TakeConflict(take MergeTake) {
+	mv.UpdateConflicts() // in case the buffer has been edited
+	if mv.Cur >= len(mv.Conflicts) {
+		mv.ShowConflict(mv.Cur)
+		return
+	}
+	tv := mv.TextView()
+	if tv == nil {
+		return
+	}
+	tb := tv.Buf
+	mc := &mv.Conflicts[mv.Cur]
+	rlns := mc.Resolved(take)
+	reg := giv.NewTextRegion(mc.St, 0, mc.Ed-1, len(tb.Line(mc.Ed-1)))
+	txt := strings.Join(rlns, "\n")
+	if len(rlns) == 0 && mc.Ed < tb.NumLines() {
+		reg = giv.NewTextRegion(mc.St, 0, mc.Ed, 0) // remove the lines
+	}
+	tv.SelectReset()
+	ReplaceRegion(tb, reg, []byte(txt))
+	mv.UpdateConflicts()
+	if len(mv.Conflicts) == 0 {
+		mv.ShowConflict(0)
+		tv.SetCursorShow(giv.TextPos{Ln: mc.St})
+		mv.Gide.SetStatus(fmt.Sprintf("All conflicts resolved in: %v -- save it to keep the result", mv.FileNode.Nm))
+		return
+	}
+	mv.ShowConflict(mv.Cur) // the next one is now at the same index
+}
+
+// MergeViewProps are style properties for MergeView
+var MergeViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
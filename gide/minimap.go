@@ -0,0 +1,224 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"time"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// MinimapWidth is the width of the minimap, in dots -- each column of text
+// is one dot wide, so this is also the number of columns shown
+var MinimapWidth = 80
+
+// MinimapLineHeight is the maximum height of each line in the minimap, in
+// dots -- lines are condensed further as needed to fit the whole buffer
+var MinimapLineHeight = 2
+
+// MinimapMarkWidth is the width of the search match and problem markers at
+// the right edge of the minimap, in dots
+var MinimapMarkWidth = 4
+
+// MinimapRefresh is the minimum interval between updates of the condensed
+// view of the buffer while it is being edited
+var MinimapRefresh = 500 * time.Millisecond
+
+// MinimapDiagColor is the color of the markers for lines with problems
+var MinimapDiagColor = color.RGBA{0xce, 0x42, 0x52, 0xff}
+
+// Minimap is a narrow strip next to a TextView, showing a condensed view of
+// its whole buffer, with the visible region highlighted, and markers for
+// search matches and problems reported in the command output (see Gide
+// DiagLines) -- click or drag in it to scroll the view
+type Minimap struct {
+	gi.WidgetBase
+	TextView *TextView   `json:"-" xml:"-" desc:"the text view shown in the minimap"`
+	Pixels   *image.RGBA `json:"-" xml:"-" view:"-" desc:"condensed view of the buffer, with markers"`
+	Updated  time.Time   `json:"-" xml:"-" desc:"when the Pixels were last updated"`
+	VisLns   [2]int      `json:"-" xml:"-" desc:"first and last visible lines of the view when last rendered"`
+}
+
+var KiT_Minimap = kit.Types.AddType(&Minimap{}, MinimapProps)
+
+var MinimapProps = ki.Props{
+	"min-width":        units.NewValue(float32(MinimapWidth), units.Dot),
+	"max-width":        units.NewValue(float32(MinimapWidth), units.Dot),
+	"max-height":       -1,
+	"margin":           units.NewValue(2, units.Px),
+	"color":            &gi.Prefs.Colors.Font,
+	"background-color": &gi.Prefs.Colors.Background,
+}
+
+// LineHeight returns the height of each line of the buffer, in dots, for
+// the current size
+func (mm *Minimap) LineHeight() float32 {
+	nln := mm.TextView.NLines
+	ht := float32(mm.VpBBox.Dy())
+	if nln == 0 || ht == 0 {
+		return float32(MinimapLineHeight)
+	}
+	lh := ht / float32(nln)
+	if lh > float32(MinimapLineHeight) {
+		lh = float32(MinimapLineHeight)
+	}
+	return lh
+}
+
+// LineAtY returns the buffer line at given y position relative to the top
+// of the minimap
+func (mm *Minimap) LineAtY(y int) int {
+	ln := int(float32(y) / mm.LineHeight())
+	if ln >= mm.TextView.NLines {
+		ln = mm.TextView.NLines - 1
+	}
+	if ln < 0 {
+		ln = 0
+	}
+	return ln
+}
+
+// ScrollToLine scrolls the text view to center given line in it
+func (mm *Minimap) ScrollToLine(ln int) {
+	tv := mm.TextView
+	if tv == nil || tv.NLines == 0 {
+		return
+	}
+	pos := tv.CharStartPos(giv.TextPos{Ln: ln})
+	tv.ScrollToVertCenter(int(pos.Y) + int(tv.LineHeight/2))
+}
+
+// ViewRendered is called after the text view is rendered, to update the
+// minimap if the visible region changed or the buffer may have been edited
+func (mm *Minimap) ViewRendered() {
+	tv := mm.TextView
+	if tv == nil || tv.NLines == 0 || mm.Viewport == nil {
+		return
+	}
+	st := tv.FirstVisibleLine(0)
+	vis := [2]int{st, tv.LastVisibleLine(st)}
+	if vis != mm.VisLns || time.Since(mm.Updated) > MinimapRefresh {
+		mm.VisLns = vis
+		mm.UpdateSig()
+	}
+}
+
+// RenderPixels renders the condensed view of the buffer into Pixels: a dot
+// per non-space character, with markers at the right edge
+func (mm *Minimap) RenderPixels(sz image.Point) {
+	if mm.Pixels == nil || mm.Pixels.Bounds().Size() != sz {
+		mm.Pixels = image.NewRGBA(image.Rectangle{Max: sz})
+	}
+	img := mm.Pixels
+	mm.Updated = time.Now()
+	draw.Draw(img, img.Bounds(), image.NewUniform(&mm.Sty.Font.BgColor.Color), image.ZP, draw.Src)
+	tv := mm.TextView
+	if tv == nil || tv.Buf == nil || tv.NLines == 0 {
+		return
+	}
+	fc := mm.Sty.Font.Color
+	fg := color.RGBA{fc.R, fc.G, fc.B, 0x90}
+	lh := mm.LineHeight()
+	tabsz := tv.Sty.Text.TabSize
+	if tabsz <= 0 {
+		tabsz = 4
+	}
+	txtw := sz.X - MinimapMarkWidth
+	nln := tv.Buf.NumLines()
+	for ln := 0; ln < nln; ln++ {
+		y0 := int(float32(ln) * lh)
+		y1 := int(float32(ln+1) * lh)
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+		if y0 >= sz.Y {
+			break
+		}
+		col := 0
+		for _, r := range tv.Buf.Line(ln) {
+			if col >= txtw {
+				break
+			}
+			if r == '\t' {
+				col += tabsz - col%tabsz
+				continue
+			}
+			if !unicode.IsSpace(r) {
+				for y := y0; y < y1; y++ {
+					img.Set(col, y, fg)
+				}
+			}
+			col++
+		}
+	}
+	mark := func(ln int, clr color.Color) {
+		y0 := int(float32(ln) * lh)
+		r := image.Rect(sz.X-MinimapMarkWidth, y0, sz.X, y0+2)
+		draw.Draw(img, r, image.NewUniform(clr), image.ZP, draw.Src)
+	}
+	for _, hr := range tv.Highlights {
+		mark(hr.Start.Ln, &gi.Prefs.Colors.Highlight)
+	}
+	if ge, ok := ParentGide(mm.This()); ok && tv.Buf.Filename != "" {
+		for _, ln := range ge.DiagLines(tv.Buf.Filename) {
+			mark(ln, MinimapDiagColor)
+		}
+	}
+}
+
+// Render2D renders the condensed view of the buffer, with the visible
+// region of the view highlighted
+func (mm *Minimap) Render2D() {
+	if mm.FullReRenderIfNeeded() {
+		return
+	}
+	if mm.PushBounds() {
+		mm.This().(gi.Node2D).ConnectEvents2D()
+		r := mm.VpBBox
+		sz := r.Size()
+		if mm.Pixels == nil || mm.Pixels.Bounds().Size() != sz || time.Since(mm.Updated) > MinimapRefresh {
+			mm.RenderPixels(sz)
+		}
+		draw.Draw(mm.Viewport.Pixels, r, mm.Pixels, image.ZP, draw.Src)
+		if tv := mm.TextView; tv != nil && tv.NLines > 0 {
+			lh := mm.LineHeight()
+			sc := gi.Prefs.Colors.Select
+			vr := image.Rect(r.Min.X, r.Min.Y+int(float32(mm.VisLns[0])*lh), r.Max.X, r.Min.Y+int(float32(mm.VisLns[1]+1)*lh))
+			draw.Draw(mm.Viewport.Pixels, vr.Intersect(r), image.NewUniform(color.RGBA{sc.R / 2, sc.G / 2, sc.B / 2, 0x80}), image.ZP, draw.Over)
+		}
+		mm.PopBounds()
+	} else {
+		mm.DisconnectAllEvents(gi.RegPri)
+	}
+}
+
+// ConnectEvents2D connects the mouse events for click and drag navigation
+func (mm *Minimap) ConnectEvents2D() {
+	mm.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		mmv := recv.Embed(KiT_Minimap).(*Minimap)
+		if me.Button != mouse.Left || me.Action != mouse.Press {
+			return
+		}
+		me.SetProcessed()
+		mmv.ScrollToLine(mmv.LineAtY(me.Where.Y - mmv.VpBBox.Min.Y))
+	})
+	mm.ConnectEvent(oswin.MouseDragEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.DragEvent)
+		mmv := recv.Embed(KiT_Minimap).(*Minimap)
+		me.SetProcessed()
+		mmv.ScrollToLine(mmv.LineAtY(me.Where.Y - mmv.VpBBox.Min.Y))
+	})
+}
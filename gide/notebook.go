@@ -0,0 +1,208 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsNotebook returns true if given file is a Jupyter notebook (.ipynb)
+func IsNotebook(fname string) bool {
+	return strings.ToLower(filepath.Ext(fname)) == ".ipynb"
+}
+
+// NbText is notebook text, which is either a string or a list of lines
+type NbText string
+
+// UnmarshalJSON accepts either a string or a list of strings (lines) --
+// other values (e.g., application/json output data) are left empty
+func (nt *NbText) UnmarshalJSON(b []byte) error {
+	var lns []string
+	if err := json.Unmarshal(b, &lns); err == nil {
+		*nt = NbText(strings.Join(lns, ""))
+		return nil
+	}
+	var s string
+	json.Unmarshal(b, &s)
+	*nt = NbText(s)
+	return nil
+}
+
+// NbOutput is an output of a notebook code cell
+type NbOutput struct {
+	OutputType string            `json:"output_type" desc:"stream, execute_result, display_data or error"`
+	Name       string            `json:"name" desc:"stdout or stderr for stream output"`
+	Text       NbText            `json:"text" desc:"stream output text"`
+	Data       map[string]NbText `json:"data" desc:"result data by mime type"`
+	ExecCount  int               `json:"execution_count" desc:"execution count for results"`
+	EName      string            `json:"ename" desc:"error name"`
+	EValue     string            `json:"evalue" desc:"error value"`
+}
+
+// NbCell is a cell of a notebook
+type NbCell struct {
+	CellType  string     `json:"cell_type" desc:"markdown, code or raw"`
+	Source    NbText     `json:"source" desc:"the source text of the cell"`
+	ExecCount int        `json:"execution_count" desc:"execution count of a code cell -- 0 if not run"`
+	Outputs   []NbOutput `json:"outputs" desc:"outputs of a code cell"`
+}
+
+// Notebook is a Jupyter notebook (nbformat 4)
+type Notebook struct {
+	Cells    []NbCell `json:"cells" desc:"the cells"`
+	Metadata struct {
+		KernelSpec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name          string `json:"name"`
+			FileExtension string `json:"file_extension"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+// OpenNotebook reads the notebook in given file
+func OpenNotebook(fname string) (*Notebook, error) {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	nb := &Notebook{}
+	if err := json.Unmarshal(b, nb); err != nil {
+		return nil, fmt.Errorf("%v is not a valid notebook: %v", fname, err)
+	}
+	return nb, nil
+}
+
+// Language returns the language of the code cells, e.g., python
+func (nb *Notebook) Language() string {
+	if nb.Metadata.LanguageInfo.Name != "" {
+		return strings.ToLower(nb.Metadata.LanguageInfo.Name)
+	}
+	if nb.Metadata.KernelSpec.Language != "" {
+		return strings.ToLower(nb.Metadata.KernelSpec.Language)
+	}
+	return "python"
+}
+
+// NbLangExts are the file extensions for code extracted from notebooks, by
+// language, with the line comment prefix
+var NbLangExts = map[string][2]string{
+	"python": {".py", "#"},
+	"go":     {".go", "//"},
+	"julia":  {".jl", "#"},
+	"r":      {".r", "#"},
+	"scala":  {".scala", "//"},
+	"ruby":   {".rb", "#"},
+	"bash":   {".sh", "#"},
+}
+
+// CodeExt returns the file extension and line comment prefix for code
+// extracted from the notebook
+func (nb *Notebook) CodeExt() (ext, comment string) {
+	if le, ok := NbLangExts[nb.Language()]; ok {
+		return le[0], le[1]
+	}
+	if nb.Metadata.LanguageInfo.FileExtension != "" {
+		return nb.Metadata.LanguageInfo.FileExtension, "#"
+	}
+	return ".txt", "#"
+}
+
+// Code returns the source of all the code cells, each preceded by a
+// comment with its execution count, as in the In [n] prompt
+func (nb *Notebook) Code() string {
+	_, cm := nb.CodeExt()
+	var sb strings.Builder
+	for _, c := range nb.Cells {
+		if c.CellType != "code" || strings.TrimSpace(string(c.Source)) == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("%v In [%v]:\n", cm, nbCount(c.ExecCount)))
+		sb.WriteString(strings.TrimRight(string(c.Source), "\n") + "\n")
+	}
+	return sb.String()
+}
+
+// nbCount returns the execution count as shown in the prompts
+func nbCount(n int) string {
+	if n <= 0 {
+		return " "
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// Render returns the lines of text and markup for showing the notebook as
+// a read-only sequence of cells: markdown source, then code with its
+// outputs -- non-text outputs (e.g., images) are noted by mime type
+func (nb *Notebook) Render() (lns, mus []string) {
+	add := func(ln, mu string) {
+		lns = append(lns, ln)
+		mus = append(mus, mu)
+	}
+	addText := func(txt, pfx string) {
+		for _, ln := range strings.Split(strings.TrimRight(txt, "\n"), "\n") {
+			add(pfx+ln, html.EscapeString(pfx+ln))
+		}
+	}
+	for i, c := range nb.Cells {
+		if i > 0 {
+			add("", "")
+		}
+		switch c.CellType {
+		case "markdown":
+			for _, ln := range strings.Split(strings.TrimRight(string(c.Source), "\n"), "\n") {
+				if strings.HasPrefix(ln, "#") {
+					add(ln, "<b>"+html.EscapeString(ln)+"</b>")
+				} else {
+					add(ln, html.EscapeString(ln))
+				}
+			}
+		case "code":
+			hd := fmt.Sprintf("In [%v]:", nbCount(c.ExecCount))
+			add(hd, "<b>"+hd+"</b>")
+			addText(string(c.Source), "    ")
+			for _, o := range c.Outputs {
+				switch o.OutputType {
+				case "stream":
+					addText(string(o.Text), "")
+				case "error":
+					ln := o.EName + ": " + o.EValue
+					add(ln, "<i>"+html.EscapeString(ln)+"</i>")
+				default:
+					if o.ExecCount > 0 {
+						hd := fmt.Sprintf("Out[%d]:", o.ExecCount)
+						add(hd, "<b>"+hd+"</b>")
+					}
+					if txt, ok := o.Data["text/plain"]; ok {
+						addText(string(txt), "")
+					}
+					var mts []string
+					for mt := range o.Data {
+						if mt != "text/plain" {
+							mts = append(mts, mt)
+						}
+					}
+					sort.Strings(mts)
+					for _, mt := range mts {
+						add("["+mt+" output]", "<i>["+html.EscapeString(mt)+" output]</i>")
+					}
+				}
+			}
+		default: // raw
+			addText(string(c.Source), "")
+		}
+	}
+	return
+}
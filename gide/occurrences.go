@@ -0,0 +1,196 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+)
+
+// OccurColor is the color of the underline of the occurrences of the
+// identifier at the cursor
+var OccurColor = gi.Color{0x4e, 0x9a, 0xe6, 0xff}
+
+// OccurMax is the maximum number of occurrences of the identifier at the
+// cursor that are highlighted
+var OccurMax = 1000
+
+// WordRegionAt returns the region of the identifier at given position, or
+// just before it, and false if there is none
+func WordRegionAt(lines [][]rune, pos giv.TextPos) (giv.TextRegion, bool) {
+	if pos.Ln < 0 || pos.Ln >= len(lines) {
+		return giv.TextRegion{}, false
+	}
+	lt := lines[pos.Ln]
+	ch := pos.Ch
+	if ch > len(lt) {
+		ch = len(lt)
+	}
+	if ch == len(lt) || !isWordRune(lt[ch]) {
+		if ch == 0 || !isWordRune(lt[ch-1]) {
+			return giv.TextRegion{}, false
+		}
+		ch--
+	}
+	st, ed := ch, ch+1
+	for st > 0 && isWordRune(lt[st-1]) {
+		st--
+	}
+	for ed < len(lt) && isWordRune(lt[ed]) {
+		ed++
+	}
+	return giv.NewTextRegion(pos.Ln, st, pos.Ln, ed), true
+}
+
+// ScanOccurrences returns the regions of the whole-word occurrences of the
+// word in the lines, skipping those in comments and strings, up to max
+func ScanOccurrences(lines [][]rune, word []rune, syn *BracketSyntax, max int) []giv.TextRegion {
+	var occs []giv.TextRegion
+	if len(word) == 0 {
+		return nil
+	}
+	scanCode(lines, syn, func(ln, ch int, r rune) {
+		if len(occs) >= max || r != word[0] {
+			return
+		}
+		lt := lines[ln]
+		if ch > 0 && isWordRune(lt[ch-1]) {
+			return
+		}
+		ed := ch + len(word)
+		if !runesAt(lt, ch, word) || (ed < len(lt) && isWordRune(lt[ed])) {
+			return
+		}
+		occs = append(occs, giv.NewTextRegion(ln, ch, ln, ed))
+	})
+	return occs
+}
+
+// UpdateOccurs finds the occurrences of the identifier at the cursor, if
+// HiOccurs is on and there is no selection other than the identifier, and
+// re-renders the lines of the occurrences no longer highlighted -- the
+// occurrences are only highlighted if the one at the cursor is in the code,
+// not in a comment or string
+func (tv *TextView) UpdateOccurs() {
+	if tv.Viewport == nil || tv.Viewport.Win == nil {
+		return
+	}
+	var occs []giv.TextRegion
+	if tv.HiOccurs && tv.Buf != nil && tv.Buf.NumLines() <= BracketMaxLines {
+		syn := BufBracketSyntax(tv.Buf)
+		tv.Buf.LinesMu.RLock()
+		if wr, ok := WordRegionAt(tv.Buf.Lines, tv.CursorPos); ok && (!tv.HasSelection() || regionSame(tv.SelectReg, wr)) {
+			word := tv.Buf.Lines[wr.Start.Ln][wr.Start.Ch:wr.End.Ch]
+			occs = ScanOccurrences(tv.Buf.Lines, word, &syn, OccurMax)
+			if !occursHave(occs, wr) || len(occs) < 2 {
+				occs = nil
+			}
+		}
+		tv.Buf.LinesMu.RUnlock()
+	}
+	old := tv.Occurs
+	tv.Occurs = occs
+	if len(old) == 0 {
+		return
+	}
+	updt := tv.Viewport.Win.UpdateStart()
+	for _, reg := range old {
+		tv.RenderLines(reg.Start.Ln, reg.Start.Ln)
+	}
+	tv.Viewport.Win.UpdateEnd(updt)
+}
+
+// occursHave returns true if the region is one of the occurrences
+func occursHave(occs []giv.TextRegion, reg giv.TextRegion) bool {
+	for _, oc := range occs {
+		if regionSame(oc, reg) {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderOccurs underlines the visible occurrences of the identifier at the
+// cursor in OccurColor
+func (tv *TextView) RenderOccurs() {
+	if len(tv.Occurs) == 0 || tv.NLines == 0 || tv.Viewport == nil || tv.VpBBox.Empty() {
+		return
+	}
+	if len(tv.Renders) < tv.NLines || len(tv.Offs) < tv.NLines {
+		return
+	}
+	st := tv.FirstVisibleLine(0)
+	ed := tv.LastVisibleLine(st)
+	img := tv.Viewport.Pixels
+	tbb := tv.VpBBox
+	tbb.Min.X += int(tv.LineNoOff)
+	chw := tv.Sty.Font.Face.Metrics.Ch
+	th := int(tv.Sty.Font.Size.Dots/8) + 1
+	for _, oc := range tv.Occurs {
+		if oc.Start.Ln < st || oc.Start.Ln > ed || oc.Start.Ln >= tv.NLines {
+			continue
+		}
+		pos := tv.CharStartPos(oc.Start)
+		ex := pos.X + chw*float32(oc.End.Ch-oc.Start.Ch)
+		if epos := tv.CharStartPos(oc.End); epos.Y == pos.Y && epos.X > pos.X {
+			ex = epos.X
+		}
+		y := int(pos.Y + tv.LineHeight)
+		ul := image.Rect(int(pos.X), y-th, int(ex+0.5), y).Intersect(tbb)
+		if !ul.Empty() {
+			draw.Draw(img, ul, image.NewUniform(OccurColor), image.ZP, draw.Src)
+		}
+	}
+}
+
+// curOccur returns the index of the occurrence at the cursor, or -1
+func (tv *TextView) curOccur() int {
+	cp := tv.CursorPos
+	for i, oc := range tv.Occurs {
+		if regionHas(oc, giv.NewTextRegionPos(cp, cp)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// NextOccur moves the cursor to the start of the next occurrence of the
+// identifier at the cursor, wrapping around to the first, and returns false
+// if there are none
+func (tv *TextView) NextOccur() bool {
+	tv.UpdateOccurs()
+	ci := tv.curOccur()
+	if ci < 0 {
+		return false
+	}
+	tv.gotoOccur(tv.Occurs[(ci+1)%len(tv.Occurs)])
+	return true
+}
+
+// PrevOccur moves the cursor to the start of the previous occurrence of the
+// identifier at the cursor, wrapping around to the last, and returns false
+// if there are none
+func (tv *TextView) PrevOccur() bool {
+	tv.UpdateOccurs()
+	ci := tv.curOccur()
+	if ci < 0 {
+		return false
+	}
+	n := len(tv.Occurs)
+	tv.gotoOccur(tv.Occurs[(ci+n-1)%n])
+	return true
+}
+
+// gotoOccur moves the cursor to the start of the occurrence, and redraws
+// the underlines
+func (tv *TextView) gotoOccur(oc giv.TextRegion) {
+	tv.SelectReset()
+	tv.SetCursorShow(oc.Start)
+	tv.UpdateOccurs()
+	tv.UpdateOverlays()
+}
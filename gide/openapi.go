@@ -0,0 +1,545 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// APINode is a node in the parsed tree of an OpenAPI (Swagger) document,
+// with the line where it starts, for reporting problems
+type APINode struct {
+	Key  string     `desc:"key of this node in its parent map, or index in its parent list"`
+	Val  string     `desc:"scalar value, if not a map or list"`
+	Ln   int        `desc:"line of the node (0-based)"`
+	Kids []*APINode `desc:"elements of a map or list"`
+}
+
+// Kid returns the child with given key, or nil
+func (an *APINode) Kid(key string) *APINode {
+	if an == nil {
+		return nil
+	}
+	for _, k := range an.Kids {
+		if k.Key == key {
+			return k
+		}
+	}
+	return nil
+}
+
+// Elems returns the elements of a map or list node, nil if the node is nil
+func (an *APINode) Elems() []*APINode {
+	if an == nil {
+		return nil
+	}
+	return an.Kids
+}
+
+// Path returns the node at given path of keys, or nil
+func (an *APINode) Path(keys ...string) *APINode {
+	for _, k := range keys {
+		an = an.Kid(k)
+	}
+	return an
+}
+
+// APIProblem is a problem found in an OpenAPI document by ValidateOpenAPI
+type APIProblem struct {
+	Ln  int    `desc:"line of the problem (0-based)"`
+	Msg string `desc:"what the problem is"`
+}
+
+// APIMethods are the http methods of OpenAPI path operations
+var APIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+var apiDocRe = regexp.MustCompile(`^\s*"?(openapi|swagger)"?\s*:`)
+
+// IsOpenAPI returns true if the file with given name and lines is an
+// OpenAPI (or Swagger) document: a yaml or json file with an openapi or
+// swagger top-level key
+func IsOpenAPI(fname string, lns []string) bool {
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return false
+	}
+	for i, ln := range lns {
+		if i > 50 {
+			break
+		}
+		if apiDocRe.MatchString(ln) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseOpenAPI parses the lines of an OpenAPI document, in json or yaml as
+// determined by the file name, into a tree of nodes -- only the subset of
+// yaml used for OpenAPI documents is supported (block maps and lists, with
+// flow collections kept as scalar values)
+func ParseOpenAPI(fname string, lns []string) (*APINode, error) {
+	if strings.ToLower(filepath.Ext(fname)) == ".json" {
+		return parseAPIJSON(strings.Join(lns, "\n"))
+	}
+	return parseAPIYAML(lns), nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+//  yaml
+
+var (
+	yamlKeyRe  = regexp.MustCompile(`^(\s*)(?:-\s+)?("[^"]*"|'[^']*'|[^\s#'"][^:#]*?)\s*:(?:\s+(.*))?$`)
+	yamlItemRe = regexp.MustCompile(`^(\s*)-(?:\s+(.*))?$`)
+)
+
+// yamlScalar cleans up a yaml scalar value: removes comments and quotes
+func yamlScalar(v string) string {
+	v = strings.TrimSpace(v)
+	if strings.HasPrefix(v, `"`) || strings.HasPrefix(v, "'") {
+		if e := strings.LastIndex(v, v[:1]); e > 0 {
+			return v[1:e]
+		}
+		return v
+	}
+	if ci := strings.Index(v, " #"); ci >= 0 {
+		v = strings.TrimSpace(v[:ci])
+	}
+	return v
+}
+
+func parseAPIYAML(lns []string) *APINode {
+	root := &APINode{Ln: 0}
+	type level struct {
+		ind  int
+		node *APINode
+	}
+	stack := []level{{-1, root}}
+	blockInd := -1 // indent of the key of a block scalar (| or >)
+	var block *APINode
+	for i, ln := range lns {
+		tl := strings.TrimSpace(ln)
+		if tl == "" || strings.HasPrefix(tl, "#") || tl == "---" {
+			continue
+		}
+		ind := len(ln) - len(strings.TrimLeft(ln, " "))
+		if blockInd >= 0 {
+			if ind > blockInd {
+				block.Val = strings.TrimSpace(block.Val + " " + tl)
+				continue
+			}
+			blockInd = -1
+		}
+		item := false
+		key, val := "", ""
+		if m := yamlKeyRe.FindStringSubmatch(ln); m != nil {
+			key = yamlScalar(m[2])
+			val = m[3]
+			item = strings.HasPrefix(strings.TrimLeft(ln, " "), "-")
+		} else if m := yamlItemRe.FindStringSubmatch(ln); m != nil {
+			item = true
+			val = m[2]
+		} else {
+			continue // continuation of a multi-line scalar
+		}
+		if item {
+			for len(stack) > 1 && stack[len(stack)-1].ind >= ind+1 {
+				stack = stack[:len(stack)-1]
+			}
+			par := stack[len(stack)-1].node
+			it := &APINode{Key: strconv.Itoa(len(par.Kids)), Ln: i}
+			par.Kids = append(par.Kids, it)
+			stack = append(stack, level{ind + 1, it})
+			if key == "" {
+				it.Val = yamlScalar(val)
+				continue
+			}
+			ind += len(ln[ind:]) - len(strings.TrimLeft(ln[ind+1:], " ")) // indent of key after "- "
+		}
+		for len(stack) > 1 && stack[len(stack)-1].ind >= ind {
+			stack = stack[:len(stack)-1]
+		}
+		par := stack[len(stack)-1].node
+		nd := &APINode{Key: key, Ln: i}
+		par.Kids = append(par.Kids, nd)
+		v := yamlScalar(val)
+		switch {
+		case v == "":
+			stack = append(stack, level{ind, nd})
+		case strings.HasPrefix(v, "|") || strings.HasPrefix(v, ">"):
+			blockInd = ind
+			block = nd
+		default:
+			nd.Val = v
+		}
+	}
+	return root
+}
+
+////////////////////////////////////////////////////////////////////////////
+//  json
+
+type apiJSONParser struct {
+	src string
+	pos int
+	ln  int
+}
+
+func parseAPIJSON(src string) (*APINode, error) {
+	p := &apiJSONParser{src: src}
+	nd, err := p.value("")
+	if err != nil {
+		return nil, err
+	}
+	p.space()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected text after end of document")
+	}
+	return nd, nil
+}
+
+func (p *apiJSONParser) errorf(f string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.ln+1, fmt.Sprintf(f, args...))
+}
+
+func (p *apiJSONParser) space() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '\n':
+			p.ln++
+		case ' ', '\t', '\r':
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+func (p *apiJSONParser) str() (string, error) {
+	st := p.pos
+	p.pos++ // opening quote
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case '\n':
+			return "", p.errorf("newline in string")
+		case '"':
+			p.pos++
+			s, err := strconv.Unquote(p.src[st:p.pos])
+			if err != nil {
+				return p.src[st+1 : p.pos-1], nil
+			}
+			return s, nil
+		}
+		p.pos++
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *apiJSONParser) value(key string) (*APINode, error) {
+	p.space()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of document")
+	}
+	nd := &APINode{Key: key, Ln: p.ln}
+	switch c := p.src[p.pos]; c {
+	case '{', '[':
+		obj := c == '{'
+		end := byte('}')
+		if !obj {
+			end = ']'
+		}
+		p.pos++
+		for {
+			p.space()
+			if p.pos < len(p.src) && p.src[p.pos] == end {
+				p.pos++
+				return nd, nil
+			}
+			k := strconv.Itoa(len(nd.Kids))
+			if obj {
+				if p.pos >= len(p.src) || p.src[p.pos] != '"' {
+					return nil, p.errorf("expected a key string")
+				}
+				var err error
+				if k, err = p.str(); err != nil {
+					return nil, err
+				}
+				p.space()
+				if p.pos >= len(p.src) || p.src[p.pos] != ':' {
+					return nil, p.errorf("expected : after key %q", k)
+				}
+				p.pos++
+			}
+			kd, err := p.value(k)
+			if err != nil {
+				return nil, err
+			}
+			nd.Kids = append(nd.Kids, kd)
+			p.space()
+			if p.pos < len(p.src) && p.src[p.pos] == ',' {
+				p.pos++
+				continue
+			}
+			if p.pos < len(p.src) && p.src[p.pos] == end {
+				continue
+			}
+			return nil, p.errorf("expected , or %c", end)
+		}
+	case '"':
+		s, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		nd.Val = s
+	default:
+		st := p.pos
+		for p.pos < len(p.src) && !strings.ContainsRune(",]}\n\r\t ", rune(p.src[p.pos])) {
+			p.pos++
+		}
+		nd.Val = p.src[st:p.pos]
+		if nd.Val == "" {
+			return nil, p.errorf("unexpected character %q", c)
+		}
+	}
+	return nd, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+//  validate
+
+// apiOps returns the operations (method nodes) of the given path node
+func apiOps(pn *APINode) []*APINode {
+	var ops []*APINode
+	for _, m := range APIMethods {
+		if op := pn.Kid(m); op != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+var apiPathParamRe = regexp.MustCompile(`\{([^}]+)\}`)
+
+// ValidateOpenAPI checks the structure of a parsed OpenAPI (3.x) or Swagger
+// (2.0) document: required fields, path operations with responses, unique
+// operation ids, declared path parameters, and local $ref targets
+func ValidateOpenAPI(root *APINode) []APIProblem {
+	var probs []APIProblem
+	add := func(ln int, f string, args ...interface{}) {
+		probs = append(probs, APIProblem{Ln: ln, Msg: fmt.Sprintf(f, args...)})
+	}
+	ver := root.Kid("openapi")
+	if ver == nil {
+		ver = root.Kid("swagger")
+	}
+	switch {
+	case ver == nil:
+		add(0, "missing openapi (or swagger) version field")
+	case ver.Key == "openapi" && !strings.HasPrefix(ver.Val, "3."):
+		add(ver.Ln, "unsupported openapi version: %q -- expected 3.x", ver.Val)
+	case ver.Key == "swagger" && ver.Val != "2.0":
+		add(ver.Ln, "unsupported swagger version: %q -- expected 2.0", ver.Val)
+	}
+	if info := root.Kid("info"); info == nil {
+		add(0, "missing info object")
+	} else {
+		for _, f := range []string{"title", "version"} {
+			if info.Kid(f) == nil {
+				add(info.Ln, "missing info.%v", f)
+			}
+		}
+	}
+	paths := root.Kid("paths")
+	if paths == nil {
+		add(0, "missing paths object")
+	}
+	opIds := make(map[string]int)
+	if paths != nil {
+		for _, pn := range paths.Kids {
+			if !strings.HasPrefix(pn.Key, "/") {
+				add(pn.Ln, "path must start with /: %v", pn.Key)
+			}
+			ops := apiOps(pn)
+			if len(ops) == 0 && pn.Kid("$ref") == nil {
+				add(pn.Ln, "path has no operations: %v", pn.Key)
+			}
+			params := map[string]bool{}
+			apiParamNames(pn.Kid("parameters"), params)
+			for _, op := range ops {
+				if op.Kid("responses") == nil || len(op.Kid("responses").Kids) == 0 {
+					add(op.Ln, "operation %v %v has no responses", strings.ToUpper(op.Key), pn.Key)
+				}
+				if id := op.Kid("operationId"); id != nil {
+					if pl, has := opIds[id.Val]; has {
+						add(id.Ln, "duplicate operationId %q (also at line %d)", id.Val, pl+1)
+					} else {
+						opIds[id.Val] = id.Ln
+					}
+				}
+				oparams := map[string]bool{}
+				for k := range params {
+					oparams[k] = true
+				}
+				apiParamNames(op.Kid("parameters"), oparams)
+				for _, m := range apiPathParamRe.FindAllStringSubmatch(pn.Key, -1) {
+					if !oparams[m[1]] && !apiParamsHaveRef(pn, op) {
+						add(op.Ln, "path parameter {%v} of %v %v is not declared in parameters", m[1], strings.ToUpper(op.Key), pn.Key)
+					}
+				}
+			}
+		}
+	}
+	apiCheckRefs(root, root, &probs)
+	sort.SliceStable(probs, func(i, j int) bool {
+		return probs[i].Ln < probs[j].Ln
+	})
+	return probs
+}
+
+// apiParamNames adds the names of the path parameters in given parameters
+// list to the map
+func apiParamNames(pl *APINode, names map[string]bool) {
+	if pl == nil {
+		return
+	}
+	for _, p := range pl.Kids {
+		if in := p.Kid("in"); in != nil && in.Val == "path" {
+			if nm := p.Kid("name"); nm != nil {
+				names[nm.Val] = true
+			}
+		}
+	}
+}
+
+// apiParamsHaveRef returns true if the parameters of the path or operation
+// include a $ref, which might declare path parameters
+func apiParamsHaveRef(pn, op *APINode) bool {
+	for _, n := range []*APINode{pn, op} {
+		if pl := n.Kid("parameters"); pl != nil {
+			for _, p := range pl.Kids {
+				if p.Kid("$ref") != nil {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// apiCheckRefs checks that all the local $ref targets (#/...) under given
+// node exist in the document
+func apiCheckRefs(root, an *APINode, probs *[]APIProblem) {
+	for _, k := range an.Kids {
+		if k.Key == "$ref" && strings.HasPrefix(k.Val, "#/") {
+			keys := strings.Split(k.Val[2:], "/")
+			for i, ky := range keys {
+				keys[i] = strings.Replace(strings.Replace(ky, "~1", "/", -1), "~0", "~", -1)
+			}
+			if root.Path(keys...) == nil {
+				*probs = append(*probs, APIProblem{Ln: k.Ln, Msg: fmt.Sprintf("$ref target not found: %v", k.Val)})
+			}
+		}
+		apiCheckRefs(root, k, probs)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+//  docs
+
+// APIDocLine is one line of the rendered documentation of an OpenAPI
+// document, with the document line it refers to
+type APIDocLine struct {
+	Text   string `desc:"plain text of the line"`
+	Markup string `desc:"html markup of the line"`
+	Ln     int    `desc:"line in the document that this refers to (0-based), or -1"`
+}
+
+// OpenAPIDocs returns the rendered documentation of an OpenAPI document: the
+// title and description, and a summary of each operation with its
+// parameters and responses
+func OpenAPIDocs(root *APINode) []APIDocLine {
+	var dl []APIDocLine
+	add := func(ln int, txt, mu string) {
+		dl = append(dl, APIDocLine{Text: txt, Markup: mu, Ln: ln})
+	}
+	esc := html.EscapeString
+	info := root.Kid("info")
+	ttl := "(untitled API)"
+	if t := info.Kid("title"); t != nil {
+		ttl = t.Val
+	}
+	if v := info.Kid("version"); v != nil {
+		ttl += " " + v.Val
+	}
+	add(-1, ttl, "<b><large>"+esc(ttl)+"</large></b>")
+	if d := info.Kid("description"); d != nil && d.Val != "" {
+		add(-1, d.Val, "<i>"+esc(d.Val)+"</i>")
+	}
+	for _, s := range root.Kid("servers").Elems() {
+		if u := s.Kid("url"); u != nil {
+			add(u.Ln, "Server: "+u.Val, "Server: <code>"+esc(u.Val)+"</code>")
+		}
+	}
+	if h := root.Kid("host"); h != nil {
+		bp := ""
+		if b := root.Kid("basePath"); b != nil {
+			bp = b.Val
+		}
+		add(h.Ln, "Server: "+h.Val+bp, "Server: <code>"+esc(h.Val+bp)+"</code>")
+	}
+	for _, pn := range root.Kid("paths").Elems() {
+		for _, op := range apiOps(pn) {
+			add(-1, "", "")
+			mth := strings.ToUpper(op.Key)
+			sum := ""
+			if s := op.Kid("summary"); s != nil {
+				sum = " -- " + s.Val
+			}
+			add(op.Ln, mth+" "+pn.Key+sum, "<b>"+mth+"</b> <code>"+esc(pn.Key)+"</code>"+esc(sum))
+			if d := op.Kid("description"); d != nil && d.Val != "" {
+				add(d.Ln, "    "+d.Val, "    <i>"+esc(d.Val)+"</i>")
+			}
+			for _, pl := range []*APINode{pn.Kid("parameters"), op.Kid("parameters")} {
+				if pl == nil {
+					continue
+				}
+				for _, p := range pl.Kids {
+					nm, in, req := p.Kid("name"), p.Kid("in"), p.Kid("required")
+					if nm == nil {
+						if r := p.Kid("$ref"); r != nil {
+							add(r.Ln, "    param: "+r.Val, "    param: <code>"+esc(r.Val)+"</code>")
+						}
+						continue
+					}
+					ps := nm.Val
+					if in != nil {
+						ps += " (" + in.Val + ")"
+					}
+					if req != nil && req.Val == "true" {
+						ps += " required"
+					}
+					add(p.Ln, "    param: "+ps, "    param: <code>"+esc(ps)+"</code>")
+				}
+			}
+			for _, r := range op.Kid("responses").Elems() {
+				rs := r.Key
+				if d := r.Kid("description"); d != nil {
+					rs += ": " + d.Val
+				}
+				add(r.Ln, "    response "+rs, "    response <b>"+esc(r.Key)+"</b>"+esc(strings.TrimPrefix(rs, r.Key)))
+			}
+		}
+	}
+	return dl
+}
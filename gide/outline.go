@@ -0,0 +1,210 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+)
+
+// OutlineFormats maps file extensions to the markup format used for parsing
+// the headings outline: "md" (Markdown), "rst" (reStructuredText), "adoc"
+// (AsciiDoc), or "tf" (Terraform / HCL blocks)
+var OutlineFormats = map[string]string{
+	".md":       "md",
+	".markdown": "md",
+	".rst":      "rst",
+	".rest":     "rst",
+	".adoc":     "adoc",
+	".asciidoc": "adoc",
+	".asc":      "adoc",
+	".tf":       "tf",
+	".hcl":      "tf",
+}
+
+// OutlineFormat returns the outline format for given file name, or "" if
+// headings outline is not supported for it
+func OutlineFormat(fname string) string {
+	return OutlineFormats[strings.ToLower(filepath.Ext(fname))]
+}
+
+// Heading is one section heading in a document outline
+type Heading struct {
+	Level int    `desc:"level of the heading, starting at 1 for top-level"`
+	Title string `desc:"title text of the heading"`
+	Ln    int    `desc:"first line of the heading (including any overline)"`
+	End   int    `desc:"line just after the end of the section started by this heading, including all sub-sections"`
+}
+
+var (
+	mdATXRe     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdFenceRe   = regexp.MustCompile("^\\s*(```|~~~)")
+	mdSetext1Re = regexp.MustCompile(`^=+\s*$`)
+	mdSetext2Re = regexp.MustCompile(`^-+\s*$`)
+	adocRe      = regexp.MustCompile(`^(=+)\s+(.*)$`)
+	adocDelimRe = regexp.MustCompile("^(----|\\.\\.\\.\\.|````)\\s*$")
+)
+
+// isRstAdorn returns true if the line is a reStructuredText section
+// adornment: a single punctuation char repeated, returning the char
+func isRstAdorn(ln string) (rune, bool) {
+	ln = strings.TrimRightFunc(ln, unicode.IsSpace)
+	if len(ln) < 2 {
+		return 0, false
+	}
+	c := rune(ln[0])
+	if !unicode.IsPunct(c) && !unicode.IsSymbol(c) {
+		return 0, false
+	}
+	for _, r := range ln {
+		if r != c {
+			return 0, false
+		}
+	}
+	return c, true
+}
+
+// Headings returns the section headings of the given lines of text, in the
+// given outline format -- the End of each heading is set to the start of the
+// next heading at the same or higher level (for "tf", the end of the block)
+func Headings(lns []string, format string) []Heading {
+	var hds []Heading
+	switch format {
+	case "tf":
+		return TerraformBlocks(lns)
+	case "md":
+		infence := false
+		for i, ln := range lns {
+			if mdFenceRe.MatchString(ln) {
+				infence = !infence
+				continue
+			}
+			if infence {
+				continue
+			}
+			if m := mdATXRe.FindStringSubmatch(ln); m != nil {
+				hds = append(hds, Heading{Level: len(m[1]), Title: m[2], Ln: i})
+				continue
+			}
+			if i+1 < len(lns) && strings.TrimSpace(ln) != "" && !strings.HasPrefix(ln, "    ") {
+				nl := lns[i+1]
+				switch {
+				case mdSetext1Re.MatchString(nl):
+					hds = append(hds, Heading{Level: 1, Title: strings.TrimSpace(ln), Ln: i})
+				case mdSetext2Re.MatchString(nl):
+					hds = append(hds, Heading{Level: 2, Title: strings.TrimSpace(ln), Ln: i})
+				}
+			}
+		}
+	case "rst":
+		var styles []string // adornment styles, in order of first use = level
+		for i := 0; i+1 < len(lns); i++ {
+			ttl := strings.TrimSpace(lns[i])
+			if ttl == "" {
+				continue
+			}
+			if _, adorn := isRstAdorn(lns[i]); adorn {
+				continue
+			}
+			c, ok := isRstAdorn(lns[i+1])
+			if !ok || len(strings.TrimSpace(lns[i+1])) < len(ttl) {
+				continue
+			}
+			st := i
+			sty := string(c)
+			if i > 0 {
+				if oc, over := isRstAdorn(lns[i-1]); over && oc == c {
+					st = i - 1
+					sty = "over" + sty
+				}
+			}
+			lev := 0
+			for si, s := range styles {
+				if s == sty {
+					lev = si + 1
+					break
+				}
+			}
+			if lev == 0 {
+				styles = append(styles, sty)
+				lev = len(styles)
+			}
+			hds = append(hds, Heading{Level: lev, Title: ttl, Ln: st})
+			i++ // skip underline
+		}
+	case "adoc":
+		indelim := false
+		for i, ln := range lns {
+			if adocDelimRe.MatchString(ln) {
+				indelim = !indelim
+				continue
+			}
+			if indelim {
+				continue
+			}
+			if m := adocRe.FindStringSubmatch(ln); m != nil {
+				hds = append(hds, Heading{Level: len(m[1]), Title: strings.TrimSpace(m[2]), Ln: i})
+			}
+		}
+	}
+	for i := range hds {
+		hds[i].End = len(lns)
+		for j := i + 1; j < len(hds); j++ {
+			if hds[j].Level <= hds[i].Level {
+				hds[i].End = hds[j].Ln
+				break
+			}
+		}
+	}
+	return hds
+}
+
+// BufLines returns the lines of the buffer as strings
+func BufLines(tb *giv.TextBuf) []string {
+	nl := tb.NumLines()
+	lns := make([]string, nl)
+	for i := 0; i < nl; i++ {
+		lns[i] = string(tb.Line(i))
+	}
+	return lns
+}
+
+// MoveSection moves the section of the given heading so that it starts at
+// line to in the buffer (which must be the start of another section, or the
+// end of the buffer), returning false if to is within the section itself.
+// The move is done as an insert and a delete, each of which can be undone.
+func MoveSection(tb *giv.TextBuf, hd Heading, to int) bool {
+	if to >= hd.Ln && to <= hd.End {
+		return false
+	}
+	nl := tb.NumLines()
+	lns := BufLines(tb)
+	sec := strings.Join(lns[hd.Ln:hd.End], "\n") + "\n"
+	st := giv.TextPos{Ln: hd.Ln}
+	ed := giv.TextPos{Ln: hd.End}
+	if hd.End >= nl { // last section -- take the newline before it instead
+		ed = tb.EndPos()
+		if hd.Ln > 0 {
+			st = giv.TextPos{Ln: hd.Ln - 1, Ch: len(tb.Line(hd.Ln - 1))}
+		}
+	}
+	ins := giv.TextPos{Ln: to}
+	if to >= nl { // moving to end
+		ins = tb.EndPos()
+		sec = "\n" + strings.TrimSuffix(sec, "\n")
+	}
+	if to > hd.Ln {
+		tb.InsertText(ins, []byte(sec), true, true)
+		tb.DeleteText(st, ed, true, true)
+	} else {
+		tb.DeleteText(st, ed, true, true)
+		tb.InsertText(ins, []byte(sec), true, true)
+	}
+	return true
+}
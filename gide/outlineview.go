@@ -0,0 +1,289 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/dnd"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/filecat"
+)
+
+// OutlineNode represents a section heading in a document outline -- the
+// name of the node is the title of the heading, and sub-sections are children
+type OutlineNode struct {
+	ki.Node
+	Head  Heading      `desc:"the heading for this section"`
+	ORoot *OutlineNode `json:"-" xml:"-" desc:"root of the tree"`
+	View  *OutlineView `json:"-" xml:"-" desc:"the outline view -- only set on the root"`
+}
+
+var KiT_OutlineNode = kit.Types.AddType(&OutlineNode{}, ki.Props{"EnumType:Flag": ki.KiT_Flags})
+
+// OutlineView is a widget that displays the headings outline of a Markdown,
+// reStructuredText or AsciiDoc document, or the blocks of a Terraform file
+// (resources, variables, modules, etc), for jumping to sections, and
+// reordering them by drag-and-drop or the toolbar Up / Down actions
+type OutlineView struct {
+	gi.Layout
+	Gide     Gide             `json:"-" xml:"-" desc:"parent gide project"`
+	Buf      *giv.TextBuf     `json:"-" xml:"-" desc:"buffer that the outline is for"`
+	Format   string           `desc:"outline format of the buffer -- see OutlineFormats"`
+	Outline  OutlineNode      `desc:"root of the outline tree"`
+	TreeView *OutlineTreeView `json:"-" xml:"-" desc:"the tree view of the outline"`
+	Cur      *OutlineNode     `json:"-" xml:"-" desc:"currently-selected heading"`
+}
+
+var KiT_OutlineView = kit.Types.AddType(&OutlineView{}, OutlineViewProps)
+
+// Config configures the view for the buffer in the active text view
+func (ov *OutlineView) Config(ge Gide) {
+	ov.Gide = ge
+	ov.Lay = gi.LayoutVert
+	ov.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "outline-bar")
+	config.Add(gi.KiT_Frame, "outline-tree")
+	mods, updt := ov.ConfigChildren(config, false)
+	if !mods {
+		updt = ov.UpdateStart()
+	}
+	ov.ConfigToolbar()
+	tv := ge.ActiveTextView()
+	if tv != nil && tv.Buf != nil {
+		ov.Buf = tv.Buf
+		ov.Format = OutlineFormat(string(tv.Buf.Filename))
+		if ov.Format == "" {
+			ge.SetStatus(fmt.Sprintf("Outline is only available for Markdown, reStructuredText, AsciiDoc and Terraform files, not: %v", tv.Buf.Filename))
+		}
+	}
+	ov.ConfigTree()
+	ov.UpdateEnd(updt)
+}
+
+// OutlineBar returns the outline toolbar
+func (ov *OutlineView) OutlineBar() *gi.ToolBar {
+	return ov.ChildByName("outline-bar", 0).(*gi.ToolBar)
+}
+
+// OutlineTree returns the frame holding the outline tree
+func (ov *OutlineView) OutlineTree() *gi.Frame {
+	return ov.ChildByName("outline-tree", 1).(*gi.Frame)
+}
+
+// ConfigToolbar adds toolbar.
+func (ov *OutlineView) ConfigToolbar() {
+	obar := ov.OutlineBar()
+	if obar.HasChildren() {
+		return
+	}
+	obar.SetStretchMaxWidth()
+
+	obar.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "refresh the outline from the current contents of the buffer"},
+		ov.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			ovv, _ := recv.Embed(KiT_OutlineView).(*OutlineView)
+			ovv.ConfigTree()
+		})
+	obar.AddAction(gi.ActOpts{Label: "Up", Icon: "wedge-up", Tooltip: "move the selected section before the previous section at the same level"},
+		ov.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			ovv, _ := recv.Embed(KiT_OutlineView).(*OutlineView)
+			ovv.MoveUp()
+		})
+	obar.AddAction(gi.ActOpts{Label: "Down", Icon: "wedge-down", Tooltip: "move the selected section after the next section at the same level"},
+		ov.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			ovv, _ := recv.Embed(KiT_OutlineView).(*OutlineView)
+			ovv.MoveDown()
+		})
+}
+
+// OpenOutline rebuilds the outline tree from the buffer
+func (ov *OutlineView) OpenOutline() {
+	ot := &ov.Outline
+	ot.ORoot = ot
+	ot.View = ov
+	ot.DeleteChildren(true)
+	ov.Cur = nil
+	if ov.Buf == nil || ov.Format == "" {
+		return
+	}
+	hds := Headings(BufLines(ov.Buf), ov.Format)
+	stack := []*OutlineNode{ot}
+	for _, hd := range hds {
+		for len(stack) > 1 && stack[len(stack)-1].Head.Level >= hd.Level {
+			stack = stack[:len(stack)-1]
+		}
+		par := stack[len(stack)-1]
+		nm := strings.Replace(fmt.Sprintf("%v: %v", hd.Ln+1, hd.Title), "/", "-", -1) // no path seps
+		kid := par.AddNewChild(KiT_OutlineNode, nm)
+		on := kid.Embed(KiT_OutlineNode).(*OutlineNode)
+		on.Head = hd
+		on.ORoot = ot
+		stack = append(stack, on)
+	}
+}
+
+// ConfigTree configures the outline tree view, rebuilding the outline
+func (ov *OutlineView) ConfigTree() {
+	otree := ov.OutlineTree()
+	updt := otree.UpdateStart()
+	ov.OpenOutline()
+	if ov.TreeView != nil {
+		ov.TreeView.OpenAll()
+		ov.TreeView.FullRender2DTree()
+		otree.UpdateEnd(updt)
+		return
+	}
+	otree.SetProp("height", units.NewEm(5)) // enables scrolling
+	otree.SetStretchMaxWidth()
+	otree.SetStretchMaxHeight()
+	otv := otree.AddNewChild(KiT_OutlineTreeView, "outline").(*OutlineTreeView)
+	otv.SetRootNode(&ov.Outline)
+	ov.TreeView = otv
+	otv.TreeViewSig.Connect(ov.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if data == nil || sig != int64(giv.TreeViewSelected) {
+			return
+		}
+		tvn, _ := data.(ki.Ki).Embed(KiT_OutlineTreeView).(*OutlineTreeView)
+		if on := tvn.OutlineNode(); on != nil && on != on.ORoot {
+			ovv, _ := recv.Embed(KiT_OutlineView).(*OutlineView)
+			ovv.Cur = on
+			ovv.SelectHeading(on.Head)
+		}
+	})
+	otv.OpenAll()
+	otree.UpdateEnd(updt)
+}
+
+// SelectHeading moves the cursor to the given heading in the buffer
+func (ov *OutlineView) SelectHeading(hd Heading) {
+	if ov.Buf == nil {
+		return
+	}
+	ge := ov.Gide
+	tr := giv.NewTextRegion(hd.Ln, 0, hd.Ln, len(ov.Buf.Line(hd.Ln)))
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf != ov.Buf {
+		ge.OpenFileAtRegion(gi.FileName(ov.Buf.Filename), tr)
+		return
+	}
+	tv.UpdateStart()
+	tv.Highlights = tv.Highlights[:0]
+	tv.Highlights = append(tv.Highlights, tr)
+	tv.UpdateEnd(true)
+	tv.RefreshIfNeeded()
+	tv.SetCursorShow(tr.Start)
+	tv.GrabFocus()
+}
+
+// MoveHeading moves the section of given heading to start at line to,
+// rewriting the buffer, and refreshes the outline
+func (ov *OutlineView) MoveHeading(on *OutlineNode, to int) {
+	if ov.Buf == nil || on == nil || on == on.ORoot {
+		return
+	}
+	if !MoveSection(ov.Buf, on.Head, to) {
+		ov.Gide.SetStatus("Cannot move a section into itself")
+		return
+	}
+	ov.ConfigTree()
+}
+
+// MoveUp moves the currently-selected section before its previous sibling
+func (ov *OutlineView) MoveUp() {
+	if ov.Cur == nil {
+		return
+	}
+	idx, ok := ov.Cur.IndexInParent()
+	if !ok || idx == 0 {
+		return
+	}
+	prv := ov.Cur.Parent().Child(idx - 1).Embed(KiT_OutlineNode).(*OutlineNode)
+	ov.MoveHeading(ov.Cur, prv.Head.Ln)
+}
+
+// MoveDown moves the currently-selected section after its next sibling
+func (ov *OutlineView) MoveDown() {
+	if ov.Cur == nil {
+		return
+	}
+	idx, ok := ov.Cur.IndexInParent()
+	if !ok || idx+1 >= ov.Cur.Parent().NumChildren() {
+		return
+	}
+	nxt := ov.Cur.Parent().Child(idx + 1).Embed(KiT_OutlineNode).(*OutlineNode)
+	ov.MoveHeading(nxt, ov.Cur.Head.Ln)
+}
+
+// OutlineViewProps are style properties for OutlineView
+var OutlineViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+
+// OutlineTreeView is a TreeView of OutlineNodes, where dropping a section
+// onto another moves it before that section in the buffer
+type OutlineTreeView struct {
+	giv.TreeView
+}
+
+var KiT_OutlineTreeView = kit.Types.AddType(&OutlineTreeView{}, nil)
+
+func init() {
+	kit.Types.SetProps(KiT_OutlineTreeView, SymbolTreeViewProps)
+}
+
+// OutlineNode returns the SrcNode as an OutlineNode
+func (ot *OutlineTreeView) OutlineNode() *OutlineNode {
+	if ot.SrcNode == nil {
+		return nil
+	}
+	on := ot.SrcNode.Embed(KiT_OutlineNode)
+	if on == nil {
+		return nil
+	}
+	return on.(*OutlineNode)
+}
+
+// Drop moves the dropped section before this one in the buffer -- dropping
+// on the root moves it to the end -- the tree itself is rebuilt from the
+// buffer, so the drop is finalized as ignored
+func (ot *OutlineTreeView) Drop(md mimedata.Mimes, mod dnd.DropMods) {
+	ot.DragNDropFinalize(dnd.DropIgnore)
+	tgt := ot.OutlineNode()
+	if tgt == nil || ot.RootView == nil || ot.RootView.SrcNode == nil {
+		return
+	}
+	sroot := ot.RootView.SrcNode
+	for _, d := range md {
+		if d.Type != filecat.TextPlain {
+			continue
+		}
+		sn := sroot.FindPathUnique(string(d.Data))
+		if sn == nil {
+			continue
+		}
+		src := sn.Embed(KiT_OutlineNode).(*OutlineNode)
+		to := tgt.Head.Ln
+		if tgt == tgt.ORoot {
+			to = tgt.View.Buf.NumLines()
+		}
+		tgt.ORoot.View.MoveHeading(src, to)
+		return
+	}
+}
+
+// Dragged does nothing -- the outline is rebuilt from the buffer after a drop
+func (ot *OutlineTreeView) Dragged(de *dnd.Event) {
+}
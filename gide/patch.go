@@ -0,0 +1,260 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PatchNoFile is the file name in a patch for the missing side of a file
+// that is created or deleted by the patch
+const PatchNoFile = "/dev/null"
+
+// PatchHunk is a hunk of a unified diff: the lines starting at OldSt (from
+// 1) in the old file are replaced with the lines starting at NewSt in the
+// new file
+type PatchHunk struct {
+	OldSt int      `desc:"starting line in the old file, from 1"`
+	NewSt int      `desc:"starting line in the new file, from 1"`
+	Lines []string `desc:"lines of the hunk, with their ' ', '-' or '+' prefix"`
+}
+
+// PatchFile is the diff of one file in a patch
+type PatchFile struct {
+	OldName string      `desc:"name of the old file, after ---, or /dev/null if the file is created"`
+	NewName string      `desc:"name of the new file, after +++, or /dev/null if the file is deleted"`
+	Hunks   []PatchHunk `desc:"hunks of the diff"`
+}
+
+// OldLines returns the lines of the hunk in the old file
+func (ph *PatchHunk) OldLines() []string {
+	var lns []string
+	for _, ln := range ph.Lines {
+		if ln[0] != '+' {
+			lns = append(lns, ln[1:])
+		}
+	}
+	return lns
+}
+
+// NewLines returns the lines of the hunk in the new file
+func (ph *PatchHunk) NewLines() []string {
+	var lns []string
+	for _, ln := range ph.Lines {
+		if ln[0] != '-' {
+			lns = append(lns, ln[1:])
+		}
+	}
+	return lns
+}
+
+// IsNew returns true if the patch creates the file
+func (pf *PatchFile) IsNew() bool {
+	return pf.OldName == PatchNoFile
+}
+
+// IsDelete returns true if the patch deletes the file
+func (pf *PatchFile) IsDelete() bool {
+	return pf.NewName == PatchNoFile
+}
+
+// Name returns the name of the file changed by the patch
+func (pf *PatchFile) Name() string {
+	if pf.IsDelete() {
+		return pf.OldName
+	}
+	return pf.NewName
+}
+
+// Stats returns the number of lines added and deleted by the patch
+func (pf *PatchFile) Stats() (add, del int) {
+	for _, ph := range pf.Hunks {
+		for _, ln := range ph.Lines {
+			switch ln[0] {
+			case '+':
+				add++
+			case '-':
+				del++
+			}
+		}
+	}
+	return
+}
+
+// Path returns the path of the file changed by the patch, in the project
+// with given root: an absolute name is used as is, and the a/ and b/
+// prefixes of git diffs are removed if the file exists without them
+func (pf *PatchFile) Path(root string) string {
+	nm := pf.Name()
+	if filepath.IsAbs(nm) {
+		return nm
+	}
+	if strings.HasPrefix(nm, "a/") || strings.HasPrefix(nm, "b/") {
+		sp := filepath.Join(root, filepath.FromSlash(nm[2:]))
+		if _, err := os.Stat(sp); err == nil || pf.IsNew() {
+			return sp
+		}
+	}
+	return filepath.Join(root, filepath.FromSlash(nm))
+}
+
+// patchName returns the file name in a --- or +++ line of a patch, without
+// the date after a tab
+func patchName(ln string) string {
+	nm := ln[4:]
+	if ti := strings.Index(nm, "\t"); ti >= 0 {
+		nm = nm[:ti]
+	}
+	return strings.TrimSpace(nm)
+}
+
+// parseHunkRange parses a range, e.g., -12,7, in a hunk header, returning
+// its start and number of lines
+func parseHunkRange(rng string) (int, int, error) {
+	rng = rng[1:]
+	n := 1
+	if ci := strings.Index(rng, ","); ci >= 0 {
+		var err error
+		if n, err = strconv.Atoi(rng[ci+1:]); err != nil {
+			return 0, 0, err
+		}
+		rng = rng[:ci]
+	}
+	st, err := strconv.Atoi(rng)
+	return st, n, err
+}
+
+// ParsePatch parses a patch in the unified diff format, e.g., as shown in
+// the Diffs tab, or written by git diff, returning its files, in order --
+// any text before, between and after the diffs is ignored
+func ParsePatch(patch []byte) ([]*PatchFile, error) {
+	lns := strings.Split(strings.Replace(string(patch), "\r\n", "\n", -1), "\n")
+	var pfs []*PatchFile
+	var pf *PatchFile
+	var ph *PatchHunk
+	on, nn := 0, 0 // old and new lines remaining in the hunk
+	for i := 0; i < len(lns); i++ {
+		ln := lns[i]
+		if ph != nil && (on > 0 || nn > 0) {
+			if ln == "" {
+				ln = " " // blank context line, with its space trimmed
+			}
+			switch ln[0] {
+			case ' ':
+				on--
+				nn--
+			case '-':
+				on--
+			case '+':
+				nn--
+			case '\\': // \ No newline at end of file
+				continue
+			default:
+				return nil, fmt.Errorf("line %v: bad hunk line: %v", i+1, ln)
+			}
+			ph.Lines = append(ph.Lines, ln)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(ln, "--- ") && i+1 < len(lns) && strings.HasPrefix(lns[i+1], "+++ "):
+			pf = &PatchFile{OldName: patchName(ln), NewName: patchName(lns[i+1])}
+			pfs = append(pfs, pf)
+			ph = nil
+			i++
+		case strings.HasPrefix(ln, "@@ ") && pf != nil:
+			flds := strings.Fields(ln)
+			if len(flds) < 3 || flds[1][0] != '-' || flds[2][0] != '+' {
+				return nil, fmt.Errorf("line %v: bad hunk header: %v", i+1, ln)
+			}
+			ost, oln, err := parseHunkRange(flds[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %v: bad hunk header: %v", i+1, ln)
+			}
+			nst, nln, err := parseHunkRange(flds[2])
+			if err != nil {
+				return nil, fmt.Errorf("line %v: bad hunk header: %v", i+1, ln)
+			}
+			pf.Hunks = append(pf.Hunks, PatchHunk{OldSt: ost, NewSt: nst})
+			ph = &pf.Hunks[len(pf.Hunks)-1]
+			on, nn = oln, nln
+		}
+	}
+	if len(pfs) == 0 {
+		return nil, fmt.Errorf("no file diffs found in the patch")
+	}
+	return pfs, nil
+}
+
+// linesMatch returns true if the lines at given index match the lines
+func linesMatch(lns []string, at int, mlns []string) bool {
+	if at < 0 || at+len(mlns) > len(lns) {
+		return false
+	}
+	for i, ml := range mlns {
+		if lns[at+i] != ml {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the lines with the hunks of the patch applied -- a hunk
+// that is not at its line, e.g., because of other changes above it, is
+// applied at the nearest line where its old lines match -- returns an error
+// for the first hunk that does not match anywhere
+func (pf *PatchFile) Apply(lns []string) ([]string, error) {
+	res := append([]string{}, lns...)
+	off := 0 // offset of the lines in res from the old lines, from the hunks applied
+	for hi := range pf.Hunks {
+		ph := &pf.Hunks[hi]
+		olns := ph.OldLines()
+		st := ph.OldSt - 1 + off
+		if len(olns) == 0 {
+			st++ // the start is the line before an insertion
+		}
+		at := -1
+		for d := 0; d <= len(res); d++ {
+			if linesMatch(res, st-d, olns) {
+				at = st - d
+				break
+			}
+			if linesMatch(res, st+d, olns) {
+				at = st + d
+				break
+			}
+		}
+		if at < 0 {
+			return nil, fmt.Errorf("%v: hunk %v at line %v does not match", pf.Name(), hi+1, ph.OldSt)
+		}
+		nlns := ph.NewLines()
+		res = append(res[:at], append(nlns, res[at+len(olns):]...)...)
+		off = at - (ph.OldSt - 1) + len(nlns) - len(olns)
+		if len(olns) == 0 {
+			off--
+		}
+	}
+	return res, nil
+}
+
+// ApplyText returns the text with the patch applied (see Apply) -- a
+// created file ends with a newline
+func (pf *PatchFile) ApplyText(txt []byte) ([]byte, error) {
+	var lns []string
+	if !pf.IsNew() {
+		lns = strings.Split(string(txt), "\n")
+	}
+	res, err := pf.Apply(lns)
+	if err != nil {
+		return nil, err
+	}
+	if pf.IsNew() {
+		res = append(res, "")
+	}
+	return []byte(strings.Join(res, "\n")), nil
+}
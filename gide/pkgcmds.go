@@ -0,0 +1,153 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/pi/filecat"
+)
+
+// PkgCmdNames are the commands that test, build and benchmark the package in
+// a directory, for a language -- "" if the language has no such command
+type PkgCmdNames struct {
+	Test  CmdName `desc:"command that runs the tests of the package"`
+	Build CmdName `desc:"command that builds the package"`
+	Bench CmdName `desc:"command that runs the benchmarks of the package"`
+}
+
+// PkgCmds are the package commands of the languages whose directories are
+// packages, run from the Test / Build / Benchmark Package actions of the
+// folders in the file tree
+var PkgCmds = map[filecat.Supported]PkgCmdNames{
+	filecat.Go:     {"Test Go", "Build Go Dir", "Bench Go"},
+	filecat.Rust:   {"Test Rust", "Build Rust", "Bench Rust"},
+	filecat.Python: {"Test Python", "", ""},
+}
+
+// PkgManifests are the files that make a directory a package of a language,
+// even if it has no source files of its own
+var PkgManifests = map[string]filecat.Supported{
+	"Cargo.toml": filecat.Rust,
+}
+
+// DirPkgLang returns the language of the package in the directory, as the
+// language in PkgCmds with the most files in it, and the path of one of its
+// files, to run the package commands on -- NoSupport if none
+func DirPkgLang(dir string) (filecat.Supported, string) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return filecat.NoSupport, ""
+	}
+	cnts := map[filecat.Supported]int{}
+	fpaths := map[filecat.Supported]string{}
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		ls, ok := PkgManifests[fi.Name()]
+		if !ok {
+			ls = filecat.ExtSupported(filepath.Ext(fi.Name()))
+		}
+		if _, has := PkgCmds[ls]; !has {
+			continue
+		}
+		cnts[ls]++
+		if _, has := fpaths[ls]; !has {
+			fpaths[ls] = filepath.Join(dir, fi.Name())
+		}
+	}
+	lang := filecat.NoSupport
+	for ls, cnt := range cnts {
+		if lang == filecat.NoSupport || cnt > cnts[lang] || (cnt == cnts[lang] && ls < lang) {
+			lang = ls
+		}
+	}
+	return lang, fpaths[lang]
+}
+
+// PkgCmdFunc selects one of the package commands of a language
+type PkgCmdFunc func(pc PkgCmdNames) CmdName
+
+// PkgTestCmd selects the command that runs the tests of a package
+func PkgTestCmd(pc PkgCmdNames) CmdName { return pc.Test }
+
+// PkgBuildCmd selects the command that builds a package
+func PkgBuildCmd(pc PkgCmdNames) CmdName { return pc.Build }
+
+// PkgBenchCmd selects the command that runs the benchmarks of a package
+func PkgBenchCmd(pc PkgCmdNames) CmdName { return pc.Bench }
+
+// DirPkgCmd returns the package command selected by cmdFun for the package
+// in the directory, and the file to run it on -- false if none
+func DirPkgCmd(dir string, cmdFun PkgCmdFunc) (CmdName, string, bool) {
+	lang, fpath := DirPkgLang(dir)
+	if lang == filecat.NoSupport {
+		return "", "", false
+	}
+	cmdNm := cmdFun(PkgCmds[lang])
+	if cmdNm == "" {
+		return "", "", false
+	}
+	return cmdNm, fpath, true
+}
+
+// TestPkg runs the tests of the package in the selected folder
+func (ft *FileTreeView) TestPkg() {
+	ft.ExecPkgCmd(PkgTestCmd)
+}
+
+// BuildPkg builds the package in the selected folder
+func (ft *FileTreeView) BuildPkg() {
+	ft.ExecPkgCmd(PkgBuildCmd)
+}
+
+// BenchPkg runs the benchmarks of the package in the selected folder
+func (ft *FileTreeView) BenchPkg() {
+	ft.ExecPkgCmd(PkgBenchCmd)
+}
+
+// ExecPkgCmd runs the package command selected by cmdFun, for the language
+// of the package in the selected folder, in that folder -- its output is
+// shown in the tab of the command
+func (ft *FileTreeView) ExecPkgCmd(cmdFun PkgCmdFunc) {
+	sels := ft.SelectedViews()
+	if len(sels) == 0 {
+		return
+	}
+	ftv := sels[len(sels)-1].Embed(KiT_FileTreeView).(*FileTreeView)
+	fn := ftv.FileNode()
+	if fn == nil || !fn.IsDir() {
+		return
+	}
+	cmdNm, fpath, ok := DirPkgCmd(string(fn.FPath), cmdFun)
+	if !ok {
+		return
+	}
+	if ge, ok := ParentGide(fn.This()); ok {
+		ge.ExecCmdNameFileName(fpath, cmdNm, true, true)
+	}
+}
+
+// FileTreeActivePkgFunc returns an ActionUpdateFunc that activates the action
+// if the node is a folder with a package having the command selected by
+// cmdFun
+func FileTreeActivePkgFunc(cmdFun PkgCmdFunc) giv.ActionUpdateFunc {
+	return giv.ActionUpdateFunc(func(fni interface{}, act *gi.Action) {
+		ft := fni.(ki.Ki).Embed(KiT_FileTreeView).(*FileTreeView)
+		fn := ft.FileNode()
+		if fn != nil {
+			ok := false
+			if fn.IsDir() {
+				_, _, ok = DirPkgCmd(string(fn.FPath), cmdFun)
+			}
+			act.SetActiveState(ok)
+		}
+	})
+}
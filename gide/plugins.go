@@ -0,0 +1,142 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/key"
+)
+
+// PluginAction is an action added by a plugin -- it is shown in the Plugins
+// menu, and run by its Shortcut key chord if set, which takes precedence
+// over the standard key functions
+type PluginAction struct {
+	Label    string             `desc:"label of the action in the Plugins menu -- must be unique"`
+	Desc     string             `desc:"brief description"`
+	Shortcut key.Chord          `desc:"optional key chord that runs the action"`
+	Func     func(ge Gide)      `view:"-" json:"-" desc:"function that does the action"`
+	Plugin   *Plugin            `view:"-" json:"-" desc:"the plugin that added this action -- set by RegisterPlugin"`
+	Active   func(ge Gide) bool `view:"-" json:"-" desc:"optional function that returns false if the action is not currently available"`
+}
+
+// Plugin is an extension to gide: a Go package that calls RegisterPlugin
+// in its init function.  Plugins can be compiled into gide by importing the
+// package (e.g., with a blank import in cmd/gide), or built with go build
+// -buildmode=plugin and put in the PluginsDir, to be loaded at startup (on
+// platforms that support Go plugins).  Plugins use the Gide interface to
+// access the project, e.g., RecycleCmdTab to show output in a tab.
+type Plugin struct {
+	Name    string                           `desc:"name of the plugin"`
+	Desc    string                           `desc:"brief description"`
+	Cmds    Commands                         `desc:"commands added to the available commands -- custom commands of the same name take precedence"`
+	Actions []PluginAction                   `desc:"actions shown in the Plugins menu, optionally with key chords"`
+	OnProj  func(ge Gide)                    `view:"-" json:"-" desc:"called when a project is opened in a new window"`
+	OnOpen  func(ge Gide, fn *giv.FileNode)  `view:"-" json:"-" desc:"called when a file is opened in a buffer"`
+	OnSave  func(ge Gide, fpath gi.FileName) `view:"-" json:"-" desc:"called after a file is saved"`
+}
+
+// Plugins are the registered plugins, in order of registration
+var Plugins []*Plugin
+
+// RegisterPlugin registers given plugin -- call from the init function of
+// the plugin package
+func RegisterPlugin(pl *Plugin) {
+	for i := range pl.Actions {
+		pl.Actions[i].Plugin = pl
+	}
+	Plugins = append(Plugins, pl)
+	MergeAvailCmds()
+}
+
+// PluginsDir returns the per-user directory where plugins built with
+// -buildmode=plugin (.so files) are loaded from at startup
+func PluginsDir() string {
+	return filepath.Join(AppPrefsDir(), "plugins")
+}
+
+// LoadPlugins loads all the plugins in the PluginsDir, logging any errors
+// -- only supported on platforms with Go plugins (linux and mac, with cgo)
+func LoadPlugins() {
+	fls, _ := filepath.Glob(filepath.Join(PluginsDir(), "*.so"))
+	for _, fl := range fls {
+		if err := loadPlugin(fl); err != nil {
+			log.Printf("gide.LoadPlugins: could not load plugin: %v: %v\n", fl, err)
+		}
+	}
+}
+
+// PluginActionByLabel returns the plugin action with given label, or nil
+func PluginActionByLabel(label string) *PluginAction {
+	for _, pl := range Plugins {
+		for i := range pl.Actions {
+			if pl.Actions[i].Label == label {
+				return &pl.Actions[i]
+			}
+		}
+	}
+	return nil
+}
+
+// PluginActionForChord returns the plugin action with given shortcut key
+// chord, or nil
+func PluginActionForChord(kc key.Chord) *PluginAction {
+	if kc == "" {
+		return nil
+	}
+	for _, pl := range Plugins {
+		for i := range pl.Actions {
+			if pl.Actions[i].Shortcut == kc {
+				return &pl.Actions[i]
+			}
+		}
+	}
+	return nil
+}
+
+// PluginActionLabels returns the labels of all the plugin actions that are
+// active for given gide, for the Plugins menu
+func PluginActionLabels(ge Gide) []string {
+	var lbs []string
+	for _, pl := range Plugins {
+		for i := range pl.Actions {
+			pa := &pl.Actions[i]
+			if pa.Active == nil || pa.Active(ge) {
+				lbs = append(lbs, pa.Label)
+			}
+		}
+	}
+	return lbs
+}
+
+// PluginsProjOpened calls the OnProj hooks of all plugins
+func PluginsProjOpened(ge Gide) {
+	for _, pl := range Plugins {
+		if pl.OnProj != nil {
+			pl.OnProj(ge)
+		}
+	}
+}
+
+// PluginsFileOpened calls the OnOpen hooks of all plugins
+func PluginsFileOpened(ge Gide, fn *giv.FileNode) {
+	for _, pl := range Plugins {
+		if pl.OnOpen != nil {
+			pl.OnOpen(ge, fn)
+		}
+	}
+}
+
+// PluginsFileSaved calls the OnSave hooks of all plugins
+func PluginsFileSaved(ge Gide, fpath gi.FileName) {
+	for _, pl := range Plugins {
+		if pl.OnSave != nil {
+			pl.OnSave(ge, fpath)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,cgo darwin,cgo
+
+package gide
+
+import "plugin"
+
+// loadPlugin opens the Go plugin at given path, which registers itself in
+// its init function
+func loadPlugin(fpath string) error {
+	_, err := plugin.Open(fpath)
+	return err
+}
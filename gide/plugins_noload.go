@@ -0,0 +1,14 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux,!darwin !cgo
+
+package gide
+
+import "errors"
+
+// loadPlugin returns an error -- Go plugins are not supported on this platform
+func loadPlugin(fpath string) error {
+	return errors.New("plugins are not supported on this platform -- import the plugin package into cmd/gide instead")
+}
@@ -24,24 +24,44 @@ import (
 // FilePrefs contains file view preferences
 type FilePrefs struct {
 	DirsOnTop bool `desc:"if true, then all directories are placed at the top of the tree view -- otherwise everything is alpha sorted"`
+	VcsMove   bool `desc:"if true, files under version control that are moved by drag-and-drop in the file tree are moved using the version control system (e.g., git mv) -- otherwise they are just moved in the file system"`
 }
 
 // EditorPrefs contains editor preferences
 type EditorPrefs struct {
-	TabSize      int  `desc:"size of a tab, in chars -- also determines indent level for space indent"`
-	SpaceIndent  bool `desc:"use spaces for indentation, otherwise tabs"`
-	WordWrap     bool `desc:"wrap lines at word boundaries -- otherwise long lines scroll off the end"`
-	LineNos      bool `desc:"show line numbers"`
-	Completion   bool `desc:"use the completion system to suggest options while typing"`
-	SpellCorrect bool `desc:"suggest corrections for unknown words while typing"`
-	AutoIndent   bool `desc:"automatically indent lines when enter, tab, }, etc pressed"`
-	EmacsUndo    bool `desc:"use emacs-style undo, where after a non-undo command, all the current undo actions are added to the undo stack, such that a subsequent undo is actually a redo"`
-	DepthColor   bool `desc:"colorize the background according to nesting depth"`
+	TabSize      int             `desc:"size of a tab, in chars -- also determines indent level for space indent"`
+	SpaceIndent  bool            `desc:"use spaces for indentation, otherwise tabs"`
+	WordWrap     bool            `desc:"wrap lines at word boundaries -- otherwise long lines scroll off the end"`
+	LineNos      bool            `desc:"show line numbers"`
+	Completion   bool            `desc:"use the completion system to suggest options while typing"`
+	SpellCorrect bool            `desc:"suggest corrections for unknown words while typing"`
+	AutoIndent   bool            `desc:"automatically indent lines when enter, tab, }, etc pressed"`
+	EmacsUndo    bool            `desc:"use emacs-style undo, where after a non-undo command, all the current undo actions are added to the undo stack, such that a subsequent undo is actually a redo"`
+	DepthColor   bool            `desc:"colorize the background according to nesting depth"`
+	DocHover     bool            `desc:"show documentation for the symbol under the mouse when hovering (Go via gopls, Python via pydoc) -- off by default, as each hover runs a lookup"`
+	ProseWidth   int             `desc:"width in chars at which lines are soft-wrapped for file types viewed in prose mode (see Lang Opts)"`
+	ZenWidth     int             `desc:"maximum width in chars of the editor in distraction-free (zen) mode"`
+	Minimap      bool            `desc:"show a minimap next to each editor: a condensed view of the whole file, with the visible region highlighted and markers for search matches and problems -- click or drag in it to scroll"`
+	Rulers       []int           `desc:"columns at which vertical ruler lines are drawn in the editors, e.g., 80, 100, 120 -- can be set per language in the Lang Opts"`
+	LongLines    bool            `desc:"mark the part of lines that extends past the last (largest) ruler column"`
+	Whitespace   WhitespacePrefs `desc:"glyphs and color for showing spaces, tabs and line endings, with Toggle Whitespace in the View / Editor menu"`
+	Rainbow      bool            `desc:"color brackets by their nesting depth, with unmatched brackets in red -- brackets in comments and strings are skipped"`
+	HiOccurs     bool            `desc:"underline the other occurrences of the identifier at the cursor, skipping those in comments and strings -- Next / Prev Occurrence in the Navigate menu move among them"`
+	VcsGutter    bool            `desc:"mark the lines added, modified and deleted, in the colors of the ColorPreset, relative to the VCS HEAD version of the file in the gutter, updated when typing pauses -- click a marker to see the HEAD version of the lines and revert them"`
+	SpellInline  bool            `desc:"underline unknown words in comments and strings of code, and in all the text of prose (document and plain text) files, in the spelling language of the project, updated when typing pauses -- right-click an underlined word for suggestions, or to add it to the custom dictionary of the project"`
+	WordComplete bool            `desc:"offer the words of the open files and of the other files of the project in completion, after the completions of the language, if any -- so completion works in any language"`
+	Abbrevs      bool            `desc:"expand abbreviations, and correct common misspellings, when typed followed by a space, punctuation or enter -- see Edit Abbrevs in the preferences"`
+	Pairs        PairPrefs       `desc:"auto-pairing of brackets and quotes: the closing char is inserted with the opening one, typed over, and the selection is wrapped in the pair when an opening char is typed"`
+	TagEdit      bool            `desc:"in HTML and XML files, insert the end tag when the > of a start tag is typed, and rename the matching tag when the name of a tag is edited -- Jump To Matching Tag in the Navigate menu moves between them"`
+	ColorSwatch  bool            `desc:"show the colors of color literals (#rrggbb, rgb() and named colors) in CSS, HTML, XML and Go strings under them, and the first of each line in the gutter -- click it, or Pick Color in the context menu, to change the color with a color picker"`
+	DataCheck    bool            `desc:"check the syntax of JSON, YAML and TOML files when typing pauses, and well-known config files against their JSON Schemas (see Schemas), with the problems found in the Problems tab, and the Structure tab updated -- see the Data submenu of the Command menu"`
 }
 
 // Preferences are the overall user preferences for Gide.
 type Preferences struct {
 	HiStyle      histyle.StyleName `desc:"highilighting style / theme"`
+	ColorPreset  ColorPresets      `desc:"colors for diffs, problems and version control decorations: Default, HighContrast, or Deuteranopia, which stays distinct with red-green color blindness"`
+	StyleColors  StyleColorPresets `desc:"color presets for particular highlighting styles / themes, overriding ColorPreset when that style is in use, e.g., HighContrast for a low-contrast dark theme"`
 	FontFamily   gi.FontName       `desc:"monospaced font family for editor"`
 	Files        FilePrefs         `desc:"file view preferences"`
 	Editor       EditorPrefs       `view:"inline" desc:"editor preferences"`
@@ -49,6 +69,15 @@ type Preferences struct {
 	SaveKeyMaps  bool              `desc:"if set, the current available set of key maps is saved to your preferences directory, and automatically loaded at startup -- this should be set if you are using custom key maps, but it may be safer to keep it <i>OFF</i> if you are <i>not</i> using custom key maps, so that you'll always have the latest compiled-in standard key maps with all the current key functions bound to standard key chords"`
 	SaveLangOpts bool              `desc:"if set, the current customized set of language options (see Edit Lang Opts) is saved / loaded along with other preferences -- if not set, then you always are using the default compiled-in standard set (which will be updated)"`
 	SaveCmds     bool              `desc:"if set, the current customized set of command parameters (see Edit Cmds) is saved / loaded along with other preferences -- if not set, then you always are using the default compiled-in standard set (which will be updated)"`
+	MaxCmds      int               `min:"0" desc:"maximum number of commands that run at the same time in a project -- further runs are queued, and listed in the Jobs tab, until others finish -- 0 for no limit"`
+	JournalSecs  int               `min:"0" desc:"number of seconds between crash-recovery journal snapshots of unsaved buffers and session state -- if gide does not exit normally, you are offered to recover the previous session when the project is next opened -- 0 = off"`
+	RestoreProjs bool              `desc:"if set, all the project windows that were open when gide last quit are reopened when it is started without a project or path to open"`
+	SaveClipHist bool              `desc:"if set, the clipboard history of the copies and cuts in all the editors, shown by Clipboard History in the Edit menu, is saved when gide quits and restored at startup -- otherwise it is kept for the session only"`
+	IdleLockMins int               `min:"0" desc:"number of minutes without key presses or mouse clicks after which open projects are locked: the editors are blanked until the lock passphrase is entered -- 0 = off -- requires a passphrase, set by Set Lock Passphrase in the View menu"`
+	LockPass     string            `view:"-" desc:"salted hash of the lock passphrase for IdleLockMins -- the passphrase itself is not saved"`
+	Updates      UpdatePrefs       `desc:"settings for checking for new gide releases, with Check For Updates in the Help menu"`
+	Schemas      SchemaPrefs       `desc:"completion, checking and hover docs of well-known JSON, YAML and TOML config files from their JSON Schemas, downloaded from a catalog"`
+	Dialogs      DialogPrefs       `desc:"how the non-critical prompts, e.g., that an auto-save file exists, are shown: as modal dialogs right away, deferred until typing pauses so they do not swallow keystrokes, or as non-modal notices in the Notices tab"`
 	Changed      bool              `view:"-" changeflag:"+" json:"-" xml:"-" desc:"flag that is set by StructView by virtue of changeflag tag, whenever an edit is made.  Used to drive save menus etc."`
 }
 
@@ -75,6 +104,9 @@ func InitPrefs() {
 	Prefs.Defaults()
 	Prefs.Open()
 	OpenPaths()
+	if Prefs.SaveClipHist {
+		OpenClipHist()
+	}
 	OpenIcons()
 	TheConsole.Init()
 	histyle.Init()
@@ -89,6 +121,7 @@ func InitPrefs() {
 // Defaults are the defaults for FilePrefs
 func (pf *FilePrefs) Defaults() {
 	pf.DirsOnTop = true
+	pf.VcsMove = true
 }
 
 // Defaults are the defaults for EditorPrefs
@@ -100,6 +133,19 @@ func (pf *EditorPrefs) Defaults() {
 	pf.SpellCorrect = true
 	pf.AutoIndent = true
 	pf.DepthColor = true
+	pf.HiOccurs = true
+	pf.VcsGutter = true
+	pf.SpellInline = true
+	pf.WordComplete = true
+	pf.Abbrevs = true
+	pf.TagEdit = true
+	pf.ColorSwatch = true
+	pf.DataCheck = true
+	pf.ProseWidth = 72
+	pf.ZenWidth = 100
+	pf.Rulers = []int{80}
+	pf.Whitespace.Defaults()
+	pf.Pairs.Defaults()
 }
 
 // ConfigTextBuf sets TextBuf Opts according to prefs
@@ -115,6 +161,30 @@ func (pf *EditorPrefs) ConfigTextBuf(tb *giv.TextBuf) {
 	tb.ConfigSupported()
 }
 
+// ViewOpts returns the view options of the editor prefs, which are the
+// starting point for per-file overrides
+func (pf *EditorPrefs) ViewOpts() ViewOpts {
+	return ViewOpts{WordWrap: pf.WordWrap, LineNos: pf.LineNos, TabSize: pf.TabSize}
+}
+
+// ViewOpts are per-file overrides of the editor prefs for how a file is
+// viewed, set from the View menu and saved in the project prefs
+type ViewOpts struct {
+	WordWrap bool `desc:"wrap lines at word boundaries -- otherwise long lines scroll off the end"`
+	LineNos  bool `desc:"show line numbers"`
+	TabSize  int  `desc:"size of a tab, in chars -- also determines indent level for space indent"`
+}
+
+// ConfigTextBuf sets TextBuf Opts according to the view options
+func (vo *ViewOpts) ConfigTextBuf(tb *giv.TextBuf) {
+	tb.Opts.TabSize = vo.TabSize
+	tb.Opts.LineNos = vo.LineNos
+}
+
+// ViewOptsMap holds the per-file view option overrides, keyed by the file
+// path relative to the project root
+type ViewOptsMap map[string]*ViewOpts
+
 // Defaults are the defaults for Preferences
 func (pf *Preferences) Defaults() {
 	pf.HiStyle = "emacs"
@@ -122,6 +192,9 @@ func (pf *Preferences) Defaults() {
 	pf.Files.Defaults()
 	pf.Editor.Defaults()
 	pf.KeyMap = DefaultKeyMap
+	pf.JournalSecs = 30
+	pf.Schemas.Defaults()
+	pf.Dialogs.Defaults()
 }
 
 // PrefsFileName is the name of the preferences file in GoGi prefs directory
@@ -135,6 +208,7 @@ func (pf *Preferences) Apply() {
 	MergeAvailCmds()
 	AvailLangs.Validate()
 	histyle.StyleDefault = pf.HiStyle
+	ApplyColorPreset(pf.ColorPresetFor(pf.HiStyle))
 }
 
 // Open preferences from GoGi standard prefs directory, and applies them
@@ -157,6 +231,8 @@ func (pf *Preferences) Open() error {
 	}
 	AvailSplits.OpenPrefs()
 	AvailRegisters.OpenPrefs()
+	AvailTemplates.OpenPrefs()
+	AvailAbbrevs.OpenPrefs()
 	pf.Apply()
 	pf.Changed = false
 	return err
@@ -186,6 +262,8 @@ func (pf *Preferences) Save() error {
 	}
 	AvailSplits.SavePrefs()
 	AvailRegisters.SavePrefs()
+	AvailTemplates.SavePrefs()
+	AvailAbbrevs.SavePrefs()
 	pf.Changed = false
 	return err
 }
@@ -230,6 +308,18 @@ func (pf *Preferences) EditRegisters() {
 	RegistersView(&AvailRegisters)
 }
 
+// EditTemplates opens the TemplatesView editor to customize the templates
+// for new files
+func (pf *Preferences) EditTemplates() {
+	TemplatesView(&AvailTemplates)
+}
+
+// EditAbbrevs opens the AbbrevsView editor to customize the abbreviations
+// expanded when typed
+func (pf *Preferences) EditAbbrevs() {
+	AbbrevsView(&AvailAbbrevs)
+}
+
 // EditHiStyles opens the HiStyleView editor to customize highlighting styles
 func (pf *Preferences) EditHiStyles() {
 	giv.HiStylesView(&histyle.CustomStyles)
@@ -295,6 +385,14 @@ var PreferencesProps = ki.Props{
 			"icon": "file-binary",
 			"desc": "opens the RegistersView editor of saved named text registers.  Current values are saved and loaded with preferences automatically.",
 		}},
+		{"EditTemplates", ki.Props{
+			"icon": "file-text",
+			"desc": "opens the TemplatesView editor of templates for the initial contents of new files.  Current values are saved and loaded with preferences automatically.",
+		}},
+		{"EditAbbrevs", ki.Props{
+			"icon": "file-text",
+			"desc": "opens the AbbrevsView editor of abbreviations, and common misspellings, that are expanded when typed.  Current values are saved and loaded with preferences automatically.",
+		}},
 		{"EditHiStyles", ki.Props{
 			"icon": "file-binary",
 			"desc": "opens the HiStylesView editor of highlighting styles.",
@@ -310,6 +408,8 @@ type ProjPrefs struct {
 	Files        FilePrefs         `desc:"file view preferences"`
 	Editor       EditorPrefs       `view:"inline" desc:"editor preferences"`
 	SplitName    SplitName         `desc:"current named-split config in use for configuring the splitters"`
+	HiStyle      histyle.StyleName `desc:"highlighting style / theme for this project -- overrides the global HiStyle in the gide prefs if set, e.g., to make projects visually distinct"`
+	EditorBg     gi.Color          `desc:"background color of the editors for this project -- overrides the default background color if set (not transparent)"`
 	MainLang     filecat.Supported `desc:"the language associated with the most frequently-encountered file extension in the file tree -- can be manually set here as well"`
 	VersCtrl     giv.VersCtrlName  `desc:"the type of version control system used in this project (git, svn, etc) -- filters commands available"`
 	ProjFilename gi.FileName       `ext:".gide" desc:"current project filename for saving / loading specific Gide configuration information in a .gide file (optional)"`
@@ -319,12 +419,25 @@ type ProjPrefs struct {
 	BuildTarg    gi.FileName       `desc:"build target for main Build button, if relevant for your  BuildCmds"`
 	RunExec      gi.FileName       `desc:"executable to run for this project via main Run button -- called by standard Run Proj command"`
 	RunCmds      CmdNames          `desc:"command(s) to run for main Run button (typically Run Proj)"`
+	LinkPanel    LinkPanels        `desc:"which text view opens the files of links, e.g., find results, errors in the command output, and go to definition: the second one, next to the tabs where links are clicked (the default), the one edited least recently, so the file being worked on stays in view, the one other than the active one, or the active one"`
+	AssetsDir    string            `desc:"directory where images pasted into Markdown files are saved, with a link to them inserted at the cursor -- relative to the Markdown file unless absolute -- default is assets"`
+	SQL          SQLPrefs          `desc:"database for the .sql files in the project, for completion of table and column names, and Explain Query"`
+	Release      ReleasePrefs      `desc:"release build settings: cross-compile targets, archives, checksums and upload command, for Build Release"`
+	Crypt        CryptPrefs        `desc:"keys for editing age and sops encrypted files, which are decrypted on open and re-encrypted on save"`
 	Find         FindParams        `view:"-" desc:"saved find params"`
 	Spell        SpellParams       `view:"-" desc:"saved spell params"`
 	Symbols      SymbolsParams     `view:"-" desc:"saved structure params"`
+	CmdFiles     CmdFilesParams    `view:"-" desc:"saved Run Command on Files params"`
 	OpenDirs     giv.OpenDirMap    `view:"-" desc:"open directories"`
 	Register     RegisterName      `view:"-" desc:"last register used"`
 	Splits       []float32         `view:"-" desc:"current splitter splits"`
+	RecentFiles  gi.FilePaths      `view:"-" desc:"files recently viewed in this project, most recent first"`
+	ViewOpts     ViewOptsMap       `view:"-" desc:"per-file overrides of the editor word wrap, line numbers and tab size, set from the View menu"`
+	FilePos      FilePosMap        `view:"-" desc:"cursor and scroll positions of the files last viewed, restored when they are opened again"`
+	PromptHist   PromptHist        `view:"-" desc:"values entered for the prompts of the commands, most recent first, offered the next time they are run"`
+	ServeDir     gi.FileName       `view:"-" desc:"last directory served by Serve Project Directory"`
+	ServePort    int               `view:"-" desc:"last port used by Serve Project Directory"`
+	ServeReload  bool              `view:"-" desc:"last live reload setting used by Serve Project Directory"`
 	Changed      bool              `view:"-" changeflag:"+" json:"-" xml:"-" desc:"flag that is set by StructView by virtue of changeflag tag, whenever an edit is made.  Used to drive save menus etc."`
 }
 
@@ -407,3 +520,23 @@ func OpenPaths() {
 	SavedPaths.OpenJSON(pnm)
 	gi.StringsAddExtras((*[]string)(&SavedPaths), SavedPathsExtras)
 }
+
+// OpenProjsFileName is the name of the file in the prefs directory with the
+// projects that were open when gide last quit
+var OpenProjsFileName = "gide_open_projs.json"
+
+// SaveOpenProjs saves the paths of the projects open at quit, to the prefs
+// dir, for restoring at the next startup
+func SaveOpenProjs(pps gi.FilePaths) {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pps.SaveJSON(filepath.Join(pdir, OpenProjsFileName))
+}
+
+// OpenProjs returns the paths of the projects that were open when gide last
+// quit, from the prefs dir
+func OpenProjs() gi.FilePaths {
+	var pps gi.FilePaths
+	pdir := oswin.TheApp.AppPrefsDir()
+	pps.OpenJSON(filepath.Join(pdir, OpenProjsFileName))
+	return pps
+}
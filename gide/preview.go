@@ -0,0 +1,264 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io/ioutil"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// FontSpecimenSizes are the font sizes (in dots) of the sample text in a
+// font specimen sheet
+var FontSpecimenSizes = []int{12, 16, 24, 36, 48, 72}
+
+// FontSpecimenText is the sample text shown at each size in a font specimen
+var FontSpecimenText = "The quick brown fox jumps over the lazy dog"
+
+// FontSpecimenChars are the character sets shown at the top of a font
+// specimen sheet
+var FontSpecimenChars = []string{
+	"ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"abcdefghijklmnopqrstuvwxyz",
+	"0123456789 !\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~",
+}
+
+// FontSpecimen renders a specimen sheet for the font file (.ttf or .otf)
+// of given width: the FontSpecimenChars, then the FontSpecimenText at each
+// of the FontSpecimenSizes, labeled with its size, in the given colors
+func FontSpecimen(fpath string, width int, fg, bg color.Color) (*image.RGBA, error) {
+	type line struct {
+		face font.Face
+		txt  string
+		gap  int // space above line
+	}
+	var lns []line
+	faces := map[int]font.Face{}
+	face := func(sz int) (font.Face, error) {
+		if f, ok := faces[sz]; ok {
+			return f, nil
+		}
+		ff, err := gi.OpenFontFace(filepath.Base(fpath), fpath, sz, 0)
+		if err != nil {
+			return nil, fmt.Errorf("could not open font %v: %v", fpath, err)
+		}
+		faces[sz] = ff.Face
+		return ff.Face, nil
+	}
+	defer func() {
+		for _, f := range faces {
+			f.Close()
+		}
+	}()
+	cf, err := face(24)
+	if err != nil {
+		return nil, err
+	}
+	for _, cs := range FontSpecimenChars {
+		lns = append(lns, line{cf, cs, 0})
+	}
+	lf, _ := face(12)
+	for _, sz := range FontSpecimenSizes {
+		sf, err := face(sz)
+		if err != nil {
+			return nil, err
+		}
+		lns = append(lns, line{lf, fmt.Sprintf("%d", sz), 16}, line{sf, FontSpecimenText, 0})
+	}
+	const pad = 10
+	ht := pad
+	for _, ln := range lns {
+		ht += ln.gap + ln.face.Metrics().Height.Ceil()
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, ht+pad))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.ZP, draw.Src)
+	dr := &font.Drawer{Dst: img, Src: image.NewUniform(fg)}
+	y := pad
+	for _, ln := range lns {
+		m := ln.face.Metrics()
+		y += ln.gap
+		dr.Face = ln.face
+		dr.Dot = fixed.P(pad, y+m.Ascent.Ceil())
+		dr.DrawString(ln.txt)
+		y += m.Height.Ceil()
+	}
+	return img, nil
+}
+
+// AudioWave is the waveform of an audio file, mixed down to one channel
+type AudioWave struct {
+	Rate    int       `desc:"sample rate, in Hz"`
+	Chans   int       `desc:"number of channels in the file"`
+	Samples []float32 `desc:"the samples, mixed down to mono, in the range -1..1"`
+}
+
+// Duration returns the duration of the audio
+func (aw *AudioWave) Duration() time.Duration {
+	if aw.Rate == 0 {
+		return 0
+	}
+	return time.Duration(len(aw.Samples)) * time.Second / time.Duration(aw.Rate)
+}
+
+// ReadWAV decodes a WAV file with integer (8, 16, 24 or 32 bit) or float
+// (32 bit) PCM samples
+func ReadWAV(b []byte) (*AudioWave, error) {
+	if len(b) < 12 || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, errors.New("not a WAV file")
+	}
+	le := binary.LittleEndian
+	var fmtc, data []byte
+	for p := 12; p+8 <= len(b); {
+		id := string(b[p : p+4])
+		sz := int(le.Uint32(b[p+4 : p+8]))
+		st := p + 8
+		ed := st + sz
+		if ed > len(b) || sz < 0 {
+			ed = len(b) // tolerate truncated files
+		}
+		switch id {
+		case "fmt ":
+			fmtc = b[st:ed]
+		case "data":
+			data = b[st:ed]
+		}
+		p = ed + sz%2 // chunks are padded to even size
+	}
+	if len(fmtc) < 16 || data == nil {
+		return nil, errors.New("WAV file has no format or data")
+	}
+	tag := le.Uint16(fmtc[0:2])
+	if tag == 0xFFFE && len(fmtc) >= 26 { // extensible: tag starts the sub format
+		tag = le.Uint16(fmtc[24:26])
+	}
+	aw := &AudioWave{Chans: int(le.Uint16(fmtc[2:4])), Rate: int(le.Uint32(fmtc[4:8]))}
+	bits := int(le.Uint16(fmtc[14:16]))
+	if aw.Chans < 1 {
+		return nil, errors.New("WAV file has no channels")
+	}
+	nb := bits / 8
+	var smp func(s []byte) float32
+	switch {
+	case tag == 1 && bits == 8:
+		smp = func(s []byte) float32 { return (float32(s[0]) - 128) / 128 }
+	case tag == 1 && bits == 16:
+		smp = func(s []byte) float32 { return float32(int16(le.Uint16(s))) / (1 << 15) }
+	case tag == 1 && bits == 24:
+		smp = func(s []byte) float32 {
+			return float32(int32(uint32(s[0])<<8|uint32(s[1])<<16|uint32(s[2])<<24)>>8) / (1 << 23)
+		}
+	case tag == 1 && bits == 32:
+		smp = func(s []byte) float32 { return float32(int32(le.Uint32(s))) / (1 << 31) }
+	case tag == 3 && bits == 32:
+		smp = func(s []byte) float32 { return math.Float32frombits(le.Uint32(s)) }
+	default:
+		return nil, fmt.Errorf("WAV format %v with %v bit samples is not supported", tag, bits)
+	}
+	fsz := nb * aw.Chans
+	aw.Samples = make([]float32, len(data)/fsz)
+	for i := range aw.Samples {
+		var sum float32
+		for c := 0; c < aw.Chans; c++ {
+			sum += smp(data[i*fsz+c*nb:])
+		}
+		aw.Samples[i] = sum / float32(aw.Chans)
+	}
+	return aw, nil
+}
+
+// AudioDecodeRate is the sample rate used for decoding non-WAV audio files
+// with ffmpeg, which is plenty for showing the waveform
+var AudioDecodeRate = 8000
+
+// OpenAudioWave reads the waveform of the audio file: WAV files are read
+// directly, and others (mp3, ogg, etc) are decoded with ffmpeg if available
+func OpenAudioWave(fpath string) (*AudioWave, error) {
+	if strings.ToLower(filepath.Ext(fpath)) == ".wav" {
+		b, err := ioutil.ReadFile(fpath)
+		if err != nil {
+			return nil, err
+		}
+		if aw, err := ReadWAV(b); err == nil {
+			return aw, nil
+		}
+	}
+	out, err := exec.Command("ffmpeg", "-v", "quiet", "-i", fpath, "-f", "s16le", "-ac", "1", "-ar", fmt.Sprintf("%d", AudioDecodeRate), "-").Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not decode %v (ffmpeg is needed for non-WAV files): %v", filepath.Base(fpath), err)
+	}
+	aw := &AudioWave{Rate: AudioDecodeRate, Chans: 1, Samples: make([]float32, len(out)/2)}
+	for i := range aw.Samples {
+		aw.Samples[i] = float32(int16(binary.LittleEndian.Uint16(out[2*i:]))) / (1 << 15)
+	}
+	return aw, nil
+}
+
+// Image renders the waveform into an image of given size, showing the range
+// of the samples in each column, in the given colors
+func (aw *AudioWave) Image(width, height int, fg, bg color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.ZP, draw.Src)
+	mid := height / 2
+	n := len(aw.Samples)
+	for x := 0; x < width; x++ {
+		st := x * n / width
+		ed := (x + 1) * n / width
+		mn, mx := float32(0), float32(0)
+		for _, s := range aw.Samples[st:ed] {
+			if s < mn {
+				mn = s
+			}
+			if s > mx {
+				mx = s
+			}
+		}
+		y0 := mid - int(mx*float32(mid))
+		y1 := mid - int(mn*float32(mid))
+		if y0 < 0 {
+			y0 = 0
+		}
+		for y := y0; y <= y1 && y < height; y++ {
+			img.Set(x, y, fg)
+		}
+	}
+	return img
+}
+
+// AudioPlayers are the programs tried in order to play audio files, with
+// their args before the file name
+var AudioPlayers = [][]string{
+	{"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet"},
+	{"afplay"},
+	{"paplay"},
+	{"aplay", "-q"},
+}
+
+// AudioPlayerCmd returns the command to play the audio file, using the
+// first of the AudioPlayers that is installed
+func AudioPlayerCmd(fpath string) (*exec.Cmd, error) {
+	for _, ap := range AudioPlayers {
+		if _, err := exec.LookPath(ap[0]); err == nil {
+			return exec.Command(ap[0], append(ap[1:len(ap):len(ap)], fpath)...), nil
+		}
+	}
+	var nms []string
+	for _, ap := range AudioPlayers {
+		nms = append(nms, ap[0])
+	}
+	return nil, fmt.Errorf("no audio player found -- install one of: %v", strings.Join(nms, ", "))
+}
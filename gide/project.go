@@ -0,0 +1,327 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/pi/filecat"
+)
+
+// Project is the state of a gide project that does not depend on the gui:
+// its root directory and project file, the project prefs, and the arg var
+// values and history of the commands run.  GideView embeds it, adding the
+// file tree, text views and other gui elements, and Batch uses it from the
+// command line -- it can also be used and tested on its own.
+type Project struct {
+	ProjRoot     gi.FileName `desc:"root directory for the project -- all projects must be organized within a top-level root directory, with all the files therein constituting the scope of the project -- by default it is the path for ProjFilename"`
+	ProjFilename gi.FileName `ext:".gide" desc:"current project filename for saving / loading specific Gide configuration information in a .gide file (optional)"`
+	Prefs        ProjPrefs   `desc:"preferences for this project -- this is what is saved in a .gide project file"`
+	ArgVals      ArgVarVals  `json:"-" xml:"-" desc:"current arg var vals"`
+	CmdHistory   CmdNames    `json:"-" desc:"history of commands executed in this session"`
+	Ops          ProjOps     `json:"-" xml:"-" desc:"history of the project-level operations that changed multiple files in this session, each of which can be undone as a whole"`
+}
+
+// IsEmpty returns true if no project has been opened yet
+func (pj *Project) IsEmpty() bool {
+	return pj.ProjRoot == ""
+}
+
+// ProjName returns the name of the project, which is the last directory of
+// the project root
+func (pj *Project) ProjName() string {
+	if pj.ProjRoot == "" {
+		return ""
+	}
+	return filepath.Base(string(pj.ProjRoot))
+}
+
+// Defaults sets new project defaults based on overall preferences
+func (pj *Project) Defaults() {
+	pj.Prefs.Files = Prefs.Files
+	pj.Prefs.Editor = Prefs.Editor
+	pj.Prefs.Splits = []float32{.1, .325, .325, .25, 0}
+}
+
+// OpenPath sets the project to the directory at given path, or the
+// directory of the file at given path, guessing its main language and
+// applying the defaults for it -- returns the name of the project and the
+// file name if the path is a file, and false if the path is not valid
+func (pj *Project) OpenPath(path string) (pnm, fnm string, ok bool) {
+	var root string
+	root, pnm, fnm, ok = ProjPathParse(path)
+	if !ok {
+		return
+	}
+	pj.ProjRoot = gi.FileName(root)
+	pj.Prefs.ProjFilename = gi.FileName(filepath.Join(root, pnm+".gide"))
+	pj.ProjFilename = pj.Prefs.ProjFilename
+	pj.Prefs.ProjRoot = pj.ProjRoot
+	pj.GuessMainLang()
+	pj.LangDefaults()
+	return
+}
+
+// OpenProj opens the .gide project file at given filename, setting the
+// project root from it (the directory of the file if not set) -- returns
+// the name of the project
+func (pj *Project) OpenProj(filename gi.FileName) (string, error) {
+	if err := pj.Prefs.OpenJSON(filename); err != nil {
+		return "", err
+	}
+	pj.Prefs.ProjFilename = filename // should already be set but..
+	if pj.Prefs.ProjRoot == "" {
+		pj.Prefs.ProjRoot = gi.FileName(filepath.Dir(string(filename)))
+	}
+	_, pnm, _, ok := ProjPathParse(string(pj.Prefs.ProjRoot))
+	if !ok {
+		return "", fmt.Errorf("gide.OpenProj: cannot open project root: %v", pj.Prefs.ProjRoot)
+	}
+	pj.ProjFilename = pj.Prefs.ProjFilename
+	pj.ProjRoot = pj.Prefs.ProjRoot
+	return pnm, nil
+}
+
+// SaveProjAs saves the project prefs to given .gide project file
+func (pj *Project) SaveProjAs(filename gi.FileName) error {
+	pj.Prefs.ProjFilename = filename
+	pj.ProjFilename = filename
+	return pj.Prefs.SaveJSON(filename)
+}
+
+// GuessMainLang guesses the main language of the project, as the supported
+// language with the most files -- returns true if successful
+func (pj *Project) GuessMainLang() bool {
+	cnts := map[filecat.Supported]int{}
+	pj.WalkFiles(func(path string, info os.FileInfo) {
+		if ls := filecat.ExtSupported(filepath.Ext(info.Name())); ls != filecat.NoSupport {
+			cnts[ls]++
+		}
+	})
+	if len(cnts) == 0 {
+		return false
+	}
+	lss := make([]filecat.Supported, 0, len(cnts))
+	for ls := range cnts {
+		lss = append(lss, ls)
+	}
+	sort.Slice(lss, func(i, j int) bool {
+		if cnts[lss[i]] != cnts[lss[j]] {
+			return cnts[lss[i]] > cnts[lss[j]]
+		}
+		return lss[i] < lss[j]
+	})
+	pj.Prefs.MainLang = lss[0]
+	return true
+}
+
+// LangDefaults applies default language settings based on MainLang
+func (pj *Project) LangDefaults() bool {
+	pj.Prefs.RunCmds = CmdNames{"Run Proj"}
+	pj.Prefs.BuildDir = pj.Prefs.ProjRoot
+	pj.Prefs.BuildTarg = pj.Prefs.ProjRoot
+	pj.Prefs.RunExec = gi.FileName(filepath.Join(string(pj.Prefs.ProjRoot), pj.ProjName()))
+	got := false
+	switch pj.Prefs.MainLang {
+	case filecat.Go:
+		pj.Prefs.BuildCmds = CmdNames{"Build Go Proj"}
+		got = true
+	case filecat.TeX:
+		pj.Prefs.BuildCmds = CmdNames{"LaTeX PDF"}
+		pj.Prefs.RunCmds = CmdNames{"Open Target File"}
+		got = true
+	default:
+		pj.Prefs.BuildCmds = CmdNames{"Make"}
+	}
+	return got
+}
+
+// BatchSkipDirs are directory names that are not searched by project Find
+// and Replace, in addition to hidden ones (starting with .)
+var BatchSkipDirs = []string{"node_modules", "vendor"}
+
+// WalkFiles calls fun for each regular file in the project, skipping hidden
+// directories and BatchSkipDirs, and auto-save files
+func (pj *Project) WalkFiles(fun func(path string, info os.FileInfo)) {
+	WalkProjFiles(string(pj.ProjRoot), fun)
+}
+
+// WalkProjFiles calls fun for each regular file under the project root,
+// skipping hidden directories and BatchSkipDirs, and auto-save files
+func WalkProjFiles(root string, fun func(path string, info os.FileInfo)) {
+	if root == "" {
+		return
+	}
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		nm := info.Name()
+		if info.IsDir() {
+			if path == root {
+				return nil
+			}
+			if strings.HasPrefix(nm, ".") {
+				return filepath.SkipDir
+			}
+			for _, sd := range BatchSkipDirs {
+				if nm == sd {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || strings.HasPrefix(nm, "#") {
+			return nil
+		}
+		fun(path, info)
+		return nil
+	})
+}
+
+// ProjFiles returns the paths of the text files in the project, of given
+// languages (all if empty), skipping hidden directories and BatchSkipDirs,
+// and auto-save and binary files
+func (pj *Project) ProjFiles(langs []filecat.Supported) []string {
+	var fls []string
+	pj.WalkFiles(func(path string, info os.FileInfo) {
+		if !filecat.IsMatchList(langs, filecat.ExtSupported(filepath.Ext(info.Name()))) {
+			return
+		}
+		if IsBinaryFile(path) {
+			return
+		}
+		fls = append(fls, path)
+	})
+	return fls
+}
+
+// FindFiles searches the project files of given languages (all if empty)
+// for the given string, returning the results sorted in descending order by
+// number of occurrences
+func (pj *Project) FindFiles(find string, ignoreCase bool, langs []filecat.Supported) []BatchFindResult {
+	if find == "" {
+		return nil
+	}
+	var res []BatchFindResult
+	for _, fp := range pj.ProjFiles(langs) {
+		cnt, matches := giv.FileSearch(fp, []byte(find), ignoreCase)
+		if cnt > 0 {
+			res = append(res, BatchFindResult{fp, cnt, matches})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Count > res[j].Count
+	})
+	return res
+}
+
+// RunCmd runs the named command for the given file (project root if empty),
+// writing its output to out, and adds it to the CmdHistory -- any prompt
+// strings used by the command must already be set in ArgVals -- returns an
+// error if the command is not found or fails
+func (pj *Project) RunCmd(name CmdName, fpath string, out io.Writer) error {
+	cmd, _, ok := AvailCmds.CmdByName(name, false)
+	if !ok {
+		return fmt.Errorf("command named: %v not found", name)
+	}
+	prs := map[string]string{}
+	for _, pv := range []string{"{PromptString1}", "{PromptString2}"} {
+		prs[pv] = pj.ArgVals[pv]
+	}
+	pj.ArgVals.Set(fpath, &pj.Prefs, nil)
+	for pv, val := range prs {
+		pj.ArgVals[pv] = val
+	}
+	if pvals, has := cmd.HasPrompts(); has {
+		for pv := range pvals {
+			if pj.ArgVals[pv] == "" {
+				return fmt.Errorf("command: %v needs a value for %v", name, pv)
+			}
+		}
+	}
+	pj.CmdHistory.Add(name)
+	ok = cmd.RunOut(&pj.ArgVals, out)
+	RemoveSelectionFile(pj.ArgVals["{SelectionPath}"])
+	if !ok {
+		return fmt.Errorf("command: %v failed", name)
+	}
+	return nil
+}
+
+// ProjPathParse parses given project path into a root directory (which could
+// be the path or just the directory portion of the path, depending in whether
+// the path is a directory or not), and a bool if all is good (otherwise error
+// message has been reported). projnm is always the last directory of the path.
+func ProjPathParse(path string) (root, projnm, fnm string, ok bool) {
+	if path == "" {
+		return "", "blank", "", false
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		emsg := fmt.Errorf("gide.ProjPathParse: Cannot open at given path: %q: Error: %v", path, err)
+		log.Println(emsg)
+		return
+	}
+	path, _ = filepath.Abs(path)
+	dir, fn := filepath.Split(path)
+	pathIsDir := info.IsDir()
+	if pathIsDir {
+		root = path
+	} else {
+		root = filepath.Clean(dir)
+		fnm = fn
+	}
+	_, projnm = filepath.Split(root)
+	ok = true
+	return
+}
+
+// CheckForProjAtPath checks if there is a .gide project at the given path
+// returns project path and true if found, otherwise false
+func CheckForProjAtPath(path string) (string, bool) {
+	root, pnm, _, ok := ProjPathParse(path)
+	if !ok {
+		return "", false
+	}
+	gproj := filepath.Join(root, pnm+".gide")
+	if _, err := os.Stat(gproj); os.IsNotExist(err) {
+		return "", false // does not exist
+	}
+	return gproj, true
+}
+
+// AppPrefsDir returns the gide prefs directory -- the same as
+// oswin.TheApp.AppPrefsDir() when running with the gui, and otherwise (in
+// batch mode) the standard location for the platform
+func AppPrefsDir() string {
+	if oswin.TheApp != nil {
+		return oswin.TheApp.AppPrefsDir()
+	}
+	usr, err := user.Current()
+	if err != nil {
+		log.Print(err)
+		return "/tmp"
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(usr.HomeDir, "Library", "gide")
+	case "windows":
+		return filepath.Join(usr.HomeDir, "AppData", "Roaming", "gide")
+	default:
+		return filepath.Join(usr.HomeDir, ".config", "gide")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/pi/filecat"
+)
+
+func TestProjectOpenSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "vendor"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\nfunc Foo() {}\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "b.go"), []byte("package a\n\nfunc Bar() { Foo() }\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# foo\n"), 0644)
+	for _, fn := range []string{"c.md", "d.md", "e.md"} {
+		ioutil.WriteFile(filepath.Join(dir, "vendor", fn), []byte("foo\n"), 0644)
+	}
+
+	var pj Project
+	if !pj.IsEmpty() {
+		t.Errorf("new project should be empty\n")
+	}
+	pj.Defaults()
+	pnm, fnm, ok := pj.OpenPath(filepath.Join(dir, "a.go"))
+	if !ok || pnm != filepath.Base(dir) || fnm != "a.go" {
+		t.Fatalf("open path error: got name: %v file: %v ok: %v\n", pnm, fnm, ok)
+	}
+	if pj.IsEmpty() || pj.ProjName() != pnm {
+		t.Errorf("open path error: project name should be: %v, was: %v\n", pnm, pj.ProjName())
+	}
+	if pj.Prefs.MainLang != filecat.Go {
+		t.Errorf("open path error: main lang should be Go (vendor skipped), was: %v\n", pj.Prefs.MainLang)
+	}
+	if len(pj.Prefs.BuildCmds) != 1 || pj.Prefs.BuildCmds[0] != "Build Go Proj" {
+		t.Errorf("lang defaults error: build cmds should be Build Go Proj, were: %v\n", pj.Prefs.BuildCmds)
+	}
+
+	res := pj.FindFiles("Foo", false, []filecat.Supported{filecat.Go})
+	if len(res) != 2 || res[0].Count != 1 || res[1].Count != 1 {
+		t.Errorf("find error: should have found 1 match in each of 2 go files, got: %v\n", res)
+	}
+
+	pj.Prefs.Find.Find = "Bar"
+	gproj := gi.FileName(filepath.Join(dir, pnm+".gide"))
+	if err := pj.SaveProjAs(gproj); err != nil {
+		t.Fatal(err)
+	}
+	var opj Project
+	onm, err := opj.OpenProj(gproj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if onm != pnm || opj.ProjRoot != pj.ProjRoot || opj.ProjFilename != gproj {
+		t.Errorf("open proj error: got name: %v root: %v file: %v\n", onm, opj.ProjRoot, opj.ProjFilename)
+	}
+	if opj.Prefs.MainLang != filecat.Go || opj.Prefs.Find.Find != "Bar" {
+		t.Errorf("open proj error: prefs not restored: main lang: %v find: %v\n", opj.Prefs.MainLang, opj.Prefs.Find.Find)
+	}
+
+	if err := opj.RunCmd("No Such Command", "", ioutil.Discard); err == nil {
+		t.Errorf("run cmd error: should have failed for unknown command\n")
+	}
+}
+
+func TestProjectRunCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-runcmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "sub", "a.go"), []byte("package a\n"), 0644)
+
+	svcmds := AvailCmds
+	defer func() { AvailCmds = svcmds }()
+	AvailCmds = Commands{
+		{Name: "Echo File", Cmds: []CmdAndArgs{{Cmd: "echo", Args: CmdArgs{"{FileName}", "{FileDirProjRel}", "{FileNameNoExt}.test", "\\{FileName}"}}}},
+		{Name: "Echo Prompt", Cmds: []CmdAndArgs{{Cmd: "echo", Args: CmdArgs{"{PromptString1}"}}}},
+		{Name: "Fail", Cmds: []CmdAndArgs{{Cmd: "false"}}},
+	}
+
+	var pj Project
+	pj.Defaults()
+	if _, _, ok := pj.OpenPath(dir); !ok {
+		t.Fatalf("open path error: could not open: %v\n", dir)
+	}
+	var out bytes.Buffer
+	if err := pj.RunCmd("Echo File", filepath.Join(dir, "sub", "a.go"), &out); err != nil {
+		t.Errorf("run cmd error: should have run, got: %v\n", err)
+	}
+	cv := "echo a.go sub a.test {FileName}"
+	if !strings.Contains(out.String(), "; "+cv+"\n") {
+		t.Errorf("run cmd error: args should have been expanded to: %v, output: %q\n", cv, out.String())
+	}
+	cv = "\na.go sub a.test {FileName}\n"
+	if !strings.Contains(out.String(), cv) {
+		t.Errorf("run cmd error: output should have had: %q, was: %q\n", cv, out.String())
+	}
+	if !strings.Contains(out.String(), "successful") {
+		t.Errorf("run cmd error: should have reported success, output: %q\n", out.String())
+	}
+	if len(pj.CmdHistory) != 1 || pj.CmdHistory[0] != "Echo File" {
+		t.Errorf("run cmd error: should have been added to the history, was: %v\n", pj.CmdHistory)
+	}
+
+	out.Reset()
+	if err := pj.RunCmd("Echo Prompt", "", &out); err == nil {
+		t.Errorf("run cmd error: should have failed without a value for {PromptString1}\n")
+	}
+	pj.ArgVals["{PromptString1}"] = "hello"
+	if err := pj.RunCmd("Echo Prompt", "", &out); err != nil || !strings.Contains(out.String(), "\nhello\n") {
+		t.Errorf("run cmd error: should have echoed the prompt string, got: %q, %v\n", out.String(), err)
+	}
+
+	if err := pj.RunCmd("Fail", "", ioutil.Discard); err == nil {
+		t.Errorf("run cmd error: should have failed for a failing command\n")
+	}
+}
@@ -0,0 +1,662 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goki/pi/filecat"
+)
+
+// ProjImport is what was imported into a project from the configs of other
+// IDEs and tools, by Project.ImportConfigs: the commands, to be added to the
+// custom commands, and notes on what was imported from where, and what was
+// skipped because it has no equivalent
+type ProjImport struct {
+	Cmds  Commands `desc:"commands imported from tasks, run configurations and Makefile targets"`
+	Notes []string `desc:"what was imported, or skipped, from which file"`
+	Build bool     `desc:"the Build commands of the project were set from an import"`
+	Run   bool     `desc:"the Run commands of the project were set from an import"`
+}
+
+// note adds a note on what was imported from given source file
+func (pi *ProjImport) note(src, format string, args ...interface{}) {
+	pi.Notes = append(pi.Notes, src+": "+fmt.Sprintf(format, args...))
+}
+
+// addCmd adds an imported command, replacing any of the same name
+func (pi *ProjImport) addCmd(cmd *Command) {
+	if _, idx, has := pi.Cmds.CmdByName(CmdName(cmd.Name), false); has {
+		pi.Cmds[idx] = cmd
+		return
+	}
+	pi.Cmds = append(pi.Cmds, cmd)
+}
+
+// ImportConfigs imports what it can from the configs of other IDEs and
+// tools in the project root: the editor settings of .vscode/settings.json
+// into the project prefs, and the tasks of .vscode/tasks.json, the run
+// configurations in .idea and the targets of the Makefile as commands --
+// the default build task, or else the first Makefile target, becomes the
+// Build command of the project, and the first run configuration its Run
+// command
+func (pj *Project) ImportConfigs() *ProjImport {
+	pi := &ProjImport{}
+	root := string(pj.ProjRoot)
+	pi.VSCodeSettings(filepath.Join(root, ".vscode", "settings.json"), &pj.Prefs)
+	pi.VSCodeTasks(filepath.Join(root, ".vscode", "tasks.json"), &pj.Prefs)
+	pi.IdeaRunConfigs(filepath.Join(root, ".idea"), &pj.Prefs)
+	pi.MakefileTargets(filepath.Join(root, "Makefile"), &pj.Prefs)
+	return pi
+}
+
+// AddCmds adds the commands, replacing those of the same name -- returns
+// the number added
+func (cm *Commands) AddCmds(cmds Commands) int {
+	n := 0
+	for _, cmd := range cmds {
+		if _, idx, has := cm.CmdByName(CmdName(cmd.Name), false); has {
+			(*cm)[idx] = cmd
+			continue
+		}
+		*cm = append(*cm, cmd)
+		n++
+	}
+	return n
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//  Translation
+
+// StripJSONComments returns the JSON with // and /* */ comments, and commas
+// just before a closing bracket, removed -- as allowed in the config files of
+// VS Code
+func StripJSONComments(b []byte) []byte {
+	var out bytes.Buffer
+	instr := false
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case instr:
+			out.WriteByte(c)
+			if c == '\\' && i+1 < len(b) {
+				i++
+				out.WriteByte(b[i])
+			} else if c == '"' {
+				instr = false
+			}
+		case c == '"':
+			instr = true
+			out.WriteByte(c)
+		case c == '/' && i+1 < len(b) && b[i+1] == '/':
+			for i < len(b) && b[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '/' && i+1 < len(b) && b[i+1] == '*':
+			ed := bytes.Index(b[i+2:], []byte("*/"))
+			if ed < 0 {
+				return out.Bytes()
+			}
+			i += ed + 3
+		case c == ',':
+			j := i + 1
+			for j < len(b) && (b[j] == ' ' || b[j] == '\t' || b[j] == '\n' || b[j] == '\r') {
+				j++
+			}
+			if j < len(b) && (b[j] == '}' || b[j] == ']') {
+				continue
+			}
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.Bytes()
+}
+
+// VSCodeVars are the variables of VS Code configs, without the ${ }, and
+// the ArgVars they are translated to
+var VSCodeVars = map[string]string{
+	"workspaceFolder":         "{ProjPath}",
+	"workspaceRoot":           "{ProjPath}",
+	"workspaceFolderBasename": "{ProjDir}",
+	"file":                    "{FilePath}",
+	"fileBasename":            "{FileName}",
+	"fileBasenameNoExtension": "{FileNameNoExt}",
+	"fileExtname":             "{FileExt}",
+	"fileDirname":             "{FileDirPath}",
+	"relativeFileDirname":     "{FileDirProjRel}",
+	"lineNumber":              "{CurLine1}",
+	"selectedText":            "{CurSel}",
+}
+
+// IdeaVars are the macros of JetBrains IDE configs, without the $ $, and
+// the ArgVars they are translated to
+var IdeaVars = map[string]string{
+	"PROJECT_DIR":                  "{ProjPath}",
+	"MODULE_DIR":                   "{ProjPath}",
+	"MODULE_WORKING_DIR":           "{ProjPath}",
+	"ProjectFileDir":               "{ProjPath}",
+	"USER_HOME":                    "{Env:HOME}",
+	"FilePath":                     "{FilePath}",
+	"FileName":                     "{FileName}",
+	"FileNameWithoutExtension":     "{FileNameNoExt}",
+	"FileDir":                      "{FileDirPath}",
+	"FileDirRelativeToProjectRoot": "{FileDirProjRel}",
+	"LineNumber":                   "{CurLine1}",
+	"SelectedText":                 "{CurSel}",
+	"Prompt":                       "{PromptString1}",
+}
+
+var (
+	vscodeVarRe = regexp.MustCompile(`\$\{([^}]+)\}`)
+	ideaVarRe   = regexp.MustCompile(`\$([A-Za-z_]+)\$`)
+)
+
+// translateVars returns the string with the variables matched by re that
+// are in vars replaced by their ArgVars, and any other { quoted, so it is
+// not taken for one -- env:NAME variables become {Env:NAME} -- the others
+// are left as they are, and returned
+func translateVars(s string, re *regexp.Regexp, vars map[string]string) (string, []string) {
+	var sb strings.Builder
+	var unk []string
+	quote := func(t string) {
+		sb.WriteString(strings.Replace(t, "{", "\\{", -1))
+	}
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		quote(s[last:m[0]])
+		last = m[1]
+		nm := s[m[2]:m[3]]
+		if av, has := vars[nm]; has {
+			sb.WriteString(av)
+		} else if strings.HasPrefix(nm, "env:") {
+			sb.WriteString("{Env:" + nm[4:] + "}")
+		} else {
+			quote(s[m[0]:m[1]])
+			unk = append(unk, s[m[0]:m[1]])
+		}
+	}
+	quote(s[last:])
+	return sb.String(), unk
+}
+
+// splitCmdLine splits the command line into its args at the spaces outside
+// of quotes, removing the quotes -- it returns false if the line must be
+// run by a shell instead, as it has shell operators, e.g., | or &&, or
+// variables
+func splitCmdLine(s string) ([]string, bool) {
+	var args []string
+	var cur strings.Builder
+	inarg := false
+	var q rune
+	for _, r := range s {
+		switch {
+		case q != 0:
+			if r == q {
+				q = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			q = r
+			inarg = true
+		case r == ' ' || r == '\t':
+			if inarg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inarg = false
+			}
+		case strings.ContainsRune("|&;<>()$`*?~", r):
+			return nil, false
+		default:
+			cur.WriteRune(r)
+			inarg = true
+		}
+	}
+	if q != 0 {
+		return nil, false
+	}
+	if inarg {
+		args = append(args, cur.String())
+	}
+	return args, true
+}
+
+// cmdLineStep returns the step that runs the command line, already
+// translated, directly if it can be split into args, or else by sh
+func cmdLineStep(line string) CmdAndArgs {
+	if args, ok := splitCmdLine(line); ok && len(args) > 0 {
+		return CmdAndArgs{Cmd: args[0], Args: args[1:]}
+	}
+	return CmdAndArgs{Cmd: "sh", Args: CmdArgs{"-c", line}}
+}
+
+// importCmd returns a new imported command running the steps in dir, or the
+// project root if it is empty
+func importCmd(name, desc, dir string, steps ...CmdAndArgs) *Command {
+	if dir == "" {
+		dir = "{ProjPath}"
+	}
+	return &Command{Name: name, Desc: desc, Lang: filecat.Any, Cmds: steps, Dir: dir, Concurrency: CmdCancelPrev}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//  VS Code
+
+// VSCodeSettings imports the editor settings of a VS Code settings.json
+// file into the project prefs: tab size and spaces, word wrap, line
+// numbers, rulers, minimap, bracket colors and occurrences highlighting
+func (pi *ProjImport) VSCodeSettings(fname string, pf *ProjPrefs) {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return
+	}
+	src := ".vscode/settings.json"
+	var st map[string]interface{}
+	if err := json.Unmarshal(StripJSONComments(b), &st); err != nil {
+		pi.note(src, "could not be read: %v", err)
+		return
+	}
+	ed := &pf.Editor
+	var skip []string
+	keys := make([]string, 0, len(st))
+	for k := range st {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := st[k]
+		ok := true
+		switch k {
+		case "editor.tabSize":
+			var n float64
+			if n, ok = v.(float64); ok && n > 0 {
+				ed.TabSize = int(n)
+			}
+		case "editor.insertSpaces":
+			ed.SpaceIndent, ok = v.(bool)
+		case "editor.wordWrap":
+			var s string
+			s, ok = v.(string)
+			ed.WordWrap = s != "off"
+		case "editor.lineNumbers":
+			var s string
+			s, ok = v.(string)
+			ed.LineNos = s != "off"
+		case "editor.minimap.enabled":
+			ed.Minimap, ok = v.(bool)
+		case "editor.bracketPairColorization.enabled":
+			ed.Rainbow, ok = v.(bool)
+		case "editor.occurrencesHighlight":
+			switch ov := v.(type) {
+			case bool:
+				ed.HiOccurs = ov
+			case string:
+				ed.HiOccurs = ov != "off"
+			default:
+				ok = false
+			}
+		case "editor.rulers":
+			var rs []interface{}
+			if rs, ok = v.([]interface{}); ok {
+				ed.Rulers = nil
+				for _, r := range rs {
+					if rm, isobj := r.(map[string]interface{}); isobj {
+						r = rm["column"]
+					}
+					if n, isnum := r.(float64); isnum && n > 0 {
+						ed.Rulers = append(ed.Rulers, int(n))
+					}
+				}
+			}
+		default:
+			skip = append(skip, k)
+			continue
+		}
+		if ok {
+			pi.note(src, "%v: %v", k, v)
+		} else {
+			pi.note(src, "%v: value %v not understood, skipped", k, v)
+		}
+	}
+	if len(skip) > 0 {
+		pi.note(src, "skipped %v settings with no equivalent: %v", len(skip), strings.Join(skip, ", "))
+	}
+}
+
+// vscodeTask is a task of a VS Code tasks.json file
+type vscodeTask struct {
+	Label    string        `json:"label"`
+	TaskName string        `json:"taskName"`
+	Type     string        `json:"type"`
+	Command  string        `json:"command"`
+	Args     []interface{} `json:"args"`
+	Group    interface{}   `json:"group"`
+	Options  struct {
+		Cwd string `json:"cwd"`
+	} `json:"options"`
+}
+
+// isDefaultBuild returns true if the task is the default of the build group
+func (vt *vscodeTask) isDefaultBuild() bool {
+	switch g := vt.Group.(type) {
+	case string:
+		return g == "build"
+	case map[string]interface{}:
+		def, _ := g["isDefault"].(bool)
+		return g["kind"] == "build" && def
+	}
+	return false
+}
+
+// VSCodeTasks imports the tasks of a VS Code tasks.json file as commands,
+// named VS Code: and the label of the task -- the default build task
+// becomes the Build command of the project
+func (pi *ProjImport) VSCodeTasks(fname string, pf *ProjPrefs) {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return
+	}
+	src := ".vscode/tasks.json"
+	var tf struct {
+		Tasks []vscodeTask `json:"tasks"`
+	}
+	if err := json.Unmarshal(StripJSONComments(b), &tf); err != nil {
+		pi.note(src, "could not be read: %v", err)
+		return
+	}
+	for i := range tf.Tasks {
+		vt := &tf.Tasks[i]
+		lbl := vt.Label
+		if lbl == "" {
+			lbl = vt.TaskName
+		}
+		if vt.Command == "" {
+			pi.note(src, "task %q has no command, e.g., it only depends on others, skipped", lbl)
+			continue
+		}
+		var unks []string
+		tr := func(s string) string {
+			ts, unk := translateVars(s, vscodeVarRe, VSCodeVars)
+			unks = append(unks, unk...)
+			return ts
+		}
+		line := tr(vt.Command)
+		var args CmdArgs
+		for _, a := range vt.Args {
+			switch av := a.(type) {
+			case string:
+				args = append(args, tr(av))
+			case map[string]interface{}:
+				s, _ := av["value"].(string)
+				args = append(args, tr(s))
+			}
+		}
+		step := CmdAndArgs{Cmd: line, Args: args}
+		if vt.Type != "process" {
+			if cl, ok := splitCmdLine(line); ok && len(cl) > 0 {
+				step = CmdAndArgs{Cmd: cl[0], Args: append(CmdArgs(cl[1:]), args...)}
+			} else {
+				step = cmdLineStep(strings.TrimSpace(line + " " + strings.Join(args, " ")))
+			}
+		}
+		cmd := importCmd("VS Code: "+lbl, "task "+lbl+" of .vscode/tasks.json", tr(vt.Options.Cwd), step)
+		pi.addCmd(cmd)
+		pi.note(src, "task %q imported as command %v", lbl, cmd.Name)
+		if len(unks) > 0 {
+			pi.note(src, "task %q has variables with no equivalent, left as they are: %v", lbl, strings.Join(unks, ", "))
+		}
+		if !pi.Build && vt.isDefaultBuild() {
+			pf.BuildCmds = CmdNames{CmdName(cmd.Name)}
+			pi.Build = true
+			pi.note(src, "default build task %q set as the Build command", lbl)
+		}
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//  JetBrains IDEs
+
+// ideaValue is an element of an IDEA config with a value attribute
+type ideaValue struct {
+	Value string `xml:"value,attr"`
+}
+
+// ideaConfig is a run configuration of a JetBrains IDE
+type ideaConfig struct {
+	Name    string      `xml:"name,attr"`
+	Type    string      `xml:"type,attr"`
+	Default bool        `xml:"default,attr"`
+	Options []ideaOpt   `xml:"option"`
+	WorkDir ideaValue   `xml:"working_directory"`
+	Params  ideaValue   `xml:"parameters"`
+	Kind    ideaValue   `xml:"kind"`
+	Package ideaValue   `xml:"package"`
+	Dir     ideaValue   `xml:"directory"`
+	File    ideaValue   `xml:"filePath"`
+	Pattern ideaValue   `xml:"pattern"`
+	Make    ideaMakeCfg `xml:"makefile"`
+}
+
+// ideaOpt is an option of an IDEA run configuration
+type ideaOpt struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// ideaMakeCfg is the makefile element of an IDEA Makefile run configuration
+type ideaMakeCfg struct {
+	Filename string `xml:"filename,attr"`
+	Target   string `xml:"target,attr"`
+	WorkDir  string `xml:"workingDirectory,attr"`
+	Args     string `xml:"arguments,attr"`
+}
+
+// opt returns the value of the option of given name, or ""
+func (ic *ideaConfig) opt(nm string) string {
+	for _, o := range ic.Options {
+		if o.Name == nm {
+			return o.Value
+		}
+	}
+	return ""
+}
+
+// readIdeaConfigs returns the run configurations in the IDEA xml file,
+// anywhere in it, skipping the defaults (templates)
+func readIdeaConfigs(r io.Reader) ([]ideaConfig, error) {
+	var ics []ideaConfig
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return ics, nil
+		}
+		if err != nil {
+			return ics, err
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "configuration" {
+			continue
+		}
+		var ic ideaConfig
+		if err := dec.DecodeElement(&ic, &se); err != nil {
+			return ics, err
+		}
+		if !ic.Default && ic.Name != "" {
+			ics = append(ics, ic)
+		}
+	}
+}
+
+// IdeaRunConfigs imports the run configurations of JetBrains IDEs (GoLand,
+// PyCharm, IntelliJ IDEA and others), in the .idea/runConfigurations
+// directory and .idea/workspace.xml, as commands, named Idea: and the
+// name of the configuration: Go applications and tests, Python scripts,
+// shell scripts and Makefile targets -- the first, other than a test,
+// becomes the Run command of the project
+func (pi *ProjImport) IdeaRunConfigs(dir string, pf *ProjPrefs) {
+	fns, _ := filepath.Glob(filepath.Join(dir, "runConfigurations", "*.xml"))
+	sort.Strings(fns)
+	fns = append(fns, filepath.Join(dir, "workspace.xml"))
+	for _, fn := range fns {
+		f, err := os.Open(fn)
+		if err != nil {
+			continue
+		}
+		src := filepath.Join(".idea", strings.TrimPrefix(fn, dir+string(filepath.Separator)))
+		ics, err := readIdeaConfigs(f)
+		f.Close()
+		if err != nil {
+			pi.note(src, "could not be read: %v", err)
+		}
+		for i := range ics {
+			pi.ideaConfig(src, &ics[i], pf)
+		}
+	}
+}
+
+// ideaConfig imports the IDEA run configuration as a command
+func (pi *ProjImport) ideaConfig(src string, ic *ideaConfig, pf *ProjPrefs) {
+	var unks []string
+	tr := func(s string) string {
+		ts, unk := translateVars(s, ideaVarRe, IdeaVars)
+		unks = append(unks, unk...)
+		return ts
+	}
+	params := func(s string) CmdArgs {
+		args, ok := splitCmdLine(tr(s))
+		if !ok {
+			return CmdArgs{tr(s)}
+		}
+		return args
+	}
+	var step CmdAndArgs
+	dir := tr(ic.WorkDir.Value)
+	isrun := true
+	switch ic.Type {
+	case "GoApplicationRunConfiguration", "GoTestRunConfiguration":
+		targ := tr(ic.Package.Value)
+		switch ic.Kind.Value {
+		case "FILE":
+			targ = tr(ic.File.Value)
+		case "DIRECTORY":
+			targ = tr(ic.Dir.Value)
+		}
+		if ic.Type == "GoTestRunConfiguration" {
+			isrun = false
+			if ic.Kind.Value == "DIRECTORY" {
+				targ += "/..."
+			}
+			step = CmdAndArgs{Cmd: "go", Args: CmdArgs{"test", targ}}
+			if ic.Pattern.Value != "" {
+				step.Args = append(step.Args, "-run", tr(ic.Pattern.Value))
+			}
+		} else {
+			step = CmdAndArgs{Cmd: "go", Args: CmdArgs{"run", targ}}
+		}
+		step.Args = append(step.Args, params(ic.Params.Value)...)
+	case "PythonConfigurationType":
+		step = CmdAndArgs{Cmd: "python3", Args: CmdArgs{tr(ic.opt("SCRIPT_NAME"))}}
+		step.Args = append(step.Args, params(ic.opt("PARAMETERS"))...)
+		dir = tr(ic.opt("WORKING_DIRECTORY"))
+	case "ShConfigurationType":
+		if ic.opt("EXECUTE_SCRIPT_FILE") == "false" {
+			step = CmdAndArgs{Cmd: "sh", Args: CmdArgs{"-c", tr(ic.opt("SCRIPT_TEXT"))}}
+		} else {
+			step = CmdAndArgs{Cmd: tr(ic.opt("SCRIPT_PATH")), Args: params(ic.opt("SCRIPT_OPTIONS"))}
+		}
+		dir = tr(ic.opt("SCRIPT_WORKING_DIRECTORY"))
+	case "MAKEFILE_TARGET_RUN_CONFIGURATION":
+		isrun = false
+		step = CmdAndArgs{Cmd: "make", Args: CmdArgs{tr(ic.Make.Target)}}
+		if ic.Make.Filename != "" {
+			step.Args = append(CmdArgs{"-f", tr(ic.Make.Filename)}, step.Args...)
+		}
+		step.Args = append(step.Args, params(ic.Make.Args)...)
+		dir = tr(ic.Make.WorkDir)
+	default:
+		pi.note(src, "run configuration %q of type %v has no equivalent, skipped", ic.Name, ic.Type)
+		return
+	}
+	cmd := importCmd("Idea: "+ic.Name, "run configuration "+ic.Name+" of "+src, dir, step)
+	pi.addCmd(cmd)
+	pi.note(src, "run configuration %q imported as command %v", ic.Name, cmd.Name)
+	if len(unks) > 0 {
+		pi.note(src, "run configuration %q has macros with no equivalent, left as they are: %v", ic.Name, strings.Join(unks, ", "))
+	}
+	if isrun && !pi.Run {
+		pf.RunCmds = CmdNames{CmdName(cmd.Name)}
+		pi.Run = true
+		pi.note(src, "run configuration %q set as the Run command", ic.Name)
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//  Makefile
+
+var makeTargetRe = regexp.MustCompile(`^([A-Za-z0-9_./-]+(?:[ \t]+[A-Za-z0-9_./-]+)*)[ \t]*::?(?:[^=]|$)`)
+
+// MakeTargets returns the explicit targets of the Makefile text, in order,
+// skipping special targets like .PHONY, pattern rules and those set by
+// variables
+func MakeTargets(mk []byte) []string {
+	var targs []string
+	has := map[string]bool{}
+	for _, ln := range strings.Split(string(mk), "\n") {
+		m := makeTargetRe.FindStringSubmatch(ln)
+		if m == nil {
+			continue
+		}
+		for _, t := range strings.Fields(m[1]) {
+			if strings.HasPrefix(t, ".") || has[t] {
+				continue
+			}
+			has[t] = true
+			targs = append(targs, t)
+		}
+	}
+	return targs
+}
+
+// MakefileTargets imports the targets of the Makefile as commands, named
+// Make and the target, e.g., Make test -- the first, which make builds by
+// default, becomes the Build command of the project, if no build task did
+func (pi *ProjImport) MakefileTargets(fname string, pf *ProjPrefs) {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return
+	}
+	src := "Makefile"
+	targs := MakeTargets(b)
+	if len(targs) == 0 {
+		pi.note(src, "no targets found")
+		return
+	}
+	for _, t := range targs {
+		cmd := importCmd("Make "+t, "make the "+t+" target of the Makefile", "", CmdAndArgs{Cmd: "make", Args: CmdArgs{t}})
+		cmd.Concurrency = CmdQueue
+		cmd.Group = CmdBuildGroup
+		pi.addCmd(cmd)
+	}
+	pi.note(src, "%v targets imported as commands: Make %v", len(targs), strings.Join(targs, ", Make "))
+	if !pi.Build {
+		pf.BuildCmds = CmdNames{CmdName("Make " + targs[0])}
+		pi.Build = true
+		pi.note(src, "default target %v set as the Build command", targs[0])
+	}
+}
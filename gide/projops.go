@@ -0,0 +1,181 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/giv"
+)
+
+// ProjOpsMax is the maximum number of project operations kept in the history
+var ProjOpsMax = 50
+
+// OpFile is a file changed by a project operation, with its contents
+// before the operation, for Undo
+type OpFile struct {
+	Path   string `desc:"path of the file"`
+	Before []byte `desc:"contents of the file, or of its open buffer, before the operation"`
+	InBuf  bool   `desc:"the operation changed the open buffer of the file, which was not saved, instead of the file itself"`
+}
+
+// ProjOp is a project-level operation that changes multiple files, e.g.,
+// Replace All in Find, or Run Command on Files, which is undone as one
+// operation, restoring all the buffers and files it changed
+type ProjOp struct {
+	ID     int       `desc:"unique id of the operation in this session"`
+	Name   string    `desc:"name of the operation, for the Operations history"`
+	Time   time.Time `desc:"time the operation was done"`
+	Files  []OpFile  `desc:"files changed by the operation"`
+	Undone bool      `desc:"the operation has been undone"`
+}
+
+// NewProjOp returns a new project operation of given name, to which the
+// files are added (see AddBuf and AddFile) before they are changed
+func NewProjOp(name string) *ProjOp {
+	return &ProjOp{Name: name, Time: time.Now()}
+}
+
+// HasFile returns true if the file at given path has been added
+func (op *ProjOp) HasFile(fpath string) bool {
+	for i := range op.Files {
+		if op.Files[i].Path == fpath {
+			return true
+		}
+	}
+	return false
+}
+
+// AddBuf adds the file of the buffer with its current contents, if it has
+// not been added yet -- call before the operation changes the buffer
+func (op *ProjOp) AddBuf(tb *giv.TextBuf) {
+	fpath := string(tb.Filename)
+	if op.HasFile(fpath) {
+		return
+	}
+	op.Files = append(op.Files, OpFile{Path: fpath, Before: tb.LinesToBytesCopy(), InBuf: true})
+}
+
+// AddFile adds the file at given path with its current contents, if it has
+// not been added yet -- call before the operation changes the file
+func (op *ProjOp) AddFile(fpath string) error {
+	if op.HasFile(fpath) {
+		return nil
+	}
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return err
+	}
+	op.Files = append(op.Files, OpFile{Path: fpath, Before: b})
+	return nil
+}
+
+// DropUnchanged removes the files, other than buffers, whose contents are
+// the same as before the operation, e.g., after running a command that only
+// changed some of them
+func (op *ProjOp) DropUnchanged() {
+	fls := op.Files[:0]
+	for _, of := range op.Files {
+		if !of.InBuf {
+			if cur, err := ioutil.ReadFile(of.Path); err == nil && bytes.Equal(cur, of.Before) {
+				continue
+			}
+		}
+		fls = append(fls, of)
+	}
+	op.Files = fls
+}
+
+// Summary returns a one-line summary of the operation
+func (op *ProjOp) Summary() string {
+	st := ""
+	if op.Undone {
+		st = " (undone)"
+	}
+	return fmt.Sprintf("%v %v: %v files%v", op.Time.Format("15:04:05"), op.Name, len(op.Files), st)
+}
+
+// Undo restores all the buffers and files changed by the operation: an
+// open buffer is restored to its contents before the operation, as one edit
+// which can itself be undone, and a file is written with its contents
+// before the operation, reverting its open buffer if it has no changes --
+// bufFor returns the open buffer for a file path, or nil -- returns an
+// error for the files that could not be restored, after restoring the
+// others
+func (op *ProjOp) Undo(bufFor func(fpath string) *giv.TextBuf) error {
+	if op.Undone {
+		return fmt.Errorf("operation: %v has already been undone", op.Name)
+	}
+	var errs []string
+	for i := range op.Files {
+		of := &op.Files[i]
+		tb := bufFor(of.Path)
+		if of.InBuf && tb != nil {
+			ReplaceChangedLines(tb, strings.Split(string(of.Before), "\n"))
+			continue
+		}
+		if cur, err := ioutil.ReadFile(of.Path); err == nil && bytes.Equal(cur, of.Before) {
+			continue
+		}
+		mode := os.FileMode(0644)
+		if fi, err := os.Stat(of.Path); err == nil {
+			mode = fi.Mode()
+		}
+		if err := ioutil.WriteFile(of.Path, of.Before, mode); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if tb != nil && !tb.IsChanged() {
+			tb.Revert()
+		}
+	}
+	op.Undone = true
+	if len(errs) > 0 {
+		return fmt.Errorf("could not restore %v files:\n%v", len(errs), strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// ProjOps is the history of project operations, most recent last
+type ProjOps []*ProjOp
+
+// Add adds the operation to the history, giving it the next id, and
+// dropping the oldest operations beyond ProjOpsMax
+func (po *ProjOps) Add(op *ProjOp) {
+	op.ID = 1
+	if n := len(*po); n > 0 {
+		op.ID = (*po)[n-1].ID + 1
+	}
+	*po = append(*po, op)
+	if n := len(*po); n > ProjOpsMax {
+		*po = (*po)[n-ProjOpsMax:]
+	}
+}
+
+// ByID returns the operation with given id, or nil if not in the history
+func (po *ProjOps) ByID(id int) *ProjOp {
+	for _, op := range *po {
+		if op.ID == id {
+			return op
+		}
+	}
+	return nil
+}
+
+// LastDone returns the most recent operation that has not been undone, or
+// nil if none
+func (po *ProjOps) LastDone() *ProjOp {
+	for i := len(*po) - 1; i >= 0; i-- {
+		if op := (*po)[i]; !op.Undone {
+			return op
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/units"
+	"github.com/goki/pi/filecat"
+)
+
+// LangProseMode returns true if files of given language should be viewed
+// in prose mode, according to AvailLangs
+func LangProseMode(sup filecat.Supported) bool {
+	lo, has := AvailLangs[sup]
+	return has && lo.ProseMode
+}
+
+// SetProseMode turns prose (writing) mode on or off for this view: in prose
+// mode, lines are soft-wrapped at the editor ProseWidth, the cursor line is
+// kept centered typewriter-style, and line numbers are hidden -- when off,
+// the regular editor prefs are restored
+func (tv *TextView) SetProseMode(on bool, ep *EditorPrefs) {
+	if tv.ProseMode == on {
+		return
+	}
+	tv.ProseMode = on
+	if on {
+		tv.SetProp("white-space", gi.WhiteSpacePreWrap)
+		tv.SetProp("max-width", units.NewValue(float32(ep.ProseWidth), units.Ch))
+	} else {
+		if ep.WordWrap {
+			tv.SetProp("white-space", gi.WhiteSpacePreWrap)
+		} else {
+			tv.SetProp("white-space", gi.WhiteSpacePre)
+		}
+		tv.DeleteProp("max-width")
+	}
+	if tv.Buf != nil {
+		tv.Buf.Opts.LineNos = !on && ep.LineNos
+	}
+	tv.SetFullReRender()
+	tv.UpdateSig()
+}
+
+// SetViewOpts sets the word wrap, line numbers and tab size for this view
+// and its buffer -- prose mode, if on, takes precedence for word wrap and
+// line numbers
+func (tv *TextView) SetViewOpts(vo *ViewOpts) {
+	if !tv.ProseMode {
+		if vo.WordWrap {
+			tv.SetProp("white-space", gi.WhiteSpacePreWrap)
+		} else {
+			tv.SetProp("white-space", gi.WhiteSpacePre)
+		}
+	}
+	tv.SetProp("tab-size", vo.TabSize)
+	if tv.Buf != nil {
+		tv.Buf.Opts.TabSize = vo.TabSize
+		tv.Buf.Opts.LineNos = vo.LineNos && !tv.ProseMode
+	}
+	tv.SetFullReRender()
+	tv.UpdateSig()
+}
+
+// ProseKeyInput keeps the cursor line vertically centered in prose mode
+func (tv *TextView) ProseKeyInput(kt *key.ChordEvent) {
+	if !tv.ProseMode {
+		return
+	}
+	tv.ScrollCursorToVertCenter()
+}
+
+// IsSentenceEnd returns true if the rune at given char position in line ends
+// a sentence: a period, question or exclamation mark followed by space or
+// the end of the line
+func IsSentenceEnd(lt []rune, ch int) bool {
+	switch lt[ch] {
+	case '.', '?', '!':
+		return ch+1 >= len(lt) || unicode.IsSpace(lt[ch+1])
+	}
+	return false
+}
+
+// SentenceNext moves the cursor to the start of the next sentence -- blank
+// lines also separate sentences (paragraphs)
+func (tv *TextView) SentenceNext() {
+	if tv.Buf == nil {
+		return
+	}
+	nl := tv.Buf.NumLines()
+	pos := tv.CursorPos
+	found := false
+	for ln := pos.Ln; ln < nl; ln++ {
+		lt := tv.Buf.Line(ln)
+		st := 0
+		if ln == pos.Ln {
+			st = pos.Ch
+		}
+		if found || (ln > pos.Ln && len(lt) == 0) {
+			found = true
+			for ch := st; ch < len(lt); ch++ {
+				if !unicode.IsSpace(lt[ch]) {
+					tv.SetCursorShow(giv.TextPos{Ln: ln, Ch: ch})
+					return
+				}
+			}
+			continue
+		}
+		for ch := st; ch < len(lt); ch++ {
+			if !IsSentenceEnd(lt, ch) {
+				continue
+			}
+			for ch++; ch < len(lt); ch++ {
+				if !unicode.IsSpace(lt[ch]) {
+					tv.SetCursorShow(giv.TextPos{Ln: ln, Ch: ch})
+					return
+				}
+			}
+			found = true
+		}
+	}
+	tv.SetCursorShow(tv.Buf.EndPos())
+}
+
+// SentencePrev moves the cursor to the start of the current sentence, or the
+// previous one if already at the start
+func (tv *TextView) SentencePrev() {
+	if tv.Buf == nil {
+		return
+	}
+	pos := tv.CursorPos
+	last := giv.TextPos{} // last non-space position seen going backward
+	started := false      // have passed a non-space char
+	for ln := pos.Ln; ln >= 0; ln-- {
+		lt := tv.Buf.Line(ln)
+		ed := len(lt) - 1
+		if ln == pos.Ln {
+			ed = pos.Ch - 1
+			if ed >= len(lt) {
+				ed = len(lt) - 1
+			}
+		}
+		if ln < pos.Ln && len(lt) == 0 && started {
+			tv.SetCursorShow(last)
+			return
+		}
+		for ch := ed; ch >= 0; ch-- {
+			if unicode.IsSpace(lt[ch]) {
+				continue
+			}
+			if started && IsSentenceEnd(lt, ch) {
+				tv.SetCursorShow(last)
+				return
+			}
+			started = true
+			last = giv.TextPos{Ln: ln, Ch: ch}
+		}
+	}
+	tv.SetCursorShow(giv.TextPos{})
+}
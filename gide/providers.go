@@ -0,0 +1,110 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/filecat"
+)
+
+// CompletionProvider provides completion, declaration lookup and
+// formatting for the files of a language, replacing the standard ones --
+// registered with RegisterCompletionProvider, e.g., by a plugin or an
+// editor built on gide.  CompletionFuncs implements it with optional
+// functions.
+type CompletionProvider interface {
+	// Name returns the name of the provider, shown in the status
+	Name() string
+
+	// SetCompleter sets the completer of a buffer just opened, e.g., with
+	// TextBuf.SetCompleter -- returns false to keep the standard completion
+	SetCompleter(ge Gide, tb *giv.TextBuf) bool
+
+	// Lookup returns the file and region of the declaration of the symbol
+	// at given position in the buffer -- false if not found
+	Lookup(ge Gide, tb *giv.TextBuf, pos giv.TextPos) (gi.FileName, giv.TextRegion, bool)
+
+	// Format returns the given source of the buffer formatted -- false to
+	// use the standard formatting (see ReformatFile)
+	Format(ge Gide, tb *giv.TextBuf, src []byte) ([]byte, bool, error)
+}
+
+// CompletionFuncs is a CompletionProvider with optional functions for each
+// of its methods -- nil functions are not provided
+type CompletionFuncs struct {
+	Nm        string                                                                              `desc:"name of the provider"`
+	Completer func(ge Gide, tb *giv.TextBuf)                                                      `view:"-" json:"-" desc:"sets the completer of the buffer"`
+	LookupFun func(ge Gide, tb *giv.TextBuf, pos giv.TextPos) (gi.FileName, giv.TextRegion, bool) `view:"-" json:"-" desc:"looks up the declaration of the symbol at the position"`
+	FormatFun func(ge Gide, tb *giv.TextBuf, src []byte) ([]byte, error)                          `view:"-" json:"-" desc:"formats the source of the buffer"`
+}
+
+// Name returns the name of the provider
+func (cf *CompletionFuncs) Name() string {
+	return cf.Nm
+}
+
+// SetCompleter calls the Completer function if set
+func (cf *CompletionFuncs) SetCompleter(ge Gide, tb *giv.TextBuf) bool {
+	if cf.Completer == nil {
+		return false
+	}
+	cf.Completer(ge, tb)
+	return true
+}
+
+// Lookup calls the LookupFun function if set
+func (cf *CompletionFuncs) Lookup(ge Gide, tb *giv.TextBuf, pos giv.TextPos) (gi.FileName, giv.TextRegion, bool) {
+	if cf.LookupFun == nil {
+		return "", giv.TextRegionNil, false
+	}
+	return cf.LookupFun(ge, tb, pos)
+}
+
+// Format calls the FormatFun function if set
+func (cf *CompletionFuncs) Format(ge Gide, tb *giv.TextBuf, src []byte) ([]byte, bool, error) {
+	if cf.FormatFun == nil {
+		return nil, false, nil
+	}
+	out, err := cf.FormatFun(ge, tb, src)
+	return out, true, err
+}
+
+// CompletionProviders are the registered completion providers for each
+// language
+var CompletionProviders = map[filecat.Supported]CompletionProvider{}
+
+// RegisterCompletionProvider registers the completion provider for files of
+// given language, replacing any registered before
+func RegisterCompletionProvider(sup filecat.Supported, cp CompletionProvider) {
+	CompletionProviders[sup] = cp
+}
+
+// BufCompletionProvider returns the completion provider for the language of
+// the buffer, or nil if there is none
+func BufCompletionProvider(tb *giv.TextBuf) CompletionProvider {
+	if tb == nil {
+		return nil
+	}
+	return CompletionProviders[tb.Info.Sup]
+}
+
+// SetBufCompleter sets the completer of the buffer from the provider for
+// its language, returning false if there is none
+func SetBufCompleter(ge Gide, tb *giv.TextBuf) bool {
+	cp := BufCompletionProvider(tb)
+	return cp != nil && cp.SetCompleter(ge, tb)
+}
+
+// LookupDecl returns the file and region of the declaration of the symbol
+// at given position in the buffer, from the provider for its language --
+// false if there is none, or it was not found
+func LookupDecl(ge Gide, tb *giv.TextBuf, pos giv.TextPos) (gi.FileName, giv.TextRegion, bool) {
+	cp := BufCompletionProvider(tb)
+	if cp == nil {
+		return "", giv.TextRegionNil, false
+	}
+	return cp.Lookup(ge, tb, pos)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+)
+
+// RecentFilesMax is the maximum number of recent files kept per project
+var RecentFilesMax = 20
+
+// ClosedFile records a file closed in a project, so it can be reopened at
+// the same cursor position
+type ClosedFile struct {
+	FPath  gi.FileName `desc:"full path of the closed file"`
+	Cursor giv.TextPos `desc:"cursor position in the file when closed"`
+}
+
+// ClosedFiles is a most-recently-used stack of closed files -- the most
+// recently closed is last
+type ClosedFiles []ClosedFile
+
+// Push adds given file to the top of the stack, removing any prior entry for
+// the same file, and dropping the oldest beyond RecentFilesMax
+func (cf *ClosedFiles) Push(fpath gi.FileName, cur giv.TextPos) {
+	cf.Remove(fpath)
+	*cf = append(*cf, ClosedFile{FPath: fpath, Cursor: cur})
+	if n := len(*cf) - RecentFilesMax; n > 0 {
+		*cf = (*cf)[n:]
+	}
+}
+
+// Pop removes and returns the most recently closed file -- false if none
+func (cf *ClosedFiles) Pop() (ClosedFile, bool) {
+	n := len(*cf)
+	if n == 0 {
+		return ClosedFile{}, false
+	}
+	c := (*cf)[n-1]
+	*cf = (*cf)[:n-1]
+	return c, true
+}
+
+// Remove removes given file from the stack, e.g., when it is opened again
+func (cf *ClosedFiles) Remove(fpath gi.FileName) {
+	for i := len(*cf) - 1; i >= 0; i-- {
+		if (*cf)[i].FPath == fpath {
+			*cf = append((*cf)[:i], (*cf)[i+1:]...)
+		}
+	}
+}
+
+// RecentFileNames returns the recent file paths relative to the project
+// root, for use in a menu
+func RecentFileNames(rf gi.FilePaths, root gi.FileName) []string {
+	nms := make([]string, len(rf))
+	for i, fp := range rf {
+		if rel, err := filepath.Rel(string(root), fp); err == nil {
+			nms[i] = rel
+		} else {
+			nms[i] = fp
+		}
+	}
+	return nms
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/key"
+)
+
+// Rectangles are the text in a range of columns of successive lines, for
+// tabular edits -- columns are counted in chars, so tabs count as one column.
+
+// RectText returns the text of the columns st to ed (exclusive) of each of
+// the lines -- lines shorter than st give empty strings
+func RectText(lns []string, st, ed int) []string {
+	rect := make([]string, len(lns))
+	for i, l := range lns {
+		rs := []rune(l)
+		if st >= len(rs) {
+			continue
+		}
+		e := ed
+		if e > len(rs) {
+			e = len(rs)
+		}
+		rect[i] = string(rs[st:e])
+	}
+	return rect
+}
+
+// InsertRect returns the lines with the lines of the rectangle inserted at
+// column ch of successive lines, starting with the first -- lines shorter
+// than ch are padded with spaces, and new lines are added after the last
+// one if the rectangle has more lines -- shorter lines of the rectangle are
+// padded with spaces to its width, unless they are at the end of the line
+func InsertRect(lns []string, ch int, rect []string) []string {
+	wd := 0
+	for _, r := range rect {
+		if n := len([]rune(r)); n > wd {
+			wd = n
+		}
+	}
+	nlns := make([]string, len(lns))
+	copy(nlns, lns)
+	for i, r := range rect {
+		if i >= len(nlns) {
+			nlns = append(nlns, "")
+		}
+		rs := []rune(nlns[i])
+		if len(rs) < ch {
+			rs = append(rs, []rune(strings.Repeat(" ", ch-len(rs)))...)
+		}
+		rest := string(rs[ch:])
+		if rest != "" {
+			r += strings.Repeat(" ", wd-len([]rune(r)))
+		}
+		nlns[i] = string(rs[:ch]) + r + rest
+	}
+	return nlns
+}
+
+// SelectionRect returns the rectangle of the selection: the columns between
+// its start and end, on each of its lines -- nil if no selection
+func (tv *TextView) SelectionRect() []string {
+	if !tv.HasSelection() {
+		return nil
+	}
+	sr := tv.SelectReg
+	st, ed := sr.Start.Ch, sr.End.Ch
+	if ed < st {
+		st, ed = ed, st
+	}
+	return RectText(BufLines(tv.Buf)[sr.Start.Ln:sr.End.Ln+1], st, ed)
+}
+
+// PasteRect inserts the rectangle at the cursor, with each of its lines
+// inserted at the column of the cursor on successive lines, as one
+// operation for Undo (see InsertRect)
+func (tv *TextView) PasteRect(rect []string) bool {
+	tb := tv.Buf
+	if tb == nil || len(rect) == 0 {
+		return false
+	}
+	pos := tv.CursorPos
+	ed := pos.Ln + len(rect)
+	if ed > tb.NumLines() {
+		ed = tb.NumLines()
+	}
+	nlns := InsertRect(BufLines(tb)[pos.Ln:ed], pos.Ch, rect)
+	reg := giv.NewTextRegion(pos.Ln, 0, ed-1, len(tb.Line(ed-1)))
+	ReplaceRegion(tb, reg, []byte(strings.Join(nlns, "\n")))
+	tv.SetCursorShow(pos)
+	return true
+}
+
+// KillRegisterKeyInput adds the text deleted by a cut or kill key function
+// to the numbered kill registers (see Registers.AddKill)
+func (tv *TextView) KillRegisterKeyInput(kt *key.ChordEvent) {
+	kf := gi.KeyFun(kt.Chord())
+	if kf != gi.KeyFunCut && kf != gi.KeyFunKill {
+		return
+	}
+	tv.AddKillRegister()
+}
+
+// AddKillRegister adds the text of the last edit of the buffer, if it was a
+// deletion, to the numbered kill registers
+func (tv *TextView) AddKillRegister() {
+	tb := tv.Buf
+	if tb == nil || tb.UndoPos == 0 || tb.UndoPos > len(tb.Undos) {
+		return
+	}
+	tbe := tb.Undos[tb.UndoPos-1]
+	if tbe == nil || !tbe.Delete {
+		return
+	}
+	AvailRegisters.AddKill(string(tbe.ToBytes()))
+}
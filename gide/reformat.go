@@ -0,0 +1,393 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/filecat"
+)
+
+// KeepIndentLangs are the languages in which indentation is syntax, so
+// Reformat File keeps the indentation levels of the lines, only converting
+// the indentation to the tabs or spaces of the buffer
+var KeepIndentLangs = map[filecat.Supported]bool{
+	filecat.Python: true,
+}
+
+// KeepIndentExts are the file extensions of other files in which
+// indentation is syntax, as for KeepIndentLangs
+var KeepIndentExts = []string{".sass", ".pug", ".haml", ".coffee", ".nim"}
+
+// NoReformatLangs are the languages of files that Reformat File does not
+// re-indent, because indentation is free-form or meaningful in them
+var NoReformatLangs = map[filecat.Supported]bool{
+	filecat.Markdown:  true,
+	filecat.TeX:       true,
+	filecat.PlainText: true,
+}
+
+// NoReformatFiles are the file extensions and names of other files that
+// Reformat File does not re-indent, e.g., because spaces or tabs are required
+var NoReformatFiles = []string{".yaml", ".yml", ".mk", ".txt", "Makefile", "makefile", "GNUmakefile"}
+
+// OutdentCaseLangs are the languages in which case and default labels are
+// at the level of their switch, not inside it
+var OutdentCaseLangs = map[filecat.Supported]bool{
+	filecat.Go: true,
+}
+
+// CaseLabelRe matches the case and default labels of a switch
+var CaseLabelRe = regexp.MustCompile(`^(case\b.*|default\s*):`)
+
+// LangFormatter returns the formatter command for given language, from
+// AvailLangs, or "" if there is none
+func LangFormatter(sup filecat.Supported) string {
+	if lo, has := AvailLangs[sup]; has {
+		return lo.Formatter
+	}
+	return ""
+}
+
+// RunFormatter runs the formatter command line (a command and its args)
+// in given directory, with the source on stdin, returning the formatted
+// output -- the error includes the formatter output if it fails
+func RunFormatter(cmdln string, src []byte, dir string) ([]byte, error) {
+	args := strings.Fields(cmdln)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no formatter command")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(src)
+	var out, errb bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errb
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %v\n%s", args[0], err, errb.Bytes())
+	}
+	return out.Bytes(), nil
+}
+
+// BlockedLines returns true for each line that starts inside a multi-line
+// comment or a multi-line raw (backquoted) string, which are not
+// re-indented, and the regions of the multi-line raw strings, in which
+// ScanBrackets finds brackets that are not code
+func BlockedLines(lines [][]rune, syn *BracketSyntax) ([]bool, []giv.TextRegion) {
+	cln := []rune(syn.CommentLn)
+	cst := []rune(syn.CommentSt)
+	ced := []rune(syn.CommentEd)
+	bl := make([]bool, len(lines))
+	var raws []giv.TextRegion
+	incmt, inraw := false, false
+	for ln, lt := range lines {
+		bl[ln] = incmt || inraw
+		for ch := 0; ch < len(lt); ch++ {
+			switch {
+			case incmt:
+				if runesAt(lt, ch, ced) {
+					incmt = false
+					ch += len(ced) - 1
+				}
+				continue
+			case inraw:
+				if lt[ch] == '`' {
+					inraw = false
+					raws[len(raws)-1].End = giv.TextPos{Ln: ln, Ch: ch + 1}
+				}
+				continue
+			}
+			if runesAt(lt, ch, cln) {
+				break
+			}
+			if len(ced) > 0 && runesAt(lt, ch, cst) {
+				incmt = true
+				ch += len(cst) - 1
+				continue
+			}
+			r := lt[ch]
+			if !strings.ContainsRune(syn.Quotes, r) {
+				continue
+			}
+			if qe := quoteEnd(lt, ch); qe > 0 {
+				ch = qe
+			} else if r == '`' {
+				inraw = true
+				raws = append(raws, giv.NewTextRegion(ln, ch, len(lines)-1, len(lines[len(lines)-1])))
+			}
+		}
+	}
+	return bl, raws
+}
+
+// BracketsOutside returns the brackets that are not within the regions
+// (e.g., the raw strings from BlockedLines)
+func BracketsOutside(brs []Bracket, regs []giv.TextRegion) []Bracket {
+	if len(regs) == 0 {
+		return brs
+	}
+	var obrs []Bracket
+	ri := 0
+	for _, br := range brs {
+		for ri < len(regs) && !br.Pos.IsLess(regs[ri].End) {
+			ri++
+		}
+		if ri < len(regs) && !br.Pos.IsLess(regs[ri].Start) {
+			continue
+		}
+		obrs = append(obrs, br)
+	}
+	return obrs
+}
+
+// onlyClosers returns true if the runes are only spaces and closing brackets
+func onlyClosers(rs []rune) bool {
+	for _, r := range rs {
+		if !unicode.IsSpace(r) && r != ')' && r != '}' && r != ']' {
+			return false
+		}
+	}
+	return true
+}
+
+// ReindentLines returns the lines re-indented with given indent string
+// (e.g., a tab) by their nesting within the brackets (e.g., from
+// ScanBrackets, which are matched again here): each line that opens
+// brackets that are still open adds one level, and closing brackets at the
+// start of a line close their level on that line -- blocked lines (from
+// BlockedLines) are unchanged, and blank lines are emptied -- if
+// outdentCase, case and default labels are at the level of their switch
+func ReindentLines(lines [][]rune, brs []Bracket, blocked []bool, ind string, outdentCase bool) []string {
+	type open struct {
+		ln int
+		r  rune
+	}
+	nlns := make([]string, len(lines))
+	var stack []open
+	bi := 0
+	for ln, lt := range lines {
+		txt := strings.TrimLeftFunc(string(lt), unicode.IsSpace)
+		lbi := bi
+		for bi < len(brs) && brs[bi].Pos.Ln == ln {
+			bi++
+		}
+		lbrs := brs[lbi:bi]
+		sn := len(stack) // open brackets, less those closed at the start of the line
+		for _, br := range lbrs {
+			if br.IsOpen() || sn == 0 || stack[sn-1].r != BracketOpenFor(br.Rune) || !onlyClosers(lt[:br.Pos.Ch]) {
+				break
+			}
+			sn--
+		}
+		switch {
+		case blocked[ln]:
+			nlns[ln] = string(lt)
+		case txt == "":
+			nlns[ln] = ""
+		default:
+			lev := 0
+			for i := 0; i < sn; i++ {
+				if i == 0 || stack[i].ln != stack[i-1].ln {
+					lev++
+				}
+			}
+			if outdentCase && lev > 0 && CaseLabelRe.MatchString(txt) {
+				lev--
+			}
+			nlns[ln] = strings.Repeat(ind, lev) + strings.TrimRightFunc(txt, unicode.IsSpace)
+		}
+		for _, br := range lbrs {
+			n := len(stack)
+			switch {
+			case br.IsOpen():
+				stack = append(stack, open{ln, br.Rune})
+			case n > 0 && stack[n-1].r == BracketOpenFor(br.Rune):
+				stack = stack[:n-1]
+			}
+		}
+	}
+	return nlns
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// NormalizeIndentLines returns the lines with their indentation converted
+// to given indent string, keeping their levels: the indent unit of the
+// lines is the greatest common divisor of their indentation widths (with
+// tabs of given size) -- blocked lines (from BlockedLines) are unchanged
+// and blank lines are emptied
+func NormalizeIndentLines(lines [][]rune, blocked []bool, ind string, tabsz int) []string {
+	unit := 0
+	for ln, lt := range lines {
+		if id := lineIndent(lt, tabsz); id > 0 && !blocked[ln] {
+			unit = gcd(unit, id)
+		}
+	}
+	nlns := make([]string, len(lines))
+	for ln, lt := range lines {
+		txt := strings.TrimLeftFunc(string(lt), unicode.IsSpace)
+		switch {
+		case blocked[ln]:
+			nlns[ln] = string(lt)
+		case txt == "":
+			nlns[ln] = ""
+		case unit < 2: // no consistent indent unit
+			nlns[ln] = strings.TrimRightFunc(string(lt), unicode.IsSpace)
+		default:
+			lev := lineIndent(lt, tabsz) / unit
+			nlns[ln] = strings.Repeat(ind, lev) + strings.TrimRightFunc(txt, unicode.IsSpace)
+		}
+	}
+	return nlns
+}
+
+// KeepsIndent returns true if indentation is syntax in files of given
+// language and name -- see KeepIndentLangs
+func KeepsIndent(sup filecat.Supported, fname string) bool {
+	if KeepIndentLangs[sup] {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(fname))
+	for _, ex := range KeepIndentExts {
+		if ex == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// ReformatLines returns the lines of a file of given language and name
+// re-indented with given indent string and tab size, by bracket nesting,
+// or by normalizing the indentation where it is syntax -- returns false if
+// files of this language or name are not re-indented (NoReformatLangs,
+// NoReformatFiles)
+func ReformatLines(lines [][]rune, sup filecat.Supported, fname string, syn *BracketSyntax, ind string, tabsz int) ([]string, bool) {
+	if NoReformatLangs[sup] {
+		return nil, false
+	}
+	base := filepath.Base(fname)
+	ext := strings.ToLower(filepath.Ext(fname))
+	for _, nf := range NoReformatFiles {
+		if nf == base || nf == ext {
+			return nil, false
+		}
+	}
+	blocked, raws := BlockedLines(lines, syn)
+	if KeepsIndent(sup, fname) {
+		return NormalizeIndentLines(lines, blocked, ind, tabsz), true
+	}
+	brs := BracketsOutside(ScanBrackets(lines, syn), raws)
+	return ReindentLines(lines, brs, blocked, ind, OutdentCaseLangs[sup]), true
+}
+
+// ReplaceChangedLines replaces the lines of the buffer with the new lines,
+// as one edit of the range from the first to the last changed line, which
+// can be undone -- returns the number of changed lines
+func ReplaceChangedLines(tb *giv.TextBuf, nlns []string) int {
+	olns := BufLines(tb)
+	st := 0
+	for st < len(olns) && st < len(nlns) && olns[st] == nlns[st] {
+		st++
+	}
+	if st == len(olns) && st == len(nlns) {
+		return 0
+	}
+	oed, ned := len(olns), len(nlns)
+	for oed > st && ned > st && olns[oed-1] == nlns[ned-1] {
+		oed--
+		ned--
+	}
+	for (oed == st || ned == st) && st > 0 { // both ranges must have lines
+		st--
+	}
+	for oed == st || ned == st {
+		oed++
+		ned++
+	}
+	ob := &giv.TextBuf{}
+	ob.InitName(ob, "reformat-buf")
+	ob.SetText([]byte(strings.Join(olns[st:oed], "\n")))
+	nb := &giv.TextBuf{}
+	nb.InitName(nb, "reformat-buf")
+	nb.SetText([]byte(strings.Join(nlns[st:ned], "\n")))
+	nch := 0
+	for _, df := range ob.DiffBufs(nb) {
+		if df.Tag == 'e' {
+			continue
+		}
+		if n := df.I2 - df.I1; n > df.J2-df.J1 {
+			nch += n
+		} else {
+			nch += df.J2 - df.J1
+		}
+	}
+	ReplaceLines(tb, st, oed, nlns[st:ned])
+	return nch
+}
+
+// ReformatFile reformats the whole buffer of the view: with the completion
+// provider for its language if it formats, else with the Formatter of its
+// language in AvailLangs if set and installed, else by re-indenting the
+// lines with the indentation of the buffer (see ReformatLines) -- returns
+// the number of changed lines and the formatter used ("" if re-indented)
+func (tv *TextView) ReformatFile() (int, string, error) {
+	tb := tv.Buf
+	if tb == nil {
+		return 0, "", fmt.Errorf("no file to reformat")
+	}
+	sup := tb.Info.Sup
+	fname := string(tb.Filename)
+	olns := BufLines(tb)
+	if cp := BufCompletionProvider(tb); cp != nil {
+		ge, _ := ParentGide(tv.This())
+		out, ok, err := cp.Format(ge, tb, []byte(strings.Join(olns, "\n")))
+		if err != nil {
+			return 0, cp.Name(), err
+		}
+		if ok {
+			return ReplaceChangedLines(tb, strings.Split(string(out), "\n")), cp.Name(), nil
+		}
+	}
+	if fmtr := LangFormatter(sup); fmtr != "" {
+		if _, err := exec.LookPath(strings.Fields(fmtr)[0]); err == nil {
+			out, err := RunFormatter(fmtr, []byte(strings.Join(olns, "\n")), filepath.Dir(fname))
+			if err != nil {
+				return 0, fmtr, err
+			}
+			return ReplaceChangedLines(tb, strings.Split(string(out), "\n")), fmtr, nil
+		}
+	}
+	syn := BufBracketSyntax(tb)
+	ind := "\t"
+	tabsz := tb.Opts.TabSize
+	if tabsz <= 0 {
+		tabsz = 4
+	}
+	if tb.Opts.SpaceIndent {
+		ind = strings.Repeat(" ", tabsz)
+	}
+	lines := make([][]rune, len(olns))
+	for i, l := range olns {
+		lines[i] = []rune(l)
+	}
+	nlns, ok := ReformatLines(lines, sup, fname, &syn, ind, tabsz)
+	if !ok {
+		return 0, "", fmt.Errorf("files of type: %v are not reformatted", sup)
+	}
+	return ReplaceChangedLines(tb, nlns), "", nil
+}
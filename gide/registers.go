@@ -6,9 +6,14 @@ package gide
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/giv"
@@ -33,20 +38,94 @@ var AvailRegisters Registers
 // AvailRegisterNames are the names of the current AvailRegisters -- used for some choosers
 var AvailRegisterNames []string
 
-// Names returns a slice of current register names
+// RegisterPreviewLen is the max number of chars of the first line of a
+// register shown in the register chooser
+var RegisterPreviewLen = 30
+
+// KillRegisters is the number of numbered registers, 1 through
+// KillRegisters, that hold the most recent kills (cut, kill line, delete
+// line), 1 being the most recent -- see AddKill
+var KillRegisters = 9
+
+// IsKillRegister returns true if the name is that of a numbered kill
+// register
+func IsKillRegister(name string) bool {
+	n, err := strconv.Atoi(name)
+	return err == nil && n >= 1 && n <= KillRegisters
+}
+
+// RegisterPreview returns a preview of the register contents for the
+// register chooser: its first line, shortened to RegisterPreviewLen, and
+// its length
+func RegisterPreview(val string) string {
+	fl := val
+	if li := strings.IndexByte(fl, '\n'); li >= 0 {
+		fl = fl[:li]
+	}
+	fl = strings.TrimSpace(fl)
+	if rs := []rune(fl); len(rs) > RegisterPreviewLen {
+		fl = string(rs[:RegisterPreviewLen]) + "..."
+	}
+	return fmt.Sprintf("%v (%v chars)", fl, utf8.RuneCountInString(val))
+}
+
+// Names returns a slice of current register names, each followed by a
+// preview of its contents (see RegisterPreview) -- named registers come
+// first, in alphabetical order, and then the numbered kill registers
 func (lt *Registers) Names() []string {
-	nms := make([]string, len(*lt))
-	i := 0
-	for key, val := range *lt {
-		if len(val) > 20 {
-			val = val[:20]
+	keys := make([]string, 0, len(*lt))
+	for key := range *lt {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ki, kj := IsKillRegister(keys[i]), IsKillRegister(keys[j])
+		if ki != kj {
+			return kj
 		}
-		nms[i] = key + ": " + val
-		i++
+		if ki {
+			ni, _ := strconv.Atoi(keys[i])
+			nj, _ := strconv.Atoi(keys[j])
+			return ni < nj
+		}
+		return keys[i] < keys[j]
+	})
+	nms := make([]string, len(keys))
+	for i, key := range keys {
+		nms[i] = key + ": " + RegisterPreview((*lt)[key])
 	}
 	return nms
 }
 
+// Append appends the text to the register of given name, creating it if
+// it does not exist yet
+func (lt *Registers) Append(name, txt string) {
+	if *lt == nil {
+		*lt = make(Registers, 100)
+	}
+	(*lt)[name] += txt
+}
+
+// AddKill adds killed text to the numbered kill registers, as register 1,
+// shifting the previous kills to the next numbers, and dropping the last
+// one -- blank text is ignored
+func (lt *Registers) AddKill(txt string) {
+	if strings.TrimSpace(txt) == "" {
+		return
+	}
+	if *lt == nil {
+		*lt = make(Registers, 100)
+	}
+	for n := KillRegisters; n > 1; n-- {
+		if val, has := (*lt)[strconv.Itoa(n-1)]; has {
+			(*lt)[strconv.Itoa(n)] = val
+		}
+	}
+	(*lt)["1"] = txt
+	if lt == &AvailRegisters {
+		AvailRegisterNames = lt.Names()
+	}
+}
+
 // PrefsRegistersFileName is the name of the preferences file in App prefs
 // directory for saving / loading the default AvailRegisters
 var PrefsRegistersFileName = "registers_prefs.json"
@@ -0,0 +1,315 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ReleasePrefs are the project settings for building release artifacts: the
+// Go package is cross-compiled for each of the Targets, and each binary is
+// archived with the extra Files (zip for windows, tar.gz otherwise), with
+// an optional SHA256SUMS file of the archives
+type ReleasePrefs struct {
+	Name         string        `desc:"base name of the program and artifacts -- defaults to the name of the project root directory"`
+	Version      string        `desc:"version used in the artifact names -- if empty, the latest git tag is used (git describe --tags)"`
+	Pkg          string        `desc:"Go package to build, relative to the project root, e.g., ./cmd/myprog -- defaults to ."`
+	Targets      []string      `desc:"platforms to cross-compile for, as GOOS/GOARCH, e.g., linux/amd64, darwin/amd64, windows/amd64 -- defaults to the current platform"`
+	Files        []string      `desc:"extra files to include in each archive, relative to the project root, e.g., README.md, LICENSE"`
+	LDFlags      string        `desc:"-ldflags for go build, with {Version} replaced by the version, e.g., -s -w -X main.version={Version}"`
+	CGo          bool          `desc:"build with cgo enabled -- cross-compiling generally requires it to be off"`
+	OutDir       string        `desc:"directory for the artifacts, relative to the project root -- defaults to dist"`
+	Checksums    bool          `desc:"write a SHA256SUMS file with the sha256 checksums of the archives, in sha256sum format"`
+	UploadCmd    string        `desc:"command to upload an artifact, with {Path} replaced by its path and {Version} by the version, e.g., gh release upload {Version} {Path}"`
+	VersionFiles []VersionFile `desc:"files with version constants to update in Prepare Release, each with a regexp whose first group matches the version"`
+	Changelog    string        `desc:"changelog file, relative to the project root, into which Prepare Release inserts the drafted section for the release -- defaults to CHANGELOG.md"`
+}
+
+// ReleaseArtifact is an artifact produced by a release build
+type ReleaseArtifact struct {
+	Path   string `desc:"path to the artifact"`
+	Target string `desc:"GOOS/GOARCH target of the binary in it, or empty for others (e.g., SHA256SUMS)"`
+	Size   int64  `desc:"size in bytes"`
+	SHA256 string `desc:"sha256 checksum"`
+}
+
+// ReleaseName returns the program name for the release
+func (rp *ReleasePrefs) ReleaseName(root string) string {
+	if rp.Name != "" {
+		return rp.Name
+	}
+	return filepath.Base(root)
+}
+
+// ReleaseVersion returns the version for the release: Version if set, else
+// the latest git tag, else dev
+func (rp *ReleasePrefs) ReleaseVersion(root string) string {
+	if rp.Version != "" {
+		return rp.Version
+	}
+	cmd := exec.Command("git", "describe", "--tags", "--always")
+	cmd.Dir = root
+	if out, err := cmd.Output(); err == nil {
+		if v := strings.TrimSpace(string(out)); v != "" {
+			return v
+		}
+	}
+	return "dev"
+}
+
+// ChangelogPath returns the absolute path of the changelog file
+func (rp *ReleasePrefs) ChangelogPath(root string) string {
+	cl := rp.Changelog
+	if cl == "" {
+		cl = "CHANGELOG.md"
+	}
+	if filepath.IsAbs(cl) {
+		return cl
+	}
+	return filepath.Join(root, cl)
+}
+
+// ReleaseTargets returns the GOOS/GOARCH targets, defaulting to the
+// current platform
+func (rp *ReleasePrefs) ReleaseTargets() []string {
+	if len(rp.Targets) > 0 {
+		return rp.Targets
+	}
+	return []string{runtime.GOOS + "/" + runtime.GOARCH}
+}
+
+// ReleaseDir returns the absolute output directory for the artifacts
+func (rp *ReleasePrefs) ReleaseDir(root string) string {
+	od := rp.OutDir
+	if od == "" {
+		od = "dist"
+	}
+	if filepath.IsAbs(od) {
+		return od
+	}
+	return filepath.Join(root, od)
+}
+
+// NSteps returns the number of steps in building the release, for progress
+func (rp *ReleasePrefs) NSteps() int {
+	n := 2 * len(rp.ReleaseTargets())
+	if rp.Checksums {
+		n++
+	}
+	return n
+}
+
+// BuildRelease builds the release artifacts for the project at given root,
+// calling progress with a message before each step -- returns the
+// artifacts produced, and an error with the output of any failed build
+func (rp *ReleasePrefs) BuildRelease(root string, progress func(step int, msg string)) ([]ReleaseArtifact, error) {
+	name := rp.ReleaseName(root)
+	vers := rp.ReleaseVersion(root)
+	odir := rp.ReleaseDir(root)
+	if err := os.MkdirAll(odir, 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := ioutil.TempDir("", "gide-release")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	pkg := rp.Pkg
+	if pkg == "" {
+		pkg = "."
+	}
+	var arts []ReleaseArtifact
+	step := 0
+	for _, targ := range rp.ReleaseTargets() {
+		osarch := strings.SplitN(targ, "/", 2)
+		if len(osarch) != 2 {
+			return arts, fmt.Errorf("target %q is not GOOS/GOARCH", targ)
+		}
+		goos, goarch := osarch[0], osarch[1]
+		bin := name
+		if goos == "windows" {
+			bin += ".exe"
+		}
+		step++
+		progress(step, fmt.Sprintf("Building %v for %v", name, targ))
+		bpath := filepath.Join(tmp, targ, bin)
+		args := []string{"build", "-o", bpath}
+		if rp.LDFlags != "" {
+			args = append(args, "-ldflags", strings.Replace(rp.LDFlags, "{Version}", vers, -1))
+		}
+		cmd := exec.Command("go", append(args, pkg)...)
+		cmd.Dir = root
+		cgo := "0"
+		if rp.CGo {
+			cgo = "1"
+		}
+		cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED="+cgo)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return arts, fmt.Errorf("go build for %v failed: %v\n%s", targ, err, out)
+		}
+		step++
+		base := fmt.Sprintf("%v-%v-%v-%v", name, vers, goos, goarch)
+		files := []string{bpath}
+		for _, f := range rp.Files {
+			files = append(files, filepath.Join(root, f))
+		}
+		var apath string
+		if goos == "windows" {
+			apath = filepath.Join(odir, base+".zip")
+			progress(step, fmt.Sprintf("Archiving %v", filepath.Base(apath)))
+			err = WriteZip(apath, base, files)
+		} else {
+			apath = filepath.Join(odir, base+".tar.gz")
+			progress(step, fmt.Sprintf("Archiving %v", filepath.Base(apath)))
+			err = WriteTarGz(apath, base, files)
+		}
+		if err != nil {
+			return arts, err
+		}
+		art, err := NewReleaseArtifact(apath, targ)
+		if err != nil {
+			return arts, err
+		}
+		arts = append(arts, art)
+	}
+	if rp.Checksums {
+		step++
+		progress(step, "Writing SHA256SUMS")
+		var sb strings.Builder
+		for _, a := range arts {
+			sb.WriteString(a.SHA256 + "  " + filepath.Base(a.Path) + "\n")
+		}
+		spath := filepath.Join(odir, "SHA256SUMS")
+		if err := ioutil.WriteFile(spath, []byte(sb.String()), 0644); err != nil {
+			return arts, err
+		}
+		art, err := NewReleaseArtifact(spath, "")
+		if err != nil {
+			return arts, err
+		}
+		arts = append(arts, art)
+	}
+	return arts, nil
+}
+
+// NewReleaseArtifact returns the artifact info for the file at given path
+func NewReleaseArtifact(fpath, targ string) (ReleaseArtifact, error) {
+	art := ReleaseArtifact{Path: fpath, Target: targ}
+	fi, err := os.Stat(fpath)
+	if err != nil {
+		return art, err
+	}
+	art.Size = fi.Size()
+	art.SHA256, err = FileChecksum(fpath, "sha256")
+	return art, err
+}
+
+// UploadArtifactCmd returns the command for uploading the artifact, per the
+// UploadCmd prefs
+func (rp *ReleasePrefs) UploadArtifactCmd(root, fpath string) (*exec.Cmd, error) {
+	if strings.TrimSpace(rp.UploadCmd) == "" {
+		return nil, fmt.Errorf("no upload command is set in the Release project prefs")
+	}
+	vers := rp.ReleaseVersion(root)
+	var args []string
+	for _, a := range strings.Fields(rp.UploadCmd) {
+		a = strings.Replace(a, "{Path}", fpath, -1)
+		args = append(args, strings.Replace(a, "{Version}", vers, -1))
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = root
+	return cmd, nil
+}
+
+// WriteTarGz writes a gzipped tar archive of the files, within directory
+// dir in the archive
+func WriteTarGz(apath, dir string, files []string) error {
+	f, err := os.Create(apath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, fp := range files {
+		fi, err := os.Stat(fp)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = dir + "/" + filepath.Base(fp)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if err := copyFileTo(tw, fp); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// WriteZip writes a zip archive of the files, within directory dir in the
+// archive
+func WriteZip(apath, dir string, files []string) error {
+	f, err := os.Create(apath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, fp := range files {
+		fi, err := os.Stat(fp)
+		if err != nil {
+			return err
+		}
+		hdr, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return err
+		}
+		hdr.Name = dir + "/" + filepath.Base(fp)
+		hdr.Method = zip.Deflate
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		if err := copyFileTo(w, fp); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// copyFileTo copies the contents of the file to w
+func copyFileTo(w io.Writer, fpath string) error {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
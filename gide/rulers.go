@@ -0,0 +1,138 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/filecat"
+)
+
+// RulerBlend is the percent blend of the text color into the background
+// color for the column ruler lines
+var RulerBlend = float32(20)
+
+// LongLineColor is the color of the marks under the part of lines that
+// extends past the last ruler, if LongLines is on
+var LongLineColor = color.RGBA{0xce, 0x42, 0x52, 0xff}
+
+// LongLineMarkHeight is the height of the long line marks, in dots
+var LongLineMarkHeight = 2
+
+// LangRulers returns the ruler columns for files of given language: those
+// of the Lang Opts if set, else those of the editor prefs
+func LangRulers(sup filecat.Supported, ep *EditorPrefs) []int {
+	if lo, has := AvailLangs[sup]; has && len(lo.Rulers) > 0 {
+		return lo.Rulers
+	}
+	return ep.Rulers
+}
+
+// SetRulers sets the ruler columns and long line marking for this view
+func (tv *TextView) SetRulers(rulers []int, longLines bool) {
+	tv.Rulers = nil
+	for _, col := range rulers {
+		if col > 0 {
+			tv.Rulers = append(tv.Rulers, col)
+		}
+	}
+	tv.LongLines = longLines
+}
+
+// LineLimit returns the column past which lines are marked as too long: the
+// last (largest) ruler, or 0 if there are none
+func (tv *TextView) LineLimit() int {
+	lim := 0
+	for _, col := range tv.Rulers {
+		if col > lim {
+			lim = col
+		}
+	}
+	return lim
+}
+
+// LimitChar returns the index of the first char of the line past given
+// column limit, with tabs expanded, or -1 if the line fits
+func LimitChar(lt []rune, lim, tabsz int) int {
+	col := 0
+	for ch, r := range lt {
+		if col >= lim {
+			return ch
+		}
+		if r == '\t' {
+			col += tabsz - col%tabsz
+		} else {
+			col++
+		}
+	}
+	return -1
+}
+
+// RenderRulers draws the column ruler lines over the visible lines of the
+// view, and marks the part of lines past the last ruler if LongLines is on
+// -- the lines and marks are opaque, so this can be redrawn at any time
+func (tv *TextView) RenderRulers() {
+	if len(tv.Rulers) == 0 || tv.NLines == 0 || tv.Viewport == nil || tv.VpBBox.Empty() {
+		return
+	}
+	if len(tv.Renders) < tv.NLines || len(tv.Offs) < tv.NLines {
+		return
+	}
+	img := tv.Viewport.Pixels
+	bb := tv.VpBBox
+	sty := &tv.Sty
+	tx := tv.RenderStartPos().X + tv.LineNoOff
+	tbb := bb
+	tbb.Min.X = int(tx)
+	chw := sty.Font.Face.Metrics.Ch
+	rclr := sty.Font.BgColor.Color.Blend(RulerBlend, &sty.Font.Color)
+	ruc := image.NewUniform(rclr)
+	for _, col := range tv.Rulers {
+		x := int(tx + float32(col)*chw)
+		r := image.Rect(x, bb.Min.Y, x+1, bb.Max.Y).Intersect(tbb)
+		if !r.Empty() {
+			draw.Draw(img, r, ruc, image.ZP, draw.Src)
+		}
+	}
+	if !tv.LongLines || tv.Buf == nil {
+		return
+	}
+	lim := tv.LineLimit()
+	tabsz := sty.Text.TabSize
+	if tabsz <= 0 {
+		tabsz = 4
+	}
+	llc := image.NewUniform(LongLineColor)
+	mh := float32(LongLineMarkHeight)
+	st := tv.FirstVisibleLine(0)
+	ed := tv.LastVisibleLine(st)
+	for ln := st; ln <= ed && ln < tv.Buf.NumLines(); ln++ {
+		lt := tv.Buf.Line(ln)
+		ch := LimitChar(lt, lim, tabsz)
+		if ch < 0 {
+			continue
+		}
+		spos := tv.CharStartPos(giv.TextPos{Ln: ln, Ch: ch})
+		epos := tv.CharStartPos(giv.TextPos{Ln: ln, Ch: len(lt)})
+		hl := tv.LineHeight / 2
+		for y := spos.Y; y < epos.Y+hl; y += tv.LineHeight { // each wrapped row
+			x0, x1 := tx, float32(bb.Max.X)
+			if y == spos.Y {
+				x0 = spos.X
+			}
+			if y+hl > epos.Y {
+				x1 = epos.X
+			}
+			yb := y + tv.LineHeight
+			r := image.Rect(int(x0), int(yb-mh), int(x1), int(yb)).Intersect(tbb)
+			if !r.Empty() {
+				draw.Draw(img, r, llc, image.ZP, draw.Src)
+			}
+		}
+	}
+}
@@ -0,0 +1,1554 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/complete"
+)
+
+// SchemaPrefs are the preferences for the completion, checking and hover
+// docs of well-known config files from their JSON Schemas
+type SchemaPrefs struct {
+	On      bool   `desc:"complete the keys and values of well-known JSON, YAML and TOML config files, e.g., package.json, tsconfig.json, GitHub Actions workflows, docker-compose.yml and Kubernetes manifests, from their JSON Schemas, check them against the schemas as they are edited (see Data Check in the editor prefs), and show the docs of their keys on hover -- the schemas are downloaded when first needed, and cached in the preferences directory"`
+	Catalog string `desc:"url (or file path) of the catalog of JSON Schemas, in the format of the schemastore.org catalog, giving the schemas for file names -- the built-in SchemaFiles are used for those it does not cover, and until it is downloaded"`
+}
+
+// DefaultSchemaCatalog is the default catalog of JSON Schemas
+var DefaultSchemaCatalog = "https://www.schemastore.org/api/json/catalog.json"
+
+// Defaults sets the default schema prefs
+func (sp *SchemaPrefs) Defaults() {
+	sp.On = true
+	sp.Catalog = DefaultSchemaCatalog
+}
+
+// SchemaFile gives the JSON Schema for the config files matching any of
+// its patterns -- also the format of the entries of the catalog
+type SchemaFile struct {
+	Name  string   `json:"name" desc:"name of the kind of file"`
+	Match []string `json:"fileMatch" desc:"glob patterns of the files, matched against the file name, or the end of the path if they have a /, e.g., **/.github/workflows/*.yml"`
+	URL   string   `json:"url" desc:"url of the JSON Schema"`
+}
+
+// SchemaFiles are the built-in schemas of the well-known config files,
+// used when the catalog does not give one
+var SchemaFiles = []SchemaFile{
+	{"package.json", []string{"package.json"}, "https://json.schemastore.org/package.json"},
+	{"tsconfig.json", []string{"tsconfig.json", "tsconfig.*.json", "tsconfig-*.json"}, "https://json.schemastore.org/tsconfig.json"},
+	{"GitHub Actions workflow", []string{"**/.github/workflows/*.yml", "**/.github/workflows/*.yaml"}, "https://json.schemastore.org/github-workflow.json"},
+	{"GitHub Action", []string{"action.yml", "action.yaml"}, "https://json.schemastore.org/github-action.json"},
+	{"Docker Compose", []string{"docker-compose.yml", "docker-compose.yaml", "docker-compose.*.yml", "docker-compose.*.yaml", "compose.yml", "compose.yaml"}, "https://raw.githubusercontent.com/compose-spec/compose-spec/master/schema/compose-spec.json"},
+}
+
+// KubernetesSchemaURL is the url of the JSON Schemas of Kubernetes
+// manifests, recognized by their apiVersion and kind -- %v is replaced
+// with the kind, group and version, e.g., deployment-apps-v1
+var KubernetesSchemaURL = "https://raw.githubusercontent.com/yannh/kubernetes-json-schema/master/master-standalone-strict/%v.json"
+
+// SchemaCacheDays is the number of days after which a cached schema or
+// catalog is downloaded again -- the cached one is used if that fails
+var SchemaCacheDays = 7
+
+// SchemaRetryMins is the number of minutes after which a schema that could
+// not be loaded is tried again
+var SchemaRetryMins = 10
+
+// SchemaMaxSize is the maximum size of a downloaded schema or catalog
+var SchemaMaxSize = int64(32 * 1024 * 1024)
+
+// SchemaHeadLines is the number of lines at the start of a buffer that are
+// searched for a $schema or the apiVersion and kind of Kubernetes
+var SchemaHeadLines = 200
+
+// SchemaMaxDepth is the maximum depth of references and nesting followed
+// in a schema
+var SchemaMaxDepth = 64
+
+// schemaItem is the key in schema paths for the elements of a list
+const schemaItem = "[]"
+
+// SchemaMatch returns true if the glob pattern of a SchemaFile matches the
+// file path: patterns with a / match the end of the path, ** matching any
+// one directory, and others the file name -- negated patterns never match
+func SchemaMatch(pat, fpath string) bool {
+	if pat == "" || pat[0] == '!' {
+		return false
+	}
+	fpath = filepath.ToSlash(fpath)
+	if !strings.Contains(pat, "/") {
+		ok, _ := path.Match(pat, path.Base(fpath))
+		return ok
+	}
+	pps := strings.Split(strings.TrimPrefix(pat, "**/"), "/")
+	fps := strings.Split(fpath, "/")
+	if len(fps) < len(pps) {
+		return false
+	}
+	fps = fps[len(fps)-len(pps):]
+	for i, pp := range pps {
+		if pp == "**" {
+			continue
+		}
+		if ok, _ := path.Match(pp, fps[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// schemaFileURL returns the url of the first of the schema files matching
+// the file path
+func schemaFileURL(sfs []SchemaFile, fpath string) string {
+	for _, sf := range sfs {
+		for _, pat := range sf.Match {
+			if SchemaMatch(pat, fpath) {
+				return sf.URL
+			}
+		}
+	}
+	return ""
+}
+
+var (
+	schemaRefRe     = regexp.MustCompile(`"\$schema"\s*[:=]\s*"([^"]+)"`)
+	schemaModeRe    = regexp.MustCompile(`(?m)^#\s*(?:yaml-language-server:\s*\$schema=|:schema\s+)(\S+)`)
+	schemaAPIVersRe = regexp.MustCompile(`(?m)^apiVersion:\s*["']?([\w./-]+)`)
+	schemaKindRe    = regexp.MustCompile(`(?m)^kind:\s*["']?(\w+)`)
+	schemaDocSepRe  = regexp.MustCompile(`(?m)^---`)
+)
+
+// KubernetesSchema returns the url of the schema of the Kubernetes
+// manifest with the text, from the apiVersion and kind of its first
+// document, or "" if it does not have them
+func KubernetesSchema(txt []byte) string {
+	if seps := schemaDocSepRe.FindAllIndex(txt, 2); len(seps) > 0 {
+		st := 0
+		if len(bytes.TrimSpace(txt[:seps[0][0]])) == 0 {
+			st = seps[0][1]
+			seps = seps[1:]
+		}
+		ed := len(txt)
+		if len(seps) > 0 {
+			ed = seps[0][0]
+		}
+		txt = txt[st:ed]
+	}
+	av := schemaAPIVersRe.FindSubmatch(txt)
+	kd := schemaKindRe.FindSubmatch(txt)
+	if av == nil || kd == nil {
+		return ""
+	}
+	nm := strings.ToLower(string(kd[1]))
+	gv := strings.Split(string(av[1]), "/")
+	if len(gv) > 1 {
+		nm += "-" + strings.Split(gv[0], ".")[0]
+	}
+	return fmt.Sprintf(KubernetesSchemaURL, nm+"-"+gv[len(gv)-1])
+}
+
+// SchemaURL returns the url (or file path) of the JSON Schema of the data
+// file, given the text at its start: from its $schema key (or a
+// yaml-language-server: $schema= or #:schema comment), the catalog, the
+// built-in SchemaFiles, or the apiVersion and kind of a Kubernetes
+// manifest -- waiting for the catalog to be loaded if wait -- "" if there
+// is none
+func SchemaURL(fpath string, head []byte, wait bool) string {
+	var ref []byte
+	if m := schemaRefRe.FindSubmatch(head); m != nil {
+		ref = m[1]
+	} else if m := schemaModeRe.FindSubmatch(head); m != nil {
+		ref = m[1]
+	}
+	if sr := string(ref); sr != "" && !strings.Contains(sr, "json-schema.org/") {
+		if strings.Contains(sr, "://") || filepath.IsAbs(sr) {
+			return sr
+		}
+		return filepath.Join(filepath.Dir(fpath), sr)
+	}
+	if cat := SchemaCatalog(wait); cat != nil {
+		if u := schemaFileURL(cat, fpath); u != "" {
+			return u
+		}
+	}
+	if u := schemaFileURL(SchemaFiles, fpath); u != "" {
+		return u
+	}
+	if DataFormat(fpath) == "yaml" {
+		return KubernetesSchema(head)
+	}
+	return ""
+}
+
+// schemaLoad is a schema or catalog that is loaded, or being loaded
+type schemaLoad struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+	time time.Time
+}
+
+var (
+	schemaLoads = map[string]*schemaLoad{}
+	schemaMu    sync.Mutex
+)
+
+// schemaGet returns the schema or catalog at given url, loading it in the
+// background with parse if it is not loaded -- waiting for it if wait, and
+// otherwise returning nil while it is being loaded
+func schemaGet(ur string, wait bool, parse func(b []byte) (interface{}, error)) (interface{}, error) {
+	schemaMu.Lock()
+	ld := schemaLoads[ur]
+	if ld != nil {
+		select {
+		case <-ld.done:
+			if ld.err != nil && time.Since(ld.time) > time.Duration(SchemaRetryMins)*time.Minute {
+				ld = nil
+			}
+		default:
+		}
+	}
+	if ld == nil {
+		ld = &schemaLoad{done: make(chan struct{})}
+		schemaLoads[ur] = ld
+		go func() {
+			b, err := schemaFetch(ur)
+			if err == nil {
+				ld.val, err = parse(b)
+			}
+			ld.err = err
+			ld.time = time.Now()
+			close(ld.done)
+		}()
+	}
+	schemaMu.Unlock()
+	if wait {
+		<-ld.done
+	} else {
+		select {
+		case <-ld.done:
+		default:
+			return nil, nil
+		}
+	}
+	return ld.val, ld.err
+}
+
+// SchemaCacheDir returns the directory where the downloaded schemas are
+// cached
+func SchemaCacheDir() string {
+	return filepath.Join(AppPrefsDir(), "schemas")
+}
+
+// schemaCacheFile returns the file of the cached download of the url
+func schemaCacheFile(ur string) string {
+	h := fnv.New64a()
+	h.Write([]byte(ur))
+	nm := strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, path.Base(ur))
+	return filepath.Join(SchemaCacheDir(), fmt.Sprintf("%016x_%v", h.Sum64(), nm))
+}
+
+// schemaFetch returns the contents of the schema or catalog at the url,
+// from the cache if it was downloaded in the last SchemaCacheDays, and
+// otherwise downloading it, falling back on the cache -- urls that are not
+// http(s) are read as files
+func schemaFetch(ur string) ([]byte, error) {
+	if !strings.HasPrefix(ur, "http://") && !strings.HasPrefix(ur, "https://") {
+		return ioutil.ReadFile(strings.TrimPrefix(ur, "file://"))
+	}
+	cfn := schemaCacheFile(ur)
+	st, serr := os.Stat(cfn)
+	if serr == nil && time.Since(st.ModTime()) < time.Duration(SchemaCacheDays)*24*time.Hour {
+		return ioutil.ReadFile(cfn)
+	}
+	b, err := schemaDownload(ur)
+	if err != nil {
+		if serr == nil {
+			return ioutil.ReadFile(cfn)
+		}
+		return nil, err
+	}
+	os.MkdirAll(SchemaCacheDir(), 0755)
+	ioutil.WriteFile(cfn, b, 0644)
+	return b, nil
+}
+
+// schemaDownload downloads the schema or catalog at the url
+func schemaDownload(ur string) ([]byte, error) {
+	req, err := http.NewRequest("GET", ur, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "gide/"+Version)
+	cl := &http.Client{Timeout: 20 * time.Second}
+	resp, err := cl.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download of %v failed: %v", ur, resp.Status)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, SchemaMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > SchemaMaxSize {
+		return nil, fmt.Errorf("download of %v failed: larger than %v bytes", ur, SchemaMaxSize)
+	}
+	return b, nil
+}
+
+// SchemaCatalog returns the schema files of the catalog in the prefs,
+// loading it in the background if needed, and waiting for it if wait --
+// nil if it is not loaded, or could not be
+func SchemaCatalog(wait bool) []SchemaFile {
+	if Prefs.Schemas.Catalog == "" {
+		return nil
+	}
+	v, _ := schemaGet(Prefs.Schemas.Catalog, wait, func(b []byte) (interface{}, error) {
+		cat := struct {
+			Schemas []SchemaFile `json:"schemas"`
+		}{}
+		if err := json.Unmarshal(b, &cat); err != nil {
+			return nil, err
+		}
+		return cat.Schemas, nil
+	})
+	cat, _ := v.([]SchemaFile)
+	return cat
+}
+
+// LoadSchema returns the JSON Schema at the url (or file path), loading it
+// in the background if needed, and waiting for it if wait -- nil and no
+// error while it is being loaded
+func LoadSchema(ur string, wait bool) (*SchemaDoc, error) {
+	v, err := schemaGet(ur, wait, func(b []byte) (interface{}, error) {
+		sd := &SchemaDoc{URL: ur}
+		if err := json.Unmarshal(b, &sd.Root); err != nil {
+			return nil, fmt.Errorf("schema %v: %v", ur, err)
+		}
+		return sd, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sd, _ := v.(*SchemaDoc)
+	return sd, nil
+}
+
+// SchemaForFile returns the JSON Schema of the data file, given the text
+// at its start (see SchemaURL), if schemas are on -- waiting for it to be
+// loaded if wait -- nil and no error if there is none, or it is being
+// loaded
+func SchemaForFile(fpath string, head []byte, wait bool) (*SchemaDoc, error) {
+	if !Prefs.Schemas.On || DataFormat(fpath) == "" {
+		return nil, nil
+	}
+	ur := SchemaURL(fpath, head, wait)
+	if ur == "" {
+		return nil, nil
+	}
+	return LoadSchema(ur, wait)
+}
+
+// SchemaForBuf returns the JSON Schema of the data file of the buffer --
+// see SchemaForFile
+func SchemaForBuf(tb *giv.TextBuf, wait bool) (*SchemaDoc, error) {
+	fpath := string(tb.Filename)
+	if !Prefs.Schemas.On || DataFormat(fpath) == "" {
+		return nil, nil
+	}
+	var head bytes.Buffer
+	tb.LinesMu.RLock()
+	for ln := 0; ln < len(tb.Lines) && ln < SchemaHeadLines; ln++ {
+		head.WriteString(string(tb.Lines[ln]))
+		head.WriteByte('\n')
+	}
+	tb.LinesMu.RUnlock()
+	return SchemaForFile(fpath, head.Bytes(), wait)
+}
+
+// ValidateData returns the problems of the parsed data file (with given
+// text) against its JSON Schema (see SchemaForFile), waiting for the
+// schema to be loaded if wait -- a schema that could not be loaded is a
+// problem at the first line
+func ValidateData(fpath string, root *DataNode, txt []byte, wait bool) []DataProblem {
+	if root == nil {
+		return nil
+	}
+	sd, err := SchemaForFile(fpath, txt, wait)
+	if err != nil {
+		return []DataProblem{{Ln: 0, Msg: fmt.Sprintf("could not load the schema: %v", err)}}
+	}
+	if sd == nil {
+		return nil
+	}
+	return sd.Validate(root)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    JSON Schema
+
+// Schema is a JSON Schema, or a part of one -- only the keywords used for
+// completion, checking and docs are read: $ref (within the schema),
+// type, properties, patternProperties, additionalProperties, items,
+// required, enum, const, allOf, anyOf and oneOf, and the docs
+type Schema struct {
+	Ref        string             `json:"$ref" desc:"reference to another part of the schema, e.g., #/definitions/service"`
+	Type       SchemaTypes        `json:"type" desc:"allowed types: object, array, string, number, integer, boolean or null"`
+	Title      string             `json:"title" desc:"title of the value"`
+	Desc       string             `json:"description" desc:"description of the value"`
+	MDDesc     string             `json:"markdownDescription" desc:"description in markdown, used if there is no plain description"`
+	Props      map[string]*Schema `json:"properties" desc:"schemas of the keys of an object"`
+	PatProps   map[string]*Schema `json:"patternProperties" desc:"schemas of the keys of an object matching regexps"`
+	AddProps   *Schema            `json:"additionalProperties" desc:"schema of the other keys of an object -- false if they are not allowed"`
+	Items      SchemaItems        `json:"items" desc:"schema of the elements of an array, or of each element for a tuple"`
+	Required   SchemaRequired     `json:"required" desc:"keys that an object must have"`
+	Enum       []interface{}      `json:"enum" desc:"allowed values"`
+	Const      json.RawMessage    `json:"const" desc:"the only allowed value"`
+	Default    json.RawMessage    `json:"default" desc:"default value"`
+	AllOf      []*Schema          `json:"allOf" desc:"schemas that the value must all match"`
+	AnyOf      []*Schema          `json:"anyOf" desc:"schemas that the value must match one of"`
+	OneOf      []*Schema          `json:"oneOf" desc:"schemas that the value must match one of -- not checked to be exactly one"`
+	Defs       map[string]*Schema `json:"definitions" desc:"schemas referred to by $ref"`
+	Defs2      map[string]*Schema `json:"$defs" desc:"schemas referred to by $ref, in newer schemas"`
+	Deprecated bool               `json:"deprecated" desc:"value is deprecated"`
+	False      bool               `json:"-" desc:"schema is false: no value is allowed"`
+}
+
+// schemaJSON is Schema without its UnmarshalJSON
+type schemaJSON Schema
+
+// UnmarshalJSON reads the schema, which can be true or false -- values of
+// keywords of unexpected types are ignored, as with older schemas
+func (sc *Schema) UnmarshalJSON(b []byte) error {
+	switch string(bytes.TrimSpace(b)) {
+	case "true":
+		*sc = Schema{}
+		return nil
+	case "false":
+		*sc = Schema{False: true}
+		return nil
+	}
+	err := json.Unmarshal(b, (*schemaJSON)(sc))
+	if _, ok := err.(*json.UnmarshalTypeError); ok {
+		return nil
+	}
+	return err
+}
+
+// DocText returns the description of the schema: the description, or else
+// the markdown description or title
+func (sc *Schema) DocText() string {
+	switch {
+	case sc.Desc != "":
+		return sc.Desc
+	case sc.MDDesc != "":
+		return sc.MDDesc
+	}
+	return sc.Title
+}
+
+// SchemaTypes are the allowed types of a schema, given as one type or a
+// list of them
+type SchemaTypes []string
+
+// UnmarshalJSON reads one type or a list of them
+func (st *SchemaTypes) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		*st = SchemaTypes{s}
+		return nil
+	}
+	var ts []interface{}
+	json.Unmarshal(b, &ts)
+	*st = nil
+	for _, t := range ts {
+		if s, ok := t.(string); ok {
+			*st = append(*st, s)
+		}
+	}
+	return nil
+}
+
+// SchemaItems are the schemas of the elements of an array: one schema for
+// all of them, or one for each for a tuple
+type SchemaItems struct {
+	List  []*Schema `desc:"the schema of all the elements, or of each one of a tuple"`
+	Tuple bool      `desc:"items is a list of schemas, one for each element"`
+}
+
+// UnmarshalJSON reads one schema or a list of them
+func (si *SchemaItems) UnmarshalJSON(b []byte) error {
+	if bs := bytes.TrimSpace(b); len(bs) > 0 && bs[0] == '[' {
+		si.Tuple = true
+		return json.Unmarshal(bs, &si.List)
+	}
+	sc := &Schema{}
+	if err := json.Unmarshal(b, sc); err != nil {
+		return err
+	}
+	si.List = []*Schema{sc}
+	return nil
+}
+
+// Item returns the schema of element i, or nil if there is none
+func (si *SchemaItems) Item(i int) *Schema {
+	switch {
+	case len(si.List) == 0:
+		return nil
+	case !si.Tuple:
+		return si.List[0]
+	case i < len(si.List):
+		return si.List[i]
+	}
+	return nil
+}
+
+// SchemaRequired are the keys that an object must have -- the bool
+// required of older schemas is ignored
+type SchemaRequired []string
+
+// UnmarshalJSON reads the list of keys, ignoring other values
+func (sr *SchemaRequired) UnmarshalJSON(b []byte) error {
+	var ks []string
+	json.Unmarshal(b, &ks)
+	*sr = ks
+	return nil
+}
+
+// SchemaDoc is a loaded JSON Schema document
+type SchemaDoc struct {
+	URL  string                    `desc:"url (or file path) of the schema"`
+	Root Schema                    `desc:"root schema"`
+	res  map[string]*regexp.Regexp `desc:"compiled patterns of patternProperties -- nil for those Go cannot compile"`
+	mu   sync.Mutex                `desc:"mutex protecting res"`
+}
+
+// Pointer returns the part of the schema at the $ref within the schema,
+// e.g., #/definitions/service -- nil if it is in another schema, or not
+// found
+func (sd *SchemaDoc) Pointer(ref string) *Schema {
+	hi := strings.Index(ref, "#")
+	if hi < 0 || (hi > 0 && ref[:hi] != sd.URL) {
+		return nil
+	}
+	sc := &sd.Root
+	segs := strings.Split(strings.TrimPrefix(ref[hi+1:], "/"), "/")
+	for i := 0; i < len(segs) && sc != nil; i++ {
+		seg := segs[i]
+		if seg == "" && i == len(segs)-1 {
+			break
+		}
+		next := func() string {
+			if i+1 >= len(segs) {
+				return ""
+			}
+			i++
+			s, err := url.PathUnescape(segs[i])
+			if err != nil {
+				s = segs[i]
+			}
+			return strings.NewReplacer("~1", "/", "~0", "~").Replace(s)
+		}
+		idx := func(scs []*Schema) *Schema {
+			n, err := strconv.Atoi(next())
+			if err != nil || n < 0 || n >= len(scs) {
+				return nil
+			}
+			return scs[n]
+		}
+		switch seg {
+		case "definitions":
+			sc = sc.Defs[next()]
+		case "$defs":
+			sc = sc.Defs2[next()]
+		case "properties":
+			sc = sc.Props[next()]
+		case "patternProperties":
+			sc = sc.PatProps[next()]
+		case "additionalProperties":
+			sc = sc.AddProps
+		case "items":
+			if sc.Items.Tuple {
+				sc = idx(sc.Items.List)
+			} else {
+				sc = sc.Items.Item(0)
+			}
+		case "allOf":
+			sc = idx(sc.AllOf)
+		case "anyOf":
+			sc = idx(sc.AnyOf)
+		case "oneOf":
+			sc = idx(sc.OneOf)
+		default:
+			return nil
+		}
+	}
+	return sc
+}
+
+// Resolve follows the $refs of the schema -- a $ref that cannot be
+// followed allows any value
+func (sd *SchemaDoc) Resolve(sc *Schema) *Schema {
+	for i := 0; sc != nil && sc.Ref != "" && i < SchemaMaxDepth; i++ {
+		if sc = sd.Pointer(sc.Ref); sc == nil {
+			return &Schema{}
+		}
+	}
+	return sc
+}
+
+// Expand returns the schema, with its $refs followed, and those of its
+// allOf, anyOf and oneOf, recursively -- all of the schemas that may apply
+// to a value, for completion and docs -- a schema with a $ref is also
+// included for its docs
+func (sd *SchemaDoc) Expand(sc *Schema) []*Schema {
+	var scs []*Schema
+	var add func(sc *Schema, depth int)
+	add = func(sc *Schema, depth int) {
+		if sc == nil || depth > SchemaMaxDepth {
+			return
+		}
+		if sc.Ref != "" && sc.DocText() != "" {
+			scs = append(scs, sc)
+		}
+		sc = sd.Resolve(sc)
+		for _, s := range scs {
+			if s == sc {
+				return
+			}
+		}
+		scs = append(scs, sc)
+		for _, alts := range [][]*Schema{sc.AllOf, sc.AnyOf, sc.OneOf} {
+			for _, s := range alts {
+				add(s, depth+1)
+			}
+		}
+	}
+	add(sc, 0)
+	return scs
+}
+
+// PatMatch returns true if the key matches the pattern of
+// patternProperties -- patterns that Go cannot compile match any key
+func (sd *SchemaDoc) PatMatch(pat, key string) bool {
+	sd.mu.Lock()
+	if sd.res == nil {
+		sd.res = map[string]*regexp.Regexp{}
+	}
+	re, ok := sd.res[pat]
+	if !ok {
+		re, _ = regexp.Compile(pat)
+		sd.res[pat] = re
+	}
+	sd.mu.Unlock()
+	return re == nil || re.MatchString(key)
+}
+
+// Kid returns the schemas of the value of the key of an object with any of
+// the schemas, or of the elements of an array for schemaItem, expanded
+func (sd *SchemaDoc) Kid(scs []*Schema, key string) []*Schema {
+	var kids []*Schema
+	for _, sc := range scs {
+		if key == schemaItem {
+			for _, s := range sc.Items.List {
+				kids = append(kids, sd.Expand(s)...)
+			}
+			continue
+		}
+		if p, ok := sc.Props[key]; ok {
+			kids = append(kids, sd.Expand(p)...)
+			continue
+		}
+		matched := false
+		for pat, p := range sc.PatProps {
+			if sd.PatMatch(pat, key) {
+				kids = append(kids, sd.Expand(p)...)
+				matched = true
+			}
+		}
+		if !matched && sc.AddProps != nil && !sc.AddProps.False {
+			kids = append(kids, sd.Expand(sc.AddProps)...)
+		}
+	}
+	return kids
+}
+
+// At returns the schemas that may apply to the value at the path of keys,
+// with schemaItem for the elements of lists
+func (sd *SchemaDoc) At(path []string) []*Schema {
+	scs := sd.Expand(&sd.Root)
+	for _, k := range path {
+		if len(scs) == 0 {
+			break
+		}
+		scs = sd.Kid(scs, k)
+	}
+	return scs
+}
+
+// schemaKindTypes are the JSON Schema types of the kinds of data values
+var schemaKindTypes = map[DataKinds]string{DataNull: "null", DataBool: "boolean", DataNum: "number", DataStr: "string", DataTime: "string", DataList: "array", DataMap: "object"}
+
+// schemaTypeOK returns true if the value is of one of the types
+func schemaTypeOK(types []string, nd *DataNode) bool {
+	for _, t := range types {
+		switch {
+		case t == schemaKindTypes[nd.Kind]:
+			return true
+		case t == "integer" && nd.Kind == DataNum:
+			n, spec := dataNumber(nd.Val)
+			if f, err := strconv.ParseFloat(n, 64); err == nil && !spec && f == math.Trunc(f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// schemaValEqual returns true if the value of an enum or const, as read
+// from JSON, is the value of the node
+func schemaValEqual(v interface{}, nd *DataNode) bool {
+	switch v := v.(type) {
+	case nil:
+		return nd.Kind == DataNull
+	case bool:
+		return nd.Kind == DataBool && strings.EqualFold(nd.Val, strconv.FormatBool(v))
+	case float64:
+		if nd.Kind != DataNum {
+			return false
+		}
+		n, _ := dataNumber(nd.Val)
+		f, err := strconv.ParseFloat(n, 64)
+		return err == nil && f == v
+	case string:
+		return (nd.Kind == DataStr || nd.Kind == DataTime) && nd.Val == v
+	}
+	return false
+}
+
+// schemaValText returns the JSON text of the value of an enum or const
+func schemaValText(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return jsonQuote(s)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// schemaPathName returns the path of keys, with [i] for the elements of
+// lists, for messages
+func schemaPathName(path []string) string {
+	if len(path) == 0 {
+		return "top level"
+	}
+	var sb strings.Builder
+	for i, k := range path {
+		if i > 0 && !strings.HasPrefix(k, "[") {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(k)
+	}
+	return sb.String()
+}
+
+// Validate returns the problems of the parsed data against the schema,
+// sorted by line
+func (sd *SchemaDoc) Validate(root *DataNode) []DataProblem {
+	var probs []DataProblem
+	sd.validate(&sd.Root, root, nil, &probs, 0)
+	sort.SliceStable(probs, func(i, j int) bool {
+		return probs[i].Ln < probs[j].Ln
+	})
+	return probs
+}
+
+func (sd *SchemaDoc) validate(sc *Schema, nd *DataNode, path []string, probs *[]DataProblem, depth int) {
+	sc = sd.Resolve(sc)
+	if sc == nil || depth > SchemaMaxDepth {
+		return
+	}
+	name := schemaPathName(path)
+	add := func(ln int, f string, args ...interface{}) {
+		*probs = append(*probs, DataProblem{Ln: ln, Msg: name + ": " + fmt.Sprintf(f, args...)})
+	}
+	if sc.False {
+		add(nd.Ln, "not allowed here")
+		return
+	}
+	if len(sc.Type) > 0 && !schemaTypeOK(sc.Type, nd) {
+		add(nd.Ln, "expected %v, not %v", strings.Join(sc.Type, " or "), schemaKindTypes[nd.Kind])
+		return
+	}
+	if len(sc.Enum) > 0 && nd.IsScalar() {
+		ok := false
+		vals := make([]string, 0, len(sc.Enum))
+		for _, v := range sc.Enum {
+			ok = ok || schemaValEqual(v, nd)
+			vals = append(vals, schemaValText(v))
+		}
+		if !ok {
+			if len(vals) > 10 {
+				vals = append(vals[:10], "...")
+			}
+			add(nd.Ln, "value must be one of: %v", strings.Join(vals, ", "))
+		}
+	}
+	if len(sc.Const) > 0 && nd.IsScalar() {
+		var v interface{}
+		if json.Unmarshal(sc.Const, &v) == nil && !schemaValEqual(v, nd) {
+			add(nd.Ln, "value must be %v", schemaValText(v))
+		}
+	}
+	for _, s := range sc.AllOf {
+		sd.validate(s, nd, path, probs, depth+1)
+	}
+	for _, alts := range [][]*Schema{sc.AnyOf, sc.OneOf} {
+		if len(alts) > 0 {
+			sd.validateAlts(alts, nd, path, probs, depth+1)
+		}
+	}
+	switch nd.Kind {
+	case DataMap:
+		have := make(map[string]bool, len(nd.Kids))
+		for _, k := range nd.Kids {
+			have[k.Key] = true
+		}
+		for _, r := range sc.Required {
+			if !have[r] {
+				add(nd.Ln, "missing required key: %v", r)
+			}
+		}
+		for _, k := range nd.Kids {
+			if len(path) == 0 && k.Key == "$schema" {
+				continue
+			}
+			kp := append(path[:len(path):len(path)], k.Key)
+			if p, ok := sc.Props[k.Key]; ok {
+				sd.validate(p, k, kp, probs, depth+1)
+				continue
+			}
+			matched := false
+			for pat, p := range sc.PatProps {
+				if sd.PatMatch(pat, k.Key) {
+					sd.validate(p, k, kp, probs, depth+1)
+					matched = true
+				}
+			}
+			switch {
+			case matched || sc.AddProps == nil:
+			case sc.AddProps.False:
+				add(k.Ln, "key not allowed: %v", k.Key)
+			default:
+				sd.validate(sc.AddProps, k, kp, probs, depth+1)
+			}
+		}
+	case DataList:
+		for i, k := range nd.Kids {
+			if is := sc.Items.Item(i); is != nil {
+				sd.validate(is, k, append(path[:len(path):len(path)], fmt.Sprintf("[%d]", i)), probs, depth+1)
+			}
+		}
+	}
+}
+
+// validateAlts validates the value against the alternatives of anyOf or
+// oneOf: if it matches none of them, the problems of the one of its type
+// with the fewest problems are reported, or that it is of none of their
+// types
+func (sd *SchemaDoc) validateAlts(alts []*Schema, nd *DataNode, path []string, probs *[]DataProblem, depth int) {
+	var best []DataProblem
+	var types []string
+	found := false
+	for _, a := range alts {
+		var ps []DataProblem
+		sd.validate(a, nd, path, &ps, depth)
+		if len(ps) == 0 {
+			return
+		}
+		ra := sd.Resolve(a)
+		if ra == nil || len(ra.Type) == 0 || schemaTypeOK(ra.Type, nd) {
+			if !found || len(ps) < len(best) {
+				best = ps
+			}
+			found = true
+			continue
+		}
+		for _, t := range ra.Type {
+			if !strSliceHas(types, t) {
+				types = append(types, t)
+			}
+		}
+	}
+	if found {
+		*probs = append(*probs, best...)
+		return
+	}
+	*probs = append(*probs, DataProblem{Ln: nd.Ln, Msg: fmt.Sprintf("%v: expected %v, not %v", schemaPathName(path), strings.Join(types, " or "), schemaKindTypes[nd.Kind])})
+}
+
+// strSliceHas returns true if the list has the string
+func strSliceHas(ss []string, s string) bool {
+	for _, t := range ss {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaTypeText returns the types allowed by the schemas, e.g., string
+// or array, for completions and docs
+func SchemaTypeText(scs []*Schema) string {
+	var types []string
+	for _, sc := range scs {
+		ts := sc.Type
+		if len(ts) == 0 && len(sc.Props) > 0 {
+			ts = SchemaTypes{"object"}
+		}
+		for _, t := range ts {
+			if !strSliceHas(types, t) {
+				types = append(types, t)
+			}
+		}
+	}
+	return strings.Join(types, " or ")
+}
+
+// SchemaDocText returns the docs of the key from the schemas of its value:
+// its types, description, allowed values and default
+func SchemaDocText(key string, scs []*Schema) string {
+	var sb strings.Builder
+	sb.WriteString(key)
+	if ts := SchemaTypeText(scs); ts != "" {
+		sb.WriteString(": " + ts)
+	}
+	var descs, vals []string
+	dflt := ""
+	dep := false
+	for _, sc := range scs {
+		if d := strings.TrimSpace(sc.DocText()); d != "" && !strSliceHas(descs, d) {
+			descs = append(descs, d)
+		}
+		for _, v := range sc.Enum {
+			if vt := schemaValText(v); !strSliceHas(vals, vt) {
+				vals = append(vals, vt)
+			}
+		}
+		if dflt == "" && len(sc.Default) > 0 {
+			dflt = string(sc.Default)
+		}
+		dep = dep || sc.Deprecated
+	}
+	if dep {
+		sb.WriteString(" (deprecated)")
+	}
+	for _, d := range descs {
+		sb.WriteString("\n\n" + d)
+	}
+	if len(vals) > 0 {
+		sb.WriteString("\n\nAllowed values: " + strings.Join(vals, ", "))
+	}
+	if dflt != "" {
+		sb.WriteString("\n\nDefault: " + dflt)
+	}
+	return sb.String()
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Cursor context
+
+// schemaCursor is where the cursor is in the structure of a data file, for
+// completion and docs
+type schemaCursor struct {
+	Format string   `desc:"format of the file: json, yaml or toml"`
+	Path   []string `desc:"path of keys to the object of the key at the cursor, or to the value at the cursor -- schemaItem for the elements of lists"`
+	InKey  bool     `desc:"cursor is in a key, and otherwise in a value"`
+	Item   bool     `desc:"the key is the first of an element of a YAML list, which could also be a plain value"`
+	Quoted bool     `desc:"key or value is in double quotes, in JSON"`
+	Header bool     `desc:"key is in a TOML table header"`
+	Seed   string   `desc:"text of the key or value before the cursor"`
+}
+
+// schemaStops are the chars that end keys and values at the cursor
+var schemaStops = " \t\"',:=[]{}#"
+
+// schemaCursorAt returns where the cursor, at given line and rune in the
+// lines of a file of given format, is in its structure
+func schemaCursorAt(format string, lines [][]rune, ln, ch int) schemaCursor {
+	cx := schemaCursor{Format: format}
+	if ln >= len(lines) {
+		return cx
+	}
+	if ch > len(lines[ln]) {
+		ch = len(lines[ln])
+	}
+	switch format {
+	case "json":
+		cx.jsonAt(lines, ln, ch)
+	case "yaml":
+		cx.yamlAt(lines, ln, ch)
+	case "toml":
+		cx.tomlAt(lines, ln, ch)
+	}
+	return cx
+}
+
+// jsonAt scans the JSON text up to the cursor, keeping track of the
+// objects and lists it is in
+func (cx *schemaCursor) jsonAt(lines [][]rune, ln, ch int) {
+	type frame struct {
+		obj   bool
+		key   string
+		inKey bool
+	}
+	var st []frame
+	word := ""
+	for l := 0; l <= ln; l++ {
+		lt := lines[l]
+		if l == ln {
+			lt = lt[:ch]
+		}
+		word = ""
+		for i := 0; i < len(lt); i++ {
+			c := lt[i]
+			if c != '"' && !strings.ContainsRune(schemaStops, c) {
+				word += string(c)
+				continue
+			}
+			if c == '/' && i+1 < len(lt) && lt[i+1] == '/' {
+				break
+			}
+			word = ""
+			top := len(st) - 1
+			switch c {
+			case '"':
+				j := i + 1
+				for ; j < len(lt) && lt[j] != '"'; j++ {
+					if lt[j] == '\\' {
+						j++
+					}
+				}
+				if j >= len(lt) {
+					if l == ln {
+						cx.Quoted = true
+						word = string(lt[i+1:])
+						i = len(lt)
+					}
+					continue
+				}
+				if top >= 0 && st[top].obj && st[top].inKey {
+					s, err := strconv.Unquote(string(lt[i : j+1]))
+					if err != nil {
+						s = string(lt[i+1 : j])
+					}
+					st[top].key = s
+				}
+				i = j
+			case '{':
+				st = append(st, frame{obj: true, inKey: true})
+			case '[':
+				st = append(st, frame{})
+			case '}', ']':
+				if top >= 0 {
+					st = st[:top]
+				}
+			case ':':
+				if top >= 0 {
+					st[top].inKey = false
+				}
+			case ',':
+				if top >= 0 && st[top].obj {
+					st[top].inKey = true
+					st[top].key = ""
+				}
+			}
+		}
+	}
+	cx.Seed = word
+	if len(st) == 0 {
+		return
+	}
+	for _, f := range st[:len(st)-1] {
+		if f.obj {
+			cx.Path = append(cx.Path, f.key)
+		} else {
+			cx.Path = append(cx.Path, schemaItem)
+		}
+	}
+	top := st[len(st)-1]
+	switch {
+	case top.obj && top.inKey:
+		cx.InKey = true
+	case top.obj:
+		cx.Path = append(cx.Path, top.key)
+	default:
+		cx.Path = append(cx.Path, schemaItem)
+	}
+}
+
+// yamlElem is a key, or the - of a list element, at the start of a YAML
+// line
+type yamlElem struct {
+	col int
+	key string
+}
+
+// yamlLineElems returns the - of list elements and the key at the start
+// of the YAML line, and the column and text after them
+func yamlLineElems(lt string) ([]yamlElem, int, string) {
+	txt := strings.TrimLeft(lt, " ")
+	col := len(lt) - len(txt)
+	var els []yamlElem
+	for yamlIsSeqItem(txt) {
+		els = append(els, yamlElem{col, schemaItem})
+		rest := strings.TrimLeft(txt[1:], " \t")
+		col += len(txt) - len(rest)
+		txt = rest
+	}
+	return els, col, txt
+}
+
+// yamlAt finds the path to the cursor from the keys and list elements of
+// the lines above it that are less indented
+func (cx *schemaCursor) yamlAt(lines [][]rune, ln, ch int) {
+	els, col, txt := yamlLineElems(string(lines[ln][:ch]))
+	if key, rest, ok, _ := yamlKey(txt); ok {
+		els = append(els, yamlElem{col, key})
+		cx.Seed = rest
+	} else {
+		cx.InKey = true
+		cx.Item = len(els) > 0
+		cx.Seed = txt
+		els = append(els, yamlElem{col, ""})
+	}
+	want := els[0].col
+	var pre []string
+	for l := ln - 1; l >= 0 && want > 0; l-- {
+		lt := yamlStrip(string(lines[l]))
+		if strings.TrimSpace(lt) == "" {
+			continue
+		}
+		if lt == "---" || strings.HasPrefix(lt, "--- ") || lt == "..." {
+			break
+		}
+		lels, lcol, ltxt := yamlLineElems(lt)
+		if key, _, ok, _ := yamlKey(ltxt); ok {
+			lels = append(lels, yamlElem{lcol, key})
+		}
+		for e := len(lels) - 1; e >= 0; e-- {
+			if lels[e].col < want {
+				pre = append(pre, lels[e].key)
+				want = lels[e].col
+			}
+		}
+	}
+	for i := len(pre) - 1; i >= 0; i-- {
+		cx.Path = append(cx.Path, pre[i])
+	}
+	for _, e := range els[:len(els)-1] {
+		cx.Path = append(cx.Path, e.key)
+	}
+	if !cx.InKey {
+		cx.Path = append(cx.Path, els[len(els)-1].key)
+		cx.flowValue()
+	}
+}
+
+// flowValue moves into the flow list or object of a value, e.g., [a, b
+func (cx *schemaCursor) flowValue() {
+	if !strings.HasPrefix(cx.Seed, "[") {
+		return
+	}
+	cx.Path = append(cx.Path, schemaItem)
+	cx.Seed = strings.TrimLeft(cx.Seed[strings.LastIndexAny(cx.Seed, "[,")+1:], " ")
+}
+
+// tomlSplitKey splits the dotted TOML key into its parts, unquoted
+func tomlSplitKey(k string) []string {
+	var parts []string
+	var sb strings.Builder
+	var q rune
+	for _, c := range k {
+		switch {
+		case q != 0:
+			if c == q {
+				q = 0
+			} else {
+				sb.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			q = c
+		case c == '.':
+			parts = append(parts, strings.TrimSpace(sb.String()))
+			sb.Reset()
+		case c != ' ' && c != '\t':
+			sb.WriteRune(c)
+		}
+	}
+	return append(parts, sb.String())
+}
+
+// tomlAt finds the path to the cursor from the table header above it, and
+// the dotted key of its line
+func (cx *schemaCursor) tomlAt(lines [][]rune, ln, ch int) {
+	txt := strings.TrimLeft(string(lines[ln][:ch]), " \t")
+	if strings.HasPrefix(txt, "[") {
+		cx.InKey = true
+		cx.Header = true
+		ks := tomlSplitKey(strings.TrimLeft(txt, "["))
+		cx.Path = ks[:len(ks)-1]
+		cx.Seed = ks[len(ks)-1]
+		return
+	}
+	for l := ln - 1; l >= 0; l-- {
+		lt := strings.TrimSpace(string(lines[l]))
+		if !strings.HasPrefix(lt, "[") {
+			continue
+		}
+		aot := strings.HasPrefix(lt, "[[")
+		if ei := strings.Index(lt, "]"); ei > 0 {
+			cx.Path = tomlSplitKey(strings.TrimLeft(lt[:ei], "["))
+		}
+		if aot {
+			cx.Path = append(cx.Path, schemaItem)
+		}
+		break
+	}
+	if ei := strings.Index(txt, "="); ei >= 0 {
+		cx.Path = append(cx.Path, tomlSplitKey(txt[:ei])...)
+		cx.Seed = strings.TrimLeft(txt[ei+1:], " \t")
+		cx.flowValue()
+		return
+	}
+	cx.InKey = true
+	ks := tomlSplitKey(txt)
+	cx.Path = append(cx.Path, ks[:len(ks)-1]...)
+	cx.Seed = ks[len(ks)-1]
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Completion
+
+// SchemaCompleter is the completion context of a data file buffer: the
+// keys and values of its JSON Schema, if it has one, and otherwise those of
+// its current completer -- set with SetSchemaCompleter
+type SchemaCompleter struct {
+	Buf   *giv.TextBuf       `desc:"the buffer completed"`
+	Data  interface{}        `desc:"context of the previous completer of the buffer"`
+	Match complete.MatchFunc `desc:"previous completer of the buffer -- nil if none"`
+	Edit  complete.EditFunc  `desc:"edit function of the previous completer"`
+	Last  schemaCursor       `desc:"where the cursor was for the last match"`
+	Ours  map[string]bool    `desc:"completions offered by the last match from the schema"`
+	Mu    sync.Mutex         `view:"-" json:"-" xml:"-" desc:"mutex protecting Last and Ours"`
+}
+
+// SetSchemaCompleter sets the completer of the data file buffer to a
+// SchemaCompleter, keeping its current completer -- also within a
+// WordCompleter
+func SetSchemaCompleter(tb *giv.TextBuf) {
+	if DataFormat(string(tb.Filename)) == "" {
+		return
+	}
+	sc := &SchemaCompleter{Buf: tb}
+	if tb.Complete != nil {
+		switch ctx := tb.Complete.Context.(type) {
+		case *SchemaCompleter:
+			return
+		case *WordCompleter:
+			if _, ok := ctx.Data.(*SchemaCompleter); !ok {
+				sc.Data, sc.Match, sc.Edit = ctx.Data, ctx.Match, ctx.Edit
+				ctx.Data, ctx.Match, ctx.Edit = sc, CompleteSchema, CompleteSchemaEdit
+			}
+			return
+		}
+		sc.Data = tb.Complete.Context
+		sc.Match = tb.Complete.MatchFunc
+		sc.Edit = tb.Complete.EditFunc
+	}
+	tb.SetCompleter(sc, CompleteSchema, CompleteSchemaEdit)
+}
+
+// schemaLines returns a copy of the lines of the buffer up to given line
+func schemaLines(tb *giv.TextBuf, ln int) [][]rune {
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	if ln >= len(tb.Lines) {
+		ln = len(tb.Lines) - 1
+	}
+	lines := make([][]rune, ln+1)
+	for l := range lines {
+		lines[l] = append([]rune(nil), tb.Lines[l]...)
+	}
+	return lines
+}
+
+// schemaKeyText returns the text of the key for the format and cursor
+func schemaKeyText(cx *schemaCursor, key string) string {
+	switch {
+	case cx.Format == "json" && cx.Quoted:
+		q := jsonQuote(key)
+		return q[1 : len(q)-1]
+	case cx.Format == "json":
+		return jsonQuote(key)
+	case cx.Format == "yaml":
+		return yamlQuote(key)
+	}
+	return tomlKey(key)
+}
+
+// schemaValueText returns the text of the value of an enum or const for
+// the format and cursor
+func schemaValueText(cx *schemaCursor, v interface{}) string {
+	s, ok := v.(string)
+	switch {
+	case !ok:
+		if v == nil && cx.Format == "toml" {
+			return ""
+		}
+		return schemaValText(v)
+	case cx.Quoted:
+		q := jsonQuote(s)
+		return q[1 : len(q)-1]
+	case cx.Format == "json" || strings.HasPrefix(cx.Seed, `"`):
+		return jsonQuote(s)
+	case strings.HasPrefix(cx.Seed, "'") && !strings.Contains(s, "'"):
+		return "'" + s + "'"
+	case cx.Format == "yaml":
+		return yamlQuote(s)
+	}
+	return tomlQuote(s)
+}
+
+// schemaPrefix returns true if the completion starts with the seed,
+// ignoring case and quotes
+func schemaPrefix(text, seed string) bool {
+	lt, ls := strings.ToLower(text), strings.ToLower(seed)
+	return strings.HasPrefix(lt, ls) || strings.HasPrefix(strings.Trim(lt, `"'`), strings.Trim(ls, `"'`))
+}
+
+// completions returns the keys or values of the schema at the cursor that
+// start with its seed -- a YAML list element with no keys at the cursor is
+// completed as a value, unsetting its InKey
+func (sd *SchemaDoc) completions(cx *schemaCursor) []complete.Completion {
+	var cs []complete.Completion
+	ours := map[string]bool{}
+	add := func(text, label string) {
+		if text == "" || ours[text] || (cx.Seed != "" && !schemaPrefix(text, cx.Seed)) {
+			return
+		}
+		ours[text] = true
+		cs = append(cs, complete.Completion{Text: text, Label: label})
+	}
+	scs := sd.At(cx.Path)
+	if cx.InKey {
+		var keys []string
+		props := map[string][]*Schema{}
+		for _, s := range scs {
+			for k, p := range s.Props {
+				if _, has := props[k]; !has {
+					keys = append(keys, k)
+				}
+				props[k] = append(props[k], sd.Expand(p)...)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			lbl := k
+			if ts := SchemaTypeText(props[k]); ts != "" {
+				lbl += "  " + ts
+			}
+			add(schemaKeyText(cx, k), lbl)
+		}
+		if len(keys) == 0 && cx.Item {
+			cx.InKey = false
+		}
+	}
+	if !cx.InKey {
+		for _, s := range scs {
+			for _, v := range s.Enum {
+				vt := schemaValueText(cx, v)
+				add(vt, vt)
+			}
+			if len(s.Const) > 0 {
+				var v interface{}
+				if json.Unmarshal(s.Const, &v) == nil {
+					vt := schemaValueText(cx, v)
+					add(vt, vt)
+				}
+			}
+			if !cx.Quoted && strSliceHas(s.Type, "boolean") {
+				add("true", "true")
+				add("false", "false")
+			}
+		}
+	}
+	return cs
+}
+
+// complete returns the keys or values of the schema at the cursor, or
+// false if the buffer has no schema (yet)
+func (sc *SchemaCompleter) complete(posLn, posCh int) (md complete.MatchData, ok bool) {
+	sd, _ := SchemaForBuf(sc.Buf, false)
+	if sd == nil {
+		return md, false
+	}
+	cx := schemaCursorAt(DataFormat(string(sc.Buf.Filename)), schemaLines(sc.Buf, posLn), posLn, posCh)
+	md.Matches = sd.completions(&cx)
+	ours := make(map[string]bool, len(md.Matches))
+	for _, c := range md.Matches {
+		ours[c.Text] = true
+	}
+	md.Seed = cx.Seed
+	sc.Mu.Lock()
+	sc.Last = cx
+	sc.Ours = ours
+	sc.Mu.Unlock()
+	return md, len(md.Matches) > 0
+}
+
+// CompleteSchema does completion of the keys and values of a data file
+// from its JSON Schema, given the *SchemaCompleter as data, falling back
+// on the previous completer of the buffer if it has no schema, or there
+// are none at the cursor
+func CompleteSchema(data interface{}, text string, posLn, posCh int) (md complete.MatchData) {
+	sc, ok := data.(*SchemaCompleter)
+	if !ok {
+		return md
+	}
+	if Prefs.Schemas.On {
+		if md, ok := sc.complete(posLn, posCh); ok {
+			return md
+		}
+	}
+	sc.Mu.Lock()
+	sc.Ours = nil
+	sc.Mu.Unlock()
+	if sc.Match != nil {
+		md = sc.Match(sc.Data, text, posLn, posCh)
+	}
+	return md
+}
+
+// CompleteSchemaEdit edits the text after the user chooses from the
+// candidate completions: replacing the key or value at the cursor, closing
+// its quote, and adding the : or = after a key if not there -- with the
+// edit function of the previous completer for its completions
+func CompleteSchemaEdit(data interface{}, text string, cursorPos int, c complete.Completion, seed string) (ed complete.EditData) {
+	sc, ok := data.(*SchemaCompleter)
+	if !ok {
+		return complete.EditWord(text, cursorPos, c.Text, seed)
+	}
+	sc.Mu.Lock()
+	ours := sc.Ours[c.Text]
+	cx := sc.Last
+	sc.Mu.Unlock()
+	if !ours {
+		if sc.Edit != nil {
+			return sc.Edit(sc.Data, text, cursorPos, c, seed)
+		}
+		return complete.EditWord(text, cursorPos, c.Text, seed)
+	}
+	rs := []rune(text)
+	if cursorPos > len(rs) {
+		cursorPos = len(rs)
+	}
+	after := rs[cursorPos:]
+	n := 0
+	for n < len(after) && !strings.ContainsRune(schemaStops, after[n]) && (cx.Format != "toml" || !cx.InKey || after[n] != '.') {
+		n++
+	}
+	ed.NewText = c.Text
+	switch {
+	case cx.Quoted:
+		ed.NewText += `"`
+		if n < len(after) && after[n] == '"' {
+			n++
+		}
+	case seed != "" && (seed[0] == '"' || seed[0] == '\'') && n < len(after) && after[n] == rune(seed[0]) && strings.HasSuffix(c.Text, seed[:1]):
+		n++ // closing quote of a quoted value
+	}
+	if cx.InKey && !cx.Header {
+		sep := ": "
+		if cx.Format == "toml" {
+			sep = " = "
+		}
+		if rest := strings.TrimLeft(string(after[n:]), " \t"); !strings.HasPrefix(rest, strings.TrimSpace(sep)) {
+			ed.NewText += sep
+		}
+	}
+	ed.ForwardDelete = n
+	return ed
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Docs
+
+// SchemaDocSupported returns true if the docs of the keys of the file are
+// looked up in its JSON Schema
+func SchemaDocSupported(fpath string) bool {
+	return Prefs.Schemas.On && DataFormat(fpath) != ""
+}
+
+// SchemaDocAt returns the docs from the JSON Schema of the data file of
+// the buffer for the key at given position
+func SchemaDocAt(tb *giv.TextBuf, pos giv.TextPos) (string, error) {
+	sd, err := SchemaForBuf(tb, false)
+	switch {
+	case err != nil:
+		return "", err
+	case sd == nil:
+		return "", fmt.Errorf("gide.SchemaDocAt: no schema loaded for: %v", tb.Filename)
+	}
+	lines := schemaLines(tb, pos.Ln)
+	if pos.Ln >= len(lines) {
+		return "", fmt.Errorf("gide.SchemaDocAt: position out of range")
+	}
+	format := DataFormat(string(tb.Filename))
+	lt := lines[pos.Ln]
+	ed := pos.Ch
+	if ed >= len(lt) || strings.ContainsRune(schemaStops, lt[ed]) {
+		return "", fmt.Errorf("gide.SchemaDocAt: no key at position")
+	}
+	for ed < len(lt) && !strings.ContainsRune(schemaStops, lt[ed]) && (format != "toml" || lt[ed] != '.') {
+		ed++
+	}
+	cx := schemaCursorAt(format, lines, pos.Ln, ed)
+	key := strings.Trim(cx.Seed, `"'`)
+	if !cx.InKey || key == "" {
+		return "", fmt.Errorf("gide.SchemaDocAt: no key at position")
+	}
+	scs := sd.At(append(cx.Path, key))
+	if len(scs) == 0 {
+		return "", fmt.Errorf("gide.SchemaDocAt: key not in schema: %v", key)
+	}
+	return SchemaDocText(key, scs), nil
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var testSchema = `{
+	"definitions": {
+		"port": {"type": "integer", "description": "a port"},
+		"service": {
+			"type": "object",
+			"properties": {
+				"image": {"type": "string"},
+				"ports": {"type": "array", "items": {"$ref": "#/definitions/port"}},
+				"restart": {"enum": ["no", "always", "on-failure"]}
+			},
+			"required": ["image"],
+			"additionalProperties": false
+		}
+	},
+	"$defs": {"name": {"type": "string"}},
+	"type": "object",
+	"properties": {
+		"version": {"type": "string"},
+		"name": {"$ref": "#/$defs/name"},
+		"debug": {"type": "boolean"},
+		"services": {"type": "object", "additionalProperties": {"$ref": "#/definitions/service"}},
+		"a~b/c": {"type": "number"},
+		"port": {"$ref": "#/definitions/service/properties/ports/items"}
+	}
+}`
+
+func loadTestSchema(t *testing.T) *SchemaDoc {
+	sd := &SchemaDoc{URL: "test.json"}
+	if err := json.Unmarshal([]byte(testSchema), &sd.Root); err != nil {
+		t.Fatal(err)
+	}
+	return sd
+}
+
+func TestSchemaRef(t *testing.T) {
+	sd := loadTestSchema(t)
+	tests := []struct {
+		ref  string
+		want *Schema
+	}{
+		{"#", &sd.Root},
+		{"#/definitions/port", sd.Root.Defs["port"]},
+		{"test.json#/definitions/port", sd.Root.Defs["port"]},
+		{"#/$defs/name", sd.Root.Defs2["name"]},
+		{"#/properties/a~0b~1c", sd.Root.Props["a~b/c"]},
+		{"#/properties/services/additionalProperties", sd.Root.Props["services"].AddProps},
+		{"#/definitions/service/properties/ports/items", sd.Root.Defs["service"].Props["ports"].Items.Item(0)},
+		{"#/definitions/missing", nil},
+		{"#/bogus/port", nil},
+		{"other.json#/definitions/port", nil},
+	}
+	for _, ts := range tests {
+		if got := sd.Pointer(ts.ref); got != ts.want {
+			t.Errorf("Pointer %v error: should be %p, got: %p\n", ts.ref, ts.want, got)
+		}
+	}
+
+	// port refers to the items of ports, which refer to the port definition
+	if got := sd.Resolve(sd.Root.Props["port"]); got != sd.Root.Defs["port"] {
+		t.Errorf("Resolve error: should follow the chain of refs to the port definition, got: %+v\n", got)
+	}
+	if got := sd.Resolve(&Schema{Ref: "#/definitions/missing"}); got == nil || len(got.Type) > 0 || got.Ref != "" {
+		t.Errorf("Resolve error: should allow any value for a missing ref, got: %+v\n", got)
+	}
+	if got := SchemaTypeText(sd.At([]string{"services", "web", "ports", schemaItem})); got != "integer" {
+		t.Errorf("At error: should find the integer items of ports through refs, got: %v\n", got)
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	sd := loadTestSchema(t)
+	tests := []struct {
+		src   string
+		probs []string
+	}{
+		{"version: \"1\"\nname: app\ndebug: true\nservices:\n  web:\n    image: x\n    ports: [80, 443]\n    restart: always\n", nil},
+		{"version: 1\n", []string{"line 1: version: expected string, not number"}},
+		{"name: [a]\n", []string{"line 1: name: expected string, not array"}},
+		{"debug: \"x\"\n", []string{"line 1: debug: expected boolean, not string"}},
+		{"a~b/c: x\n", []string{"line 1: a~b/c: expected number, not string"}},
+		{"services:\n  web:\n    image: x\n    ports: [80, 8.5]\n", []string{"line 4: services.web.ports[1]: expected integer, not number"}},
+		{"services:\n  web:\n    image: x\n    restart: never\n", []string{`line 4: services.web.restart: value must be one of: "no", "always", "on-failure"`}},
+		{"services:\n  web:\n    restart: always\n", []string{"line 2: services.web: missing required key: image"}},
+		{"services:\n  web:\n    image: x\n    build: .\n", []string{"line 4: services.web: key not allowed: build"}},
+		{"services:\n  web: 1\n", []string{"line 2: services.web: expected object, not number"}},
+		{"- a\n", []string{"line 1: top level: expected object, not array"}},
+		{"other: 1\n", nil},
+	}
+	for _, ts := range tests {
+		root, probs := ParseData("yaml", []byte(ts.src))
+		if root == nil {
+			t.Errorf("ParseData %q error: should parse, got: %v\n", ts.src, probs)
+			continue
+		}
+		var got []string
+		for _, pb := range sd.Validate(root) {
+			got = append(got, fmt.Sprintf("line %d: %v", pb.Ln+1, pb.Msg))
+		}
+		if strings.Join(got, "\n") != strings.Join(ts.probs, "\n") {
+			t.Errorf("Validate %q error: should be %q, got: %q\n", ts.src, ts.probs, got)
+		}
+	}
+}
+
+func TestSchemaCompletions(t *testing.T) {
+	sd := loadTestSchema(t)
+	tests := []struct {
+		format string
+		src    string
+		want   []string
+	}{
+		{"yaml", "", []string{"a~b/c", "debug", "name", "port", "services", "version"}},
+		{"yaml", "services:\n  web:\n    re", []string{"restart"}},
+		{"yaml", "services:\n  web:\n    restart: ", []string{`"no"`, "always", "on-failure"}},
+		{"yaml", "services:\n  web:\n    restart: a", []string{"always"}},
+		{"yaml", "debug: ", []string{"true", "false"}},
+		{"json", `{"services": {"web": {"`, []string{"image", "ports", "restart"}},
+		{"json", `{"services": {"web": {"restart": `, []string{`"no"`, `"always"`, `"on-failure"`}},
+		{"json", `{"debug": f`, []string{"false"}},
+		{"toml", "[services.web]\nim", []string{"image"}},
+		{"toml", "[services.web]\nrestart = ", []string{`"no"`, `"always"`, `"on-failure"`}},
+		{"toml", "[services.", []string{}},
+		{"toml", "[services.web]\nbuild = ", []string{}},
+	}
+	for _, ts := range tests {
+		var lines [][]rune
+		for _, l := range strings.Split(ts.src, "\n") {
+			lines = append(lines, []rune(l))
+		}
+		ln := len(lines) - 1
+		cx := schemaCursorAt(ts.format, lines, ln, len(lines[ln]))
+		got := []string{}
+		for _, c := range sd.completions(&cx) {
+			got = append(got, c.Text)
+		}
+		if strings.Join(got, " ") != strings.Join(ts.want, " ") {
+			t.Errorf("completions %v %q error: should be %q, got: %q\n", ts.format, ts.src, ts.want, got)
+		}
+	}
+}
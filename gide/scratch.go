@@ -0,0 +1,166 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/filecat"
+)
+
+// ScratchPrefix is the prefix for the paths of scratch buffers, which are
+// not associated with any file -- they are never saved, and are discarded
+// when closed
+var ScratchPrefix = "scratch:///"
+
+// PlaygroundURL is the base url of the Go Playground used for sharing
+var PlaygroundURL = "https://play.golang.org"
+
+// ScratchPath returns the path for a scratch buffer of given name
+func ScratchPath(name string) gi.FileName {
+	return gi.FileName(ScratchPrefix + name)
+}
+
+// IsScratch returns true if the file node is a scratch buffer
+func IsScratch(fn *giv.FileNode) bool {
+	return strings.HasPrefix(string(fn.FPath), ScratchPrefix)
+}
+
+// NewScratchNode returns a new file node for a scratch buffer of given name
+// and language, with an empty buffer already open -- the node is not added to
+// the file tree, but has the given root so it can be listed in OpenNodes
+func NewScratchNode(root *giv.FileTree, name string, sup filecat.Supported) *giv.FileNode {
+	fn := &giv.FileNode{}
+	fn.InitName(fn, name)
+	fn.FRoot = root
+	fn.FPath = ScratchPath(name)
+	fn.Info.Name = name
+	fn.Info.Path = string(fn.FPath)
+	fn.Info.Sup = sup
+	fn.Info.Kind = "Scratch"
+	fn.Buf = &giv.TextBuf{}
+	fn.Buf.InitName(fn.Buf, name)
+	fn.Buf.Filename = fn.FPath // marks as open -- see OpenBuf
+	fn.Buf.Info = fn.Info
+	fn.Buf.Autosave = false
+	fn.Buf.New(1)
+	return fn
+}
+
+// ShebangExts are the file extensions for the interpreters of #! lines, for
+// GuessContentLang
+var ShebangExts = map[string]string{
+	"sh": ".sh", "bash": ".sh", "zsh": ".sh", "ksh": ".sh",
+	"python": ".py", "perl": ".pl", "ruby": ".rb", "node": ".js",
+}
+
+// GuessContentExt guesses the file extension of text that has no file name,
+// e.g., piped to gide -, from its #! line or the way it starts -- returns ""
+// if unknown
+func GuessContentExt(b []byte) string {
+	if len(b) > 4096 {
+		b = b[:4096]
+	}
+	s := strings.TrimSpace(string(b))
+	fl := s
+	if li := strings.IndexByte(fl, '\n'); li >= 0 {
+		fl = fl[:li]
+	}
+	lfl := strings.ToLower(fl)
+	switch {
+	case strings.HasPrefix(fl, "#!"):
+		f := strings.Fields(fl[2:])
+		if len(f) == 0 {
+			return ""
+		}
+		in := filepath.Base(f[0])
+		if in == "env" && len(f) > 1 {
+			in = f[1]
+		}
+		return ShebangExts[strings.TrimRight(in, "0123456789.")]
+	case snippetPkgRe.MatchString(s):
+		return ".go"
+	case strings.HasPrefix(fl, "diff ") || (strings.HasPrefix(fl, "--- ") && strings.Contains(s, "\n+++ ")):
+		return ".diff"
+	case strings.HasPrefix(lfl, "<?xml"):
+		return ".xml"
+	case strings.HasPrefix(lfl, "<!doctype html") || strings.HasPrefix(lfl, "<html"):
+		return ".html"
+	case strings.HasPrefix(fl, "{") || strings.HasPrefix(fl, "["):
+		return ".json"
+	case strings.HasPrefix(fl, "# "):
+		return ".md"
+	}
+	return ""
+}
+
+// GuessContentLang guesses the language of text that has no file name, from
+// its start (see GuessContentExt) -- NoSupport if unknown
+func GuessContentLang(b []byte) filecat.Supported {
+	ext := GuessContentExt(b)
+	if ext == "" {
+		return filecat.NoSupport
+	}
+	return filecat.ExtSupported(ext)
+}
+
+// ShareToPlayground posts the given Go source to the Go Playground, returning
+// the url of the shared snippet
+func ShareToPlayground(src []byte) (string, error) {
+	resp, err := http.Post(PlaygroundURL+"/share", "text/plain; charset=utf-8", bytes.NewReader(src))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	id, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gide.ShareToPlayground: share failed: %v: %v", resp.Status, strings.TrimSpace(string(id)))
+	}
+	return PlaygroundURL + "/p/" + strings.TrimSpace(string(id)), nil
+}
+
+// snippetPkgRe matches a package clause at the start of a line
+var snippetPkgRe = regexp.MustCompile(`(?m)^package\s+\w+`)
+
+// SnippetMain returns the snippet as a complete main package: if it does not
+// have a package clause, it is wrapped in a main function -- imports are
+// filled in by running goimports on the result
+func SnippetMain(snip string) string {
+	if snippetPkgRe.MatchString(snip) {
+		return snip
+	}
+	return "package main\n\nfunc main() {\n" + snip + "\n}\n"
+}
+
+// RunSnippet runs the given Go snippet, wrapped in a main harness, in a
+// temporary directory, returning its combined output
+func RunSnippet(snip string) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "gide-snippet")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	fnm := filepath.Join(dir, "main.go")
+	if err := ioutil.WriteFile(fnm, []byte(SnippetMain(snip)), 0644); err != nil {
+		return nil, err
+	}
+	exec.Command("goimports", "-w", fnm).Run() // best effort -- go run reports any missing imports
+	cmd := exec.Command("go", "run", fnm)
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"github.com/goki/gi/giv"
+)
+
+// ScrollSync is the synchronized scrolling of a view with another one: when
+// either is scrolled, the other is scrolled by the same number of lines, so
+// the offset between their first visible lines stays the same -- e.g., for
+// comparing a file with its translation, or two parts of a long file
+type ScrollSync struct {
+	View *TextView `desc:"the other view, scrolled along with this one -- nil if not synchronized"`
+	Off  int       `desc:"number of lines the first visible line of the other view is below that of this one"`
+	Top  int       `desc:"first visible line of this view when last synchronized"`
+	Busy bool      `desc:"this view is scrolling the other one, which must not scroll it back"`
+}
+
+// SetSyncScroll synchronizes the scrolling of this view and the other one
+// (see ScrollSync), with the offset between their current first visible
+// lines, or stops it if the other one is nil
+func (tv *TextView) SetSyncScroll(ov *TextView) {
+	if ov == nil || ov == tv {
+		if pv := tv.Sync.View; pv != nil && pv.Sync.View == tv {
+			pv.Sync = ScrollSync{}
+		}
+		tv.Sync = ScrollSync{}
+		return
+	}
+	top, otop := tv.TopLine(), ov.TopLine()
+	tv.Sync = ScrollSync{View: ov, Off: otop - top, Top: top}
+	ov.Sync = ScrollSync{View: tv, Off: top - otop, Top: otop}
+}
+
+// TopLine returns the first visible line of the view
+func (tv *TextView) TopLine() int {
+	if tv.NLines == 0 {
+		return 0
+	}
+	return tv.FirstVisibleLine(0)
+}
+
+// ScrollToLineTop scrolls the view to put given line at its top, to the
+// extent possible, with the line clamped to those of the view
+func (tv *TextView) ScrollToLineTop(ln int) bool {
+	if tv.NLines == 0 {
+		return false
+	}
+	if ln >= tv.NLines {
+		ln = tv.NLines - 1
+	}
+	if ln < 0 {
+		ln = 0
+	}
+	return tv.ScrollToTop(int(tv.CharStartPos(giv.TextPos{Ln: ln}).Y))
+}
+
+// SyncScroll is called after the view is rendered, to scroll the other view
+// it is synchronized with if this one has been scrolled since the last time
+func (tv *TextView) SyncScroll() {
+	ss := &tv.Sync
+	ov := ss.View
+	if ov == nil || ss.Busy || ov.Sync.Busy || tv.NLines == 0 {
+		return
+	}
+	if ov.Sync.View != tv || ov.This() == nil {
+		tv.Sync = ScrollSync{}
+		return
+	}
+	top := tv.TopLine()
+	if top == ss.Top {
+		return
+	}
+	ss.Top = top
+	ss.Busy = true
+	ov.ScrollToLineTop(top + ss.Off)
+	ss.Busy = false
+	ov.Sync.Top = ov.TopLine()
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultServePort is the default port for serving a project directory
+var DefaultServePort = 8080
+
+// LiveReloadPath is the url path of the live reload websocket
+var LiveReloadPath = "/__gide_livereload"
+
+// LiveReloadExts are the extensions of files that trigger a live reload of
+// the browser when saved
+var LiveReloadExts = []string{".html", ".htm", ".css", ".js"}
+
+// LiveReloadScript is injected into served html pages when live reload is on
+// -- it reloads the page when told to by the server
+var LiveReloadScript = `<script>(function() {
+	var ws = new WebSocket("ws://" + location.host + "` + "%s" + `");
+	ws.onmessage = function(ev) { if (ev.data == "reload") { location.reload(); } };
+})();</script>
+`
+
+// FileServer is a local static http file server for previewing a directory,
+// e.g., a static site built from the project -- it only listens on the
+// loopback interface.  With LiveReload on, a script is injected into html
+// pages so that they are reloaded whenever Reload is called.
+type FileServer struct {
+	Dir        string            `desc:"directory being served"`
+	Port       int               `desc:"port the server is listening on"`
+	LiveReload bool              `desc:"inject live reload script into html pages"`
+	Server     *http.Server      `desc:"the http server"`
+	Files      http.Handler      `desc:"the static file handler"`
+	Mu         sync.Mutex        `desc:"mutex protecting Conns"`
+	Conns      map[net.Conn]bool `desc:"open live reload websocket connections"`
+}
+
+// StartFileServer starts serving the given directory on given port on
+// localhost -- if the port is 0, or it is in use, a free port is used
+// instead
+func StartFileServer(dir string, port int, liveReload bool) (*FileServer, error) {
+	ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil && port != 0 {
+		ln, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if adir, err := filepath.Abs(dir); err == nil {
+		dir = adir
+	}
+	fs := &FileServer{Dir: dir, Port: ln.Addr().(*net.TCPAddr).Port, LiveReload: liveReload}
+	fs.Files = http.FileServer(http.Dir(dir))
+	fs.Conns = make(map[net.Conn]bool)
+	fs.Server = &http.Server{Handler: fs}
+	go fs.Server.Serve(ln)
+	return fs, nil
+}
+
+// URL returns the url for the root of the served directory
+func (fs *FileServer) URL() string {
+	return fmt.Sprintf("http://localhost:%d/", fs.Port)
+}
+
+// ServeHTTP serves the live reload websocket and html pages with the live
+// reload script injected, and otherwise the static files
+func (fs *FileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !fs.LiveReload {
+		fs.Files.ServeHTTP(w, r)
+		return
+	}
+	if r.URL.Path == LiveReloadPath {
+		fs.ServeLiveReload(w, r)
+		return
+	}
+	upath := path.Clean("/" + r.URL.Path)
+	fpath := filepath.Join(fs.Dir, filepath.FromSlash(upath))
+	if fi, err := os.Stat(fpath); err == nil && fi.IsDir() {
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			fs.Files.ServeHTTP(w, r) // redirects to dir/
+			return
+		}
+		fpath = filepath.Join(fpath, "index.html")
+	}
+	ext := strings.ToLower(filepath.Ext(fpath))
+	if ext != ".html" && ext != ".htm" {
+		fs.Files.ServeHTTP(w, r)
+		return
+	}
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		fs.Files.ServeHTTP(w, r)
+		return
+	}
+	scr := []byte(fmt.Sprintf(LiveReloadScript, LiveReloadPath))
+	if i := bytes.LastIndex(bytes.ToLower(b), []byte("</body>")); i >= 0 {
+		b = append(b[:i], append(scr, b[i:]...)...)
+	} else {
+		b = append(b, scr...)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(b)
+}
+
+// ServeLiveReload accepts a live reload websocket connection -- the server
+// only ever sends "reload" messages, and the connection is dropped when the
+// browser closes it
+func (fs *FileServer) ServeLiveReload(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	hj, ok := w.(http.Hijacker)
+	if key == "" || !ok {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	h := sha1.New()
+	io.WriteString(h, key+"258EAFA5-E914-47DA-95CA-C5AB0DC85B11")
+	acc := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", acc)
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return
+	}
+	fs.Mu.Lock()
+	fs.Conns[conn] = true
+	fs.Mu.Unlock()
+	go func() { // discard anything sent by the browser, until it closes
+		io.Copy(ioutil.Discard, rw)
+		fs.Mu.Lock()
+		delete(fs.Conns, conn)
+		fs.Mu.Unlock()
+		conn.Close()
+	}()
+}
+
+// Reload tells all the connected browsers to reload their page
+func (fs *FileServer) Reload() {
+	msg := []byte("reload")
+	frame := append([]byte{0x81, byte(len(msg))}, msg...) // final text frame
+	fs.Mu.Lock()
+	defer fs.Mu.Unlock()
+	for c := range fs.Conns {
+		c.SetWriteDeadline(time.Now().Add(time.Second))
+		if _, err := c.Write(frame); err != nil {
+			delete(fs.Conns, c)
+			c.Close()
+		}
+	}
+}
+
+// ReloadForFile calls Reload if the given saved file is within the served
+// directory and is one of the LiveReloadExts
+func (fs *FileServer) ReloadForFile(fpath string) {
+	if !fs.LiveReload {
+		return
+	}
+	if rel, err := filepath.Rel(fs.Dir, fpath); err != nil || strings.HasPrefix(rel, "..") {
+		return
+	}
+	ext := strings.ToLower(filepath.Ext(fpath))
+	for _, le := range LiveReloadExts {
+		if ext == le {
+			fs.Reload()
+			return
+		}
+	}
+}
+
+// Stop stops the server, waiting briefly for active requests to finish
+func (fs *FileServer) Stop() error {
+	fs.Mu.Lock()
+	for c := range fs.Conns {
+		c.Close()
+	}
+	fs.Conns = make(map[net.Conn]bool)
+	fs.Mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return fs.Server.Shutdown(ctx)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// ShellCheckDiag is a diagnostic from shellcheck
+type ShellCheckDiag struct {
+	File    string `json:"file" desc:"file with the problem"`
+	Line    int    `json:"line" desc:"line of the problem (1-based)"`
+	Column  int    `json:"column" desc:"column of the problem (1-based)"`
+	Level   string `json:"level" desc:"error, warning, info or style"`
+	Code    int    `json:"code" desc:"the shellcheck code, e.g., 2086 for SC2086"`
+	Message string `json:"message" desc:"description of the problem"`
+}
+
+// ParseShellCheck parses the output of shellcheck -f json
+func ParseShellCheck(out []byte) ([]ShellCheckDiag, error) {
+	var dgs []ShellCheckDiag
+	if err := json.Unmarshal(bytes.TrimSpace(out), &dgs); err != nil {
+		return nil, fmt.Errorf("could not parse shellcheck output: %v", err)
+	}
+	return dgs, nil
+}
+
+// RunShellCheck runs shellcheck on the given file, returning its
+// diagnostics -- shellcheck exits with an error if it finds problems, so
+// that is only returned if the output can't be parsed
+func RunShellCheck(fpath, dir string) ([]ShellCheckDiag, error) {
+	cmd := exec.Command("shellcheck", "-f", "json", fpath)
+	cmd.Dir = dir
+	out, cerr := cmd.Output()
+	dgs, err := ParseShellCheck(out)
+	if err != nil && cerr != nil {
+		return nil, cerr
+	}
+	return dgs, err
+}
+
+// ShellProg returns the shell used to run shell code: $SHELL if set, else
+// sh (cmd on windows)
+func ShellProg() string {
+	if sh := os.Getenv("SHELL"); sh != "" {
+		return sh
+	}
+	if runtime.GOOS == "windows" {
+		return "cmd"
+	}
+	return "sh"
+}
+
+// RunShellSnippet runs the given lines of shell code in the given
+// directory, by passing them to the ShellProg on its standard input,
+// returning the combined output
+func RunShellSnippet(code, dir string) ([]byte, error) {
+	cmd := exec.Command(ShellProg())
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewBufferString(code + "\n")
+	return cmd.CombinedOutput()
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/pi/filecat"
+)
+
+// SigHelpCmd is the language server command used to get signature help --
+// it is run as: SigHelpCmd signature file:line:col
+var SigHelpCmd = "gopls"
+
+// SigHelpWaitMSec is the number of milliseconds to wait after typing before
+// showing the signature help popup
+var SigHelpWaitMSec = 100
+
+// SigHelpMaxLines is the maximum number of lines to search backward from the
+// cursor for the start of the enclosing call
+var SigHelpMaxLines = 20
+
+// SigHelp records the signature help state for a TextView
+type SigHelp struct {
+	On     bool        `desc:"signature help is active -- cursor is within a call"`
+	CallSt giv.TextPos `desc:"position of the open paren of the call"`
+	Sig    string      `desc:"signature of the function being called, as returned by SigHelpCmd"`
+	Timer  *time.Timer `desc:"timer for delayed showing of popup"`
+}
+
+// GoplsOverlay returns the archive passed on stdin to the -modified flag of
+// the language server, giving the unsaved text of given file: its name, its
+// size in bytes and its contents, the first two each followed by a newline
+func GoplsOverlay(fpath string, txt []byte) []byte {
+	if afp, err := filepath.Abs(fpath); err == nil {
+		fpath = afp
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%v\n%v\n", fpath, len(txt))
+	b.Write(txt)
+	return b.Bytes()
+}
+
+// GoplsCmd returns the command running the SigHelpCmd language server with
+// given args on the file of the buffer -- if the buffer has unsaved changes,
+// its text is passed as a -modified overlay on stdin, so the server sees
+// what is being edited and the file is never saved implicitly
+func GoplsCmd(tb *giv.TextBuf, args ...string) *exec.Cmd {
+	if !tb.IsChanged() {
+		return exec.Command(SigHelpCmd, args...)
+	}
+	cmd := exec.Command(SigHelpCmd, append([]string{"-modified"}, args...)...)
+	cmd.Stdin = bytes.NewReader(GoplsOverlay(string(tb.Filename), tb.LinesToBytesCopy()))
+	return cmd
+}
+
+// Signature returns the signature of the function called at given position
+// in the buffer, using the SigHelpCmd language server -- ln and col are
+// 1-based, and col is in bytes, not runes
+func Signature(tb *giv.TextBuf, ln, col int) (string, error) {
+	cmd := GoplsCmd(tb, "signature", fmt.Sprintf("%v:%v:%v", tb.Filename, ln, col))
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	for _, l := range bytes.Split(out, []byte("\n")) {
+		l = bytes.TrimSpace(l)
+		if len(l) > 0 {
+			return string(l), nil
+		}
+	}
+	return "", fmt.Errorf("gide.Signature: no signature found")
+}
+
+// SignatureParams splits the signature into the part before the params, the
+// params themselves, and the part after the params (results)
+func SignatureParams(sig string) (pre string, params []string, post string) {
+	st := strings.Index(sig, "(")
+	if st < 0 {
+		return sig, nil, ""
+	}
+	pre = sig[:st+1]
+	depth := 0
+	cst := st + 1
+	for i := st + 1; i < len(sig); i++ {
+		switch sig[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				if ps := strings.TrimSpace(sig[cst:i]); ps != "" {
+					params = append(params, ps)
+				}
+				post = sig[i:]
+				return
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				params = append(params, strings.TrimSpace(sig[cst:i]))
+				cst = i + 1
+			}
+		}
+	}
+	return sig, nil, ""
+}
+
+// SignatureMarkup returns the signature as markup, with the active param
+// (0-based index) in bold -- variadic params remain active for all
+// subsequent args
+func SignatureMarkup(sig string, active int) string {
+	pre, params, post := SignatureParams(sig)
+	if len(params) > 0 && active >= len(params) && strings.Contains(params[len(params)-1], "...") {
+		active = len(params) - 1
+	}
+	var b strings.Builder
+	b.WriteString(html.EscapeString(pre))
+	for i, p := range params {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if i == active {
+			b.WriteString("<b>" + html.EscapeString(p) + "</b>")
+		} else {
+			b.WriteString(html.EscapeString(p))
+		}
+	}
+	b.WriteString(html.EscapeString(post))
+	return b.String()
+}
+
+// CallStart searches backward from the cursor for the unmatched open paren
+// of the enclosing call, returning its position and the index of the arg
+// that the cursor is within -- false if not within a call
+func (tv *TextView) CallStart() (giv.TextPos, int, bool) {
+	if tv.Buf == nil {
+		return giv.TextPos{}, 0, false
+	}
+	depth := 0
+	arg := 0
+	pos := tv.CursorPos
+	stln := pos.Ln - SigHelpMaxLines
+	if stln < 0 {
+		stln = 0
+	}
+	for ln := pos.Ln; ln >= stln; ln-- {
+		lt := tv.Buf.Line(ln)
+		ch := len(lt)
+		if ln == pos.Ln && pos.Ch < ch {
+			ch = pos.Ch
+		}
+		for ch--; ch >= 0; ch-- {
+			switch lt[ch] {
+			case ')', ']', '}':
+				depth++
+			case '[', '{':
+				if depth == 0 {
+					return giv.TextPos{}, 0, false
+				}
+				depth--
+			case '(':
+				if depth == 0 {
+					return giv.TextPos{Ln: ln, Ch: ch}, arg, true
+				}
+				depth--
+			case ',':
+				if depth == 0 {
+					arg++
+				}
+			}
+		}
+	}
+	return giv.TextPos{}, 0, false
+}
+
+// SignatureHelp shows a popup with the signature of the function being
+// called at the cursor, with the current param highlighted
+func (tv *TextView) SignatureHelp() {
+	if tv.Buf == nil || tv.Buf.Info.Sup != filecat.Go {
+		tv.SigHelpOff()
+		return
+	}
+	cst, arg, ok := tv.CallStart()
+	if !ok {
+		tv.SigHelpOff()
+		return
+	}
+	if !tv.SigHelp.On || tv.SigHelp.CallSt != cst || tv.SigHelp.Sig == "" {
+		bcol := len(string(tv.Buf.Line(cst.Ln)[:cst.Ch+1])) + 1 // just after paren
+		sig, err := Signature(tv.Buf, cst.Ln+1, bcol)
+		if err != nil {
+			tv.SigHelpOff()
+			return
+		}
+		tv.SigHelp.CallSt = cst
+		tv.SigHelp.Sig = sig
+	}
+	tv.SigHelp.On = true
+	tv.ShowSigHelp(SignatureMarkup(tv.SigHelp.Sig, arg))
+}
+
+// ShowSigHelp shows the given signature markup in a popup below the cursor,
+// after a brief delay
+func (tv *TextView) ShowSigHelp(mu string) {
+	if tv.SigHelp.Timer != nil {
+		tv.SigHelp.Timer.Stop()
+	}
+	cpos := tv.CharStartPos(tv.CursorPos).ToPoint()
+	cpos.Y += int(tv.LineHeight) + 2
+	vp := tv.Viewport
+	tv.SigHelp.Timer = time.AfterFunc(time.Duration(SigHelpWaitMSec)*time.Millisecond,
+		func() {
+			if vp == nil || vp.Win == nil || !tv.SigHelp.On {
+				return
+			}
+			gi.PopupTooltip(mu, cpos.X, cpos.Y, vp, "sig-help")
+			vp.Win.OSWin.SendEmptyEvent() // needs an extra event to show popup
+		})
+}
+
+// SigHelpOff turns off signature help
+func (tv *TextView) SigHelpOff() {
+	if tv.SigHelp.Timer != nil {
+		tv.SigHelp.Timer.Stop()
+		tv.SigHelp.Timer = nil
+	}
+	tv.SigHelp.On = false
+	tv.SigHelp.Sig = ""
+}
+
+// SigHelpKeyInput updates signature help after the given key has been
+// processed: typing an open paren starts it, Escape dismisses it, and any
+// other key while active updates the current param -- a close paren only
+// dismisses it if it closes the outermost call, and otherwise goes back to
+// the signature of the enclosing call
+func (tv *TextView) SigHelpKeyInput(kt *key.ChordEvent) {
+	switch {
+	case kt.Code == key.CodeEscape:
+		tv.SigHelpOff()
+	case kt.Rune == '(':
+		tv.SigHelp.On = false
+		tv.SignatureHelp()
+	case tv.SigHelp.On:
+		tv.SignatureHelp()
+	}
+}
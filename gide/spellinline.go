@@ -0,0 +1,446 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/spell"
+	"github.com/goki/ki/ki"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/lex"
+	"github.com/goki/pi/token"
+)
+
+// SpellInlineWaitMSec is the number of milliseconds after the last key
+// press that the unknown words of a view are underlined again
+var SpellInlineWaitMSec = 500
+
+// SpellInlineMaxLines is the maximum number of lines of a buffer for which
+// unknown words are underlined
+var SpellInlineMaxLines = 20000
+
+// SpellMaxSuggests is the maximum number of suggestions for an unknown
+// word in the context menu
+var SpellMaxSuggests = 8
+
+// SpellInlineState is the state of the underlining of unknown words of a
+// TextView
+type SpellInlineState struct {
+	Timer  *time.Timer `desc:"timer for checking after typing pauses"`
+	Marked bool        `desc:"unknown words are currently underlined"`
+}
+
+// SpellSpan is the span of an unknown word in a line, in runes
+type SpellSpan struct {
+	St int `desc:"start of the word"`
+	Ed int `desc:"end of the word, exclusive"`
+}
+
+// AddWord adds the word, in lower case, to the custom dictionary of the
+// project, keeping it sorted -- returns false if it is already there
+func (sp *SpellParams) AddWord(word string) bool {
+	lw := strings.ToLower(word)
+	i := sort.SearchStrings(sp.Words, lw)
+	if i < len(sp.Words) && sp.Words[i] == lw {
+		return false
+	}
+	sp.Words = append(sp.Words, "")
+	copy(sp.Words[i+1:], sp.Words[i:])
+	sp.Words[i] = lw
+	return true
+}
+
+// WordsMap returns the custom dictionary of the project as a map, in lower
+// case
+func (sp *SpellParams) WordsMap() map[string]bool {
+	wm := make(map[string]bool, len(sp.Words))
+	for _, w := range sp.Words {
+		wm[strings.ToLower(w)] = true
+	}
+	return wm
+}
+
+// spellCache caches whether words are known by the spelling model of the
+// current language, as looking them up is slow
+type spellCache struct {
+	Mu    sync.Mutex
+	Lang  string
+	Known map[string]bool
+}
+
+var spellKnown spellCache
+
+// ClearSpellCache clears the cache of the words known by the spelling
+// model, e.g., after a word has been learned
+func ClearSpellCache() {
+	spellKnown.Mu.Lock()
+	spellKnown.Known = nil
+	spellKnown.Mu.Unlock()
+}
+
+// SpellWordKnown returns true if the word is in the given custom dictionary
+// (in lower case), is ignored, or is known by the spelling model
+func SpellWordKnown(word string, words map[string]bool) bool {
+	lw := strings.ToLower(word)
+	if words[lw] || spell.DoIgnore(word) || spell.DoIgnore(lw) {
+		return true
+	}
+	spellKnown.Mu.Lock()
+	defer spellKnown.Mu.Unlock()
+	if spellKnown.Known == nil || spellKnown.Lang != CurSpellLang {
+		spellKnown.Known = make(map[string]bool)
+		spellKnown.Lang = CurSpellLang
+	}
+	if kn, ok := spellKnown.Known[lw]; ok {
+		return kn
+	}
+	_, kn, err := spell.CheckWord(lw)
+	kn = kn || err != nil
+	spellKnown.Known[lw] = kn
+	return kn
+}
+
+// spellSkipRunes are runes that make a whitespace-separated field of text
+// code, a path, a URL or an email address, rather than words, if they are
+// inside of it
+const spellSkipRunes = "_/\\@<>=(){}[]#$%^&*+|~`.:;"
+
+// SpellWords calls fun for each word of the runes of a line from st to ed
+// (exclusive) that is spell checked: words of at least 3 letters, split at
+// hyphens, without a possessive 's, skipping contractions, fields of text
+// with digits or code-like runes inside them, e.g., identifiers, paths and
+// URLs, and words with upper case letters after the first, e.g., camel case
+// names and acronyms
+func SpellWords(lt []rune, st, ed int, fun func(st, ed int)) {
+	if ed > len(lt) {
+		ed = len(lt)
+	}
+	for fs := st; fs < ed; {
+		if unicode.IsSpace(lt[fs]) {
+			fs++
+			continue
+		}
+		fe := fs
+		for fe < ed && !unicode.IsSpace(lt[fe]) {
+			fe++
+		}
+		ws, we := fs, fe
+		for ws < we && !unicode.IsLetter(lt[ws]) && !unicode.IsDigit(lt[ws]) {
+			ws++
+		}
+		for we > ws && !unicode.IsLetter(lt[we-1]) && !unicode.IsDigit(lt[we-1]) {
+			we--
+		}
+		fs = fe
+		if ws == we || strings.ContainsAny(string(lt[ws:we]), spellSkipRunes) {
+			continue
+		}
+		code := false
+		for _, r := range lt[ws:we] {
+			if unicode.IsDigit(r) {
+				code = true
+				break
+			}
+		}
+		if code {
+			continue
+		}
+		for ps := ws; ps < we; {
+			pe := ps
+			for pe < we && unicode.IsLetter(lt[pe]) {
+				pe++
+			}
+			ne := pe // end of the part, with any apostrophe suffix
+			for ne < we && lt[ne] != '-' {
+				ne++
+			}
+			if pe-ps >= 3 && !spellMixedCase(lt[ps:pe]) && (ne == pe || spellPossessive(lt[pe:ne])) {
+				fun(ps, pe)
+			}
+			ps = ne + 1
+		}
+	}
+}
+
+// spellPossessive returns true if the suffix of a word is 's, the only one
+// after which the word is checked -- others are contractions, e.g., n't
+func spellPossessive(sfx []rune) bool {
+	return len(sfx) == 2 && (sfx[0] == '\'' || sfx[0] == '’') && unicode.ToLower(sfx[1]) == 's'
+}
+
+// spellMixedCase returns true if the word has an upper case letter after
+// the first one
+func spellMixedCase(w []rune) bool {
+	for _, r := range w[1:] {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanCommentsStrings calls fun for each span of the lines in comments and
+// strings, in order -- the complement of scanCode
+func scanCommentsStrings(lines [][]rune, syn *BracketSyntax, fun func(ln, st, ed int)) {
+	cln := []rune(syn.CommentLn)
+	cst := []rune(syn.CommentSt)
+	ced := []rune(syn.CommentEd)
+	incmt := false
+	for ln, lt := range lines {
+		cs := 0 // start of the multi-line comment on this line
+		for ch := 0; ch < len(lt); ch++ {
+			if incmt {
+				if runesAt(lt, ch, ced) {
+					fun(ln, cs, ch)
+					incmt = false
+					ch += len(ced) - 1
+				}
+				continue
+			}
+			if runesAt(lt, ch, cln) {
+				fun(ln, ch+len(cln), len(lt))
+				break
+			}
+			if len(ced) > 0 && runesAt(lt, ch, cst) {
+				incmt = true
+				ch += len(cst) - 1
+				cs = ch + 1
+				continue
+			}
+			if strings.ContainsRune(syn.Quotes, lt[ch]) {
+				if qe := quoteEnd(lt, ch); qe > 0 {
+					fun(ln, ch+1, qe)
+					ch = qe
+				}
+			}
+		}
+		if incmt {
+			fun(ln, cs, len(lt))
+		}
+	}
+}
+
+// SpellUnknowns returns the spans of the unknown words of each line (see
+// SpellWords) in comments and strings, or in all the text if prose, given
+// the custom dictionary of the project, in lower case
+func SpellUnknowns(lines [][]rune, syn *BracketSyntax, prose bool, words map[string]bool) [][]SpellSpan {
+	unk := make([][]SpellSpan, len(lines))
+	check := func(ln, st, ed int) {
+		lt := lines[ln]
+		SpellWords(lt, st, ed, func(ws, we int) {
+			if !SpellWordKnown(string(lt[ws:we]), words) {
+				unk[ln] = append(unk[ln], SpellSpan{St: ws, Ed: we})
+			}
+		})
+	}
+	if prose {
+		for ln, lt := range lines {
+			check(ln, 0, len(lt))
+		}
+	} else {
+		scanCommentsStrings(lines, syn, check)
+	}
+	return unk
+}
+
+// SetSpellTags sets the TextSpellErr tags of the buffer, rendered as a
+// dotted underline, to the spans of the unknown words of each line,
+// replacing the previous ones, and marks up the lines with changes --
+// returns those lines
+func SetSpellTags(tb *giv.TextBuf, unk [][]SpellSpan) []int {
+	var chg []int
+	nln := tb.NumLines()
+	for ln := 0; ln < nln && ln < len(tb.Tags); ln++ {
+		var sps []SpellSpan
+		if ln < len(unk) {
+			sps = unk[ln]
+		}
+		var tags lex.Line
+		var osps []SpellSpan
+		for _, tg := range tb.AdjustedTags(ln) {
+			if tg.Tok.Tok == token.TextSpellErr {
+				osps = append(osps, SpellSpan{St: tg.St, Ed: tg.Ed})
+				continue
+			}
+			tags = append(tags, tg)
+		}
+		if spellSpansEqual(osps, sps) {
+			continue
+		}
+		for _, sp := range sps {
+			tr := lex.NewLex(token.KeyToken{Tok: token.TextSpellErr}, sp.St, sp.Ed)
+			tr.Time.Now()
+			tags.AddSort(tr)
+		}
+		tb.Tags[ln] = tags
+		tb.MarkupLinesLock(ln, ln)
+		chg = append(chg, ln)
+	}
+	return chg
+}
+
+// spellSpansEqual returns true if the spans are the same
+func spellSpansEqual(a, b []SpellSpan) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// UpdateSpellInline underlines the unknown words of the view again after
+// SpellInlineWaitMSec, if SpellInline is on, or removes the underlines if
+// it has been turned off
+func (tv *TextView) UpdateSpellInline() {
+	if !tv.SpellInline && !tv.InlineSpell.Marked {
+		return
+	}
+	if tv.InlineSpell.Timer != nil {
+		tv.InlineSpell.Timer.Stop()
+	}
+	tv.InlineSpell.Timer = time.AfterFunc(time.Duration(SpellInlineWaitMSec)*time.Millisecond, tv.SpellInlineCheck)
+}
+
+// SpellInlineCheck underlines the unknown words in the comments and strings
+// of the buffer, or in all its text for prose files, in the spelling
+// language of the project, skipping the words of its custom dictionary --
+// all the underlines are removed if SpellInline is off
+func (tv *TextView) SpellInlineCheck() {
+	tb := tv.Buf
+	if tb == nil {
+		return
+	}
+	prose := IsProseFile(&tb.Info) || tv.ProseMode
+	var unk [][]SpellSpan
+	if tv.SpellInline && (prose || tb.Info.Cat == filecat.Code) && tb.NumLines() <= SpellInlineMaxLines {
+		lang := ""
+		var words map[string]bool
+		if ge, ok := ParentGide(tv.This()); ok {
+			sp := &ge.ProjPrefs().Spell
+			lang = sp.Lang
+			words = sp.WordsMap()
+		}
+		if LoadSpellLang(lang) == nil {
+			syn := BufBracketSyntax(tb)
+			tb.LinesMu.RLock()
+			unk = SpellUnknowns(tb.Lines, &syn, prose, words)
+			tb.LinesMu.RUnlock()
+		}
+	}
+	tv.InlineSpell.Marked = tv.SpellInline
+	chg := SetSpellTags(tb, unk)
+	if len(chg) == 0 || tv.Viewport == nil || tv.Viewport.Win == nil || tv.NLines == 0 {
+		return
+	}
+	updt := tv.Viewport.Win.UpdateStart()
+	for _, ln := range chg {
+		if ln < tv.NLines {
+			tv.LayoutLines(ln, ln, false)
+			tv.RenderLines(ln, ln)
+		}
+	}
+	tv.Viewport.Win.UpdateEnd(updt)
+	tv.UpdateOverlays()
+}
+
+// SpellWordAt returns the region and text of the word underlined as unknown
+// at given position, if any
+func (tv *TextView) SpellWordAt(pos giv.TextPos) (giv.TextRegion, string, bool) {
+	tb := tv.Buf
+	if tb == nil || !tb.IsValidLine(pos.Ln) || pos.Ln >= len(tb.Tags) {
+		return giv.TextRegionNil, "", false
+	}
+	for _, tg := range tb.AdjustedTags(pos.Ln) {
+		if tg.Tok.Tok != token.TextSpellErr || pos.Ch < tg.St || pos.Ch > tg.Ed {
+			continue
+		}
+		lt := tb.Line(pos.Ln)
+		if tg.Ed > len(lt) {
+			break
+		}
+		return giv.NewTextRegion(pos.Ln, tg.St, pos.Ln, tg.Ed), string(lt[tg.St:tg.Ed]), true
+	}
+	return giv.TextRegionNil, "", false
+}
+
+// SpellContextMenu adds the suggestions for the word underlined as unknown
+// at the cursor, if any, to the menu, followed by actions to add it to the
+// custom dictionary of the project, or ignore it for this session
+func (tv *TextView) SpellContextMenu(m *gi.Menu) {
+	if tv.IsInactive() {
+		return
+	}
+	reg, word, ok := tv.SpellWordAt(tv.CursorPos)
+	if !ok {
+		return
+	}
+	sugs, _, _ := spell.CheckWord(word)
+	if len(sugs) > SpellMaxSuggests {
+		sugs = sugs[:SpellMaxSuggests]
+	}
+	for _, sug := range sugs {
+		sug := sug
+		m.AddAction(gi.ActOpts{Label: sug},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				txf.SpellReplace(reg, word, sug)
+			})
+	}
+	m.AddAction(gi.ActOpts{Label: "Add to Project Dictionary", Tooltip: "add the word to the custom dictionary of the project, saved with it, so it is known in all of its files"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			txf := recv.Embed(KiT_TextView).(*TextView)
+			txf.AddSpellWord(word)
+		})
+	m.AddAction(gi.ActOpts{Label: "Ignore", Tooltip: "ignore the word in all files until gide quits"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			txf := recv.Embed(KiT_TextView).(*TextView)
+			spell.IgnoreWord(word)
+			txf.SpellInlineCheck()
+		})
+	m.AddSeparator("sep-spell")
+}
+
+// SpellReplace replaces the unknown word in given region with the
+// suggestion, keeping the case of its first letters, if it is still there
+func (tv *TextView) SpellReplace(reg giv.TextRegion, word, sug string) {
+	tb := tv.Buf
+	if tb == nil {
+		return
+	}
+	if tbe := tb.Region(reg.Start, reg.End); tbe == nil || string(tbe.ToBytes()) != word {
+		return
+	}
+	nw := spell.CorrectText(word, sug).NewText
+	tb.DeleteText(reg.Start, reg.End, true, true)
+	tb.InsertText(reg.Start, []byte(nw), true, true)
+	tv.UpdateSpellInline()
+}
+
+// AddSpellWord adds the word to the custom dictionary of the project, saved
+// with it, so it is no longer underlined
+func (tv *TextView) AddSpellWord(word string) {
+	ge, ok := ParentGide(tv.This())
+	if !ok {
+		return
+	}
+	pp := ge.ProjPrefs()
+	if pp.Spell.AddWord(word) {
+		pp.Changed = true
+	}
+	ge.SetStatus(fmt.Sprintf("Added to the project dictionary: %v", strings.ToLower(word)))
+	tv.SpellInlineCheck()
+}
@@ -0,0 +1,301 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/spell"
+	"github.com/goki/pi/filecat"
+)
+
+// DefaultSpellLang is the spelling language used when a project does not
+// specify one -- it is the model that gi builds from its own corpus
+var DefaultSpellLang = "en_US"
+
+// StdSpellLangs are commonly-used spelling language codes offered in the
+// language chooser, in addition to any dictionaries that are installed
+var StdSpellLangs = []string{"en_US", "en_GB", "de_DE", "fr_FR", "es_ES", "it_IT", "nl_NL", "pt_BR", "ru_RU"}
+
+// CurSpellLang is the spelling language of the currently-loaded spell model
+var CurSpellLang = ""
+
+// SpellLangFileName returns the file name of the spelling model for given
+// language, within the GoGi prefs directory -- en_US uses the standard gi model
+func SpellLangFileName(lang string) string {
+	if lang == "" {
+		lang = DefaultSpellLang
+	}
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	return filepath.Join(pdir, "spell_"+strings.ToLower(lang)+"_plain.json")
+}
+
+// SpellLangsInstalled returns the sorted list of spelling languages that have
+// a saved model in the GoGi prefs directory
+func SpellLangsInstalled() []string {
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	fls, _ := filepath.Glob(filepath.Join(pdir, "spell_*_plain.json"))
+	var langs []string
+	for _, fl := range fls {
+		fn := filepath.Base(fl)
+		lang := strings.TrimSuffix(strings.TrimPrefix(fn, "spell_"), "_plain.json")
+		if ui := strings.Index(lang, "_"); ui > 0 {
+			lang = lang[:ui] + "_" + strings.ToUpper(lang[ui+1:])
+		}
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// SpellLangNames returns the list of languages to choose from: installed
+// languages first, followed by the standard languages not yet installed
+func SpellLangNames() []string {
+	inst := SpellLangsInstalled()
+	nms := make([]string, 0, len(inst)+len(StdSpellLangs))
+	has := make(map[string]bool, len(inst))
+	for _, l := range inst {
+		nms = append(nms, l)
+		has[l] = true
+	}
+	for _, l := range StdSpellLangs {
+		if !has[l] {
+			nms = append(nms, l)
+		}
+	}
+	return nms
+}
+
+// LoadSpellLang makes the spelling model for given language the current
+// one, if it is not already -- the standard gi model is always initialized
+// first, and the default language just uses that model.  Returns an error if
+// no dictionary has been installed for the language.
+func LoadSpellLang(lang string) error {
+	gi.InitSpell()
+	if lang == "" {
+		lang = DefaultSpellLang
+	}
+	if lang == CurSpellLang {
+		return nil
+	}
+	if lang == DefaultSpellLang && CurSpellLang == "" {
+		CurSpellLang = lang
+		return nil
+	}
+	fn := SpellLangFileName(lang)
+	if _, err := os.Stat(fn); os.IsNotExist(err) {
+		return fmt.Errorf("no spelling dictionary installed for language: %v -- use Install Dict to download one", lang)
+	}
+	if err := spell.Load(fn); err != nil {
+		return err
+	}
+	CurSpellLang = lang
+	return nil
+}
+
+// SpellDictTimeout is the maximum time for downloading a dictionary in
+// FetchSpellDict
+var SpellDictTimeout = 2 * time.Minute
+
+// SaveSpellLang saves the current spelling model, including any learned
+// words, to the file for the currently-loaded language -- the standard gi
+// model if no language has been loaded
+func SaveSpellLang() error {
+	if CurSpellLang == "" {
+		return gi.SaveSpellModel()
+	}
+	return spell.Save(SpellLangFileName(CurSpellLang))
+}
+
+// InstallSpellDict downloads a plain-text dictionary or corpus for given
+// language from url (or copies it if url is a local file path), and trains a
+// new spelling model on it, which then becomes the current model -- see
+// FetchSpellDict and TrainSpellDict to download it in the background
+func InstallSpellDict(lang, url string) error {
+	cfn, err := FetchSpellDict(lang, url)
+	if err != nil {
+		return err
+	}
+	return TrainSpellDict(lang, cfn)
+}
+
+// FetchSpellDict downloads a plain-text dictionary or corpus for given
+// language from url, waiting at most SpellDictTimeout, or copies it if url
+// is a local file path, returning the file it is saved to, in the GoGi
+// prefs directory -- it does not use the spelling model, and can be run in
+// the background
+func FetchSpellDict(lang, url string) (string, error) {
+	if lang == "" || url == "" {
+		return "", fmt.Errorf("InstallSpellDict: language and url must both be specified")
+	}
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	cfn := filepath.Join(pdir, "spell_"+strings.ToLower(lang)+"_corpus.txt")
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		b, err := ioutil.ReadFile(url)
+		if err != nil {
+			return "", err
+		}
+		return cfn, ioutil.WriteFile(cfn, b, 0644)
+	}
+	client := &http.Client{Timeout: SpellDictTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("InstallSpellDict: download of %v failed: %v", url, resp.Status)
+	}
+	out, err := os.Create(cfn)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(out, resp.Body)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(cfn)
+		return "", err
+	}
+	return cfn, nil
+}
+
+// TrainSpellDict trains a new spelling model for given language on the
+// dictionary or corpus file from FetchSpellDict, which then becomes the
+// current model, and saves it
+func TrainSpellDict(lang, cfn string) error {
+	gi.InitSpell()
+	f, err := os.Open(cfn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := spell.Train(*f, true); err != nil { // true = new model
+		return err
+	}
+	CurSpellLang = lang
+	return SaveSpellLang()
+}
+
+// DeleteSpellDict removes the installed spelling model and corpus for given
+// language -- the default language cannot be deleted
+func DeleteSpellDict(lang string) error {
+	if lang == "" || lang == DefaultSpellLang {
+		return fmt.Errorf("DeleteSpellDict: cannot delete the default spelling language: %v", DefaultSpellLang)
+	}
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	os.Remove(filepath.Join(pdir, "spell_"+strings.ToLower(lang)+"_corpus.txt"))
+	err := os.Remove(SpellLangFileName(lang))
+	if lang == CurSpellLang {
+		CurSpellLang = ""
+	}
+	return err
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Grammar
+
+// GrammarIssue is one problem reported by a GrammarChecker
+type GrammarIssue struct {
+	Reg      giv.TextRegion `desc:"region of text that the issue applies to"`
+	Msg      string         `desc:"description of the issue"`
+	Suggests []string       `desc:"suggested replacements, if any"`
+}
+
+// GrammarChecker is the interface for a grammar checking provider, which
+// could be an external program or service
+type GrammarChecker interface {
+	// Name is the name of the provider, as shown in the chooser
+	Name() string
+
+	// Check checks the given text, in given spelling language, returning
+	// any issues found
+	Check(text []byte, lang string) ([]GrammarIssue, error)
+}
+
+// GrammarCheckers are the available grammar checking providers, by name --
+// add to this to register a new provider
+var GrammarCheckers = map[string]GrammarChecker{
+	"vale": &CmdGrammarChecker{Nm: "vale", Cmd: "vale", Args: []string{"--output=line", "--ext=.md"}},
+}
+
+// GrammarCheckerNames returns the sorted names of the GrammarCheckers
+func GrammarCheckerNames() []string {
+	nms := make([]string, 0, len(GrammarCheckers))
+	for nm := range GrammarCheckers {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	return nms
+}
+
+// IsProseFile returns true if given file info is for a prose file (a
+// document or plain text) that grammar checking applies to
+func IsProseFile(fi *giv.FileInfo) bool {
+	return fi.Cat == filecat.Doc || fi.Cat == filecat.Text
+}
+
+// CmdGrammarChecker is a GrammarChecker that runs an external command,
+// passing the text on stdin, and parsing output lines of the form
+// [file:]line:col: message -- {Lang} in the args is replaced with the
+// spelling language
+type CmdGrammarChecker struct {
+	Nm   string   `desc:"name of the checker"`
+	Cmd  string   `desc:"command to run"`
+	Args []string `desc:"args for the command -- {Lang} is replaced with the language"`
+}
+
+// Name returns the name of the checker
+func (gc *CmdGrammarChecker) Name() string {
+	return gc.Nm
+}
+
+// grammarLineRe matches [file:]line:col: message output lines
+var grammarLineRe = regexp.MustCompile(`^(?:.*?:)?(\d+):(\d+):\s*(.*)$`)
+
+// Check runs the command on the text and parses its output
+func (gc *CmdGrammarChecker) Check(text []byte, lang string) ([]GrammarIssue, error) {
+	args := make([]string, len(gc.Args))
+	for i, a := range gc.Args {
+		args[i] = strings.Replace(a, "{Lang}", lang, -1)
+	}
+	cmd := exec.Command(gc.Cmd, args...)
+	cmd.Stdin = bytes.NewReader(text)
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("grammar checker %v failed: %v", gc.Nm, err)
+	}
+	var iss []GrammarIssue
+	for _, ln := range strings.Split(string(out), "\n") {
+		m := grammarLineRe.FindStringSubmatch(strings.TrimSpace(ln))
+		if m == nil {
+			continue
+		}
+		l, _ := strconv.Atoi(m[1])
+		c, _ := strconv.Atoi(m[2])
+		st := giv.TextPos{Ln: l - 1, Ch: c - 1}
+		if st.Ch < 0 {
+			st.Ch = 0
+		}
+		iss = append(iss, GrammarIssue{Reg: giv.TextRegion{Start: st, End: st}, Msg: m[3]})
+	}
+	return iss, nil
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/filecat"
+)
+
+// SpellWordLoc is the location of an unknown word in a file
+type SpellWordLoc struct {
+	Word string `desc:"the unknown word"`
+	Ln   int    `desc:"line of the word, starting at 0"`
+	Ch   int    `desc:"start of the word in the line, in runes"`
+	Ed   int    `desc:"end of the word in the line, in runes, exclusive"`
+}
+
+// SpellFileResult is the unknown words of one file, from SpellProjFiles
+type SpellFileResult struct {
+	FPath string         `desc:"path of the file"`
+	Words []SpellWordLoc `desc:"the unknown words, in order"`
+}
+
+// FileSpellSyntax returns the comment and quote syntax of the language of
+// the file, and whether all of its text is checked, as prose (see
+// IsProseFile), or only its comments and strings, as code -- ok is false if
+// it is neither
+func FileSpellSyntax(fi *giv.FileInfo) (syn BracketSyntax, prose, ok bool) {
+	if IsProseFile(fi) {
+		return syn, true, true
+	}
+	if fi.Cat != filecat.Code {
+		return syn, false, false
+	}
+	var opts giv.TextBufOpts
+	opts.ConfigSupported(fi.Sup)
+	cs := LangCommentStrs(fi.Sup, &opts)
+	syn = BracketSyntax{CommentLn: cs.Ln, Quotes: "\"'`"}
+	if cs.St != "" && cs.Ed != "" {
+		syn.CommentSt = cs.St
+		syn.CommentEd = cs.Ed
+	}
+	return syn, false, true
+}
+
+// SpellCheckFile returns the unknown words in the comments and strings of
+// the file at given path, or in all its text if it is a prose file, as
+// saved, given the custom dictionary of the project, in lower case --
+// false if the file is not checked: it is neither prose nor code, or of
+// none of the given languages (all if empty), or cannot be read
+func SpellCheckFile(fpath string, langs []filecat.Supported, words map[string]bool) ([]SpellWordLoc, bool) {
+	fi, err := giv.NewFileInfo(fpath)
+	if err != nil || !filecat.IsMatchList(langs, fi.Sup) {
+		return nil, false
+	}
+	syn, prose, ok := FileSpellSyntax(fi)
+	if !ok {
+		return nil, false
+	}
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil, false
+	}
+	blns := bytes.Split(b, []byte("\n"))
+	lines := make([][]rune, len(blns))
+	for i, bl := range blns {
+		lines[i] = []rune(string(bytes.TrimSuffix(bl, []byte("\r"))))
+	}
+	var locs []SpellWordLoc
+	for ln, sps := range SpellUnknowns(lines, &syn, prose, words) {
+		for _, sp := range sps {
+			locs = append(locs, SpellWordLoc{Word: string(lines[ln][sp.St:sp.Ed]), Ln: ln, Ch: sp.St, Ed: sp.Ed})
+		}
+	}
+	return locs, true
+}
+
+// SpellProjFiles spell checks the files under the project root (see
+// WalkProjFiles) of given languages (all if empty), except those matching
+// any of the glob patterns in ignore (see GlobPatterns and GlobMatch), with
+// SpellCheckFile -- returns the files with unknown words, in order of their
+// paths, and the number of files checked -- returns an error if a pattern
+// is not valid
+func SpellProjFiles(root string, langs []filecat.Supported, ignore string, words map[string]bool) ([]SpellFileResult, int, error) {
+	pats := GlobPatterns(ignore)
+	for _, pat := range pats {
+		if _, err := filepath.Match(pat, ""); err != nil {
+			return nil, 0, fmt.Errorf("invalid glob pattern: %v: %v", pat, err)
+		}
+	}
+	var res []SpellFileResult
+	nchk := 0
+	WalkProjFiles(root, func(path string, info os.FileInfo) {
+		if len(pats) > 0 && GlobMatch(pats, root, path) {
+			return
+		}
+		if IsBinaryFile(path) {
+			return
+		}
+		locs, ok := SpellCheckFile(path, langs, words)
+		if !ok {
+			return
+		}
+		nchk++
+		if len(locs) > 0 {
+			res = append(res, SpellFileResult{FPath: path, Words: locs})
+		}
+	})
+	return res, nchk, nil
+}
+
+// SpellResultWords returns the distinct unknown words of the results,
+// sorted -- words differing only in case are listed once, in lower case
+func SpellResultWords(res []SpellFileResult) []string {
+	wm := make(map[string]string)
+	for _, fr := range res {
+		for _, wl := range fr.Words {
+			lw := strings.ToLower(wl.Word)
+			if w, has := wm[lw]; has && w != wl.Word {
+				wm[lw] = lw
+			} else if !has {
+				wm[lw] = wl.Word
+			}
+		}
+	}
+	wds := make([]string, 0, len(wm))
+	for _, w := range wm {
+		wds = append(wds, w)
+	}
+	sort.Strings(wds)
+	return wds
+}
@@ -5,7 +5,10 @@
 package gide
 
 import (
+	"bytes"
 	"fmt"
+	"html"
+	"path/filepath"
 	"strings"
 
 	"github.com/goki/gi/spell"
@@ -15,10 +18,16 @@ import (
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
+	"github.com/goki/pi/filecat"
 )
 
 // SpellParams are parameters for spell check and correction
 type SpellParams struct {
+	Lang    string              `desc:"spelling language for this project, e.g., en_US, de_DE -- empty = DefaultSpellLang"`
+	Grammar string              `desc:"name of grammar checker to use for prose files (see GrammarCheckers) -- empty = no grammar checking"`
+	Words   []string            `desc:"custom dictionary of the project: words, in lower case, that are known in its files in addition to those of the language"`
+	Langs   []filecat.Supported `desc:"languages of the files checked by Check Project -- all if empty"`
+	Ignore  string              `desc:"glob patterns of the files not checked by Check Project, separated by spaces or commas, e.g., *.pb.go testdata/* -- matched against the file name, or the path relative to the project root if the pattern has a /"`
 }
 
 // SpellView is a widget that displays results of spell check
@@ -32,13 +41,22 @@ type SpellView struct {
 	PreviousLine int         `desc:"line of previous unknown word"`
 	CurrentLine  int         `desc:"line of current unknown word"`
 	LastAction   *gi.Action  `desc:"last user action (ignore, change, learn)"`
+	ProjWords    []string    `desc:"distinct unknown words found by the last Check Project"`
 }
 
 var KiT_SpellView = kit.Types.AddType(&SpellView{}, SpellViewProps)
 
+// SaveParams saves the params to the project prefs, keeping the custom
+// dictionary of the project, which is only edited there
+func (sv *SpellView) SaveParams() {
+	pp := sv.Gide.ProjPrefs()
+	sv.Spell.Words = pp.Spell.Words
+	pp.Spell = sv.Spell
+}
+
 // SpellAction runs a new spell check with current params
 func (sv *SpellView) SpellAction() {
-	sv.Gide.ProjPrefs().Spell = sv.Spell
+	sv.SaveParams()
 
 	uf := sv.UnknownText()
 	uf.SetText("")
@@ -190,12 +208,47 @@ func (sv *SpellView) ConfigToolbar() {
 			svv.SpellAction()
 		})
 
+	spbar.AddAction(gi.ActOpts{Label: "Check Project", Tooltip: "spell check the comments and strings of all the code files of the project, and all the text of its prose files, listing the unknown words by file"}, sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		svv, _ := recv.Embed(KiT_SpellView).(*SpellView)
+		giv.CallMethod(svv, "SpellProject", svv.Viewport)
+	})
+
+	spbar.AddAction(gi.ActOpts{Label: "Add All to Project", Tooltip: "add the unknown words found by Check Project to the custom dictionary of the project, after reviewing them"}, sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		svv, _ := recv.Embed(KiT_SpellView).(*SpellView)
+		svv.AddAllProjAction()
+	})
+
+	spbar.AddAction(gi.ActOpts{Label: "Ignore All", Tooltip: "ignore the unknown words found by Check Project for the rest of this session, after reviewing them"}, sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		svv, _ := recv.Embed(KiT_SpellView).(*SpellView)
+		svv.IgnoreAllAction()
+	})
+
 	train := spbar.AddAction(gi.ActOpts{Label: "Train", Tooltip: "add additional text to the training corpus"}, sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		svv, _ := recv.Embed(KiT_SpellView).(*SpellView)
 		svv.TrainAction()
 	})
 	train.SetProp("horizontal-align", gi.AlignRight)
 
+	spbar.AddAction(gi.ActOpts{Name: "lang", Label: sv.LangLabel(), Tooltip: "select the spelling language for this project"}, sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		svv, _ := recv.Embed(KiT_SpellView).(*SpellView)
+		svv.LangAction()
+	})
+
+	spbar.AddAction(gi.ActOpts{Label: "Install Dict", Tooltip: "download (or copy from a local file) a plain-text dictionary or corpus for a language, and train a new spelling model on it"}, sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		svv, _ := recv.Embed(KiT_SpellView).(*SpellView)
+		giv.CallMethod(svv, "InstallDict", svv.Viewport)
+	})
+
+	spbar.AddAction(gi.ActOpts{Label: "Project Words", Tooltip: "edit the custom dictionary of the project: words that are known in its files, saved with it"}, sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		svv, _ := recv.Embed(KiT_SpellView).(*SpellView)
+		svv.EditWords()
+	})
+
+	spbar.AddAction(gi.ActOpts{Label: "Grammar", Tooltip: "check grammar of the current prose file using the selected grammar checker"}, sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		svv, _ := recv.Embed(KiT_SpellView).(*SpellView)
+		svv.GrammarAction()
+	})
+
 	// unknown toolbar
 
 	unknown := unknbar.AddNewChild(gi.KiT_TextField, "unknown-str").(*gi.TextField)
@@ -226,6 +279,12 @@ func (sv *SpellView) ConfigToolbar() {
 			svv.LearnAction()
 		})
 
+	unknbar.AddAction(gi.ActOpts{Name: "add-proj", Label: "Add to Project", Tooltip: "add the word to the custom dictionary of the project, saved with it"}, sv.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_SpellView).(*SpellView)
+			svv.AddProjAction()
+		})
+
 	// change toolbar
 	changestr := chgbar.AddNewChild(gi.KiT_TextField, "change-str").(*gi.TextField)
 	changestr.SetStretchMaxWidth()
@@ -259,6 +318,221 @@ func (sv *SpellView) ConfigToolbar() {
 	})
 }
 
+// LangLabel returns the label for the language action
+func (sv *SpellView) LangLabel() string {
+	lang := sv.Spell.Lang
+	if lang == "" {
+		lang = DefaultSpellLang
+	}
+	return "Lang: " + lang
+}
+
+// LangAction pops up a chooser to select the spelling language
+func (sv *SpellView) LangAction() {
+	la := sv.SpellBar().ChildByName("lang", 3).(*gi.Action)
+	gi.StringsChooserPopup(SpellLangNames(), sv.Spell.Lang, la, func(recv, send ki.Ki, sig int64, data interface{}) {
+		ac := send.(*gi.Action)
+		sv.SetLang(ac.Text)
+	})
+}
+
+// SetLang sets the spelling language for the project and loads its model
+func (sv *SpellView) SetLang(lang string) {
+	sv.Spell.Lang = lang
+	sv.SaveParams()
+	la := sv.SpellBar().ChildByName("lang", 3).(*gi.Action)
+	la.SetText(sv.LangLabel())
+	SaveSpellLang()
+	if err := LoadSpellLang(lang); err != nil {
+		gi.PromptDialog(sv.Viewport, gi.DlgOpts{Title: "Spelling Language Not Installed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+	}
+}
+
+// InstallDict installs a dictionary for given language from given url or
+// local file path, and makes it the spelling language for the project --
+// it is downloaded in the background, and the model trained on it when done
+func (sv *SpellView) InstallDict(lang string, url string) {
+	sv.Gide.SetStatus(fmt.Sprintf("Installing spelling dictionary for: %v", lang))
+	go func() {
+		cfn, err := FetchSpellDict(lang, url)
+		sv.Gide.RunOnGUI(func() {
+			if err == nil {
+				err = TrainSpellDict(lang, cfn)
+			}
+			if err != nil {
+				gi.PromptDialog(sv.Viewport, gi.DlgOpts{Title: "Dictionary Install Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+				sv.Gide.SetStatus(fmt.Sprintf("Could not install spelling dictionary for: %v", lang))
+				return
+			}
+			sv.SetLang(lang)
+			sv.Gide.SetStatus(fmt.Sprintf("Installed spelling dictionary for: %v", lang))
+		})
+	}()
+}
+
+// GrammarAction checks the grammar of the current file, if it is a prose
+// file, using the grammar checker selected in the params (prompting for one
+// if not yet selected), writing results to the spell output
+func (sv *SpellView) GrammarAction() {
+	if sv.Spell.Grammar == "" {
+		la := sv.SpellBar().ChildByName("lang", 3).(*gi.Action)
+		gi.StringsChooserPopup(GrammarCheckerNames(), "", la, func(recv, send ki.Ki, sig int64, data interface{}) {
+			ac := send.(*gi.Action)
+			sv.Spell.Grammar = ac.Text
+			sv.SaveParams()
+			sv.GrammarAction()
+		})
+		return
+	}
+	gc, ok := GrammarCheckers[sv.Spell.Grammar]
+	if !ok {
+		gi.PromptDialog(sv.Viewport, gi.DlgOpts{Title: "Grammar Checker Not Found", Prompt: fmt.Sprintf("No grammar checker named: %v", sv.Spell.Grammar)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	tv := sv.Gide.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	if !IsProseFile(&tv.Buf.Info) {
+		sv.Gide.SetStatus("Grammar check only applies to prose (document or text) files")
+		return
+	}
+	lang := sv.Spell.Lang
+	if lang == "" {
+		lang = DefaultSpellLang
+	}
+	iss, err := gc.Check(tv.Buf.LinesToBytesCopy(), lang)
+	if err != nil {
+		gi.PromptDialog(sv.Viewport, gi.DlgOpts{Title: "Grammar Check Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	outlns := make([][]byte, 0, len(iss))
+	outmus := make([][]byte, 0, len(iss)) // markups
+	fp := string(tv.Buf.Filename)
+	for _, is := range iss {
+		ln := is.Reg.Start.Ln + 1
+		ch := is.Reg.Start.Ch + 1
+		fnstr := fmt.Sprintf("%v:%d:%d", fp, ln, ch)
+		lstr := fmt.Sprintf(`%v: %v`, fnstr, is.Msg)
+		outlns = append(outlns, []byte(lstr))
+		mstr := fmt.Sprintf(`<a href="spell:///%v#L%vC%v-L%vC%v">%v</a>: %v`, fp, ln, ch, ln, ch, fnstr, html.EscapeString(is.Msg))
+		outmus = append(outmus, []byte(mstr))
+	}
+	outbuf := sv.TextView().Buf
+	outbuf.New(0)
+	outbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	sv.Gide.SetStatus(fmt.Sprintf("Grammar check: %v issues found", len(iss)))
+}
+
+// SpellProject spell checks the files of the project of given languages
+// (all if empty), except those matching the glob patterns in ignore (see
+// SpellProjFiles), saving them in the params, and lists the unknown words
+// in the spell output, grouped by file, with links to their locations
+func (sv *SpellView) SpellProject(langs []filecat.Supported, ignore string) {
+	sv.Spell.Langs = langs
+	sv.Spell.Ignore = ignore
+	sv.SaveParams()
+	pp := sv.Gide.ProjPrefs()
+	root := string(pp.ProjRoot)
+	if root == "" {
+		return
+	}
+	if err := LoadSpellLang(sv.Spell.Lang); err != nil {
+		gi.PromptDialog(sv.Viewport, gi.DlgOpts{Title: "Spelling Language Not Installed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	sv.Gide.SetStatus("Spell checking project files...")
+	jb := sv.Gide.Jobs().Add("Spell", root, "spell checking the project files", nil)
+	res, nchk, err := SpellProjFiles(root, langs, ignore, pp.Spell.WordsMap())
+	jb.Finish()
+	if err != nil {
+		gi.PromptDialog(sv.Viewport, gi.DlgOpts{Title: "Invalid Ignore Pattern", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	sv.ProjWords = SpellResultWords(res)
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	nwd := 0
+	for _, fr := range res {
+		fp := fr.FPath
+		fn, _ := filepath.Rel(root, fp)
+		lstr := fmt.Sprintf(`%v: %v`, fn, len(fr.Words))
+		outlns = append(outlns, []byte(lstr))
+		outmus = append(outmus, []byte(fmt.Sprintf(`<b>%v</b>`, html.EscapeString(lstr))))
+		for _, wl := range fr.Words {
+			ln := wl.Ln + 1
+			ch := wl.Ch + 1
+			fnstr := fmt.Sprintf("%v:%d:%d", fn, ln, ch)
+			lstr = fmt.Sprintf("\t%v: %v", fnstr, wl.Word)
+			outlns = append(outlns, []byte(lstr))
+			mstr := fmt.Sprintf("\t<a href=\"spell:///%v#L%vC%v-L%vC%v\">%v</a>: %v", fp, ln, ch, ln, wl.Ed+1, html.EscapeString(fnstr), html.EscapeString(wl.Word))
+			outmus = append(outmus, []byte(mstr))
+		}
+		outlns = append(outlns, []byte(""))
+		outmus = append(outmus, []byte(""))
+		nwd += len(fr.Words)
+	}
+	outbuf := sv.TextView().Buf
+	outbuf.New(0)
+	outbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	sv.Gide.SetStatus(fmt.Sprintf("Spell check of project: %v unknown words (%v distinct) in %v of %v files", nwd, len(sv.ProjWords), len(res), nchk))
+}
+
+// ReviewProjWords opens a dialog with the unknown words found by the last
+// Check Project, where words can be removed or edited, and calls fun with
+// the words if accepted
+func (sv *SpellView) ReviewProjWords(title, prompt string, fun func(words []string)) {
+	if len(sv.ProjWords) == 0 {
+		sv.Gide.SetStatus("No unknown words: run Check Project first")
+		return
+	}
+	tmp := append([]string{}, sv.ProjWords...)
+	opts := giv.DlgOpts{Title: title, Prompt: prompt, Ok: true, Cancel: true}
+	giv.SliceViewDialog(sv.Viewport, &tmp, opts,
+		nil, sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			var wds []string
+			for _, w := range tmp {
+				if w = strings.TrimSpace(w); w != "" {
+					wds = append(wds, w)
+				}
+			}
+			fun(wds)
+			sv.SpellProject(sv.Spell.Langs, sv.Spell.Ignore)
+			if tv := sv.Gide.ActiveTextView(); tv != nil {
+				tv.UpdateSpellInline()
+			}
+		})
+}
+
+// AddAllProjAction adds the unknown words found by the last Check Project,
+// as reviewed, to the custom dictionary of the project, and checks it again
+func (sv *SpellView) AddAllProjAction() {
+	sv.ReviewProjWords("Add Words to Project", "Unknown words to add to the custom dictionary of the project -- delete those that are misspelled", func(words []string) {
+		pp := sv.Gide.ProjPrefs()
+		for _, w := range words {
+			if pp.Spell.AddWord(w) {
+				pp.Changed = true
+			}
+		}
+		sv.Spell.Words = pp.Spell.Words
+		sv.Gide.SetStatus(fmt.Sprintf("Added %v words to the project dictionary", len(words)))
+	})
+}
+
+// IgnoreAllAction ignores the unknown words found by the last Check
+// Project, as reviewed, for the rest of the session, and checks it again
+func (sv *SpellView) IgnoreAllAction() {
+	sv.ReviewProjWords("Ignore Words", "Unknown words to ignore for the rest of this session -- delete those that are misspelled", func(words []string) {
+		for _, w := range words {
+			spell.IgnoreWord(w)
+		}
+		sv.Gide.SetStatus(fmt.Sprintf("Ignoring %v words", len(words)))
+	})
+}
+
 // CheckNext will find the next misspelled/unknown word and get suggestions for replacing it
 func (sv *SpellView) CheckNext() {
 	tw, suggests, _ := gi.NextUnknownWord()
@@ -390,10 +664,43 @@ func (sv *SpellView) IgnoreAction() {
 func (sv *SpellView) LearnAction() {
 	nw := strings.ToLower(sv.Unknown.Word)
 	gi.LearnWord(nw)
+	ClearSpellCache()
 	sv.LastAction = sv.LearnAct()
 	sv.CheckNext()
 }
 
+// AddProjAction adds the current unknown word to the custom dictionary of
+// the project, and calls CheckNext
+func (sv *SpellView) AddProjAction() {
+	pp := sv.Gide.ProjPrefs()
+	if pp.Spell.AddWord(sv.Unknown.Word) {
+		pp.Changed = true
+	}
+	spell.IgnoreWord(sv.Unknown.Word) // skipped by CheckNext
+	sv.LastAction = sv.UnknownBar().ChildByName("add-proj", 3).(*gi.Action)
+	sv.CheckNext()
+}
+
+// EditWords opens a dialog editor of the custom dictionary of the project
+func (sv *SpellView) EditWords() {
+	pp := sv.Gide.ProjPrefs()
+	tmp := append([]string{}, pp.Spell.Words...)
+	opts := giv.DlgOpts{Title: "Project Words", Prompt: "Words that are known in the files of the project, in addition to those of the spelling language", Ok: true, Cancel: true}
+	giv.SliceViewDialog(sv.Viewport, &tmp, opts,
+		nil, sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			pp.Spell.Words = nil
+			for _, w := range tmp {
+				if w = strings.TrimSpace(w); w != "" {
+					pp.Spell.AddWord(w)
+				}
+			}
+			pp.Changed = true
+		})
+}
+
 // AcceptSuggestion replaces the misspelled word with the word in the ChangeText field
 func (sv *SpellView) AcceptSuggestion(s string) {
 	ct := sv.ChangeText()
@@ -408,4 +715,27 @@ var SpellViewProps = ki.Props{
 	"color":            &gi.Prefs.Colors.Font,
 	"max-width":        -1,
 	"max-height":       -1,
+	"CallMethods": ki.PropSlice{
+		{"SpellProject", ki.Props{
+			"Args": ki.PropSlice{
+				{"Langs", ki.Props{
+					"default-field": "Spell.Langs",
+				}},
+				{"Ignore", ki.Props{
+					"default-field": "Spell.Ignore",
+					"width":         60,
+				}},
+			},
+		}},
+		{"InstallDict", ki.Props{
+			"Args": ki.PropSlice{
+				{"Language", ki.Props{
+					"width": 20,
+				}},
+				{"URL or File Path", ki.Props{
+					"width": 60,
+				}},
+			},
+		}},
+	},
 }
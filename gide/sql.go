@@ -0,0 +1,577 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/complete"
+)
+
+// SQLPrefs are the project settings for the database used by the .sql
+// files, for schema-aware completion and explaining queries -- commands are
+// split on spaces, without any quoting
+type SQLPrefs struct {
+	Schema    gi.FileName `ext:".sql" desc:"schema dump file with the CREATE TABLE statements for the database -- used for completion of table and column names"`
+	SchemaCmd string      `desc:"command that writes the CREATE TABLE statements of the database to its output, e.g., sqlite3 app.db .schema or pg_dump -s mydb -- used for completion if Schema is not set"`
+	Client    string      `desc:"command line for the database client, which is given the query on its input and should write results as CSV with a header, e.g., sqlite3 -csv -header app.db or psql -d mydb --csv -- used for Explain Query"`
+}
+
+// IsSQLFile returns true if given file is a .sql file
+func IsSQLFile(fname string) bool {
+	return strings.ToLower(filepath.Ext(fname)) == ".sql"
+}
+
+// SQLKeywords are the SQL keywords, which are upper-cased by FormatSQL and
+// offered by completion
+var SQLKeywords = []string{"ADD", "ALL", "ALTER", "ANALYZE", "AND", "AS", "ASC", "BEGIN", "BETWEEN", "BY", "CASCADE", "CASE", "CHECK", "COLUMN", "COMMIT", "CONFLICT", "CONSTRAINT", "CREATE", "CROSS", "DEFAULT", "DELETE", "DESC", "DISTINCT", "DO", "DROP", "ELSE", "END", "EXCEPT", "EXISTS", "EXPLAIN", "FALSE", "FOREIGN", "FROM", "FULL", "GROUP", "HAVING", "IF", "ILIKE", "IN", "INDEX", "INNER", "INSERT", "INTERSECT", "INTO", "IS", "JOIN", "KEY", "LEFT", "LIKE", "LIMIT", "NATURAL", "NOT", "NOTHING", "NULL", "OFFSET", "ON", "OR", "ORDER", "OUTER", "OVER", "PARTITION", "PRIMARY", "QUERY", "RECURSIVE", "REFERENCES", "RETURNING", "RIGHT", "ROLLBACK", "SELECT", "SET", "TABLE", "THEN", "TRANSACTION", "TRUE", "UNION", "UNIQUE", "UPDATE", "USING", "VALUES", "VIEW", "WHEN", "WHERE", "WITH"}
+
+// SQLFuncs are the SQL functions and types that take arguments in
+// parentheses, which are upper-cased by FormatSQL and offered by completion
+var SQLFuncs = []string{"AVG", "BIGINT", "BOOLEAN", "CAST", "CHAR", "COALESCE", "COUNT", "DATE", "DECIMAL", "INT", "INTEGER", "LOWER", "MAX", "MIN", "NULLIF", "NUMERIC", "REAL", "SUM", "TEXT", "TIMESTAMP", "UPPER", "VARCHAR"}
+
+var sqlKeySet, sqlFuncSet map[string]bool
+
+func init() {
+	sqlKeySet = make(map[string]bool, len(SQLKeywords))
+	for _, k := range SQLKeywords {
+		sqlKeySet[k] = true
+	}
+	sqlFuncSet = make(map[string]bool, len(SQLFuncs))
+	for _, k := range SQLFuncs {
+		sqlFuncSet[k] = true
+	}
+}
+
+// sqlTokKind is the kind of an SQL token
+type sqlTokKind int
+
+const (
+	sqlWord sqlTokKind = iota
+	sqlString
+	sqlNumber
+	sqlComment
+	sqlLineComment
+	sqlPunct
+)
+
+// sqlTok is an SQL token
+type sqlTok struct {
+	Kind sqlTokKind
+	Str  string
+}
+
+// sqlTokens splits SQL source into tokens, dropping white space
+func sqlTokens(src string) []sqlTok {
+	var toks []sqlTok
+	rs := []rune(src)
+	n := len(rs)
+	for i := 0; i < n; {
+		r := rs[i]
+		st := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '-' && i+1 < n && rs[i+1] == '-':
+			for i < n && rs[i] != '\n' {
+				i++
+			}
+			toks = append(toks, sqlTok{sqlLineComment, strings.TrimRight(string(rs[st:i]), " \t\r")})
+			continue
+		case r == '/' && i+1 < n && rs[i+1] == '*':
+			i += 2
+			for i < n && !(rs[i] == '*' && i+1 < n && rs[i+1] == '/') {
+				i++
+			}
+			if i < n {
+				i += 2
+			}
+			toks = append(toks, sqlTok{sqlComment, string(rs[st:i])})
+			continue
+		case r == '\'' || r == '"' || r == '`' || r == '[':
+			end := r
+			if r == '[' {
+				end = ']'
+			}
+			i++
+			for i < n {
+				if rs[i] == end {
+					if i+1 < n && rs[i+1] == end && end != ']' { // doubled quote
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+			kind := sqlWord // quoted identifier
+			if r == '\'' {
+				kind = sqlString
+			}
+			toks = append(toks, sqlTok{kind, string(rs[st:i])})
+			continue
+		case unicode.IsLetter(r) || r == '_':
+			for i < n && (unicode.IsLetter(rs[i]) || unicode.IsDigit(rs[i]) || rs[i] == '_' || rs[i] == '$') {
+				i++
+			}
+			toks = append(toks, sqlTok{sqlWord, string(rs[st:i])})
+			continue
+		case unicode.IsDigit(r):
+			for i < n && (unicode.IsDigit(rs[i]) || rs[i] == '.') {
+				i++
+			}
+			toks = append(toks, sqlTok{sqlNumber, string(rs[st:i])})
+			continue
+		}
+		i++
+		if i < n {
+			switch string(rs[st : i+1]) {
+			case "<=", ">=", "<>", "!=", "||", "::":
+				i++
+			}
+		}
+		toks = append(toks, sqlTok{sqlPunct, string(rs[st:i])})
+	}
+	return toks
+}
+
+// sqlClauses are the keywords that start a clause on a new line in
+// FormatSQL
+var sqlClauses = map[string]bool{"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "ORDER": true, "HAVING": true, "LIMIT": true, "OFFSET": true, "UNION": true, "INTERSECT": true, "EXCEPT": true, "INSERT": true, "VALUES": true, "UPDATE": true, "SET": true, "DELETE": true, "RETURNING": true, "JOIN": true, "LEFT": true, "RIGHT": true, "INNER": true, "FULL": true, "CROSS": true, "NATURAL": true}
+
+// sqlTableKeys are the keywords before a table name, which can be followed
+// by a column list in parentheses
+var sqlTableKeys = map[string]bool{"INTO": true, "TABLE": true, "ON": true, "REFERENCES": true, "VIEW": true}
+
+// sqlJoinMods are the keywords that can come before JOIN
+var sqlJoinMods = map[string]bool{"LEFT": true, "RIGHT": true, "INNER": true, "FULL": true, "CROSS": true, "NATURAL": true, "OUTER": true}
+
+// FormatSQL formats the given SQL source: keywords are upper-cased, each
+// clause (SELECT, FROM, WHERE, JOIN, etc) starts on a new line, with the
+// items of a SELECT list and the conditions of a WHERE on their own lines
+// indented by given indent string, and subqueries indented -- comments are
+// kept, and statements are separated by a blank line
+func FormatSQL(src, indent string) string {
+	toks := sqlTokens(src)
+	var sb strings.Builder
+	type paren struct {
+		sub    bool   // is a subquery
+		def    bool   // is the column definitions of a CREATE TABLE
+		clause string // current clause within the parens
+	}
+	stack := []paren{{sub: true}}
+	lvl := 0         // number of open subqueries
+	nl := -1         // pending newline, at this indent level
+	blank := false   // pending blank line
+	first := true    // at start of statement
+	between := false // in BETWEEN x AND y
+	prev := sqlTok{Kind: sqlPunct, Str: "("}
+	prev2 := ""
+	for ti, t := range toks {
+		up := strings.ToUpper(t.Str)
+		isKey := t.Kind == sqlWord && (sqlKeySet[up] || sqlFuncSet[up])
+		if isKey {
+			t.Str = up
+		}
+		top := &stack[len(stack)-1]
+		if isKey && top.sub && sqlClauses[up] && !first && !(up == "JOIN" && sqlJoinMods[strings.ToUpper(prev.Str)]) && !(up == "FROM" && top.clause == "DELETE") && !(up == "SET" && top.clause != "UPDATE") {
+			nl = lvl
+		}
+		if isKey && top.sub && (up == "AND" || up == "OR") && (top.clause == "WHERE" || top.clause == "HAVING") {
+			if up == "AND" && between {
+				between = false
+			} else {
+				nl = lvl + 1
+			}
+		}
+		if t.Kind == sqlPunct && t.Str == ")" && top.def {
+			nl = lvl
+		}
+		space := !first
+		switch {
+		case t.Kind == sqlPunct && (t.Str == "," || t.Str == ";" || t.Str == ")" || t.Str == "." || t.Str == "::"):
+			space = false
+		case t.Kind == sqlPunct && t.Str == "(":
+			pu := strings.ToUpper(prev.Str)
+			if prev.Kind == sqlWord && (sqlFuncSet[pu] || !sqlKeySet[pu] && !sqlTableKeys[prev2] && prev2 != ".") { // function call
+				space = false
+			}
+		case prev.Kind == sqlPunct && (prev.Str == "(" || prev.Str == "." || prev.Str == "::"):
+			space = false
+		}
+		switch {
+		case blank && sb.Len() > 0:
+			sb.WriteString("\n\n")
+		case nl >= 0 && sb.Len() > 0:
+			sb.WriteString("\n" + strings.Repeat(indent, nl))
+		case space && sb.Len() > 0:
+			sb.WriteString(" ")
+		}
+		blank = false
+		nl = -1
+		sb.WriteString(t.Str)
+		first = false
+		switch {
+		case t.Kind == sqlLineComment:
+			nl = lvl
+			if top.clause == "SELECT" || top.clause == "WHERE" {
+				nl = lvl + 1
+			}
+		case isKey && top.sub && sqlClauses[up] && !sqlJoinMods[up]:
+			if !(up == "FROM" && top.clause == "DELETE") && !(up == "SET" && top.clause != "UPDATE") {
+				top.clause = up
+			}
+		case isKey && top.sub && sqlJoinMods[up]:
+			top.clause = "JOIN"
+		case isKey && up == "ON":
+			top.clause = "WHERE" // join conditions break like WHERE
+		case isKey && up == "CREATE":
+			top.clause = up
+		case isKey && up == "TABLE" && top.clause == "CREATE":
+			top.clause = up
+		case isKey && up == "BETWEEN":
+			between = true
+		case t.Kind == sqlPunct && t.Str == "(":
+			sub := false
+			for _, nt := range toks[ti+1:] {
+				if nt.Kind == sqlComment || nt.Kind == sqlLineComment {
+					continue
+				}
+				nu := strings.ToUpper(nt.Str)
+				sub = nu == "SELECT" || nu == "WITH"
+				break
+			}
+			if sub {
+				lvl++
+			}
+			def := top.clause == "TABLE" && len(stack) == 1
+			stack = append(stack, paren{sub: sub, def: def})
+			first = sub // no newline before the first clause
+			if def {
+				nl = lvl + 1
+			}
+		case t.Kind == sqlPunct && t.Str == ")":
+			if len(stack) > 1 {
+				if top.sub {
+					lvl--
+				}
+				stack = stack[:len(stack)-1]
+			}
+		case t.Kind == sqlPunct && t.Str == ",":
+			if top.sub && (top.clause == "SELECT" || top.clause == "SET") || top.def {
+				nl = lvl + 1
+			}
+		case t.Kind == sqlPunct && t.Str == ";":
+			stack = []paren{{sub: true}}
+			lvl = 0
+			blank = true
+			first = true
+		}
+		if isKey && top.sub && (top.clause == "SELECT" || top.clause == "SET") && sqlClauses[up] && !(up == "SELECT" && ti+1 < len(toks) && strings.ToUpper(toks[ti+1].Str) == "DISTINCT") {
+			nl = lvl + 1
+		}
+		if isKey && up == "DISTINCT" && top.clause == "SELECT" {
+			nl = lvl + 1
+		}
+		prev2 = strings.ToUpper(prev.Str)
+		prev = t
+	}
+	out := sb.String()
+	if out != "" {
+		out += "\n"
+	}
+	return out
+}
+
+// SQLTable is a table (or view) in an SQL schema
+type SQLTable struct {
+	Name string   `desc:"name of the table, without any schema qualifier"`
+	Cols []string `desc:"names of the columns"`
+}
+
+// SQLSchema is the set of tables in a database, for completion
+type SQLSchema struct {
+	Tables []SQLTable `desc:"the tables, sorted by name"`
+}
+
+// sqlIdent returns the identifier without any quotes or schema qualifier
+func sqlIdent(s string) string {
+	if di := strings.LastIndex(s, "."); di >= 0 {
+		s = s[di+1:]
+	}
+	return strings.Trim(s, "\"`[]")
+}
+
+// ParseSQLSchema parses the CREATE TABLE and CREATE VIEW statements in the
+// given SQL source (e.g., a schema dump), returning the tables and their
+// columns
+func ParseSQLSchema(src string) *SQLSchema {
+	toks := sqlTokens(src)
+	sch := &SQLSchema{}
+	for i := 0; i < len(toks); i++ {
+		if strings.ToUpper(toks[i].Str) != "CREATE" {
+			continue
+		}
+		j := i + 1
+		for j < len(toks) && toks[j].Kind == sqlWord && !sqlIsObj(toks[j].Str) { // OR REPLACE, TEMP, etc
+			j++
+		}
+		if j >= len(toks) {
+			break
+		}
+		obj := strings.ToUpper(toks[j].Str)
+		if obj != "TABLE" && obj != "VIEW" {
+			continue
+		}
+		j++
+		for j+2 < len(toks) && (strings.ToUpper(toks[j].Str) == "IF" || strings.ToUpper(toks[j].Str) == "NOT" || strings.ToUpper(toks[j].Str) == "EXISTS") {
+			j++
+		}
+		if j >= len(toks) {
+			break
+		}
+		nm := toks[j].Str
+		for j+2 < len(toks) && toks[j+1].Str == "." { // schema.table
+			j += 2
+			nm += "." + toks[j].Str
+		}
+		tb := SQLTable{Name: sqlIdent(nm)}
+		j++
+		if obj == "TABLE" && j < len(toks) && toks[j].Str == "(" {
+			depth := 0
+			colStart := true
+			for j++; j < len(toks); j++ {
+				t := toks[j]
+				switch {
+				case t.Kind == sqlComment || t.Kind == sqlLineComment:
+					continue
+				case t.Str == "(":
+					depth++
+				case t.Str == ")":
+					depth--
+				case t.Str == "," && depth == 0:
+					colStart = true
+					continue
+				case colStart && depth == 0 && t.Kind == sqlWord:
+					switch strings.ToUpper(t.Str) {
+					case "PRIMARY", "FOREIGN", "UNIQUE", "CONSTRAINT", "CHECK", "KEY", "INDEX", "EXCLUDE":
+					default:
+						tb.Cols = append(tb.Cols, sqlIdent(t.Str))
+					}
+				}
+				colStart = false
+				if depth < 0 {
+					break
+				}
+			}
+		}
+		sch.Tables = append(sch.Tables, tb)
+		i = j
+	}
+	sort.Slice(sch.Tables, func(i, j int) bool {
+		return sch.Tables[i].Name < sch.Tables[j].Name
+	})
+	return sch
+}
+
+// sqlIsObj returns true if given word is the kind of object being created
+func sqlIsObj(s string) bool {
+	switch strings.ToUpper(s) {
+	case "TABLE", "VIEW", "INDEX", "TRIGGER", "FUNCTION", "PROCEDURE", "SEQUENCE", "TYPE", "SCHEMA", "DATABASE", "EXTENSION":
+		return true
+	}
+	return false
+}
+
+// LoadSQLSchema loads the schema for the given settings: from the Schema
+// file if set, else from the output of the SchemaCmd run in given directory
+func LoadSQLSchema(sp *SQLPrefs, dir string) (*SQLSchema, error) {
+	switch {
+	case sp.Schema != "":
+		fn := string(sp.Schema)
+		if !filepath.IsAbs(fn) {
+			fn = filepath.Join(dir, fn)
+		}
+		b, err := ioutil.ReadFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		return ParseSQLSchema(string(b)), nil
+	case sp.SchemaCmd != "":
+		args := strings.Fields(sp.SchemaCmd)
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", sp.SchemaCmd, err)
+		}
+		return ParseSQLSchema(string(out)), nil
+	}
+	return &SQLSchema{}, nil
+}
+
+// Table returns the table with given name (case insensitive), or nil
+func (sch *SQLSchema) Table(name string) *SQLTable {
+	name = sqlIdent(name)
+	for i := range sch.Tables {
+		if strings.EqualFold(sch.Tables[i].Name, name) {
+			return &sch.Tables[i]
+		}
+	}
+	return nil
+}
+
+// sqlSeed returns the identifier (with any table qualifier) before the end
+// of given text
+func sqlSeed(text string) string {
+	st := len(text)
+	for st > 0 {
+		c := text[st-1]
+		if !(c == '_' || c == '.' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			break
+		}
+		st--
+	}
+	return text[st:]
+}
+
+// CompleteSQL does completion of SQL keywords, and table and column names
+// from the *SQLSchema given as data -- after table. only the columns of that
+// table are offered
+func CompleteSQL(data interface{}, text string, posLn, posCh int) (md complete.MatchData) {
+	sch, _ := data.(*SQLSchema)
+	if posCh < len(text) {
+		text = text[:posCh]
+	}
+	md.Seed = sqlSeed(text)
+	seed := md.Seed
+	add := func(s string) {
+		if len(s) > len(seed) && strings.HasPrefix(strings.ToLower(s), strings.ToLower(seed)) {
+			md.Matches = append(md.Matches, complete.Completion{Text: s})
+		}
+	}
+	if di := strings.LastIndex(seed, "."); di >= 0 {
+		if sch == nil {
+			return md
+		}
+		qual := seed[:di+1]
+		seed = seed[di+1:]
+		if tb := sch.Table(strings.TrimSuffix(qual, ".")); tb != nil {
+			for _, c := range tb.Cols {
+				if strings.HasPrefix(strings.ToLower(c), strings.ToLower(seed)) {
+					md.Matches = append(md.Matches, complete.Completion{Text: qual + c})
+				}
+			}
+		}
+		return md
+	}
+	if seed == "" {
+		return md
+	}
+	if sch != nil {
+		cols := map[string]bool{}
+		for _, tb := range sch.Tables {
+			add(tb.Name)
+			for _, c := range tb.Cols {
+				cols[c] = true
+			}
+		}
+		cnms := make([]string, 0, len(cols))
+		for c := range cols {
+			cnms = append(cnms, c)
+		}
+		sort.Strings(cnms)
+		for _, c := range cnms {
+			add(c)
+		}
+	}
+	for _, k := range SQLKeywords {
+		add(k)
+	}
+	for _, k := range SQLFuncs {
+		add(k)
+	}
+	return md
+}
+
+// CompleteSQLEdit edits the text after the user chooses from the candidate
+// SQL completions
+func CompleteSQLEdit(data interface{}, text string, cursorPos int, c complete.Completion, seed string) (ed complete.EditData) {
+	return complete.EditWord(text, cursorPos, c.Text, seed)
+}
+
+// SQLFormat formats the selected SQL in the text view, or the whole buffer
+// if there is no selection, using FormatSQL with given indent string
+func (tv *TextView) SQLFormat(indent string) bool {
+	if tv.Buf == nil {
+		return false
+	}
+	if sel := tv.Selection(); sel != nil {
+		tv.Buf.DeleteText(sel.Reg.Start, sel.Reg.End, true, true)
+		tv.Buf.InsertText(sel.Reg.Start, []byte(FormatSQL(string(sel.ToBytes()), indent)), true, true)
+		tv.SelectReset()
+		return true
+	}
+	src := string(tv.Buf.LinesToBytesCopy())
+	ReplaceLines(tv.Buf, 0, tv.Buf.NumLines(), strings.Split(strings.TrimSuffix(FormatSQL(src, indent), "\n"), "\n"))
+	tv.SetCursorShow(giv.TextPos{})
+	return true
+}
+
+// SQLStatementAt returns the SQL statement (up to the ; separators) that
+// contains the given line
+func SQLStatementAt(lns []string, ln int) string {
+	if ln >= len(lns) {
+		return ""
+	}
+	st := ln
+	for st > 0 && !strings.HasSuffix(strings.TrimSpace(lns[st-1]), ";") {
+		st--
+	}
+	ed := ln
+	for ed < len(lns)-1 && !strings.HasSuffix(strings.TrimSpace(lns[ed]), ";") {
+		ed++
+	}
+	return strings.TrimSpace(strings.Join(lns[st:ed+1], "\n"))
+}
+
+// ExplainSQL runs EXPLAIN for given query with the SQL Client, in given
+// directory, returning the plan as the lines of an aligned table if the
+// client writes CSV, else as the plain output lines
+func ExplainSQL(sp *SQLPrefs, query, dir string) ([]string, error) {
+	if sp.Client == "" {
+		return nil, fmt.Errorf("no SQL Client set in the project prefs")
+	}
+	query = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(query), ";"))
+	if query == "" {
+		return nil, fmt.Errorf("no query to explain")
+	}
+	if !strings.HasPrefix(strings.ToUpper(query), "EXPLAIN") {
+		query = "EXPLAIN " + query
+	}
+	args := strings.Fields(sp.Client)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewBufferString(query + ";\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v\n%s", args[0], err, out)
+	}
+	if lns, err := CSVToTable(string(out)); err == nil {
+		return lns, nil
+	}
+	return strings.Split(strings.TrimRight(string(out), "\n"), "\n"), nil
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goki/pi/filecat"
+)
+
+// StatsLargestMax is the number of largest files shown in the Stats
+var StatsLargestMax = 15
+
+// StatsChurnDays is the number of days of VCS history for the churn shown
+// in the Stats
+var StatsChurnDays = 30
+
+// StatsChurnMax is the number of most changed files shown in the Stats
+var StatsChurnMax = 15
+
+// LangStat is the code size of the files of one language in the project
+type LangStat struct {
+	Lang  string `desc:"language, or the extension of files of no supported language"`
+	Files int    `desc:"number of files"`
+	Lines int    `desc:"number of lines in the text files"`
+	Bytes int64  `desc:"total size of the files"`
+}
+
+// FileStat is the size of a file in the project
+type FileStat struct {
+	Path  string `desc:"path of the file"`
+	Lines int    `desc:"number of lines, if it is a text file"`
+	Bytes int64  `desc:"size of the file"`
+}
+
+// ChurnStat is the number of lines changed in a file in the recent VCS
+// history
+type ChurnStat struct {
+	Path    string `desc:"path of the file, relative to the project root"`
+	Added   int    `desc:"lines added"`
+	Deleted int    `desc:"lines deleted"`
+}
+
+// DirSize is the total size of the files in a directory, and in each of its
+// subdirectories, for the treemap
+type DirSize struct {
+	Name  string     `desc:"name of the directory"`
+	Path  string     `desc:"path of the directory"`
+	Bytes int64      `desc:"total size of the files in the directory and its subdirectories"`
+	Files int64      `desc:"size of the files directly in the directory"`
+	Dirs  []*DirSize `desc:"subdirectories, largest first"`
+}
+
+// ProjStats are the statistics of the code size of a project, shown in the
+// Stats tab
+type ProjStats struct {
+	Files   int         `desc:"number of files"`
+	Dirs    int         `desc:"number of directories"`
+	Lines   int         `desc:"number of lines in the text files"`
+	Bytes   int64       `desc:"total size of the files"`
+	Langs   []LangStat  `desc:"code size per language, most lines first"`
+	Largest []FileStat  `desc:"largest files, up to StatsLargestMax"`
+	Churn   []ChurnStat `desc:"most changed files in the last StatsChurnDays days of VCS history, up to StatsChurnMax"`
+	Tree    *DirSize    `desc:"sizes of the directories, for the treemap"`
+}
+
+// Stats computes the statistics of the project files (see WalkFiles) --
+// lines are only counted in text files, and the churn is from git, if the
+// project is in a git repository
+func (pj *Project) Stats() *ProjStats {
+	root := string(pj.ProjRoot)
+	ps := &ProjStats{Tree: &DirSize{Name: filepath.Base(root), Path: root}}
+	dirs := map[string]*DirSize{root: ps.Tree}
+	var dirFor func(dir string) *DirSize
+	dirFor = func(dir string) *DirSize {
+		if ds, ok := dirs[dir]; ok {
+			return ds
+		}
+		ds := &DirSize{Name: filepath.Base(dir), Path: dir}
+		dirs[dir] = ds
+		par := dirFor(filepath.Dir(dir))
+		par.Dirs = append(par.Dirs, ds)
+		ps.Dirs++
+		return ds
+	}
+	langs := map[string]*LangStat{}
+	var fls []FileStat
+	pj.WalkFiles(func(path string, info os.FileInfo) {
+		ext := strings.ToLower(filepath.Ext(info.Name()))
+		lang := ext
+		if sup := filecat.ExtSupported(ext); sup != filecat.NoSupport {
+			lang = sup.String()
+		} else if lang == "" {
+			lang = "(no extension)"
+		}
+		fs := FileStat{Path: path, Bytes: info.Size()}
+		if !IsBinaryFile(path) {
+			if b, err := ioutil.ReadFile(path); err == nil {
+				fs.Lines = bytes.Count(b, []byte("\n"))
+				if len(b) > 0 && b[len(b)-1] != '\n' {
+					fs.Lines++
+				}
+			}
+		}
+		ls, ok := langs[lang]
+		if !ok {
+			ls = &LangStat{Lang: lang}
+			langs[lang] = ls
+		}
+		ls.Files++
+		ls.Lines += fs.Lines
+		ls.Bytes += fs.Bytes
+		ps.Files++
+		ps.Lines += fs.Lines
+		ps.Bytes += fs.Bytes
+		fls = append(fls, fs)
+		dirFor(filepath.Dir(path)).Files += fs.Bytes
+	})
+	for _, ls := range langs {
+		ps.Langs = append(ps.Langs, *ls)
+	}
+	sort.Slice(ps.Langs, func(i, j int) bool {
+		if ps.Langs[i].Lines != ps.Langs[j].Lines {
+			return ps.Langs[i].Lines > ps.Langs[j].Lines
+		}
+		return ps.Langs[i].Bytes > ps.Langs[j].Bytes
+	})
+	sort.Slice(fls, func(i, j int) bool {
+		return fls[i].Bytes > fls[j].Bytes
+	})
+	if len(fls) > StatsLargestMax {
+		fls = fls[:StatsLargestMax]
+	}
+	ps.Largest = fls
+	ps.Tree.Total()
+	ps.Churn, _ = GitChurn(root, StatsChurnDays, StatsChurnMax)
+	return ps
+}
+
+// Total computes the total size of the directory, from the sizes of its
+// files and its subdirectories, and sorts the subdirectories, largest first
+func (ds *DirSize) Total() int64 {
+	ds.Bytes = ds.Files
+	for _, sd := range ds.Dirs {
+		ds.Bytes += sd.Total()
+	}
+	sort.Slice(ds.Dirs, func(i, j int) bool {
+		return ds.Dirs[i].Bytes > ds.Dirs[j].Bytes
+	})
+	return ds.Bytes
+}
+
+// GitChurn returns the files with the most lines changed in the last given
+// number of days of the git history of the repository at root, up to max
+// files, most changed first
+func GitChurn(root string, days, max int) ([]ChurnStat, error) {
+	cmd := exec.Command("git", "log", fmt.Sprintf("--since=%v.days", days), "--numstat", "--relative", "--format=")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	cmap := map[string]*ChurnStat{}
+	for _, ln := range strings.Split(string(out), "\n") {
+		flds := strings.SplitN(ln, "\t", 3)
+		if len(flds) != 3 {
+			continue
+		}
+		add, err1 := strconv.Atoi(flds[0])
+		del, err2 := strconv.Atoi(flds[1])
+		if err1 != nil || err2 != nil { // - for binary files
+			continue
+		}
+		cs, ok := cmap[flds[2]]
+		if !ok {
+			cs = &ChurnStat{Path: flds[2]}
+			cmap[flds[2]] = cs
+		}
+		cs.Added += add
+		cs.Deleted += del
+	}
+	cs := make([]ChurnStat, 0, len(cmap))
+	for _, c := range cmap {
+		cs = append(cs, *c)
+	}
+	sort.Slice(cs, func(i, j int) bool {
+		return cs[i].Added+cs[i].Deleted > cs[j].Added+cs[j].Deleted
+	})
+	if len(cs) > max {
+		cs = cs[:max]
+	}
+	return cs, nil
+}
+
+// SizeString returns the size in bytes in the largest whole unit, e.g., 12.3 KB
+func SizeString(sz int64) string {
+	switch {
+	case sz >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(sz)/(1<<30))
+	case sz >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(sz)/(1<<20))
+	case sz >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(sz)/(1<<10))
+	}
+	return fmt.Sprintf("%v B", sz)
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// StatsView shows the statistics of the project files (see ProjStats):
+// lines of code per language, the largest and most changed files, and a
+// treemap of the sizes of the directories -- refreshed on demand
+type StatsView struct {
+	gi.Layout
+	Gide  Gide       `json:"-" xml:"-" desc:"parent gide project"`
+	Stats *ProjStats `json:"-" xml:"-" desc:"statistics shown"`
+}
+
+var KiT_StatsView = kit.Types.AddType(&StatsView{}, StatsViewProps)
+
+// Config configures the view to show given statistics
+func (sv *StatsView) Config(ge Gide, ps *ProjStats) {
+	sv.Gide = ge
+	sv.Stats = ps
+	sv.Lay = gi.LayoutVert
+	sv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "toolbar")
+	config.Add(gi.KiT_SplitView, "splitview")
+	mods, updt := sv.ConfigChildren(config, false)
+	if !mods {
+		updt = sv.UpdateStart()
+	}
+	sv.ConfigToolbar()
+	sv.ConfigSplitView()
+	sv.StatsLabel().SetText(fmt.Sprintf("%v files, %v lines, %v", ps.Files, ps.Lines, SizeString(ps.Bytes)))
+	lns, mus := ps.Report(ge.ProjPrefs().ProjRoot)
+	tv := sv.TextView()
+	tb := tv.Buf
+	tb.New(0)
+	tb.AppendTextMarkup(bytes.Join(lns, []byte("\n")), bytes.Join(mus, []byte("\n")), false, true)
+	tv.CursorStartDoc()
+	sv.Treemap().SetRoot(ps.Tree)
+	sv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (sv *StatsView) ToolBar() *gi.ToolBar {
+	return sv.ChildByName("toolbar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the splitview of the report and the treemap
+func (sv *StatsView) SplitView() *gi.SplitView {
+	return sv.ChildByName("splitview", 1).(*gi.SplitView)
+}
+
+// StatsLabel returns the label with the totals in the toolbar
+func (sv *StatsView) StatsLabel() *gi.Label {
+	return sv.ToolBar().ChildByName("totals", 1).(*gi.Label)
+}
+
+// TextView returns the view of the report
+func (sv *StatsView) TextView() *giv.TextView {
+	return sv.SplitView().Child(0).Child(0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// Treemap returns the treemap of the directory sizes
+func (sv *StatsView) Treemap() *Treemap {
+	return sv.SplitView().Child(1).Embed(KiT_Treemap).(*Treemap)
+}
+
+// ConfigToolbar adds the refresh action and totals label to the toolbar
+func (sv *StatsView) ConfigToolbar() {
+	tb := sv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "compute the statistics of the project files again"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_StatsView).(*StatsView)
+			svv.Gide.ProjStats()
+		})
+	gi.AddNewLabel(tb, "totals", "")
+}
+
+// ConfigSplitView configures the report text view and the treemap
+func (sv *StatsView) ConfigSplitView() {
+	split := sv.SplitView()
+	if split.HasChildren() {
+		return
+	}
+	split.Dim = gi.Y
+	tly := gi.AddNewLayout(split, "report", gi.LayoutVert)
+	tv := sv.Gide.ConfigOutputTextView(tly)
+	tb := &giv.TextBuf{}
+	tb.InitName(tb, "stats-buf")
+	tv.SetBuf(tb)
+	split.AddNewChild(KiT_Treemap, "treemap")
+	split.SetSplits(.6, .4)
+}
+
+// Report returns the lines of the report of the statistics, and their
+// markup, with links to the files -- paths are shown relative to root
+func (ps *ProjStats) Report(root gi.FileName) (lns, mus [][]byte) {
+	add := func(ln, mu string) {
+		lns = append(lns, []byte(ln))
+		mus = append(mus, []byte(mu))
+	}
+	head := func(hd, cols string) {
+		add("", "")
+		add(hd, "<b>"+html.EscapeString(hd)+"</b>")
+		add(cols, "<b>"+html.EscapeString(cols)+"</b>")
+	}
+	rel := func(fp string) string {
+		if rp, err := filepath.Rel(string(root), fp); err == nil {
+			return rp
+		}
+		return fp
+	}
+	link := func(cols, fp string) {
+		rp := rel(fp)
+		add(cols+rp, html.EscapeString(cols)+fmt.Sprintf(`<a href="file:///%v">%v</a>`, fp, html.EscapeString(rp)))
+	}
+	tot := fmt.Sprintf("Project: %v files, %v directories, %v lines, %v", ps.Files, ps.Dirs, ps.Lines, SizeString(ps.Bytes))
+	add(tot, "<b>"+html.EscapeString(tot)+"</b>")
+	head("Lines of Code", fmt.Sprintf("%-20v %8v %10v %10v", "Language", "Files", "Lines", "Size"))
+	for _, ls := range ps.Langs {
+		ln := fmt.Sprintf("%-20v %8v %10v %10v", ls.Lang, ls.Files, ls.Lines, SizeString(ls.Bytes))
+		add(ln, html.EscapeString(ln))
+	}
+	head("Largest Files", fmt.Sprintf("%10v %10v  %v", "Size", "Lines", "File"))
+	for _, fs := range ps.Largest {
+		link(fmt.Sprintf("%10v %10v  ", SizeString(fs.Bytes), fs.Lines), fs.Path)
+	}
+	if len(ps.Churn) > 0 {
+		head(fmt.Sprintf("Most Changed Files in the last %v days", StatsChurnDays), fmt.Sprintf("%8v %8v  %v", "Added", "Deleted", "File"))
+		for _, cs := range ps.Churn {
+			link(fmt.Sprintf("%8v %8v  ", cs.Added, cs.Deleted), filepath.Join(string(root), filepath.FromSlash(cs.Path)))
+		}
+	}
+	return
+}
+
+// StatsViewProps are style properties for StatsView
+var StatsViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
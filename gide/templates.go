@@ -0,0 +1,420 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// Template is a named template for the initial contents of new files -- the
+// text can contain variables that are filled in when the file is made: see
+// TemplateVars
+type Template struct {
+	Name string `desc:"name of template"`
+	Desc string `desc:"brief description"`
+	Exts string `desc:"file extensions or name endings (space separated, e.g., .h .hpp _test.go) for which this template is used automatically, when none is selected"`
+	Text string `width:"60" desc:"text of the template, with {Var} variables -- see TemplateVars"`
+}
+
+// Label satisfies the Labeler interface
+func (tm Template) Label() string {
+	return tm.Name
+}
+
+// MatchLen returns the length of the longest of the template's Exts that
+// the given file name ends with -- 0 if none
+func (tm *Template) MatchLen(fname string) int {
+	fname = strings.ToLower(filepath.Base(fname))
+	ml := 0
+	for _, e := range strings.Fields(tm.Exts) {
+		if strings.HasSuffix(fname, strings.ToLower(e)) && len(e) > ml {
+			ml = len(e)
+		}
+	}
+	return ml
+}
+
+// Templates is a list of named file templates
+type Templates []Template
+
+var KiT_Templates = kit.Types.AddType(&Templates{}, TemplatesProps)
+
+// TemplateName has an associated ValueView for selecting from the list of
+// available file templates
+type TemplateName string
+
+// AvailTemplates are available file templates.  can be loaded / saved /
+// edited with preferences.  This is set to StdTemplates at startup.
+var AvailTemplates Templates
+
+func init() {
+	AvailTemplates.CopyFrom(StdTemplates)
+}
+
+// TemplateByName returns a named template and index by name -- returns false
+// if not found
+func (lt *Templates) TemplateByName(name TemplateName) (*Template, int, bool) {
+	if name == "" {
+		return nil, -1, false
+	}
+	for i := range *lt {
+		tm := &((*lt)[i])
+		if tm.Name == string(name) {
+			return tm, i, true
+		}
+	}
+	return nil, -1, false
+}
+
+// TemplateForFile returns the template with the longest of its Exts
+// matching the end of the given file name -- nil if none
+func (lt *Templates) TemplateForFile(fname string) *Template {
+	var btm *Template
+	bl := 0
+	for i := range *lt {
+		tm := &((*lt)[i])
+		if ml := tm.MatchLen(fname); ml > bl {
+			btm, bl = tm, ml
+		}
+	}
+	return btm
+}
+
+// Names returns a slice of current names
+func (lt *Templates) Names() []string {
+	nms := make([]string, len(*lt))
+	for i := range *lt {
+		nms[i] = (*lt)[i].Name
+	}
+	return nms
+}
+
+// TemplateVars are the variables that can be used in the text of templates,
+// with a description of each
+var TemplateVars = map[string]string{
+	"{FileName}":      "name of the new file, without the path",
+	"{FileNameNoExt}": "name of the new file, without the path or extension",
+	"{Package}":       "Go package name for the directory of the file -- from other Go files in the directory, or the directory name",
+	"{Guard}":         "C / C++ header guard name based on the file name, e.g., MY_FILE_H",
+	"{Include}":       "#include of the C / C++ header with the same name as the file in its directory, followed by a blank line -- empty if there is none",
+	"{Type}":          "exported Go type name made from the file name, e.g., MyView for my_view.go",
+	"{ProjName}":      "name of the project",
+	"{Author}":        "user name from GoGi preferences",
+	"{Email}":         "user email from GoGi preferences",
+	"{Date}":          "current date, as 2006-01-02",
+	"{Year}":          "current year",
+}
+
+// GoPackageName returns the Go package name for files in the given
+// directory: the package of the first Go file found there (other than
+// tests, unless there are only tests), else a name made from the last
+// element of its import path (see GoImportPath), without any major version
+// suffix or go- prefix, else from the directory name
+func GoPackageName(dir string) string {
+	fls, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	fset := token.NewFileSet()
+	tpkg := ""
+	for _, fl := range fls {
+		af, err := parser.ParseFile(fset, fl, nil, parser.PackageClauseOnly)
+		if err != nil || af.Name == nil {
+			continue
+		}
+		if !strings.HasSuffix(fl, "_test.go") {
+			return af.Name.Name
+		}
+		if tpkg == "" {
+			tpkg = strings.TrimSuffix(af.Name.Name, "_test")
+		}
+	}
+	if tpkg != "" {
+		return tpkg
+	}
+	nm := filepath.Base(dir)
+	if ip := GoImportPath(dir); ip != "" {
+		nm = path.Base(ip)
+		if isMajorVersion(nm) && path.Dir(ip) != "." {
+			nm = path.Base(path.Dir(ip))
+		}
+		nm = strings.TrimPrefix(strings.TrimSuffix(nm, "-go"), "go-")
+	}
+	nm = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, nm)
+	if nm == "" || unicode.IsDigit(rune(nm[0])) {
+		nm = "main"
+	}
+	return nm
+}
+
+// isMajorVersion returns true if the import path element is a major
+// version suffix, e.g., v2
+func isMajorVersion(el string) bool {
+	if len(el) < 2 || el[0] != 'v' {
+		return false
+	}
+	for _, r := range el[1:] {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// HeaderExts are the extensions of C / C++ header files, in the order they
+// are looked for by HeaderInclude
+var HeaderExts = []string{".h", ".hpp", ".hh", ".hxx"}
+
+// HeaderInclude returns an #include line, followed by a blank line, for the
+// C / C++ header with the same name as the given source file in its
+// directory -- "" if there is none
+func HeaderInclude(fpath string) string {
+	base := strings.TrimSuffix(fpath, filepath.Ext(fpath))
+	for _, ext := range HeaderExts {
+		if _, err := os.Stat(base + ext); err == nil {
+			return fmt.Sprintf("#include \"%v\"\n\n", filepath.Base(base+ext))
+		}
+	}
+	return ""
+}
+
+// HeaderGuard returns a C / C++ header guard name for given file name
+func HeaderGuard(fname string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, filepath.Base(fname))
+}
+
+// ExpandTemplate returns the template text with the TemplateVars filled in
+// for a new file at given path, in project with given name
+func ExpandTemplate(text, fpath, projName string) string {
+	fnm := filepath.Base(fpath)
+	now := time.Now()
+	vals := []string{
+		"{FileName}", fnm,
+		"{FileNameNoExt}", strings.TrimSuffix(fnm, filepath.Ext(fnm)),
+		"{Guard}", HeaderGuard(fnm),
+		"{Type}", GoTypeName(fnm),
+		"{ProjName}", projName,
+		"{Author}", gi.Prefs.User.Name,
+		"{Email}", gi.Prefs.User.Email,
+		"{Date}", now.Format("2006-01-02"),
+		"{Year}", now.Format("2006"),
+	}
+	if strings.Contains(text, "{Package}") {
+		vals = append(vals, "{Package}", GoPackageName(filepath.Dir(fpath)))
+	}
+	if strings.Contains(text, "{Include}") {
+		vals = append(vals, "{Include}", HeaderInclude(fpath))
+	}
+	return strings.NewReplacer(vals...).Replace(text)
+}
+
+// NewFileFromTemplate makes a new file at given path, with the contents of
+// the named template, or if the name is empty, the template for the file's
+// extension, if any -- the file must not already exist.  Files starting with
+// a #! shebang line are made executable.
+func NewFileFromTemplate(fpath string, tmpl TemplateName, projName string) error {
+	var tm *Template
+	if tmpl != "" {
+		var ok bool
+		if tm, _, ok = AvailTemplates.TemplateByName(tmpl); !ok {
+			return fmt.Errorf("template named: %v not found", tmpl)
+		}
+	} else {
+		tm = AvailTemplates.TemplateForFile(fpath)
+	}
+	perm := os.FileMode(0666)
+	if tm != nil && strings.HasPrefix(tm.Text, "#!") {
+		perm = 0777
+	}
+	f, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if tm != nil {
+		_, err = f.WriteString(ExpandTemplate(tm.Text, fpath, projName))
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// PrefsTemplatesFileName is the name of the preferences file in App prefs
+// directory for saving / loading the default AvailTemplates
+var PrefsTemplatesFileName = "templates_prefs.json"
+
+// OpenJSON opens templates from a JSON-formatted file.
+func (lt *Templates) OpenJSON(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return err
+	}
+	*lt = make(Templates, 0, 10) // reset
+	return json.Unmarshal(b, lt)
+}
+
+// SaveJSON saves templates to a JSON-formatted file.
+func (lt *Templates) SaveJSON(filename gi.FileName) error {
+	b, err := json.MarshalIndent(lt, "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		gi.PromptDialog(nil, gi.DlgOpts{Title: "Could not Save to File", Prompt: err.Error()}, true, false, nil, nil)
+		log.Println(err)
+	}
+	return err
+}
+
+// OpenPrefs opens Templates from App standard prefs directory, using PrefsTemplatesFileName
+func (lt *Templates) OpenPrefs() error {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, PrefsTemplatesFileName)
+	AvailTemplatesChanged = false
+	return lt.OpenJSON(gi.FileName(pnm))
+}
+
+// SavePrefs saves Templates to App standard prefs directory, using PrefsTemplatesFileName
+func (lt *Templates) SavePrefs() error {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, PrefsTemplatesFileName)
+	AvailTemplatesChanged = false
+	return lt.SaveJSON(gi.FileName(pnm))
+}
+
+// CopyFrom copies templates from given other list
+func (lt *Templates) CopyFrom(cp Templates) {
+	*lt = make(Templates, len(cp))
+	copy(*lt, cp)
+}
+
+// RevertToStd reverts the templates to the standard compiled-in set
+func (lt *Templates) RevertToStd() {
+	lt.CopyFrom(StdTemplates)
+	AvailTemplatesChanged = true
+}
+
+// AvailTemplatesChanged is used to update toolbars via following menu, toolbar
+// props update methods -- not accurate if editing any other list but works for
+// now..
+var AvailTemplatesChanged = false
+
+// TemplatesProps define the ToolBar and MenuBar for TableView of Templates
+var TemplatesProps = ki.Props{
+	"MainMenu": ki.PropSlice{
+		{"AppMenu", ki.BlankProp{}},
+		{"File", ki.PropSlice{
+			{"OpenPrefs", ki.Props{}},
+			{"SavePrefs", ki.Props{
+				"shortcut": "Command+S",
+				"updtfunc": giv.ActionUpdateFunc(func(tmi interface{}, act *gi.Action) {
+					act.SetActiveState(AvailTemplatesChanged && tmi.(*Templates) == &AvailTemplates)
+				}),
+			}},
+			{"sep-file", ki.BlankProp{}},
+			{"OpenJSON", ki.Props{
+				"label":    "Open from file",
+				"desc":     "You can save and open file templates to / from files to share, experiment, transfer, etc",
+				"shortcut": "Command+O",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+			{"SaveJSON", ki.Props{
+				"label": "Save to file",
+				"desc":  "You can save and open file templates to / from files to share, experiment, transfer, etc",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+			{"RevertToStd", ki.Props{
+				"desc":    "This reverts the templates to using the StdTemplates that are compiled into the program and have all the standard templates",
+				"confirm": true,
+			}},
+		}},
+		{"Edit", "Copy Cut Paste Dupe"},
+		{"Window", "Windows"},
+	},
+	"ToolBar": ki.PropSlice{
+		{"SavePrefs", ki.Props{
+			"desc": "saves Templates to App standard prefs directory, in file templates_prefs.json, which will be loaded automatically at startup)",
+			"icon": "file-save",
+			"updtfunc": giv.ActionUpdateFunc(func(tmi interface{}, act *gi.Action) {
+				act.SetActiveState(AvailTemplatesChanged && tmi.(*Templates) == &AvailTemplates)
+			}),
+		}},
+		{"sep-file", ki.BlankProp{}},
+		{"OpenJSON", ki.Props{
+			"label": "Open from file",
+			"icon":  "file-open",
+			"desc":  "You can save and open file templates to / from files to share, experiment, transfer, etc",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
+		{"SaveJSON", ki.Props{
+			"label": "Save to file",
+			"icon":  "file-save",
+			"desc":  "You can save and open file templates to / from files to share, experiment, transfer, etc",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
+		{"sep-std", ki.BlankProp{}},
+		{"RevertToStd", ki.Props{
+			"icon":    "update",
+			"desc":    "This reverts the templates to using the StdTemplates that are compiled into the program and have all the standard templates",
+			"confirm": true,
+		}},
+	},
+}
+
+// StdTemplates is the original compiled-in set of standard file templates.
+var StdTemplates = Templates{
+	{"Go", "Go file with package clause", ".go", "package {Package}\n\n"},
+	{"Go Test", "Go test file", "_test.go", "package {Package}\n\nimport \"testing\"\n\n"},
+	{"C Header", "C / C++ header with include guard", ".h .hh .hpp .hxx", "#ifndef {Guard}\n#define {Guard}\n\n\n\n#endif // {Guard}\n"},
+	{"C Source", "C / C++ source including its header, if any", ".c .cc .cpp .cxx", "{Include}"},
+	{"HTML", "HTML page skeleton", ".html .htm", "<!DOCTYPE html>\n<html>\n<head>\n  <meta charset=\"utf-8\">\n  <title>{FileNameNoExt}</title>\n</head>\n<body>\n\n</body>\n</html>\n"},
+	{"Shell", "shell script with shebang", ".sh", "#!/bin/sh\n\n"},
+	{"Bash", "bash script with shebang", ".bash", "#!/usr/bin/env bash\n\nset -euo pipefail\n\n"},
+	{"Python", "python script with shebang", ".py", "#!/usr/bin/env python3\n\n"},
+	{"Markdown", "Markdown document with title", ".md", "# {FileNameNoExt}\n\n"},
+	{"GoKi Widget", "GoKi widget type named {Type}, with its KiT type registration, Props and Config", "", GoKiWidgetText},
+}
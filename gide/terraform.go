@@ -0,0 +1,190 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/lexers"
+)
+
+// TerraformExts are the extensions of Terraform / HCL files
+var TerraformExts = []string{".tf", ".tfvars", ".hcl"}
+
+func init() {
+	// chroma's Terraform lexer only matches .tf files -- it also does fine
+	// on the other HCL files
+	if lx := lexers.Get("terraform"); lx != nil {
+		cfg := lx.Config()
+		for _, ext := range TerraformExts[1:] {
+			cfg.Filenames = append(cfg.Filenames, "*"+ext)
+		}
+	}
+}
+
+// IsTerraformFile returns true if given file is a Terraform / HCL file
+func IsTerraformFile(fname string) bool {
+	ext := strings.ToLower(filepath.Ext(fname))
+	for _, te := range TerraformExts {
+		if ext == te {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	tfBlockRe = regexp.MustCompile(`^\s*([A-Za-z_][\w-]*)((?:\s+(?:"[^"]*"|[A-Za-z_][\w-]*))*)\s*\{\s*$`)
+	tfLabelRe = regexp.MustCompile(`"[^"]*"|[A-Za-z_][\w-]*`)
+	tfHereRe  = regexp.MustCompile(`<<-?\s*([A-Za-z_]\w*)\s*$`)
+)
+
+// TerraformBlockTitle returns the outline title for a block of given type
+// and labels, using the Terraform reference syntax, e.g., aws_instance.web
+// for a resource, var.region for a variable
+func TerraformBlockTitle(typ string, labels []string) string {
+	switch {
+	case typ == "resource" && len(labels) > 0:
+		return strings.Join(labels, ".")
+	case typ == "variable" && len(labels) > 0:
+		return "var." + strings.Join(labels, ".")
+	case len(labels) > 0:
+		return typ + "." + strings.Join(labels, ".")
+	}
+	return typ
+}
+
+// TerraformBlocks returns the top-level blocks (resources, modules,
+// variables, etc) and the blocks nested directly within them, of the given
+// lines of a Terraform / HCL file, as outline headings -- the End of each
+// is the line after its closing brace
+func TerraformBlocks(lns []string) []Heading {
+	var hds []Heading
+	var open []int // indexes of open headings in hds, or -1 for other braces
+	here := ""     // heredoc end marker, if in a heredoc
+	for i, ln := range lns {
+		if here != "" {
+			if strings.TrimSpace(ln) == here {
+				here = ""
+			}
+			continue
+		}
+		if m := tfBlockRe.FindStringSubmatch(ln); m != nil && len(open) < 2 {
+			var lbls []string
+			for _, l := range tfLabelRe.FindAllString(m[2], -1) {
+				lbls = append(lbls, strings.Trim(l, `"`))
+			}
+			open = append(open, len(hds))
+			hds = append(hds, Heading{Level: len(open), Title: TerraformBlockTitle(m[1], lbls), Ln: i, End: len(lns)})
+			continue
+		}
+		instr := false
+		for ci := 0; ci < len(ln); ci++ {
+			c := ln[ci]
+			if instr {
+				if c == '\\' {
+					ci++
+				} else if c == '"' {
+					instr = false
+				}
+				continue
+			}
+			switch c {
+			case '"':
+				instr = true
+			case '#':
+				ci = len(ln)
+			case '/':
+				if ci+1 < len(ln) && ln[ci+1] == '/' {
+					ci = len(ln)
+				}
+			case '{':
+				open = append(open, -1)
+			case '}':
+				if n := len(open); n > 0 {
+					if hi := open[n-1]; hi >= 0 {
+						hds[hi].End = i + 1
+					}
+					open = open[:n-1]
+				}
+			}
+		}
+		if m := tfHereRe.FindStringSubmatch(ln); m != nil {
+			here = m[1]
+		}
+	}
+	return hds
+}
+
+// TerraformDiag is a diagnostic (error or warning) from terraform
+type TerraformDiag struct {
+	Severity string `desc:"error or warning"`
+	Summary  string `desc:"summary of the problem"`
+	Detail   string `desc:"details of the problem"`
+	File     string `desc:"full path of the file with the problem, if any"`
+	Ln       int    `desc:"line of the problem (1-based)"`
+	Col      int    `desc:"column of the problem (1-based)"`
+}
+
+// ParseTerraformValidate parses the output of terraform validate -json run
+// in given directory, returning the diagnostics and whether the
+// configuration is valid
+func ParseTerraformValidate(out []byte, dir string) ([]TerraformDiag, bool, error) {
+	var res struct {
+		Valid       bool `json:"valid"`
+		Diagnostics []struct {
+			Severity string `json:"severity"`
+			Summary  string `json:"summary"`
+			Detail   string `json:"detail"`
+			Range    *struct {
+				Filename string `json:"filename"`
+				Start    struct {
+					Line   int `json:"line"`
+					Column int `json:"column"`
+				} `json:"start"`
+			} `json:"range"`
+		} `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(out, &res); err != nil {
+		return nil, false, fmt.Errorf("could not parse terraform validate output: %v", err)
+	}
+	dgs := make([]TerraformDiag, len(res.Diagnostics))
+	for i, d := range res.Diagnostics {
+		dg := TerraformDiag{Severity: d.Severity, Summary: d.Summary, Detail: d.Detail}
+		if d.Range != nil {
+			dg.File = d.Range.Filename
+			if dg.File != "" && !filepath.IsAbs(dg.File) {
+				dg.File = filepath.Join(dir, dg.File)
+			}
+			dg.Ln = d.Range.Start.Line
+			dg.Col = d.Range.Start.Column
+		}
+		dgs[i] = dg
+	}
+	return dgs, res.Valid, nil
+}
+
+var tfOnLineRe = regexp.MustCompile(`^(\s*(?:│\s*)?on )(\S+) line (\d+)`)
+
+// MarkupTerraformDiag returns the line of terraform (e.g., plan) output
+// with a link to the file and line of a diagnostic location, as in "on
+// main.tf line 12", and true -- false if there is no location
+func MarkupTerraformDiag(out []byte) ([]byte, bool) {
+	m := tfOnLineRe.FindSubmatchIndex(out)
+	if m == nil {
+		return out, false
+	}
+	fn := string(out[m[4]:m[5]])
+	ln, _ := strconv.Atoi(string(out[m[6]:m[7]]))
+	lnk := fmt.Sprintf(`<a href="file:///%v#L%dC1">%s</a>`, fn, ln, out[m[4]:m[7]])
+	mu := append([]byte{}, out[:m[4]]...)
+	mu = append(mu, lnk...)
+	return append(mu, out[m[7]:]...), true
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TestPattern is how the test files of source files with given extensions
+// are named: the name of the source file without its extension, between
+// the Prefix and Suffix, with the same extension -- e.g., foo_test.go for
+// foo.go -- in the same directory, or one of the Dirs
+type TestPattern struct {
+	Exts   string `desc:"extensions of the source files (space separated)"`
+	Prefix string `desc:"prefix of the name of the test file"`
+	Suffix string `desc:"suffix of the name of the test file, before the extension"`
+	Dirs   string `desc:"sub-directories of the directory of the source file where the test file can also be (space separated), e.g., tests"`
+	Trees  string `desc:"pairs of source:test directory names that are swapped in the path of the source file for the test file (space separated), e.g., main:test for src/main/java and src/test/java"`
+}
+
+// TestPatterns are the patterns of the names of test files, in the order
+// they are tried -- for a missing test file, the first pattern for the
+// extension names the one to make
+var TestPatterns = []TestPattern{
+	{".go", "", "_test", "", ""},
+	{".py", "test_", "", "tests test", ""},
+	{".py", "", "_test", "", ""},
+	{".js .jsx .ts .tsx .mjs", "", ".test", "__tests__", ""},
+	{".js .jsx .ts .tsx .mjs", "", ".spec", "", ""},
+	{".c .cc .cpp .cxx", "", "_test", "test tests", ""},
+	{".java .kt", "", "Test", "", "main:test"},
+	{".rb", "", "_spec", "spec", ""},
+	{".rb", "", "_test", "test", ""},
+}
+
+// HasExt returns true if the file name has one of the extensions of the
+// pattern
+func (tp *TestPattern) HasExt(fname string) bool {
+	ext := filepath.Ext(fname)
+	for _, e := range strings.Fields(tp.Exts) {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTest returns true if the file name is that of a test file per the
+// pattern, and then the name of its source file
+func (tp *TestPattern) IsTest(fname string) (string, bool) {
+	if !tp.HasExt(fname) || tp.Prefix+tp.Suffix == "" {
+		return "", false
+	}
+	ext := filepath.Ext(fname)
+	nm := strings.TrimSuffix(fname, ext)
+	if !strings.HasPrefix(nm, tp.Prefix) || !strings.HasSuffix(nm, tp.Suffix) {
+		return "", false
+	}
+	nm = strings.TrimSuffix(strings.TrimPrefix(nm, tp.Prefix), tp.Suffix)
+	if nm == "" {
+		return "", false
+	}
+	return nm + ext, true
+}
+
+// TestName returns the name of the test file of the source file with given
+// name, per the pattern
+func (tp *TestPattern) TestName(fname string) string {
+	ext := filepath.Ext(fname)
+	return tp.Prefix + strings.TrimSuffix(fname, ext) + tp.Suffix + ext
+}
+
+// swapTree returns the directory with the first element named from
+// replaced by to, and true, or false if it has no such element
+func swapTree(dir, from, to string) (string, bool) {
+	els := strings.Split(filepath.ToSlash(dir), "/")
+	for i := len(els) - 1; i >= 0; i-- {
+		if els[i] == from {
+			els[i] = to
+			return filepath.FromSlash(strings.Join(els, "/")), true
+		}
+	}
+	return "", false
+}
+
+// testDirs returns the directories where the test files of source files in
+// given directory can be, per the pattern, or if toSrc, where the source
+// files of test files in it can be -- the given one first
+func (tp *TestPattern) testDirs(dir string, toSrc bool) []string {
+	dirs := []string{dir}
+	for _, sd := range strings.Fields(tp.Dirs) {
+		if !toSrc {
+			dirs = append(dirs, filepath.Join(dir, sd))
+		} else if filepath.Base(dir) == sd {
+			dirs = append(dirs, filepath.Dir(dir))
+		}
+	}
+	for _, tr := range strings.Fields(tp.Trees) {
+		ft := strings.SplitN(tr, ":", 2)
+		if len(ft) != 2 {
+			continue
+		}
+		from, to := ft[0], ft[1]
+		if toSrc {
+			from, to = to, from
+		}
+		if sd, ok := swapTree(dir, from, to); ok {
+			dirs = append(dirs, sd)
+		}
+	}
+	return dirs
+}
+
+// TestSourceFiles returns the paths of the files that can be the test file
+// of the file at given path, per TestPatterns, in the order they are tried
+// -- or if it is a test file, of its source file, and then isTest is true
+func TestSourceFiles(fpath string) (paths []string, isTest bool) {
+	dir, fname := filepath.Split(fpath)
+	dir = filepath.Clean(dir)
+	for i := range TestPatterns {
+		tp := &TestPatterns[i]
+		if src, ok := tp.IsTest(fname); ok {
+			isTest = true
+			for _, d := range tp.testDirs(dir, true) {
+				paths = append(paths, filepath.Join(d, src))
+			}
+		}
+	}
+	if isTest {
+		return paths, true
+	}
+	for i := range TestPatterns {
+		tp := &TestPatterns[i]
+		if !tp.HasExt(fname) {
+			continue
+		}
+		tnm := tp.TestName(fname)
+		for _, d := range tp.testDirs(dir, false) {
+			paths = append(paths, filepath.Join(d, tnm))
+		}
+	}
+	return paths, false
+}
+
+// TestSourcePair returns the path of the test file of the file at given
+// path, or if it is a test file, of its source file (see TestSourceFiles):
+// the first of them that exists, else the first one, with exists false --
+// "" if there are none for its extension
+func TestSourcePair(fpath string) (pair string, exists, isTest bool) {
+	paths, isTest := TestSourceFiles(fpath)
+	if len(paths) == 0 {
+		return "", false, isTest
+	}
+	for _, p := range paths {
+		if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+			return p, true, isTest
+		}
+	}
+	return paths[0], false, isTest
+}
@@ -2,9 +2,13 @@ package gide
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
@@ -12,6 +16,36 @@ import (
 
 type TextView struct {
 	giv.TextView
+	SigHelp     SigHelp          `json:"-" xml:"-" view:"-" desc:"signature help state"`
+	DocTimer    *time.Timer      `json:"-" xml:"-" view:"-" desc:"timer for the delayed documentation lookup of a hover -- see DocHoverEvent"`
+	ProseMode   bool             `json:"-" xml:"-" desc:"prose (writing) mode is on for this view -- see SetProseMode"`
+	Minimap     *Minimap         `json:"-" xml:"-" view:"-" desc:"minimap for this view, if shown"`
+	Rulers      []int            `json:"-" xml:"-" desc:"columns at which vertical ruler lines are drawn -- see SetRulers"`
+	LongLines   bool             `json:"-" xml:"-" desc:"mark the part of lines that extends past the last ruler"`
+	Whitespace  bool             `json:"-" xml:"-" desc:"show spaces, tabs and line endings as faint glyphs -- see SetWhitespace"`
+	WhitePrefs  *WhitespacePrefs `json:"-" xml:"-" view:"-" desc:"glyphs and color for showing whitespace"`
+	Rainbow     bool             `json:"-" xml:"-" desc:"color brackets by their nesting depth -- see RenderBrackets"`
+	Brackets    []Bracket        `json:"-" xml:"-" view:"-" desc:"brackets of the buffer outside comments and strings, as of the last scan -- see ScanBufBrackets"`
+	ExpandReg   giv.TextRegion   `json:"-" xml:"-" view:"-" desc:"selection made by the last ExpandSel or ShrinkSel"`
+	ExpandStack []giv.TextRegion `json:"-" xml:"-" view:"-" desc:"selections before each ExpandSel, for ShrinkSel"`
+	HiOccurs    bool             `json:"-" xml:"-" desc:"underline the other occurrences of the identifier at the cursor -- see UpdateOccurs"`
+	Occurs      []giv.TextRegion `json:"-" xml:"-" view:"-" desc:"occurrences of the identifier at the cursor, as of the last UpdateOccurs"`
+	VcsGutter   bool             `json:"-" xml:"-" desc:"mark the lines added, modified and deleted relative to the VCS HEAD version of the file in the gutter -- see UpdateVcsGutter"`
+	Vcs         VcsGutterState   `json:"-" xml:"-" view:"-" desc:"state of the markers of changed lines"`
+	Jump        JumpState        `json:"-" xml:"-" view:"-" desc:"state of Jump to Visible, while its labels are shown -- see StartJump"`
+	Injects     InjectLines      `json:"-" xml:"-" view:"-" desc:"highlighting of the embedded languages, as of the last InjectMarkup"`
+	Edit        EditTrack        `json:"-" xml:"-" view:"-" desc:"when the buffer was last edited in this view -- see TrackEdit"`
+	Pinned      bool             `json:"-" xml:"-" desc:"the file in this view is pinned to it: other files are opened in the other view"`
+	Sync        ScrollSync       `json:"-" xml:"-" view:"-" desc:"synchronized scrolling with another view -- see SetSyncScroll"`
+	SpellInline bool             `json:"-" xml:"-" desc:"underline unknown words in comments and strings, or in all the text of prose files -- see SpellInlineCheck"`
+	InlineSpell SpellInlineState `json:"-" xml:"-" view:"-" desc:"state of the underlining of unknown words"`
+	Abbrevs     bool             `json:"-" xml:"-" desc:"expand abbreviations when typed -- see AbbrevKeyInput"`
+	PairPrefs   *PairPrefs       `json:"-" xml:"-" view:"-" desc:"auto-pairing of brackets and quotes -- see PairKeyInput"`
+	TagEdit     bool             `json:"-" xml:"-" desc:"in HTML and XML files, insert the end tag when a start tag is typed, and rename the matching tag when the name of a tag is edited -- see TagCloseKeyInput and TagRenameKeyInput"`
+	ColorSwatch bool             `json:"-" xml:"-" desc:"show the colors of color literals in CSS, HTML, XML and Go strings under them and in the gutter -- see RenderColorSwatches"`
+	DataCheck   bool             `json:"-" xml:"-" desc:"check the syntax of JSON, YAML and TOML files when typing pauses -- see CheckData"`
+	TagRename   TagRenameState   `json:"-" xml:"-" view:"-" desc:"state of the renaming of the matching tag"`
+	DataChk     DataCheckState   `json:"-" xml:"-" view:"-" desc:"state of the checking of a data file"`
 }
 
 var KiT_TextView = kit.Types.AddType(&TextView{}, TextViewProps)
@@ -48,8 +82,142 @@ var TextViewProps = ki.Props{
 	},
 }
 
-// MakeContextMenu builds the textview context menu
+// ConnectEvents2D connects the standard TextView events, plus our own
+// pre- and post-processing of keys and clicks, and documentation hover
+func (tv *TextView) ConnectEvents2D() {
+	tv.KeyInputBeforeEvent()
+	tv.TextView.ConnectEvents2D()
+	tv.KeyInputAfterEvent()
+	tv.MouseInputAfterEvent()
+	tv.DocHoverEvent()
+	tv.InjectFocusEvent()
+}
+
+// Render2D renders the view, with its embedded languages highlighted, its
+// whitespace glyphs, rainbow brackets, rulers, occurrences of the identifier
+// at the cursor, color swatches, markers of changed lines and Jump to
+// Visible labels, and then updates its minimap, if any, and scrolls the view
+// it is synchronized with, if it has been scrolled
+func (tv *TextView) Render2D() {
+	tv.InjectMarkup()
+	tv.TextView.Render2D()
+	if tv.Whitespace && tv.NLines > 0 {
+		st := tv.FirstVisibleLine(0)
+		tv.RenderWhitespace(st, tv.LastVisibleLine(st))
+	}
+	tv.RenderBrackets()
+	tv.RenderRulers()
+	tv.RenderOccurs()
+	tv.RenderColorSwatches()
+	tv.RenderVcsGutter()
+	tv.RenderJump()
+	if tv.Minimap != nil {
+		tv.Minimap.ViewRendered()
+	}
+	tv.SyncScroll()
+}
+
+// KeyInputBeforeEvent connects to key events at high priority, so they
+// are processed before the regular key processing
+func (tv *TextView) KeyInputBeforeEvent() {
+	tv.ConnectEvent(oswin.KeyChordEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		tvv := recv.Embed(KiT_TextView).(*TextView)
+		kt := d.(*key.ChordEvent)
+		tvv.KeyInputBefore(kt)
+	})
+}
+
+// KeyInputBefore does any processing needed before a key is processed by
+// the regular key processing, which it can preempt
+func (tv *TextView) KeyInputBefore(kt *key.ChordEvent) {
+	tv.TagRenameKeyBefore(kt)
+	tv.PairKeyInput(kt)
+}
+
+// KeyInputAfterEvent connects to key events at the lowest priority, so they
+// are processed after all the regular key processing, and even if processed
+func (tv *TextView) KeyInputAfterEvent() {
+	tv.ConnectEvent(oswin.KeyChordEvent, gi.LowRawPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		tvv := recv.Embed(KiT_TextView).(*TextView)
+		kt := d.(*key.ChordEvent)
+		tvv.KeyInputAfter(kt)
+	})
+}
+
+// KeyInputAfter does any processing needed after a key has been processed
+// by the regular key processing
+func (tv *TextView) KeyInputAfter(kt *key.ChordEvent) {
+	tv.TrackEdit()
+	tv.AbbrevKeyInput(kt)
+	tv.SigHelpKeyInput(kt)
+	tv.ProseKeyInput(kt)
+	tv.PasteImageKeyInput(kt)
+	tv.UndoPairKeyInput(kt)
+	tv.TagRenameKeyInput(kt)
+	tv.TagCloseKeyInput(kt)
+	tv.KillRegisterKeyInput(kt)
+	tv.UpdateInjections()
+	tv.MatchBrackets()
+	tv.UpdateOccurs()
+	tv.UpdateVcsGutter()
+	tv.UpdateSpellInline()
+	tv.UpdateDataCheck()
+	tv.UpdateOverlays()
+}
+
+// MouseInputAfterEvent connects to mouse events at the lowest priority, so
+// they are processed after the regular mouse processing
+func (tv *TextView) MouseInputAfterEvent() {
+	tv.ConnectEvent(oswin.MouseEvent, gi.LowRawPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		tvv := recv.Embed(KiT_TextView).(*TextView)
+		me := d.(*mouse.Event)
+		tvv.MouseInputAfter(me)
+	})
+}
+
+// MouseInputAfter does any processing needed after a mouse event has been
+// processed by the regular mouse processing: matching brackets at the
+// cursor, and clicks in the gutter
+func (tv *TextView) MouseInputAfter(me *mouse.Event) {
+	tv.BracketMouseInput(me)
+	tv.VcsGutterMouseInput(me)
+	tv.ColorSwatchMouseInput(me)
+}
+
+// UpdateOverlays redraws the whitespace glyphs, rainbow brackets, rulers,
+// occurrences of the identifier at the cursor, color swatches, markers of
+// changed lines and Jump to Visible labels over the visible lines after they
+// have been re-rendered outside of Render2D, e.g., while typing, and uploads
+// them to the window
+func (tv *TextView) UpdateOverlays() {
+	swatch, _ := tv.colorSwatchOn()
+	if (len(tv.Rulers) == 0 && !tv.Whitespace && !tv.Rainbow && len(tv.Occurs) == 0 && len(tv.VcsChangesCopy()) == 0 && !tv.Jump.Active && !swatch) || tv.NLines == 0 || tv.Viewport == nil || tv.Viewport.Win == nil {
+		return
+	}
+	if !tv.This().(gi.Node2D).IsVisible() {
+		return
+	}
+	vp := tv.Viewport
+	updt := vp.Win.UpdateStart()
+	if tv.Whitespace {
+		st := tv.FirstVisibleLine(0)
+		tv.RenderWhitespace(st, tv.LastVisibleLine(st))
+	}
+	tv.RenderBrackets()
+	tv.RenderRulers()
+	tv.RenderOccurs()
+	tv.RenderColorSwatches()
+	tv.RenderVcsGutter()
+	tv.RenderJump()
+	vp.Win.UploadVpRegion(vp, tv.VpBBox, tv.WinBBox)
+	vp.Win.UpdateEnd(updt)
+}
+
+// MakeContextMenu builds the textview context menu, starting with the
+// suggestions for the word at the cursor if it is underlined as unknown
 func (tv *TextView) MakeContextMenu(m *gi.Menu) {
+	tv.SpellContextMenu(m)
+	tv.ColorContextMenu(m)
 	ac := m.AddAction(gi.ActOpts{Label: "Copy", ShortcutKey: gi.KeyFunCopy},
 		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			txf := recv.Embed(KiT_TextView).(*TextView)
@@ -84,6 +252,14 @@ func (tv *TextView) MakeContextMenu(m *gi.Menu) {
 	}
 }
 
+// Declaration goes to the declaration of the symbol at the cursor, using
+// the completion provider for the language of the buffer
 func (tv *TextView) Declaration() {
+	if ge, ok := ParentGide(tv.This()); ok {
+		if fname, reg, ok := LookupDecl(ge, tv.Buf, tv.CursorPos); ok {
+			ge.OpenFileAtRegion(fname, reg)
+			return
+		}
+	}
 	fmt.Println("Go to Declaration: not yet implemented")
 }
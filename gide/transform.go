@@ -0,0 +1,200 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/key"
+)
+
+// TransformNames are the names of the transformations of the selected text
+// in the Edit / Transform menu -- see TransformText
+var TransformNames = []string{
+	"Sort Lines", "Sort Lines Descending", "Sort Lines Numeric", "Sort Lines Numeric Descending",
+	"Unique Lines", "Reverse Lines", "Join Lines", gi.MenuTextSeparator,
+	"Upper Case", "Lower Case", "Title Case", gi.MenuTextSeparator,
+	"Base64 Encode", "Base64 Decode", "URL Encode", "URL Decode",
+}
+
+// numPrefixRe matches a number at the start of a line, for numeric sorting
+var numPrefixRe = regexp.MustCompile(`^\s*[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?`)
+
+// lineNum returns the number at the start of the line, and false if none
+func lineNum(ln string) (float64, bool) {
+	ns := numPrefixRe.FindString(ln)
+	if ns == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(ns), 64)
+	return n, err == nil
+}
+
+// SortLinesNumeric sorts the lines by the number at their start, with
+// lines that do not start with a number first, in their original order
+func SortLinesNumeric(lns []string, desc bool) {
+	sort.SliceStable(lns, func(i, j int) bool {
+		ni, iok := lineNum(lns[i])
+		nj, jok := lineNum(lns[j])
+		if !iok || !jok {
+			return !iok && jok
+		}
+		if desc {
+			return ni > nj
+		}
+		return ni < nj
+	})
+}
+
+// UniqueLines returns the lines without the duplicates of earlier lines
+func UniqueLines(lns []string) []string {
+	has := map[string]bool{}
+	var ulns []string
+	for _, l := range lns {
+		if has[l] {
+			continue
+		}
+		has[l] = true
+		ulns = append(ulns, l)
+	}
+	return ulns
+}
+
+// JoinLines joins the lines into one, separated by a space, keeping the
+// indentation of the first line only, and skipping blank lines
+func JoinLines(lns []string) string {
+	var jl []string
+	for i, l := range lns {
+		if i > 0 {
+			l = strings.TrimLeftFunc(l, unicode.IsSpace)
+		}
+		l = strings.TrimRightFunc(l, unicode.IsSpace)
+		if l == "" && i > 0 {
+			continue
+		}
+		jl = append(jl, l)
+	}
+	return strings.Join(jl, " ")
+}
+
+// TransformText returns the text transformed by the transformation of
+// given name (see TransformNames) -- line transformations keep a final
+// line ending in place -- returns an error if the name is unknown, or the
+// text cannot be decoded
+func TransformText(name, txt string) (string, error) {
+	body := strings.TrimSuffix(txt, "\n")
+	end := txt[len(body):]
+	lns := strings.Split(body, "\n")
+	switch name {
+	case "Sort Lines":
+		sort.Strings(lns)
+	case "Sort Lines Descending":
+		sort.Sort(sort.Reverse(sort.StringSlice(lns)))
+	case "Sort Lines Numeric":
+		SortLinesNumeric(lns, false)
+	case "Sort Lines Numeric Descending":
+		SortLinesNumeric(lns, true)
+	case "Unique Lines":
+		lns = UniqueLines(lns)
+	case "Reverse Lines":
+		for i, j := 0, len(lns)-1; i < j; i, j = i+1, j-1 {
+			lns[i], lns[j] = lns[j], lns[i]
+		}
+	case "Join Lines":
+		return JoinLines(lns) + end, nil
+	case "Upper Case":
+		return strings.ToUpper(txt), nil
+	case "Lower Case":
+		return strings.ToLower(txt), nil
+	case "Title Case":
+		return strings.Title(strings.ToLower(txt)), nil
+	case "Base64 Encode":
+		return base64.StdEncoding.EncodeToString([]byte(txt)), nil
+	case "Base64 Decode":
+		b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(txt), ""))
+		return string(b), err
+	case "URL Encode":
+		return url.QueryEscape(txt), nil
+	case "URL Decode":
+		return url.QueryUnescape(txt)
+	default:
+		return txt, fmt.Errorf("unknown transform: %v", name)
+	}
+	return strings.Join(lns, "\n") + end, nil
+}
+
+// undoPairs are the edits made by ReplaceRegion, which are undone and redone
+// together, as one operation: true for the delete, which is first, false for
+// the insert
+var undoPairs = map[*giv.TextBufEdit]bool{}
+
+// ReplaceRegion replaces the region of the buffer with given text, as one
+// operation for Undo and Redo in a gide TextView -- returns the region of
+// the new text
+func ReplaceRegion(tb *giv.TextBuf, reg giv.TextRegion, txt []byte) giv.TextRegion {
+	dtbe := tb.DeleteText(reg.Start, reg.End, true, true)
+	itbe := tb.InsertText(reg.Start, txt, true, true)
+	if itbe == nil {
+		return giv.NewTextRegion(reg.Start.Ln, reg.Start.Ch, reg.Start.Ln, reg.Start.Ch)
+	}
+	if dtbe != nil && !tb.Opts.EmacsUndo {
+		undoPairs[dtbe] = true
+		undoPairs[itbe] = false
+	}
+	return itbe.Reg
+}
+
+// UndoPairKeyInput completes the Undo or Redo of the edits of a
+// ReplaceRegion after the first one of them has been undone or redone
+func (tv *TextView) UndoPairKeyInput(kt *key.ChordEvent) {
+	tb := tv.Buf
+	if tb == nil || len(undoPairs) == 0 {
+		return
+	}
+	switch gi.KeyFun(kt.Chord()) {
+	case gi.KeyFunUndo:
+		if tb.UndoPos < len(tb.Undos) {
+			if first, has := undoPairs[tb.Undos[tb.UndoPos]]; has && !first {
+				tv.Undo()
+			}
+		}
+	case gi.KeyFunRedo:
+		if tb.UndoPos > 0 && tb.UndoPos < len(tb.Undos) {
+			if first, has := undoPairs[tb.Undos[tb.UndoPos-1]]; has && first {
+				tv.Redo()
+			}
+		}
+	}
+}
+
+// TransformSel applies the transformation of given name (see TransformText)
+// to the selected text, as one operation for Undo, leaving the result
+// selected
+func (tv *TextView) TransformSel(name string) error {
+	sel := tv.Selection()
+	if sel == nil {
+		return fmt.Errorf("select the text to transform")
+	}
+	txt := string(sel.ToBytes())
+	ntxt, err := TransformText(name, txt)
+	if err != nil {
+		return err
+	}
+	if ntxt == txt {
+		return nil
+	}
+	reg := ReplaceRegion(tv.Buf, sel.Reg, []byte(ntxt))
+	tv.SelectRegionShow(reg)
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// TreemapDepth is the number of levels of directories shown in the treemap
+var TreemapDepth = 4
+
+// TreemapColors are the colors of the directories in the treemap, by depth
+var TreemapColors = []color.RGBA{
+	{0x4e, 0x79, 0xa7, 0xff},
+	{0x59, 0xa1, 0x4f, 0xff},
+	{0xed, 0xc9, 0x48, 0xff},
+	{0xe1, 0x57, 0x59, 0xff},
+	{0xb0, 0x7a, 0xa1, 0xff},
+}
+
+// TreemapRect is the rectangle of a directory in the treemap
+type TreemapRect struct {
+	Rect  image.Rectangle `desc:"rectangle, relative to the treemap"`
+	Dir   *DirSize        `desc:"directory"`
+	Depth int             `desc:"depth of the directory below the root"`
+}
+
+// Treemap shows the sizes of the directories of a project as nested
+// rectangles, with areas proportional to the sizes, down to TreemapDepth
+// levels -- click on a directory to show its path and size in the status bar
+type Treemap struct {
+	gi.WidgetBase
+	RootDir *DirSize      `json:"-" xml:"-" desc:"root directory shown"`
+	Rects   []TreemapRect `json:"-" xml:"-" view:"-" desc:"rectangles of the directories, as last rendered, deepest last"`
+}
+
+var KiT_Treemap = kit.Types.AddType(&Treemap{}, TreemapProps)
+
+var TreemapProps = ki.Props{
+	"min-height":       units.NewValue(12, units.Em),
+	"max-width":        -1,
+	"max-height":       -1,
+	"margin":           units.NewValue(2, units.Px),
+	"background-color": &gi.Prefs.Colors.Background,
+}
+
+// SetRoot sets the root directory shown, and renders it
+func (tm *Treemap) SetRoot(root *DirSize) {
+	tm.RootDir = root
+	tm.UpdateSig()
+}
+
+// LayoutRects lays out the rectangles of the directories in a rectangle of
+// given size, slicing each directory among its subdirectories in proportion
+// to their sizes, alternating horizontally and vertically by depth
+func (tm *Treemap) LayoutRects(sz image.Point) {
+	tm.Rects = tm.Rects[:0]
+	if tm.RootDir == nil || tm.RootDir.Bytes == 0 {
+		return
+	}
+	tm.layoutDir(tm.RootDir, image.Rectangle{Max: sz}, 0)
+}
+
+// layoutDir lays out the directory in the rectangle, and its
+// subdirectories within it
+func (tm *Treemap) layoutDir(ds *DirSize, r image.Rectangle, depth int) {
+	tm.Rects = append(tm.Rects, TreemapRect{Rect: r, Dir: ds, Depth: depth})
+	if depth >= TreemapDepth || ds.Bytes == 0 {
+		return
+	}
+	in := r.Inset(3)
+	if in.Dx() < 4 || in.Dy() < 4 {
+		return
+	}
+	horiz := depth%2 == 0
+	ext := in.Dy()
+	if horiz {
+		ext = in.Dx()
+	}
+	pos := 0
+	var sum int64
+	for _, sd := range ds.Dirs {
+		sum += sd.Bytes
+		nps := int(float64(ext) * float64(sum) / float64(ds.Bytes))
+		if nps-pos < 2 {
+			continue // too small to show -- added to the next one
+		}
+		sr := image.Rect(in.Min.X, in.Min.Y+pos, in.Max.X, in.Min.Y+nps)
+		if horiz {
+			sr = image.Rect(in.Min.X+pos, in.Min.Y, in.Min.X+nps, in.Max.Y)
+		}
+		tm.layoutDir(sd, sr, depth+1)
+		pos = nps
+	}
+}
+
+// DirAt returns the deepest directory at given point, relative to the
+// treemap, or nil if none
+func (tm *Treemap) DirAt(pt image.Point) *DirSize {
+	for i := len(tm.Rects) - 1; i >= 0; i-- {
+		if pt.In(tm.Rects[i].Rect) {
+			return tm.Rects[i].Dir
+		}
+	}
+	return nil
+}
+
+// Render2D renders the rectangles of the directories, with a darker border
+func (tm *Treemap) Render2D() {
+	if tm.FullReRenderIfNeeded() {
+		return
+	}
+	if tm.PushBounds() {
+		tm.This().(gi.Node2D).ConnectEvents2D()
+		r := tm.VpBBox
+		img := tm.Viewport.Pixels
+		draw.Draw(img, r, image.NewUniform(&tm.Sty.Font.BgColor.Color), image.ZP, draw.Src)
+		tm.LayoutRects(r.Size())
+		for _, tr := range tm.Rects {
+			c := TreemapColors[tr.Depth%len(TreemapColors)]
+			br := tr.Rect.Add(r.Min).Intersect(r)
+			draw.Draw(img, br, image.NewUniform(color.RGBA{c.R / 2, c.G / 2, c.B / 2, 0xff}), image.ZP, draw.Src)
+			draw.Draw(img, br.Inset(1), image.NewUniform(c), image.ZP, draw.Src)
+		}
+		tm.PopBounds()
+	} else {
+		tm.DisconnectAllEvents(gi.RegPri)
+	}
+}
+
+// ConnectEvents2D connects the mouse click to show the directory clicked on
+// in the status bar
+func (tm *Treemap) ConnectEvents2D() {
+	tm.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		tmv := recv.Embed(KiT_Treemap).(*Treemap)
+		if me.Button != mouse.Left || me.Action != mouse.Press {
+			return
+		}
+		me.SetProcessed()
+		ds := tmv.DirAt(me.Where.Sub(tmv.VpBBox.Min))
+		if ds == nil || tmv.RootDir == nil {
+			return
+		}
+		if ge, ok := ParentGide(tmv.This()); ok {
+			ge.SetStatus(fmt.Sprintf("%v: %v (%.1f%%)", ds.Path, SizeString(ds.Bytes), 100*float64(ds.Bytes)/float64(tmv.RootDir.Bytes)))
+		}
+	})
+}
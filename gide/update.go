@@ -0,0 +1,272 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goki/ki/kit"
+)
+
+// UpdateChannels are the release channels checked for gide updates
+type UpdateChannels int32
+
+const (
+	// UpdateStable only offers full releases
+	UpdateStable UpdateChannels = iota
+
+	// UpdateDev also offers pre-releases (e.g., v0.6.0-beta.1), for trying
+	// out new features before they are released
+	UpdateDev
+
+	// UpdateChannelsN is the number of update channels
+	UpdateChannelsN
+)
+
+//go:generate stringer -type=UpdateChannels
+
+var KiT_UpdateChannels = kit.Enums.AddEnumAltLower(UpdateChannelsN, kit.NotBitFlag, nil, "Update")
+
+// MarshalJSON encodes
+func (ev UpdateChannels) MarshalJSON() ([]byte, error) { return kit.EnumMarshalJSON(ev) }
+
+// UnmarshalJSON decodes
+func (ev *UpdateChannels) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// UpdatePrefs are the settings for checking for new gide releases
+type UpdatePrefs struct {
+	Check     bool           `desc:"check for a new gide release when a project is opened, at most once a day -- off by default, as it contacts github.com -- Check For Updates in the Help menu always checks"`
+	Channel   UpdateChannels `desc:"release channel: stable only offers full releases, dev also offers pre-releases"`
+	Skip      string         `desc:"version that was skipped when offered -- it is not offered again by the automatic check"`
+	LastCheck time.Time      `view:"-" desc:"time of the last automatic check"`
+}
+
+// UpdateReleasesURL is the url of the gide releases, in the github api
+var UpdateReleasesURL = "https://api.github.com/repos/goki/gide/releases"
+
+// UpdateModule is the package installed by InstallUpdate
+var UpdateModule = "github.com/goki/gide/cmd/gide"
+
+// UpdateCheckInterval is the minimum time between automatic update checks
+var UpdateCheckInterval = 24 * time.Hour
+
+// UpdateAsset is a file attached to a release, e.g., a binary archive
+type UpdateAsset struct {
+	Name string `json:"name" desc:"file name"`
+	URL  string `json:"browser_download_url" desc:"download url"`
+}
+
+// UpdateRelease is a gide release, as returned by the github api
+type UpdateRelease struct {
+	Version    string        `json:"tag_name" desc:"version tag, e.g., v0.5.11"`
+	Name       string        `json:"name" desc:"release title"`
+	Notes      string        `json:"body" desc:"release notes, in markdown"`
+	URL        string        `json:"html_url" desc:"url of the release page"`
+	Prerelease bool          `json:"prerelease" desc:"this is a pre-release, only offered in the dev channel"`
+	Draft      bool          `json:"draft" desc:"this is a draft, never offered"`
+	Published  time.Time     `json:"published_at" desc:"when the release was published"`
+	Assets     []UpdateAsset `json:"assets" desc:"files attached to the release"`
+}
+
+// UpdateDue returns true if the automatic update check is on and has not
+// been done within the UpdateCheckInterval
+func (up *UpdatePrefs) UpdateDue() bool {
+	return up.Check && time.Since(up.LastCheck) >= UpdateCheckInterval
+}
+
+// CompareVersions compares two semantic versions (with optional v prefix),
+// returning -1, 0 or 1 if a is older, the same or newer than b -- a
+// pre-release (e.g., -beta.1) is older than the release itself
+func CompareVersions(a, b string) int {
+	ma := SemverRe.FindStringSubmatch(a)
+	mb := SemverRe.FindStringSubmatch(b)
+	if ma == nil || mb == nil {
+		return strings.Compare(a, b)
+	}
+	for i := 2; i <= 4; i++ {
+		na, _ := strconv.Atoi(ma[i])
+		nb, _ := strconv.Atoi(mb[i])
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	pa := strings.TrimPrefix(strings.SplitN(a[len(ma[0]):], "+", 2)[0], "-")
+	pb := strings.TrimPrefix(strings.SplitN(b[len(mb[0]):], "+", 2)[0], "-")
+	switch {
+	case pa == pb:
+		return 0
+	case pa == "":
+		return 1
+	case pb == "":
+		return -1
+	}
+	return comparePrerelease(pa, pb)
+}
+
+// comparePrerelease compares two semver pre-release versions, e.g., rc.9
+// and rc.10, as CompareVersions -- per semver, the dot-separated identifiers
+// are compared in turn: numbers numerically, and lower than others, which
+// are compared as strings -- with one having more identifiers newer if the
+// others are the same
+func comparePrerelease(a, b string) int {
+	fa := strings.Split(a, ".")
+	fb := strings.Split(b, ".")
+	for i := 0; i < len(fa) && i < len(fb); i++ {
+		na, erra := strconv.ParseUint(fa[i], 10, 64)
+		nb, errb := strconv.ParseUint(fb[i], 10, 64)
+		switch {
+		case erra == nil && errb == nil:
+			if na < nb {
+				return -1
+			}
+			if na > nb {
+				return 1
+			}
+		case erra == nil:
+			return -1
+		case errb == nil:
+			return 1
+		default:
+			if c := strings.Compare(fa[i], fb[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(fa) < len(fb):
+		return -1
+	case len(fa) > len(fb):
+		return 1
+	}
+	return 0
+}
+
+// FetchReleases returns the gide releases, newest first
+func FetchReleases() ([]UpdateRelease, error) {
+	req, err := http.NewRequest("GET", UpdateReleasesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "gide/"+Version)
+	cl := &http.Client{Timeout: 20 * time.Second}
+	resp, err := cl.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("checking for updates failed: %v", resp.Status)
+	}
+	var rels []UpdateRelease
+	if err := json.NewDecoder(resp.Body).Decode(&rels); err != nil {
+		return nil, err
+	}
+	return rels, nil
+}
+
+// LatestRelease returns the newest of the releases in given channel, or
+// nil if there are none
+func LatestRelease(rels []UpdateRelease, ch UpdateChannels) *UpdateRelease {
+	var lr *UpdateRelease
+	for i := range rels {
+		rl := &rels[i]
+		if rl.Draft || (rl.Prerelease && ch != UpdateDev) || SemverRe.FindString(rl.Version) == "" {
+			continue
+		}
+		if lr == nil || CompareVersions(rl.Version, lr.Version) > 0 {
+			lr = rl
+		}
+	}
+	return lr
+}
+
+// CheckUpdate returns the newest release in given channel if it is newer
+// than the running Version, or nil if gide is up to date
+func CheckUpdate(ch UpdateChannels) (*UpdateRelease, error) {
+	rels, err := FetchReleases()
+	if err != nil {
+		return nil, err
+	}
+	lr := LatestRelease(rels, ch)
+	if lr == nil || CompareVersions(lr.Version, Version) <= 0 {
+		return nil, nil
+	}
+	return lr, nil
+}
+
+// AssetURL returns the download url of the release file for this os and
+// architecture, or the release page if there is none
+func (rl *UpdateRelease) AssetURL() string {
+	arch := runtime.GOARCH
+	for _, as := range rl.Assets {
+		nm := strings.ToLower(as.Name)
+		if strings.Contains(nm, runtime.GOOS) && (strings.Contains(nm, arch) || (arch == "amd64" && strings.Contains(nm, "x86_64"))) {
+			return as.URL
+		}
+	}
+	return rl.URL
+}
+
+// NotesText returns the text and markup showing the release and its notes,
+// with markdown headings in bold
+func (rl *UpdateRelease) NotesText() ([]byte, []byte) {
+	hdr := fmt.Sprintf("gide %v (you have %v), published %v", rl.Version, Version, rl.Published.Format("2006-01-02"))
+	if rl.Name != "" && rl.Name != rl.Version {
+		hdr += ": " + rl.Name
+	}
+	lns := []string{hdr, rl.URL, ""}
+	mus := []string{"<b>" + html.EscapeString(hdr) + "</b>", fmt.Sprintf(`<a href="%v">%v</a>`, html.EscapeString(rl.URL), html.EscapeString(rl.URL)), ""}
+	for _, ln := range strings.Split(strings.Replace(rl.Notes, "\r\n", "\n", -1), "\n") {
+		lns = append(lns, ln)
+		if strings.HasPrefix(ln, "#") {
+			mus = append(mus, "<b>"+html.EscapeString(strings.TrimLeft(ln, "# "))+"</b>")
+		} else {
+			mus = append(mus, html.EscapeString(ln))
+		}
+	}
+	return []byte(strings.Join(lns, "\n") + "\n"), []byte(strings.Join(mus, "\n") + "\n")
+}
+
+// InstallUpdate installs given version of gide with the go tool, in the go
+// bin directory -- go install is used, falling back on go get for older go
+// versions -- returns the output of the go tool
+func InstallUpdate(vers string) ([]byte, error) {
+	pkg := UpdateModule + "@" + vers
+	out, err := runGoUpdate("install", pkg)
+	if err == nil {
+		return out, nil
+	}
+	if gout, gerr := runGoUpdate("get", pkg); gerr == nil {
+		return gout, nil
+	}
+	return out, err
+}
+
+// runGoUpdate runs the go tool with given args in module mode, outside of
+// any module, returning its combined output
+func runGoUpdate(args ...string) ([]byte, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = os.TempDir()
+	cmd.Env = append(os.Environ(), "GO111MODULE=on")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return out.Bytes(), err
+}
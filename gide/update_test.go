@@ -0,0 +1,30 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	vers := []struct {
+		a, b string
+		cmp  int
+	}{
+		{"v0.5.11", "v0.5.9", 1},
+		{"v0.5.11", "0.5.11", 0},
+		{"v1.0.0-rc.1", "v1.0.0", -1},
+		{"v1.0.0-rc.10", "v1.0.0-rc.9", 1},
+		{"v1.0.0-rc.9", "v1.0.0-rc.10", -1},
+		{"v1.0.0-alpha", "v1.0.0-alpha.1", -1},
+		{"v1.0.0-alpha.1", "v1.0.0-alpha.beta", -1},
+		{"v1.0.0-alpha.beta", "v1.0.0-beta", -1},
+		{"v1.0.0-beta.11", "v1.0.0-beta.2", 1},
+		{"v1.0.0-rc.1+build.5", "v1.0.0-rc.1", 0},
+	}
+	for _, v := range vers {
+		if cmp := CompareVersions(v.a, v.b); cmp != v.cmp {
+			t.Errorf("CompareVersions(%v, %v) error: should have been: %v  was: %v\n", v.a, v.b, v.cmp, cmp)
+		}
+	}
+}
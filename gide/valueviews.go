@@ -373,6 +373,12 @@ func CmdsView(pt *Commands) {
 	title.SetStretchMaxWidth()
 	title.SetProp("white-space", gi.WhiteSpaceNormal) // wrap
 
+	probs := mfr.AddNewChild(gi.KiT_Label, "probs").(*gi.Label)
+	probs.SetProp("width", units.NewValue(30, units.Ch)) // need for wrap
+	probs.SetStretchMaxWidth()
+	probs.SetProp("white-space", gi.WhiteSpaceNormal) // wrap
+	probs.SetText(CmdsProblemsLabel(pt))
+
 	tv := mfr.AddNewChild(giv.KiT_TableView, "tv").(*giv.TableView)
 	tv.Viewport = vp
 	tv.SetSlice(pt)
@@ -382,6 +388,9 @@ func CmdsView(pt *Commands) {
 	CustomCmdsChanged = false
 	tv.ViewSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		CustomCmdsChanged = true
+		updt := probs.UpdateStart()
+		probs.SetText(CmdsProblemsLabel(pt))
+		probs.UpdateEnd(updt)
 	})
 
 	mmen := win.MainMenu
@@ -652,6 +661,226 @@ func (vv *SplitValueView) Activate(vp *gi.Viewport2D, dlgRecv ki.Ki, dlgFunc ki.
 
 }
 
+// TemplatesView opens a view of a file templates table
+func TemplatesView(pt *Templates) {
+	winm := "gide-templates"
+	width := 800
+	height := 800
+	win, recyc := gi.RecycleMainWindow(pt, winm, "Gide File Templates", width, height)
+	if recyc {
+		return
+	}
+
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+
+	mfr := win.SetMainFrame()
+	mfr.Lay = gi.LayoutVert
+
+	title := mfr.AddNewChild(gi.KiT_Label, "title").(*gi.Label)
+	title.SetText("Available File Templates: used for the initial contents of new files, chosen by extension or by name -- text can contain variables: {FileName}, {FileNameNoExt}, {Package}, {Guard}, {ProjName}, {Author}, {Email}, {Date}, {Year}")
+	title.SetProp("width", units.NewValue(30, units.Ch)) // need for wrap
+	title.SetStretchMaxWidth()
+	title.SetProp("white-space", gi.WhiteSpaceNormal) // wrap
+
+	tv := mfr.AddNewChild(giv.KiT_TableView, "tv").(*giv.TableView)
+	tv.Viewport = vp
+	tv.SetSlice(pt)
+	tv.SetStretchMaxWidth()
+	tv.SetStretchMaxHeight()
+
+	AvailTemplatesChanged = false
+	tv.ViewSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		AvailTemplatesChanged = true
+	})
+
+	mmen := win.MainMenu
+	giv.MainMenuView(pt, win, mmen)
+
+	inClosePrompt := false
+	win.OSWin.SetCloseReqFunc(func(w oswin.Window) {
+		if !AvailTemplatesChanged || pt != &AvailTemplates { // only for main avail map..
+			win.Close()
+			return
+		}
+		if inClosePrompt {
+			return
+		}
+		inClosePrompt = true
+		gi.ChoiceDialog(vp, gi.DlgOpts{Title: "Save Templates Before Closing?",
+			Prompt: "Do you want to save any changes to custom templates file before closing, or Cancel the close and do a Save to a different file?"},
+			[]string{"Save and Close", "Discard and Close", "Cancel"},
+			win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				switch sig {
+				case 0:
+					pt.SavePrefs()
+					fmt.Printf("Preferences Saved to %v\n", PrefsTemplatesFileName)
+					win.Close()
+				case 1:
+					pt.OpenPrefs() // revert
+					win.Close()
+				case 2:
+					inClosePrompt = false
+					// default is to do nothing, i.e., cancel
+				}
+			})
+	})
+
+	win.MainMenuUpdated()
+
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+}
+
+// AbbrevsView opens a view of an abbreviations table
+func AbbrevsView(pt *Abbrevs) {
+	winm := "gide-abbrevs"
+	width := 800
+	height := 800
+	win, recyc := gi.RecycleMainWindow(pt, winm, "Gide Abbreviations", width, height)
+	if recyc {
+		return
+	}
+
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+
+	mfr := win.SetMainFrame()
+	mfr.Lay = gi.LayoutVert
+
+	title := mfr.AddNewChild(gi.KiT_Label, "title").(*gi.Label)
+	title.SetText("Available Abbreviations: expanded to their text when typed as a word followed by a space, punctuation or enter, in files of their languages (all if none) -- lower case abbreviations are also expanded when typed with an upper case first letter -- Undo reverts an expansion")
+	title.SetProp("width", units.NewValue(30, units.Ch)) // need for wrap
+	title.SetStretchMaxWidth()
+	title.SetProp("white-space", gi.WhiteSpaceNormal) // wrap
+
+	tv := mfr.AddNewChild(giv.KiT_TableView, "tv").(*giv.TableView)
+	tv.Viewport = vp
+	tv.SetSlice(pt)
+	tv.SetStretchMaxWidth()
+	tv.SetStretchMaxHeight()
+
+	AvailAbbrevsChanged = false
+	tv.ViewSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		AvailAbbrevsChanged = true
+	})
+
+	mmen := win.MainMenu
+	giv.MainMenuView(pt, win, mmen)
+
+	inClosePrompt := false
+	win.OSWin.SetCloseReqFunc(func(w oswin.Window) {
+		if !AvailAbbrevsChanged || pt != &AvailAbbrevs { // only for main avail map..
+			win.Close()
+			return
+		}
+		if inClosePrompt {
+			return
+		}
+		inClosePrompt = true
+		gi.ChoiceDialog(vp, gi.DlgOpts{Title: "Save Abbreviations Before Closing?",
+			Prompt: "Do you want to save any changes to custom abbreviations file before closing, or Cancel the close and do a Save to a different file?"},
+			[]string{"Save and Close", "Discard and Close", "Cancel"},
+			win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				switch sig {
+				case 0:
+					pt.SavePrefs()
+					fmt.Printf("Preferences Saved to %v\n", PrefsAbbrevsFileName)
+					win.Close()
+				case 1:
+					pt.OpenPrefs() // revert
+					win.Close()
+				case 2:
+					inClosePrompt = false
+					// default is to do nothing, i.e., cancel
+				}
+			})
+	})
+
+	win.MainMenuUpdated()
+
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  TemplateValueView
+
+// ValueView registers TemplateValueView as the viewer of TemplateName
+func (kn TemplateName) ValueView() giv.ValueView {
+	vv := TemplateValueView{}
+	vv.Init(&vv)
+	return &vv
+}
+
+// TemplateValueView presents an action for displaying an TemplateName and selecting
+type TemplateValueView struct {
+	giv.ValueViewBase
+}
+
+var KiT_TemplateValueView = kit.Types.AddType(&TemplateValueView{}, nil)
+
+func (vv *TemplateValueView) WidgetType() reflect.Type {
+	vv.WidgetTyp = gi.KiT_Action
+	return vv.WidgetTyp
+}
+
+func (vv *TemplateValueView) UpdateWidget() {
+	if vv.Widget == nil {
+		return
+	}
+	ac := vv.Widget.(*gi.Action)
+	txt := kit.ToString(vv.Value.Interface())
+	if txt == "" {
+		txt = "(by extension)"
+	}
+	ac.SetText(txt)
+}
+
+func (vv *TemplateValueView) ConfigWidget(widg gi.Node2D) {
+	vv.Widget = widg
+	ac := vv.Widget.(*gi.Action)
+	ac.SetProp("border-radius", units.NewValue(4, units.Px))
+	ac.ActionSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		vvv, _ := recv.Embed(KiT_TemplateValueView).(*TemplateValueView)
+		ac := vvv.Widget.(*gi.Action)
+		vvv.Activate(ac.Viewport, nil, nil)
+	})
+	vv.UpdateWidget()
+}
+
+func (vv *TemplateValueView) HasAction() bool {
+	return true
+}
+
+func (vv *TemplateValueView) Activate(vp *gi.Viewport2D, dlgRecv ki.Ki, dlgFunc ki.RecvFunc) {
+	if vv.IsInactive() {
+		return
+	}
+	cur := kit.ToString(vv.Value.Interface())
+	curRow := -1
+	if cur != "" {
+		_, curRow, _ = AvailTemplates.TemplateByName(TemplateName(cur))
+	}
+	desc, _ := vv.Tag("desc")
+	giv.TableViewSelectDialog(vp, &AvailTemplates, giv.DlgOpts{Title: "Select a File Template", Prompt: desc}, curRow, nil,
+		vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.DialogAccepted) {
+				ddlg, _ := send.(*gi.Dialog)
+				si := giv.TableViewSelectDialogValue(ddlg)
+				if si >= 0 {
+					pt := AvailTemplates[si]
+					vv.SetValue(pt.Name)
+					vv.UpdateWidget()
+				}
+			}
+			if dlgRecv != nil && dlgFunc != nil {
+				dlgFunc(dlgRecv, send, sig, data)
+			}
+		})
+
+}
+
 //////////////////////////////////////////////////////////////////////////////////////
 //  RegistersView
 
@@ -0,0 +1,308 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ki"
+)
+
+// VcsAddedColor, VcsModifiedColor and VcsDeletedColor are the colors of the
+// markers in the gutter of lines added, modified and deleted relative to the
+// VCS HEAD version of the file
+var (
+	VcsAddedColor    = gi.Color{0x4c, 0xaf, 0x50, 0xff}
+	VcsModifiedColor = gi.Color{0x42, 0x85, 0xf4, 0xff}
+	VcsDeletedColor  = gi.Color{0xe5, 0x39, 0x35, 0xff}
+)
+
+// VcsGutterWidth is the width in pixels of the markers of changed lines, at
+// the left of the line numbers
+var VcsGutterWidth = 3
+
+// VcsDiffWaitMSec is the number of milliseconds after the last key press
+// that the buffer is diffed against the VCS HEAD version
+var VcsDiffWaitMSec = 750
+
+// VcsHeadMaxAge is how long the HEAD version of a file is used before it is
+// read again, e.g., in case of a commit
+var VcsHeadMaxAge = 10 * time.Second
+
+// VcsGutterMaxLines is the maximum number of lines of a buffer for which the
+// changed lines are marked
+var VcsGutterMaxLines = 20000
+
+// VcsChange is a range of lines of a buffer that differs from the VCS HEAD
+// version of its file
+type VcsChange struct {
+	Tag byte     `desc:"kind of change, as in TextDiffs: 'i' for added lines, 'r' for modified lines, 'd' for deleted lines"`
+	St  int      `desc:"first line of the change in the buffer"`
+	Ed  int      `desc:"line after the last line of the change in the buffer -- same as St for deleted lines, which were just before St"`
+	Old []string `desc:"lines of the HEAD version that were modified or deleted"`
+}
+
+// Color returns the color of the marker of the change
+func (vc *VcsChange) Color() gi.Color {
+	switch vc.Tag {
+	case 'i':
+		return VcsAddedColor
+	case 'd':
+		return VcsDeletedColor
+	}
+	return VcsModifiedColor
+}
+
+// Has returns true if the marker of the change is at given line -- deleted
+// lines are marked at the line after them
+func (vc *VcsChange) Has(ln int) bool {
+	if vc.Tag == 'd' {
+		return ln == vc.St
+	}
+	return ln >= vc.St && ln < vc.Ed
+}
+
+// VcsChanges returns the changes of the lines of the current text relative
+// to the HEAD text, given the diffs from head to current
+func VcsChanges(diffs giv.TextDiffs, head []string) []VcsChange {
+	var chs []VcsChange
+	for _, df := range diffs {
+		switch df.Tag {
+		case 'i':
+			chs = append(chs, VcsChange{Tag: 'i', St: df.J1, Ed: df.J2})
+		case 'd', 'r':
+			old := make([]string, df.I2-df.I1)
+			copy(old, head[df.I1:df.I2])
+			chs = append(chs, VcsChange{Tag: df.Tag, St: df.J1, Ed: df.J2, Old: old})
+		}
+	}
+	return chs
+}
+
+// VcsHeadText returns the text of the file in the VCS HEAD, using git, or
+// an error if it is not in a repository or not committed
+func VcsHeadText(fpath string) ([]byte, error) {
+	cmd := exec.Command("git", "show", "HEAD:./"+filepath.Base(fpath))
+	cmd.Dir = filepath.Dir(fpath)
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("git show: %v", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, fmt.Errorf("git show: %v", err)
+	}
+	return out, nil
+}
+
+// VcsGutterState is the state of the markers of changed lines of a TextView
+type VcsGutterState struct {
+	Changes  []VcsChange  `desc:"changes of the buffer relative to the HEAD version, as of the last diff"`
+	Head     *giv.TextBuf `desc:"HEAD version of the file, as of HeadTime"`
+	HeadFile string       `desc:"file the HEAD version is of"`
+	HeadTime time.Time    `desc:"time the HEAD version was read"`
+	Timer    *time.Timer  `desc:"timer for diffing after typing pauses"`
+	Mu       sync.Mutex   `desc:"mutex for the changes and the HEAD version"`
+}
+
+// VcsChangesCopy returns the current changes
+func (tv *TextView) VcsChangesCopy() []VcsChange {
+	tv.Vcs.Mu.Lock()
+	defer tv.Vcs.Mu.Unlock()
+	return tv.Vcs.Changes
+}
+
+// VcsChangeAt returns the change marked at given line, if any -- lines
+// deleted at the end are marked at the last line
+func (tv *TextView) VcsChangeAt(ln int) (VcsChange, bool) {
+	for _, vc := range tv.VcsChangesCopy() {
+		if vc.Has(ln) || (vc.Tag == 'd' && vc.St == tv.NLines && ln == vc.St-1) {
+			return vc, true
+		}
+	}
+	return VcsChange{}, false
+}
+
+// UpdateVcsGutter diffs the buffer against the VCS HEAD version of its file
+// in the background, after VcsDiffWaitMSec, and updates the markers of the
+// changed lines, if VcsGutter is on
+func (tv *TextView) UpdateVcsGutter() {
+	if !tv.VcsGutter && len(tv.VcsChangesCopy()) == 0 {
+		return
+	}
+	if tv.Vcs.Timer != nil {
+		tv.Vcs.Timer.Stop()
+	}
+	tv.Vcs.Timer = time.AfterFunc(time.Duration(VcsDiffWaitMSec)*time.Millisecond, tv.DiffVcsHead)
+}
+
+// DiffVcsHead diffs the buffer against the VCS HEAD version of its file,
+// reading it again if older than VcsHeadMaxAge, and re-renders the markers
+// of the changed lines -- there are none if VcsGutter is off, or the file
+// is not committed
+func (tv *TextView) DiffVcsHead() {
+	var chs []VcsChange
+	tb := tv.Buf
+	if tv.VcsGutter && tb != nil && tb.Filename != "" && tb.NumLines() <= VcsGutterMaxLines {
+		fname := string(tb.Filename)
+		vs := &tv.Vcs
+		vs.Mu.Lock()
+		if vs.HeadFile != fname || time.Since(vs.HeadTime) > VcsHeadMaxAge {
+			vs.Head = nil
+			if txt, err := VcsHeadText(fname); err == nil {
+				vs.Head = DiffTextBuf(fname, txt)
+			}
+			vs.HeadFile = fname
+			vs.HeadTime = time.Now()
+		}
+		hb := vs.Head
+		vs.Mu.Unlock()
+		if hb != nil {
+			chs = VcsChanges(hb.DiffBufs(tb), BufLines(hb))
+		}
+	}
+	tv.Vcs.Mu.Lock()
+	old := tv.Vcs.Changes
+	tv.Vcs.Changes = chs
+	tv.Vcs.Mu.Unlock()
+	if len(old) == 0 && len(chs) == 0 {
+		return
+	}
+	if tv.Viewport == nil || tv.Viewport.Win == nil || tv.NLines == 0 {
+		return
+	}
+	if len(old) > 0 {
+		updt := tv.Viewport.Win.UpdateStart()
+		for _, vc := range old {
+			st := vc.St
+			if st > 0 {
+				st-- // the wedge of deleted lines is drawn over the line before
+			}
+			tv.RenderLines(st, vc.Ed)
+		}
+		tv.Viewport.Win.UpdateEnd(updt)
+	}
+	tv.UpdateOverlays()
+}
+
+// RenderVcsGutter draws the markers of the visible changed lines at the left
+// of the line numbers: a bar for added and modified lines, and a wedge
+// between the lines for deleted ones
+func (tv *TextView) RenderVcsGutter() {
+	chs := tv.VcsChangesCopy()
+	if len(chs) == 0 || tv.NLines == 0 || tv.Viewport == nil || tv.VpBBox.Empty() {
+		return
+	}
+	if len(tv.Renders) < tv.NLines || len(tv.Offs) < tv.NLines {
+		return
+	}
+	st := tv.FirstVisibleLine(0)
+	ed := tv.LastVisibleLine(st)
+	img := tv.Viewport.Pixels
+	x := tv.VpBBox.Min.X
+	w := VcsGutterWidth
+	for _, vc := range chs {
+		clr := image.NewUniform(vc.Color())
+		if vc.Tag == 'd' {
+			if vc.St < st || vc.St > ed+1 {
+				continue
+			}
+			var y int
+			if vc.St < tv.NLines {
+				y = int(tv.CharStartPos(giv.TextPos{Ln: vc.St}).Y)
+			} else {
+				y = int(tv.CharStartPos(giv.TextPos{Ln: tv.NLines - 1}).Y + tv.LineHeight)
+			}
+			for dy := -w; dy <= w; dy++ {
+				rw := 2*w - dy
+				if dy < 0 {
+					rw = 2*w + dy
+				}
+				r := image.Rect(x, y+dy, x+rw, y+dy+1).Intersect(tv.VpBBox)
+				draw.Draw(img, r, clr, image.ZP, draw.Src)
+			}
+			continue
+		}
+		for ln := vc.St; ln < vc.Ed && ln < tv.NLines; ln++ {
+			if ln < st || ln > ed {
+				continue
+			}
+			y := tv.CharStartPos(giv.TextPos{Ln: ln}).Y
+			r := image.Rect(x, int(y), x+w, int(y+tv.LineHeight)).Intersect(tv.VpBBox)
+			draw.Draw(img, r, clr, image.ZP, draw.Src)
+		}
+	}
+}
+
+// VcsGutterMouseInput shows the change at a line when its marker in the
+// gutter is clicked
+func (tv *TextView) VcsGutterMouseInput(me *mouse.Event) {
+	if me.Action != mouse.Press || me.Button != mouse.Left || tv.Buf == nil {
+		return
+	}
+	pt := tv.PointToRelPos(me.Pos())
+	if pt.X > int(tv.LineNoOff) && pt.X > 3*VcsGutterWidth {
+		return
+	}
+	if vc, ok := tv.VcsChangeAt(tv.PixelToCursor(pt).Ln); ok {
+		tv.ShowVcsChange(vc)
+	}
+}
+
+// ShowVcsChange shows a dialog with the HEAD version of the lines of the
+// change, and a choice to revert them
+func (tv *TextView) ShowVcsChange(vc VcsChange) {
+	var title, prompt string
+	switch vc.Tag {
+	case 'i':
+		title = "Added Lines"
+		prompt = fmt.Sprintf("Lines %v-%v were added since HEAD", vc.St+1, vc.Ed)
+	case 'd':
+		title = "Deleted Lines"
+		prompt = "Deleted since HEAD:\n\n" + strings.Join(vc.Old, "\n")
+	default:
+		title = "Modified Lines"
+		prompt = "In HEAD:\n\n" + strings.Join(vc.Old, "\n")
+	}
+	gi.ChoiceDialog(tv.Viewport, gi.DlgOpts{Title: title, Prompt: prompt},
+		[]string{"Revert Hunk", "Close"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != 0 {
+				return
+			}
+			tvv := recv.Embed(KiT_TextView).(*TextView)
+			tvv.RevertVcsChange(vc)
+		})
+}
+
+// RevertVcsChange replaces the lines of the change with their HEAD version,
+// as an edit that can be undone -- returns false if the lines of the change
+// are no longer in the buffer
+func (tv *TextView) RevertVcsChange(vc VcsChange) bool {
+	if tv.Buf == nil {
+		return false
+	}
+	lns := BufLines(tv.Buf)
+	if vc.St > len(lns) || vc.Ed > len(lns) {
+		return false
+	}
+	nlns := make([]string, 0, len(lns)-(vc.Ed-vc.St)+len(vc.Old))
+	nlns = append(nlns, lns[:vc.St]...)
+	nlns = append(nlns, vc.Old...)
+	nlns = append(nlns, lns[vc.Ed:]...)
+	ReplaceChangedLines(tv.Buf, nlns)
+	tv.SetCursorShow(giv.TextPos{Ln: vc.St})
+	tv.DiffVcsHead()
+	return true
+}
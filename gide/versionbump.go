@@ -0,0 +1,256 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VersionFile is a file with a version to update when preparing a release
+type VersionFile struct {
+	File   string `desc:"file with the version, relative to the project root"`
+	Regexp string `desc:"regular expression matching the version in the file, whose first group is replaced with the new version, e.g., Version = \"([^\"]*)\""`
+}
+
+// ConvCommit is a commit message in conventional commits form:
+// type(scope)!: subject, where ! (or BREAKING CHANGE in the body) marks
+// a breaking change
+type ConvCommit struct {
+	Hash     string `desc:"commit hash"`
+	Type     string `desc:"type of commit: feat, fix, docs, etc -- empty if not in conventional form"`
+	Scope    string `desc:"optional scope"`
+	Subject  string `desc:"subject (first line), without the type and scope"`
+	Breaking bool   `desc:"commit has breaking changes"`
+}
+
+// ConvCommitRe matches the subject of a conventional commit
+var ConvCommitRe = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.*)$`)
+
+// ParseConvCommit parses the subject and body of a commit message
+func ParseConvCommit(hash, subj, body string) ConvCommit {
+	cc := ConvCommit{Hash: hash, Subject: subj}
+	if m := ConvCommitRe.FindStringSubmatch(subj); m != nil {
+		cc.Type = strings.ToLower(m[1])
+		cc.Scope = m[2]
+		cc.Breaking = m[3] == "!"
+		cc.Subject = m[4]
+	}
+	if strings.Contains(body, "BREAKING CHANGE") || strings.Contains(body, "BREAKING-CHANGE") {
+		cc.Breaking = true
+	}
+	return cc
+}
+
+// LastTag returns the most recent git tag reachable from HEAD, or "" if none
+func LastTag(root string) string {
+	cmd := exec.Command("git", "describe", "--tags", "--abbrev=0")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// CommitsSince returns the commits since given tag (all commits if empty),
+// most recent first
+func CommitsSince(root, tag string) ([]ConvCommit, error) {
+	args := []string{"log", "--format=%h%x00%s%x00%b%x1e"}
+	if tag != "" {
+		args = append(args, tag+"..HEAD")
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %v", err)
+	}
+	var cms []ConvCommit
+	for _, rec := range bytes.Split(out, []byte{0x1e}) {
+		fs := strings.SplitN(strings.TrimSpace(string(rec)), "\x00", 3)
+		if len(fs) < 2 {
+			continue
+		}
+		body := ""
+		if len(fs) == 3 {
+			body = fs[2]
+		}
+		cms = append(cms, ParseConvCommit(fs[0], fs[1], body))
+	}
+	return cms, nil
+}
+
+// SemverRe matches a semantic version, with optional v prefix and
+// pre-release / build suffix
+var SemverRe = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)`)
+
+// NextVersion returns the next semantic version after last (a tag, e.g.,
+// v1.2.3) for the given commits, and the kind of bump: major for breaking
+// changes (minor while the major version is 0), minor for features, and
+// patch otherwise -- v0.1.0 if there is no last version
+func NextVersion(last string, cms []ConvCommit) (string, string) {
+	m := SemverRe.FindStringSubmatch(last)
+	if m == nil {
+		return "v0.1.0", "initial"
+	}
+	maj, _ := strconv.Atoi(m[2])
+	min, _ := strconv.Atoi(m[3])
+	pat, _ := strconv.Atoi(m[4])
+	brk, feat := false, false
+	for _, cc := range cms {
+		brk = brk || cc.Breaking
+		feat = feat || cc.Type == "feat"
+	}
+	bump := "patch"
+	switch {
+	case brk && maj > 0:
+		bump = "major"
+		maj, min, pat = maj+1, 0, 0
+	case brk || feat:
+		bump = "minor"
+		min, pat = min+1, 0
+	default:
+		pat++
+	}
+	return fmt.Sprintf("%v%d.%d.%d", m[1], maj, min, pat), bump
+}
+
+// ChangelogGroups are the sections of a drafted changelog, by commit type
+// -- commits of other types are listed under Other Changes
+var ChangelogGroups = []struct{ Type, Title string }{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance"},
+}
+
+// ChangelogSection drafts the changelog section for a release of given
+// version and date, from the commits: breaking changes first, then by
+// ChangelogGroups, then the others (except merges)
+func ChangelogSection(vers, date string, cms []ConvCommit) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %v (%v)\n", vers, date)
+	item := func(cc ConvCommit) string {
+		s := cc.Subject
+		if cc.Scope != "" {
+			s = "**" + cc.Scope + ":** " + s
+		}
+		return fmt.Sprintf("- %v (%v)\n", s, cc.Hash)
+	}
+	group := func(title string, sel func(cc ConvCommit) bool) {
+		n := 0
+		for _, cc := range cms {
+			if !sel(cc) {
+				continue
+			}
+			if n == 0 {
+				fmt.Fprintf(&sb, "\n### %v\n\n", title)
+			}
+			sb.WriteString(item(cc))
+			n++
+		}
+	}
+	group("Breaking Changes", func(cc ConvCommit) bool { return cc.Breaking })
+	grouped := map[string]bool{}
+	for _, g := range ChangelogGroups {
+		typ := g.Type
+		grouped[typ] = true
+		group(g.Title, func(cc ConvCommit) bool { return !cc.Breaking && cc.Type == typ })
+	}
+	group("Other Changes", func(cc ConvCommit) bool {
+		return !cc.Breaking && !grouped[cc.Type] && !strings.HasPrefix(cc.Subject, "Merge ")
+	})
+	return sb.String()
+}
+
+// InsertChangelog inserts the section at the top of the changelog file,
+// after its title heading if any -- the file is created if it does not exist
+func InsertChangelog(fpath, sect string) error {
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	cl := string(b)
+	if cl == "" {
+		cl = "# Changelog\n"
+	}
+	head := ""
+	if strings.HasPrefix(cl, "# ") {
+		if i := strings.Index(cl, "\n"); i >= 0 {
+			head = cl[:i+1] + "\n"
+			cl = strings.TrimLeft(cl[i+1:], "\n")
+		} else {
+			head = cl + "\n\n"
+			cl = ""
+		}
+	}
+	if cl != "" {
+		sect += "\n"
+	}
+	return ioutil.WriteFile(fpath, []byte(head+sect+cl), 0644)
+}
+
+// UpdateVersionFile replaces the first group of each match of the regexp in
+// the file with the version -- returns an error if there is no match
+func UpdateVersionFile(fpath, re, vers string) error {
+	rx, err := regexp.Compile(re)
+	if err != nil {
+		return fmt.Errorf("version regexp for %v: %v", filepath.Base(fpath), err)
+	}
+	if rx.NumSubexp() < 1 {
+		return fmt.Errorf("version regexp for %v has no group for the version: %v", filepath.Base(fpath), re)
+	}
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return err
+	}
+	ms := rx.FindAllSubmatchIndex(b, -1)
+	if len(ms) == 0 {
+		return fmt.Errorf("version regexp does not match in %v: %v", filepath.Base(fpath), re)
+	}
+	var nb []byte
+	st := 0
+	for _, m := range ms {
+		if m[2] < 0 {
+			continue
+		}
+		nb = append(nb, b[st:m[2]]...)
+		nb = append(nb, vers...)
+		st = m[3]
+	}
+	nb = append(nb, b[st:]...)
+	return ioutil.WriteFile(fpath, nb, 0644)
+}
+
+// TagRelease commits the given files (e.g., those updated by Prepare
+// Release), if any, and creates an annotated tag for the version, returning
+// the git output
+func TagRelease(root, vers string, files []string) ([]byte, error) {
+	var out []byte
+	git := func(args ...string) error {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		o, err := cmd.CombinedOutput()
+		out = append(out, o...)
+		return err
+	}
+	if len(files) > 0 {
+		if err := git(append([]string{"add", "--"}, files...)...); err != nil {
+			return out, err
+		}
+		if err := git(append([]string{"commit", "-m", "Release " + vers, "--"}, files...)...); err != nil {
+			return out, err
+		}
+	}
+	err := git("tag", "-a", vers, "-m", "Release "+vers)
+	return out, err
+}
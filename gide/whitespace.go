@@ -0,0 +1,93 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+)
+
+// WhitespacePrefs are the glyphs and color used to show spaces, tabs and
+// line endings when whitespace is shown in a view
+type WhitespacePrefs struct {
+	Space string  `desc:"glyph shown for each space"`
+	Tab   string  `desc:"glyph shown at the start of each tab"`
+	EOL   string  `desc:"glyph shown at the end of each line"`
+	Blend float32 `min:"0" max:"100" step:"5" desc:"percent blend of the text color into the background color for the glyphs -- lower is fainter"`
+}
+
+// Defaults are the defaults for WhitespacePrefs
+func (wp *WhitespacePrefs) Defaults() {
+	wp.Space = "·"
+	wp.Tab = "→"
+	wp.EOL = "¬"
+	wp.Blend = 30
+}
+
+// SetWhitespace turns showing of whitespace on or off for this view, with
+// the glyphs of given prefs
+func (tv *TextView) SetWhitespace(on bool, wp *WhitespacePrefs) {
+	tv.Whitespace = on
+	tv.WhitePrefs = wp
+	tv.SetFullReRender()
+	tv.UpdateSig()
+}
+
+// RenderWhitespace draws the whitespace glyphs over the spaces, tabs and
+// line endings of the given range of lines (inclusive) that are visible
+func (tv *TextView) RenderWhitespace(st, ed int) {
+	if !tv.Whitespace || tv.WhitePrefs == nil || tv.Buf == nil || tv.NLines == 0 || tv.Viewport == nil || tv.VpBBox.Empty() {
+		return
+	}
+	if len(tv.Renders) < tv.NLines || len(tv.Offs) < tv.NLines {
+		return
+	}
+	if st < 0 {
+		st = 0
+	}
+	if ed >= tv.NLines {
+		ed = tv.NLines - 1
+	}
+	wp := tv.WhitePrefs
+	sty := &tv.Sty
+	fst := sty.Font
+	fst.BgColor.SetColor(nil)
+	fst.Color = sty.Font.BgColor.Color.Blend(wp.Blend, &sty.Font.Color)
+	var spr, tbr, eor gi.TextRender
+	spr.SetString(wp.Space, &fst, &sty.UnContext, &sty.Text, true, 0, 0)
+	tbr.SetString(wp.Tab, &fst, &sty.UnContext, &sty.Text, true, 0, 0)
+	eor.SetString(wp.EOL, &fst, &sty.UnContext, &sty.Text, true, 0, 0)
+	met := sty.Font.Face.Face.Metrics()
+	boff := gi.FixedToFloat32(met.Ascent) - gi.FixedToFloat32(met.Descent)
+	rs := &tv.Viewport.Render
+	tbb := tv.VpBBox
+	tbb.Min.X += int(tv.LineNoOff)
+	rs.PushBounds(tbb)
+	rs.Lock()
+	render := func(tr *gi.TextRender, ln, ch int) {
+		pos := tv.CharStartPos(giv.TextPos{Ln: ln, Ch: ch})
+		pos.Y += boff
+		if int(pos.Y) < tbb.Min.Y || int(pos.Y-boff) > tbb.Max.Y {
+			return
+		}
+		tr.Render(rs, pos)
+	}
+	for ln := st; ln <= ed && ln < tv.Buf.NumLines(); ln++ {
+		lt := tv.Buf.Line(ln)
+		for ch, r := range lt {
+			switch r {
+			case ' ':
+				render(&spr, ln, ch)
+			case '\t':
+				render(&tbr, ln, ch)
+			}
+		}
+		if ln < tv.Buf.NumLines()-1 { // last line has no line ending
+			render(&eor, ln, len(lt))
+		}
+	}
+	rs.Unlock()
+	rs.PopBounds()
+}
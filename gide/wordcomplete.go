@@ -0,0 +1,336 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/complete"
+)
+
+// WordCompleteMinLen is the minimum number of runes of the words offered by
+// word completion
+var WordCompleteMinLen = 3
+
+// WordCompleteMinSeed is the minimum number of runes typed before words
+// are offered by word completion
+var WordCompleteMinSeed = 2
+
+// WordCompleteMax is the maximum number of words offered by word
+// completion, after those of the language
+var WordCompleteMax = 20
+
+// WordIndexMaxAgeSec is the number of seconds after which the word index
+// of the project is built again, in the background, when next used
+var WordIndexMaxAgeSec = 300
+
+// WordIndexMaxFileSize is the maximum size of the files of the project
+// whose words are indexed, in bytes
+var WordIndexMaxFileSize = int64(512 * 1024)
+
+// WordIndexMaxWords is the maximum number of distinct words in the word
+// index of a project
+var WordIndexMaxWords = 200000
+
+// ScanWords calls fun for each word of the runes of a line: identifiers
+// and words of letters, digits and _, starting with a letter or _, of at
+// least WordCompleteMinLen runes
+func ScanWords(lt []rune, fun func(st, ed int)) {
+	for ch := 0; ch < len(lt); {
+		if !isWordRune(lt[ch]) {
+			ch++
+			continue
+		}
+		st := ch
+		for ch < len(lt) && isWordRune(lt[ch]) {
+			ch++
+		}
+		if ch-st >= WordCompleteMinLen && !unicode.IsDigit(lt[st]) {
+			fun(st, ch)
+		}
+	}
+}
+
+// wordSeed returns the word at the end of the text, which is completed
+func wordSeed(text string) string {
+	rs := []rune(text)
+	st := len(rs)
+	for st > 0 && isWordRune(rs[st-1]) {
+		st--
+	}
+	return string(rs[st:])
+}
+
+// wordMatcher returns a function that returns true if a word matches the
+// seed: starts with it, ignoring case unless the seed has upper case, and
+// is longer
+func wordMatcher(seed string) func(w string) bool {
+	fold := strings.ToLower(seed) == seed
+	return func(w string) bool {
+		if len(w) <= len(seed) {
+			return false
+		}
+		if fold {
+			return strings.HasPrefix(strings.ToLower(w), seed)
+		}
+		return strings.HasPrefix(w, seed)
+	}
+}
+
+// WordIndex is the index of the words in the files of a project, for word
+// completion, built in the background (see Update)
+type WordIndex struct {
+	Root  string     `desc:"root directory of the project"`
+	Words []string   `desc:"distinct words, sorted by their lower case, in Lower"`
+	Lower []string   `desc:"lower case of the Words, sorted"`
+	Time  time.Time  `desc:"when the index was last built"`
+	Busy  bool       `desc:"index is being built"`
+	Jobs  *Jobs      `view:"-" json:"-" xml:"-" desc:"jobs of the project, in which building the index is listed"`
+	Mu    sync.Mutex `view:"-" json:"-" xml:"-" desc:"mutex protecting the index"`
+}
+
+// NewWordIndex returns a new word index for the project at given root,
+// built when first used, as a job in given jobs of the project
+func NewWordIndex(root string, jobs *Jobs) *WordIndex {
+	return &WordIndex{Root: root, Jobs: jobs}
+}
+
+// Update builds the index again in the background, if it has not been
+// built in the last WordIndexMaxAgeSec and is not being built
+func (wi *WordIndex) Update() {
+	wi.Mu.Lock()
+	defer wi.Mu.Unlock()
+	if wi.Busy || wi.Root == "" || time.Since(wi.Time) < time.Duration(WordIndexMaxAgeSec)*time.Second {
+		return
+	}
+	wi.Busy = true
+	jb := wi.Jobs.Add("Word Index", wi.Root, "indexing the words of the project files for completion", nil)
+	go func() {
+		wi.Build()
+		jb.Finish()
+	}()
+}
+
+// Build builds the index from the words (see ScanWords) of the text files
+// of the project (see WalkProjFiles), up to WordIndexMaxFileSize and
+// WordIndexMaxWords
+func (wi *WordIndex) Build() {
+	wm := make(map[string]bool)
+	WalkProjFiles(wi.Root, func(path string, info os.FileInfo) {
+		if len(wm) >= WordIndexMaxWords || info.Size() > WordIndexMaxFileSize || IsBinaryFile(path) {
+			return
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return
+		}
+		for _, l := range strings.Split(string(b), "\n") {
+			lt := []rune(l)
+			ScanWords(lt, func(st, ed int) {
+				if len(wm) < WordIndexMaxWords {
+					wm[string(lt[st:ed])] = true
+				}
+			})
+		}
+	})
+	wds := make([]string, 0, len(wm))
+	for w := range wm {
+		wds = append(wds, w)
+	}
+	sort.Slice(wds, func(i, j int) bool {
+		li, lj := strings.ToLower(wds[i]), strings.ToLower(wds[j])
+		if li != lj {
+			return li < lj
+		}
+		return wds[i] < wds[j]
+	})
+	lwr := make([]string, len(wds))
+	for i, w := range wds {
+		lwr[i] = strings.ToLower(w)
+	}
+	wi.Mu.Lock()
+	wi.Words = wds
+	wi.Lower = lwr
+	wi.Time = time.Now()
+	wi.Busy = false
+	wi.Mu.Unlock()
+}
+
+// Matches calls fun for each word of the index that matches the seed (see
+// wordMatcher), in order, until it returns false
+func (wi *WordIndex) Matches(seed string, fun func(w string) bool) {
+	wi.Mu.Lock()
+	defer wi.Mu.Unlock()
+	match := wordMatcher(seed)
+	ls := strings.ToLower(seed)
+	for i := sort.SearchStrings(wi.Lower, ls); i < len(wi.Lower) && strings.HasPrefix(wi.Lower[i], ls); i++ {
+		if match(wi.Words[i]) && !fun(wi.Words[i]) {
+			return
+		}
+	}
+}
+
+// WordCompleter is the completion context of a buffer with word
+// completion: the words of the buffer, then those of the other open
+// buffers, then those of the word index of the project, are offered after
+// the completions of the language, if any, like dabbrev in emacs -- so that
+// completion works in any language -- set with SetWordCompleter
+type WordCompleter struct {
+	Buf   *giv.TextBuf       `desc:"the buffer completed"`
+	Open  *OpenNodes         `desc:"open nodes of the project, whose buffers' words are offered"`
+	Index *WordIndex         `desc:"word index of the project"`
+	Data  interface{}        `desc:"context of the completion of the language"`
+	Match complete.MatchFunc `desc:"completion of the language -- nil if none"`
+	Edit  complete.EditFunc  `desc:"edit function of the completion of the language"`
+	Words map[string]bool    `desc:"words offered by the last match, not by the language"`
+	Mu    sync.Mutex         `view:"-" json:"-" xml:"-" desc:"mutex protecting Words"`
+}
+
+// SetWordCompleter sets the completer of the buffer to a WordCompleter
+// with the open nodes and word index of the project, keeping its current
+// completer as that of the language -- or if !on, restores the latter
+func SetWordCompleter(tb *giv.TextBuf, open *OpenNodes, idx *WordIndex, on bool) {
+	var wc *WordCompleter
+	if tb.Complete != nil {
+		wc, _ = tb.Complete.Context.(*WordCompleter)
+	}
+	if !on {
+		if wc != nil {
+			tb.SetCompleter(wc.Data, wc.Match, wc.Edit)
+		}
+		return
+	}
+	if wc != nil {
+		wc.Open = open
+		wc.Index = idx
+		return
+	}
+	wc = &WordCompleter{Buf: tb, Open: open, Index: idx}
+	if tb.Complete != nil {
+		wc.Data = tb.Complete.Context
+		wc.Match = tb.Complete.MatchFunc
+		wc.Edit = tb.Complete.EditFunc
+	}
+	tb.SetCompleter(wc, CompleteWords, CompleteWordsEdit)
+}
+
+// bufWords calls fun for each word of the lines of the buffer matching the
+// seed, starting at given line and going out from it, until it returns false
+func bufWords(tb *giv.TextBuf, ln int, match func(w string) bool, fun func(w string) bool) {
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	nln := len(tb.Lines)
+	for d := 0; ln-d >= 0 || ln+d < nln; d++ {
+		lns := []int{ln - d, ln + d}
+		if d == 0 {
+			lns = lns[:1]
+		}
+		for _, l := range lns {
+			if l < 0 || l >= nln {
+				continue
+			}
+			lt := tb.Lines[l]
+			done := false
+			ScanWords(lt, func(st, ed int) {
+				if w := string(lt[st:ed]); !done && match(w) && !fun(w) {
+					done = true
+				}
+			})
+			if done {
+				return
+			}
+		}
+	}
+}
+
+// CompleteWords does completion with the completion of the language, if
+// any, followed by the words of the buffer, nearest to the cursor first,
+// of the other open buffers, and of the word index of the project,
+// matching the word before the cursor -- given the *WordCompleter as data
+// -- words are only added if the language completes the same seed
+func CompleteWords(data interface{}, text string, posLn, posCh int) (md complete.MatchData) {
+	wc, ok := data.(*WordCompleter)
+	if !ok {
+		return md
+	}
+	if wc.Match != nil {
+		md = wc.Match(wc.Data, text, posLn, posCh)
+	}
+	seed := wordSeed(text)
+	wds := make(map[string]bool)
+	defer func() {
+		wc.Mu.Lock()
+		wc.Words = wds
+		wc.Mu.Unlock()
+	}()
+	if len([]rune(seed)) < WordCompleteMinSeed || (len(md.Matches) > 0 && md.Seed != seed) {
+		return md
+	}
+	md.Seed = seed
+	max := gi.CompleteMaxItems - len(md.Matches)
+	if max > WordCompleteMax {
+		max = WordCompleteMax
+	}
+	if max <= 0 {
+		return md
+	}
+	have := make(map[string]bool, len(md.Matches))
+	for _, m := range md.Matches {
+		have[m.Text] = true
+	}
+	add := func(w string) bool {
+		if !have[w] {
+			have[w] = true
+			wds[w] = true
+			md.Matches = append(md.Matches, complete.Completion{Text: w})
+		}
+		return len(wds) < max
+	}
+	match := wordMatcher(seed)
+	if wc.Buf != nil {
+		bufWords(wc.Buf, posLn, match, add)
+	}
+	if wc.Open != nil {
+		for _, fn := range *wc.Open {
+			if len(wds) >= max {
+				break
+			}
+			if fn.This() == nil || fn.Buf == nil || fn.Buf == wc.Buf {
+				continue
+			}
+			bufWords(fn.Buf, 0, match, add)
+		}
+	}
+	if wc.Index != nil && len(wds) < max {
+		wc.Index.Update()
+		wc.Index.Matches(seed, add)
+	}
+	return md
+}
+
+// CompleteWordsEdit edits the text after the user chooses from the
+// candidate completions: with the edit function of the language for its
+// completions, else replacing the word before the cursor
+func CompleteWordsEdit(data interface{}, text string, cursorPos int, c complete.Completion, seed string) (ed complete.EditData) {
+	wc, ok := data.(*WordCompleter)
+	if !ok {
+		return complete.EditWord(text, cursorPos, c.Text, seed)
+	}
+	wc.Mu.Lock()
+	word := wc.Words[c.Text]
+	wc.Mu.Unlock()
+	if !word && wc.Edit != nil {
+		return wc.Edit(wc.Data, text, cursorPos, c, seed)
+	}
+	return complete.EditWord(text, cursorPos, c.Text, seed)
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gidev
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gide/gide"
+)
+
+// SaveBufEnc saves the buffer to its file converted to given encoding,
+// via a temporary file, so the file is never left partly written or in
+// the wrong encoding
+func (ge *GideView) SaveBufEnc(tb *giv.TextBuf, fe gide.FileEnc) error {
+	tb.EditDone()
+	b, nbad, err := gide.EncodeText(tb.Txt, fe)
+	if err == nil {
+		err = gide.WriteFileAtomic(string(tb.Filename), b, 0644)
+	}
+	if err != nil {
+		tb.SetChanged()
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not Save with Encoding", Prompt: fmt.Sprintf("File %v was not saved, as it could not be converted to %v: %v", ge.Files.RelPath(tb.Filename), fe, err)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return err
+	}
+	if nbad > 0 {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Characters Replaced", Prompt: fmt.Sprintf("File %v was saved, but %v characters could not be saved in %v and were replaced by ?", ge.Files.RelPath(tb.Filename), nbad, fe.Encoding)}, gi.AddOk, gi.NoCancel, nil, nil)
+	}
+	tb.Stat()
+	return nil
+}
+
+// DecodeBuf converts the text of the file in the buffer for editing, if it
+// is not UTF-8 with LF line endings: using its encoding in FileEncs if set,
+// else the one detected from its contents
+func (ge *GideView) DecodeBuf(tb *giv.TextBuf) {
+	b, err := ioutil.ReadFile(string(tb.Filename))
+	if err != nil {
+		return
+	}
+	fe, ok := ge.FileEncs[tb.Filename]
+	if !ok {
+		fe, _ = gide.DetectFileEnc(b)
+	}
+	ge.SetBufEnc(tb, b, fe)
+}
+
+// SetBufEnc sets the text of the buffer from the raw file contents, decoded
+// from given encoding, which is used when saving it
+func (ge *GideView) SetBufEnc(tb *giv.TextBuf, raw []byte, fe gide.FileEnc) error {
+	txt, err := gide.DecodeText(raw, fe)
+	if err != nil {
+		return err
+	}
+	if ge.FileEncs == nil {
+		ge.FileEncs = make(gide.FileEncMap)
+	}
+	if fe.IsDefault() {
+		delete(ge.FileEncs, tb.Filename)
+	} else {
+		ge.FileEncs[tb.Filename] = fe
+	}
+	if !bytes.Equal(txt, tb.Txt) {
+		tb.SetText(txt)
+		tb.ClearChanged()
+		tb.ReMarkup()
+	}
+	return nil
+}
+
+// BufEnc returns the encoding and line endings of the file in the buffer
+func (ge *GideView) BufEnc(tb *giv.TextBuf) gide.FileEnc {
+	if fe, ok := ge.FileEncs[tb.Filename]; ok {
+		return fe
+	}
+	return gide.FileEnc{Encoding: "UTF-8"}
+}
+
+// EncodeSavedFile converts the file just saved by Save As (as UTF-8 with LF
+// line endings) back to its encoding in FileEncs, if any, via a temporary
+// file -- Save converts the text before writing it (see SaveBufEnc)
+func (ge *GideView) EncodeSavedFile(fpath gi.FileName) {
+	fe, ok := ge.FileEncs[fpath]
+	if !ok || fe.IsDefault() {
+		return
+	}
+	b, err := ioutil.ReadFile(string(fpath))
+	if err == nil {
+		var nbad int
+		b, nbad, err = gide.EncodeText(b, fe)
+		if err == nil {
+			err = gide.WriteFileAtomic(string(fpath), b, 0644)
+		}
+		if err == nil && nbad > 0 {
+			ge.SetStatus(fmt.Sprintf("Warning: %v characters could not be saved in %v and were replaced by ?", nbad, fe.Encoding))
+		}
+	}
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not Save with Encoding", Prompt: fmt.Sprintf("File %v was saved as UTF-8, as it could not be converted to %v: %v", fpath, fe, err)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	if fnk, ok := ge.Files.FindFile(string(fpath)); ok {
+		fn := fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode)
+		if fn.Buf != nil {
+			fn.Buf.Stat() // own the re-encoded file
+		}
+	}
+}
+
+// ReopenWithEncoding re-opens the file in the active view, decoding it from
+// the given encoding, which is then used when saving it -- any changes are
+// lost
+func (ge *GideView) ReopenWithEncoding(encoding string) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.Buf.Filename == "" {
+		return
+	}
+	b, err := ioutil.ReadFile(string(tv.Buf.Filename))
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not reopen file: %v", err))
+		return
+	}
+	fe, _ := gide.DetectFileEnc(b)
+	if fe.Encoding != encoding {
+		fe.Encoding = encoding
+		fe.BOM = false
+		switch {
+		case encoding == "UTF-8" && bytes.HasPrefix(b, []byte{0xEF, 0xBB, 0xBF}):
+			fe.BOM = true
+		case encoding == "UTF-16LE" && bytes.HasPrefix(b, []byte{0xFF, 0xFE}):
+			fe.BOM = true
+		case encoding == "UTF-16BE" && bytes.HasPrefix(b, []byte{0xFE, 0xFF}):
+			fe.BOM = true
+		}
+	}
+	if err := ge.SetBufEnc(tv.Buf, b, fe); err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not reopen file: %v", err))
+		return
+	}
+	ge.SetStatus(fmt.Sprintf("Reopened with encoding: %v", fe))
+}
+
+// SaveWithEncoding saves the file in the active view in the given encoding
+// (UTF-16 with a byte order mark), keeping its line endings, and uses that
+// encoding for subsequent saves
+func (ge *GideView) SaveWithEncoding(encoding string) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.Buf.Filename == "" {
+		return
+	}
+	fe := ge.BufEnc(tv.Buf)
+	fe.Encoding = encoding
+	fe.BOM = strings.HasPrefix(encoding, "UTF-16")
+	if ge.FileEncs == nil {
+		ge.FileEncs = make(gide.FileEncMap)
+	}
+	ge.FileEncs[tv.Buf.Filename] = fe
+	tv.Buf.SetChanged()
+	ge.SaveActiveView()
+	ge.SetStatus(fmt.Sprintf("Saved with encoding: %v", fe))
+}
+
+// ToggleLineEndings toggles the line endings of the file in the active view
+// between LF and CR LF (windows style), which are used when it is next saved
+func (ge *GideView) ToggleLineEndings() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.Buf.Filename == "" {
+		return
+	}
+	fe := ge.BufEnc(tv.Buf)
+	fe.CRLF = !fe.CRLF
+	if ge.FileEncs == nil {
+		ge.FileEncs = make(gide.FileEncMap)
+	}
+	ge.FileEncs[tv.Buf.Filename] = fe
+	tv.Buf.SetChanged()
+	ge.SetStatus(fmt.Sprintf("Line endings: %v -- save to apply", fe))
+}
+
+// GideViewTextEncodings gets the text encodings for submenu-func
+func GideViewTextEncodings(it interface{}, vp *gi.Viewport2D) []string {
+	return gide.TextEncodings
+}
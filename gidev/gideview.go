@@ -13,11 +13,16 @@ import (
 	"bytes"
 	"fmt"
 	"html"
+	"io/ioutil"
 	"log"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -26,7 +31,10 @@ import (
 	"github.com/goki/gi/giv"
 	"github.com/goki/gi/histyle"
 	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/dnd"
 	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/oswin/mouse"
 	"github.com/goki/gi/units"
 	"github.com/goki/gide/gide"
 	"github.com/goki/ki/ki"
@@ -54,8 +62,7 @@ const (
 // middle, and a tabbed viewer on the right.
 type GideView struct {
 	gi.Frame
-	ProjRoot          gi.FileName             `desc:"root directory for the project -- all projects must be organized within a top-level root directory, with all the files therein constituting the scope of the project -- by default it is the path for ProjFilename"`
-	ProjFilename      gi.FileName             `ext:".gide" desc:"current project filename for saving / loading specific Gide configuration information in a .gide file (optional)"`
+	gide.Project
 	ActiveFilename    gi.FileName             `desc:"filename of the currently-active textview"`
 	ActiveLang        filecat.Supported       `desc:"language for current active filename"`
 	Changed           bool                    `json:"-" desc:"has the root changed?  we receive update signals from root for changes"`
@@ -63,11 +70,45 @@ type GideView struct {
 	ActiveTextViewIdx int                     `json:"-" desc:"index of the currently-active textview -- new files will be viewed in other views if available"`
 	OpenNodes         gide.OpenNodes          `json:"-" desc:"list of open nodes, most recent first"`
 	CmdBufs           map[string]*giv.TextBuf `json:"-" desc:"the command buffers for commands run in this project"`
-	CmdHistory        gide.CmdNames           `json:"-" desc:"history of commands executed in this session"`
-	RunningCmds       gide.CmdRuns            `json:"-" xml:"-" desc:"currently running commands in this project"`
-	ArgVals           gide.ArgVarVals         `json:"-" xml:"-" desc:"current arg var vals"`
-	Prefs             gide.ProjPrefs          `desc:"preferences for this project -- this is what is saved in a .gide project file"`
+	RunningJobs       gide.Jobs               `json:"-" xml:"-" desc:"jobs running in the background in this project, e.g., commands -- shown in the Jobs tab"`
+	JobsTimer         *time.Timer             `json:"-" xml:"-" desc:"timer for updating the elapsed times in the Jobs tab"`
 	KeySeq1           key.Chord               `desc:"first key in sequence if needs2 key pressed"`
+	DocsLang          filecat.Supported       `json:"-" xml:"-" desc:"language of the docs currently shown in the Docs tab -- for following cross-references"`
+	DocsDir           string                  `json:"-" xml:"-" desc:"directory for looking up docs cross-references in the Docs tab"`
+	JournalTimer      *time.Timer             `json:"-" xml:"-" desc:"timer for periodic crash-recovery journal snapshots"`
+	ProjSaveTimer     *time.Timer             `json:"-" xml:"-" desc:"timer for saving the project file after files are saved -- see SaveProjSoon"`
+	ProjSaveMu        sync.Mutex              `json:"-" xml:"-" view:"-" desc:"mutex protecting ProjSaveTimer"`
+	HistoryFile       gi.FileName             `json:"-" xml:"-" desc:"file whose local history is shown in the Local History tab"`
+	ClosedFiles       gide.ClosedFiles        `json:"-" xml:"-" desc:"files closed in this session, for reopening with their cursor position"`
+	FileServer        *gide.FileServer        `json:"-" xml:"-" desc:"local file server started by Serve Project Directory"`
+	CmdWatch          *gide.CmdWatch          `json:"-" xml:"-" desc:"watch mode, re-running the commands with Watch globs when their files change, if on -- see ToggleWatchCmds"`
+	SQLSchema         *gide.SQLSchema         `json:"-" xml:"-" desc:"database schema for completion in .sql files, loaded per the SQL project prefs"`
+	NotebookFile      gi.FileName             `json:"-" xml:"-" desc:"Jupyter notebook shown in the Notebook tab"`
+	PreviewFile       gi.FileName             `json:"-" xml:"-" desc:"font or audio file shown in the Preview tab"`
+	AudioPlayer       *exec.Cmd               `json:"-" xml:"-" desc:"audio player playing the PreviewFile, if any"`
+	ReleaseArts       []gide.ReleaseArtifact  `json:"-" xml:"-" desc:"artifacts produced by the last Build Release"`
+	PrepRelVers       string                  `json:"-" xml:"-" desc:"version set by Prepare Release, to be committed and tagged by Tag Release"`
+	PrepRelFiles      []string                `json:"-" xml:"-" desc:"version files and changelog updated by Prepare Release, to be committed by Tag Release"`
+	FileEncs          gide.FileEncMap         `json:"-" xml:"-" desc:"text encodings and line endings of open files that are not UTF-8 with LF, which are converted back when saved"`
+	FileCrypts        gide.FileCryptMap       `json:"-" xml:"-" desc:"encryption (age or sops) of open files that were decrypted, which are re-encrypted when saved"`
+	ZenMode           bool                    `json:"-" xml:"-" desc:"in distraction-free (zen) editing mode, showing only the active text view"`
+	ZenSplits         []float32               `json:"-" xml:"-" desc:"splitter proportions before entering zen mode, restored if there is no named split config"`
+	Broadcast         *gide.Broadcaster       `json:"-" xml:"-" desc:"broadcast of the active view started by Start Broadcast"`
+	FollowURL         string                  `json:"-" xml:"-" desc:"share url of the broadcast last followed by Follow Broadcast"`
+	FollowStop        chan struct{}           `json:"-" xml:"-" desc:"closed to stop following the broadcast"`
+	KeyDisplay        *gide.KeyDisplay        `json:"-" xml:"-" desc:"display of pressed keys for screencasts, if on -- see ToggleKeyDisplay"`
+	LastActive        time.Time               `json:"-" xml:"-" desc:"time of the last key press or mouse click, for the idle lock -- see SetActive and IdleTime"`
+	ActiveMu          sync.Mutex              `json:"-" xml:"-" view:"-" desc:"mutex protecting LastActive"`
+	IdleTimer         *time.Timer             `json:"-" xml:"-" desc:"timer for locking the project after the IdleLockMins of inactivity in the gide prefs"`
+	Dialogs           gide.DialogDeferrer     `json:"-" xml:"-" view:"-" desc:"defers non-critical prompts while typing -- see the Dialogs prefs"`
+	Notices           gide.Notices            `json:"-" xml:"-" desc:"non-modal prompts shown in the Notices tab"`
+	CallTree          *gide.CallTree          `json:"-" xml:"-" desc:"call hierarchy shown in the Call Hierarchy tab"`
+	Locked            bool                    `json:"-" xml:"-" desc:"project is locked: the text views are blanked until the lock passphrase is entered"`
+	LockBufs          []*giv.TextBuf          `json:"-" xml:"-" desc:"buffers of the text views when locked, restored when unlocked"`
+	LockSplits        []float32               `json:"-" xml:"-" desc:"splitter proportions when locked, restored when unlocked"`
+	WordIdx           *gide.WordIndex         `json:"-" xml:"-" desc:"index of the words in the files of the project, for word completion"`
+	GUIFuncs          []func()                `json:"-" xml:"-" view:"-" desc:"functions queued by RunOnGUI, to be run on the event loop goroutine of the window"`
+	GUIMu             sync.Mutex              `json:"-" xml:"-" view:"-" desc:"mutex protecting GUIFuncs"`
 	UpdtMu            sync.Mutex              `desc:"mutex for protecting overall updates to GideView"`
 }
 
@@ -98,8 +139,8 @@ func (ge *GideView) VersCtrl() giv.VersCtrlName {
 	return vc
 }
 
-func (ge *GideView) CmdRuns() *gide.CmdRuns {
-	return &ge.RunningCmds
+func (ge *GideView) Jobs() *gide.Jobs {
+	return &ge.RunningJobs
 }
 
 func (ge *GideView) ArgVarVals() *gide.ArgVarVals {
@@ -118,10 +159,6 @@ func (ge *GideView) UpdateFiles() {
 	ge.Files.OpenPath(string(ge.ProjRoot))
 }
 
-func (ge *GideView) IsEmpty() bool {
-	return ge.ProjRoot == ""
-}
-
 // OpenRecent opens a recently-used file
 func (ge *GideView) OpenRecent(filename gi.FileName) {
 	if string(filename) == gide.GideViewResetRecents {
@@ -166,19 +203,14 @@ func (ge *GideView) OpenPath(path gi.FileName) (*gi.Window, *GideView) {
 		return NewGideProjPath(string(path))
 	}
 	ge.Defaults()
-	root, pnm, fnm, ok := ProjPathParse(string(path))
+	pnm, fnm, ok := ge.Project.OpenPath(string(path))
 	if ok {
+		root := string(ge.ProjRoot)
 		os.Chdir(root)
 		gide.SavedPaths.AddPath(root, gi.Prefs.SavedPathsMax)
 		gide.SavePaths()
-		ge.ProjRoot = gi.FileName(root)
 		ge.SetName(pnm)
-		ge.Prefs.ProjFilename = gi.FileName(filepath.Join(root, pnm+".gide"))
-		ge.ProjFilename = ge.Prefs.ProjFilename
-		ge.Prefs.ProjRoot = ge.ProjRoot
 		ge.Config()
-		ge.GuessMainLang()
-		ge.LangDefaults()
 		win := ge.ParentWindow()
 		if win != nil {
 			winm := "gide-" + pnm
@@ -188,6 +220,9 @@ func (ge *GideView) OpenPath(path gi.FileName) (*gi.Window, *GideView) {
 		if fnm != "" {
 			ge.NextViewFile(gi.FileName(fnm))
 		}
+		ge.RecoverJournalCheck()
+		ge.StartIdleLock()
+		ge.AutoCheckUpdate()
 	}
 	return ge.ParentWindow(), ge
 }
@@ -199,10 +234,10 @@ func (ge *GideView) OpenProj(filename gi.FileName) (*gi.Window, *GideView) {
 		return OpenGideProj(string(filename))
 	}
 	ge.Defaults()
-	ge.Prefs.OpenJSON(filename)
-	ge.Prefs.ProjFilename = filename // should already be set but..
-	_, pnm, _, ok := ProjPathParse(string(ge.Prefs.ProjRoot))
-	if ok {
+	pnm, err := ge.Project.OpenProj(filename)
+	if err != nil {
+		log.Println(err)
+	} else {
 		os.Chdir(string(ge.Prefs.ProjRoot))
 		gide.SavedPaths.AddPath(string(filename), gi.Prefs.SavedPathsMax)
 		gide.SavePaths()
@@ -215,6 +250,9 @@ func (ge *GideView) OpenProj(filename gi.FileName) (*gi.Window, *GideView) {
 			win.SetName(winm)
 			win.SetTitle(winm)
 		}
+		ge.RecoverJournalCheck()
+		ge.StartIdleLock()
+		ge.AutoCheckUpdate()
 	}
 	return ge.ParentWindow(), ge
 }
@@ -238,10 +276,12 @@ func (ge *GideView) NewProj(path gi.FileName, folder string, mainLang filecat.Su
 	return win, nge
 }
 
-// NewFile creates a new file in the project
-func (ge *GideView) NewFile(filename string, addToVcs bool) {
+// NewFile creates a new file in the project, with its initial contents from
+// the given template, or if none is given, the template for the file's
+// extension (see gide.AvailTemplates)
+func (ge *GideView) NewFile(filename string, tmpl gide.TemplateName, addToVcs bool) {
 	np := filepath.Join(string(ge.ProjRoot), filename)
-	_, err := os.Create(np)
+	err := gide.NewFileFromTemplate(np, tmpl, ge.Nm)
 	if err != nil {
 		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Make File", Prompt: fmt.Sprintf("Could not make new file at: %v, err: %v", np, err)}, gi.AddOk, gi.NoCancel, nil, nil)
 		return
@@ -255,6 +295,55 @@ func (ge *GideView) NewFile(filename string, addToVcs bool) {
 	}
 }
 
+// NewGoKiWidget creates a new Go file in the project with a GoKi widget type
+// of given name (from the file name if empty), pre-wired with its KiT type
+// registration, Props and Config, and opens it (see gide.NewGoKiWidget)
+func (ge *GideView) NewGoKiWidget(filename, typeName string) {
+	np := filepath.Join(string(ge.ProjRoot), filename)
+	if filepath.Ext(np) == "" {
+		np += ".go"
+	}
+	if err := gide.NewGoKiWidget(np, typeName, ge.Nm); err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Make File", Prompt: fmt.Sprintf("Could not make new GoKi widget file at: %v, err: %v", np, err)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	ge.Files.UpdateNewFile(np)
+	ge.NextViewFile(gi.FileName(np))
+}
+
+// NewGoKiEditor creates a new package in given folder of the project for a
+// GoKi editor app, like gide, with an editor type of given name (from the
+// folder name if empty), pre-wired with its KiT type registration, ToolBar
+// and MainMenu Props and window, and a cmd main package that opens it, and
+// opens the editor file (see gide.NewGoKiEditor)
+func (ge *GideView) NewGoKiEditor(folder, typeName string) {
+	np := filepath.Join(string(ge.ProjRoot), folder)
+	fls, err := gide.NewGoKiEditor(np, typeName, ge.Nm)
+	for _, fl := range fls {
+		ge.Files.UpdateNewFile(fl)
+	}
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Make Package", Prompt: fmt.Sprintf("Could not make new GoKi editor package at: %v, err: %v", np, err)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	ge.NextViewFile(gi.FileName(fls[0]))
+}
+
+// NewScratchBuf opens a new scratch buffer of given name and language in the
+// active view -- scratch buffers are not associated with a file, and are
+// listed in the open nodes until closed or saved as a file
+func (ge *GideView) NewScratchBuf(name string, lang filecat.Supported) {
+	if name == "" {
+		name = fmt.Sprintf("scratch%d", len(ge.OpenNodes)+1)
+	}
+	if on := ge.OpenNodes.ByStringName(name + " - scratch"); on != nil {
+		ge.ViewFileNode(ge.ActiveTextView(), ge.ActiveTextViewIdx, on)
+		return
+	}
+	fn := gide.NewScratchNode(&ge.Files, name, lang)
+	ge.ViewFileNode(ge.ActiveTextView(), ge.ActiveTextViewIdx, fn)
+}
+
 // SaveProj saves project file containing custom project settings, in a
 // standard JSON-formatted file
 func (ge *GideView) SaveProj() {
@@ -278,19 +367,51 @@ func (ge *GideView) SaveProjIfExists(saveAllFiles bool) bool {
 	return true
 }
 
+// ProjSaveDelay is the time without files being saved after which the
+// project file is saved by SaveProjSoon
+var ProjSaveDelay = 2 * time.Second
+
+// SaveProjSoon saves the project file, if it exists, once no files have been
+// saved for ProjSaveDelay, so that saving a file, or many in a row, does not
+// write the project file each time -- it is saved on the event loop
+// goroutine (see RunOnGUI), unless it has been saved in the meantime
+func (ge *GideView) SaveProjSoon() {
+	ge.ProjSaveMu.Lock()
+	defer ge.ProjSaveMu.Unlock()
+	if ge.ProjSaveTimer != nil {
+		ge.ProjSaveTimer.Stop()
+	}
+	var tm *time.Timer
+	tm = time.AfterFunc(ProjSaveDelay, func() {
+		ge.RunOnGUI(func() {
+			ge.ProjSaveMu.Lock()
+			cur := ge.ProjSaveTimer == tm
+			ge.ProjSaveMu.Unlock()
+			if cur {
+				ge.SaveProjIfExists(false) // no saveall
+			}
+		})
+	})
+	ge.ProjSaveTimer = tm
+}
+
 // SaveProjAs saves project custom settings to given filename, in a standard
 // JSON-formatted file
 // saveAllFiles indicates if user should be prompted for saving all files
 // returns true if the user was prompted, false otherwise
 func (ge *GideView) SaveProjAs(filename gi.FileName, saveAllFiles bool) bool {
+	ge.ProjSaveMu.Lock()
+	if ge.ProjSaveTimer != nil { // saving now
+		ge.ProjSaveTimer.Stop()
+		ge.ProjSaveTimer = nil
+	}
+	ge.ProjSaveMu.Unlock()
 	gide.SavedPaths.AddPath(string(filename), gi.Prefs.SavedPathsMax)
 	gide.SavePaths()
 	ge.Files.UpdateNewFile(string(filename))
-	ge.Prefs.ProjFilename = filename
-	ge.ProjFilename = ge.Prefs.ProjFilename
 	ge.GrabPrefs()
-	ge.Prefs.SaveJSON(filename)
-	gi.SaveSpellModel()
+	ge.Project.SaveProjAs(filename)
+	gide.SaveSpellLang()
 	ge.Changed = false
 	if saveAllFiles {
 		return ge.SaveAllCheck(false, nil) // false = no cancel option
@@ -334,90 +455,63 @@ func (ge *GideView) SaveAllCheck(cancelOpt bool, fun func(ge *GideView)) bool {
 // the path is a directory or not), and a bool if all is good (otherwise error
 // message has been reported). projnm is always the last directory of the path.
 func ProjPathParse(path string) (root, projnm, fnm string, ok bool) {
-	if path == "" {
-		return "", "blank", "", false
-	}
-	info, err := os.Lstat(path)
-	if err != nil {
-		emsg := fmt.Errorf("gide.ProjPathParse: Cannot open at given path: %q: Error: %v", path, err)
-		log.Println(emsg)
-		return
-	}
-	path, _ = filepath.Abs(path)
-	dir, fn := filepath.Split(path)
-	pathIsDir := info.IsDir()
-	if pathIsDir {
-		root = path
-	} else {
-		root = filepath.Clean(dir)
-		fnm = fn
-	}
-	_, projnm = filepath.Split(root)
-	ok = true
-	return
+	return gide.ProjPathParse(path)
 }
 
 // CheckForProjAtPath checks if there is a .gide project at the given path
 // returns project path and true if found, otherwise false
 func CheckForProjAtPath(path string) (string, bool) {
-	root, pnm, _, ok := ProjPathParse(path)
-	if !ok {
-		return "", false
-	}
-	gproj := filepath.Join(root, pnm+".gide")
-	if _, err := os.Stat(gproj); os.IsNotExist(err) {
-		return "", false // does not exist
-	}
-	return gproj, true
+	return gide.CheckForProjAtPath(path)
 }
 
-// GuessMainLang guesses the main language in the project -- returns true if successful
-func (ge *GideView) GuessMainLang() bool {
-	ecs := ge.Files.FileExtCounts()
-	for _, ec := range ecs {
-		ls := filecat.ExtSupported(ec.Name)
-		if ls != filecat.NoSupport {
-			ge.Prefs.MainLang = ls
-			return true
-		}
-	}
-	return false
-}
+//////////////////////////////////////////////////////////////////////////////////////
+//   TextViews
 
-// LangDefaults applies default language settings based on MainLang
-func (ge *GideView) LangDefaults() bool {
-	ge.Prefs.RunCmds = gide.CmdNames{"Run Proj"}
-	ge.Prefs.BuildDir = ge.Prefs.ProjRoot
-	ge.Prefs.BuildTarg = ge.Prefs.ProjRoot
-	ge.Prefs.RunExec = gi.FileName(filepath.Join(string(ge.Prefs.ProjRoot), ge.Nm))
-	got := false
-	switch ge.Prefs.MainLang {
-	case filecat.Go:
-		ge.Prefs.BuildCmds = gide.CmdNames{"Build Go Proj"}
-		got = true
-	case filecat.TeX:
-		ge.Prefs.BuildCmds = gide.CmdNames{"LaTeX PDF"}
-		ge.Prefs.RunCmds = gide.CmdNames{"Open Target File"}
-		got = true
-	default:
-		ge.Prefs.BuildCmds = gide.CmdNames{"Make"}
+// HiStyle returns the highlighting style for this project: the HiStyle in
+// the project prefs if set, else the global one
+func (ge *GideView) HiStyle() histyle.StyleName {
+	if ge.Prefs.HiStyle != "" {
+		return ge.Prefs.HiStyle
 	}
-	return got
+	return gide.Prefs.HiStyle
 }
 
-//////////////////////////////////////////////////////////////////////////////////////
-//   TextViews
-
 // ConfigTextBuf configures the text buf according to prefs
 func (ge *GideView) ConfigTextBuf(tb *giv.TextBuf) {
-	tb.SetHiStyle(gide.Prefs.HiStyle)
+	if hs := ge.HiStyle(); tb.Hi.Style != hs {
+		tb.SetHiStyle(hs)
+		if tb.NLines > 0 { // live update of already-open buffer
+			tb.Hi.Init(&tb.Info, &tb.PiState)
+			tb.ReMarkup()
+		}
+	}
 	ge.Prefs.Editor.ConfigTextBuf(tb)
+	if vo, ok := ge.Prefs.ViewOpts[ge.Files.RelPath(tb.Filename)]; ok {
+		vo.ConfigTextBuf(tb)
+	}
+	if !gide.SetBufCompleter(ge, tb) {
+		if gide.IsSQLFile(string(tb.Filename)) {
+			ge.SetSQLCompleter(tb)
+		} else {
+			gide.SetSchemaCompleter(tb)
+		}
+	}
+	gide.SetWordCompleter(tb, &ge.OpenNodes, ge.WordIndex(), ge.Prefs.Editor.WordComplete)
 
 	// these are now set in std textbuf..
 	// tb.SetSpellCorrect(tb, giv.SpellCorrectEdit)                    // always set -- option can override
 	// tb.SetCompleter(&tb.PiState, pi.CompletePi, giv.CompleteGoEdit) // todo: need pi edit too..
 }
 
+// WordIndex returns the index of the words in the files of the project,
+// for word completion, making a new one if the project root has changed
+func (ge *GideView) WordIndex() *gide.WordIndex {
+	if ge.WordIdx == nil || ge.WordIdx.Root != string(ge.ProjRoot) {
+		ge.WordIdx = gide.NewWordIndex(string(ge.ProjRoot), &ge.RunningJobs)
+	}
+	return ge.WordIdx
+}
+
 // ActiveTextView returns the currently-active TextView
 func (ge *GideView) ActiveTextView() *gide.TextView {
 	//	fmt.Printf("stdout: active text view idx: %v\n", ge.ActiveTextViewIdx)
@@ -427,9 +521,8 @@ func (ge *GideView) ActiveTextView() *gide.TextView {
 
 // TextViewIndex finds index of given textview (0 or 1)
 func (ge *GideView) TextViewIndex(av *gide.TextView) int {
-	split := ge.SplitView()
 	for i := 0; i < NTextViews; i++ {
-		tv := split.Child(TextView1Idx + i).Child(0).Embed(gide.KiT_TextView).(*gide.TextView)
+		tv := ge.TextViewByIndex(i)
 		if tv.This() == av.This() {
 			return i
 		}
@@ -444,9 +537,8 @@ func (ge *GideView) TextViewForFileNode(fn *giv.FileNode) (*gide.TextView, int,
 		return nil, -1, false
 	}
 	ge.ConfigTextBuf(fn.Buf)
-	split := ge.SplitView()
 	for i := 0; i < NTextViews; i++ {
-		tv := split.Child(TextView1Idx + i).Child(0).Embed(gide.KiT_TextView).(*gide.TextView)
+		tv := ge.TextViewByIndex(i)
 		if tv != nil && tv.Buf != nil && tv.Buf.This() == fn.Buf.This() && ge.PanelIsOpen(i+TextView1Idx) {
 			return tv, i, true
 		}
@@ -522,34 +614,70 @@ func (ge *GideView) SetActiveTextViewIdx(idx int) *gide.TextView {
 
 // NextTextView returns the next text view available for viewing a file and
 // its index -- if the active text view is empty, then it is used, otherwise
-// it is the next one (if visible)
+// it is the next one (if visible) -- a pinned view is never used if the
+// other one can be (see UnpinnedTextView)
 func (ge *GideView) NextTextView() (*gide.TextView, int) {
 	av := ge.TextViewByIndex(ge.ActiveTextViewIdx)
 	if av.Buf == nil {
 		return av, ge.ActiveTextViewIdx
 	}
 	nxt := (ge.ActiveTextViewIdx + 1) % NTextViews
-	if !ge.PanelIsOpen(nxt + TextView1Idx) {
-		return av, ge.ActiveTextViewIdx
+	if !ge.PanelIsOpen(nxt+TextView1Idx) || ge.TextViewByIndex(nxt).Pinned {
+		return ge.UnpinnedTextView(ge.ActiveTextViewIdx)
 	}
 	return ge.TextViewByIndex(nxt), nxt
 }
 
+// UnpinnedTextView returns the text view of given index, and its index, to
+// view another file in, unless it is pinned (see TogglePin), in which case
+// it is the other one, if it is open and not pinned -- if both are pinned,
+// the given one is returned, so the caller must check
+func (ge *GideView) UnpinnedTextView(idx int) (*gide.TextView, int) {
+	tv := ge.TextViewByIndex(idx)
+	if !tv.Pinned {
+		return tv, idx
+	}
+	oidx := (idx + 1) % NTextViews
+	if ov := ge.TextViewByIndex(oidx); !ov.Pinned && ge.PanelIsOpen(oidx+TextView1Idx) {
+		return ov, oidx
+	}
+	return tv, idx
+}
+
+// TogglePin toggles pinning the file in the active view to it: while it is
+// pinned, other files are opened in the other view, e.g., to keep a
+// reference file in view while opening files from the tree
+func (ge *GideView) TogglePin() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	tv.Pinned = !tv.Pinned
+	if tv.Pinned {
+		ge.SetStatus("Pinned: other files open in the other view")
+	} else {
+		ge.SetStatus("Unpinned")
+	}
+}
+
 // SaveActiveView saves the contents of the currently-active textview
 func (ge *GideView) SaveActiveView() {
 	tv := ge.ActiveTextView()
 	if tv.Buf != nil {
-		if tv.Buf.Filename != "" {
-			tv.Buf.Save()
+		if tv.Buf.Filename != "" && !strings.HasPrefix(string(tv.Buf.Filename), gide.ScratchPrefix) {
+			if err := ge.SaveBuf(tv.Buf); err != nil {
+				ge.SetStatus(fmt.Sprintf("File NOT Saved: %v: %v", ge.Files.RelPath(tv.Buf.Filename), err))
+				return
+			}
+			ge.FileSaved(tv.Buf.Filename)
 			ge.SetStatus("File Saved")
-			fpath, _ := filepath.Split(string(tv.Buf.Filename))
-			ge.Files.UpdateNewFile(fpath) // update everything in dir -- will have removed autosave
+			gide.UpdateSavedFile(&ge.Files, tv.Buf) // will have removed autosave
 			ge.RunPostCmdsActiveView()
 		} else {
 			giv.CallMethod(ge, "SaveActiveViewAs", ge.Viewport) // uses fileview
 		}
 	}
-	ge.SaveProjIfExists(false) // no saveall
+	ge.SaveProjSoon()
 }
 
 // SaveActiveViewAs save with specified filename the contents of the
@@ -558,12 +686,25 @@ func (ge *GideView) SaveActiveViewAs(filename gi.FileName) {
 	tv := ge.ActiveTextView()
 	if tv.Buf != nil {
 		ofn := tv.Buf.Filename
+		if _, ok := ge.FileCrypts[ofn]; ok {
+			gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Cannot Save Encrypted File As", Prompt: "Save As would write the decrypted text to the new file -- duplicate the file in the file tree instead, and open that"}, gi.AddOk, gi.NoCancel, nil, nil)
+			return
+		}
+		ond, _, _ := ge.OpenNodeForTextView(tv)
 		tv.Buf.SaveAsFunc(filename, func(canceled bool) {
 			if canceled {
 				ge.SetStatus(fmt.Sprintf("File %v NOT Saved As: %v", ofn, filename))
 				return
 			}
 			ge.SetStatus(fmt.Sprintf("File %v Saved As: %v", ofn, filename))
+			if fe, ok := ge.FileEncs[ofn]; ok {
+				ge.FileEncs[filename] = fe
+			}
+			ge.EncodeSavedFile(filename)
+			ge.FileSaved(filename)
+			if ond != nil && gide.IsScratch(ond) { // now a regular file
+				ge.OpenNodes.Delete(ond)
+			}
 			// ge.RunPostCmdsActiveView() // doesn't make sense..
 			ge.Files.UpdateNewFile(string(filename)) // update everything in dir -- will have removed autosave
 			fnk, ok := ge.Files.FindFile(string(filename))
@@ -576,7 +717,7 @@ func (ge *GideView) SaveActiveViewAs(filename gi.FileName) {
 			}
 		})
 	}
-	ge.SaveProjIfExists(false) // no saveall
+	ge.SaveProjSoon()
 }
 
 // RevertActiveView revert active view to saved version
@@ -585,9 +726,137 @@ func (ge *GideView) RevertActiveView() {
 	if tv.Buf != nil {
 		ge.ConfigTextBuf(tv.Buf)
 		tv.Buf.Revert()
-		fpath, _ := filepath.Split(string(tv.Buf.Filename))
-		ge.Files.UpdateNewFile(fpath) // update everything in dir -- will have removed autosave
+		if !ge.DecryptBuf(tv.Buf) {
+			ge.DecodeBuf(tv.Buf)
+		}
+		gide.UpdateSavedFile(&ge.Files, tv.Buf) // will have removed autosave
+	}
+}
+
+// ActiveExportDoc returns the selection in the active view, or all of its
+// lines, with their highlighting, to export -- nil if there is no file
+func (ge *GideView) ActiveExportDoc() *gide.ExportDoc {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return nil
+	}
+	st, ed := gide.ExportLines(&tv.TextView)
+	return gide.NewExportDoc(tv.Buf, st, ed, ge.Files.RelPath(tv.Buf.Filename), tv.Buf.Opts.LineNos)
+}
+
+// ExportHTML exports the selection in the active view, or the whole file,
+// with its highlighting and line numbers, as a standalone HTML page -- .html
+// is added to the file name if it has no .html or .htm extension
+func (ge *GideView) ExportHTML(filename gi.FileName) {
+	xd := ge.ActiveExportDoc()
+	if xd == nil {
+		return
+	}
+	fn := string(filename)
+	if ext := strings.ToLower(filepath.Ext(fn)); ext != ".html" && ext != ".htm" {
+		fn += ".html"
+	}
+	if err := ioutil.WriteFile(fn, xd.HTML(), 0644); err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Export HTML Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	ge.SetStatus(fmt.Sprintf("Exported %v lines of %v as HTML: %v", len(xd.Lines), xd.Title, fn))
+}
+
+// ExportPDF exports the selection in the active view, or the whole file,
+// with its highlighting and line numbers, as a paginated PDF -- .pdf is
+// added to the file name if it has no .pdf extension
+func (ge *GideView) ExportPDF(filename gi.FileName) {
+	xd := ge.ActiveExportDoc()
+	if xd == nil {
+		return
+	}
+	fn := string(filename)
+	if strings.ToLower(filepath.Ext(fn)) != ".pdf" {
+		fn += ".pdf"
+	}
+	if err := xd.PDF(fn); err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Export PDF Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	ge.SetStatus(fmt.Sprintf("Exported %v lines of %v as PDF: %v", len(xd.Lines), xd.Title, fn))
+}
+
+// PrintActiveView prints the selection in the active view, or the whole
+// file, as a PDF like ExportPDF, with lp if available, else by opening the
+// PDF in the system viewer to print from there
+func (ge *GideView) PrintActiveView() {
+	xd := ge.ActiveExportDoc()
+	if xd == nil {
+		return
+	}
+	tf, err := ioutil.TempFile("", "gide-print-*.pdf")
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Print Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	fn := tf.Name()
+	tf.Close()
+	if err := xd.PDF(fn); err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Print Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	if lp, err := exec.LookPath("lp"); err == nil {
+		out, err := exec.Command(lp, "-t", filepath.Base(xd.Title), fn).CombinedOutput()
+		if err != nil {
+			gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Print Failed", Prompt: html.EscapeString(strings.TrimSpace(string(out)))}, gi.AddOk, gi.NoCancel, nil, nil)
+			return
+		}
+		ge.SetStatus(fmt.Sprintf("Printed %v lines of %v: %v", len(xd.Lines), xd.Title, strings.TrimSpace(string(out))))
+		return
+	}
+	oswin.TheApp.OpenURL("file://" + fn)
+	ge.SetStatus(fmt.Sprintf("Opened %v lines of %v as PDF to print: %v", len(xd.Lines), xd.Title, fn))
+}
+
+// DecryptBuf decrypts the text of the file in the buffer if it is age or
+// sops encrypted, per the Crypt project prefs, so it is re-encrypted when
+// saved -- returns true if decrypted
+func (ge *GideView) DecryptBuf(tb *giv.TextBuf) bool {
+	kind := gide.EncryptedKind(string(tb.Filename))
+	if kind == "" {
+		return false
+	}
+	txt, err := ge.Prefs.Crypt.Decrypt(kind, string(tb.Filename))
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not Decrypt File", Prompt: fmt.Sprintf("File %v is %v encrypted, but could not be decrypted -- check the Crypt keys in the project prefs: %v", ge.Files.RelPath(tb.Filename), kind, err)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return false
+	}
+	if ge.FileCrypts == nil {
+		ge.FileCrypts = make(gide.FileCryptMap)
+	}
+	ge.FileCrypts[tb.Filename] = kind
+	tb.Autosave = false
+	tb.SetText(txt)
+	tb.ClearChanged()
+	tb.ReMarkup()
+	return true
+}
+
+// SaveBuf saves the buffer to its file -- decrypted files are re-encrypted,
+// without writing the plaintext to disk, and files in other encodings than
+// UTF-8 with LF line endings are converted back to them (see SaveBufEnc)
+func (ge *GideView) SaveBuf(tb *giv.TextBuf) error {
+	kind, ok := ge.FileCrypts[tb.Filename]
+	if !ok {
+		if fe, ok := ge.FileEncs[tb.Filename]; ok && !fe.IsDefault() {
+			return ge.SaveBufEnc(tb, fe)
+		}
+		return tb.Save()
+	}
+	tb.EditDone()
+	if err := ge.Prefs.Crypt.SaveEncrypted(kind, string(tb.Filename), tb.Txt); err != nil {
+		tb.SetChanged()
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not Save Encrypted File", Prompt: fmt.Sprintf("File %v was not saved, as it could not be encrypted: %v", ge.Files.RelPath(tb.Filename), err)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return err
 	}
+	tb.Stat()
+	return nil
 }
 
 // CloseActiveView closes the buffer associated with active view
@@ -595,16 +864,125 @@ func (ge *GideView) CloseActiveView() {
 	tv := ge.ActiveTextView()
 	ond, idx, got := ge.OpenNodeForTextView(tv)
 	if got {
-		ond.Buf.Close(func(canceled bool) {
-			if canceled {
-				ge.SetStatus(fmt.Sprintf("File %v NOT closed", ond.FPath))
-				return
+		if gide.IsScratch(ond) {
+			ond.Buf.ClearChanged() // scratch buffers are discarded on close
+		}
+		cur := tv.CursorPos
+		ge.RememberFilePos(tv)
+		ge.SaveCryptCheck(ond.Buf, func() {
+			ond.Buf.Close(func(canceled bool) {
+				if canceled {
+					ge.SetStatus(fmt.Sprintf("File %v NOT closed", ond.FPath))
+					return
+				}
+				if !gide.IsScratch(ond) {
+					ge.ClosedFiles.Push(ond.FPath, cur)
+				}
+				ge.OpenNodes.DeleteIdx(idx)
+				ond.SetClosed()
+				ge.SetStatus(fmt.Sprintf("File %v closed", ond.FPath))
+			})
+		})
+	}
+}
+
+// SaveCryptCheck prompts to save changes to a decrypted file before fun
+// closes its buffer, so they are re-encrypted instead of being saved as
+// plaintext by the close prompt of the buffer -- fun is called unless
+// canceled
+func (ge *GideView) SaveCryptCheck(tb *giv.TextBuf, fun func()) {
+	if _, ok := ge.FileCrypts[tb.Filename]; !ok || !tb.IsChanged() {
+		fun()
+		return
+	}
+	gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "Close Without Saving?",
+		Prompt: fmt.Sprintf("Do you want to save your changes to encrypted file: %v?", tb.Filename)},
+		[]string{"Save", "Close Without Saving", "Cancel"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			switch sig {
+			case 0:
+				if ge.SaveBuf(tb) == nil {
+					fun()
+				}
+			case 1:
+				tb.ClearChanged()
+				fun()
 			}
-			ge.OpenNodes.DeleteIdx(idx)
-			ond.SetClosed()
-			ge.SetStatus(fmt.Sprintf("File %v closed", ond.FPath))
 		})
+}
+
+// FileRenamed updates any open buffers for a file or directory that has
+// been renamed from oldpath to newpath
+func (ge *GideView) FileRenamed(oldpath, newpath gi.FileName) {
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf == nil || !gide.IsPathUnder(ond.Buf.Filename, oldpath) {
+			continue
+		}
+		nfn := newpath + ond.Buf.Filename[len(oldpath):]
+		ond.Buf.Filename = nfn
+		ond.Buf.Stat()
+		ond.FPath = nfn
+	}
+	ge.SetStatus(fmt.Sprintf("Renamed: %v to: %v", oldpath, newpath))
+}
+
+// CloseFileBufs closes, without saving, any open buffers for the file or
+// files within the directory at given path, e.g., prior to deleting it
+func (ge *GideView) CloseFileBufs(fpath gi.FileName) {
+	for i := len(ge.OpenNodes) - 1; i >= 0; i-- {
+		ond := ge.OpenNodes[i]
+		if ond.Buf == nil || !gide.IsPathUnder(ond.Buf.Filename, fpath) {
+			continue
+		}
+		ond.Buf.ClearChanged()
+		ond.Buf.AutoSaveDelete()
+		ond.Buf.Close(nil)
+		ge.OpenNodes.DeleteIdx(i)
+		ond.SetClosed()
+	}
+}
+
+// ReopenClosedFile reopens the most recently closed file in the next text
+// view, restoring its cursor position
+func (ge *GideView) ReopenClosedFile() bool {
+	for {
+		cf, ok := ge.ClosedFiles.Pop()
+		if !ok {
+			ge.SetStatus("No closed files to reopen")
+			return false
+		}
+		tv, _, ok := ge.NextViewFile(cf.FPath)
+		if !ok {
+			continue // file no longer exists
+		}
+		tv.SetCursorShow(cf.Cursor)
+		ge.SetStatus(fmt.Sprintf("Reopened file: %v", cf.FPath))
+		return true
+	}
+}
+
+// OpenRecentFile opens a file recently viewed in this project, given its
+// path relative to the project root
+func (ge *GideView) OpenRecentFile(filename gi.FileName) bool {
+	fnm := string(filename)
+	if !filepath.IsAbs(fnm) {
+		fnm = filepath.Join(string(ge.ProjRoot), fnm)
+	}
+	_, _, ok := ge.NextViewFile(gi.FileName(fnm))
+	if !ok {
+		ge.SetStatus(fmt.Sprintf("Could not find recent file: %v", filename))
+	}
+	return ok
+}
+
+// GideViewRecentFiles gets the list of recent files in the project for
+// submenu-func
+func GideViewRecentFiles(it interface{}, vp *gi.Viewport2D) []string {
+	ge, ok := it.(ki.Ki).Embed(KiT_GideView).(*GideView)
+	if !ok {
+		return nil
 	}
+	return gide.RecentFileNames(ge.Prefs.RecentFiles, ge.ProjRoot)
 }
 
 // RunPostCmdsActiveView runs any registered post commands on the active view
@@ -631,6 +1009,11 @@ func (ge *GideView) RunPostCmdsFileNode(fn *giv.FileNode) bool {
 			return true
 		}
 	}
+	if cmds, has := gide.ExtPostSaveCmds[strings.ToLower(filepath.Ext(string(fn.FPath)))]; has {
+		ge.ExecCmdsFileNode(fn, cmds, false, true)
+		fn.Buf.Revert()
+		return true
+	}
 	return false
 }
 
@@ -642,16 +1025,20 @@ func (ge *GideView) AutoSaveCheck(tv *gide.TextView, vidx int, fn *giv.FileNode)
 		fn.Buf.Autosave = false
 		return false // we are the autosave file
 	}
+	if _, ok := ge.FileCrypts[fn.FPath]; ok {
+		fn.Buf.Autosave = false
+		return false // no plaintext autosave files
+	}
 	fn.Buf.Autosave = true
 	if tv.IsChanged() || !fn.Buf.AutoSaveCheck() {
 		return false
 	}
 	ge.DiffFileNode(gi.FileName(fn.Buf.AutoSaveFilename()), fn)
-	gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "Autosave file Exists",
-		Prompt: fmt.Sprintf("An auto-save file for file: %v exists -- open it in the other text view (you can then do Save As to replace current file)?  If you don't open it, the next change made will overwrite it with a new one, erasing any changes.", fn.Nm)},
+	ge.PromptChoice(gide.Prefs.Dialogs.AutoSave, "Autosave file Exists",
+		fmt.Sprintf("An auto-save file for file: %v exists -- open it in the other text view (you can then do Save As to replace current file)?  If you don't open it, the next change made will overwrite it with a new one, erasing any changes.", fn.Nm),
 		[]string{"Open", "Ignore and Overwrite"},
-		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-			switch sig {
+		func(choice int) {
+			switch choice {
 			case 0:
 				ge.NextViewFile(gi.FileName(fn.Buf.AutoSaveFilename()))
 			case 1:
@@ -667,9 +1054,16 @@ func (ge *GideView) OpenFileNode(fn *giv.FileNode) (bool, error) {
 	if fn.IsDir() {
 		return false, fmt.Errorf("cannot open directory: %v", fn.FPath)
 	}
-	giv.FileNodeHiStyle = gide.Prefs.HiStyle // must be set prior to OpenBuf
+	giv.FileNodeHiStyle = ge.HiStyle() // must be set prior to OpenBuf
 	nw, err := fn.OpenBuf()
 	if err == nil {
+		if nw {
+			delete(ge.FileEncs, fn.FPath)
+			delete(ge.FileCrypts, fn.FPath)
+			if !ge.DecryptBuf(fn.Buf) {
+				ge.DecodeBuf(fn.Buf)
+			}
+		}
 		ge.ConfigTextBuf(fn.Buf)
 		ge.OpenNodes.Add(fn)
 		fn.SetOpen()
@@ -683,19 +1077,63 @@ func (ge *GideView) ViewFileNode(tv *gide.TextView, vidx int, fn *giv.FileNode)
 	if fn.IsDir() {
 		return
 	}
+	if tv.Pinned && tv.Buf != nil && tv.Buf != fn.Buf {
+		tv, vidx = ge.UnpinnedTextView(vidx)
+		if tv.Pinned {
+			ge.SetStatus(fmt.Sprintf("Both views are pinned -- unpin one to open: %v", fn.FPath))
+			return
+		}
+	}
 	if tv.IsChanged() {
 		ge.SetStatus(fmt.Sprintf("Note: Changes not yet saved in file: %v", tv.Buf.Filename))
 	}
 	nw, err := ge.OpenFileNode(fn)
 	if err == nil {
+		if tv.Buf != fn.Buf {
+			ge.RememberFilePos(tv)
+		}
 		tv.SetBuf(fn.Buf)
+		tv.SetProseMode(gide.LangProseMode(fn.Info.Sup), &ge.Prefs.Editor)
+		ge.ApplyViewOpts(tv)
+		if nw {
+			ge.RestoreFilePos(tv)
+		}
+		if !gide.IsScratch(fn) {
+			ge.Prefs.RecentFiles.AddPath(string(fn.FPath), gide.RecentFilesMax)
+			ge.ClosedFiles.Remove(fn.FPath)
+		}
 		if nw {
 			ge.AutoSaveCheck(tv, vidx, fn)
+			gide.PluginsFileOpened(ge, fn)
 		} else {
 			fn.Buf.FileModCheck()
 		}
 		ge.SetActiveTextViewIdx(vidx)
+		if nw && gide.HasConflicts(gide.BufLines(fn.Buf)) {
+			ge.ResolveConflictsFileNode(fn)
+		}
+	}
+}
+
+// RememberFilePos remembers the cursor and scroll position of the file in
+// the text view in the project prefs, to restore when it is opened again,
+// e.g., in a later session
+func (ge *GideView) RememberFilePos(tv *gide.TextView) {
+	if tv == nil || tv.Buf == nil || tv.Buf.Filename == "" || strings.HasPrefix(string(tv.Buf.Filename), gide.ScratchPrefix) {
+		return
+	}
+	ge.Prefs.FilePos.Set(ge.Files.RelPath(tv.Buf.Filename), tv.FilePos())
+}
+
+// RestoreFilePos restores the remembered cursor and scroll position of the
+// file in the text view, if any -- returns false if none
+func (ge *GideView) RestoreFilePos(tv *gide.TextView) bool {
+	fp, ok := ge.Prefs.FilePos[ge.Files.RelPath(tv.Buf.Filename)]
+	if !ok {
+		return false
 	}
+	tv.RestoreFilePos(fp)
+	return true
 }
 
 // NextViewFileNode sets the next text view to view file in given node (opens
@@ -745,28 +1183,48 @@ func (ge *GideView) ViewFile(fnm gi.FileName) (*gide.TextView, int, bool) {
 		ge.SetActiveTextViewIdx(idx)
 		return tv, idx, ok
 	}
-	tv = ge.ActiveTextView()
-	idx = ge.ActiveTextViewIdx
+	tv, idx = ge.UnpinnedTextView(ge.ActiveTextViewIdx)
 	ge.ViewFileNode(tv, idx, fn)
 	return tv, idx, true
 }
 
-// LinkViewFileNode opens the file node in the 2nd textview, which is next to
-// the tabs where links are clicked, if it is not collapsed -- else 1st
-func (ge *GideView) LinkViewFileNode(fn *giv.FileNode) (*gide.TextView, int) {
-	if ge.PanelIsOpen(TextView2Idx) {
-		ge.SetActiveTextViewIdx(1)
-	} else {
-		ge.SetActiveTextViewIdx(0)
+// LinkTextViewIdx returns the index of the text view that opens the files
+// of links, per the LinkPanel project prefs -- by default the 2nd
+// textview, which is next to the tabs where links are clicked -- falling
+// back on the 1st if the chosen one is collapsed
+func (ge *GideView) LinkTextViewIdx() int {
+	tvs := make([]*gide.TextView, NTextViews)
+	open := make([]bool, NTextViews)
+	for i := range tvs {
+		tvs[i] = ge.TextViewByIndex(i)
+		open[i] = ge.PanelIsOpen(i + TextView1Idx)
+	}
+	idx := 1
+	switch ge.Prefs.LinkPanel {
+	case gide.LinkPanelLeastEdited:
+		idx = gide.LeastEdited(tvs, open)
+	case gide.LinkPanelOther:
+		idx = (ge.ActiveTextViewIdx + 1) % NTextViews
+	case gide.LinkPanelActive:
+		idx = ge.ActiveTextViewIdx
+	}
+	if idx < 0 || !open[idx] {
+		return 0
 	}
-	tv := ge.ActiveTextView()
-	idx := ge.ActiveTextViewIdx
+	return idx
+}
+
+// LinkViewFileNode opens the file node in the textview for links (see
+// LinkTextViewIdx), or the other one if it is pinned
+func (ge *GideView) LinkViewFileNode(fn *giv.FileNode) (*gide.TextView, int) {
+	tv, idx := ge.UnpinnedTextView(ge.LinkTextViewIdx())
+	ge.SetActiveTextViewIdx(idx)
 	ge.ViewFileNode(tv, idx, fn)
 	return tv, idx
 }
 
-// LinkViewFile opens the file in the 2nd textview, which is next to
-// the tabs where links are clicked, if it is not collapsed -- else 1st
+// LinkViewFile opens the file in the textview for links (see
+// LinkTextViewIdx), or the other one if it is pinned
 func (ge *GideView) LinkViewFile(fnm gi.FileName) (*gide.TextView, int, bool) {
 	fnk, ok := ge.Files.FindFile(string(fnm))
 	if !ok {
@@ -836,14 +1294,106 @@ func (ge *GideView) CloneActiveView() (*gide.TextView, int) {
 	return nil, -1
 }
 
+// ToggleTestSource opens the test file of the file in the active view in
+// its place, e.g., foo_test.go for foo.go, or the source file if it is a
+// test (see OpenTestSource)
+func (ge *GideView) ToggleTestSource() {
+	ge.OpenTestSource(false)
+}
+
+// TestSourceSideBySide opens the test file of the file in the active view
+// in the other view, e.g., foo_test.go for foo.go, or the source file if it
+// is a test, so they are side-by-side (see OpenTestSource)
+func (ge *GideView) TestSourceSideBySide() {
+	ge.OpenTestSource(true)
+}
+
+// OpenTestSource opens the test file of the file in the active view, or the
+// source file if it is a test, per gide.TestPatterns -- a missing test file
+// is made from the template for it -- in the active view, or if sideBySide,
+// in the other one, opening it if collapsed
+func (ge *GideView) OpenTestSource(sideBySide bool) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	fnm := ge.Files.RelPath(tv.Buf.Filename)
+	pair, exists, isTest := gide.TestSourcePair(string(tv.Buf.Filename))
+	if pair == "" {
+		ge.SetStatus("No test file naming is known for: " + fnm)
+		return
+	}
+	pnm := ge.Files.RelPath(gi.FileName(pair))
+	if !exists {
+		if isTest {
+			ge.SetStatus("Source file not found for: " + fnm + ", e.g., " + pnm)
+			return
+		}
+		if err := gide.NewFileFromTemplate(pair, "", ge.Nm); err != nil {
+			gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Make File", Prompt: fmt.Sprintf("Could not make new test file at: %v, err: %v", pair, err)}, gi.AddOk, gi.NoCancel, nil, nil)
+			return
+		}
+		ge.Files.UpdateNewFile(pair)
+	}
+	var ok bool
+	if sideBySide {
+		ge.OpenTextViewPanels()
+		_, _, ok = ge.NextViewFile(gi.FileName(pair))
+	} else {
+		_, _, ok = ge.ViewFile(gi.FileName(pair))
+	}
+	if !ok {
+		ge.SetStatus("Could not open: " + pnm)
+	} else if !exists {
+		ge.SetStatus("Made new test file: " + pnm)
+	}
+}
+
+// OpenTextViewPanels opens the text view panels if either is collapsed,
+// splitting the space of the open one, if any, between them
+func (ge *GideView) OpenTextViewPanels() {
+	if ge.PanelIsOpen(TextView1Idx) && ge.PanelIsOpen(TextView2Idx) {
+		return
+	}
+	sv := ge.SplitView()
+	splits := append([]float32{}, sv.Splits...)
+	tot := splits[TextView1Idx] + splits[TextView2Idx]
+	if tot < 0.2 {
+		tot = 0.6
+	}
+	splits[TextView1Idx], splits[TextView2Idx] = tot/2, tot/2
+	sv.SetSplitsAction(splits...)
+}
+
+// ToggleSyncScroll toggles synchronized scrolling of the two text views:
+// when either is scrolled, the other is scrolled by the same number of
+// lines, keeping the offset between them when it was turned on -- e.g., for
+// a file and its clone (see CloneActiveView) at two points, or two related
+// files
+func (ge *GideView) ToggleSyncScroll() {
+	tv0, tv1 := ge.TextViewByIndex(0), ge.TextViewByIndex(1)
+	if tv0.Sync.View != nil {
+		tv0.SetSyncScroll(nil)
+		ge.SetStatus("Sync scrolling: off")
+		return
+	}
+	tv0.SetSyncScroll(tv1)
+	ge.SetStatus(fmt.Sprintf("Sync scrolling: on, offset %v lines", tv0.Sync.Off))
+}
+
 // SaveAllOpenNodes saves all of the open filenodes to their current file names
 func (ge *GideView) SaveAllOpenNodes() {
 	for _, ond := range ge.OpenNodes {
-		if ond.Buf == nil {
+		if ond.Buf == nil || gide.IsScratch(ond) {
 			continue
 		}
 		if ond.Buf.IsChanged() {
-			ond.Buf.Save()
+			if err := ge.SaveBuf(ond.Buf); err != nil {
+				ge.SetStatus(fmt.Sprintf("File NOT Saved: %v: %v", ge.Files.RelPath(ond.FPath), err))
+				continue
+			}
+			ge.FileSaved(ond.FPath)
+			gide.UpdateSavedFile(&ge.Files, ond.Buf)
 			ge.RunPostCmdsFileNode(ond)
 		}
 	}
@@ -899,15 +1449,444 @@ func (ge *GideView) DiffFileNode(fnm gi.FileName, fn *giv.FileNode) {
 	if fn.Buf == nil {
 		return
 	}
-	dif := fn1.Buf.DiffBufsUnified(fn.Buf, 3)
-	cbuf, _, _ := ge.RecycleCmdTab("Diffs", true, true)
-	cbuf.SetText(dif)
-	cbuf.AutoScrollViews()
+	ge.ShowDiffs(fn1.Buf.DiffBufsUnified(fn.Buf, 3), fn1.Nm+" vs "+fn.Nm)
+}
+
+// ShowDiffs shows the given unified diff in the Diffs tab, or that there are
+// no differences between the given things compared, in the status bar, if
+// it is empty
+func (ge *GideView) ShowDiffs(dif []byte, what string) {
+	if len(dif) == 0 {
+		ge.SetStatus("No differences: " + what)
+		return
+	}
+	cbuf, _, _ := ge.RecycleCmdTab("Diffs", true, true)
+	cbuf.AppendTextMarkup(dif, bytes.Join(gide.DiffMarkup(dif), []byte("\n")), false, true)
+	cbuf.AutoScrollViews()
+}
+
+// DiffBufSaved shows the unsaved changes of the active buffer: the
+// differences from its file on disk to the buffer, in the Diffs tab
+func (ge *GideView) DiffBufSaved() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil || tv.Buf.Filename == "" || strings.HasPrefix(string(tv.Buf.Filename), gide.ScratchPrefix) {
+		return false
+	}
+	b, err := ioutil.ReadFile(string(tv.Buf.Filename))
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not read saved file: %v", err))
+		return false
+	}
+	rp := filepath.ToSlash(ge.Files.RelPath(tv.Buf.Filename))
+	ge.ShowDiffs(gide.DiffTextUnified("a/"+rp, b, "b/"+rp, tv.Buf.LinesToBytesCopy()), rp+" buffer vs saved")
+	return true
+}
+
+// DiffClipboard shows the differences from the selection in the active
+// view, or its whole buffer if there is no selection, to the text on the
+// clipboard, in the Diffs tab
+func (ge *GideView) DiffClipboard() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	md := oswin.TheApp.ClipBoard(ge.Viewport.Win.OSWin).Read([]string{filecat.TextPlain})
+	if md == nil {
+		ge.SetStatus("No text on the clipboard")
+		return false
+	}
+	clip := md.Text(filecat.TextPlain)
+	nm := ge.Files.RelPath(tv.Buf.Filename)
+	txt := tv.Buf.LinesToBytesCopy()
+	if sel := tv.Selection(); sel != nil {
+		nm += " (selection)"
+		txt = sel.ToBytes()
+	}
+	ge.ShowDiffs(gide.DiffTextUnified(nm, txt, "clipboard", []byte(clip)), nm+" vs clipboard")
+	return true
+}
+
+// DiffOpenNode shows the differences from the buffer in the active view to
+// the open buffer of given name (see OpenNodes), in the Diffs tab
+func (ge *GideView) DiffOpenNode(name string) bool {
+	tv := ge.ActiveTextView()
+	on := ge.OpenNodes.ByStringName(name)
+	if tv.Buf == nil || on == nil || on.Buf == nil {
+		return false
+	}
+	anm := ge.Files.RelPath(tv.Buf.Filename)
+	bnm := ge.Files.RelPath(on.Buf.Filename)
+	ge.ShowDiffs(gide.DiffTextUnified(anm, tv.Buf.LinesToBytesCopy(), bnm, on.Buf.LinesToBytesCopy()), anm+" vs "+bnm)
+	return true
+}
+
+// ResolveConflicts opens the merge conflicts in the buffer in the active
+// view in the Merge tab, a three-pane merge view to resolve them
+func (ge *GideView) ResolveConflicts() {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return
+	}
+	fn, _, ok := ge.OpenNodeForTextView(tv)
+	if !ok {
+		return
+	}
+	ge.ResolveConflictsFileNode(fn)
+}
+
+// ResolveConflictsFileNode opens the merge conflicts in the buffer of given
+// file node in the Merge tab, with our side, the base and their side of
+// each conflict -- it is opened when a file with conflicts is opened
+func (ge *GideView) ResolveConflictsFileNode(fn *giv.FileNode) {
+	mvi := ge.RecycleMainTab("Merge", gide.KiT_MergeView, true) // sel
+	mv := mvi.Embed(gide.KiT_MergeView).(*gide.MergeView)
+	mv.Config(ge, fn)
+	if len(mv.Conflicts) == 0 {
+		ge.SetStatus(fmt.Sprintf("No merge conflicts in: %v", fn.Nm))
+		return
+	}
+	ge.SetStatus(fmt.Sprintf("%v merge conflicts in: %v", len(mv.Conflicts), fn.Nm))
+}
+
+// SaveDiffsPatch saves the diffs in the Diffs tab as a patch file, which
+// can be applied with Apply Patch, or git apply -- .patch is added to a
+// file name without an extension
+func (ge *GideView) SaveDiffsPatch(filename gi.FileName) bool {
+	buf, ok := ge.CmdBufs["Diffs"]
+	if !ok || len(bytes.TrimSpace(buf.LinesToBytesCopy())) == 0 {
+		ge.SetStatus("No diffs to save -- show some diffs first")
+		return false
+	}
+	fnm := string(filename)
+	if filepath.Ext(fnm) == "" {
+		fnm += ".patch"
+	}
+	txt := append(bytes.TrimRight(buf.LinesToBytesCopy(), "\n"), '\n')
+	if err := ioutil.WriteFile(fnm, txt, 0644); err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not Save Patch", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return false
+	}
+	ge.Files.UpdateNewFile(fnm)
+	ge.SetStatus(fmt.Sprintf("Saved diffs as patch: %v", fnm))
+	return true
+}
+
+// ApplyPatch applies the patch in given file, e.g., saved by Save Diffs As
+// Patch, or written by git diff, to the project (see ApplyPatchText)
+func (ge *GideView) ApplyPatch(filename gi.FileName) bool {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not read patch: %v", err))
+		return false
+	}
+	return ge.ApplyPatchText(ge.Files.RelPath(filename), b)
+}
+
+// ApplyPatchClipboard applies the patch on the clipboard to the project
+// (see ApplyPatchText)
+func (ge *GideView) ApplyPatchClipboard() bool {
+	md := oswin.TheApp.ClipBoard(ge.Viewport.Win.OSWin).Read([]string{filecat.TextPlain})
+	if md == nil {
+		ge.SetStatus("No text on the clipboard")
+		return false
+	}
+	return ge.ApplyPatchText("clipboard", []byte(md.Text(filecat.TextPlain)))
+}
+
+// ApplyPatchText previews the patch of given name in the Apply Patch tab,
+// listing the files it changes and whether it applies to each, and then
+// applies it if confirmed, and it applies to all of them: a file whose open
+// buffer has unsaved changes is patched in the buffer, and any other file
+// is patched on disk, and its open buffer reverted -- it is undone as one
+// operation (see Operations)
+func (ge *GideView) ApplyPatchText(name string, patch []byte) bool {
+	pfs, err := gide.ParsePatch(patch)
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not parse patch: %v: %v", name, err))
+		return false
+	}
+	type patchRes struct {
+		pf   *gide.PatchFile
+		path string
+		tb   *giv.TextBuf // open buffer with unsaved changes, which is patched
+		res  []byte
+		err  error
+	}
+	root := string(ge.ProjRoot)
+	prs := make([]*patchRes, len(pfs))
+	nerr := 0
+	for i, pf := range pfs {
+		pr := &patchRes{pf: pf, path: pf.Path(root)}
+		prs[i] = pr
+		if fnk, ok := ge.Files.FindFile(pr.path); ok {
+			if fn := fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode); fn.Buf != nil && fn.Buf.IsChanged() {
+				pr.tb = fn.Buf
+			}
+		}
+		switch {
+		case pr.tb != nil:
+			var rlns []string
+			if rlns, pr.err = pf.Apply(gide.BufLines(pr.tb)); pr.err == nil {
+				pr.res = []byte(strings.Join(rlns, "\n"))
+			}
+		case pf.IsNew():
+			if _, err := os.Stat(pr.path); err == nil {
+				pr.err = fmt.Errorf("file to create already exists")
+			} else {
+				pr.res, pr.err = pf.ApplyText(nil)
+			}
+		default:
+			var txt []byte
+			if txt, pr.err = ioutil.ReadFile(pr.path); pr.err == nil {
+				pr.res, pr.err = pf.ApplyText(txt)
+			}
+		}
+		if pr.err != nil {
+			nerr++
+		}
+	}
+	hstr := fmt.Sprintf("Apply Patch: %v: %v files", name, len(prs))
+	lns := []string{hstr, ""}
+	mus := []string{"<b>" + html.EscapeString(hstr) + "</b>", ""}
+	for _, pr := range prs {
+		add, del := pr.pf.Stats()
+		st := "ok"
+		switch {
+		case pr.err != nil:
+			st = pr.err.Error()
+		case pr.pf.IsNew():
+			st = "new file"
+		case pr.pf.IsDelete():
+			st = "delete file"
+		case pr.tb != nil:
+			st = "ok, in unsaved buffer"
+		}
+		cols := fmt.Sprintf("+%-5v -%-5v ", add, del)
+		rp := ge.Files.RelPath(gi.FileName(pr.path))
+		lns = append(lns, cols+rp+": "+st)
+		mu := html.EscapeString(cols) + fmt.Sprintf(`<a href="file:///%v">%v</a>: `, pr.path, html.EscapeString(rp))
+		if pr.err != nil {
+			mu += gide.ErrorSpan(st)
+		} else {
+			mu += html.EscapeString(st)
+		}
+		mus = append(mus, mu)
+	}
+	buf, _, _ := ge.RecycleCmdTab("Apply Patch", true, true)
+	ge.AppendTabLines(buf, lns, mus)
+	ge.FocusOnPanel(MainTabsIdx)
+	if nerr > 0 {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Patch Does Not Apply", Prompt: fmt.Sprintf("The patch: %v does not apply to %v of its %v files, and has not been applied -- see the Apply Patch tab", name, nerr, len(prs))}, gi.AddOk, gi.NoCancel, nil, nil)
+		return false
+	}
+	gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "Apply Patch?",
+		Prompt: fmt.Sprintf("Apply the patch: %v to the %v files listed in the Apply Patch tab?", name, len(prs))},
+		[]string{"Apply", "Cancel"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != 0 {
+				return
+			}
+			op := gide.NewProjOp("Apply Patch " + name)
+			var errs []string
+			updt := ge.VPort().Win.UpdateStart()
+			for _, pr := range prs {
+				if pr.tb != nil {
+					op.AddBuf(pr.tb)
+					gide.ReplaceChangedLines(pr.tb, strings.Split(string(pr.res), "\n"))
+					continue
+				}
+				op.AddFile(pr.path) // no undo for a new file
+				var err error
+				if pr.pf.IsDelete() {
+					err = os.Remove(pr.path)
+				} else {
+					if pr.pf.IsNew() {
+						os.MkdirAll(filepath.Dir(pr.path), 0755)
+					}
+					err = ioutil.WriteFile(pr.path, pr.res, 0644)
+				}
+				if err != nil {
+					errs = append(errs, err.Error())
+					continue
+				}
+				if fnk, ok := ge.Files.FindFile(pr.path); ok && !pr.pf.IsDelete() {
+					if fn := fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode); fn.Buf != nil {
+						fn.Buf.Revert()
+					}
+				} else {
+					ge.Files.UpdateNewFile(pr.path)
+				}
+			}
+			ge.VPort().Win.UpdateEnd(updt)
+			if len(op.Files) > 0 {
+				ge.AddProjOp(op)
+			}
+			if len(errs) > 0 {
+				gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Patch Applied with Errors", Prompt: strings.Join(errs, "\n")}, gi.AddOk, gi.NoCancel, nil, nil)
+				return
+			}
+			ge.SetStatus(fmt.Sprintf("Applied patch: %v to %v files", name, len(prs)))
+		})
+	return true
 }
 
 //////////////////////////////////////////////////////////////////////////////////////
 //   Links
 
+// AddProjOp adds a project-level operation that changed multiple files to
+// the Operations history, where it can be undone
+func (ge *GideView) AddProjOp(op *gide.ProjOp) {
+	ge.Ops.Add(op)
+	ge.SetStatus(fmt.Sprintf("%v: %v files changed -- Edit / Undo Operation restores them", op.Name, len(op.Files)))
+}
+
+// ProjStats shows the statistics of the project files in the Stats tab in
+// the VisTabs: lines of code per language, file counts, the largest files,
+// the most changed files in the recent VCS history, and a treemap of the
+// directory sizes
+func (ge *GideView) ProjStats() {
+	ge.SetStatus("Computing project stats...")
+	ps := ge.Project.Stats()
+	svi := ge.RecycleVisTab("Stats", gide.KiT_StatsView, true)
+	sv := svi.Embed(gide.KiT_StatsView).(*gide.StatsView)
+	sv.Config(ge, ps)
+	ge.SetStatus(fmt.Sprintf("Project stats: %v files, %v lines", ps.Files, ps.Lines))
+}
+
+// DepGraph shows the dependency graph of the project, e.g., the imports
+// among the packages of a Go module, in the Deps tab in the VisTabs, from
+// the DepGraphProvider for the main language of the project -- cycles are
+// highlighted, and clicking on a node selects its directory in the file tree
+func (ge *GideView) DepGraph() {
+	lang := ge.Prefs.MainLang
+	dp, ok := gide.DepGraphProviders[lang]
+	if !ok {
+		ge.SetStatus(fmt.Sprintf("No dependency graph for projects of main language: %v -- set in Project Prefs", lang))
+		return
+	}
+	ge.SetStatus("Getting dependency graph...")
+	dg, err := dp(string(ge.ProjRoot))
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Dependency graph: %v", err))
+		return
+	}
+	dvi := ge.RecycleVisTab("Deps", gide.KiT_DepGraphView, true)
+	dv := dvi.Embed(gide.KiT_DepGraphView).(*gide.DepGraphView)
+	dv.Config(ge, lang, dg)
+	ge.SetStatus(fmt.Sprintf("Dependency graph: %v nodes, %v cycles", len(dg.Nodes), len(dg.Cycles)))
+}
+
+// SelectFileInTree opens the directories above the file or directory at
+// given path in the file tree, and selects it and scrolls to it
+func (ge *GideView) SelectFileInTree(fpath gi.FileName) bool {
+	fn, ok := ge.Files.FindFile(string(fpath))
+	if !ok {
+		return false
+	}
+	var tvn *giv.TreeView
+	ge.FileTree().FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if tvn != nil {
+			return false
+		}
+		if tv, ok := k.Embed(giv.KiT_TreeView).(*giv.TreeView); ok && tv.SrcNode == fn.This() {
+			tvn = tv
+			return false
+		}
+		return true
+	})
+	if tvn == nil {
+		return false
+	}
+	tvn.SelectAction(mouse.SelectOne)
+	tvn.ScrollToMe()
+	return true
+}
+
+// Operations shows the history of project-level operations that changed
+// multiple files, e.g., Replace All, in the Operations tab, with links to
+// undo each of them, restoring all the buffers and files it changed, and to
+// open the files
+func (ge *GideView) Operations() {
+	obuf, otv, _ := ge.RecycleCmdTab("Operations", true, true)
+	outlns := make([][]byte, 0, len(ge.Ops)+1)
+	outmus := make([][]byte, 0, len(ge.Ops)+1)
+	lstr := fmt.Sprintf("Operations: %v", len(ge.Ops))
+	outlns = append(outlns, []byte(lstr))
+	outmus = append(outmus, []byte("<b>"+html.EscapeString(lstr)+"</b>"))
+	for i := len(ge.Ops) - 1; i >= 0; i-- {
+		op := ge.Ops[i]
+		sum := op.Summary()
+		if op.Undone {
+			outlns = append(outlns, []byte(sum))
+			outmus = append(outmus, []byte(html.EscapeString(sum)))
+		} else {
+			outlns = append(outlns, []byte(sum+": undo"))
+			outmus = append(outmus, []byte(fmt.Sprintf(`%v: <a href="op:///%v#undo">undo</a>`, html.EscapeString(sum), op.ID)))
+		}
+		for _, of := range op.Files {
+			rp := ge.Files.RelPath(gi.FileName(of.Path))
+			outlns = append(outlns, []byte("    "+rp))
+			outmus = append(outmus, []byte(fmt.Sprintf(`    <a href="file:///%v">%v</a>`, of.Path, html.EscapeString(rp))))
+		}
+	}
+	obuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	otv.CursorStartDoc()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// OpenOpURL opens given op:/// url from the Operations tab: the #undo
+// fragment undoes the operation of that id
+func (ge *GideView) OpenOpURL(ur string) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("GideView OpenOpURL parse err: %v\n", err)
+		return false
+	}
+	id, err := strconv.Atoi(up.Path[1:]) // has double //
+	if err != nil {
+		return false
+	}
+	op := ge.Ops.ByID(id)
+	if op == nil {
+		return false
+	}
+	return ge.UndoProjOp(op)
+}
+
+// UndoLastOp undoes the most recent project-level operation that changed
+// multiple files, e.g., Replace All, that has not been undone, restoring all
+// the buffers and files it changed
+func (ge *GideView) UndoLastOp() bool {
+	op := ge.Ops.LastDone()
+	if op == nil {
+		ge.SetStatus("No operation to undo")
+		return false
+	}
+	return ge.UndoProjOp(op)
+}
+
+// UndoProjOp undoes the project-level operation, restoring all the buffers
+// and files it changed, and updates the Operations tab if it is open
+func (ge *GideView) UndoProjOp(op *gide.ProjOp) bool {
+	updt := ge.VPort().Win.UpdateStart()
+	err := op.Undo(func(fpath string) *giv.TextBuf {
+		if fnk, ok := ge.Files.FindFile(fpath); ok {
+			return fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode).Buf
+		}
+		return nil
+	})
+	ge.VPort().Win.UpdateEnd(updt)
+	if ge.MainTabByName("Operations") != nil {
+		ge.Operations()
+	}
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Undo Operation Incomplete", Prompt: fmt.Sprintf("Undo of %v: %v", op.Name, err)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return false
+	}
+	ge.SetStatus(fmt.Sprintf("Undone: %v: %v files restored", op.Name, len(op.Files)))
+	return true
+}
+
 // TextLinkHandler is the GideView handler for text links -- preferred one b/c
 // directly connects to correct GideView project
 func TextLinkHandler(tl gi.TextLink) bool {
@@ -924,6 +1903,26 @@ func TextLinkHandler(tl gi.TextLink) bool {
 			ge.OpenSpellURL(ur, ftv)
 		case strings.HasPrefix(ur, "file:///"):
 			ge.OpenFileURL(ur, ftv)
+		case strings.HasPrefix(ur, "doc:///"):
+			ge.OpenDocURL(ur)
+		case strings.HasPrefix(ur, "history:///"):
+			ge.OpenHistoryURL(ur)
+		case strings.HasPrefix(ur, "release:///"):
+			ge.OpenReleaseURL(ur)
+		case strings.HasPrefix(ur, "op:///"):
+			ge.OpenOpURL(ur)
+		case strings.HasPrefix(ur, "clip:///"):
+			ge.OpenClipURL(ur)
+		case strings.HasPrefix(ur, "inspect:///"):
+			ge.OpenInspectURL(ur)
+		case strings.HasPrefix(ur, "notice:///"):
+			ge.OpenNoticeURL(ur)
+		case strings.HasPrefix(ur, "calls:///"):
+			ge.OpenCallsURL(ur)
+		case strings.HasPrefix(ur, "job:///"):
+			ge.OpenJobURL(ur)
+		case strings.HasPrefix(ur, "goto:///"):
+			ge.OpenGotoURL(ur)
 		default:
 			oswin.TheApp.OpenURL(ur)
 		}
@@ -999,6 +1998,243 @@ func (ge *GideView) NChangedFiles() int {
 	return ge.OpenNodes.NChanged()
 }
 
+// FileSaved is called after a file is saved in gide: it adds a snapshot to
+// the local history, and reloads any live-reload browser pages
+func (ge *GideView) FileSaved(fpath gi.FileName) {
+	gide.SaveHistory(fpath)
+	if ge.FileServer != nil {
+		ge.FileServer.ReloadForFile(string(fpath))
+	}
+	if ge.CmdWatch != nil {
+		go ge.CmdWatch.Check()
+	}
+	gide.PluginsFileSaved(ge, fpath)
+}
+
+// ServeDir starts a local static file server for the given directory (the
+// project root if empty) on the given port, stopping any prior one -- if the
+// port is in use, a free one is used.  Useful for previewing static sites
+// built from the project.  With live reload, html pages are reloaded in the
+// browser whenever html, css or js files in the directory are saved.
+func (ge *GideView) ServeDir(dir gi.FileName, port int, liveReload, openBrowser bool) {
+	ge.StopServeDir()
+	if dir == "" {
+		dir = ge.ProjRoot
+	}
+	if port <= 0 {
+		port = gide.DefaultServePort
+	}
+	fs, err := gide.StartFileServer(string(dir), port, liveReload)
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not serve directory: %v: %v", dir, err))
+		return
+	}
+	ge.FileServer = fs
+	ge.RunningJobs.Add("Serve", fs.Dir, fs.URL(), ge.StopServeDir)
+	ge.Prefs.ServeDir = dir
+	ge.Prefs.ServePort = fs.Port
+	ge.Prefs.ServeReload = liveReload
+	ge.SetStatus(fmt.Sprintf("Serving: %v at: %v", dir, fs.URL()))
+	if openBrowser {
+		oswin.TheApp.OpenURL(fs.URL())
+	}
+}
+
+// StopServeDir stops the local file server started by ServeDir, if running
+func (ge *GideView) StopServeDir() {
+	if ge.FileServer == nil {
+		return
+	}
+	ge.FileServer.Stop()
+	ge.RunningJobs.FinishByName("Serve", ge.FileServer.Dir)
+	ge.SetStatus(fmt.Sprintf("Stopped serving: %v", ge.FileServer.Dir))
+	ge.FileServer = nil
+}
+
+// ToggleWatchCmds toggles watch mode: the commands with Watch globs are
+// re-run, in their output tabs, whenever files in the project matching the
+// globs change -- for auto-rebuild, auto-test and live-reload loops
+func (ge *GideView) ToggleWatchCmds() {
+	if ge.CmdWatch != nil {
+		ge.StopWatchCmds()
+		return
+	}
+	nw := len(gide.WatchedCmds())
+	if nw == 0 {
+		ge.SetStatus("No commands have Watch globs -- set them in Edit Cmds")
+		return
+	}
+	root := string(ge.ProjRoot)
+	ge.CmdWatch = gide.NewCmdWatch(root, func(cmdNm gide.CmdName) {
+		ge.ExecCmdName(cmdNm, false, true)
+	})
+	ge.RunningJobs.Add("Watch", root, fmt.Sprintf("re-running %v command(s) when their files change", nw), ge.StopWatchCmds)
+	ge.SetStatus(fmt.Sprintf("Watch mode on: %v command(s)", nw))
+}
+
+// StopWatchCmds stops watch mode, if on
+func (ge *GideView) StopWatchCmds() {
+	if ge.CmdWatch == nil {
+		return
+	}
+	ge.CmdWatch.Stop()
+	ge.RunningJobs.FinishByName("Watch", ge.CmdWatch.Root)
+	ge.CmdWatch = nil
+	ge.SetStatus("Watch mode off")
+}
+
+// StartBroadcast starts broadcasting the active view read-only over the
+// network on the given port, stopping any prior broadcast: the file, cursor
+// and visible lines can be followed by another gide with Follow Broadcast,
+// or in a browser -- the share urls, with the access token, are shown in
+// the Broadcast tab.  Nothing is sent while the project is locked, nor the
+// text of encrypted files.
+func (ge *GideView) StartBroadcast(port int) {
+	ge.StopBroadcast()
+	if port <= 0 {
+		port = gide.DefaultBroadcastPort
+	}
+	bc, err := gide.StartBroadcast(port)
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not start broadcast: %v", err))
+		return
+	}
+	ge.Broadcast = bc
+	ge.RunningJobs.Add("Broadcast", fmt.Sprintf("port %v", bc.Port), "broadcasting the active view", ge.StopBroadcast)
+	go ge.BroadcastLoop(bc)
+	buf, _, _ := ge.RecycleCmdTab("Broadcast", true, true)
+	hstr := fmt.Sprintf("Broadcasting the active view on port %v -- share one of these urls to follow it, in a browser or with Follow Broadcast:", bc.Port)
+	lns := []string{hstr, ""}
+	mus := []string{"<b>" + html.EscapeString(hstr) + "</b>", ""}
+	for _, u := range bc.URLs() {
+		lns = append(lns, u)
+		mus = append(mus, fmt.Sprintf(`<a href="%v">%v</a>`, u, html.EscapeString(u)))
+	}
+	buf.AppendTextMarkup([]byte(strings.Join(lns, "\n")+"\n"), []byte(strings.Join(mus, "\n")+"\n"), false, true)
+	ge.SetStatus(fmt.Sprintf("Broadcasting on port: %v", bc.Port))
+}
+
+// StopBroadcast stops the broadcast started by StartBroadcast, if running
+func (ge *GideView) StopBroadcast() {
+	if ge.Broadcast == nil {
+		return
+	}
+	ge.Broadcast.Stop()
+	ge.RunningJobs.FinishByName("Broadcast", fmt.Sprintf("port %v", ge.Broadcast.Port))
+	ge.Broadcast = nil
+	ge.SetStatus("Stopped broadcasting")
+}
+
+// BroadcastLoop updates the broadcast state from the active view every
+// BroadcastInterval, until the broadcast is stopped
+func (ge *GideView) BroadcastLoop(bc *gide.Broadcaster) {
+	tick := time.NewTicker(gide.BroadcastInterval)
+	defer tick.Stop()
+	for range tick.C {
+		if ge.Broadcast != bc {
+			return
+		}
+		ge.BroadcastUpdate(bc)
+	}
+}
+
+// BroadcastUpdate updates the broadcast state from the active view
+func (ge *GideView) BroadcastUpdate(bc *gide.Broadcaster) {
+	if ge.Locked {
+		bc.Update("", nil, 0, 0, 0, 0)
+		return
+	}
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.NLines == 0 {
+		bc.Update("", nil, 0, 0, 0, 0)
+		return
+	}
+	fnm := ge.Files.RelPath(tv.Buf.Filename)
+	if _, ok := ge.FileCrypts[tv.Buf.Filename]; ok {
+		bc.Update(fnm, []byte("(encrypted file -- not broadcast)\n"), 0, 0, 0, 0)
+		return
+	}
+	st := tv.FirstVisibleLine(0)
+	bc.Update(fnm, tv.Buf.LinesToBytesCopy(), tv.CursorPos.Ln, tv.CursorPos.Ch, st, tv.LastVisibleLine(st))
+}
+
+// FollowBroadcast follows a broadcast from another gide, given one of its
+// share urls: the file being viewed is shown read-only in the Follow tab,
+// scrolled along with the broadcast view, with its cursor line highlighted
+func (ge *GideView) FollowBroadcast(url string) {
+	ge.StopFollow()
+	ge.FollowURL = url
+	buf, tv, _ := ge.RecycleCmdTab("Follow", true, true)
+	stop := make(chan struct{})
+	ge.FollowStop = stop
+	ge.RunningJobs.Add("Follow", url, "following a broadcast", ge.StopFollow)
+	go ge.FollowLoop(url, buf, tv, stop)
+	ge.SetStatus(fmt.Sprintf("Following: %v", url))
+}
+
+// StopFollow stops following the broadcast started by FollowBroadcast
+func (ge *GideView) StopFollow() {
+	if ge.FollowStop == nil {
+		return
+	}
+	close(ge.FollowStop)
+	ge.FollowStop = nil
+	ge.SetStatus("Stopped following")
+}
+
+// FollowLoop polls the broadcast for changes and shows them, until stopped
+// or the broadcast cannot be reached after a few retries
+func (ge *GideView) FollowLoop(url string, buf *giv.TextBuf, tv *giv.TextView, stop chan struct{}) {
+	defer ge.RunningJobs.FinishByName("Follow", url)
+	vers, tvers, fails := 0, 0, 0
+	file := ""
+	for {
+		st, err := gide.FetchFollowState(url, vers, tvers)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if err != nil {
+			fails++
+			if fails >= 5 {
+				ge.SetStatus(fmt.Sprintf("Stopped following: %v", err))
+				if ge.FollowStop == stop {
+					ge.FollowStop = nil
+				}
+				return
+			}
+			time.Sleep(2 * time.Second)
+			continue
+		}
+		fails = 0
+		ge.FollowShow(st, st.TextVers != tvers, buf, tv)
+		if st.File != file {
+			file = st.File
+			ge.SetStatus(fmt.Sprintf("Following: %v", file))
+		}
+		vers, tvers = st.Version, st.TextVers
+	}
+}
+
+// FollowShow shows the followed broadcast state in the Follow tab: the text
+// if it changed, scrolled to the broadcast view, with the cursor line
+// highlighted
+func (ge *GideView) FollowShow(st *gide.FollowState, newText bool, buf *giv.TextBuf, tv *giv.TextView) {
+	updt := ge.VPort().Win.UpdateStart()
+	if newText {
+		buf.SetText([]byte(st.Text))
+	}
+	if st.Line < buf.NumLines() {
+		cp := giv.TextPos{Ln: st.Line, Ch: st.Col}
+		tv.SetCursor(cp)
+		tv.Highlights = []giv.TextRegion{{Start: giv.TextPos{Ln: st.Line}, End: giv.TextPos{Ln: st.Line, Ch: len(buf.Line(st.Line))}}}
+		tv.ScrollToTop(int(tv.CharStartPos(giv.TextPos{Ln: st.Top}).Y))
+	}
+	tv.UpdateSig()
+	ge.VPort().Win.UpdateEnd(updt)
+}
+
 // CloseWindowReq is called when user tries to close window -- we
 // automatically save the project if it already exists (no harm), and prompt
 // to save open files -- if this returns true, then it is OK to close --
@@ -1007,6 +2243,14 @@ func (ge *GideView) CloseWindowReq() bool {
 	ge.SaveProjIfExists(false) // don't prompt here, as we will do it now..
 	nch := ge.NChangedFiles()
 	if nch == 0 {
+		ge.StopJournal()
+		ge.StopIdleLock()
+		ge.StopServeDir()
+		ge.StopWatchCmds()
+		ge.StopBroadcast()
+		ge.StopFollow()
+		ge.StopKeyDisplay()
+		ge.StopAudio()
 		return true
 	}
 	gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "Close Project: There are Unsaved Files",
@@ -1019,16 +2263,23 @@ func (ge *GideView) CloseWindowReq() bool {
 			case 1:
 				ge.SaveAllOpenNodes()
 			case 2:
+				ge.StopJournal()
+				ge.StopIdleLock()
+				ge.StopServeDir()
+				ge.StopWatchCmds()
+				ge.StopBroadcast()
+				ge.StopFollow()
+				ge.StopKeyDisplay()
+				ge.StopAudio()
 				ge.ParentWindow().OSWin.Close() // will not be prompted again!
 			}
 		})
 	return false // not yet
 }
 
-// QuitReq is called when user tries to quit the app -- we go through all open
-// main windows and look for gide windows and call their CloseWindowReq
-// functions!
-func QuitReq() bool {
+// GideViews returns the GideViews of all the open gide project windows
+func GideViews() []*GideView {
+	var ges []*GideView
 	for _, win := range gi.MainWindows {
 		if !strings.HasPrefix(win.Nm, "gide-") {
 			continue
@@ -1041,7 +2292,28 @@ func QuitReq() bool {
 		if gek == nil {
 			continue
 		}
-		ge := gek.Embed(KiT_GideView).(*GideView)
+		ges = append(ges, gek.Embed(KiT_GideView).(*GideView))
+	}
+	return ges
+}
+
+// QuitReq is called when user tries to quit the app -- we go through all open
+// main windows and look for gide windows and call their CloseWindowReq
+// functions!  The open projects are first recorded, for RestoreProjs, and
+// the clipboard history is saved if the SaveClipHist pref is set.
+func QuitReq() bool {
+	ges := GideViews()
+	var pps gi.FilePaths
+	for _, ge := range ges {
+		if pp := ge.ProjPath(); pp != "" {
+			pps = append(pps, pp)
+		}
+	}
+	gide.SaveOpenProjs(pps)
+	if gide.Prefs.SaveClipHist {
+		gide.SaveClipHist()
+	}
+	for _, ge := range ges {
 		if !ge.CloseWindowReq() {
 			return false
 		}
@@ -1049,24 +2321,237 @@ func QuitReq() bool {
 	return true
 }
 
-//////////////////////////////////////////////////////////////////////////////////////
-//   Panels
-
-// PanelIsOpen returns true if the given panel has not been collapsed and is avail
-// and visible for displaying something
-func (ge *GideView) PanelIsOpen(panel int) bool {
-	sv := ge.SplitView()
-	if panel < 0 || panel >= len(sv.Kids) {
-		return false
-	}
-	if sv.Splits[panel] <= 0.01 {
-		return false
+// ProjPath returns the path for reopening this project: the project file if
+// it has been saved, else the project root directory
+func (ge *GideView) ProjPath() string {
+	if ge.ProjFilename != "" {
+		if _, err := os.Stat(string(ge.ProjFilename)); err == nil {
+			return string(ge.ProjFilename)
+		}
 	}
-	return true
+	return string(ge.ProjRoot)
 }
 
-// CurPanel returns the splitter panel that currently has keyboard focus
-func (ge *GideView) CurPanel() int {
+// RestoreProjs opens windows for all the projects that were open when gide
+// last quit, returning false if there were none
+func RestoreProjs() bool {
+	got := false
+	for _, pp := range gide.OpenProjs() {
+		if _, err := os.Stat(pp); err != nil {
+			continue
+		}
+		if strings.ToLower(filepath.Ext(pp)) == ".gide" {
+			OpenGideProj(pp)
+		} else {
+			NewGideProjPath(pp)
+		}
+		got = true
+	}
+	return got
+}
+
+// GideViewByName returns the GideView of the open project window with given
+// project name, or nil
+func GideViewByName(projnm string) *GideView {
+	for _, ge := range GideViews() {
+		if ge.Nm == projnm {
+			return ge
+		}
+	}
+	return nil
+}
+
+// GideViewOpenProjs gets the names of the open projects for submenu-func
+func GideViewOpenProjs(it interface{}, vp *gi.Viewport2D) []string {
+	var nms []string
+	for _, ge := range GideViews() {
+		nms = append(nms, ge.Nm)
+	}
+	return nms
+}
+
+// GideViewOtherProjs gets the names of the other open projects for
+// submenu-func
+func GideViewOtherProjs(it interface{}, vp *gi.Viewport2D) []string {
+	ge, ok := it.(ki.Ki).Embed(KiT_GideView).(*GideView)
+	if !ok {
+		return nil
+	}
+	var nms []string
+	for _, oge := range GideViews() {
+		if oge != ge {
+			nms = append(nms, oge.Nm)
+		}
+	}
+	return nms
+}
+
+// GoToProj raises the window of the open project with given name
+func (ge *GideView) GoToProj(projnm string) {
+	oge := GideViewByName(projnm)
+	if oge == nil {
+		return
+	}
+	if win := oge.ParentWindow(); win != nil {
+		win.OSWin.Raise()
+	}
+}
+
+// MoveFileToProj closes the file in the active view (prompting to save it if
+// changed), and opens it in the window of the open project with given name,
+// at the same position -- the file must be within that project
+func (ge *GideView) MoveFileToProj(projnm string) {
+	oge := GideViewByName(projnm)
+	if oge == nil || oge == ge {
+		return
+	}
+	tv := ge.ActiveTextView()
+	ond, idx, got := ge.OpenNodeForTextView(tv)
+	if !got {
+		ge.SetStatus("No file in the active view to move")
+		return
+	}
+	fpath := ond.FPath
+	if _, ok := oge.Files.FindFile(string(fpath)); !ok {
+		ge.SetStatus(fmt.Sprintf("File %v is not in project: %v at: %v", fpath, projnm, oge.ProjRoot))
+		return
+	}
+	cur := tv.CursorPos
+	ge.SaveCryptCheck(ond.Buf, func() {
+		ond.Buf.Close(func(canceled bool) {
+			if canceled {
+				ge.SetStatus(fmt.Sprintf("File %v NOT moved", fpath))
+				return
+			}
+			ge.OpenNodes.DeleteIdx(idx)
+			ond.SetClosed()
+			otv, _, ok := oge.NextViewFile(fpath)
+			if !ok {
+				return
+			}
+			otv.SetCursorShow(cur)
+			if win := oge.ParentWindow(); win != nil {
+				win.OSWin.Raise()
+			}
+		})
+	})
+}
+
+// GideViewForPath returns the open gide window whose project contains the
+// file or directory at given full path, raising it, or else a new window for
+// the path, which is reused if already open -- used for requests from
+// external tools
+func GideViewForPath(fpath string) (*GideView, error) {
+	var ge *GideView
+	for _, gew := range GideViews() {
+		root := string(gew.ProjRoot)
+		if root == "" || (fpath != root && !strings.HasPrefix(fpath, root+string(filepath.Separator))) {
+			continue
+		}
+		if ge == nil || len(root) > len(ge.ProjRoot) { // innermost project
+			ge = gew
+		}
+	}
+	if ge == nil {
+		_, ge = NewGideProjPath(fpath)
+		if ge == nil {
+			return nil, fmt.Errorf("could not open gide window for: %v", fpath)
+		}
+	} else if win := ge.ParentWindow(); win != nil {
+		win.OSWin.Raise()
+	}
+	return ge, nil
+}
+
+// OpenFilePos opens the given file (full path) at given line and column
+// (1-based, 0 for none) in the open gide window whose project contains the
+// file, or else in a new window for the directory of the file (see
+// GideViewForPath)
+func OpenFilePos(fpath string, ln, col int) error {
+	ge, err := GideViewForPath(fpath)
+	if err != nil {
+		return err
+	}
+	tv, _, ok := ge.NextViewFile(gi.FileName(fpath))
+	if !ok {
+		return fmt.Errorf("file not found in project: %v", ge.ProjRoot)
+	}
+	if ln > 0 {
+		txpos := giv.TextPos{Ln: ln - 1}
+		if col > 0 {
+			txpos.Ch = col - 1
+		}
+		tv.SetCursorShow(txpos)
+	}
+	return nil
+}
+
+// OpenScratchText opens the text in a new scratch buffer, named stdin, with
+// the language guessed from its start, in the gide window for the project
+// containing the directory at given full path (see GideViewForPath) -- the
+// buffer is marked as changed, so it can be saved as a file -- used for text
+// piped to gide -
+func OpenScratchText(dir string, text []byte) error {
+	ge, err := GideViewForPath(dir)
+	if err != nil {
+		return err
+	}
+	name := "stdin"
+	for i := 2; ge.OpenNodes.ByStringName(name+" - scratch") != nil; i++ {
+		name = fmt.Sprintf("stdin%d", i)
+	}
+	ge.NewScratchBuf(name, gide.GuessContentLang(text))
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return fmt.Errorf("could not open scratch buffer: %v", name)
+	}
+	tv.Buf.SetText(text)
+	tv.Buf.SetChanged()
+	return nil
+}
+
+// IPCServer is the server for requests from external tools, e.g., gide
+// open file:line, if started
+var IPCServer *gide.IPCServer
+
+// StartIPCServer starts the server for requests from external tools, which
+// open files or piped text in the running gide -- logs an error if another
+// gide is already serving
+func StartIPCServer() {
+	sv, err := gide.StartIPCServer(func(req *gide.IPCRequest) error {
+		switch req.Cmd {
+		case "open":
+			return OpenFilePos(req.Path, req.Ln, req.Col)
+		case "scratch":
+			return OpenScratchText(req.Path, []byte(req.Text))
+		}
+		return fmt.Errorf("unknown command: %v", req.Cmd)
+	})
+	if err != nil {
+		log.Printf("gide.StartIPCServer: %v\n", err)
+		return
+	}
+	IPCServer = sv
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//   Panels
+
+// PanelIsOpen returns true if the given panel has not been collapsed and is avail
+// and visible for displaying something
+func (ge *GideView) PanelIsOpen(panel int) bool {
+	sv := ge.SplitView()
+	if panel < 0 || panel >= len(sv.Kids) {
+		return false
+	}
+	if sv.Splits[panel] <= 0.01 {
+		return false
+	}
+	return true
+}
+
+// CurPanel returns the splitter panel that currently has keyboard focus
+func (ge *GideView) CurPanel() int {
 	sv := ge.SplitView()
 	for i, ski := range sv.Kids {
 		_, sk := gi.KiToNode2D(ski)
@@ -1263,9 +2748,38 @@ func (ge *GideView) VisTabByName(label string) gi.Node2D {
 	return tv.TabByName(label)
 }
 
+// RecycleVisTab returns a VisTabs (second set of tabs) tab with given
+// name, first by looking for an existing one, and if not found, making a new
+// one with widget of given type.  if sel, then select it.  returns widget
+func (ge *GideView) RecycleVisTab(label string, typ reflect.Type, sel bool) gi.Node2D {
+	tv := ge.VisTabs()
+	widg, err := tv.TabByNameTry(label)
+	if err == nil {
+		if sel {
+			tv.SelectTabByName(label)
+		}
+		return widg
+	}
+	widg = tv.AddNewTab(typ, label)
+	if sel {
+		tv.SelectTabByName(label)
+	}
+	return widg
+}
+
+// RecycleVisTabTextView returns a VisTabs (second set of tabs) tab with given
+// name, first by looking for an existing one, and if not found, making a new
+// one with a Layout and then a TextView in it.  if sel, then select it.
+// returns widget
+func (ge *GideView) RecycleVisTabTextView(label string, sel bool) *giv.TextView {
+	ly := ge.RecycleVisTab(label, gi.KiT_Layout, sel).Embed(gi.KiT_Layout).(*gi.Layout)
+	tv := ge.ConfigOutputTextView(ly)
+	return tv
+}
+
 // MainTabDeleted is called when a main tab is deleted -- we cancel any running commmands
 func (ge *GideView) MainTabDeleted(tabnm string) {
-	ge.RunningCmds.KillByName(tabnm)
+	ge.RunningJobs.CancelByName(gide.JobCommand, tabnm)
 }
 
 //////////////////////////////////////////////////////////////////////////////////////
@@ -1338,6 +2852,62 @@ func (ge *GideView) ExecCmdNameActive(cmdNm string) {
 	})
 }
 
+// RunPluginAction runs the plugin action with given label
+func (ge *GideView) RunPluginAction(label string) {
+	pa := gide.PluginActionByLabel(label)
+	if pa == nil || pa.Func == nil {
+		ge.SetStatus(fmt.Sprintf("Plugin action: %v not found", label))
+		return
+	}
+	pa.Func(ge)
+}
+
+// GideViewPluginActions gets the labels of the active plugin actions for
+// submenu-func
+func GideViewPluginActions(it interface{}, vp *gi.Viewport2D) []string {
+	ge, ok := it.(ki.Ki).Embed(KiT_GideView).(*GideView)
+	if !ok {
+		return nil
+	}
+	return gide.PluginActionLabels(ge)
+}
+
+// ViewPlugins shows the registered plugins in the Plugins tab, with their
+// actions, key chords and commands
+func (ge *GideView) ViewPlugins() {
+	pbuf, ptv, _ := ge.RecycleCmdTab("Plugins", true, true)
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100)
+	add := func(ln, mu string) {
+		outlns = append(outlns, []byte(ln))
+		outmus = append(outmus, []byte(mu))
+	}
+	sum := fmt.Sprintf("Plugins: %v registered -- plugins directory: %v", len(gide.Plugins), gide.PluginsDir())
+	add(sum, "<b>"+html.EscapeString(sum)+"</b>")
+	for _, pl := range gide.Plugins {
+		add("", "")
+		hd := pl.Name + ": " + pl.Desc
+		add(hd, "<b>"+html.EscapeString(pl.Name)+"</b>: "+html.EscapeString(pl.Desc))
+		for _, pa := range pl.Actions {
+			ln := "    action: " + pa.Label
+			if pa.Shortcut != "" {
+				ln += " (" + string(pa.Shortcut) + ")"
+			}
+			if pa.Desc != "" {
+				ln += " -- " + pa.Desc
+			}
+			add(ln, html.EscapeString(ln))
+		}
+		for _, cm := range pl.Cmds {
+			ln := "    command: " + cm.Name + " -- " + cm.Desc
+			add(ln, html.EscapeString(ln))
+		}
+	}
+	pbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	ptv.CursorStartDoc()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
 // ExecCmd pops up a menu to select a command appropriate for the current
 // active text view, and shows output in MainTab with name of command
 func (ge *GideView) ExecCmd() {
@@ -1405,6 +2975,95 @@ func (ge *GideView) ExecCmdsFileNode(fn *giv.FileNode, cmdNms gide.CmdNames, sel
 	}
 }
 
+// RunCmdOnFiles runs the command once for each of the project files of
+// given languages (all if empty) matching the glob patterns (e.g., *.c *.h
+// -- all files if empty), as {FilePath} etc, on at most maxProcs files at
+// the same time (number of cpus if 0) -- e.g., to run a formatter or a
+// codemod across the tree -- the exit status of each file is shown in a
+// table in the Run On Files tab
+func (ge *GideView) RunCmdOnFiles(cmdNm gide.CmdName, glob string, langs []filecat.Supported, maxProcs int) {
+	ge.Prefs.CmdFiles = gide.CmdFilesParams{Cmd: cmdNm, Glob: glob, Langs: langs, MaxProcs: maxProcs}
+	fls, err := ge.FilesMatching(glob, langs)
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Run Command on Files: %v", err))
+		return
+	}
+	if len(fls) == 0 {
+		ge.SetStatus(fmt.Sprintf("Run Command on Files: no files match: %q", glob))
+		return
+	}
+	ge.SaveAllCheck(true, func(gee *GideView) { // true = cancel option
+		gee.RunCmdOnFilesNoChecks(cmdNm, fls, maxProcs)
+	})
+}
+
+// RunCmdOnFilesNoChecks runs the command on the files without checking for
+// unsaved files -- see RunCmdOnFiles
+func (ge *GideView) RunCmdOnFilesNoChecks(cmdNm gide.CmdName, fls []string, maxProcs int) {
+	buf, _, _ := ge.RecycleCmdTab("Run On Files", true, true)
+	hstr := fmt.Sprintf("Run %v on %v files", cmdNm, len(fls))
+	tstr := fmt.Sprintf("%-7v %5v %9v  %v", "Status", "Exit", "Time", "File")
+	ge.AppendTabLines(buf, []string{hstr, "", tstr}, []string{"<b>" + html.EscapeString(hstr) + "</b>", "", "<b>" + html.EscapeString(tstr) + "</b>"})
+	ge.FocusOnPanel(MainTabsIdx)
+	ge.SetStatus(hstr + "...")
+	op := gide.NewProjOp(fmt.Sprintf("Run %v on Files", cmdNm))
+	for _, fp := range fls {
+		op.AddFile(fp)
+	}
+	jb := ge.RunningJobs.Add("Run On Files", string(cmdNm), hstr, nil)
+	go func() {
+		defer jb.Finish()
+		n := 0
+		res, err := ge.Project.RunCmdOnFiles(cmdNm, fls, maxProcs, func(cr *gide.CmdFileResult) {
+			n++
+			ln, mu := ge.CmdFileResultRow(cr)
+			ge.AppendTabLines(buf, []string{ln}, []string{mu})
+			ge.SetStatus(fmt.Sprintf("%v: %v / %v done", hstr, n, len(fls)))
+			jb.SetProgress(n, len(fls))
+		})
+		if err != nil {
+			ge.AppendTabLines(buf, []string{err.Error()}, []string{gide.ErrorSpan(err.Error())})
+			ge.SetStatus(fmt.Sprintf("Run Command on Files failed: %v", err))
+			return
+		}
+		sum := gide.CmdFileResultsSummary(cmdNm, res)
+		ge.AppendTabLines(buf, []string{"", sum}, []string{"", "<b>" + html.EscapeString(sum) + "</b>"})
+		updt := ge.VPort().Win.UpdateStart()
+		for _, fp := range fls {
+			if fnk, ok := ge.Files.FindFile(fp); ok {
+				fn := fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode)
+				if fn.Buf != nil && !fn.Buf.IsChanged() {
+					fn.Buf.Revert()
+				}
+			}
+		}
+		ge.VPort().Win.UpdateEnd(updt)
+		ge.SetStatus(sum)
+		if op.DropUnchanged(); len(op.Files) > 0 {
+			ge.AddProjOp(op)
+		}
+	}()
+}
+
+// CmdFileResultRow returns the row of the Run On Files results table for
+// given result, and its markup, with a link to the file, and the first line
+// of the output if the command failed
+func (ge *GideView) CmdFileResultRow(cr *gide.CmdFileResult) (string, string) {
+	rp := ge.Files.RelPath(gi.FileName(cr.FPath))
+	st := cr.Status()
+	cols := fmt.Sprintf("%-7v %5v %9v  ", st, cr.ExitCode, cr.Dur.Round(time.Millisecond))
+	ln := cols + rp
+	mu := html.EscapeString(cols) + fmt.Sprintf(`<a href="file:///%v">%v</a>`, cr.FPath, html.EscapeString(rp))
+	if !cr.OK {
+		mu = gide.ErrorSpan(st) + mu[len(st):]
+		if ol := cr.FirstOutLine(); ol != "" {
+			ln += ": " + ol
+			mu += ": " + html.EscapeString(ol)
+		}
+	}
+	return ln, mu
+}
+
 // Build runs the BuildCmds set for this project
 func (ge *GideView) Build() {
 	if len(ge.Prefs.BuildCmds) == 0 {
@@ -1425,365 +3084,2648 @@ func (ge *GideView) Run() {
 	ge.ExecCmds(ge.Prefs.RunCmds, true, true)
 }
 
-// Commit commits the current changes using relevant VCS tool, and updates the changelog.
-// Checks for VCS setting and
-func (ge *GideView) Commit() {
-	vc := ge.VersCtrl()
-	if vc == "" {
-		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No Version Control System Found", Prompt: fmt.Sprintf("No version control system detected in file system, or defined in project prefs -- define in project prefs if viewing a sub-directory within a larger repository")}, gi.AddOk, gi.NoCancel, nil, nil)
-		return
-	}
+// BuildRelease builds the release artifacts per the Release project prefs:
+// cross-compiling for each target, archiving and writing checksums, showing
+// the progress and then the artifacts in the Release tab
+func (ge *GideView) BuildRelease() {
 	ge.SaveAllCheck(true, func(gee *GideView) { // true = cancel option
-		ge.CommitNoChecks()
+		gee.BuildReleaseNoChecks()
 	})
 }
 
-// CommitNoChecks does the commit without any further checks for VCS, and unsaved files
-func (ge *GideView) CommitNoChecks() {
-	vc := ge.VersCtrl()
-	cmds := gide.AvailCmds.FilterCmdNames(ge.ActiveLang, vc)
-	cmdnm := ""
-	for _, cm := range cmds {
-		if strings.Contains(cm, "Commit") {
-			cmdnm = cm
-			break
+// BuildReleaseNoChecks builds the release without checking for unsaved files
+func (ge *GideView) BuildReleaseNoChecks() {
+	rp := ge.Prefs.Release
+	root := string(ge.ProjRoot)
+	buf, _, _ := ge.RecycleCmdTab("Release", true, true)
+	nst := rp.NSteps()
+	hstr := fmt.Sprintf("Release: %v %v: %v targets", rp.ReleaseName(root), rp.ReleaseVersion(root), len(rp.ReleaseTargets()))
+	ge.AppendRelease(buf, []string{hstr}, []string{"<b>" + html.EscapeString(hstr) + "</b>"})
+	ge.FocusOnPanel(MainTabsIdx)
+	ge.SetStatus("Building release...")
+	jb := ge.RunningJobs.Add("Release", rp.ReleaseVersion(root), hstr, nil)
+	go func() {
+		defer jb.Finish()
+		arts, err := rp.BuildRelease(root, func(step int, msg string) {
+			lstr := fmt.Sprintf("[%v/%v] %v", step, nst, msg)
+			ge.AppendRelease(buf, []string{lstr}, []string{html.EscapeString(lstr)})
+			jb.SetProgress(step, nst)
+		})
+		ge.ReleaseArts = arts
+		if err != nil {
+			elns := strings.Split(strings.TrimSpace(err.Error()), "\n")
+			emus := make([]string, len(elns))
+			for i, ln := range elns {
+				emus[i] = string(gide.MarkupCmdOutput([]byte(ln)))
+			}
+			emus[0] = gide.ErrorSpan(elns[0])
+			ge.AppendRelease(buf, elns, emus)
+			ge.SetStatus(fmt.Sprintf("Build Release failed: %v", elns[0]))
+			return
+		}
+		ge.AppendReleaseArts(buf, arts)
+		ge.SetStatus(fmt.Sprintf("Built release: %v artifacts in %v", len(arts), ge.Files.RelPath(gi.FileName(rp.ReleaseDir(root)))))
+	}()
+}
+
+// AppendRelease appends lines of text, with corresponding markup, to the
+// Release tab buffer -- safe to call from the release build goroutine
+func (ge *GideView) AppendRelease(buf *giv.TextBuf, lns, mus []string) {
+	ge.AppendTabLines(buf, lns, mus)
+}
+
+// AppendTabLines appends lines of text, with corresponding markup, to the
+// buffer of a main tab, e.g., from RecycleCmdTab -- safe to call from a
+// goroutine
+func (ge *GideView) AppendTabLines(buf *giv.TextBuf, lns, mus []string) {
+	updt := ge.VPort().Win.UpdateStart()
+	buf.AppendTextMarkup([]byte(strings.Join(lns, "\n")+"\n"), []byte(strings.Join(mus, "\n")+"\n"), false, true)
+	buf.AutoScrollViews()
+	ge.VPort().Win.UpdateEnd(updt)
+}
+
+// AppendReleaseArts appends the list of release artifacts to the Release tab
+// buffer, with links to reveal each in the file manager and to upload it
+func (ge *GideView) AppendReleaseArts(buf *giv.TextBuf, arts []gide.ReleaseArtifact) {
+	lstr := fmt.Sprintf("Artifacts: %v", len(arts))
+	lns := []string{"", lstr}
+	mus := []string{"", "<b>" + lstr + "</b>"}
+	for _, a := range arts {
+		astr := ge.Files.RelPath(gi.FileName(a.Path))
+		if a.Target != "" {
+			astr += " (" + a.Target + ")"
 		}
+		astr += fmt.Sprintf(": %v bytes, sha256 %v", a.Size, a.SHA256)
+		lns = append(lns, astr+": reveal upload")
+		mus = append(mus, fmt.Sprintf(`%v: <a href="release:///%v#reveal">reveal</a> <a href="release:///%v#upload">upload</a>`, html.EscapeString(astr), a.Path, a.Path))
 	}
-	if cmdnm == "" {
-		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No Commit command found", Prompt: fmt.Sprintf("Could not find Commit command in list of avail commands -- this is usually a programmer error -- check preferences settings etc")}, gi.AddOk, gi.NoCancel, nil, nil)
+	ge.AppendRelease(buf, lns, mus)
+}
+
+// OpenReleaseURL opens given release:/// url from the Release tab: the
+// #reveal fragment shows the artifact in the file manager, and #upload
+// uploads it with the UploadCmd of the Release project prefs
+func (ge *GideView) OpenReleaseURL(ur string) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("GideView OpenReleaseURL parse err: %v\n", err)
+		return false
+	}
+	fpath := up.Path[1:] // has double //
+	switch up.Fragment {
+	case "upload":
+		ge.UploadReleaseFiles([]string{fpath})
+	default:
+		if err := gide.RevealFile(fpath); err != nil {
+			ge.SetStatus(fmt.Sprintf("Could not reveal %v: %v", filepath.Base(fpath), err))
+			return false
+		}
+	}
+	return true
+}
+
+// UploadReleaseArtifacts uploads all the artifacts from the last Build
+// Release, with the UploadCmd of the Release project prefs
+func (ge *GideView) UploadReleaseArtifacts() {
+	if len(ge.ReleaseArts) == 0 {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No Release Artifacts", Prompt: "Build Release first to produce the artifacts to upload"}, gi.AddOk, gi.NoCancel, nil, nil)
 		return
 	}
-	ge.SetArgVarVals() // need to set before setting prompt string below..
+	fps := make([]string, len(ge.ReleaseArts))
+	for i, a := range ge.ReleaseArts {
+		fps[i] = a.Path
+	}
+	ge.UploadReleaseFiles(fps)
+}
 
-	gi.StringPromptDialog(ge.Viewport, "", "Enter commit message here..",
-		gi.DlgOpts{Title: "Commit Message", Prompt: "Please enter your commit message here -- this will be recorded along with other information from the commit in the project's ChangeLog, which can be viewed under Proj Prefs menu item -- author information comes from User settings in GoGi Preferences."},
+// UploadReleaseFiles uploads the files in turn with the UploadCmd of the
+// Release project prefs, showing the output in the Release Upload tab
+func (ge *GideView) UploadReleaseFiles(fpaths []string) {
+	rp := ge.Prefs.Release
+	root := string(ge.ProjRoot)
+	if strings.TrimSpace(rp.UploadCmd) == "" {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No Upload Command Set", Prompt: "You need to set the Release UploadCmd in the Project Preferences, e.g., gh release upload {Version} {Path}"}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	buf, _, _ := ge.RecycleCmdTab("Release Upload", true, true)
+	ge.FocusOnPanel(MainTabsIdx)
+	jb := ge.RunningJobs.Add("Release Upload", rp.ReleaseVersion(root), fmt.Sprintf("upload %v files", len(fpaths)), nil)
+	go func() {
+		defer jb.Finish()
+		nok := 0
+		for i, fp := range fpaths {
+			jb.SetProgress(i, len(fpaths))
+			cmd, err := rp.UploadArtifactCmd(root, fp)
+			if err != nil {
+				ge.AppendRelease(buf, []string{err.Error()}, []string{html.EscapeString(err.Error())})
+				break
+			}
+			cstr := strings.Join(cmd.Args, " ")
+			ge.AppendRelease(buf, []string{cstr}, []string{"<b>" + html.EscapeString(cstr) + "</b>"})
+			out, err := cmd.CombinedOutput()
+			if len(out) > 0 {
+				olns := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+				omus := make([]string, len(olns))
+				for i, ln := range olns {
+					omus[i] = string(gide.MarkupCmdOutput([]byte(ln)))
+				}
+				ge.AppendRelease(buf, olns, omus)
+			}
+			if err != nil {
+				estr := fmt.Sprintf("Upload of %v failed: %v", filepath.Base(fp), err)
+				ge.AppendRelease(buf, []string{estr}, []string{gide.ErrorSpan(estr)})
+				continue
+			}
+			nok++
+		}
+		ge.SetStatus(fmt.Sprintf("Uploaded %v of %v release artifacts", nok, len(fpaths)))
+	}()
+}
+
+// PrepareRelease suggests the next version from the conventional commits
+// (feat:, fix:, etc) since the last tag, showing them and the drafted
+// changelog section in the Prepare Release tab, and prompts for the version
+// -- the version files and changelog are then updated per the Release
+// project prefs, and the release can be committed and tagged
+func (ge *GideView) PrepareRelease() {
+	ge.SaveAllCheck(true, func(gee *GideView) { // true = cancel option
+		gee.PrepareReleaseNoChecks()
+	})
+}
+
+// PrepareReleaseNoChecks prepares the release without checking for unsaved
+// files
+func (ge *GideView) PrepareReleaseNoChecks() {
+	rp := &ge.Prefs.Release
+	root := string(ge.ProjRoot)
+	tag := gide.LastTag(root)
+	cms, err := gide.CommitsSince(root, tag)
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Prepare Release", Prompt: fmt.Sprintf("Could not get the commits since the last tag: %v", err)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	next, bump := gide.NextVersion(tag, cms)
+	last := tag
+	if last == "" {
+		last = "the start (no tags)"
+	}
+	buf, tv, _ := ge.RecycleCmdTab("Prepare Release", true, true)
+	lstr := fmt.Sprintf("Prepare Release: %v commits since %v: suggested version %v (%v)", len(cms), last, next, bump)
+	outlns := [][]byte{[]byte(lstr), nil, []byte("Commits:")}
+	outmus := [][]byte{[]byte("<b>" + html.EscapeString(lstr) + "</b>"), nil, []byte("<b>Commits:</b>")}
+	for _, cc := range cms {
+		cstr := fmt.Sprintf("  %v %v", cc.Hash, cc.Subject)
+		if cc.Type != "" {
+			cstr = fmt.Sprintf("  %v %v: %v", cc.Hash, cc.Type, cc.Subject)
+		}
+		outlns = append(outlns, []byte(cstr))
+		if cc.Breaking {
+			outmus = append(outmus, []byte(gide.ErrorSpan(cstr+" (breaking)")))
+		} else {
+			outmus = append(outmus, []byte(html.EscapeString(cstr)))
+		}
+	}
+	outlns = append(outlns, nil, []byte("Version files:"))
+	outmus = append(outmus, nil, []byte("<b>Version files:</b>"))
+	if len(rp.VersionFiles) == 0 {
+		outlns = append(outlns, []byte("  none -- set VersionFiles in the Release project prefs"))
+		outmus = append(outmus, []byte("  <i>none -- set VersionFiles in the Release project prefs</i>"))
+	}
+	for _, vf := range rp.VersionFiles {
+		vstr := fmt.Sprintf("  %v: %v", vf.File, vf.Regexp)
+		outlns = append(outlns, []byte(vstr))
+		outmus = append(outmus, []byte(html.EscapeString(vstr)))
+	}
+	cstr := fmt.Sprintf("Changelog draft for %v:", ge.Files.RelPath(gi.FileName(rp.ChangelogPath(root))))
+	outlns = append(outlns, nil, []byte(cstr), nil)
+	outmus = append(outmus, nil, []byte("<b>"+html.EscapeString(cstr)+"</b>"), nil)
+	for _, ln := range strings.Split(gide.ChangelogSection(next, time.Now().Format("2006-01-02"), cms), "\n") {
+		outlns = append(outlns, []byte(ln))
+		outmus = append(outmus, []byte(html.EscapeString(ln)))
+	}
+	buf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	tv.CursorStartDoc()
+	ge.FocusOnPanel(MainTabsIdx)
+	gi.StringPromptDialog(ge.Viewport, next, "Version..",
+		gi.DlgOpts{Title: "Prepare Release", Prompt: fmt.Sprintf("Version for the release -- %v is suggested for a %v bump from %v.  The version files and changelog are updated, and can be reviewed before committing and tagging the release.", next, bump, last)},
 		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			dlg := send.(*gi.Dialog)
 			if sig == int64(gi.DialogAccepted) {
-				msg := gi.StringPromptDialogValue(dlg)
-				ge.ArgVals["{PromptString1}"] = msg
-				gide.CmdNoUserPrompt = true                     // don't re-prompt!
-				ge.ExecCmdName(gide.CmdName(cmdnm), true, true) // must be wait
-				ge.CommitUpdtLog(cmdnm)
+				vers := strings.TrimSpace(gi.StringPromptDialogValue(dlg))
+				if vers != "" {
+					ge.SetReleaseVersion(vers, cms)
+				}
 			}
 		})
 }
 
-// CommitUpdtLog grabs info from buffer in main tabs about the commit, and
-// updates the changelog record
-func (ge *GideView) CommitUpdtLog(cmdnm string) {
-	ctv := ge.RecycleMainTabTextView(cmdnm, false) // don't sel
-	if ctv == nil {
-		return
+// SetReleaseVersion updates the version files and inserts the drafted
+// changelog section for the commits, per the Release project prefs, opening
+// the changelog for review, and then offers to commit and tag the release
+func (ge *GideView) SetReleaseVersion(vers string, cms []gide.ConvCommit) {
+	rp := &ge.Prefs.Release
+	root := string(ge.ProjRoot)
+	var files, errs []string
+	for _, vf := range rp.VersionFiles {
+		fp := filepath.Join(root, vf.File)
+		if err := gide.UpdateVersionFile(fp, vf.Regexp, vers); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		files = append(files, fp)
 	}
-	if ctv.Buf == nil {
+	clp := rp.ChangelogPath(root)
+	if err := gide.InsertChangelog(clp, gide.ChangelogSection(vers, time.Now().Format("2006-01-02"), cms)); err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		files = append(files, clp)
+	}
+	ge.PrepRelVers = vers
+	ge.PrepRelFiles = files
+	for _, fp := range files {
+		ge.Files.UpdateNewFile(fp)
+		if fnk, ok := ge.Files.FindFile(fp); ok {
+			fn := fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode)
+			if fn.Buf != nil {
+				fn.Buf.Revert()
+			}
+		}
+	}
+	ge.NextViewFile(gi.FileName(clp))
+	if len(errs) > 0 {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Prepare Release Errors", Prompt: fmt.Sprintf("Not all files could be updated for %v -- fix them, and then use Tag Release:<br>%v", vers, strings.Join(errs, "<br>"))}, gi.AddOk, gi.NoCancel, nil, nil)
 		return
 	}
-	// todo: process text!
-	ge.SaveProjIfExists(true) // saveall
+	ge.TagRelease()
 }
 
-// OpenConsoleTab opens a main tab displaying console output (stdout, stderr)
-func (ge *GideView) OpenConsoleTab() {
-	ctv := ge.RecycleMainTabTextView("Console", true)
-	ctv.SetInactive()
+// TagRelease commits the version files and changelog updated by Prepare
+// Release, and tags the release, after confirmation
+func (ge *GideView) TagRelease() {
+	if ge.PrepRelVers == "" {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No Release Prepared", Prompt: "Use Prepare Release first to set the version of the release"}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	ge.SaveAllCheck(true, func(gee *GideView) { // true = cancel option
+		vers := gee.PrepRelVers
+		gi.ChoiceDialog(gee.Viewport, gi.DlgOpts{Title: "Tag Release " + vers,
+			Prompt: fmt.Sprintf("Commit the version files and changelog (%v files), and tag the commit as %v?  You can also review and edit them first, and then use Tag Release.", len(gee.PrepRelFiles), vers)},
+			[]string{"Commit and Tag", "Review First"},
+			gee.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				if sig != 0 {
+					return
+				}
+				out, err := gide.TagRelease(string(gee.ProjRoot), vers, gee.PrepRelFiles)
+				buf, _, _ := gee.RecycleCmdTab("Prepare Release", true, false)
+				if len(out) > 0 {
+					buf.AppendTextMarkup(out, []byte(html.EscapeString(string(out))), false, true)
+				}
+				if err != nil {
+					gee.SetStatus(fmt.Sprintf("Tag Release %v failed: %v", vers, err))
+					return
+				}
+				gee.PrepRelVers = ""
+				gee.PrepRelFiles = nil
+				gee.SetStatus(fmt.Sprintf("Tagged release: %v", vers))
+			})
+	})
+}
+
+// ShareToPlayground posts the Go source in the active view to the Go
+// Playground, and shows the resulting url in the status bar, also copying it
+// to the clipboard
+func (ge *GideView) ShareToPlayground() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	if tv.Buf.Info.Sup != filecat.Go {
+		ge.SetStatus("Share to Playground only applies to Go files")
+		return
+	}
+	ur, err := gide.ShareToPlayground(tv.Buf.LinesToBytesCopy())
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Share to Playground Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	oswin.TheApp.ClipBoard(ge.Viewport.Win.OSWin).Write(mimedata.NewText(ur))
+	ge.SetStatus(fmt.Sprintf("Shared to Playground (url copied): %v", ur))
+}
+
+// RunSnippet runs the selected Go code in the active view (or the entire
+// buffer if no selection), wrapped in a main function if it is not already a
+// complete program, showing the output in the Run Snippet tab
+func (ge *GideView) RunSnippet() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	if tv.Buf.Info.Sup != filecat.Go {
+		ge.SetStatus("Run Snippet only applies to Go files")
+		return
+	}
+	var snip []byte
+	if sel := tv.Selection(); sel != nil {
+		snip = sel.ToBytes()
+	} else {
+		snip = tv.Buf.LinesToBytesCopy()
+	}
+	buf, _, _ := ge.RecycleCmdTab("Run Snippet", true, true)
+	out, err := gide.RunSnippet(string(snip))
+	if err != nil {
+		out = append(out, []byte(fmt.Sprintf("\n%v\n", err))...)
+	}
+	lns := bytes.Split(out, []byte("\n"))
+	mlns := make([][]byte, len(lns))
+	for i, ln := range lns {
+		mlns[i] = gide.MarkupCmdOutput(ln)
+	}
+	buf.AppendTextMarkup(out, bytes.Join(mlns, []byte("\n")), false, true)
+}
+
+// EvaluateSelection evaluates the selected Go code (or the line of the
+// cursor) with the Go interpreter (see gide.EvalSnippet), printing the
+// result to the Console: the value of an expression, or the output of
+// statements
+func (ge *GideView) EvaluateSelection() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	if tv.Buf.Info.Sup != filecat.Go {
+		ge.SetStatus("Evaluate Selection only applies to Go files")
+		return
+	}
+	var snip string
+	if sel := tv.Selection(); sel != nil {
+		snip = string(sel.ToBytes())
+	} else {
+		snip = string(tv.Buf.Line(tv.CursorPos.Ln))
+	}
+	if strings.TrimSpace(snip) == "" {
+		return
+	}
+	ge.OpenConsoleTab()
+	ge.SetStatus("Evaluating...")
+	go func() {
+		st := time.Now()
+		out, err := gide.EvalSnippet(snip)
+		fmt.Printf("> %v\n%s", strings.Replace(strings.TrimSpace(snip), "\n", "\n> ", -1), out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+		ge.SetStatus(fmt.Sprintf("Evaluated in %v", time.Since(st).Round(time.Millisecond)))
+	}()
+}
+
+// LoadSQLSchema (re)loads the database schema used for completion in .sql
+// files, from the Schema file or SchemaCmd in the SQL project prefs
+func (ge *GideView) LoadSQLSchema() {
+	sch, err := gide.LoadSQLSchema(&ge.Prefs.SQL, string(ge.ProjRoot))
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not load SQL schema: %v", err))
+		sch = &gide.SQLSchema{}
+	} else {
+		ge.SetStatus(fmt.Sprintf("SQL schema loaded: %v tables", len(sch.Tables)))
+	}
+	if ge.SQLSchema == nil {
+		ge.SQLSchema = sch
+	} else {
+		*ge.SQLSchema = *sch // completers of open buffers use the same schema
+	}
+}
+
+// SetSQLCompleter sets the completion of SQL keywords, and table and column
+// names from the project database schema, for given buffer
+func (ge *GideView) SetSQLCompleter(tb *giv.TextBuf) {
+	if ge.SQLSchema == nil {
+		ge.LoadSQLSchema()
+	}
+	tb.SetCompleter(ge.SQLSchema, gide.CompleteSQL, gide.CompleteSQLEdit)
+}
+
+// ExplainQuery runs EXPLAIN for the selected SQL query in the active view, or
+// the statement at the cursor, with the SQL Client in the project prefs,
+// showing the query plan as a table in the Explain tab
+func (ge *GideView) ExplainQuery() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	var query string
+	if sel := tv.Selection(); sel != nil {
+		query = string(sel.ToBytes())
+	} else {
+		query = gide.SQLStatementAt(gide.BufLines(tv.Buf), tv.CursorPos.Ln)
+	}
+	lns, err := gide.ExplainSQL(&ge.Prefs.SQL, query, string(ge.ProjRoot))
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Explain Query failed: %v", err))
+		return
+	}
+	buf, etv, _ := ge.RecycleCmdTab("Explain", true, true)
+	out := strings.Join(lns, "\n")
+	buf.AppendTextMarkup([]byte(out), []byte(html.EscapeString(out)), false, true)
+	etv.CursorStartDoc()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// ShellCheck runs shellcheck on the shell script in the active view,
+// reporting the problems in the Problems tab, with links to their locations
+func (ge *GideView) ShellCheck() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	if tv.Buf.Info.Sup != filecat.Bash {
+		ge.SetStatus("ShellCheck only applies to shell scripts")
+		return
+	}
+	ge.SaveAllCheck(true, func(gee *GideView) { // true = cancel option
+		gee.ShellCheckNoChecks(string(tv.Buf.Filename))
+	})
+}
+
+// ShellCheckNoChecks runs shellcheck on given file, without checking for
+// unsaved files, reporting in the Problems tab
+func (ge *GideView) ShellCheckNoChecks(fpath string) {
+	dgs, err := gide.RunShellCheck(fpath, filepath.Dir(fpath))
+	if err != nil {
+		ge.SetStatus("shellcheck failed: " + err.Error())
+		return
+	}
+	pbuf, ptv, _ := ge.RecycleCmdTab("Problems", true, true)
+	outlns := make([][]byte, 0, len(dgs)+1)
+	outmus := make([][]byte, 0, len(dgs)+1)
+	rp := ge.Files.RelPath(gi.FileName(fpath))
+	sum := fmt.Sprintf("ShellCheck: %v: %v problems found", rp, len(dgs))
+	outlns = append(outlns, []byte(sum))
+	outmus = append(outmus, []byte("<b>"+sum+"</b>"))
+	for _, dg := range dgs {
+		fnstr := fmt.Sprintf("%v:%d:%d", rp, dg.Line, dg.Column)
+		msg := fmt.Sprintf("%v: %v [SC%d]", dg.Level, dg.Message, dg.Code)
+		outlns = append(outlns, []byte(fmt.Sprintf("%v: %v", fnstr, msg)))
+		mstr := fmt.Sprintf(`<a href="file:///%v#L%dC%d">%v</a>: %v`, fpath, dg.Line, dg.Column, fnstr, gide.SeverityMarkup(dg.Level, msg))
+		outmus = append(outmus, []byte(mstr))
+	}
+	pbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	ptv.CursorStartDoc()
+	ge.SetStatus(sum)
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// RunShellSelection runs the selected lines in the active view (or the
+// current line if no selection) in the shell, in the directory of the file,
+// showing the output in the Shell tab
+func (ge *GideView) RunShellSelection() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	var code []byte
+	if sel := tv.Selection(); sel != nil {
+		code = sel.ToBytes()
+	} else {
+		code = tv.Buf.BytesLine(tv.CursorPos.Ln)
+	}
+	if len(bytes.TrimSpace(code)) == 0 {
+		ge.SetStatus("Run Selection in Shell: nothing to run")
+		return
+	}
+	dir := string(ge.ProjRoot)
+	if tv.Buf.Filename != "" {
+		dir = filepath.Dir(string(tv.Buf.Filename))
+	}
+	buf, _, _ := ge.RecycleCmdTab("Shell", true, false)
+	in := []byte("$ " + strings.Replace(strings.TrimRight(string(code), "\n"), "\n", "\n$ ", -1) + "\n")
+	buf.AppendTextMarkup(in, []byte("<b>"+html.EscapeString(string(in))+"</b>"), false, true)
+	out, err := gide.RunShellSnippet(string(code), dir)
+	if err != nil {
+		out = append(out, []byte(fmt.Sprintf("%v\n", err))...)
+	}
+	lns := bytes.Split(out, []byte("\n"))
+	mlns := make([][]byte, len(lns))
+	for i, ln := range lns {
+		mlns[i] = gide.MarkupCmdOutput(ln)
+	}
+	buf.AppendTextMarkup(out, bytes.Join(mlns, []byte("\n")), false, true)
+}
+
+// CheckDocLinks checks the relative links and anchors in all the Markdown
+// files in the project, reporting the broken ones in the Problems tab --
+// open files are checked as currently edited
+func (ge *GideView) CheckDocLinks() {
+	bufLines := func(fpath string) []string {
+		for _, ond := range ge.OpenNodes {
+			if ond.Buf != nil && string(ond.FPath) == fpath {
+				return gide.BufLines(ond.Buf)
+			}
+		}
+		return nil
+	}
+	probs := gide.CheckDocLinks(string(ge.ProjRoot), bufLines)
+	pbuf, ptv, _ := ge.RecycleCmdTab("Problems", true, true)
+	outlns := make([][]byte, 0, len(probs)+1)
+	outmus := make([][]byte, 0, len(probs)+1)
+	sum := fmt.Sprintf("Check Doc Links: %v broken links found", len(probs))
+	outlns = append(outlns, []byte(sum))
+	outmus = append(outmus, []byte("<b>"+sum+"</b>"))
+	for _, pb := range probs {
+		rp := ge.Files.RelPath(gi.FileName(pb.File))
+		fnstr := fmt.Sprintf("%v:%d:%d", rp, pb.Ln+1, pb.Ch+1)
+		lstr := fmt.Sprintf("%v: %v: %v", fnstr, pb.Msg, pb.Link)
+		outlns = append(outlns, []byte(lstr))
+		mstr := fmt.Sprintf(`<a href="file:///%v#L%dC%d">%v</a>: %v: %v`, pb.File, pb.Ln+1, pb.Ch+1, fnstr, html.EscapeString(pb.Msg), html.EscapeString(pb.Link))
+		outmus = append(outmus, []byte(mstr))
+	}
+	pbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	ptv.CursorStartDoc()
+	ge.SetStatus(sum)
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// ActiveDataFile returns the active text view if it shows a JSON, YAML or
+// TOML file, and its format -- otherwise reports that it is not one
+func (ge *GideView) ActiveDataFile() (*gide.TextView, string) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return nil, ""
+	}
+	format := gide.DataFormat(string(tv.Buf.Filename))
+	if format == "" {
+		ge.SetStatus(fmt.Sprintf("Not a JSON, YAML or TOML file: %v", ge.Files.RelPath(tv.Buf.Filename)))
+		return nil, ""
+	}
+	return tv, format
+}
+
+// DataChecked shows the problems found in the JSON, YAML or TOML file of the
+// text view as it is edited in the Problems tab, when they change, without
+// selecting it, and updates the Structure tab if it shows the file
+func (ge *GideView) DataChecked(tv *gide.TextView, root *gide.DataNode, probs []gide.DataProblem, changed bool) {
+	if changed {
+		ge.ShowDataProblems(tv.Buf.Filename, probs, false)
+	}
+	if dvi := ge.VisTabByName("Structure"); dvi != nil {
+		if dv := dvi.Embed(gide.KiT_DataView).(*gide.DataView); dv.Buf == tv.Buf {
+			dv.Update(root, probs)
+		}
+	}
+}
+
+// ShowDataProblems shows the problems found in the JSON, YAML or TOML file
+// in the Problems tab, selecting it if sel
+func (ge *GideView) ShowDataProblems(fpath gi.FileName, probs []gide.DataProblem, sel bool) {
+	fnm := string(fpath)
+	rp := ge.Files.RelPath(fpath)
+	sum := fmt.Sprintf("Check %v: %v: %v problems found", gide.DataFormatNames[gide.DataFormat(fnm)], rp, len(probs))
+	lns := []string{sum}
+	mus := []string{"<b>" + sum + "</b>"}
+	for _, pb := range probs {
+		loc := fmt.Sprintf("%v:%d:%d", rp, pb.Ln+1, pb.Col+1)
+		lns = append(lns, loc+": "+pb.Msg)
+		mus = append(mus, fmt.Sprintf(`<a href="file:///%v#L%dC%d">%v</a>: %v`, fnm, pb.Ln+1, pb.Col+1, loc, html.EscapeString(pb.Msg)))
+	}
+	pbuf, ptv, _ := ge.RecycleCmdTab("Problems", sel, true)
+	pbuf.AppendTextMarkup([]byte(strings.Join(lns, "\n")), []byte(strings.Join(mus, "\n")), false, true)
+	ptv.CursorStartDoc()
+	ge.SetStatus(sum)
+	if sel {
+		ge.FocusOnPanel(MainTabsIdx)
+	}
+}
+
+// CheckData checks the syntax of the JSON, YAML or TOML file in the active
+// view, for duplicate keys, and against its JSON Schema if it is loaded,
+// reporting the problems in the Problems tab
+func (ge *GideView) CheckData() {
+	tv, format := ge.ActiveDataFile()
+	if tv == nil {
+		return
+	}
+	txt := tv.Buf.LinesToBytesCopy()
+	root, probs := gide.ParseData(format, txt)
+	probs = append(probs, gide.ValidateData(string(tv.Buf.Filename), root, txt, false)...)
+	ge.ShowDataProblems(tv.Buf.Filename, probs, true)
+}
+
+// DataStructure shows the structure of the JSON, YAML or TOML file in the
+// active view in a collapsible outline in the Structure tab in the
+// VisTabs, which is updated as the file is edited -- selecting a key moves
+// the cursor to it
+func (ge *GideView) DataStructure() {
+	tv, _ := ge.ActiveDataFile()
+	if tv == nil {
+		return
+	}
+	dv := ge.RecycleVisTab("Structure", gide.KiT_DataView, true).Embed(gide.KiT_DataView).(*gide.DataView)
+	dv.Config(ge, tv.Buf)
+}
+
+// FormatDataJSON pretty-prints the JSON file in the active view, with the
+// indentation of the file, or minifies it if minify -- as one undoable
+// change, keeping the order of the keys
+func (ge *GideView) FormatDataJSON(minify bool) {
+	tv, format := ge.ActiveDataFile()
+	if tv == nil {
+		return
+	}
+	if format != "json" {
+		ge.SetStatus("Only JSON files are pretty-printed and minified -- convert the file to JSON first")
+		return
+	}
+	tb := tv.Buf
+	ind := gide.BufIndentString(tb)
+	if minify {
+		ind = ""
+	}
+	out, err := gide.FormatJSON(tb.LinesToBytesCopy(), ind)
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("JSON not formatted: %v", err))
+		return
+	}
+	out = append(bytes.TrimRight(out, "\n"), '\n')
+	n := gide.ReplaceChangedLines(tb, strings.Split(string(out), "\n"))
+	ge.SetStatus(fmt.Sprintf("JSON formatted: %v lines changed", n))
+}
+
+// PrettyPrintData pretty-prints the JSON file in the active view, with the
+// indentation of the file
+func (ge *GideView) PrettyPrintData() {
+	ge.FormatDataJSON(false)
+}
+
+// MinifyData minifies the JSON file in the active view onto one line
+func (ge *GideView) MinifyData() {
+	ge.FormatDataJSON(true)
+}
+
+// ConvertData converts the JSON, YAML or TOML file in the active view to
+// given data format, writing a new file next to it, with the extension of
+// the format, and opening it -- comments are not converted, and duplicate
+// keys and values that the format does not have (e.g., null in TOML) stop
+// the conversion
+func (ge *GideView) ConvertData(to string) {
+	tv, format := ge.ActiveDataFile()
+	if tv == nil {
+		return
+	}
+	tb := tv.Buf
+	fnm := string(tb.Filename)
+	name := gide.DataFormatNames[to]
+	if format == to {
+		ge.SetStatus(fmt.Sprintf("File is already %v: %v", name, ge.Files.RelPath(tb.Filename)))
+		return
+	}
+	root, probs := gide.ParseData(format, tb.LinesToBytesCopy())
+	if root == nil {
+		ge.ShowDataProblems(tb.Filename, probs, true)
+		return
+	}
+	out, err := gide.EmitData(root, to, gide.BufIndentString(tb))
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not Convert to " + name, Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	np := strings.TrimSuffix(fnm, filepath.Ext(fnm)) + "." + to
+	if _, err := os.Stat(np); err == nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "File Exists", Prompt: fmt.Sprintf("The file: %v already exists -- rename or delete it first to convert to %v", np, name)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	if err := ioutil.WriteFile(np, out, 0644); err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not write converted file: %v", err))
+		return
+	}
+	ge.Files.UpdateNewFile(np)
+	ge.NextViewFile(gi.FileName(np))
+	ge.SetStatus(fmt.Sprintf("Converted to %v: %v", name, ge.Files.RelPath(gi.FileName(np))))
+}
+
+// ConvertToJSON converts the YAML or TOML file in the active view to a new
+// JSON file next to it
+func (ge *GideView) ConvertToJSON() {
+	ge.ConvertData("json")
+}
+
+// ConvertToYAML converts the JSON or TOML file in the active view to a new
+// YAML file next to it
+func (ge *GideView) ConvertToYAML() {
+	ge.ConvertData("yaml")
+}
+
+// ConvertToTOML converts the JSON or YAML file in the active view to a new
+// TOML file next to it
+func (ge *GideView) ConvertToTOML() {
+	ge.ConvertData("toml")
+}
+
+// ActiveOpenAPI returns the parsed OpenAPI (Swagger) document in the active
+// text view -- reports a problem and returns nil if it is not one
+func (ge *GideView) ActiveOpenAPI() (*gide.APINode, string, error) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return nil, "", nil
+	}
+	fnm := string(tv.Buf.Filename)
+	lns := gide.BufLines(tv.Buf)
+	if !gide.IsOpenAPI(fnm, lns) {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Not an OpenAPI Document", Prompt: fmt.Sprintf("The active file: %v is not an OpenAPI or Swagger document -- must be a .yaml or .json file with an openapi or swagger key", ge.Files.RelPath(tv.Buf.Filename))}, gi.AddOk, gi.NoCancel, nil, nil)
+		return nil, fnm, nil
+	}
+	root, err := gide.ParseOpenAPI(fnm, lns)
+	return root, fnm, err
+}
+
+// ValidateOpenAPI checks the OpenAPI (Swagger) document in the active text
+// view for missing required fields, undeclared path parameters, duplicate
+// operation ids and broken local $ref's, reporting them in the Problems tab
+func (ge *GideView) ValidateOpenAPI() {
+	root, fnm, err := ge.ActiveOpenAPI()
+	if root == nil && err == nil {
+		return
+	}
+	var probs []gide.APIProblem
+	if err != nil {
+		probs = append(probs, gide.APIProblem{Ln: 0, Msg: err.Error()})
+	} else {
+		probs = gide.ValidateOpenAPI(root)
+	}
+	pbuf, ptv, _ := ge.RecycleCmdTab("Problems", true, true)
+	outlns := make([][]byte, 0, len(probs)+1)
+	outmus := make([][]byte, 0, len(probs)+1)
+	rp := ge.Files.RelPath(gi.FileName(fnm))
+	sum := fmt.Sprintf("Validate OpenAPI: %v: %v problems found", rp, len(probs))
+	outlns = append(outlns, []byte(sum))
+	outmus = append(outmus, []byte("<b>"+sum+"</b>"))
+	for _, pb := range probs {
+		fnstr := fmt.Sprintf("%v:%d", rp, pb.Ln+1)
+		outlns = append(outlns, []byte(fmt.Sprintf("%v: %v", fnstr, pb.Msg)))
+		mstr := fmt.Sprintf(`<a href="file:///%v#L%dC1">%v</a>: %v`, fnm, pb.Ln+1, fnstr, html.EscapeString(pb.Msg))
+		outmus = append(outmus, []byte(mstr))
+	}
+	pbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	ptv.CursorStartDoc()
+	ge.SetStatus(sum)
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// PreviewOpenAPI shows the documentation of the OpenAPI (Swagger) document
+// in the active text view in the API Docs tab, with links to the
+// operations, parameters and responses in the document
+func (ge *GideView) PreviewOpenAPI() {
+	root, fnm, err := ge.ActiveOpenAPI()
+	if err != nil {
+		ge.SetStatus("OpenAPI document could not be parsed: " + err.Error())
+		return
+	}
+	if root == nil {
+		return
+	}
+	dl := gide.OpenAPIDocs(root)
+	dbuf, dtv, _ := ge.RecycleCmdTab("API Docs", true, true)
+	outlns := make([][]byte, 0, len(dl))
+	outmus := make([][]byte, 0, len(dl))
+	for _, d := range dl {
+		outlns = append(outlns, []byte(d.Text))
+		mu := d.Markup
+		if d.Ln >= 0 {
+			mu = fmt.Sprintf(`<a href="file:///%v#L%dC1">%v</a>`, fnm, d.Ln+1, mu)
+		}
+		outmus = append(outmus, []byte(mu))
+	}
+	dbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	dtv.CursorStartDoc()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// GenAPIClient runs the OpenAPI Gen Client command on the active OpenAPI
+// document, prompting for the generator (e.g., go, typescript-fetch) and
+// the output directory
+func (ge *GideView) GenAPIClient() {
+	ge.ExecCmdNameActive("OpenAPI Gen Client")
+}
+
+// GenAPIServer runs the OpenAPI Gen Server command on the active OpenAPI
+// document, prompting for the generator (e.g., go-server) and the output
+// directory
+func (ge *GideView) GenAPIServer() {
+	ge.ExecCmdNameActive("OpenAPI Gen Server")
+}
+
+// ActiveTerraformDir returns the directory of the Terraform (or HCL) file in
+// the active text view, or prompts that it is not a Terraform file and
+// returns ""
+func (ge *GideView) ActiveTerraformDir() string {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return ""
+	}
+	if !gide.IsTerraformFile(string(tv.Buf.Filename)) {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Not a Terraform File", Prompt: fmt.Sprintf("The active file: %v is not a Terraform file -- must be a .tf, .tfvars or .hcl file", ge.Files.RelPath(tv.Buf.Filename))}, gi.AddOk, gi.NoCancel, nil, nil)
+		return ""
+	}
+	return filepath.Dir(string(tv.Buf.Filename))
+}
+
+// ValidateTerraform runs terraform validate on the configuration in the
+// directory of the active Terraform file, reporting the errors and warnings
+// in the Problems tab, with links to their locations -- terraform init
+// must have been run in the directory
+func (ge *GideView) ValidateTerraform() {
+	dir := ge.ActiveTerraformDir()
+	if dir == "" {
+		return
+	}
+	ge.SaveAllCheck(true, func(gee *GideView) { // true = cancel option
+		gee.ValidateTerraformNoChecks(dir)
+	})
+}
+
+// ValidateTerraformNoChecks runs terraform validate in given directory,
+// without checking for unsaved files, reporting in the Problems tab
+func (ge *GideView) ValidateTerraformNoChecks(dir string) {
+	cmd := exec.Command("terraform", "validate", "-json", "-no-color")
+	cmd.Dir = dir
+	out, cerr := cmd.Output() // exits with error if not valid
+	dgs, valid, err := gide.ParseTerraformValidate(out, dir)
+	if err != nil {
+		if cerr != nil {
+			err = cerr
+		}
+		ge.SetStatus("terraform validate failed: " + err.Error())
+		return
+	}
+	pbuf, ptv, _ := ge.RecycleCmdTab("Problems", true, true)
+	outlns := make([][]byte, 0, len(dgs)+1)
+	outmus := make([][]byte, 0, len(dgs)+1)
+	rd := ge.Files.RelPath(gi.FileName(dir))
+	sum := fmt.Sprintf("Validate Terraform: %v: valid, %v warnings", rd, len(dgs))
+	if !valid {
+		sum = fmt.Sprintf("Validate Terraform: %v: not valid, %v problems found", rd, len(dgs))
+	}
+	outlns = append(outlns, []byte(sum))
+	outmus = append(outmus, []byte("<b>"+sum+"</b>"))
+	for _, dg := range dgs {
+		msg := dg.Severity + ": " + dg.Summary
+		if dg.Detail != "" {
+			msg += ": " + dg.Detail
+		}
+		if dg.File == "" {
+			outlns = append(outlns, []byte(msg))
+			outmus = append(outmus, []byte(gide.SeverityMarkup(dg.Severity, msg)))
+			continue
+		}
+		fnstr := fmt.Sprintf("%v:%d:%d", ge.Files.RelPath(gi.FileName(dg.File)), dg.Ln, dg.Col)
+		outlns = append(outlns, []byte(fmt.Sprintf("%v: %v", fnstr, msg)))
+		mstr := fmt.Sprintf(`<a href="file:///%v#L%dC%d">%v</a>: %v`, dg.File, dg.Ln, dg.Col, fnstr, gide.SeverityMarkup(dg.Severity, msg))
+		outmus = append(outmus, []byte(mstr))
+	}
+	pbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	ptv.CursorStartDoc()
+	ge.SetStatus(sum)
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// PlanTerraform runs terraform plan in the directory of the active
+// Terraform file, showing the output in its command tab
+func (ge *GideView) PlanTerraform() {
+	if ge.ActiveTerraformDir() == "" {
+		return
+	}
+	ge.ExecCmdNameActive("Plan Terraform")
+}
+
+// InitTerraform runs terraform init in the directory of the active
+// Terraform file, showing the output in its command tab
+func (ge *GideView) InitTerraform() {
+	if ge.ActiveTerraformDir() == "" {
+		return
+	}
+	ge.ExecCmdNameActive("Init Terraform")
+}
+
+// Commit commits the current changes using relevant VCS tool, and updates the changelog.
+// Checks for VCS setting and
+func (ge *GideView) Commit() {
+	vc := ge.VersCtrl()
+	if vc == "" {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No Version Control System Found", Prompt: fmt.Sprintf("No version control system detected in file system, or defined in project prefs -- define in project prefs if viewing a sub-directory within a larger repository")}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	ge.SaveAllCheck(true, func(gee *GideView) { // true = cancel option
+		ge.CommitNoChecks()
+	})
+}
+
+// CommitNoChecks does the commit without any further checks for VCS, and unsaved files
+func (ge *GideView) CommitNoChecks() {
+	vc := ge.VersCtrl()
+	cmds := gide.AvailCmds.FilterCmdNames(ge.ActiveLang, vc)
+	cmdnm := ""
+	for _, cm := range cmds {
+		if strings.Contains(cm, "Commit") {
+			cmdnm = cm
+			break
+		}
+	}
+	if cmdnm == "" {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No Commit command found", Prompt: fmt.Sprintf("Could not find Commit command in list of avail commands -- this is usually a programmer error -- check preferences settings etc")}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	ge.SetArgVarVals() // need to set before setting prompt string below..
+
+	gi.StringPromptDialog(ge.Viewport, "", "Enter commit message here..",
+		gi.DlgOpts{Title: "Commit Message", Prompt: "Please enter your commit message here -- this will be recorded along with other information from the commit in the project's ChangeLog, which can be viewed under Proj Prefs menu item -- author information comes from User settings in GoGi Preferences."},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dlg := send.(*gi.Dialog)
+			if sig == int64(gi.DialogAccepted) {
+				msg := gi.StringPromptDialogValue(dlg)
+				ge.ArgVals["{PromptString1}"] = msg
+				gide.CmdNoUserPrompt = true                     // don't re-prompt!
+				ge.ExecCmdName(gide.CmdName(cmdnm), true, true) // must be wait
+				ge.CommitUpdtLog(cmdnm)
+			}
+		})
+}
+
+// CommitUpdtLog grabs info from buffer in main tabs about the commit, and
+// updates the changelog record
+func (ge *GideView) CommitUpdtLog(cmdnm string) {
+	ctv := ge.RecycleMainTabTextView(cmdnm, false) // don't sel
+	if ctv == nil {
+		return
+	}
+	if ctv.Buf == nil {
+		return
+	}
+	// todo: process text!
+	ge.SaveProjIfExists(true) // saveall
+}
+
+// OpenConsoleTab opens a main tab displaying console output (stdout, stderr)
+func (ge *GideView) OpenConsoleTab() {
+	ctv := ge.RecycleMainTabTextView("Console", true)
+	ctv.SetInactive()
 	if ctv.Buf == nil || ctv.Buf != gide.TheConsole.Buf {
 		ctv.SetBuf(gide.TheConsole.Buf)
 		gide.TheConsole.Buf.TextBufSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			gee, _ := recv.Embed(KiT_GideView).(*GideView)
-			gee.SelectMainTabByName("Console")
+			gee.SelectMainTabByName("Console")
+		})
+	}
+}
+
+// OpenMainTab opens the main tab of given type registered with
+// gide.RegisterMainTab, or selects it if already open
+func (ge *GideView) OpenMainTab(label string) {
+	if gide.OpenMainTab(ge, label) == nil {
+		ge.SetStatus(fmt.Sprintf("Tab type: %v not found", label))
+	}
+}
+
+// GideViewMainTabTypes gets the labels of the registered main tab types for
+// submenu-func
+func GideViewMainTabTypes(it interface{}, vp *gi.Viewport2D) []string {
+	return gide.MainTabTypeLabels()
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    TextView functions
+
+// CursorToHistPrev moves cursor to previous position on history list --
+// returns true if moved
+func (ge *GideView) CursorToHistPrev() bool {
+	tv := ge.ActiveTextView()
+	return tv.CursorToHistPrev()
+}
+
+// CursorToHistNext moves cursor to next position on history list --
+// returns true if moved
+func (ge *GideView) CursorToHistNext() bool {
+	tv := ge.ActiveTextView()
+	return tv.CursorToHistNext()
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Find / Replace
+
+// Find does Find / Replace in files, using given options and filters -- opens up a
+// main tab with the results and further controls.
+func (ge *GideView) Find(find, repl string, ignoreCase bool, loc gide.FindLoc, langs []filecat.Supported) {
+	if find == "" {
+		return
+	}
+	ge.Prefs.Find.IgnoreCase = ignoreCase
+	ge.Prefs.Find.Langs = langs
+	ge.Prefs.Find.Loc = loc
+
+	fbuf, _ := ge.RecycleCmdBuf("Find", true)
+	fvi := ge.RecycleMainTab("Find", gide.KiT_FindView, true) // sel
+	fv := fvi.Embed(gide.KiT_FindView).(*gide.FindView)
+	fv.Config(ge)
+	fv.Time = time.Now()
+	ftv := fv.TextView()
+	ftv.SetInactive()
+	ftv.SetBuf(fbuf)
+
+	fv.SaveFindString(find)
+	fv.SaveReplString(repl)
+
+	root := ge.Files.Embed(giv.KiT_FileNode).(*giv.FileNode)
+
+	atv := ge.ActiveTextView()
+	ond, _, got := ge.OpenNodeForTextView(atv)
+	adir := ""
+	if got {
+		adir, _ = filepath.Split(string(ond.FPath))
+	}
+
+	var res []gide.FileSearchResults
+	if loc == gide.FindLocFile {
+		if got {
+			cnt, matches := atv.Buf.Search([]byte(find), ignoreCase)
+			res = append(res, gide.FileSearchResults{ond, cnt, matches})
+		}
+	} else if loc == gide.FindLocSelection || loc == gide.FindLocFunc {
+		if got {
+			scope, ok := atv.FindScope(loc)
+			if !ok {
+				what := "selection"
+				if loc == gide.FindLocFunc {
+					what = "function at the cursor"
+				}
+				ge.SetStatus(fmt.Sprintf("Find: no %v in the active file to find in", what))
+			} else {
+				_, matches := atv.Buf.Search([]byte(find), ignoreCase)
+				matches = gide.ScopeMatches(matches, scope)
+				res = append(res, gide.FileSearchResults{ond, len(matches), matches})
+			}
+		}
+	} else {
+		jb := ge.RunningJobs.Add("Find", find, "finding in the project files", nil)
+		res = gide.FileTreeSearch(root, find, ignoreCase, loc, adir, langs)
+		jb.Finish()
+	}
+
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	for _, fs := range res {
+		fp := fs.Node.Info.Path
+		fn := fs.Node.MyRelPath()
+		fbStLn := len(outlns) // find buf start ln
+		lstr := fmt.Sprintf(`%v: %v`, fn, fs.Count)
+		outlns = append(outlns, []byte(lstr))
+		mstr := fmt.Sprintf(`<b>%v</b>`, lstr)
+		outmus = append(outmus, []byte(mstr))
+		for _, mt := range fs.Matches {
+			ln := mt.Reg.Start.Ln + 1
+			ch := mt.Reg.Start.Ch + 1
+			ech := mt.Reg.End.Ch + 1
+			fnstr := fmt.Sprintf("%v:%d:%d", fn, ln, ch)
+			nomu := bytes.Replace(mt.Text, []byte("<mark>"), nil, -1)
+			nomu = bytes.Replace(nomu, []byte("</mark>"), nil, -1)
+			nomus := html.EscapeString(string(nomu))
+			lstr = fmt.Sprintf(`%v: %s`, fnstr, nomus) // note: has tab embedded at start of lstr
+
+			outlns = append(outlns, []byte(lstr))
+			mstr = fmt.Sprintf(`	<a href="find:///%v#R%vN%vL%vC%v-L%vC%v">%v</a>: %s`, fp, fbStLn, fs.Count, ln, ch, ln, ech, fnstr, mt.Text)
+			outmus = append(outmus, []byte(mstr))
+		}
+		outlns = append(outlns, []byte(""))
+		outmus = append(outmus, []byte(""))
+	}
+	ltxt := bytes.Join(outlns, []byte("\n"))
+	mtxt := bytes.Join(outmus, []byte("\n"))
+	fbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+	ftv.CursorStartDoc()
+	ok := ftv.CursorNextLink(false) // no wrap
+	if ok {
+		ftv.OpenLinkAt(ftv.CursorPos)
+	}
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// Spell checks spelling in files
+func (ge *GideView) Spell() {
+	fbuf, _ := ge.RecycleCmdBuf("Spell", true)
+	sv := ge.RecycleMainTab("Spell", gide.KiT_SpellView, true).Embed(gide.KiT_SpellView).(*gide.SpellView)
+	sv.Config(ge, ge.Prefs.Spell)
+	stv := sv.TextView()
+	stv.SetInactive()
+	stv.SetBuf(fbuf)
+
+	fp := string(ge.ActiveFilename)
+	if fp == "" {
+		return
+	}
+
+	tv := ge.ActiveTextView()
+	if err := gide.LoadSpellLang(ge.Prefs.Spell.Lang); err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Spelling Language Not Installed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	text := tv.Buf.LinesToBytesCopy()
+	gi.InitNewSpellCheck(text)
+	tw, suggests, err := gi.NextUnknownWord()
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Error Running Spell Check", Prompt: fmt.Sprintf("%v", err)}, gi.AddOk, gi.NoCancel, nil, nil)
+	}
+	sv.SetUnknownAndSuggest(tw, suggests)
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// Symbols displays the Symbols of a file or package
+func (ge *GideView) Symbols() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	sv := ge.RecycleMainTab("Symbols", gide.KiT_SymbolsView, true).Embed(gide.KiT_SymbolsView).(*gide.SymbolsView)
+	sv.Config(ge, ge.Prefs.Symbols)
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// Outline displays the headings outline of the Markdown, reStructuredText or
+// AsciiDoc document, or the resource blocks of the Terraform file, in the
+// active view
+func (ge *GideView) Outline() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	ov := ge.RecycleMainTab("Outline", gide.KiT_OutlineView, true).Embed(gide.KiT_OutlineView).(*gide.OutlineView)
+	ov.Config(ge)
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// ParseOpenFindURL parses and opens given find:/// url from Find, return text
+// region encoded in url, and starting line of results in find buffer, and
+// number of results returned -- for parsing all the find results
+func (ge *GideView) ParseOpenFindURL(ur string, ftv *giv.TextView) (tv *gide.TextView, reg giv.TextRegion, findBufStLn, findCount int, ok bool) {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("FindView OpenFindURL parse err: %v\n", err)
+		return
+	}
+	fpath := up.Path[1:] // has double //
+	pos := up.Fragment
+	tv, _, ok = ge.LinkViewFile(gi.FileName(fpath))
+	if !ok {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Open File at Link", Prompt: fmt.Sprintf("Could not find or open file path in project: %v", fpath)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	if pos == "" {
+		return
+	}
+
+	lidx := strings.Index(pos, "L")
+	if lidx > 0 {
+		reg.FromString(pos[lidx:])
+		pos = pos[:lidx]
+	}
+	fmt.Sscanf(pos, "R%dN%d", &findBufStLn, &findCount)
+	return
+}
+
+// OpenFindURL opens given find:/// url from Find -- delegates to FindView
+func (ge *GideView) OpenFindURL(ur string, ftv *giv.TextView) bool {
+	fvk := ftv.ParentByType(gide.KiT_FindView, true)
+	if fvk == nil {
+		return false
+	}
+	fv := fvk.(*gide.FindView)
+	return fv.OpenFindURL(ur, ftv)
+}
+
+// OpenSpellURL opens given spell:/// url from Spell -- delegates to SpellView
+func (ge *GideView) OpenSpellURL(ur string, stv *giv.TextView) bool {
+	svk := stv.ParentByType(gide.KiT_SpellView, true)
+	if svk == nil {
+		return false
+	}
+	fv := svk.(*gide.SpellView)
+	return fv.OpenSpellURL(ur, stv)
+}
+
+// ReplaceInActive does query-replace in active file only
+func (ge *GideView) ReplaceInActive() {
+	tv := ge.ActiveTextView()
+	tv.QReplacePrompt()
+}
+
+func (ge *GideView) OpenFileAtRegion(filename gi.FileName, tr giv.TextRegion) (tv *gide.TextView, ok bool) {
+	tv, _, ok = ge.LinkViewFile(filename)
+	if tv != nil {
+		tv.UpdateStart()
+		tv.Highlights = tv.Highlights[:0]
+		tv.Highlights = append(tv.Highlights, tr)
+		tv.UpdateEnd(true)
+		tv.RefreshIfNeeded()
+		tv.SetCursorShow(tr.Start)
+		tv.GrabFocus()
+		return tv, true
+
+	}
+	return nil, false
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Registers
+
+// RegisterCopy saves current selection in active text view to register of given name
+// returns true if saved
+func (ge *GideView) RegisterCopy(name string) bool {
+	if name == "" {
+		return false
+	}
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	sel := tv.Selection()
+	if sel == nil {
+		return false
+	}
+	if gide.AvailRegisters == nil {
+		gide.AvailRegisters = make(gide.Registers, 100)
+	}
+	gide.AvailRegisters[name] = string(sel.ToBytes())
+	gide.AvailRegisters.SavePrefs()
+	ge.Prefs.Register = gide.RegisterName(name)
+	tv.SelectReset()
+	return true
+}
+
+// RegisterPaste pastes register of given name into active text view
+// returns true if pasted
+func (ge *GideView) RegisterPaste(name gide.RegisterName) bool {
+	if name == "" {
+		return false
+	}
+	str, ok := gide.AvailRegisters[string(name)]
+	if !ok {
+		return false
+	}
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	tv.InsertAtCursor([]byte(str))
+	ge.Prefs.Register = name
+	return true
+}
+
+// RegisterAppend appends current selection in active text view to register
+// of given name, creating it if it does not exist -- returns true if saved
+func (ge *GideView) RegisterAppend(name string) bool {
+	if name == "" {
+		return false
+	}
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	sel := tv.Selection()
+	if sel == nil {
+		return false
+	}
+	gide.AvailRegisters.Append(name, string(sel.ToBytes()))
+	gide.AvailRegisters.SavePrefs()
+	ge.Prefs.Register = gide.RegisterName(name)
+	tv.SelectReset()
+	return true
+}
+
+// RegisterCopyRect saves the rectangle of the current selection in active
+// text view -- the columns between its start and end, on each of its lines
+// -- to register of given name, for pasting with RegisterPasteRect --
+// returns true if saved
+func (ge *GideView) RegisterCopyRect(name string) bool {
+	if name == "" {
+		return false
+	}
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	rect := tv.SelectionRect()
+	if rect == nil {
+		return false
+	}
+	if gide.AvailRegisters == nil {
+		gide.AvailRegisters = make(gide.Registers, 100)
+	}
+	gide.AvailRegisters[name] = strings.Join(rect, "\n")
+	gide.AvailRegisters.SavePrefs()
+	ge.Prefs.Register = gide.RegisterName(name)
+	tv.SelectReset()
+	return true
+}
+
+// RegisterPasteRect pastes register of given name into active text view as
+// a rectangle: each of its lines is inserted at the column of the cursor on
+// successive lines -- returns true if pasted
+func (ge *GideView) RegisterPasteRect(name gide.RegisterName) bool {
+	if name == "" {
+		return false
+	}
+	str, ok := gide.AvailRegisters[string(name)]
+	if !ok {
+		return false
+	}
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	tv.PasteRect(strings.Split(str, "\n"))
+	ge.Prefs.Register = name
+	return true
+}
+
+// ClipHistory shows the clipboard history of the copies and cuts in all the
+// editors, most recent first, in the Clipboard tab, with only the entries
+// containing the find string, ignoring case, if it is not empty -- clicking
+// on an entry, or Enter on it, pastes it into the active text view
+func (ge *GideView) ClipHistory(find string) {
+	idxs := gide.ClipHistFind(find)
+	cbuf, ctv, _ := ge.RecycleCmdTab("Clipboard", true, true)
+	outlns := make([][]byte, 0, len(idxs)+1)
+	outmus := make([][]byte, 0, len(idxs)+1)
+	lstr := fmt.Sprintf("Clipboard History: %v entries", len(giv.TextViewClipHistory))
+	if find != "" {
+		lstr = fmt.Sprintf("Clipboard History: %v of %v entries containing: %q", len(idxs), len(giv.TextViewClipHistory), find)
+	}
+	outlns = append(outlns, []byte(lstr))
+	outmus = append(outmus, []byte("<b>"+html.EscapeString(lstr)+"</b>"))
+	for _, i := range idxs {
+		pv := gide.RegisterPreview(string(giv.TextViewClipHistory[i]))
+		outlns = append(outlns, []byte(fmt.Sprintf("%3d: %v", i+1, pv)))
+		outmus = append(outmus, []byte(fmt.Sprintf(`%3d: <a href="clip:///%v">%v</a>`, i+1, i, html.EscapeString(pv))))
+	}
+	cbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	ctv.CursorStartDoc()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// OpenClipURL opens given clip:/// url from the Clipboard tab: pastes the
+// clipboard history entry of that index into the active text view, also
+// putting it on the clipboard
+func (ge *GideView) OpenClipURL(ur string) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("GideView OpenClipURL parse err: %v\n", err)
+		return false
+	}
+	idx, err := strconv.Atoi(up.Path[1:]) // has double //
+	if err != nil || idx < 0 || idx >= len(giv.TextViewClipHistory) {
+		return false
+	}
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	clip := giv.TextViewClipHistory[idx]
+	updt := ge.VPort().Win.UpdateStart()
+	oswin.TheApp.ClipBoard(ge.Viewport.Win.OSWin).Write(mimedata.NewTextBytes(clip))
+	tv.InsertAtCursor(clip)
+	tv.SavePosHistory(tv.CursorPos)
+	ge.VPort().Win.UpdateEnd(updt)
+	tv.GrabFocus()
+	return true
+}
+
+// Inspector shows the live tree of the widgets in this window in the
+// Inspector tab, with their layout and number of props, for debugging the
+// layout of custom views, e.g., of plugins: each node has links to show its
+// props, re-render it, and edit it in the GoGi Editor -- the tree is a
+// snapshot, shown again with the refresh link
+func (ge *GideView) Inspector() {
+	ibuf, itv, _ := ge.RecycleCmdTab("Inspector", true, true)
+	lns, paths := gide.InspectTree(ge.Viewport, ge.MainTabByName("Inspector"))
+	outlns := make([][]byte, 0, len(lns)+2)
+	outmus := make([][]byte, 0, len(lns)+2)
+	lstr := fmt.Sprintf("Inspector: %v nodes in window: %v", len(lns), ge.Nm)
+	outlns = append(outlns, []byte(lstr+"  refresh"), []byte(""))
+	outmus = append(outmus, []byte("<b>"+html.EscapeString(lstr)+`</b>  <a href="inspect:///refresh">refresh</a>`), []byte(""))
+	for i, ln := range lns {
+		pu := url.QueryEscape(paths[i])
+		outlns = append(outlns, []byte(ln+"  props re-render edit"))
+		outmus = append(outmus, []byte(fmt.Sprintf(`%v  <a href="inspect:///props#%v">props</a> <a href="inspect:///rerender#%v">re-render</a> <a href="inspect:///edit#%v">edit</a>`, html.EscapeString(ln), pu, pu, pu)))
+	}
+	ibuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	itv.CursorStartDoc()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// OpenInspectURL opens given inspect:/// url from the Inspector tab: the
+// path is the action, props, rerender or edit, or refresh of the tree, and
+// the fragment is the unique path of the node
+func (ge *GideView) OpenInspectURL(ur string) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("GideView OpenInspectURL parse err: %v\n", err)
+		return false
+	}
+	act := up.Path[1:] // has double //
+	if act == "refresh" {
+		ge.Inspector()
+		return true
+	}
+	k := ge.Viewport.FindPathUnique(up.Fragment)
+	if k == nil {
+		ge.SetStatus(fmt.Sprintf("Node no longer exists: %v -- refresh the Inspector", up.Fragment))
+		return false
+	}
+	switch act {
+	case "props":
+		pbuf, ptv, _ := ge.RecycleCmdTab("Inspector Props", true, true)
+		pbuf.SetText([]byte(strings.Join(gide.InspectProps(k), "\n")))
+		ptv.CursorStartDoc()
+	case "rerender":
+		nb, ok := k.Embed(gi.KiT_Node2DBase).(*gi.Node2DBase)
+		if !ok {
+			return false
+		}
+		nb.SetFullReRender()
+		nb.UpdateSig()
+		ge.SetStatus("Re-rendered: " + k.Name())
+	case "edit":
+		giv.GoGiEditorDialog(k)
+	default:
+		return false
+	}
+	return true
+}
+
+// CommentOut comments-out selected lines in active text view
+// and uncomments if already commented
+// If multiple lines are selected and any line is uncommented all will be commented
+// The comment strings for each language can be set in the language prefs,
+// including block comments around the lines
+func (ge *GideView) CommentOut() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	sel := tv.Selection()
+	var stl, etl int
+	if sel == nil {
+		stl = tv.CursorPos.Ln
+		etl = stl + 1
+	} else {
+		stl = sel.Reg.Start.Ln
+		etl = sel.Reg.End.Ln
+		if sel.Reg.End.Ch > 0 || etl == stl { // include the partly selected last line
+			etl++
+		}
+	}
+	tv.CommentOut(stl, etl)
+	tv.SelectReset()
+	return true
+}
+
+// Indent indents selected lines in active view
+func (ge *GideView) Indent() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	sel := tv.Selection()
+	if sel == nil {
+		return false
+	}
+	// todo: add indent chars to langs
+	tv.Buf.AutoIndentRegion(sel.Reg.Start.Ln, sel.Reg.End.Ln, giv.DefaultIndentStrings, giv.DefaultUnindentStrings)
+	tv.SelectReset()
+	return true
+}
+
+// TableFormat reformats (aligns) the Markdown table at the cursor in the
+// active view
+func (ge *GideView) TableFormat() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	if !tv.TableFormat() {
+		ge.SetStatus("Cursor is not within a Markdown table")
+		return false
+	}
+	return true
+}
+
+// TableAddColumn adds an empty column after the one at the cursor in the
+// Markdown table at the cursor in the active view
+func (ge *GideView) TableAddColumn() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	if !tv.TableAddColumn() {
+		ge.SetStatus("Cursor is not within a Markdown table")
+		return false
+	}
+	return true
+}
+
+// TableDeleteColumn deletes the column at the cursor in the Markdown table
+// at the cursor in the active view
+func (ge *GideView) TableDeleteColumn() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	if !tv.TableDeleteColumn() {
+		ge.SetStatus("Cursor is not within a Markdown table")
+		return false
+	}
+	return true
+}
+
+// TableFromCSV converts the CSV text selected in the active view to a
+// Markdown table
+func (ge *GideView) TableFromCSV() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	if err := tv.TableFromCSV(); err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not convert CSV to table: %v", err))
+		return false
+	}
+	return true
+}
+
+// TransformSel applies the transformation of given name to the selection in
+// the active view, e.g., Sort Lines or Base64 Encode (see
+// gide.TransformNames) -- it is undone as one operation
+func (ge *GideView) TransformSel(transform string) bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	if err := tv.TransformSel(transform); err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not %v: %v", transform, err))
+		return false
+	}
+	return true
+}
+
+// FormatSQL formats the selected SQL in the active view, or the whole file
+// if there is no selection: keywords are upper-cased and each clause starts
+// on a new line
+func (ge *GideView) FormatSQL() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	if !gide.IsSQLFile(string(tv.Buf.Filename)) && !tv.HasSelection() {
+		ge.SetStatus("Format SQL only applies to .sql files, or selected SQL")
+		return false
+	}
+	indent := "\t"
+	if ge.Prefs.Editor.SpaceIndent {
+		indent = strings.Repeat(" ", ge.Prefs.Editor.TabSize)
+	}
+	return tv.SQLFormat(indent)
+}
+
+// ViewNotebook shows the Jupyter notebook (.ipynb) in the Notebook tab, as
+// a read-only sequence of its markdown and code cells, with their outputs --
+// use Open File to edit the raw JSON
+func (ge *GideView) ViewNotebook(fname gi.FileName) {
+	nb, err := gide.OpenNotebook(string(fname))
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not open notebook: %v", err))
+		return
+	}
+	ge.NotebookFile = fname
+	lns, mus := nb.Render()
+	buf, ntv, _ := ge.RecycleCmdTab("Notebook", true, true)
+	buf.AppendTextMarkup([]byte(strings.Join(lns, "\n")), []byte(strings.Join(mus, "\n")), false, true)
+	ntv.CursorStartDoc()
+	ge.SetStatus(fmt.Sprintf("Notebook: %v: %v cells", ge.Files.RelPath(fname), len(nb.Cells)))
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// ExtractNotebookCode writes the code cells of the Jupyter notebook to a
+// new source file next to it, named by the notebook and its language (e.g.,
+// analysis.py for analysis.ipynb), and opens it
+func (ge *GideView) ExtractNotebookCode(fname gi.FileName) {
+	nb, err := gide.OpenNotebook(string(fname))
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not open notebook: %v", err))
+		return
+	}
+	ext, _ := nb.CodeExt()
+	np := strings.TrimSuffix(string(fname), filepath.Ext(string(fname))) + ext
+	if _, err := os.Stat(np); err == nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "File Exists", Prompt: fmt.Sprintf("The file: %v already exists -- rename or delete it first to extract the notebook code", np)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	if err := ioutil.WriteFile(np, []byte(nb.Code()), 0644); err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not write notebook code: %v", err))
+		return
+	}
+	ge.Files.UpdateNewFile(np)
+	ge.NextViewFile(gi.FileName(np))
+}
+
+// ConfigPreviewTab configures the Preview tab for showing given file,
+// returning its layout, which is cleared, with a toolbar having the given
+// info label and actions for the file -- must call UpdateEnd(updt) on the
+// layout after adding the preview
+func (ge *GideView) ConfigPreviewTab(fname gi.FileName, info string) (ly *gi.Layout, tb *gi.ToolBar, updt bool) {
+	ge.StopAudio()
+	ge.PreviewFile = fname
+	ly = ge.RecycleMainTab("Preview", gi.KiT_Layout, true).Embed(gi.KiT_Layout).(*gi.Layout)
+	updt = ly.UpdateStart()
+	ly.SetFullReRender()
+	ly.DeleteChildren(true)
+	ly.Lay = gi.LayoutVert
+	ly.SetStretchMaxWidth()
+	ly.SetStretchMaxHeight()
+	ly.SetProp("overflow", "auto")
+	tb = gi.AddNewToolBar(ly, "toolbar")
+	tb.SetStretchMaxWidth()
+	gi.AddNewLabel(tb, "info", info)
+	tb.AddSeparator("sep-info")
+	tb.AddAction(gi.ActOpts{Label: "Open Externally", Icon: "file-open", Tooltip: "open the file in the default application for its type, using the Open File command"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			gee, _ := recv.Embed(KiT_GideView).(*GideView)
+			gee.ExecCmdNameFileName(string(gee.PreviewFile), gide.CmdName("Open File"), true, true)
+		})
+	return
+}
+
+// PreviewFont shows a specimen sheet of the font file in the Preview tab,
+// with the character set and sample text at a range of sizes
+func (ge *GideView) PreviewFont(fname gi.FileName) {
+	width := 800
+	if sz := ge.MainTabs().LayData.AllocSize.X; sz > float32(width) {
+		width = int(sz) - 40
+	}
+	img, err := gide.FontSpecimen(string(fname), width, gi.Prefs.Colors.Font, gi.Prefs.Colors.Background)
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not preview font: %v", err))
+		return
+	}
+	ly, _, updt := ge.ConfigPreviewTab(fname, "<b>"+ge.Files.RelPath(fname)+"</b>")
+	bm := gi.AddNewBitmap(ly, "specimen")
+	bm.SetImage(img, 0, 0)
+	bm.LayoutToImgSize()
+	ly.UpdateEnd(updt)
+	ge.SetStatus(fmt.Sprintf("Font: %v", ge.Files.RelPath(fname)))
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// PreviewAudio shows the waveform of the audio file in the Preview tab,
+// with actions to play and stop it -- WAV files are read directly, and
+// other formats need ffmpeg to show the waveform
+func (ge *GideView) PreviewAudio(fname gi.FileName) {
+	aw, err := gide.OpenAudioWave(string(fname))
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not preview audio: %v", err))
+		return
+	}
+	width := 800
+	if sz := ge.MainTabs().LayData.AllocSize.X; sz > float32(width) {
+		width = int(sz) - 40
+	}
+	img := aw.Image(width, 200, gi.Prefs.Colors.Highlight, gi.Prefs.Colors.Background)
+	info := fmt.Sprintf("<b>%v</b>: %v channels, %v Hz, %v", ge.Files.RelPath(fname), aw.Chans, aw.Rate, aw.Duration().Round(time.Millisecond))
+	ly, tb, updt := ge.ConfigPreviewTab(fname, info)
+	tb.AddAction(gi.ActOpts{Label: "Play", Icon: "play", Tooltip: "play the audio file, using the first of the available audio players (ffplay, afplay, paplay, aplay)"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			gee, _ := recv.Embed(KiT_GideView).(*GideView)
+			gee.PlayAudio()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "stop playing the audio file"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			gee, _ := recv.Embed(KiT_GideView).(*GideView)
+			gee.StopAudio()
+		})
+	bm := gi.AddNewBitmap(ly, "waveform")
+	bm.SetImage(img, 0, 0)
+	bm.LayoutToImgSize()
+	ly.UpdateEnd(updt)
+	ge.SetStatus(fmt.Sprintf("Audio: %v", ge.Files.RelPath(fname)))
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// PlayAudio plays the audio file shown in the Preview tab, stopping any
+// that is already playing
+func (ge *GideView) PlayAudio() {
+	if ge.PreviewFile == "" {
+		return
+	}
+	ge.StopAudio()
+	cmd, err := gide.AudioPlayerCmd(string(ge.PreviewFile))
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not play audio: %v", err))
+		return
+	}
+	ge.AudioPlayer = cmd
+	go cmd.Wait()
+	ge.SetStatus(fmt.Sprintf("Playing: %v", ge.Files.RelPath(ge.PreviewFile)))
+}
+
+// StopAudio stops the audio player, if playing
+func (ge *GideView) StopAudio() {
+	if ge.AudioPlayer == nil {
+		return
+	}
+	if ge.AudioPlayer.Process != nil {
+		ge.AudioPlayer.Process.Kill()
+	}
+	ge.AudioPlayer = nil
+}
+
+// PasteImage saves the image on the clipboard into the project assets
+// directory, and inserts a link to it at the cursor in the active (Markdown)
+// view -- this is also done automatically by the regular paste key if the
+// clipboard only has an image
+func (ge *GideView) PasteImage() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	if err := tv.PasteImage(ge.Prefs.AssetsDir, true); err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not paste image: %v", err))
+		return false
+	}
+	return true
+}
+
+// ToggleProseMode toggles prose (writing) mode for the file type of the
+// active view, applying it to all views of that type -- the setting is
+// saved in the Lang Opts
+func (ge *GideView) ToggleProseMode() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	sup := tv.Buf.Info.Sup
+	lo, has := gide.AvailLangs[sup]
+	if !has {
+		lo = &gide.LangOpts{}
+		gide.AvailLangs[sup] = lo
+	}
+	lo.ProseMode = !lo.ProseMode
+	gide.AvailLangsChanged = true
+	for i := 0; i < NTextViews; i++ {
+		vtv := ge.TextViewByIndex(i)
+		if vtv.Buf != nil && vtv.Buf.Info.Sup == sup {
+			vtv.SetProseMode(lo.ProseMode, &ge.Prefs.Editor)
+			ge.ApplyViewOpts(vtv)
+		}
+	}
+	ge.SetStatus(fmt.Sprintf("Prose mode for %v files: %v", sup, lo.ProseMode))
+}
+
+// BufViewOpts returns the view options for the buffer: the per-file
+// overrides in the project prefs if set, else those of the editor prefs
+func (ge *GideView) BufViewOpts(tb *giv.TextBuf) *gide.ViewOpts {
+	if vo, ok := ge.Prefs.ViewOpts[ge.Files.RelPath(tb.Filename)]; ok {
+		return vo
+	}
+	vo := ge.Prefs.Editor.ViewOpts()
+	return &vo
+}
+
+// ApplyViewOpts sets the word wrap, line numbers and tab size of the text
+// view according to the view options for its buffer, and its rulers
+// according to the language of the file (none in prose mode), and starts
+// marking its changed lines and unknown words
+func (ge *GideView) ApplyViewOpts(tv *gide.TextView) {
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	if tv.ProseMode {
+		tv.SetRulers(nil, false)
+	} else {
+		tv.SetRulers(gide.LangRulers(tv.Buf.Info.Sup, &ge.Prefs.Editor), ge.Prefs.Editor.LongLines)
+	}
+	tv.Rainbow = ge.Prefs.Editor.Rainbow
+	tv.HiOccurs = ge.Prefs.Editor.HiOccurs
+	tv.VcsGutter = ge.Prefs.Editor.VcsGutter
+	tv.SpellInline = ge.Prefs.Editor.SpellInline
+	tv.Abbrevs = ge.Prefs.Editor.Abbrevs
+	tv.PairPrefs = &ge.Prefs.Editor.Pairs
+	tv.TagEdit = ge.Prefs.Editor.TagEdit
+	tv.ColorSwatch = ge.Prefs.Editor.ColorSwatch
+	tv.DataCheck = ge.Prefs.Editor.DataCheck
+	tv.SetViewOpts(ge.BufViewOpts(tv.Buf))
+	tv.UpdateVcsGutter()
+	tv.UpdateSpellInline()
+	tv.UpdateDataCheck()
+}
+
+// EditViewOpts calls fun to modify the per-file view options of the file
+// in the active view, creating them from the editor prefs if not yet set,
+// and applies them to all the views of the file
+func (ge *GideView) EditViewOpts(fun func(vo *gide.ViewOpts)) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	if ge.Prefs.ViewOpts == nil {
+		ge.Prefs.ViewOpts = make(gide.ViewOptsMap)
+	}
+	fnm := ge.Files.RelPath(tv.Buf.Filename)
+	vo, ok := ge.Prefs.ViewOpts[fnm]
+	if !ok {
+		evo := ge.Prefs.Editor.ViewOpts()
+		vo = &evo
+		ge.Prefs.ViewOpts[fnm] = vo
+	}
+	fun(vo)
+	ge.Prefs.Changed = true
+	ge.UpdateBufViews(tv.Buf)
+	ge.SetStatus(fmt.Sprintf("View opts for %v: word wrap: %v, line numbers: %v, tab size: %v", fnm, vo.WordWrap, vo.LineNos, vo.TabSize))
+}
+
+// UpdateBufViews applies the view options to all the views of the buffer
+func (ge *GideView) UpdateBufViews(tb *giv.TextBuf) {
+	for i := 0; i < NTextViews; i++ {
+		vtv := ge.TextViewByIndex(i)
+		if vtv != nil && vtv.Buf != nil && vtv.Buf.This() == tb.This() {
+			ge.ApplyViewOpts(vtv)
+		}
+	}
+}
+
+// ToggleWordWrap toggles word wrap for the file in the active view, which
+// is saved in the project prefs
+func (ge *GideView) ToggleWordWrap() {
+	ge.EditViewOpts(func(vo *gide.ViewOpts) {
+		vo.WordWrap = !vo.WordWrap
+	})
+}
+
+// ToggleLineNos toggles line numbers for the file in the active view, which
+// is saved in the project prefs
+func (ge *GideView) ToggleLineNos() {
+	ge.EditViewOpts(func(vo *gide.ViewOpts) {
+		vo.LineNos = !vo.LineNos
+	})
+}
+
+// SetTabSize sets the tab size for the file in the active view, which is
+// saved in the project prefs
+func (ge *GideView) SetTabSize(tabSize int) {
+	if tabSize < 1 {
+		return
+	}
+	ge.EditViewOpts(func(vo *gide.ViewOpts) {
+		vo.TabSize = tabSize
+	})
+}
+
+// ResetViewOpts removes the view option overrides for the file in the
+// active view, so it uses the editor prefs again
+func (ge *GideView) ResetViewOpts() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	fnm := ge.Files.RelPath(tv.Buf.Filename)
+	if _, ok := ge.Prefs.ViewOpts[fnm]; !ok {
+		return
+	}
+	delete(ge.Prefs.ViewOpts, fnm)
+	ge.Prefs.Changed = true
+	ge.Prefs.Editor.ConfigTextBuf(tv.Buf)
+	ge.UpdateBufViews(tv.Buf)
+	ge.SetStatus(fmt.Sprintf("View opts for %v: using the editor prefs", fnm))
+}
+
+// ToggleWhitespace toggles showing spaces, tabs and line endings as faint
+// glyphs in the active view, per the editor Whitespace prefs
+func (ge *GideView) ToggleWhitespace() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	tv.SetWhitespace(!tv.Whitespace, &ge.Prefs.Editor.Whitespace)
+	ge.SetStatus(fmt.Sprintf("Show whitespace: %v", tv.Whitespace))
+}
+
+// ToggleWhitespaceAll toggles showing whitespace in all the views, turning
+// it on if it is off in the active view, and off otherwise
+func (ge *GideView) ToggleWhitespaceAll() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	on := !tv.Whitespace
+	for i := 0; i < NTextViews; i++ {
+		ge.TextViewByIndex(i).SetWhitespace(on, &ge.Prefs.Editor.Whitespace)
+	}
+	ge.SetStatus(fmt.Sprintf("Show whitespace in all views: %v", on))
+}
+
+// ToggleKeyDisplay toggles the display of pressed key chords, and the key
+// functions they execute, at the bottom of the window -- for screencasts and
+// teaching
+func (ge *GideView) ToggleKeyDisplay() {
+	if ge.KeyDisplay != nil {
+		ge.StopKeyDisplay()
+		ge.SetStatus("Key display off")
+		return
+	}
+	win := ge.ParentWindow()
+	if win == nil {
+		return
+	}
+	ge.KeyDisplay = gide.NewKeyDisplay(win, &ge.Sty)
+	ge.SetStatus("Key display on")
+}
+
+// StopKeyDisplay turns off the display of pressed keys, if on
+func (ge *GideView) StopKeyDisplay() {
+	if ge.KeyDisplay == nil {
+		return
+	}
+	ge.KeyDisplay.Stop()
+	ge.KeyDisplay = nil
+}
+
+// ShowKey adds the key chord(s) and the gide or gi key function they
+// execute to the key display, if on
+func (ge *GideView) ShowKey(chord string, kf gide.KeyFuns, gkf gi.KeyFuns) {
+	if ge.KeyDisplay == nil {
+		return
+	}
+	fun := ""
+	switch {
+	case kf != gide.KeyFunNil:
+		fun = strings.TrimPrefix(kf.String(), "KeyFun")
+	case gkf != gi.KeyFunNil:
+		fun = strings.TrimPrefix(gkf.String(), "KeyFun")
+	}
+	ge.KeyDisplay.Add(gide.KeyDisplayText(chord, fun))
+}
+
+// JumpBracket moves the cursor in the active view to the matching bracket,
+// or the start of the enclosing bracket pair
+func (ge *GideView) JumpBracket() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	tv.JumpBracket()
+}
+
+// JumpTag moves the cursor in the active view to the tag matching the one
+// at the cursor, in HTML and XML files, or else to the start tag of the
+// enclosing element
+func (ge *GideView) JumpTag() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	if !tv.JumpTag() {
+		ge.SetStatus("No matching or enclosing tag -- only in HTML and XML files")
+	}
+}
+
+// NextOccur moves the cursor in the active view to the next occurrence of
+// the identifier at the cursor, wrapping around to the first
+func (ge *GideView) NextOccur() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	if !tv.NextOccur() {
+		ge.SetStatus("No other occurrences of an identifier at the cursor")
+	}
+}
+
+// JumpVisible shows letter labels on the words and line starts visible in
+// the active view -- typing a label moves the cursor there
+func (ge *GideView) JumpVisible() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	if !tv.StartJump() {
+		ge.SetStatus("Nothing visible to jump to")
+		return
+	}
+	ge.SetStatus("Jump to Visible: type a label, or any other key to cancel")
+}
+
+// GotoPrompt prompts for a target to go to in the active view, with the
+// current line and column as the default (see Goto)
+func (ge *GideView) GotoPrompt() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	cur := fmt.Sprintf("%v:%v", tv.CursorPos.Ln+1, tv.CursorPos.Ch+1)
+	gi.StringPromptDialog(ge.Viewport, cur, "120, +15, -10, 50%, 120:8 or file.go:120:8",
+		gi.DlgOpts{Title: "Goto", Prompt: "Line to go to: a line number, lines down (+) or up (-) from the cursor, or a percentage of the lines -- any of which can be followed by :column -- or else file:line:column, opening the file first"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dlg := send.(*gi.Dialog)
+			if sig == int64(gi.DialogAccepted) {
+				ge.Goto(gi.StringPromptDialogValue(dlg))
+			}
 		})
+}
+
+// Goto moves the cursor to given target, e.g., 120, +15, -10, 50%, 120:8 or
+// file.go:120:8 (see gide.ParseGoto) -- the file form opens the file first,
+// found relative to the file of the active view, or else in the project --
+// returns false if the target is not valid or its file is not found
+func (ge *GideView) Goto(target string) bool {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return false
+	}
+	gt, err := gide.ParseGoto(target, tv.CursorPos.Ln+1, tv.NLines)
+	if err != nil {
+		ge.SetStatus(err.Error())
+		return false
+	}
+	if gt.File != "" {
+		fnm := gt.File
+		if !filepath.IsAbs(fnm) && tv.Buf.Filename != "" {
+			rp := filepath.Join(filepath.Dir(string(tv.Buf.Filename)), fnm)
+			if _, err := os.Stat(rp); err == nil {
+				fnm = rp
+			}
+		}
+		ntv, _, ok := ge.ViewFile(gi.FileName(fnm))
+		if !ok {
+			ge.SetStatus(fmt.Sprintf("Goto: file %v not found in the project", gt.File))
+			return false
+		}
+		tv = ntv
+		if gt.Ln == 0 {
+			return true
+		}
 	}
+	pos := gt.Pos(tv.NLines)
+	updt := tv.Viewport.Win.UpdateStart()
+	tv.SelectReset()
+	tv.SetCursorShow(pos)
+	tv.SavePosHistory(tv.CursorPos)
+	tv.Viewport.Win.UpdateEnd(updt)
+	tv.GrabFocus()
+	return true
 }
 
-//////////////////////////////////////////////////////////////////////////////////////
-//    TextView functions
+// OpenGotoURL opens given goto:///<target> url, going to the target (see
+// Goto), or prompting for one if empty, e.g., from the line and column in the
+// statusbar
+func (ge *GideView) OpenGotoURL(ur string) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("GideView OpenGotoURL parse err: %v\n", err)
+		return false
+	}
+	target := up.Path[1:] // has double //
+	if target == "" {
+		ge.GotoPrompt()
+		return true
+	}
+	return ge.Goto(target)
+}
 
-// CursorToHistPrev moves cursor to previous position on history list --
-// returns true if moved
-func (ge *GideView) CursorToHistPrev() bool {
+// PrevOccur moves the cursor in the active view to the previous occurrence
+// of the identifier at the cursor, wrapping around to the last
+func (ge *GideView) PrevOccur() {
 	tv := ge.ActiveTextView()
-	return tv.CursorToHistPrev()
+	if tv == nil {
+		return
+	}
+	if !tv.PrevOccur() {
+		ge.SetStatus("No other occurrences of an identifier at the cursor")
+	}
 }
 
-// CursorToHistNext moves cursor to next position on history list --
-// returns true if moved
-func (ge *GideView) CursorToHistNext() bool {
+// ReformatFile reformats the whole file in the active view, with the
+// formatter for its language if set in the language prefs and installed,
+// else by re-indenting its lines by their bracket nesting -- the number of
+// changed lines is shown in the status bar, and it can be undone
+func (ge *GideView) ReformatFile() {
 	tv := ge.ActiveTextView()
-	return tv.CursorToHistNext()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	n, fmtr, err := tv.ReformatFile()
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Reformat File failed: %v", err))
+		return
+	}
+	if fmtr == "" {
+		fmtr = "re-indent"
+	}
+	if n == 0 {
+		ge.SetStatus(fmt.Sprintf("Reformat File (%v): no changes", fmtr))
+		return
+	}
+	ge.SetStatus(fmt.Sprintf("Reformat File (%v): %v lines changed -- Undo to revert", fmtr, n))
 }
 
-//////////////////////////////////////////////////////////////////////////////////////
-//    Find / Replace
+// ExpandSel expands the selection in the active view to the next larger
+// syntactic unit
+func (ge *GideView) ExpandSel() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	tv.ExpandSel()
+}
 
-// Find does Find / Replace in files, using given options and filters -- opens up a
-// main tab with the results and further controls.
-func (ge *GideView) Find(find, repl string, ignoreCase bool, loc gide.FindLoc, langs []filecat.Supported) {
-	if find == "" {
+// ShrinkSel shrinks the selection in the active view back to what it was
+// before the last ExpandSel
+func (ge *GideView) ShrinkSel() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
 		return
 	}
-	ge.Prefs.Find.IgnoreCase = ignoreCase
-	ge.Prefs.Find.Langs = langs
-	ge.Prefs.Find.Loc = loc
+	tv.ShrinkSel()
+}
 
-	fbuf, _ := ge.RecycleCmdBuf("Find", true)
-	fvi := ge.RecycleMainTab("Find", gide.KiT_FindView, true) // sel
-	fv := fvi.Embed(gide.KiT_FindView).(*gide.FindView)
-	fv.Config(ge)
-	fv.Time = time.Now()
-	ftv := fv.TextView()
-	ftv.SetInactive()
-	ftv.SetBuf(fbuf)
+// DupLine duplicates the current line, or the selected lines, in the active
+// view
+func (ge *GideView) DupLine() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	tv.DupLines()
+}
 
-	fv.SaveFindString(find)
-	fv.SaveReplString(repl)
+// MoveLineUp moves the current line, or the selected lines, in the active
+// view up one line, re-indenting them as needed
+func (ge *GideView) MoveLineUp() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	tv.MoveLines(true)
+}
 
-	root := ge.Files.Embed(giv.KiT_FileNode).(*giv.FileNode)
+// MoveLineDown moves the current line, or the selected lines, in the
+// active view down one line, re-indenting them as needed
+func (ge *GideView) MoveLineDown() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	tv.MoveLines(false)
+}
 
-	atv := ge.ActiveTextView()
-	ond, _, got := ge.OpenNodeForTextView(atv)
-	adir := ""
-	if got {
-		adir, _ = filepath.Split(string(ond.FPath))
+// DeleteLine deletes the current line, or the selected lines, in the active
+// view
+func (ge *GideView) DeleteLine() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
 	}
+	tv.DeleteLines()
+}
 
-	var res []gide.FileSearchResults
-	if loc == gide.FindLocFile {
-		if got {
-			cnt, matches := atv.Buf.Search([]byte(find), ignoreCase)
-			res = append(res, gide.FileSearchResults{ond, cnt, matches})
-		}
+// ToggleZenMode toggles distraction-free (zen) editing mode, which collapses
+// the file tree, tabs, toolbar and statusbar, leaving only the active text
+// view, with its width limited to the editor ZenWidth -- toggling off
+// restores the named split config that was in use
+func (ge *GideView) ToggleZenMode() {
+	sv := ge.SplitView()
+	updt := ge.UpdateStart()
+	ge.SetFullReRender()
+	ge.ZenMode = !ge.ZenMode
+	tb := ge.ToolBar()
+	sb := ge.StatusBar()
+	if ge.ZenMode {
+		ge.ZenSplits = append([]float32{}, sv.Splits...)
+		splits := make([]float32, len(sv.Splits))
+		splits[TextView1Idx+ge.ActiveTextViewIdx] = 1
+		sv.SetSplitsAction(splits...)
+		tv := ge.ActiveTextView()
+		tv.SetProp("max-width", units.NewValue(float32(ge.Prefs.Editor.ZenWidth), units.Ch))
+		tv.SetFullReRender()
+		tb.SetMinPrefHeight(units.NewValue(0, units.Px))
+		tb.SetProp("max-height", units.NewValue(0, units.Px))
+		sb.SetMinPrefHeight(units.NewValue(0, units.Px))
+		sb.SetProp("max-height", units.NewValue(0, units.Px))
 	} else {
-		res = gide.FileTreeSearch(root, find, ignoreCase, loc, adir, langs)
+		for i := 0; i < NTextViews; i++ {
+			tv := ge.TextViewByIndex(i)
+			if tv.ProseMode {
+				tv.SetProp("max-width", units.NewValue(float32(ge.Prefs.Editor.ProseWidth), units.Ch))
+			} else {
+				tv.DeleteProp("max-width")
+			}
+			tv.SetFullReRender()
+		}
+		tb.DeleteProp("min-height")
+		tb.DeleteProp("height")
+		tb.DeleteProp("max-height")
+		sb.SetMinPrefHeight(units.NewValue(1.2, units.Em))
+		sb.DeleteProp("max-height")
+		if _, _, ok := gide.AvailSplits.SplitByName(ge.Prefs.SplitName); ok {
+			ge.SplitsSetView(ge.Prefs.SplitName)
+		} else if len(ge.ZenSplits) > 0 {
+			sv.SetSplitsAction(ge.ZenSplits...)
+		}
+		ge.SetStatus("Zen mode off")
 	}
+	ge.UpdateEnd(updt)
+	ge.ActiveTextView().GrabFocus()
+}
 
-	outlns := make([][]byte, 0, 100)
-	outmus := make([][]byte, 0, 100) // markups
-	for _, fs := range res {
-		fp := fs.Node.Info.Path
-		fn := fs.Node.MyRelPath()
-		fbStLn := len(outlns) // find buf start ln
-		lstr := fmt.Sprintf(`%v: %v`, fn, fs.Count)
-		outlns = append(outlns, []byte(lstr))
-		mstr := fmt.Sprintf(`<b>%v</b>`, lstr)
-		outmus = append(outmus, []byte(mstr))
-		for _, mt := range fs.Matches {
-			ln := mt.Reg.Start.Ln + 1
-			ch := mt.Reg.Start.Ch + 1
-			ech := mt.Reg.End.Ch + 1
-			fnstr := fmt.Sprintf("%v:%d:%d", fn, ln, ch)
-			nomu := bytes.Replace(mt.Text, []byte("<mark>"), nil, -1)
-			nomu = bytes.Replace(nomu, []byte("</mark>"), nil, -1)
-			nomus := html.EscapeString(string(nomu))
-			lstr = fmt.Sprintf(`%v: %s`, fnstr, nomus) // note: has tab embedded at start of lstr
-
-			outlns = append(outlns, []byte(lstr))
-			mstr = fmt.Sprintf(`	<a href="find:///%v#R%vN%vL%vC%v-L%vC%v">%v</a>: %s`, fp, fbStLn, fs.Count, ln, ch, ln, ech, fnstr, mt.Text)
-			outmus = append(outmus, []byte(mstr))
-		}
-		outlns = append(outlns, []byte(""))
-		outmus = append(outmus, []byte(""))
+// ShowDocs shows a popup with documentation for the symbol at the cursor in
+// the active view -- use PinDocs to show the full docs in the Docs tab
+func (ge *GideView) ShowDocs() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
 	}
-	ltxt := bytes.Join(outlns, []byte("\n"))
-	mtxt := bytes.Join(outmus, []byte("\n"))
-	fbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
-	ftv.CursorStartDoc()
-	ok := ftv.CursorNextLink(false) // no wrap
-	if ok {
-		ftv.OpenLinkAt(ftv.CursorPos)
+	if !gide.DocSupported(tv.Buf) {
+		ge.SetStatus(fmt.Sprintf("Documentation not available for language: %v", tv.Buf.Info.Sup))
+		return
 	}
-	ge.FocusOnPanel(MainTabsIdx)
+	tv.ShowDocs()
 }
 
-// Spell checks spelling in files
-func (ge *GideView) Spell() {
-	fbuf, _ := ge.RecycleCmdBuf("Spell", true)
-	sv := ge.RecycleMainTab("Spell", gide.KiT_SpellView, true).Embed(gide.KiT_SpellView).(*gide.SpellView)
-	sv.Config(ge, ge.Prefs.Spell)
-	stv := sv.TextView()
-	stv.SetInactive()
-	stv.SetBuf(fbuf)
-
-	fp := string(ge.ActiveFilename)
-	if fp == "" {
+// PinDocs shows the full documentation for the symbol at the cursor in the
+// active view in the Docs tab, with clickable cross-references
+func (ge *GideView) PinDocs() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	doc, err := gide.DocAt(tv.Buf, tv.CursorPos)
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Documentation lookup failed: %v", err))
 		return
 	}
+	ge.DocsLang = tv.Buf.Info.Sup
+	ge.DocsDir = gide.DocDir(tv.Buf)
+	ge.SetDocs(doc)
+}
 
-	tv := ge.ActiveTextView()
-	gi.InitSpell()
-	text := tv.Buf.LinesToBytesCopy()
-	gi.InitNewSpellCheck(text)
-	tw, suggests, err := gi.NextUnknownWord()
+// SetDocs sets the contents of the Docs tab to given documentation text
+func (ge *GideView) SetDocs(doc string) {
+	dbuf, _ := ge.RecycleCmdBuf("Docs", true)
+	dtv := ge.RecycleVisTabTextView("Docs", true)
+	dtv.SetInactive()
+	dtv.SetBuf(dbuf)
+	dbuf.AppendTextMarkup([]byte(doc), []byte(gide.DocMarkup(doc)), false, true)
+	dtv.CursorStartDoc()
+}
+
+// OpenDocURL opens given doc:/// cross-reference url from the Docs tab,
+// showing the docs for the referenced name
+func (ge *GideView) OpenDocURL(ur string) bool {
+	nm := strings.TrimPrefix(ur, "doc:///")
+	doc, err := gide.DocForName(ge.DocsLang, ge.DocsDir, nm)
 	if err != nil {
-		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Error Running Spell Check", Prompt: fmt.Sprintf("%v", err)}, gi.AddOk, gi.NoCancel, nil, nil)
+		ge.SetStatus(fmt.Sprintf("Documentation lookup failed for: %v: %v", nm, err))
+		return false
 	}
-	sv.SetUnknownAndSuggest(tw, suggests)
-	ge.FocusOnPanel(MainTabsIdx)
+	ge.SetDocs(doc)
+	return true
 }
 
-// Symbols displays the Symbols of a file or package
-func (ge *GideView) Symbols() {
+// SignatureHelp shows the signature of the function being called at the
+// cursor in the active view, with the current param highlighted
+func (ge *GideView) SignatureHelp() {
 	tv := ge.ActiveTextView()
 	if tv == nil || tv.Buf == nil {
 		return
 	}
-	sv := ge.RecycleMainTab("Symbols", gide.KiT_SymbolsView, true).Embed(gide.KiT_SymbolsView).(*gide.SymbolsView)
-	sv.Config(ge, ge.Prefs.Symbols)
-	ge.FocusOnPanel(MainTabsIdx)
+	tv.SignatureHelp()
 }
 
-// ParseOpenFindURL parses and opens given find:/// url from Find, return text
-// region encoded in url, and starting line of results in find buffer, and
-// number of results returned -- for parsing all the find results
-func (ge *GideView) ParseOpenFindURL(ur string, ftv *giv.TextView) (tv *gide.TextView, reg giv.TextRegion, findBufStLn, findCount int, ok bool) {
-	up, err := url.Parse(ur)
-	if err != nil {
-		log.Printf("FindView OpenFindURL parse err: %v\n", err)
+// CallHierarchy shows the callers and callees of the function at the cursor
+// in the active view in the Call Hierarchy tab, as a tree that expands to
+// their callers and callees in turn, with links to the functions and call
+// sites (Go files, via gopls, which reads the saved file)
+func (ge *GideView) CallHierarchy() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
 		return
 	}
-	fpath := up.Path[1:] // has double //
-	pos := up.Fragment
-	tv, _, ok = ge.LinkViewFile(gi.FileName(fpath))
-	if !ok {
-		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Open File at Link", Prompt: fmt.Sprintf("Could not find or open file path in project: %v", fpath)}, gi.AddOk, gi.NoCancel, nil, nil)
+	if tv.Buf.Info.Sup != filecat.Go {
+		ge.SetStatus(fmt.Sprintf("Call hierarchy not available for language: %v", tv.Buf.Info.Sup))
 		return
 	}
-	if pos == "" {
+	pos := tv.CursorPos
+	lt := tv.Buf.Line(pos.Ln)
+	ch := pos.Ch
+	if ch > len(lt) {
+		ch = len(lt)
+	}
+	bcol := len(string(lt[:ch])) + 1
+	chy, err := gide.CallHierarchyAt(string(tv.Buf.Filename), pos.Ln+1, bcol)
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Call hierarchy failed: %v", err))
 		return
 	}
-
-	lidx := strings.Index(pos, "L")
-	if lidx > 0 {
-		reg.FromString(pos[lidx:])
-		pos = pos[:lidx]
+	ge.CallTree = gide.NewCallTree(chy)
+	ge.ShowCallTree()
+	if tv.Buf.IsChanged() {
+		ge.SetStatus("Call hierarchy is from the saved file -- save to update it")
 	}
-	fmt.Sscanf(pos, "R%dN%d", &findBufStLn, &findCount)
-	return
 }
 
-// OpenFindURL opens given find:/// url from Find -- delegates to FindView
-func (ge *GideView) OpenFindURL(ur string, ftv *giv.TextView) bool {
-	fvk := ftv.ParentByType(gide.KiT_FindView, true)
-	if fvk == nil {
-		return false
+// ShowCallTree shows the call hierarchy in the Call Hierarchy tab
+func (ge *GideView) ShowCallTree() {
+	if ge.CallTree == nil {
+		return
 	}
-	fv := fvk.(*gide.FindView)
-	return fv.OpenFindURL(ur, ftv)
+	cbuf, ctv, _ := ge.RecycleCmdTab("Call Hierarchy", true, true)
+	lns, mus := ge.CallTree.Lines(string(ge.ProjRoot))
+	cbuf.AppendTextMarkup(bytes.Join(lns, []byte("\n")), bytes.Join(mus, []byte("\n")), false, true)
+	ctv.CursorStartDoc()
+	ge.FocusOnPanel(MainTabsIdx)
 }
 
-// OpenSpellURL opens given spell:/// url from Spell -- delegates to SpellView
-func (ge *GideView) OpenSpellURL(ur string, stv *giv.TextView) bool {
-	svk := stv.ParentByType(gide.KiT_SpellView, true)
-	if svk == nil {
+// OpenCallsURL opens given calls:/// url from the Call Hierarchy tab: the
+// #toggle fragment expands or collapses the node of that id
+func (ge *GideView) OpenCallsURL(ur string) bool {
+	if ge.CallTree == nil {
 		return false
 	}
-	fv := svk.(*gide.SpellView)
-	return fv.OpenSpellURL(ur, stv)
-}
-
-// ReplaceInActive does query-replace in active file only
-func (ge *GideView) ReplaceInActive() {
-	tv := ge.ActiveTextView()
-	tv.QReplacePrompt()
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("GideView OpenCallsURL parse err: %v\n", err)
+		return false
+	}
+	id, err := strconv.Atoi(up.Path[1:]) // has double //
+	if err != nil {
+		return false
+	}
+	cn := ge.CallTree.NodeByID(id)
+	if cn == nil {
+		return false
+	}
+	ge.CallTree.Toggle(cn)
+	ge.ShowCallTree()
+	return true
 }
 
-func (ge *GideView) OpenFileAtRegion(filename gi.FileName, tr giv.TextRegion) (tv *gide.TextView, ok bool) {
-	tv, _, ok = ge.LinkViewFile(filename)
-	if tv != nil {
-		tv.UpdateStart()
-		tv.Highlights = tv.Highlights[:0]
-		tv.Highlights = append(tv.Highlights, tr)
-		tv.UpdateEnd(true)
-		tv.RefreshIfNeeded()
-		tv.SetCursorShow(tr.Start)
-		tv.GrabFocus()
-		return tv, true
+//////////////////////////////////////////////////////////////////////////////////////
+//    Crash Journal
 
+// StartJournal starts (or restarts) the timer for periodic crash-recovery
+// journal snapshots, per the JournalSecs preference
+func (ge *GideView) StartJournal() {
+	if ge.JournalTimer != nil {
+		ge.JournalTimer.Stop()
+		ge.JournalTimer = nil
 	}
-	return nil, false
+	if gide.Prefs.JournalSecs <= 0 || ge.ProjRoot == "" {
+		return
+	}
+	ge.JournalTimer = time.AfterFunc(time.Duration(gide.Prefs.JournalSecs)*time.Second, func() {
+		ge.RunOnGUI(func() {
+			ge.SaveJournal()
+			ge.StartJournal()
+		})
+	})
 }
 
-//////////////////////////////////////////////////////////////////////////////////////
-//    Registers
+// StopJournal stops the journal timer and deletes the journal -- called when
+// the project is closed normally
+func (ge *GideView) StopJournal() {
+	if ge.JournalTimer != nil {
+		ge.JournalTimer.Stop()
+		ge.JournalTimer = nil
+	}
+	if ge.ProjRoot != "" {
+		gide.DeleteJournal(ge.ProjRoot)
+	}
+}
 
-// RegisterCopy saves current selection in active text view to register of given name
-// returns true if saved
-func (ge *GideView) RegisterCopy(name string) bool {
-	if name == "" {
-		return false
+// SaveJournal saves a snapshot of all unsaved buffers (including scratch
+// buffers) and the session state to the crash-recovery journal -- if there
+// are no unsaved buffers, any existing journal is deleted.  It must be
+// called on the event loop goroutine (see RunOnGUI), as it walks the buffers
+// and views.
+func (ge *GideView) SaveJournal() {
+	if ge.Locked {
+		return // views are blanked, and nothing is edited while locked
 	}
-	tv := ge.ActiveTextView()
-	if tv.Buf == nil {
-		return false
+	jn := &gide.Journal{ProjRoot: ge.ProjRoot, Time: time.Now(), Active: ge.ActiveTextViewIdx}
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf == nil || !ond.Buf.IsChanged() {
+			continue
+		}
+		if _, ok := ge.FileCrypts[ond.FPath]; ok {
+			continue // plaintext only in memory
+		}
+		jb := gide.JournalBuf{Filename: ond.FPath, Sup: ond.Info.Sup, Text: string(ond.Buf.LinesToBytesCopy())}
+		if tv, _, ok := ge.TextViewForFileNode(ond); ok {
+			jb.Cursor = tv.CursorPos
+		}
+		jn.Bufs = append(jn.Bufs, jb)
 	}
-	sel := tv.Selection()
-	if sel == nil {
-		return false
+	if len(jn.Bufs) == 0 {
+		gide.DeleteJournal(ge.ProjRoot)
+		return
 	}
-	if gide.AvailRegisters == nil {
-		gide.AvailRegisters = make(gide.Registers, 100)
+	jn.Splits = append(jn.Splits, ge.SplitView().Splits...)
+	for i := 0; i < NTextViews; i++ {
+		tv := ge.TextViewByIndex(i)
+		if tv.Buf != nil {
+			jn.Views = append(jn.Views, tv.Buf.Filename)
+		} else {
+			jn.Views = append(jn.Views, "")
+		}
+	}
+	if err := jn.Save(); err != nil {
+		log.Printf("GideView SaveJournal: could not save crash-recovery journal: %v\n", err)
 	}
-	gide.AvailRegisters[name] = string(sel.ToBytes())
-	gide.AvailRegisters.SavePrefs()
-	ge.Prefs.Register = gide.RegisterName(name)
-	tv.SelectReset()
-	return true
 }
 
-// RegisterPaste pastes register of given name into active text view
-// returns true if pasted
-func (ge *GideView) RegisterPaste(name gide.RegisterName) bool {
-	if name == "" {
-		return false
+// RecoverJournalCheck checks for a crash-recovery journal left by a previous
+// session of this project, and offers to recover it -- this replaces the
+// per-file auto-save prompts for the files in the journal.  Starts the
+// journal timer for this session.
+func (ge *GideView) RecoverJournalCheck() {
+	jn := gide.OpenJournal(ge.ProjRoot)
+	if jn == nil {
+		ge.StartJournal()
+		return
 	}
-	str, ok := gide.AvailRegisters[string(name)]
-	if !ok {
-		return false
+	var fl []string
+	for _, jb := range jn.Bufs {
+		fl = append(fl, html.EscapeString(string(jb.Filename)))
 	}
-	tv := ge.ActiveTextView()
-	if tv.Buf == nil {
-		return false
+	gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "Recover Previous Session",
+		Prompt: fmt.Sprintf("Project: %v was not closed normally -- the previous session, saved at %v, had <b>%v</b> files with <b>unsaved changes</b>:<br>%v<br>Recover the previous session, including the unsaved changes?  If you discard it, the changes are lost.", ge.Nm, jn.Time.Format("2006-01-02 15:04:05"), len(jn.Bufs), strings.Join(fl, "<br>"))},
+		[]string{"Recover", "Discard"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			gee, _ := recv.Embed(KiT_GideView).(*GideView)
+			if sig == 0 {
+				gee.RecoverJournal(jn)
+			} else {
+				gide.DeleteJournal(gee.ProjRoot)
+			}
+			gee.StartJournal()
+		})
+}
+
+// RecoverJournal restores the unsaved buffers and session state from given
+// journal -- recovered buffers are marked as changed, and any auto-save
+// files for them are removed, as the journal supersedes them
+func (ge *GideView) RecoverJournal(jn *gide.Journal) {
+	for _, jb := range jn.Bufs {
+		var fn *giv.FileNode
+		if strings.HasPrefix(string(jb.Filename), gide.ScratchPrefix) {
+			fn = gide.NewScratchNode(&ge.Files, strings.TrimPrefix(string(jb.Filename), gide.ScratchPrefix), jb.Sup)
+			ge.ConfigTextBuf(fn.Buf)
+			ge.OpenNodes.Add(fn)
+		} else {
+			fnk, ok := ge.Files.FindFile(string(jb.Filename))
+			if !ok {
+				continue
+			}
+			fn = fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode)
+			if _, err := ge.OpenFileNode(fn); err != nil {
+				continue
+			}
+			fn.Buf.AutoSaveDelete()
+			fn.Buf.Autosave = true
+		}
+		fn.Buf.SetText([]byte(jb.Text))
+		fn.Buf.SetChanged()
 	}
-	tv.InsertAtCursor([]byte(str))
-	ge.Prefs.Register = name
-	return true
+	if len(jn.Splits) == len(ge.SplitView().Splits) {
+		ge.SplitView().SetSplitsAction(jn.Splits...)
+	}
+	for i, vf := range jn.Views {
+		if i >= NTextViews || vf == "" {
+			continue
+		}
+		for _, ond := range ge.OpenNodes {
+			if ond.FPath != vf {
+				continue
+			}
+			tv := ge.TextViewByIndex(i)
+			ge.ViewFileNode(tv, i, ond)
+			for _, jb := range jn.Bufs {
+				if jb.Filename == vf {
+					tv.SetCursorShow(jb.Cursor)
+				}
+			}
+			break
+		}
+	}
+	if jn.Active >= 0 && jn.Active < NTextViews {
+		ge.SetActiveTextViewIdx(jn.Active)
+	}
+	gide.DeleteJournal(ge.ProjRoot)
+	ge.Files.UpdateNewFile(string(ge.ProjRoot)) // removed auto-save files
+	ge.SetStatus(fmt.Sprintf("Recovered %v files from previous session", len(jn.Bufs)))
 }
 
-// CommentOut comments-out selected lines in active text view
-// and uncomments if already commented
-// If multiple lines are selected and any line is uncommented all will be commented
-func (ge *GideView) CommentOut() bool {
-	tv := ge.ActiveTextView()
-	if tv.Buf == nil {
-		return false
+// ActivityEvent connects to key presses and mouse clicks at the lowest
+// priority, even if processed, to record the time of the last activity
+// for the idle lock, and of the last key press for deferred prompts
+func (ge *GideView) ActivityEvent() {
+	ge.ConnectEvent(oswin.KeyChordEvent, gi.LowRawPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		gee := recv.Embed(KiT_GideView).(*GideView)
+		gee.SetActive()
+		gee.Dialogs.KeyPressed()
+	})
+	ge.ConnectEvent(oswin.MouseEvent, gi.LowRawPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		gee := recv.Embed(KiT_GideView).(*GideView)
+		gee.SetActive()
+	})
+}
+
+// PromptChoice shows a non-critical prompt with given choices, in given
+// mode (see the Dialogs prefs): as a modal dialog right away, as a modal
+// dialog once typing pauses, so it does not swallow keystrokes, or as a
+// non-modal notice in the Notices tab -- fun is called with the index of
+// the choice made
+func (ge *GideView) PromptChoice(mode gide.DialogModes, title, prompt string, choices []string, fun func(choice int)) {
+	if mode == gide.DialogBanner {
+		ge.Notices.Add(title, prompt, choices, fun)
+		ge.ShowNotices(false)
+		ge.SetStatus(fmt.Sprintf("Notice: %v -- see the Notices tab", title))
+		return
 	}
-	sel := tv.Selection()
-	var stl, etl int
-	if sel == nil {
-		stl = tv.CursorPos.Ln
-		etl = stl + 1
-	} else {
-		stl = sel.Reg.Start.Ln
-		etl = sel.Reg.End.Ln
+	show := func() {
+		gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: title, Prompt: prompt}, choices,
+			ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				fun(int(sig))
+			})
 	}
-	tv.Buf.CommentRegion(stl, etl)
-	tv.SelectReset()
-	return true
+	if mode == gide.DialogModal {
+		show()
+		return
+	}
+	ge.Dialogs.Show(gide.Prefs.Dialogs.TypingPause(), show)
+}
+
+// ViewNotices shows the non-modal prompts in the Notices tab
+func (ge *GideView) ViewNotices() {
+	ge.ShowNotices(true)
+}
+
+// ShowNotices shows the non-modal prompts in the Notices tab, most recent
+// first, with links for the choices of those with no choice made yet -- if
+// sel, the tab is selected
+func (ge *GideView) ShowNotices(sel bool) {
+	nbuf, ntv, _ := ge.RecycleCmdTab("Notices", sel, true)
+	outlns := make([][]byte, 0, len(ge.Notices)+1)
+	outmus := make([][]byte, 0, len(ge.Notices)+1)
+	lstr := fmt.Sprintf("Notices: %v pending", ge.Notices.Pending())
+	outlns = append(outlns, []byte(lstr))
+	outmus = append(outmus, []byte("<b>"+html.EscapeString(lstr)+"</b>"))
+	for i := len(ge.Notices) - 1; i >= 0; i-- {
+		nt := ge.Notices[i]
+		hd := fmt.Sprintf("%v %v: %v", nt.Time.Format("15:04:05"), nt.Title, nt.Prompt)
+		ln := hd
+		mu := html.EscapeString(hd)
+		if nt.Done {
+			ln += " (done)"
+			mu += " (done)"
+		} else {
+			for ci, ch := range nt.Choices {
+				ln += "  " + ch
+				mu += fmt.Sprintf(`  <a href="notice:///%v#%v">%v</a>`, nt.ID, ci, html.EscapeString(ch))
+			}
+		}
+		outlns = append(outlns, []byte(ln))
+		outmus = append(outmus, []byte(mu))
+	}
+	nbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	ntv.CursorStartDoc()
 }
 
-// Indent indents selected lines in active view
-func (ge *GideView) Indent() bool {
-	tv := ge.ActiveTextView()
-	if tv.Buf == nil {
+// OpenNoticeURL opens given notice:/// url from the Notices tab, making the
+// choice of the fragment index for the notice of the path id
+func (ge *GideView) OpenNoticeURL(ur string) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("GideView OpenNoticeURL parse err: %v\n", err)
 		return false
 	}
-	sel := tv.Selection()
-	if sel == nil {
+	id, err := strconv.Atoi(up.Path[1:]) // has double //
+	if err != nil {
 		return false
 	}
-	// todo: add indent chars to langs
-	tv.Buf.AutoIndentRegion(sel.Reg.Start.Ln, sel.Reg.End.Ln, giv.DefaultIndentStrings, giv.DefaultUnindentStrings)
-	tv.SelectReset()
+	ci, err := strconv.Atoi(up.Fragment)
+	if err != nil {
+		return false
+	}
+	nt := ge.Notices.ByID(id)
+	if nt == nil || !nt.Choose(ci) {
+		return false
+	}
+	ge.ShowNotices(true)
 	return true
 }
 
@@ -1816,6 +5758,15 @@ func (ge *GideView) SetStatus(msg string) {
 			if tv.Buf.Info.Sup != filecat.NoSupport {
 				fnm += " (" + tv.Buf.Info.Sup.String() + ")"
 			}
+			if tv.Buf.Filename != "" {
+				fnm += " " + ge.BufEnc(tv.Buf).String()
+				if kind, ok := ge.FileCrypts[tv.Buf.Filename]; ok {
+					fnm += " [encrypted: " + kind + "]"
+				}
+			}
+			if tv.Pinned {
+				fnm += " [pinned]"
+			}
 		}
 		if tv.ISearch.On {
 			msg = fmt.Sprintf("\tISearch: %v (n=%v)\t%v", tv.ISearch.Find, len(tv.ISearch.Matches), msg)
@@ -1825,7 +5776,7 @@ func (ge *GideView) SetStatus(msg string) {
 		}
 	}
 
-	str := fmt.Sprintf("%v\t<b>%v:</b>\t(%v,%v)\t%v", ge.Nm, fnm, ln, ch, msg)
+	str := fmt.Sprintf("%v\t<b>%v:</b>\t<a href=\"goto:///\">(%v,%v)</a>\t%v", ge.Nm, fnm, ln, ch, msg)
 	lbl.SetText(str)
 	sb.UpdateEnd(updt)
 }
@@ -1835,11 +5786,9 @@ func (ge *GideView) SetStatus(msg string) {
 
 // Defaults sets new project defaults based on overall preferences
 func (ge *GideView) Defaults() {
-	ge.Prefs.Files = gide.Prefs.Files
-	ge.Prefs.Editor = gide.Prefs.Editor
-	ge.Prefs.Splits = []float32{.1, .325, .325, .25, 0}
+	ge.Project.Defaults()
 	ge.Files.DirsOnTop = ge.Prefs.Files.DirsOnTop
-	ge.Files.NodeType = gide.KiT_FileNode
+	ge.Files.NodeType = gide.FileNodeType
 }
 
 // GrabPrefs grabs the current project preference settings from various
@@ -1848,6 +5797,9 @@ func (ge *GideView) GrabPrefs() {
 	sv := ge.SplitView()
 	ge.Prefs.Splits = sv.Splits
 	ge.Prefs.OpenDirs = ge.Files.OpenDirs
+	for i := 0; i < NTextViews; i++ {
+		ge.RememberFilePos(ge.TextViewByIndex(i))
+	}
 }
 
 // ApplyPrefs applies current project preference settings into places where
@@ -1858,11 +5810,15 @@ func (ge *GideView) ApplyPrefs() {
 	ge.Files.OpenDirs = ge.Prefs.OpenDirs
 	ge.Files.DirsOnTop = ge.Prefs.Files.DirsOnTop
 	histyle.StyleDefault = gide.Prefs.HiStyle
+	gide.ApplyColorPreset(gide.Prefs.ColorPresetFor(ge.HiStyle()))
 	if len(ge.Kids) > 0 {
-		sv := ge.SplitView()
 		for i := 0; i < NTextViews; i++ {
-			txly := sv.Child(1 + i).(*gi.Layout)
-			txed := txly.Child(0).Embed(giv.KiT_TextView).(*giv.TextView)
+			txed := ge.TextViewByIndex(i)
+			if !ge.Prefs.EditorBg.IsNil() {
+				txed.SetProp("background-color", ge.Prefs.EditorBg)
+			} else {
+				txed.DeleteProp("background-color")
+			}
 			if txed.Buf != nil {
 				ge.ConfigTextBuf(txed.Buf)
 			}
@@ -1882,6 +5838,34 @@ func (ge *GideView) ApplyPrefsAction() {
 	ge.Config()
 }
 
+// GideViewHiStyles gets the highlighting styles for submenu-func, with an
+// item to use the global style first
+func GideViewHiStyles(it interface{}, vp *gi.Viewport2D) []string {
+	nms := []string{GideViewGlobalStyle}
+	for nm := range histyle.AvailStyles {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms[1:])
+	return nms
+}
+
+// GideViewGlobalStyle is the style switcher item for using the global
+// highlighting style in the gide prefs
+var GideViewGlobalStyle = "(Global Style)"
+
+// SetHiStyle sets the highlighting style for this project, in the project
+// prefs, applying it to all the open buffers -- GideViewGlobalStyle or empty
+// uses the global style
+func (ge *GideView) SetHiStyle(style string) {
+	if style == GideViewGlobalStyle {
+		style = ""
+	}
+	ge.Prefs.HiStyle = histyle.StyleName(style)
+	ge.Prefs.Changed = true
+	ge.ApplyPrefsAction()
+	ge.SetStatus(fmt.Sprintf("Highlighting style: %v", ge.HiStyle()))
+}
+
 // EditProjPrefs allows editing of project preferences (settings specific to this project)
 func (ge *GideView) EditProjPrefs() {
 	sv, _ := gide.ProjPrefsView(&ge.Prefs)
@@ -1892,6 +5876,29 @@ func (ge *GideView) EditProjPrefs() {
 	})
 }
 
+// ImportProjConfigs imports what it can from the configs of other IDEs and
+// tools in the project root -- VS Code settings and tasks, JetBrains IDE run
+// configurations and Makefile targets (see gide.Project.ImportConfigs) --
+// into the project prefs, and the custom commands, and shows what it did
+func (ge *GideView) ImportProjConfigs() {
+	pi := ge.ImportConfigs()
+	if len(pi.Notes) == 0 {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Nothing to Import", Prompt: "No .vscode/settings.json, .vscode/tasks.json, .idea run configurations or Makefile were found in the project root: " + string(ge.ProjRoot)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	if len(pi.Cmds) > 0 {
+		gide.CustomCmds.AddCmds(pi.Cmds)
+		gide.CustomCmds.SavePrefs()
+	}
+	ge.Prefs.Changed = true
+	ge.ApplyPrefsAction()
+	var b strings.Builder
+	for _, n := range pi.Notes {
+		fmt.Fprintf(&b, "%v<br>\n", html.EscapeString(n))
+	}
+	gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Imported Project Configs", Prompt: fmt.Sprintf("%v commands were added to the custom commands, and the project prefs were updated -- save the project to keep them:<br>\n<br>\n%v", len(pi.Cmds), b.String())}, gi.AddOk, gi.NoCancel, nil, nil)
+}
+
 // SplitsSetView sets split view splitters to given named setting
 func (ge *GideView) SplitsSetView(split gide.SplitName) {
 	sv := ge.SplitView()
@@ -1956,6 +5963,7 @@ func (ge *GideView) Config() {
 	ge.SetStatus("just updated")
 	if mods {
 		ge.OpenConsoleTab()
+		ge.RunningJobs.Watch(ge.JobsChanged)
 	}
 	ge.UpdateEnd(updt)
 }
@@ -1989,7 +5997,7 @@ func (ge *GideView) TextViewByIndex(idx int) *gide.TextView {
 		return nil
 	}
 	split := ge.SplitView()
-	svk := split.Child(TextView1Idx + idx).Child(0)
+	svk := split.Child(TextView1Idx + idx).Child(0).Child(0)
 	return svk.Embed(gide.KiT_TextView).(*gide.TextView)
 }
 
@@ -2061,6 +6069,55 @@ var fnFolderProps = ki.Props{
 	"icon-off": "folder",
 }
 
+// ConfigMinimaps adds or removes the minimaps next to the text views,
+// according to the Minimap editor prefs
+func (ge *GideView) ConfigMinimaps() {
+	split := ge.SplitView()
+	for i := 0; i < NTextViews; i++ {
+		txly := split.Child(TextView1Idx + i).(*gi.Layout)
+		tv := ge.TextViewByIndex(i)
+		has := txly.NumChildren() > 1
+		switch {
+		case ge.Prefs.Editor.Minimap && !has:
+			mm := txly.AddNewChild(gide.KiT_Minimap, fmt.Sprintf("minimap-%v", i)).(*gide.Minimap)
+			mm.TextView = tv
+			tv.Minimap = mm
+		case !ge.Prefs.Editor.Minimap && has:
+			tv.Minimap = nil
+			txly.DeleteChildAtIndex(1, true)
+		}
+	}
+}
+
+// DiagLinkRe matches the links to file lines in command output
+var DiagLinkRe = regexp.MustCompile(`file:///([^#"]+)#L(\d+)`)
+
+// DiagLines returns the lines (0-based) of given file that have problems
+// reported in the command output tabs, e.g., build errors or lint, from
+// the links to the file in their output -- relative paths in the links
+// match by suffix
+func (ge *GideView) DiagLines(fpath gi.FileName) []int {
+	fp := string(fpath)
+	var lns []int
+	for _, buf := range ge.CmdBufs {
+		for _, mu := range buf.Markup {
+			if !bytes.Contains(mu, []byte("file:///")) {
+				continue
+			}
+			for _, m := range DiagLinkRe.FindAllSubmatch(mu, -1) {
+				lp := filepath.Clean(string(m[1]))
+				if lp != fp && !(!filepath.IsAbs(lp) && strings.HasSuffix(fp, string(filepath.Separator)+lp)) {
+					continue
+				}
+				if ln, err := strconv.Atoi(string(m[2])); err == nil && ln > 0 {
+					lns = append(lns, ln-1)
+				}
+			}
+		}
+	}
+	return lns
+}
+
 // ConfigSplitView configures the SplitView.
 func (ge *GideView) ConfigSplitView() {
 	split := ge.SplitView()
@@ -2106,7 +6163,13 @@ func (ge *GideView) ConfigSplitView() {
 			txly.SetMinPrefWidth(units.NewValue(20, units.Ch))
 			txly.SetMinPrefHeight(units.NewValue(10, units.Ch))
 			if !txly.HasChildren() {
-				ted := txly.AddNewChild(gide.KiT_TextView, fmt.Sprintf("textview-%v", i)).(*gide.TextView)
+				// text view scrolls within its own layout, with the minimap next to it
+				tvly := gi.AddNewLayout(txly, fmt.Sprintf("textlay-%v", i), gi.LayoutHoriz)
+				tvly.SetStretchMaxWidth()
+				tvly.SetStretchMaxHeight()
+				tvly.SetMinPrefWidth(units.NewValue(20, units.Ch))
+				tvly.SetMinPrefHeight(units.NewValue(10, units.Ch))
+				ted := tvly.AddNewChild(gide.KiT_TextView, fmt.Sprintf("textview-%v", i)).(*gide.TextView)
 				ted.TextViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 					gee, _ := recv.Embed(KiT_GideView).(*GideView)
 					tee := send.Embed(gide.KiT_TextView).(*gide.TextView)
@@ -2131,9 +6194,9 @@ func (ge *GideView) ConfigSplitView() {
 		split.SetSplits(ge.Prefs.Splits...)
 		split.UpdateEnd(updt)
 	}
+	ge.ConfigMinimaps()
 	for i := 0; i < NTextViews; i++ {
-		txly := split.Child(1 + i).(*gi.Layout)
-		txed := txly.Child(0).(*gide.TextView)
+		txed := ge.TextViewByIndex(i)
 		if ge.Prefs.Editor.WordWrap {
 			txed.SetProp("white-space", gi.WhiteSpacePreWrap)
 		} else {
@@ -2141,6 +6204,7 @@ func (ge *GideView) ConfigSplitView() {
 		}
 		txed.SetProp("tab-size", ge.Prefs.Editor.TabSize)
 		txed.SetProp("font-family", gide.Prefs.FontFamily)
+		ge.ApplyViewOpts(txed)
 	}
 
 	// set some properties always, even if no mods
@@ -2160,6 +6224,13 @@ var BigFileSize = 10000000 // 10Mb?
 
 // FileNodeOpened is called whenever file node is double-clicked in file tree
 func (ge *GideView) FileNodeOpened(fn *giv.FileNode, tvn *gide.FileTreeView) {
+	if !fn.IsDir() && gide.OpenFileViewer(ge, fn) {
+		return
+	}
+	if gide.IsNotebook(string(fn.FPath)) {
+		ge.ViewNotebook(fn.FPath)
+		return
+	}
 	// todo: could add all these options in LangOpts
 	switch fn.Info.Cat {
 	case filecat.Folder:
@@ -2179,10 +6250,10 @@ func (ge *GideView) FileNodeOpened(fn *giv.FileNode, tvn *gide.FileTreeView) {
 			cmd.Run(ge, cbuf)
 		}
 	case filecat.Font:
-		fallthrough
-	case filecat.Video:
-		fallthrough
+		ge.PreviewFont(fn.FPath)
 	case filecat.Audio:
+		ge.PreviewAudio(fn.FPath)
+	case filecat.Video:
 		ge.ExecCmdNameFileNode(fn, gide.CmdName("Open File"), true, true) // sel, clear
 	case filecat.Sheet:
 		ge.ExecCmdNameFileNode(fn, gide.CmdName("Open File"), true, true) // sel, clear
@@ -2196,12 +6267,21 @@ func (ge *GideView) FileNodeOpened(fn *giv.FileNode, tvn *gide.FileTreeView) {
 		ge.ExecCmdNameFileNode(fn, gide.CmdName("Open File"), true, true) // sel, clear
 	default:
 		// program, document, data
-		if int(fn.Info.Size) > BigFileSize {
-			gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "File is relatively large",
-				Prompt: fmt.Sprintf("The file: %v is relatively large at: %v -- really open for editing?", fn.Nm, fn.Info.Size)},
+		if gide.EncryptedKind(string(fn.FPath)) == "" && gide.IsBinaryFile(string(fn.FPath)) {
+			ge.PromptChoice(gide.Prefs.Dialogs.Binary, "File appears to be binary",
+				fmt.Sprintf("The file: %v appears to be binary, not text -- really open for editing?  All bytes are preserved when saving, but editing may corrupt it.", fn.Nm),
 				[]string{"Open", "Cancel"},
-				ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-					switch sig {
+				func(choice int) {
+					if choice == 0 {
+						ge.NextViewFileNode(fn)
+					}
+				})
+		} else if int(fn.Info.Size) > BigFileSize {
+			ge.PromptChoice(gide.Prefs.Dialogs.BigFile, "File is relatively large",
+				fmt.Sprintf("The file: %v is relatively large at: %v -- really open for editing?", fn.Nm, fn.Info.Size),
+				[]string{"Open", "Cancel"},
+				func(choice int) {
+					switch choice {
 					case 0:
 						ge.NextViewFileNode(fn)
 					case 1:
@@ -2229,7 +6309,20 @@ func (ge *GideView) GideViewKeys(kt *key.ChordEvent) {
 	if gi.KeyEventTrace {
 		fmt.Printf("GideView KeyInput: %v\n", ge.PathUnique())
 	}
+	if tv := ge.ActiveTextView(); tv != nil && tv.JumpKeyInput(kt) {
+		return
+	}
 	gkf := gi.KeyFun(kc)
+	if ge.KeySeq1 == "" {
+		if pa := gide.PluginActionForChord(kc); pa != nil && (pa.Active == nil || pa.Active(ge)) {
+			kt.SetProcessed()
+			if ge.KeyDisplay != nil {
+				ge.KeyDisplay.Add(gide.KeyDisplayText(string(kc), pa.Label))
+			}
+			pa.Func(ge)
+			return
+		}
+	}
 	if ge.KeySeq1 != "" {
 		kf = gide.KeyFun(ge.KeySeq1, kc)
 		seqstr := string(ge.KeySeq1) + " " + string(kc)
@@ -2245,6 +6338,7 @@ func (ge *GideView) GideViewKeys(kt *key.ChordEvent) {
 		ge.SetStatus(seqstr)
 		ge.KeySeq1 = ""
 		gkf = gi.KeyFunNil // override!
+		ge.ShowKey(seqstr, kf, gkf)
 	} else {
 		kf = gide.KeyFun(kc, "")
 		if kf == gide.KeyFunNeeds2 {
@@ -2261,6 +6355,7 @@ func (ge *GideView) GideViewKeys(kt *key.ChordEvent) {
 			}
 			gkf = gi.KeyFunNil // override!
 		}
+		ge.ShowKey(string(kc), kf, gkf)
 	}
 
 	switch gkf {
@@ -2271,6 +6366,9 @@ func (ge *GideView) GideViewKeys(kt *key.ChordEvent) {
 			ge.Prefs.Find.Find = string(tv.Selection().ToBytes())
 		}
 		giv.CallMethod(ge, "Find", ge.Viewport)
+	case gi.KeyFunJump:
+		kt.SetProcessed()
+		ge.GotoPrompt()
 	}
 	if kt.IsProcessed() {
 		return
@@ -2300,6 +6398,45 @@ func (ge *GideView) GideViewKeys(kt *key.ChordEvent) {
 	case gide.KeyFunBufClose:
 		kt.SetProcessed()
 		ge.CloseActiveView()
+	case gide.KeyFunBufReopen:
+		kt.SetProcessed()
+		ge.ReopenClosedFile()
+	case gide.KeyFunZenMode:
+		kt.SetProcessed()
+		ge.ToggleZenMode()
+	case gide.KeyFunJumpBracket:
+		kt.SetProcessed()
+		ge.JumpBracket()
+	case gide.KeyFunExpandSel:
+		kt.SetProcessed()
+		ge.ExpandSel()
+	case gide.KeyFunShrinkSel:
+		kt.SetProcessed()
+		ge.ShrinkSel()
+	case gide.KeyFunDupLine:
+		kt.SetProcessed()
+		ge.DupLine()
+	case gide.KeyFunMoveLineUp:
+		kt.SetProcessed()
+		ge.MoveLineUp()
+	case gide.KeyFunMoveLineDown:
+		kt.SetProcessed()
+		ge.MoveLineDown()
+	case gide.KeyFunDeleteLine:
+		kt.SetProcessed()
+		ge.DeleteLine()
+	case gide.KeyFunNextOccur:
+		kt.SetProcessed()
+		ge.NextOccur()
+	case gide.KeyFunPrevOccur:
+		kt.SetProcessed()
+		ge.PrevOccur()
+	case gide.KeyFunJumpVisible:
+		kt.SetProcessed()
+		ge.JumpVisible()
+	case gide.KeyFunJumpTag:
+		kt.SetProcessed()
+		ge.JumpTag()
 	case gide.KeyFunExecCmd:
 		kt.SetProcessed()
 		giv.CallMethod(ge, "ExecCmd", ge.Viewport)
@@ -2317,9 +6454,7 @@ func (ge *GideView) GideViewKeys(kt *key.ChordEvent) {
 		ge.Indent()
 	case gide.KeyFunJump:
 		kt.SetProcessed()
-		tv := ge.ActiveTextView()
-		tv.JumpToLinePrompt()
-		ge.Indent()
+		ge.GotoPrompt()
 	case gide.KeyFunSetSplit:
 		kt.SetProcessed()
 		giv.CallMethod(ge, "SplitsSetView", ge.Viewport)
@@ -2329,6 +6464,18 @@ func (ge *GideView) GideViewKeys(kt *key.ChordEvent) {
 	case gide.KeyFunRunProj:
 		kt.SetProcessed()
 		ge.Run()
+	case gide.KeyFunSigHelp:
+		kt.SetProcessed()
+		ge.SignatureHelp()
+	case gide.KeyFunDocs:
+		kt.SetProcessed()
+		ge.ShowDocs()
+	case gide.KeyFunSentenceNext:
+		kt.SetProcessed()
+		ge.ActiveTextView().SentenceNext()
+	case gide.KeyFunSentencePrev:
+		kt.SetProcessed()
+		ge.ActiveTextView().SentencePrev()
 	}
 }
 
@@ -2341,6 +6488,41 @@ func (ge *GideView) KeyChordEvent() {
 	})
 }
 
+// DropEvent connects to drops of files from outside the window (e.g., from
+// the OS file manager), which are opened -- see OpenDroppedPaths
+func (ge *GideView) DropEvent() {
+	ge.ConnectEvent(oswin.DNDEvent, gi.LowRawPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		gee := recv.Embed(KiT_GideView).(*GideView)
+		de := d.(*dnd.Event)
+		if de.Action != dnd.DropOnTarget || de.Source != nil || de.IsProcessed() {
+			return
+		}
+		_, paths := gide.DroppedFiles(de.Data, nil)
+		if len(paths) == 0 {
+			return
+		}
+		de.SetProcessed()
+		de.Mod = dnd.DropCopy
+		gee.OpenDroppedPaths(paths)
+	})
+}
+
+// OpenDroppedPaths opens files dropped onto the window: files within the
+// project are viewed, and other files and folders are opened as projects
+// (in a new window if this one already has a project open)
+func (ge *GideView) OpenDroppedPaths(paths []string) {
+	root := string(ge.ProjRoot)
+	for _, p := range paths {
+		if root != "" && strings.HasPrefix(p, root+string(filepath.Separator)) {
+			if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+				ge.NextViewFile(gi.FileName(p))
+				continue
+			}
+		}
+		ge.OpenPath(gi.FileName(p))
+	}
+}
+
 func (ge *GideView) Render2D() {
 	if len(ge.Kids) > 0 {
 		ge.ToolBar().UpdateActions()
@@ -2360,10 +6542,63 @@ func (ge *GideView) ConnectEvents2D() {
 		ge.LayoutScrollEvents()
 	}
 	ge.KeyChordEvent()
+	ge.DropEvent()
+	ge.ActivityEvent()
+	ge.GUIFuncEvent()
+}
+
+// RunOnGUI runs given function on the event loop goroutine of the window,
+// which is the only one that may change the views, buffers and file tree --
+// for use by timers and other goroutines.  It is queued, and a custom event
+// is sent to run it -- as the window only passes events to its widgets
+// while it has focus, functions queued while it does not are run when it
+// gets or loses focus.
+func (ge *GideView) RunOnGUI(fun func()) {
+	ge.GUIMu.Lock()
+	ge.GUIFuncs = append(ge.GUIFuncs, fun)
+	ge.GUIMu.Unlock()
+	if win := ge.ParentWindow(); win != nil {
+		win.SendCustomEvent(ge.This())
+	}
+}
+
+// RunGUIFuncs runs the functions queued by RunOnGUI -- must only be called
+// on the event loop goroutine
+func (ge *GideView) RunGUIFuncs() {
+	ge.GUIMu.Lock()
+	fns := ge.GUIFuncs
+	ge.GUIFuncs = nil
+	ge.GUIMu.Unlock()
+	for _, fun := range fns {
+		fun()
+	}
+}
+
+// GUIFuncEvent connects to the custom events sent by RunOnGUI, and to window
+// focus events, to run the functions queued by RunOnGUI
+func (ge *GideView) GUIFuncEvent() {
+	ge.ConnectEvent(oswin.CustomEventType, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		gee := recv.Embed(KiT_GideView).(*GideView)
+		ce := d.(*oswin.CustomEvent)
+		if ce.Data == gee.This() {
+			ce.SetProcessed()
+			gee.RunGUIFuncs()
+		}
+	})
+	ge.ConnectEvent(oswin.WindowFocusEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		gee := recv.Embed(KiT_GideView).(*GideView)
+		gee.RunGUIFuncs()
+	})
 }
 
 // Declaration looks up the declaration for the selected text and if found moves cursor and highlights
 func (ge *GideView) Declaration() {
+	if tv := ge.ActiveTextView(); tv != nil && tv.Buf != nil {
+		if fname, reg, ok := gide.LookupDecl(ge, tv.Buf, tv.CursorPos); ok {
+			ge.OpenFileAtRegion(fname, reg)
+			return
+		}
+	}
 	fmt.Println("Go to Declaration not yet implemented")
 	return
 
@@ -2434,6 +6669,15 @@ var GideViewInactiveTextViewFunc = giv.ActionUpdateFunc(func(gei interface{}, ac
 	act.SetInactiveState(ge.ActiveTextView().Buf == nil)
 })
 
+// GideViewInactiveNoServerFunc is an ActionUpdateFunc that inactivates action if no file server is running
+var GideViewInactiveNoServerFunc = giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+	ge := gei.(ki.Ki).Embed(KiT_GideView).(*GideView)
+	if !ge.IsConfiged() {
+		return
+	}
+	act.SetActiveState(ge.FileServer != nil)
+})
+
 // GideViewInactiveTextSelectionFunc is an ActionUpdateFunc that inactivates action there is no active text view
 var GideViewInactiveTextSelectionFunc = giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
 	ge := gei.(ki.Ki).Embed(KiT_GideView).(*GideView)
@@ -2558,6 +6802,11 @@ var GideViewProps = ki.Props{
 			"label": "Symbols",
 			"icon":  "structure",
 		}},
+		{"Outline", ki.Props{
+			"label": "Outline",
+			"icon":  "file-text",
+			"desc":  "headings outline of the Markdown, reStructuredText or AsciiDoc document, or resource blocks of the Terraform file, in the active view -- click to jump to a section, drag to reorder sections",
+		}},
 		{"Spell", ki.Props{
 			"label": "Spelling",
 			"icon":  "spelling",
@@ -2638,6 +6887,15 @@ var GideViewProps = ki.Props{
 					{"File Name", ki.Props{}},
 				},
 			}},
+			{"OpenRecentFile", ki.Props{
+				"label":        "Recent Files",
+				"desc":         "open a file recently viewed in this project",
+				"submenu-func": giv.SubMenuFunc(GideViewRecentFiles),
+				"updtfunc":     GideViewInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{}},
+				},
+			}},
 			{"OpenProj", ki.Props{
 				"shortcut": gi.KeyFunMenuOpen,
 				"label":    "Open Project...",
@@ -2677,12 +6935,51 @@ var GideViewProps = ki.Props{
 				{"NewFile", ki.Props{
 					"shortcut": gi.KeyFunMenuNewAlt1,
 					"label":    "New File...",
-					"desc":     "Create a new file in project -- to create in sub-folders, use context menu on folder in file browser",
+					"desc":     "Create a new file in project, with initial contents from a template (chosen by extension if not selected) -- to create in sub-folders, use context menu on folder in file browser",
+					"Args": ki.PropSlice{
+						{"File Name", ki.Props{
+							"width": 60,
+						}},
+						{"Template", ki.Props{
+							"desc": "template for the initial contents of the file -- if none, the template for the file's extension is used, if any",
+						}},
+						{"Add To Version Control", ki.Props{}},
+					},
+				}},
+				{"NewGoKiWidget", ki.Props{
+					"label": "New GoKi Widget...",
+					"desc":  "Create a new Go file in the project with a GoKi widget type, pre-wired with its KiT type registration, Props and Config",
 					"Args": ki.PropSlice{
 						{"File Name", ki.Props{
 							"width": 60,
 						}},
-						{"Add To Version Control", ki.Props{}},
+						{"Type Name", ki.Props{
+							"desc": "name of the widget type -- made from the file name if empty, e.g., MyView for my_view.go",
+						}},
+					},
+				}},
+				{"NewGoKiEditor", ki.Props{
+					"label": "New GoKi Editor Package...",
+					"desc":  "Create a new package in a new folder of the project for a GoKi editor app, like gide: an editor type pre-wired with its KiT type registration, toolbar and main menu Props, and window, and a cmd main package that opens it",
+					"Args": ki.PropSlice{
+						{"Folder", ki.Props{
+							"width": 60,
+						}},
+						{"Type Name", ki.Props{
+							"desc": "name of the editor type -- made from the folder name if empty",
+						}},
+					},
+				}},
+				{"NewScratchBuf", ki.Props{
+					"label": "New Scratch Buffer...",
+					"desc":  "Open a new scratch buffer, which is not associated with a file -- it is listed in the open buffers, and is discarded when closed unless saved as a file",
+					"Args": ki.PropSlice{
+						{"Name", ki.Props{
+							"width": 40,
+						}},
+						{"Lang", ki.Props{
+							"default": filecat.Go,
+						}},
 					},
 				}},
 			}},
@@ -2737,12 +7034,61 @@ var GideViewProps = ki.Props{
 					}},
 				},
 			}},
+			{"ExportHTML", ki.Props{
+				"label":    "Export HTML...",
+				"desc":     "export the selection in the active view, or the whole file, with its highlighting and line numbers, as a standalone HTML page",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"default-field": "ActiveFilename",
+					}},
+				},
+			}},
+			{"ExportPDF", ki.Props{
+				"label":    "Export PDF...",
+				"desc":     "export the selection in the active view, or the whole file, with its highlighting and line numbers, as a paginated PDF with the file name and date in the header",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"default-field": "ActiveFilename",
+					}},
+				},
+			}},
+			{"PrintActiveView", ki.Props{
+				"label":    "Print",
+				"desc":     "print the selection in the active view, or the whole file, with its highlighting, using lp if available, else by opening it as a PDF in the system viewer",
+				"updtfunc": GideViewInactiveTextViewFunc,
+			}},
 			{"RevertActiveView", ki.Props{
 				"desc":     "Revert active file to last saved version: this will lose all active changes -- are you sure?",
 				"confirm":  true,
 				"label":    "Revert File...",
 				"updtfunc": GideViewInactiveEmptyFunc,
 			}},
+			{"Encoding", ki.PropSlice{
+				{"ReopenWithEncoding", ki.Props{
+					"label":        "Reopen with Encoding",
+					"desc":         "re-open the file in the active view, decoding it from the selected encoding, which is then used when saving it -- any changes are lost",
+					"submenu-func": giv.SubMenuFunc(GideViewTextEncodings),
+					"updtfunc":     GideViewInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Encoding", ki.Props{}},
+					},
+				}},
+				{"SaveWithEncoding", ki.Props{
+					"label":        "Save with Encoding",
+					"desc":         "save the file in the active view in the selected encoding, which is used for subsequent saves -- the current encoding is shown in the status bar",
+					"submenu-func": giv.SubMenuFunc(GideViewTextEncodings),
+					"updtfunc":     GideViewInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Encoding", ki.Props{}},
+					},
+				}},
+				{"ToggleLineEndings", ki.Props{
+					"desc":     "toggle the line endings of the file in the active view between LF and CR LF (windows style), applied when it is next saved",
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
+			}},
 			{"CloseActiveView", ki.Props{
 				"label":    "Close File",
 				"updtfunc": GideViewInactiveEmptyFunc,
@@ -2750,11 +7096,24 @@ var GideViewProps = ki.Props{
 					return key.Chord(gide.ChordForFun(gide.KeyFunBufClose).String())
 				}),
 			}},
+			{"ReopenClosedFile", ki.Props{
+				"label":    "Reopen Closed File",
+				"desc":     "reopen the most recently closed file, at the same cursor position",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunBufReopen).String())
+				}),
+			}},
 			{"sep-prefs", ki.BlankProp{}},
 			{"EditProjPrefs", ki.Props{
 				"label":    "Project Prefs...",
 				"updtfunc": GideViewInactiveEmptyFunc,
 			}},
+			{"ImportProjConfigs", ki.Props{
+				"label":    "Import Project Configs",
+				"desc":     "import what can be translated from the configs of other IDEs and tools in the project root: the editor settings of .vscode/settings.json into the project prefs, and the tasks of .vscode/tasks.json, the run configurations of JetBrains IDEs in .idea and the Makefile targets as custom commands, setting the Build and Run commands",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
 			{"sep-close", ki.BlankProp{}},
 			{"Close Window", ki.BlankProp{}},
 		}},
@@ -2773,6 +7132,16 @@ var GideViewProps = ki.Props{
 			{"Paste History...", ki.Props{
 				"keyfun": gi.KeyFunPasteHist,
 			}},
+			{"ClipHistory", ki.Props{
+				"label":    "Clipboard History...",
+				"desc":     "show the clipboard history of the copies and cuts in all the editors, with only the entries containing the find string if it is not empty -- click on an entry, or press Enter on it, to paste it into the active editor",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"Find", ki.Props{
+						"width": 40,
+					}},
+				},
+			}},
 			{"Registers", ki.PropSlice{
 				{"RegisterCopy", ki.Props{
 					"label": "Copy...",
@@ -2798,6 +7167,35 @@ var GideViewProps = ki.Props{
 						}},
 					},
 				}},
+				{"RegisterAppend", ki.Props{
+					"label":    "Append...",
+					"desc":     "append currently-selected text to a named register, creating it if it does not exist",
+					"updtfunc": GideViewInactiveTextSelectionFunc,
+					"Args": ki.PropSlice{
+						{"Register Name", ki.Props{
+							"default-field": "Prefs.Register",
+						}},
+					},
+				}},
+				{"sep-rect", ki.BlankProp{}},
+				{"RegisterCopyRect", ki.Props{
+					"label":    "Copy Rectangle...",
+					"desc":     "save the rectangle of the selection -- the columns between its start and end, on each of its lines -- to a named register, for tabular edits",
+					"updtfunc": GideViewInactiveTextSelectionFunc,
+					"Args": ki.PropSlice{
+						{"Register Name", ki.Props{}},
+					},
+				}},
+				{"RegisterPasteRect", ki.Props{
+					"label":    "Paste Rectangle...",
+					"desc":     "paste text from named register as a rectangle: each of its lines is inserted at the column of the cursor on successive lines",
+					"updtfunc": GideViewInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Register Name", ki.Props{
+							"default-field": "Prefs.Register",
+						}},
+					},
+				}},
 			}},
 			{"sep-undo", ki.BlankProp{}},
 			{"Undo", ki.Props{
@@ -2806,6 +7204,16 @@ var GideViewProps = ki.Props{
 			{"Redo", ki.Props{
 				"keyfun": gi.KeyFunRedo,
 			}},
+			{"UndoLastOp", ki.Props{
+				"label":    "Undo Operation",
+				"desc":     "undo the last project-level operation that changed multiple files, e.g., Replace All or Run Command on Files, restoring all the buffers and files it changed",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"Operations", ki.Props{
+				"label":    "Operations...",
+				"desc":     "show the history of project-level operations that changed multiple files, with links to undo each of them",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
 			{"sep-find", ki.BlankProp{}},
 			{"Find", ki.Props{
 				"label":    "Find...",
@@ -2851,6 +7259,32 @@ var GideViewProps = ki.Props{
 				"keyfun":   gi.KeyFunComplete,
 				"updtfunc": GideViewInactiveEmptyFunc,
 			}},
+			{"SignatureHelp", ki.Props{
+				"label": "Signature Help",
+				"desc":  "show the signature of the function being called at the cursor, with the current param highlighted (Go files, via gopls)",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunSigHelp).String())
+				}),
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"ShowDocs", ki.Props{
+				"label": "Show Docs",
+				"desc":  "show documentation for the symbol at the cursor in a popup (Go via gopls, Python via pydoc)",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunDocs).String())
+				}),
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"PinDocs", ki.Props{
+				"label":    "Pin Docs",
+				"desc":     "show full documentation for the symbol at the cursor in the Docs tab, with clickable cross-references",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"CallHierarchy", ki.Props{
+				"label":    "Call Hierarchy",
+				"desc":     "show the callers and callees of the function at the cursor in the Call Hierarchy tab, as an expandable tree with links to the call sites (Go files, via gopls)",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
 			{"sep-adv", ki.BlankProp{}},
 			{"CommentOut", ki.Props{
 				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
@@ -2864,6 +7298,100 @@ var GideViewProps = ki.Props{
 				}),
 				"updtfunc": GideViewInactiveEmptyFunc,
 			}},
+			{"DupLine", ki.Props{
+				"label":    "Duplicate Line",
+				"desc":     "duplicate the current line, or the selected lines, below them",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunDupLine).String())
+				}),
+			}},
+			{"MoveLineUp", ki.Props{
+				"label":    "Move Line Up",
+				"desc":     "move the current line, or the selected lines, up one line -- they are re-indented when moved into or out of a block, except in languages where indentation is syntax",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunMoveLineUp).String())
+				}),
+			}},
+			{"MoveLineDown", ki.Props{
+				"label":    "Move Line Down",
+				"desc":     "move the current line, or the selected lines, down one line -- they are re-indented when moved into or out of a block, except in languages where indentation is syntax",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunMoveLineDown).String())
+				}),
+			}},
+			{"DeleteLine", ki.Props{
+				"label":    "Delete Line",
+				"desc":     "delete the current line, or the selected lines",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunDeleteLine).String())
+				}),
+			}},
+			{"ReformatFile", ki.Props{
+				"label":    "Reformat File",
+				"desc":     "reformat the whole file, with the Formatter for its language in the language prefs if set and installed (e.g., gofmt), else by re-indenting its lines by their bracket nesting -- shows the number of changed lines in the status bar, and can be undone",
+				"updtfunc": GideViewInactiveTextViewFunc,
+			}},
+			{"ExpandSel", ki.Props{
+				"label":    "Expand Selection",
+				"desc":     "expand the selection to the next larger syntactic unit: word, string, expression in brackets, line, block, function -- uses the language parser where available, and indentation elsewhere",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunExpandSel).String())
+				}),
+			}},
+			{"ShrinkSel", ki.Props{
+				"label":    "Shrink Selection",
+				"desc":     "shrink the selection back to what it was before the last Expand Selection",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunShrinkSel).String())
+				}),
+			}},
+			{"Table", ki.PropSlice{
+				{"TableFormat", ki.Props{
+					"label":    "Format Table",
+					"desc":     "reformat (align) the Markdown table at the cursor",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"TableAddColumn", ki.Props{
+					"label":    "Add Column",
+					"desc":     "add an empty column after the one at the cursor in the Markdown table",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"TableDeleteColumn", ki.Props{
+					"label":    "Delete Column",
+					"desc":     "delete the column at the cursor in the Markdown table",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"TableFromCSV", ki.Props{
+					"label":    "CSV To Table",
+					"desc":     "convert the selected CSV text (first row is the header) to a Markdown table",
+					"updtfunc": GideViewInactiveTextSelectionFunc,
+				}},
+			}},
+			{"TransformSel", ki.Props{
+				"label":    "Transform",
+				"desc":     "transform the selected text: sort, unique, reverse or join lines, change case, or base64 / URL encode or decode -- undone as one operation",
+				"submenu":  &gide.TransformNames,
+				"updtfunc": GideViewInactiveTextSelectionFunc,
+				"Args": ki.PropSlice{
+					{"Transform", ki.Props{}},
+				},
+			}},
+			{"FormatSQL", ki.Props{
+				"label":    "Format SQL",
+				"desc":     "format the selected SQL, or the whole .sql file if no selection: upper-case keywords, and start each clause on a new line",
+				"updtfunc": GideViewInactiveTextViewFunc,
+			}},
+			{"PasteImage", ki.Props{
+				"label":    "Paste Image",
+				"desc":     "save the image on the clipboard into the project assets directory (see Assets Dir in project prefs) and insert a Markdown link to it at the cursor",
+				"updtfunc": GideViewInactiveTextViewFunc,
+			}},
 		}},
 		{"View", ki.PropSlice{
 			{"Panels", ki.PropSlice{
@@ -2888,6 +7416,26 @@ var GideViewProps = ki.Props{
 					}),
 					"updtfunc": GideViewInactiveEmptyFunc,
 				}},
+				{"TogglePin", ki.Props{
+					"label":    "Toggle Pin",
+					"desc":     "toggle pinning the file in the active view to it: while pinned, the view is never used to open other files, e.g., from the file tree, which open in the other view instead",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"ToggleTestSource", ki.Props{
+					"label":    "Toggle Test/Source",
+					"desc":     "open the test file of the file in the active view in its place, e.g., foo_test.go for foo.go, or the source file if it is a test -- a missing test file is made from the template for it",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"TestSourceSideBySide", ki.Props{
+					"label":    "Test/Source Side By Side",
+					"desc":     "open the test file of the file in the active view in the other view, e.g., foo_test.go for foo.go, or the source file if it is a test -- a missing test file is made from the template for it",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"ToggleSyncScroll", ki.Props{
+					"label":    "Toggle Sync Scrolling",
+					"desc":     "toggle synchronized scrolling of the two text views: scrolling either one scrolls the other by the same number of lines, keeping their current offset -- e.g., to compare a file with its translation, tests with their implementation, or two parts of a long file (see Clone Active)",
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
 			}},
 			{"Splits", ki.PropSlice{
 				{"SplitsSetView", ki.Props{
@@ -2927,6 +7475,124 @@ var GideViewProps = ki.Props{
 			{"OpenConsoleTab", ki.Props{
 				"updtfunc": GideViewInactiveEmptyFunc,
 			}},
+			{"ViewNotices", ki.Props{
+				"label": "Notices",
+				"desc":  "show the non-modal prompts, e.g., that an auto-save file exists, with links for their choices -- prompts are shown as notices according to the Dialogs prefs",
+			}},
+			{"ViewJobs", ki.Props{
+				"label": "Jobs",
+				"desc":  "show the jobs running in the background, e.g., commands, Run On Files, release builds, the file server and broadcasts, with their elapsed time and progress, and links to cancel them, in the Jobs tab",
+			}},
+			{"ProjStats", ki.Props{
+				"label": "Project Stats",
+				"desc":  "show the statistics of the project files: lines of code per language, file counts, the largest files, the most changed files in the recent VCS history, and a treemap of the directory sizes, in the Stats tab",
+			}},
+			{"DepGraph", ki.Props{
+				"label": "Dependency Graph",
+				"desc":  "show the dependency graph of the project, e.g., the imports among the packages of a Go module, in the Deps tab -- cycles are highlighted, and clicking on a node selects its directory in the file tree",
+			}},
+			{"OpenMainTab", ki.Props{
+				"label":        "Open Tab",
+				"desc":         "open a tab of a type added with gide.RegisterMainTab, e.g., by a plugin or an editor built on gide",
+				"submenu-func": giv.SubMenuFunc(GideViewMainTabTypes),
+				"updtfunc": giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+					act.SetInactiveState(len(gide.MainTabTypes) == 0)
+				}),
+				"Args": ki.PropSlice{
+					{"Tab", ki.Props{}},
+				},
+			}},
+			{"SetHiStyle", ki.Props{
+				"label":        "Style",
+				"desc":         "set the highlighting style for this project (saved in the project prefs), applied to all open files -- (Global Style) uses the one in the gide prefs",
+				"submenu-func": giv.SubMenuFunc(GideViewHiStyles),
+				"Args": ki.PropSlice{
+					{"Style Name", ki.Props{}},
+				},
+			}},
+			{"ToggleProseMode", ki.Props{
+				"label":    "Toggle Prose Mode",
+				"desc":     "toggle prose (writing) mode for the file type of the active view: soft wrap at a comfortable width, centered typewriter-style scrolling, and no line numbers -- sentence navigation keys are available in any mode",
+				"updtfunc": GideViewInactiveTextViewFunc,
+			}},
+			{"Editor", ki.PropSlice{
+				{"ToggleWordWrap", ki.Props{
+					"desc":     "toggle word wrap for the file in the active view -- saved per file in the project prefs, overriding the editor prefs",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"ToggleLineNos", ki.Props{
+					"label":    "Toggle Line Numbers",
+					"desc":     "toggle line numbers for the file in the active view -- saved per file in the project prefs, overriding the editor prefs",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"SetTabSize", ki.Props{
+					"label":    "Set Tab Size...",
+					"desc":     "set the tab size for the file in the active view -- saved per file in the project prefs, overriding the editor prefs",
+					"updtfunc": GideViewInactiveTextViewFunc,
+					"Args": ki.PropSlice{
+						{"Tab Size", ki.Props{
+							"default-field": "Prefs.Editor.TabSize",
+						}},
+					},
+				}},
+				{"ToggleWhitespace", ki.Props{
+					"desc":     "toggle showing spaces, tabs and line endings as faint glyphs in the active view, e.g., to find mixed indentation -- the glyphs are set in the editor Whitespace prefs",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"ToggleWhitespaceAll", ki.Props{
+					"label":    "Toggle Whitespace All Views",
+					"desc":     "toggle showing spaces, tabs and line endings as faint glyphs in all the views",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"ResetViewOpts", ki.Props{
+					"label":    "Reset",
+					"desc":     "remove the word wrap, line numbers and tab size settings for the file in the active view, so the editor prefs are used again",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+			}},
+			{"ToggleZenMode", ki.Props{
+				"label":    "Toggle Zen Mode",
+				"desc":     "toggle distraction-free (zen) editing mode: only the active text view is shown, centered at the editor Zen Width, without the file tree, tabs, toolbar or statusbar -- toggling off restores the named split config",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunZenMode).String())
+				}),
+			}},
+			{"ToggleKeyDisplay", ki.Props{
+				"label": "Toggle Key Display",
+				"desc":  "toggle showing the pressed key chords, and the key functions they execute, at the bottom of the window -- for screencasts and teaching -- plain typing is not shown",
+			}},
+			{"LockProject", ki.Props{
+				"label":    "Lock",
+				"desc":     "lock the project now: the editors are blanked until the lock passphrase is entered -- projects are also locked after the Idle Lock Mins of inactivity in the gide prefs",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"SetLockPassphrase", ki.Props{
+				"label": "Set Lock Passphrase...",
+				"desc":  "set the passphrase for unlocking locked projects -- only a salted hash of it is saved in the gide prefs",
+			}},
+			{"Notebook", ki.PropSlice{
+				{"ViewNotebook", ki.Props{
+					"label": "View Notebook...",
+					"desc":  "show a Jupyter notebook (.ipynb) as its rendered cells and outputs, in the Notebook tab -- notebooks opened in the file tree are shown this way",
+					"Args": ki.PropSlice{
+						{"Notebook", ki.Props{
+							"default-field": "NotebookFile",
+							"ext":           ".ipynb",
+						}},
+					},
+				}},
+				{"ExtractNotebookCode", ki.Props{
+					"label": "Extract Code...",
+					"desc":  "write the code cells of the Jupyter notebook to a new .py (or .go, etc, per the notebook language) file next to it, and open it",
+					"Args": ki.PropSlice{
+						{"Notebook", ki.Props{
+							"default-field": "NotebookFile",
+							"ext":           ".ipynb",
+						}},
+					},
+				}},
+			}},
 		}},
 		{"Navigate", ki.PropSlice{
 			{"Cursor", ki.PropSlice{
@@ -2936,13 +7602,56 @@ var GideViewProps = ki.Props{
 				{"Forward", ki.Props{
 					"keyfun": gi.KeyFunHistNext,
 				}},
-				{"Jump To Line", ki.Props{
-					"keyfun": gi.KeyFunJump,
+				{"GotoPrompt", ki.Props{
+					"label":    "Goto...",
+					"desc":     "go to a line number, lines down (+15) or up (-10) from the cursor, or a percentage of the lines (50%), optionally followed by :column -- or to file.go:line:column, opening the file first -- also by clicking the line and column in the statusbar",
+					"updtfunc": GideViewInactiveTextViewFunc,
+					"shortcut": gi.KeyFunJump,
 				}},
 			}},
 			{"Declaration", ki.Props{
 				"updtfunc": GideViewInactiveTextSelectionFunc,
 			}},
+			{"JumpBracket", ki.Props{
+				"label":    "Jump To Matching Bracket",
+				"desc":     "move the cursor to the bracket matching the one at or just before the cursor, or else to the start of the innermost enclosing bracket pair -- brackets in comments and strings are skipped",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunJumpBracket).String())
+				}),
+			}},
+			{"JumpTag", ki.Props{
+				"label":    "Jump To Matching Tag",
+				"desc":     "in HTML and XML files, move the cursor to the tag matching the one at the cursor, or else to the start tag of the innermost element containing the cursor -- tags in comments are skipped",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunJumpTag).String())
+				}),
+			}},
+			{"NextOccur", ki.Props{
+				"label":    "Next Occurrence",
+				"desc":     "move the cursor to the next occurrence of the identifier at the cursor, as underlined when the HiOccurs editor pref is on -- occurrences in comments and strings are skipped",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunNextOccur).String())
+				}),
+			}},
+			{"PrevOccur", ki.Props{
+				"label":    "Prev Occurrence",
+				"desc":     "move the cursor to the previous occurrence of the identifier at the cursor",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunPrevOccur).String())
+				}),
+			}},
+			{"JumpVisible", ki.Props{
+				"label":    "Jump to Visible",
+				"desc":     "show short letter labels on the words and line starts visible in the active view -- typing a label moves the cursor there, and any other key cancels",
+				"updtfunc": GideViewInactiveTextViewFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunJumpVisible).String())
+				}),
+			}},
 		}},
 		{"Command", ki.PropSlice{
 			{"Build", ki.Props{
@@ -2960,6 +7669,229 @@ var GideViewProps = ki.Props{
 			{"Commit", ki.Props{
 				"updtfunc": GideViewInactiveEmptyFunc,
 			}},
+			{"RunCmdOnFiles", ki.Props{
+				"label":    "Run Command on Files...",
+				"desc":     "run a command once for each of the project files matching glob patterns and languages, as {FilePath} etc, a few at a time -- e.g., a formatter or a codemod across the tree -- the exit status of each file is shown in the Run On Files tab",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"Command", ki.Props{
+						"default-field": "Prefs.CmdFiles.Cmd",
+					}},
+					{"Glob Patterns", ki.Props{
+						"desc":          "glob patterns of the files, separated by spaces or commas, e.g., *.c *.h -- matched against the file name, or the path relative to the project root if the pattern has a / -- leave empty for all files",
+						"default-field": "Prefs.CmdFiles.Glob",
+						"width":         40,
+					}},
+					{"Languages", ki.Props{
+						"desc":          "restrict to files of these languages -- leave empty for all files",
+						"default-field": "Prefs.CmdFiles.Langs",
+					}},
+					{"Max Procs", ki.Props{
+						"desc":          "maximum number of files the command runs on at the same time -- number of cpus if 0",
+						"default-field": "Prefs.CmdFiles.MaxProcs",
+					}},
+				},
+			}},
+			{"ToggleWatchCmds", ki.Props{
+				"label":    "Watch Commands",
+				"desc":     "toggle watch mode: the commands with Watch globs (set in Edit Cmds) are re-run, in their output tabs, whenever files in the project matching the globs change -- for auto-rebuild, auto-test and live-reload loops",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"ServeDir", ki.Props{
+				"label":    "Serve Project Directory...",
+				"desc":     "start a local static file server for a folder (default is the project root), and open it in the browser -- useful for previewing static sites built from the project",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"Folder", ki.Props{
+						"default-field": "Prefs.ServeDir",
+					}},
+					{"Port", ki.Props{
+						"default-field": "Prefs.ServePort",
+					}},
+					{"Live Reload", ki.Props{
+						"default-field": "Prefs.ServeReload",
+					}},
+					{"Open Browser", ki.Props{
+						"default": true,
+					}},
+				},
+			}},
+			{"StopServeDir", ki.Props{
+				"label":    "Stop Serving",
+				"desc":     "stop the local file server started by Serve Project Directory",
+				"updtfunc": GideViewInactiveNoServerFunc,
+			}},
+			{"Broadcast", ki.PropSlice{
+				{"StartBroadcast", ki.Props{
+					"label":    "Start...",
+					"desc":     "broadcast the active view read-only over the network, for following in a browser or another gide (Follow) -- the share urls are shown in the Broadcast tab",
+					"updtfunc": GideViewInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Port", ki.Props{
+							"default": gide.DefaultBroadcastPort,
+						}},
+					},
+				}},
+				{"StopBroadcast", ki.Props{
+					"label": "Stop",
+					"updtfunc": giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+						ge := gei.(ki.Ki).Embed(KiT_GideView).(*GideView)
+						act.SetActiveState(ge.Broadcast != nil)
+					}),
+				}},
+				{"FollowBroadcast", ki.Props{
+					"label": "Follow...",
+					"desc":  "follow the broadcast of another gide, given one of its share urls, in the Follow tab",
+					"Args": ki.PropSlice{
+						{"URL", ki.Props{
+							"default-field": "FollowURL",
+							"width":         60,
+						}},
+					},
+				}},
+				{"StopFollow", ki.Props{
+					"label": "Stop Following",
+					"updtfunc": giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+						ge := gei.(ki.Ki).Embed(KiT_GideView).(*GideView)
+						act.SetActiveState(ge.FollowStop != nil)
+					}),
+				}},
+			}},
+			{"CheckDocLinks", ki.Props{
+				"label":    "Check Doc Links",
+				"desc":     "check the relative links and anchors in all the Markdown files in the project, reporting broken ones in the Problems tab",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"Data", ki.PropSlice{
+				{"CheckData", ki.Props{
+					"label":    "Check",
+					"desc":     "check the syntax of the JSON, YAML or TOML file in the active view, and for duplicate keys, reporting problems in the Problems tab -- files are also checked as they are edited (see DataCheck in the editor prefs)",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"DataStructure", ki.Props{
+					"label":    "Structure",
+					"desc":     "show the structure of the JSON, YAML or TOML file in the active view in a collapsible outline, in the Structure tab, updated as the file is edited",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"sep-data-fmt", ki.BlankProp{}},
+				{"PrettyPrintData", ki.Props{
+					"label":    "Pretty-Print JSON",
+					"desc":     "pretty-print the JSON file in the active view, with the indentation of the file",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"MinifyData", ki.Props{
+					"label":    "Minify JSON",
+					"desc":     "minify the JSON file in the active view onto one line",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"sep-data-conv", ki.BlankProp{}},
+				{"ConvertToJSON", ki.Props{
+					"label":    "Convert To JSON",
+					"desc":     "convert the YAML or TOML file in the active view to a new .json file next to it -- comments are not converted",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"ConvertToYAML", ki.Props{
+					"label":    "Convert To YAML",
+					"desc":     "convert the JSON or TOML file in the active view to a new .yaml file next to it -- comments are not converted",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"ConvertToTOML", ki.Props{
+					"label":    "Convert To TOML",
+					"desc":     "convert the JSON or YAML file in the active view to a new .toml file next to it -- comments are not converted, and TOML has no null values",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+			}},
+			{"OpenAPI", ki.PropSlice{
+				{"ValidateOpenAPI", ki.Props{
+					"label":    "Validate",
+					"desc":     "check the OpenAPI (Swagger) document in the active view, reporting problems in the Problems tab",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"PreviewOpenAPI", ki.Props{
+					"label":    "Preview Docs",
+					"desc":     "show the API documentation for the OpenAPI (Swagger) document in the active view, in the API Docs tab",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"GenAPIClient", ki.Props{
+					"label":    "Generate Client Stub",
+					"desc":     "run the OpenAPI Gen Client command (openapi-generator by default -- edit in Commands prefs) on the active document",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"GenAPIServer", ki.Props{
+					"label":    "Generate Server Stub",
+					"desc":     "run the OpenAPI Gen Server command (openapi-generator by default -- edit in Commands prefs) on the active document",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+			}},
+			{"SQL", ki.PropSlice{
+				{"ExplainQuery", ki.Props{
+					"label":    "Explain Query",
+					"desc":     "run EXPLAIN for the selected query, or the statement at the cursor, with the SQL Client set in the project prefs, showing the plan in the Explain tab",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"LoadSQLSchema", ki.Props{
+					"label": "Reload Schema",
+					"desc":  "reload the database schema used for completing table and column names in .sql files, from the Schema file or Schema Cmd set in the SQL project prefs",
+				}},
+			}},
+			{"Shell", ki.PropSlice{
+				{"ShellCheck", ki.Props{
+					"desc":     "run shellcheck on the shell script in the active view, reporting problems in the Problems tab",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"RunShellSelection", ki.Props{
+					"label":    "Run Selection in Shell",
+					"desc":     "run the selected lines (or current line) in the active view in the shell ($SHELL), in the directory of the file, showing output in the Shell tab",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+			}},
+			{"Terraform", ki.PropSlice{
+				{"ValidateTerraform", ki.Props{
+					"label":    "Validate",
+					"desc":     "run terraform validate in the directory of the active Terraform file, reporting errors and warnings in the Problems tab",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"PlanTerraform", ki.Props{
+					"label":    "Plan",
+					"desc":     "run terraform plan in the directory of the active Terraform file -- diagnostic locations in the output are linked",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+				{"InitTerraform", ki.Props{
+					"label":    "Init",
+					"desc":     "run terraform init in the directory of the active Terraform file",
+					"updtfunc": GideViewInactiveTextViewFunc,
+				}},
+			}},
+			{"Release", ki.PropSlice{
+				{"PrepareRelease", ki.Props{
+					"label": "Prepare Release...",
+					"desc":  "suggest the next version from the conventional commits (feat:, fix:, etc) since the last tag, update the version files and insert a drafted changelog section per the Release project prefs, and then commit and tag the release after confirmation",
+				}},
+				{"TagRelease", ki.Props{
+					"desc": "commit the version files and changelog updated by Prepare Release, and tag the release",
+				}},
+				{"BuildRelease", ki.Props{
+					"desc": "build the release artifacts per the Release project prefs: cross-compile for each target, archive with the extra files, and write checksums -- the artifacts are listed in the Release tab, with links to reveal or upload each",
+				}},
+				{"UploadReleaseArtifacts", ki.Props{
+					"label": "Upload All Artifacts",
+					"desc":  "upload all the artifacts from the last Build Release, with the UploadCmd of the Release project prefs",
+				}},
+			}},
+			{"EvaluateSelection", ki.Props{
+				"label":    "Evaluate Selection",
+				"desc":     "evaluate the selected Go code (or the line of the cursor) with the built-in yaegi Go interpreter, in a separate process that is stopped if it runs too long -- the value of an expression, or the output of statements, is printed to the Console",
+				"updtfunc": GideViewInactiveTextViewFunc,
+			}},
+			{"RunSnippet", ki.Props{
+				"desc":     "run the selected Go code (or entire buffer), wrapped in a main function if needed, showing output in the Run Snippet tab",
+				"updtfunc": GideViewInactiveTextViewFunc,
+			}},
+			{"ShareToPlayground", ki.Props{
+				"label":    "Share to Playground",
+				"desc":     "share the Go code in the active view on the Go Playground -- the url is shown in the status bar and copied to the clipboard",
+				"updtfunc": GideViewInactiveTextViewFunc,
+			}},
 			{"ExecCmdNameActive", ki.Props{
 				"label":        "Exec Cmd",
 				"submenu-func": giv.SubMenuFunc(ExecCmds),
@@ -2975,10 +7907,100 @@ var GideViewProps = ki.Props{
 					{"File Name 2", ki.Props{}},
 				},
 			}},
+			{"DiffBufSaved", ki.Props{
+				"label":    "Diff Buffer vs Saved",
+				"desc":     "show the unsaved changes of the active buffer: the differences from its file on disk, in the Diffs tab",
+				"updtfunc": GideViewInactiveTextViewFunc,
+			}},
+			{"DiffClipboard", ki.Props{
+				"label":    "Diff vs Clipboard",
+				"desc":     "show the differences from the selection, or the active buffer if there is no selection, to the text on the clipboard, in the Diffs tab",
+				"updtfunc": GideViewInactiveTextViewFunc,
+			}},
+			{"DiffOpenNode", ki.Props{
+				"label":        "Diff vs Open Buffer",
+				"desc":         "show the differences from the active buffer to another open buffer, in the Diffs tab",
+				"submenu-func": giv.SubMenuFunc(GideViewOpenNodes),
+				"updtfunc":     GideViewInactiveTextViewFunc,
+				"Args": ki.PropSlice{
+					{"Buffer Name", ki.Props{}},
+				},
+			}},
+			{"SaveDiffsPatch", ki.Props{
+				"label":    "Save Diffs As Patch...",
+				"desc":     "save the diffs in the Diffs tab as a patch file, which can be applied with Apply Patch, or git apply",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".patch",
+					}},
+				},
+			}},
+			{"ApplyPatch", ki.Props{
+				"label":    "Apply Patch...",
+				"desc":     "apply a patch file, e.g., saved from the Diffs tab, or written by git diff, to the project, after a preview of the files it changes, in the Apply Patch tab -- it can be undone as one operation",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".patch,.diff",
+					}},
+				},
+			}},
+			{"ApplyPatchClipboard", ki.Props{
+				"label":    "Apply Patch from Clipboard",
+				"desc":     "apply the patch on the clipboard to the project, after a preview of the files it changes, in the Apply Patch tab -- it can be undone as one operation",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"ResolveConflicts", ki.Props{
+				"label":    "Resolve Conflicts",
+				"desc":     "resolve the merge conflicts in the active buffer, marked by the VCS, in a three-pane merge view of our side, the base and their side of each conflict, taking either or both sides",
+				"updtfunc": GideViewInactiveTextViewFunc,
+			}},
+		}},
+		{"Plugins", ki.PropSlice{
+			{"RunPluginAction", ki.Props{
+				"label":        "Run",
+				"desc":         "run an action added by a plugin -- plugins are Go packages that call gide.RegisterPlugin, compiled in or loaded from the plugins directory in the gide prefs directory",
+				"submenu-func": giv.SubMenuFunc(GideViewPluginActions),
+				"Args": ki.PropSlice{
+					{"Action", ki.Props{}},
+				},
+			}},
+			{"ViewPlugins", ki.Props{
+				"label": "View Plugins",
+				"desc":  "show the registered plugins and their actions, commands and key chords",
+			}},
+			{"Inspector", ki.Props{
+				"label": "Inspector",
+				"desc":  "show the live tree of the widgets in this window, with their layout and props, and links to re-render or edit them, for debugging the layout of custom views",
+			}},
+		}},
+		{"Projects", ki.PropSlice{
+			{"GoToProj", ki.Props{
+				"label":        "Go To Project",
+				"desc":         "raise the window of the open project",
+				"submenu-func": giv.SubMenuFunc(GideViewOpenProjs),
+				"Args": ki.PropSlice{
+					{"Project Name", ki.Props{}},
+				},
+			}},
+			{"MoveFileToProj", ki.Props{
+				"label":        "Move File To Project",
+				"desc":         "close the file in the active view, and open it in the window of the other open project, which must contain it",
+				"submenu-func": giv.SubMenuFunc(GideViewOtherProjs),
+				"updtfunc":     GideViewInactiveTextViewFunc,
+				"Args": ki.PropSlice{
+					{"Project Name", ki.Props{}},
+				},
+			}},
 		}},
 		{"Window", "Windows"},
 		{"Help", ki.PropSlice{
 			{"HelpWiki", ki.Props{}},
+			{"CheckForUpdates", ki.Props{
+				"label": "Check For Updates...",
+				"desc":  "check for a new gide release, showing its release notes and offering to install it -- the release channel (stable or dev) and automatic checking are set in the gide prefs Updates",
+			}},
 		}},
 	},
 	"CallMethods": ki.PropSlice{
@@ -3051,6 +8073,7 @@ func NewGideWindow(path, projnm, root string, doPath bool) (*gi.Window, *GideVie
 	} else {
 		ge.OpenProj(gi.FileName(path))
 	}
+	gide.PluginsProjOpened(ge)
 
 	mmen := win.MainMenu
 	giv.MainMenuView(ge, win, mmen)
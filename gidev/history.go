@@ -0,0 +1,86 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gidev
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"path/filepath"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/gide/gide"
+)
+
+// LocalHistory shows the local history of saved versions of given file
+// node in the Local History tab, with links to show the diffs against the
+// current buffer, or restore the version into the buffer
+func (ge *GideView) LocalHistory(fn *giv.FileNode) {
+	hs := gide.FileHistory(fn.FPath)
+	ge.HistoryFile = fn.FPath
+	hbuf, htv, _ := ge.RecycleCmdTab("Local History", true, true)
+	outlns := make([][]byte, 0, len(hs)+1)
+	outmus := make([][]byte, 0, len(hs)+1)
+	lstr := fmt.Sprintf("Local History: %v: %v versions", fn.MyRelPath(), len(hs))
+	outlns = append(outlns, []byte(lstr))
+	outmus = append(outmus, []byte("<b>"+html.EscapeString(lstr)+"</b>"))
+	for _, h := range hs {
+		tstr := h.Time.Format("2006-01-02 15:04:05")
+		outlns = append(outlns, []byte(fmt.Sprintf("%v (%v bytes): diff restore", tstr, h.Size)))
+		outmus = append(outmus, []byte(fmt.Sprintf(`%v (%v bytes): <a href="history:///%v#diff">diff</a> <a href="history:///%v#restore">restore</a>`, tstr, h.Size, h.Path, h.Path)))
+	}
+	hbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true)
+	htv.CursorStartDoc()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// OpenHistoryURL opens given history:/// url from the Local History tab:
+// the #diff fragment shows the diff from that version to the current buffer,
+// and #restore replaces the buffer contents with that version (which can
+// then be saved)
+func (ge *GideView) OpenHistoryURL(ur string) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("GideView OpenHistoryURL parse err: %v\n", err)
+		return false
+	}
+	snap := up.Path[1:] // has double //
+	fnk, ok := ge.Files.FindFile(string(ge.HistoryFile))
+	if !ok {
+		return false
+	}
+	fn := fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode)
+	if fn.Buf == nil {
+		ge.OpenFileNode(fn)
+	}
+	if fn.Buf == nil {
+		return false
+	}
+	switch up.Fragment {
+	case "restore":
+		b, err := ioutil.ReadFile(snap)
+		if err != nil {
+			ge.SetStatus(fmt.Sprintf("Could not read local history version: %v", err))
+			return false
+		}
+		ge.NextViewFileNode(fn)
+		fn.Buf.SetText(b)
+		fn.Buf.SetChanged()
+		ge.SetStatus(fmt.Sprintf("Restored version: %v -- save to keep it", filepath.Base(snap)))
+	default:
+		dif, err := gide.HistoryDiff(snap, fn.Buf)
+		if err != nil {
+			ge.SetStatus(fmt.Sprintf("Could not diff local history version: %v", err))
+			return false
+		}
+		cbuf, _, _ := ge.RecycleCmdTab("Diffs", true, true)
+		cbuf.SetText(dif)
+		cbuf.AutoScrollViews()
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gidev
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ViewJobs shows the jobs running in the background in the Jobs tab
+func (ge *GideView) ViewJobs() {
+	ge.ShowJobs(true)
+}
+
+// ShowJobs shows the jobs running in the background in the Jobs tab, with
+// their elapsed time, progress, if known, and links to cancel those that
+// can be -- the tab is updated every second while there are jobs, and
+// whenever they change -- if sel, the tab is selected
+func (ge *GideView) ShowJobs(sel bool) {
+	jbs := ge.RunningJobs.Running()
+	updt := ge.VPort().Win.UpdateStart()
+	jbuf, jtv, _ := ge.RecycleCmdTab("Jobs", sel, true)
+	nq := ge.RunningJobs.NQueued()
+	lstr := fmt.Sprintf("Jobs: %v running, %v queued", len(jbs)-nq, nq)
+	lns := []string{lstr}
+	mus := []string{"<b>" + html.EscapeString(lstr) + "</b>"}
+	for _, jb := range jbs {
+		cols := fmt.Sprintf("%-14v %-24v %8v %9v  ", jb.Kind, jb.Name, jb.Elapsed().Round(time.Second), jb.Progress())
+		ln := cols + jb.Description()
+		mu := html.EscapeString(ln)
+		if jb.Cancel != nil {
+			ln += "  Cancel"
+			mu += fmt.Sprintf(`  <a href="job:///%v#cancel">Cancel</a>`, jb.ID)
+		}
+		lns = append(lns, ln)
+		mus = append(mus, mu)
+	}
+	jbuf.AppendTextMarkup([]byte(strings.Join(lns, "\n")), []byte(strings.Join(mus, "\n")), false, true)
+	jtv.CursorStartDoc()
+	ge.VPort().Win.UpdateEnd(updt)
+	if ge.JobsTimer != nil {
+		ge.JobsTimer.Stop()
+	}
+	if len(jbs) > 0 {
+		ge.JobsTimer = time.AfterFunc(time.Second, ge.JobsChanged)
+	}
+}
+
+// JobsChanged updates the Jobs tab, if it is open -- it watches the
+// RunningJobs
+func (ge *GideView) JobsChanged() {
+	if _, err := ge.MainTabByNameTry("Jobs"); err != nil {
+		return
+	}
+	ge.ShowJobs(false)
+}
+
+// OpenJobURL opens given job:///<id>#cancel url from the Jobs tab,
+// canceling the job
+func (ge *GideView) OpenJobURL(ur string) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("GideView OpenJobURL parse err: %v\n", err)
+		return false
+	}
+	id, err := strconv.Atoi(up.Path[1:]) // has double //
+	if err != nil || up.Fragment != "cancel" {
+		return false
+	}
+	jb := ge.RunningJobs.ByID(id)
+	if jb == nil {
+		ge.SetStatus("The job has already finished")
+		return false
+	}
+	if !ge.RunningJobs.CancelJob(jb) {
+		return false
+	}
+	ge.SetStatus(fmt.Sprintf("Canceled %v: %v", jb.Kind, jb.Name))
+	return true
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gidev
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/gide/gide"
+	"github.com/goki/ki/ki"
+)
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Idle Lock
+
+// StartIdleLock starts (or restarts) the timer for locking the project
+// after IdleLockMins of inactivity, if a lock passphrase is set
+func (ge *GideView) StartIdleLock() {
+	ge.StopIdleLock()
+	if gide.Prefs.IdleLockMins <= 0 || gide.Prefs.LockPass == "" {
+		return
+	}
+	ge.SetActive()
+	ge.IdleLockAfter(time.Duration(gide.Prefs.IdleLockMins) * time.Minute)
+}
+
+// IdleLockAfter starts the idle lock timer, to call IdleLockCheck after
+// given duration -- the check is run on the event loop goroutine (see
+// RunOnGUI), and only if the timer has not been stopped or replaced since
+func (ge *GideView) IdleLockAfter(d time.Duration) {
+	var tm *time.Timer
+	tm = time.AfterFunc(d, func() {
+		ge.RunOnGUI(func() {
+			if ge.IdleTimer == tm {
+				ge.IdleLockCheck()
+			}
+		})
+	})
+	ge.IdleTimer = tm
+}
+
+// StopIdleLock stops the idle lock timer
+func (ge *GideView) StopIdleLock() {
+	if ge.IdleTimer != nil {
+		ge.IdleTimer.Stop()
+		ge.IdleTimer = nil
+	}
+}
+
+// IdleLockCheck is called by the idle lock timer, on the event loop
+// goroutine: it locks the project if there has been no activity for
+// IdleLockMins, and otherwise waits for the remaining time
+func (ge *GideView) IdleLockCheck() {
+	dur := time.Duration(gide.Prefs.IdleLockMins) * time.Minute
+	if dur <= 0 || gide.Prefs.LockPass == "" || ge.Locked {
+		return
+	}
+	idle := ge.IdleTime()
+	if idle >= dur {
+		ge.LockProject()
+		return
+	}
+	ge.IdleLockAfter(dur - idle)
+}
+
+// SetActive records the current time as that of the last activity
+func (ge *GideView) SetActive() {
+	ge.ActiveMu.Lock()
+	ge.LastActive = time.Now()
+	ge.ActiveMu.Unlock()
+}
+
+// IdleTime returns the time since the last activity
+func (ge *GideView) IdleTime() time.Duration {
+	ge.ActiveMu.Lock()
+	defer ge.ActiveMu.Unlock()
+	return time.Since(ge.LastActive)
+}
+
+// LockProject locks the project: the text views are blanked and only the
+// first one is shown, until the lock passphrase is entered -- the buffers
+// and splitter proportions are restored by UnlockProject
+func (ge *GideView) LockProject() {
+	if ge.Locked {
+		return
+	}
+	if gide.Prefs.LockPass == "" {
+		ge.SetStatus("No lock passphrase is set -- use Set Lock Passphrase first")
+		return
+	}
+	ge.StopIdleLock()
+	updt := ge.UpdateStart()
+	ge.SetFullReRender()
+	ge.Locked = true
+	lb := &giv.TextBuf{}
+	lb.InitName(lb, "locked-buf")
+	lb.Autosave = false
+	lb.SetText([]byte(gide.LockText))
+	ge.LockBufs = make([]*giv.TextBuf, NTextViews)
+	for i := 0; i < NTextViews; i++ {
+		tv := ge.TextViewByIndex(i)
+		ge.LockBufs[i] = tv.Buf
+		tv.SetBuf(lb)
+	}
+	sv := ge.SplitView()
+	ge.LockSplits = append([]float32{}, sv.Splits...)
+	splits := make([]float32, len(sv.Splits))
+	splits[TextView1Idx] = 1
+	sv.SetSplitsAction(splits...)
+	ge.UpdateEnd(updt)
+	ge.SetStatus("Project locked")
+	ge.UnlockPrompt()
+}
+
+// UnlockPrompt prompts for the lock passphrase, and unlocks the project if
+// it is correct -- otherwise it prompts again
+func (ge *GideView) UnlockPrompt() {
+	ge.LockPassDialog(gi.DlgOpts{Title: "Project Locked", Prompt: fmt.Sprintf("Project: %v is locked -- enter the lock passphrase to unlock it", ge.Nm)}, false,
+		func(ok bool, pass string) {
+			if gide.CheckLockPass(gide.Prefs.LockPass, pass) {
+				ge.UnlockProject()
+				return
+			}
+			ge.UnlockPrompt()
+		})
+}
+
+// UnlockProject restores the buffers of the text views and the splitter
+// proportions saved by LockProject, and restarts the idle lock timer
+func (ge *GideView) UnlockProject() {
+	if !ge.Locked {
+		return
+	}
+	updt := ge.UpdateStart()
+	ge.SetFullReRender()
+	for i, tb := range ge.LockBufs {
+		ge.TextViewByIndex(i).SetBuf(tb)
+	}
+	ge.LockBufs = nil
+	if len(ge.LockSplits) == len(ge.SplitView().Splits) {
+		ge.SplitView().SetSplitsAction(ge.LockSplits...)
+	}
+	ge.LockSplits = nil
+	ge.Locked = false
+	ge.UpdateEnd(updt)
+	ge.SetStatus("Project unlocked")
+	ge.StartIdleLock()
+}
+
+// SetLockPassphrase sets the passphrase for unlocking projects locked after
+// the IdleLockMins of inactivity in the gide prefs (or by Lock) -- an empty
+// passphrase turns off the idle lock
+func (ge *GideView) SetLockPassphrase() {
+	ge.LockPassDialog(gi.DlgOpts{Title: "Set Lock Passphrase", Prompt: "Enter the passphrase for unlocking projects -- leave empty to turn off the idle lock"}, true,
+		func(ok bool, pass string) {
+			if !ok {
+				return
+			}
+			if pass == "" {
+				gide.Prefs.LockPass = ""
+				gide.Prefs.Save()
+				ge.StopIdleLock()
+				ge.SetStatus("Lock passphrase removed -- idle lock is off")
+				return
+			}
+			ge.LockPassDialog(gi.DlgOpts{Title: "Confirm Lock Passphrase", Prompt: "Enter the passphrase again"}, true,
+				func(ok bool, conf string) {
+					if !ok {
+						return
+					}
+					if conf != pass {
+						ge.SetStatus("Lock passphrases do not match -- not changed")
+						return
+					}
+					gide.Prefs.LockPass = gide.HashLockPass(pass)
+					gide.Prefs.Save()
+					ge.StartIdleLock()
+					if gide.Prefs.IdleLockMins <= 0 {
+						ge.SetStatus("Lock passphrase set -- set Idle Lock Mins in the gide prefs to lock after inactivity")
+					} else {
+						ge.SetStatus(fmt.Sprintf("Lock passphrase set -- projects lock after %v minutes of inactivity", gide.Prefs.IdleLockMins))
+					}
+				})
+		})
+}
+
+// LockPassDialog opens a modal dialog prompting for a passphrase, which is
+// not shown as it is typed, and calls fun with whether it was accepted and
+// the passphrase
+func (ge *GideView) LockPassDialog(opts gi.DlgOpts, cancel bool, fun func(ok bool, pass string)) {
+	dlg := gi.NewStdDialog(opts, true, cancel)
+	dlg.Modal = true
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	tf := frame.InsertNewChild(gi.KiT_TextField, prIdx+1, "pass-field").(*gi.TextField)
+	tf.SetProp("color", "transparent") // passphrase is not shown
+	tf.SetStretchMaxWidth()
+	tf.SetMinPrefWidth(units.NewCh(40))
+	dlg.DialogSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		fun(sig == int64(gi.DialogAccepted), tf.Text())
+	})
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, ge.Viewport, nil)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gidev
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gide/gide"
+	"github.com/goki/ki/ki"
+)
+
+// updateCheckOnce limits the automatic update check to once per run, for
+// all the project windows
+var updateCheckOnce sync.Once
+
+// AutoCheckUpdate checks for a new gide release in the background, if the
+// automatic check is on in the gide Updates prefs and is due, and offers it
+// if found and not skipped -- errors are ignored
+func (ge *GideView) AutoCheckUpdate() {
+	up := &gide.Prefs.Updates
+	if !up.UpdateDue() {
+		return
+	}
+	updateCheckOnce.Do(func() {
+		go func() {
+			rl, err := gide.CheckUpdate(up.Channel)
+			up.LastCheck = time.Now()
+			gide.Prefs.Save()
+			if err != nil || rl == nil || rl.Version == up.Skip {
+				return
+			}
+			ge.OfferUpdate(rl)
+		}()
+	})
+}
+
+// CheckForUpdates checks for a new gide release in the channel of the gide
+// Updates prefs, and offers it if found
+func (ge *GideView) CheckForUpdates() {
+	ch := gide.Prefs.Updates.Channel
+	ge.SetStatus("Checking for updates...")
+	go func() {
+		rl, err := gide.CheckUpdate(ch)
+		if rl != nil {
+			ge.OfferUpdate(rl)
+			return
+		}
+		prompt := fmt.Sprintf("gide %v is the latest version in the %v channel", gide.Version, strings.TrimPrefix(ch.String(), "Update"))
+		if err != nil {
+			prompt = fmt.Sprintf("Could not check for updates: %v", err)
+		}
+		updt := ge.VPort().Win.UpdateStart()
+		ge.SetStatus("")
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Check For Updates", Prompt: prompt}, gi.AddOk, gi.NoCancel, nil, nil)
+		ge.VPort().Win.UpdateEnd(updt)
+	}()
+}
+
+// OfferUpdate shows the release notes of the new release in the Update tab,
+// and offers to install it with the go tool, open its download, or skip it
+func (ge *GideView) OfferUpdate(rl *gide.UpdateRelease) {
+	updt := ge.VPort().Win.UpdateStart()
+	defer ge.VPort().Win.UpdateEnd(updt)
+	buf, _, _ := ge.RecycleCmdTab("Update", true, true)
+	txt, mu := rl.NotesText()
+	buf.AppendTextMarkup(txt, mu, false, true)
+	ge.SetStatus(fmt.Sprintf("gide %v is available", rl.Version))
+	gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "Update Available",
+		Prompt: fmt.Sprintf("gide <b>%v</b> is available (you have %v) -- see the release notes in the Update tab.  Install installs it with the go tool, which must be available, and Open Download opens the download for this system (or the release page) in the browser.", html.EscapeString(rl.Version), html.EscapeString(gide.Version))},
+		[]string{"Install", "Open Download", "Skip This Version", "Later"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			switch sig {
+			case 0:
+				ge.InstallUpdate(rl)
+			case 1:
+				oswin.TheApp.OpenURL(rl.AssetURL())
+			case 2:
+				gide.Prefs.Updates.Skip = rl.Version
+				gide.Prefs.Save()
+			}
+		})
+}
+
+// InstallUpdate installs the release with the go tool in the background,
+// showing the output in the Update tab
+func (ge *GideView) InstallUpdate(rl *gide.UpdateRelease) {
+	buf, _, _ := ge.RecycleCmdTab("Update", true, false)
+	hstr := fmt.Sprintf("Installing gide %v: go install %v@%v", rl.Version, gide.UpdateModule, rl.Version)
+	buf.AppendTextMarkup([]byte("\n"+hstr+"\n"), []byte("\n<b>"+html.EscapeString(hstr)+"</b>\n"), false, true)
+	ge.SetStatus(hstr)
+	jb := ge.RunningJobs.Add("Update", rl.Version, hstr, nil)
+	go func() {
+		defer jb.Finish()
+		out, err := gide.InstallUpdate(rl.Version)
+		res := fmt.Sprintf("Installed gide %v -- restart gide to use it", rl.Version)
+		if err != nil {
+			res = fmt.Sprintf("Install failed: %v -- Open Download from Check For Updates can be used instead", err)
+		}
+		updt := ge.VPort().Win.UpdateStart()
+		if len(out) > 0 {
+			buf.AppendTextMarkup(out, []byte(html.EscapeString(string(out))), false, true)
+		}
+		buf.AppendTextMarkup([]byte(res+"\n"), []byte("<b>"+html.EscapeString(res)+"</b>\n"), false, true)
+		ge.SetStatus(res)
+		ge.VPort().Win.UpdateEnd(updt)
+	}()
+}
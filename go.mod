@@ -1,12 +1,15 @@
 module github.com/goki/gide
 
 require (
+	github.com/alecthomas/chroma v0.6.9
 	github.com/ajstarks/svgo v0.0.0-20190826172357-de52242f3d65 // indirect
 	github.com/fogleman/gg v1.3.0 // indirect
 	github.com/goki/gi v0.9.9
 	github.com/goki/ki v0.9.9
 	github.com/goki/pi v0.5.9
-	github.com/jung-kurt/gofpdf v1.16.2 // indirect
+	github.com/jung-kurt/gofpdf v1.16.2
+	github.com/traefik/yaegi v0.9.21
+	golang.org/x/image v0.0.0-20191009234506-e7c1f5e7dbb8
 	gonum.org/v1/plot v0.0.0-20191107103940-ca91d9d40d0a
 )
 